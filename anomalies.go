@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"better-fabric-monitor/internal/api"
+	"better-fabric-monitor/internal/db"
+	"better-fabric-monitor/internal/logger"
+)
+
+// Defaults of GetAnomalies
+const (
+	defaultAnomalyDays     = 7
+	defaultAnomalyMinScore = 3.5 // Modified z-scores beyond 3.5 are the usual outlier cutoff
+	anomalyLimit           = 100
+)
+
+// GetAnomalies returns completed runs of the last days whose duration was unusual for their item,
+// with an anomaly score of at least minScore in either direction, most unusual first
+// Runs not scored yet, e.g. from a headless sync or demo data, are scored first
+func (a *App) GetAnomalies(days int, minScore float64) api.AnomaliesResult {
+	if a.db() == nil {
+		return api.AnomaliesResult{Error: "Database not initialized"}
+	}
+	if !a.background.Begin() {
+		return api.AnomaliesResult{Error: errShuttingDown.Error()}
+	}
+	defer a.background.Done()
+	if days <= 0 {
+		days = defaultAnomalyDays
+	}
+	if minScore <= 0 {
+		minScore = defaultAnomalyMinScore
+	}
+
+	if !a.db().ReadOnly() {
+		if _, err := a.db().ScoreRunAnomalies(time.Now().UTC()); err != nil {
+			logger.Warn("Failed to score run anomalies", logger.Err(err))
+		}
+	}
+
+	anomalies, err := a.db().GetAnomalies(days, minScore, anomalyLimit)
+	if err != nil {
+		return api.AnomaliesResult{Error: fmt.Sprintf("Failed to get anomalies: %v", err)}
+	}
+	if anomalies == nil {
+		anomalies = []db.RunAnomaly{}
+	}
+	return api.AnomaliesResult{Anomalies: anomalies}
+}
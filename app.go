@@ -2,40 +2,97 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"path/filepath"
+	"runtime/debug"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"better-fabric-monitor/internal/api"
 	"better-fabric-monitor/internal/auth"
 	"better-fabric-monitor/internal/config"
 	"better-fabric-monitor/internal/db"
 	"better-fabric-monitor/internal/fabric"
 	"better-fabric-monitor/internal/logger"
+	"better-fabric-monitor/internal/notify"
+	syncer "better-fabric-monitor/internal/sync"
+	"better-fabric-monitor/internal/tlsconfig"
 	"better-fabric-monitor/internal/utils"
 )
 
 // App struct
 type App struct {
 	ctx                 context.Context
-	config              *config.Config
+	cancel              context.CancelFunc // Cancels ctx, stopping background work on shutdown
+	background          backgroundWork
+	currentConfig       atomic.Pointer[config.Config] // Running settings, read through config and replaced whole by updateConfig
+	configMutex         sync.Mutex                    // Serializes config changes, so none overwrites another
+	fileConfig          *config.Config                // As last loaded from config.yaml, before startup adjustments; guarded by configMutex
 	auth                *auth.AuthManager
-	db                  *db.Database
+	currentDB           atomic.Pointer[db.Database] // Open database, read through db; the read-only replica may be opened after startup
+	instanceLock        *db.InstanceLock            // Held while this instance owns the database
+	databaseInUse       bool                        // Another instance held the database at startup
 	fabricClient        *fabric.Client
 	currentToken        *auth.Token
 	parquetExportMutex  sync.Mutex
 	parquetExportActive bool
+	parquetLastExport   time.Time   // When the last export started
+	parquetExportTimer  *time.Timer // Pending debounced export, if any
+	syncMutex           sync.Mutex
+	syncActive          bool
+	syncCancel          context.CancelFunc
+	syncStatus          *syncTracker
+	statusRefreshedAt   time.Time // When the poller last refreshed the status of running jobs
+	runningMutex        sync.Mutex
+	runningSignature    string                        // Running jobs and statuses last published as jobs:running
+	currentSyncer       atomic.Pointer[syncer.Syncer] // Syncer over the current database, read through syncer
+	notifier            *notify.Notifier
 }
 
 // NewApp creates a new App application struct
 func NewApp() *App {
-	return &App{}
+	a := &App{}
+	a.syncStatus = newSyncTracker(func(status SyncStatus) {
+		a.emitEvent(EventSyncProgress, status)
+	})
+	return a
+}
+
+// config returns the running settings (nil before startup loads them); the returned Config must not be modified
+func (a *App) config() *config.Config {
+	return a.currentConfig.Load()
+}
+
+// db returns the open database, or nil if none is open
+func (a *App) db() *db.Database {
+	return a.currentDB.Load()
+}
+
+// syncer returns the syncer writing to the open database
+func (a *App) syncer() *syncer.Syncer {
+	return a.currentSyncer.Load()
+}
+
+// updateConfig applies change to a copy of the running settings and makes the copy current unless change fails
+// Slices and maps of the copy are shared with the previous settings, so change must replace them rather than
+// modify them in place
+func (a *App) updateConfig(change func(cfg *config.Config) error) error {
+	a.configMutex.Lock()
+	defer a.configMutex.Unlock()
+	cfg := *a.config()
+	if err := change(&cfg); err != nil {
+		return err
+	}
+	a.currentConfig.Store(&cfg)
+	return nil
 }
 
 // startup is called when the app starts. The context is saved
-// so we can call the runtime methods
+// so we can call the runtime methods; it is wrapped so shutdown can cancel background work
 func (a *App) startup(ctx context.Context) {
-	a.ctx = ctx
+	a.ctx, a.cancel = context.WithCancel(ctx)
 
 	// Initialize log buffer
 	logger.Init(2000)
@@ -43,7 +100,7 @@ func (a *App) startup(ctx context.Context) {
 	// Load configuration
 	cfg, err := config.Load()
 	if err != nil {
-		logger.Log("Failed to load config: %v\n", err)
+		logger.Error("Failed to load config", logger.Err(err))
 		// Continue with default config but set essential defaults
 		cfg = &config.Config{
 			Database: config.DatabaseConfig{
@@ -63,25 +120,53 @@ func (a *App) startup(ctx context.Context) {
 				Debug:    false,
 			},
 		}
+	} else {
+		// Kept before startup adjusts cfg, so hot-reload can tell edits to config.yaml from those adjustments
+		loaded := *cfg
+		a.fileConfig = &loaded
+	}
+	a.currentConfig.Store(cfg)
+	if err := logger.SetLevel(cfg.App.LogLevel); err != nil {
+		logger.Warn("Keeping the info log level", logger.Err(err))
+	}
+	openLogFile(cfg.App.LogFile)
+	applyTLS(cfg.TLS)
+	startTracing(cfg.Tracing, cfg.App.Version)
+	applyFabricURLs(cfg.Fabric)
+	startErrorReporting(cfg.App.ErrorReporting, cfg.App.Version)
+
+	// Soft-cap the Go heap so very large tenants make the GC work harder rather than exhaust memory
+	if cfg.App.MemoryLimitMB > 0 {
+		debug.SetMemoryLimit(int64(cfg.App.MemoryLimitMB) << 20)
+		logger.Info("Go memory limit set", "megabytes", cfg.App.MemoryLimitMB)
 	}
-	a.config = cfg
 
 	// Initialize database with proper path validation
 	dbPath := cfg.Database.Path
 	if dbPath == "" {
 		dbPath = "data/fabric-monitor.db"
-		logger.Log("Warning: database path not set, using default: %s\n", dbPath)
+		logger.Warn("Database path not set, using default", "path", dbPath)
 	}
-	database, err := db.NewDatabase(dbPath, cfg.Database.EncryptionKey)
-	if err != nil {
-		logger.Log("Failed to initialize database: %v\n", err)
-	} else {
-		a.db = database
+	if cfg.App.DemoMode {
+		// Demo data lives in its own database so it never mixes with synced data or its replica
+		dbPath = demoDatabasePath(dbPath)
+		cfg.Database.EnableReadOnlyReplica = false
+	}
+	a.openDatabase(dbPath)
+	if a.syncer() == nil {
+		a.currentSyncer.Store(syncer.New(a.db(), a.syncStatus))
 	}
+	if cfg.App.DemoMode {
+		a.seedDemoData()
+	}
+	a.notifier = newNotifier(cfg.Notifications)
+	recordNotifications(a.notifier, a.db())
+	applyNotificationMutes(a.notifier, a.db())
+	applyWorkspaceRouting(a.notifier, a.db())
 
 	// Use Microsoft PowerShell public client ID for user authentication (no app registration needed)
 	// This client ID has http://localhost redirect URIs pre-registered
-	if cfg.Auth.ClientID == "" || cfg.Auth.ClientID == "your-client-id-here" {
+	if cfg.Auth.ClientID == "" || cfg.Auth.ClientID == placeholderClientID {
 		cfg.Auth.ClientID = "1950a258-227b-4e31-a9cf-717495945fc2" // Microsoft PowerShell public client
 	}
 
@@ -90,66 +175,87 @@ func (a *App) startup(ctx context.Context) {
 		ClientID:    cfg.Auth.ClientID,
 		TenantID:    cfg.Auth.TenantID,
 		RedirectURI: cfg.Auth.RedirectURI,
-		Scopes:      []string{"https://analysis.windows.net/powerbi/api/.default"},
+		Scopes:      auth.FabricScopes,
 	}
 
 	authManager, err := auth.NewAuthManager(authConfig)
 	if err != nil {
-		logger.Log("Failed to initialize auth: %v\n", err)
+		logger.Error("Failed to initialize auth", logger.Err(err))
 	} else {
 		a.auth = authManager
 
 		// Try to restore existing session from cache
 		if token, err := a.auth.GetToken(ctx); err == nil {
-			logger.Log("Restored authentication from cache\n")
+			logger.Info("Restored authentication from cache")
 			a.currentToken = token
-			a.fabricClient = fabric.NewClient(token.AccessToken)
+			a.fabricClient = newFabricClient(a.config(), token.AccessToken)
 		} else {
-			logger.Log("No cached authentication found: %v\n", err)
+			logger.Info("No cached authentication found", logger.Err(err))
 		}
 	}
 
 	// Start Parquet export on startup
-	a.StartParquetExport()
+	a.scheduleParquetExport(true)
+
+	// Keep data fresh between manual refreshes
+	a.startPoller()
+	a.startWebhookListener()
+	a.startNotificationFlusher()
+	a.startDigestScheduler()
+	a.startConfigWatcher()
 }
 
 // shutdown is called when the app is closing
 func (a *App) shutdown(ctx context.Context) {
-	logger.Log("Shutting down application...\n")
+	logger.Info("Shutting down application")
+
+	// Stop syncs, enrichment and exports, then give them a moment to finish before the database goes away
+	if a.cancel != nil {
+		a.cancel()
+	}
+	if !a.background.Close(shutdownGracePeriod) {
+		logger.Warn("Background work still running, closing database anyway", "gracePeriod", shutdownGracePeriod)
+	}
 
 	// Close database connection
-	if a.db != nil {
-		if err := a.db.Close(); err != nil {
-			logger.Log("Error closing database: %v\n", err)
+	if a.db() != nil {
+		if err := a.db().Close(); err != nil {
+			logger.Error("Failed to close database", logger.Err(err))
 		} else {
-			logger.Log("Database connection closed successfully\n")
+			logger.Info("Database connection closed")
 		}
 	}
+	a.releaseInstanceLock()
 
 	// Clean up authentication if needed
 	if a.auth != nil {
 		// Auth cleanup is already handled by Logout if needed
-		logger.Log("Authentication cleanup complete\n")
+		logger.Info("Authentication cleanup complete")
 	}
 
-	logger.Log("Shutdown complete\n")
+	stopTracing()
+	stopErrorReporting()
+	logger.Info("Shutdown complete")
+	if err := logger.CloseFile(); err != nil {
+		logger.Error("Failed to close log file", logger.Err(err))
+	}
 }
 
 // Login initiates the authentication flow
-func (a *App) Login(tenantID string) map[string]interface{} {
+func (a *App) Login(tenantID string) api.LoginResult {
 	if a.auth == nil {
-		return map[string]interface{}{
-			"success": false,
-			"error":   "Authentication not initialized",
-		}
+		return api.LoginResult{Error: "Authentication not initialized"}
 	}
 
 	// Update tenant ID in config
-	a.config.Auth.TenantID = tenantID
+	a.updateConfig(func(cfg *config.Config) error {
+		cfg.Auth.TenantID = tenantID
+		return nil
+	})
 
 	// Use Microsoft PowerShell public client ID for user authentication
-	clientID := a.config.Auth.ClientID
-	if clientID == "" || clientID == "your-client-id-here" {
+	clientID := a.config().Auth.ClientID
+	if clientID == "" || clientID == placeholderClientID {
 		clientID = "1950a258-227b-4e31-a9cf-717495945fc2" // Microsoft PowerShell public client
 	}
 
@@ -157,68 +263,53 @@ func (a *App) Login(tenantID string) map[string]interface{} {
 	authConfig := &auth.AuthConfig{
 		ClientID:    clientID,
 		TenantID:    tenantID,
-		RedirectURI: a.config.Auth.RedirectURI,
-		Scopes:      []string{"https://analysis.windows.net/powerbi/api/.default"},
+		RedirectURI: a.config().Auth.RedirectURI,
+		Scopes:      auth.FabricScopes,
 	}
 
 	authManager, err := auth.NewAuthManager(authConfig)
 	if err != nil {
-		return map[string]interface{}{
-			"success": false,
-			"error":   fmt.Sprintf("Failed to initialize auth: %v", err),
-		}
+		return api.LoginResult{Error: fmt.Sprintf("Failed to initialize auth: %v", err)}
 	}
 	a.auth = authManager
 
 	// Start device code flow
 	deviceCodeInfo, err := a.auth.StartDeviceCodeFlow(a.ctx)
 	if err != nil {
-		return map[string]interface{}{
-			"success": false,
-			"error":   fmt.Sprintf("Failed to start login: %v", err),
-		}
+		return api.LoginResult{Error: fmt.Sprintf("Failed to start login: %v", err)}
 	}
 
 	// Return device code information to display in UI
-	return map[string]interface{}{
-		"success":         true,
-		"requiresCode":    true,
-		"userCode":        deviceCodeInfo.UserCode,
-		"verificationURL": deviceCodeInfo.VerificationURL,
-		"message":         deviceCodeInfo.Message,
+	return api.LoginResult{
+		Success:         true,
+		RequiresCode:    true,
+		UserCode:        deviceCodeInfo.UserCode,
+		VerificationURL: deviceCodeInfo.VerificationURL,
+		Message:         deviceCodeInfo.Message,
 	}
 }
 
 // CompleteLogin waits for the user to complete device code authentication
-func (a *App) CompleteLogin() map[string]interface{} {
+func (a *App) CompleteLogin() api.LoginResult {
 	if a.auth == nil {
-		return map[string]interface{}{
-			"success": false,
-			"error":   "Authentication not initialized",
-		}
+		return api.LoginResult{Error: "Authentication not initialized"}
 	}
 
 	// Complete the device code flow
 	token, err := a.auth.CompleteDeviceCodeFlow(a.ctx)
 	if err != nil {
-		return map[string]interface{}{
-			"success": false,
-			"error":   fmt.Sprintf("Login failed: %v", err),
-		}
+		return api.LoginResult{Error: fmt.Sprintf("Login failed: %v", err)}
 	}
 
 	// Store the token and initialize Fabric client
 	a.currentToken = token
-	a.fabricClient = fabric.NewClient(token.AccessToken)
-
-	return map[string]interface{}{
-		"success": true,
-		"user": map[string]interface{}{
-			"id":    "user-id",          // TODO: Extract from token
-			"name":  "User",             // TODO: Extract from token
-			"email": "user@example.com", // TODO: Extract from token
-		},
-		"token": token,
+	a.fabricClient = newFabricClient(a.config(), token.AccessToken)
+
+	user := a.GetUserInfo()
+	return api.LoginResult{
+		Success: true,
+		User:    &user,
+		Token:   token,
 	}
 }
 
@@ -226,6 +317,9 @@ func (a *App) CompleteLogin() map[string]interface{} {
 func (a *App) Logout() error {
 	a.currentToken = nil
 	a.fabricClient = nil
+	if err := a.SetOfflineMode(false); err != nil {
+		logger.Warn("Failed to leave offline mode", logger.Err(err))
+	}
 	if a.auth != nil {
 		return a.auth.Logout()
 	}
@@ -235,6 +329,10 @@ func (a *App) Logout() error {
 // ensureValidToken checks if the current token is valid and refreshes if needed
 // Returns error if token refresh fails (requires re-authentication)
 func (a *App) ensureValidToken() error {
+	if err := a.cacheOnly(); err != nil {
+		return err
+	}
+
 	// If no auth manager, cannot proceed
 	if a.auth == nil {
 		return fmt.Errorf("authentication not initialized")
@@ -246,25 +344,28 @@ func (a *App) ensureValidToken() error {
 		return nil
 	}
 
-	logger.Log("Token expired or about to expire, refreshing...\n")
+	logger.Info("Token expired or about to expire, refreshing")
 
 	// Try to refresh token silently
 	token, err := a.auth.GetToken(a.ctx)
 	if err != nil {
-		logger.Log("ERROR: Token refresh failed: %v\n", err)
+		logger.Error("Token refresh failed", logger.Err(err))
 		return fmt.Errorf("token refresh failed: %w", err)
 	}
 
 	// Update token and recreate Fabric client
 	a.currentToken = token
-	a.fabricClient = fabric.NewClient(token.AccessToken)
-	logger.Log("Token refreshed successfully, expires at: %s\n", token.ExpiresAt.Format(time.RFC3339))
+	a.fabricClient = newFabricClient(a.config(), token.AccessToken)
+	logger.Info("Token refreshed", "expiresAt", token.ExpiresAt.Format(time.RFC3339))
 
 	return nil
 }
 
 // IsAuthenticated checks if user is authenticated
 func (a *App) IsAuthenticated() bool {
+	if a.cacheOnly() != nil {
+		return true
+	}
 	if a.auth != nil {
 		return a.auth.IsAuthenticated()
 	}
@@ -272,565 +373,522 @@ func (a *App) IsAuthenticated() bool {
 }
 
 // GetUserInfo returns current user information
-func (a *App) GetUserInfo() map[string]interface{} {
-	return map[string]interface{}{
-		"id":    "user-id",
-		"name":  "User",
-		"email": "user@example.com",
+func (a *App) GetUserInfo() api.User {
+	return api.User{
+		ID:    "user-id",          // TODO: Extract from token
+		Name:  "User",             // TODO: Extract from token
+		Email: "user@example.com", // TODO: Extract from token
 	}
 }
 
 // GetWorkspaces returns available workspaces
-func (a *App) GetWorkspaces() []map[string]interface{} {
+func (a *App) GetWorkspaces() []api.Workspace {
+	if !a.background.Begin() {
+		return []api.Workspace{}
+	}
+	defer a.background.Done()
+
+	if a.cacheOnly() != nil {
+		return a.GetWorkspacesFromCache()
+	}
+
 	// Check and refresh token if needed
 	if err := a.ensureValidToken(); err != nil {
-		logger.Log("Authentication required: %v\n", err)
+		logger.Warn("Authentication required", logger.Err(err))
 		// Check if we have cached data
 		cachedWorkspaces := a.GetWorkspacesFromCache()
 		hasCachedData := len(cachedWorkspaces) > 0
 
 		if hasCachedData {
-			logger.Log("Loaded %d workspaces from cache (authentication expired)\n", len(cachedWorkspaces))
+			logger.Info("Loaded workspaces from cache (authentication expired)", "workspaces", len(cachedWorkspaces))
 			// Return cached data with error flag
-			return append([]map[string]interface{}{
-				{
-					"error":                 "authentication_required",
-					"message":               "Your session has expired. Please sign in again or continue with cached data.",
-					"cached_data_available": true,
-					"_is_error_marker":      true, // Special flag so frontend can filter this out
-				},
-			}, cachedWorkspaces...)
+			return append([]api.Workspace{api.AuthRequiredWorkspace(true)}, cachedWorkspaces...)
 		}
 
 		// No cached data, return error only
-		return []map[string]interface{}{
-			{
-				"error":                 "authentication_required",
-				"message":               "Your session has expired. Please sign in again.",
-				"cached_data_available": false,
-			},
-		}
+		return []api.Workspace{api.AuthRequiredWorkspace(false)}
 	}
 
 	// Get real workspaces from Fabric API
-	workspaces, err := a.fabricClient.GetWorkspaces(a.ctx)
+	workspaces, err := syncer.ScopedWorkspaces(a.ctx, a.fabricClient, a.workspaceScope())
 	if err != nil {
-		logger.Log("Failed to get workspaces from API: %v, checking cache...\n", err)
+		logger.Warn("Failed to get workspaces from API, checking cache", logger.Err(err))
 		// Try cache as fallback
 		cachedWorkspaces := a.GetWorkspacesFromCache()
 		if len(cachedWorkspaces) > 0 {
-			logger.Log("Loaded %d workspaces from cache as fallback\n", len(cachedWorkspaces))
+			logger.Info("Loaded workspaces from cache as fallback", "workspaces", len(cachedWorkspaces))
 			return cachedWorkspaces
 		}
 
-		return []map[string]interface{}{
-			{
-				"id":          "error",
-				"displayName": fmt.Sprintf("Error loading workspaces: %v", err),
-				"type":        "Error",
-			},
-		}
+		return []api.Workspace{{
+			ID:          "error",
+			DisplayName: fmt.Sprintf("Error loading workspaces: %v", err),
+			Type:        "Error",
+		}}
 	}
 
 	// Persist workspaces to DuckDB
-	if a.db != nil {
-		for _, ws := range workspaces {
-			dbWorkspace := &db.Workspace{
-				ID:          ws.ID,
-				DisplayName: ws.DisplayName,
-				Type:        ws.Type,
-			}
-			if ws.Description != "" {
-				dbWorkspace.Description = &ws.Description
-			}
-			if err := a.db.SaveWorkspace(dbWorkspace); err != nil {
-				logger.Log("Warning: failed to save workspace %s to database: %v\n", ws.ID, err)
-			}
-		}
-		logger.Log("Persisted %d workspaces to database\n", len(workspaces))
+	if a.writable() == nil {
+		a.syncer().SaveWorkspaces(workspaces)
 	}
 
-	// Convert to map format for frontend
-	result := make([]map[string]interface{}, 0, len(workspaces))
+	result := make([]api.Workspace, 0, len(workspaces))
 	for _, ws := range workspaces {
-		result = append(result, map[string]interface{}{
-			"id":          ws.ID,
-			"displayName": ws.DisplayName,
-			"type":        ws.Type,
-			"description": ws.Description,
-		})
+		result = append(result, api.WorkspaceFromFabric(ws))
 	}
 
 	return result
 }
 
-// GetJobs returns recent jobs
-func (a *App) GetJobs() []map[string]interface{} {
-	// Check and refresh token if needed
-	if err := a.ensureValidToken(); err != nil {
-		logger.Log("Authentication required: %v\n", err)
-		// Check if we have cached data
-		cachedJobs := a.GetJobsFromCache()
-		hasCachedData := len(cachedJobs) > 0
-
-		if hasCachedData {
-			logger.Log("Loaded %d jobs from cache (authentication expired)\n", len(cachedJobs))
-			// Return cached data with error flag
-			return append([]map[string]interface{}{
-				{
-					"error":                 "authentication_required",
-					"message":               "Your session has expired. Please sign in again or continue with cached data.",
-					"cached_data_available": true,
-					"_is_error_marker":      true, // Special flag so frontend can filter this out
-				},
-			}, cachedJobs...)
-		}
+// workspaceScope builds the workspace scope from the current configuration
+func (a *App) workspaceScope() fabric.WorkspaceScope {
+	return workspaceScope(a.config())
+}
 
-		// No cached data, return error only
-		return []map[string]interface{}{
-			{
-				"error":                 "authentication_required",
-				"message":               "Your session has expired. Please sign in again.",
-				"cached_data_available": false,
-			},
-		}
-	}
+// workspaceScope builds the workspace scope from the configured allowlist, name patterns and personal workspace setting
+func workspaceScope(cfg *config.Config) fabric.WorkspaceScope {
+	scope := fabric.NewWorkspaceScope(
+		cfg.Fabric.WorkspaceIDs,
+		cfg.Fabric.IncludeWorkspaces,
+		cfg.Fabric.ExcludeWorkspaces,
+	)
+	scope.SkipPersonal = !cfg.Fabric.IncludePersonal
+	return scope
+}
 
-	// Get real workspaces first
-	workspaces, err := a.fabricClient.GetWorkspaces(a.ctx)
+// applyTLS sets the TLS settings of the HTTPS clients created from now on as configured in cfg
+// Invalid settings are logged and the clients keep Go's defaults
+func applyTLS(cfg config.TLSConfig) {
+	err := tlsconfig.Set(tlsconfig.Options{
+		CAFiles:            cfg.CAFiles,
+		MinVersion:         cfg.MinVersion,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+	})
 	if err != nil {
-		logger.Log("Failed to get workspaces for jobs: %v\n", err)
-		return []map[string]interface{}{}
-	}
-
-	// Persist workspaces to database first (needed for foreign key constraints)
-	logger.Log("DEBUG: a.db=%v, len(workspaces)=%d\n", a.db != nil, len(workspaces))
-	if a.db != nil && len(workspaces) > 0 {
-		for _, ws := range workspaces {
-			dbWorkspace := &db.Workspace{
-				ID:          ws.ID,
-				DisplayName: ws.DisplayName,
-				Type:        ws.Type,
-			}
-			if ws.Description != "" {
-				dbWorkspace.Description = &ws.Description
-			}
-			if err := a.db.SaveWorkspace(dbWorkspace); err != nil {
-				logger.Log("Warning: failed to save workspace %s to database: %v\n", ws.ID, err)
-			}
-		}
-		logger.Log("Persisted %d workspaces to database\n", len(workspaces))
-	} else {
-		logger.Log("Skipping workspace persistence: db=%v, workspaces=%d\n", a.db != nil, len(workspaces))
-	}
-
-	// Check for last sync time to enable incremental loading
-	// GetMaxJobStartTime returns either:
-	// - The MIN start_time of in-progress jobs (to re-check them for completion), OR
-	// - The MAX start_time of completed jobs (if no in-progress jobs exist)
-	var startTimeFrom *time.Time
-	var cachedItemsByWorkspace map[string][]fabric.Item
-	if a.db != nil {
-		maxStartTime, err := a.db.GetMaxJobStartTime()
-		if err == nil && maxStartTime != nil {
-			startTimeFrom = maxStartTime
-			logger.Log("Incremental load starting from: %s\n", maxStartTime.Format(time.RFC3339))
-
-			// For incremental syncs, load cached items from database to avoid API calls
-			cachedItemsByWorkspace = make(map[string][]fabric.Item)
-			for _, ws := range workspaces {
-				dbItems, err := a.db.GetItemsByWorkspace(ws.ID)
-				if err == nil && len(dbItems) > 0 {
-					// Convert db.Item to fabric.Item
-					fabricItems := make([]fabric.Item, 0, len(dbItems))
-					for _, dbItem := range dbItems {
-						fabricItem := fabric.Item{
-							ID:          dbItem.ID,
-							DisplayName: dbItem.DisplayName,
-							Type:        dbItem.Type,
-						}
-						if dbItem.Description != nil {
-							fabricItem.Description = *dbItem.Description
-						}
-						fabricItems = append(fabricItems, fabricItem)
-					}
-					cachedItemsByWorkspace[ws.ID] = fabricItems
-					logger.Log("Loaded %d cached items for workspace %s\n", len(fabricItems), ws.DisplayName)
-				}
-			}
-		} else {
-			logger.Log("No previous jobs found, doing full load")
-		}
+		logger.Error("Keeping the default TLS settings", logger.Err(err))
+		return
 	}
-	// Get recent jobs across all workspaces (no limit - return all)
-	// Pass startTimeFrom for incremental sync (will also fetch all in-progress jobs)
-	// Pass cachedItemsByWorkspace to avoid fetching items from API during incremental syncs
-	jobs, newItems, err := a.fabricClient.GetRecentJobs(a.ctx, workspaces, 0, startTimeFrom, cachedItemsByWorkspace)
-	if err != nil {
-		logger.Log("Failed to get jobs: %v\n", err)
-		return []map[string]interface{}{
-			{
-				"id":              "error",
-				"itemDisplayName": fmt.Sprintf("Error loading jobs: %v", err),
-				"status":          "Error",
-			},
-		}
+	if len(cfg.CAFiles) > 0 {
+		logger.Info("Trusting additional CA bundles", "files", len(cfg.CAFiles))
+	}
+	if cfg.InsecureSkipVerify {
+		logger.Warn("TLS certificate verification is disabled by tls.insecure_skip_verify")
 	}
+}
 
-	// Persist jobs to DuckDB
-	if a.db != nil && len(jobs) > 0 {
-		// First, persist any new items from the API (for full syncs or new items discovered)
-		if len(newItems) > 0 {
-			for _, fabricItem := range newItems {
-				dbItem := db.Item{
-					ID:          fabricItem.ID,
-					WorkspaceID: fabricItem.WorkspaceID,
-					DisplayName: fabricItem.DisplayName,
-					Type:        fabricItem.Type,
-				}
-				if fabricItem.Description != "" {
-					dbItem.Description = &fabricItem.Description
-				}
-				if err := a.db.SaveItem(&dbItem); err != nil {
-					logger.Log("Warning: failed to save new item %s to database: %v\n", dbItem.ID, err)
-				}
-			}
-			logger.Log("Persisted %d new items from API to database\n", len(newItems))
-		}
+// applyFabricURLs points the deep links to Fabric at the portal host and URL templates configured in cfg
+// Invalid settings are logged and the links keep their previous host and templates
+func applyFabricURLs(cfg config.FabricConfig) {
+	if err := utils.SetFabricURLs(cfg.PortalHost, cfg.LinkTemplates); err != nil {
+		logger.Warn("Keeping the default Fabric links", logger.Err(err))
+	}
+}
 
-		// Also persist all unique items that these jobs reference (to satisfy foreign key constraints)
-		itemsMap := make(map[string]db.Item)
-		for _, job := range jobs {
-			itemID := job["itemId"].(string)
-			if _, exists := itemsMap[itemID]; !exists {
-				item := db.Item{
-					ID:          itemID,
-					WorkspaceID: job["workspaceId"].(string),
-					DisplayName: job["itemDisplayName"].(string),
-					Type:        job["itemType"].(string),
-				}
-				itemsMap[itemID] = item
-			}
-		}
+// newFabricClient creates an API client with the concurrency, rate and retry limits configured in cfg
+// Limits out of bounds are clamped, with a warning naming the values used instead
+func newFabricClient(cfg *config.Config, accessToken string) *fabric.Client {
+	configured := fabricLimits(cfg.Fabric.Limits)
+	if bounded := configured.Bounded(); bounded != configured {
+		logger.Warn("Clamped fabric.limits to supported bounds", "workspaceConcurrency", bounded.WorkspaceConcurrency,
+			"itemConcurrency", bounded.ItemConcurrency, "initialRps", bounded.InitialRPS, "minRps", bounded.MinRPS,
+			"maxRps", bounded.MaxRPS, "maxRetries", bounded.MaxRetries)
+	}
+	return fabric.NewClientWithLimits(accessToken, configured)
+}
 
-		// Save all items referenced by jobs
-		for _, item := range itemsMap {
-			if err := a.db.SaveItem(&item); err != nil {
-				logger.Log("Warning: failed to save item %s to database: %v\n", item.ID, err)
-			}
-		}
-		logger.Log("Persisted %d unique items from jobs to database\n", len(itemsMap))
-
-		// Now persist job instances
-		dbJobs := make([]db.JobInstance, 0, len(jobs))
-		for _, job := range jobs {
-			// Parse start time
-			startTime, err := time.Parse(time.RFC3339, job["startTime"].(string))
-			if err != nil {
-				logger.Log("Warning: failed to parse start time: %v\n", err)
-				continue
-			}
+// fabricLimits returns the client limits configured by cfg, before they are bounded
+func fabricLimits(cfg config.LimitsConfig) fabric.Limits {
+	return fabric.Limits{
+		WorkspaceConcurrency: cfg.WorkspaceConcurrency,
+		ItemConcurrency:      cfg.ItemConcurrency,
+		InitialRPS:           cfg.InitialRPS,
+		MinRPS:               cfg.MinRPS,
+		MaxRPS:               cfg.MaxRPS,
+		MaxRetries:           cfg.MaxRetries,
+	}
+}
 
-			dbJob := db.JobInstance{
-				ID:          job["id"].(string),
-				WorkspaceID: job["workspaceId"].(string),
-				ItemID:      job["itemId"].(string),
-				JobType:     job["jobType"].(string),
-				Status:      job["status"].(string),
-				StartTime:   startTime,
-			}
+// syncOptions returns the sync options shared by the app and the sync command
+func syncOptions(cfg *config.Config) syncer.Options {
+	return syncer.Options{
+		Scope:             workspaceScope(cfg),
+		ExcludedItemTypes: cfg.Fabric.ExcludedItemTypes,
+		ItemCacheTTL:      cfg.Fabric.ItemCacheTTL,
+		StaleJobAfter:     cfg.Fabric.StaleJobAfter,
+		MaxLookback:       time.Duration(cfg.Fabric.MaxLookbackDays) * 24 * time.Hour,
+		Polling: syncer.PollPolicy{
+			MinInterval: cfg.Polling.Interval,
+			MaxInterval: cfg.Polling.MaxInterval,
+		},
+		LongRunning: syncer.LongRunningPolicy{
+			Threshold: cfg.Notifications.LongRunningThreshold,
+			Factor:    cfg.Notifications.LongRunningFactor,
+		},
+		StuckFactor: cfg.Notifications.StuckFactor,
+	}
+}
 
-			// Parse end time if present
-			if endTimeStr, ok := job["endTime"].(string); ok && endTimeStr != "" {
-				if endTime, err := time.Parse(time.RFC3339, endTimeStr); err == nil {
-					dbJob.EndTime = &endTime
-				}
-			}
+// WorkspaceScopeSettings holds the workspace scope rules editable from the UI
+type WorkspaceScopeSettings struct {
+	WorkspaceIDs      []string `json:"workspaceIds"`
+	IncludeWorkspaces []string `json:"includeWorkspaces"`
+	ExcludeWorkspaces []string `json:"excludeWorkspaces"`
+	IncludePersonal   bool     `json:"includePersonal"`
+}
 
-			// Duration
-			if durationMs, ok := job["durationMs"].(int64); ok {
-				dbJob.DurationMs = &durationMs
-			}
+// GetWorkspaceScope returns the workspace allowlist, include/exclude name patterns and the personal workspace toggle
+func (a *App) GetWorkspaceScope() WorkspaceScopeSettings {
+	return WorkspaceScopeSettings{
+		WorkspaceIDs:      a.config().Fabric.WorkspaceIDs,
+		IncludeWorkspaces: a.config().Fabric.IncludeWorkspaces,
+		ExcludeWorkspaces: a.config().Fabric.ExcludeWorkspaces,
+		IncludePersonal:   a.config().Fabric.IncludePersonal,
+	}
+}
 
-			// Failure reason
-			if failureReason, ok := job["failureReason"].(string); ok && failureReason != "" {
-				dbJob.FailureReason = &failureReason
-			}
+// SaveWorkspaceScope validates and persists workspace scope rules; they apply from the next sync
+func (a *App) SaveWorkspaceScope(settings WorkspaceScopeSettings) error {
+	scope := fabric.NewWorkspaceScope(settings.WorkspaceIDs, settings.IncludeWorkspaces, settings.ExcludeWorkspaces)
+	if err := fabric.ValidatePatterns(scope.Include); err != nil {
+		return err
+	}
+	if err := fabric.ValidatePatterns(scope.Exclude); err != nil {
+		return err
+	}
 
-			// Root activity ID
-			if rootActivityId, ok := job["rootActivityId"].(string); ok && rootActivityId != "" {
-				dbJob.RootActivityID = &rootActivityId
-			}
+	err := a.updateConfig(func(cfg *config.Config) error {
+		cfg.Fabric.WorkspaceIDs = scope.IDs
+		cfg.Fabric.IncludeWorkspaces = scope.Include
+		cfg.Fabric.ExcludeWorkspaces = scope.Exclude
+		cfg.Fabric.IncludePersonal = settings.IncludePersonal
+		return cfg.Save()
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save workspace scope: %w", err)
+	}
+	logger.Info("Workspace scope updated", "ids", len(scope.IDs), "includePatterns", len(scope.Include),
+		"excludePatterns", len(scope.Exclude), "includePersonal", settings.IncludePersonal)
+	return nil
+}
 
-			dbJobs = append(dbJobs, dbJob)
-		}
+// ItemTypeFilterSettings describes which job-capable item types are synced
+type ItemTypeFilterSettings struct {
+	SupportedItemTypes []string `json:"supportedItemTypes"`
+	ExcludedItemTypes  []string `json:"excludedItemTypes"`
+}
 
-		if len(dbJobs) > 0 {
-			if err := a.db.SaveJobInstances(dbJobs); err != nil {
-				logger.Log("Warning: failed to save jobs to database: %v\n", err)
-			} else {
-				if startTimeFrom != nil {
-					logger.Log("Persisted %d new/updated job instances to database (incremental)\n", len(dbJobs))
-				} else {
-					logger.Log("Persisted %d job instances to database (full sync)\n", len(dbJobs))
-				}
-				// Record sync metadata
-				if err := a.db.UpdateSyncMetadata("job_instances", len(dbJobs), 0); err != nil {
-					logger.Log("Warning: failed to update sync metadata: %v\n", err)
-				}
-			}
-		}
+// GetItemTypeFilters returns the item types that can be synced and those currently turned off
+func (a *App) GetItemTypeFilters() ItemTypeFilterSettings {
+	return ItemTypeFilterSettings{
+		SupportedItemTypes: fabric.SupportedJobItemTypes,
+		ExcludedItemTypes:  a.config().Fabric.ExcludedItemTypes,
 	}
+}
 
-	// After all jobs are persisted, fetch activity runs for completed DataPipeline jobs
-	// This blocks until enrichment completes to ensure child executions are available when UI loads
-	// We do this AFTER the persistence block to ensure all jobs are committed to the database
-	if a.db != nil {
-		// Sync notebook sessions to get livyID for notebook deep links
-		// This runs synchronously to ensure all livyIDs are available before UI loads
-		// Run unconditionally during incremental refresh to backfill historical notebooks
-		if len(jobs) > 0 || startTimeFrom != nil {
-			if err := a.SyncNotebookSessions(); err != nil {
-				logger.Log("Warning: failed to sync notebook sessions: %v\n", err)
+// SaveItemTypeFilters persists the item types to skip during sync; they apply from the next sync
+func (a *App) SaveItemTypeFilters(excludedItemTypes []string) error {
+	excluded := make([]string, 0, len(excludedItemTypes))
+	for _, itemType := range excludedItemTypes {
+		supported := false
+		for _, t := range fabric.SupportedJobItemTypes {
+			if t == itemType {
+				supported = true
+				break
 			}
 		}
-
-		if len(jobs) > 0 {
-			a.enrichPipelineJobsWithActivityRuns()
+		if !supported {
+			return fmt.Errorf("unsupported item type: %s", itemType)
 		}
+		excluded = append(excluded, itemType)
 	}
 
-	// If doing incremental sync, get cached jobs AFTER enrichment to ensure fresh activity_runs data
-	var cachedJobs []map[string]interface{}
-	if startTimeFrom != nil && a.db != nil {
-		cachedJobs = a.GetJobsFromCache()
+	err := a.updateConfig(func(cfg *config.Config) error {
+		cfg.Fabric.ExcludedItemTypes = excluded
+		return cfg.Save()
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save item type filters: %w", err)
 	}
+	logger.Info("Item type filters updated", "excludedTypes", len(excluded))
+	return nil
+}
 
-	// Add Fabric deep link URLs to jobs
-	if a.db != nil {
-		// Now get livyIDs from database and add Fabric deep link URLs to jobs
-		jobIDs := make([]string, 0, len(jobs)+len(cachedJobs))
-		for _, job := range jobs {
-			if jobID, ok := job["id"].(string); ok {
-				jobIDs = append(jobIDs, jobID)
-			}
-		}
-		// Include cached notebook jobs so we can regenerate their URLs with fresh Livy data
-		for _, cachedJob := range cachedJobs {
-			if jobID, ok := cachedJob["id"].(string); ok {
-				if itemType, ok := cachedJob["itemType"].(string); ok && itemType == "Notebook" {
-					jobIDs = append(jobIDs, jobID)
-				}
-			}
-		}
+// StartSync runs a sync in the background and returns immediately
+// Progress and results are pushed to the frontend as sync:* events
+// Returns false if a sync started this way is already running
+func (a *App) StartSync() bool {
+	return a.startSync(false)
+}
 
-		livyIDMap := make(map[string]string)
-		if len(jobIDs) > 0 {
-			var err error
-			livyIDMap, err = a.db.GetLivyIDsByJobInstanceIDs(jobIDs)
-			if err != nil {
-				logger.Log("Warning: failed to get livyIDs from database: %v\n", err)
-			}
-		}
+// startSync runs a full or due-only sync in the background unless one is already running
+func (a *App) startSync(dueOnly bool) bool {
+	a.syncMutex.Lock()
+	if a.syncActive {
+		a.syncMutex.Unlock()
+		logger.Info("Sync already in progress, skipping")
+		return false
+	}
+	a.syncActive = true
+	a.syncMutex.Unlock()
 
-		// Add Fabric deep link URLs to all jobs
-		for i := range jobs {
-			job := jobs[i]
-			workspaceID, _ := job["workspaceId"].(string)
-			itemID, _ := job["itemId"].(string)
-			itemType, _ := job["itemType"].(string)
-			jobID, _ := job["id"].(string)
-
-			// Check if we have a livyID for this job
-			var livyIDPtr *string
-			if livyID, exists := livyIDMap[jobID]; exists && livyID != "" {
-				livyIDPtr = &livyID
-			}
+	started := a.background.Go(func() {
+		defer func() {
+			a.syncMutex.Lock()
+			a.syncActive = false
+			a.syncMutex.Unlock()
+		}()
+		a.syncJobs(dueOnly)
+	})
+	if !started {
+		a.syncMutex.Lock()
+		a.syncActive = false
+		a.syncMutex.Unlock()
+	}
 
-			fabricURL := utils.GenerateFabricURL(workspaceID, itemID, itemType, jobID, livyIDPtr)
-			if fabricURL != "" {
-				jobs[i]["fabricUrl"] = fabricURL
-			}
-		}
+	return started
+}
 
-		// Regenerate URLs for cached notebook jobs with fresh Livy data
-		for i := range cachedJobs {
-			cachedJob := cachedJobs[i]
-			itemType, _ := cachedJob["itemType"].(string)
-			if itemType != "Notebook" {
-				continue
-			}
+// CancelSync cancels the running sync
+// Work that already finished is persisted; the sync stops before the next phase
+// Returns false if no sync was running
+func (a *App) CancelSync() bool {
+	a.syncMutex.Lock()
+	cancel := a.syncCancel
+	a.syncMutex.Unlock()
+
+	if cancel == nil {
+		return false
+	}
 
-			workspaceID, _ := cachedJob["workspaceId"].(string)
-			itemID, _ := cachedJob["itemId"].(string)
-			jobID, _ := cachedJob["id"].(string)
+	logger.Info("Sync cancellation requested")
+	cancel()
+	return true
+}
 
-			// Check if we have a livyID for this cached job
-			var livyIDPtr *string
-			if livyID, exists := livyIDMap[jobID]; exists && livyID != "" {
-				livyIDPtr = &livyID
-			}
+// beginSyncContext creates the cancellable context driving a sync and registers it for CancelSync
+func (a *App) beginSyncContext() (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(a.ctx)
 
-			fabricURL := utils.GenerateFabricURL(workspaceID, itemID, itemType, jobID, livyIDPtr)
-			if fabricURL != "" {
-				cachedJobs[i]["fabricUrl"] = fabricURL
-			}
-		}
+	a.syncMutex.Lock()
+	a.syncCancel = cancel
+	a.syncMutex.Unlock()
+
+	return ctx, func() {
+		a.syncMutex.Lock()
+		a.syncCancel = nil
+		a.syncMutex.Unlock()
+		cancel()
 	}
+}
 
-	// If doing incremental sync, merge with cached data to get complete view
-	if startTimeFrom != nil && a.db != nil && len(cachedJobs) > 0 {
-		logger.Log("Merging fresh jobs with cached historical data...")
+// syncJobs syncs jobs from the API into the cache
+// It only runs through startSync, which keeps a single sync running at a time
+// With dueOnly set, only workspaces whose adaptive poll is due are visited
+func (a *App) syncJobs(dueOnly bool) {
+	if !a.background.Begin() {
+		return
+	}
+	defer a.background.Done()
 
-		// Create a map of fresh jobs by ID for quick lookup
-		freshJobMap := make(map[string]map[string]interface{})
-		for _, job := range jobs {
-			if id, ok := job["id"].(string); ok {
-				freshJobMap[id] = job
-			}
-		}
+	if errors.Is(a.writable(), errReadOnly) {
+		logger.Info("Read-only: skipping sync")
+		return
+	}
+	if a.cacheOnly() != nil {
+		return
+	}
 
-		// Start with fresh jobs (these have the latest data)
-		mergedJobs := make([]map[string]interface{}, 0, len(cachedJobs))
-		mergedJobs = append(mergedJobs, jobs...)
+	syncCtx, endSync := a.beginSyncContext()
+	defer endSync()
 
-		// Add cached jobs that aren't in the fresh results
-		for _, cachedJob := range cachedJobs {
-			if id, ok := cachedJob["id"].(string); ok {
-				if _, exists := freshJobMap[id]; !exists {
-					mergedJobs = append(mergedJobs, cachedJob)
-				}
-			}
+	syncStart := time.Now()
+	a.syncStatus.Begin()
+	a.emitEvent(EventSyncStarted, map[string]interface{}{
+		"startedAt": syncStart.Format(time.RFC3339),
+	})
+
+	// Check and refresh token if needed
+	if err := a.ensureValidToken(); err != nil {
+		logger.Warn("Authentication required", logger.Err(err))
+		a.syncStatus.Finish(err)
+		hasCachedData := len(a.GetJobsFromCache()) > 0
+		a.emitEvent(EventSyncFailed, map[string]interface{}{
+			"error":               "authentication_required",
+			"message":             api.AuthRequiredMessage(hasCachedData),
+			"cachedDataAvailable": hasCachedData,
+		})
+		return
+	}
+
+	opts := syncOptions(a.config())
+	opts.AppVersion = a.GetAppVersion()
+	opts.DueOnly = dueOnly
+	opts.OnJobFailed = func(job api.Job) {
+		a.emitEvent(EventJobFailed, job)
+		a.notifyJobFailed(job)
+	}
+	if a.config().Notifications.Enabled && a.config().Notifications.OnLongRunning {
+		opts.OnJobLongRunning = func(job syncer.LongRunningJob) {
+			a.emitEvent(EventJobLongRunning, job.Job)
+			a.playAlertSound(notify.EventJobLongRunning, job.Job)
+			a.sendNotification(notify.JobLongRunningEvent(job.Job, job.Elapsed, job.Baseline))
+		}
+	}
+	if a.config().Notifications.Enabled && a.config().Notifications.OnStuck {
+		opts.OnJobStuck = func(job syncer.LongRunningJob) {
+			a.emitEvent(EventJobStuck, job.Job)
+			a.playAlertSound(notify.EventJobStuck, job.Job)
+			a.sendNotification(notify.JobStuckEvent(job.Job, job.Elapsed, job.Baseline))
 		}
+	}
+	result, err := a.syncer().Run(syncCtx, a.fabricClient, opts)
+	if err != nil {
+		logger.Error("Sync failed", logger.SyncRunID(a.syncStatus.Snapshot().SyncRunID), logger.Err(err))
+		a.syncStatus.Finish(err)
+		a.emitEvent(EventSyncFailed, map[string]interface{}{
+			"error": err.Error(),
+		})
+		a.sendNotification(notify.SyncFailedEvent(err))
+		return
+	}
 
-		logger.Log("Total jobs after merge: %d (fresh: %d, cached: %d, replaced: %d)\n",
-			len(mergedJobs), len(jobs), len(cachedJobs), len(freshJobMap))
+	// Refresh the Parquet replica if the sync wrote data (debounced by the export interval)
+	a.scheduleParquetExport(result.JobsFetched > 0)
 
-		// Trigger Parquet export after data sync
-		a.StartParquetExport()
+	a.emitSyncCompleted(syncCtx, syncStart, result)
+	a.publishRunningJobs()
+}
 
-		return mergedJobs
+// emitSyncCompleted marks the sync as finished and publishes sync:completed, or sync:cancelled if it was cancelled
+func (a *App) emitSyncCompleted(syncCtx context.Context, syncStart time.Time, result *syncer.Result) {
+	payload := map[string]interface{}{
+		"jobsFetched": result.JobsFetched,
+		"totalJobs":   len(result.Jobs),
+		"incremental": result.Incremental,
+		"durationMs":  time.Since(syncStart).Milliseconds(),
+		"warnings":    result.Warnings,
+		"syncRunId":   result.SyncRunID,
+	}
+	a.syncStatus.SetWarnings(result.Warnings)
+
+	if syncCtx.Err() != nil {
+		a.syncStatus.Cancel()
+		a.emitEvent(EventSyncCancelled, payload)
+		return
 	}
 
-	// Trigger Parquet export after data sync
-	a.StartParquetExport()
+	a.syncStatus.Finish(nil)
+	payload["lastSync"] = a.GetLastSyncTime()
+	a.emitEvent(EventSyncCompleted, payload)
+}
 
-	return jobs
+// GetSyncStatus returns the progress of the running sync, or the outcome of the last one
+func (a *App) GetSyncStatus() SyncStatus {
+	return a.syncStatus.Snapshot()
 }
 
-// GetJobsFromCache retrieves jobs from the local DuckDB cache
-func (a *App) GetJobsFromCache() []map[string]interface{} {
-	if a.db == nil {
-		return []map[string]interface{}{}
+// SyncItem immediately re-pulls job instances for one item, bypassing the full sync
+// New and changed runs are persisted, then Livy sessions (notebooks) or activity runs (pipelines) are refreshed
+func (a *App) SyncItem(workspaceID, itemID string) api.ItemSyncResult {
+	if err := a.apiAvailable(); err != nil {
+		return api.ItemSyncResult{Error: err.Error()}
+	}
+	if !a.background.Begin() {
+		return api.ItemSyncResult{Error: errShuttingDown.Error()}
+	}
+	defer a.background.Done()
+	if err := a.ensureValidToken(); err != nil {
+		return api.ItemSyncResult{Error: "authentication_required"}
 	}
 
-	// Get all jobs from database
-	filter := db.JobFilter{}
-	jobs, err := a.db.GetJobInstances(filter)
+	updated, err := a.syncer().SyncItem(a.ctx, a.fabricClient, workspaceID, itemID)
 	if err != nil {
-		logger.Log("Failed to get jobs from cache: %v\n", err)
-		return []map[string]interface{}{}
+		logger.Error("SyncItem failed", logger.WorkspaceID(workspaceID), logger.ItemID(itemID), logger.Err(err))
+		return api.ItemSyncResult{Error: err.Error()}
 	}
+	a.publishRunningJobs()
 
-	// Convert to map format for frontend
-	result := make([]map[string]interface{}, 0, len(jobs))
+	result := api.ItemSyncResult{JobsUpdated: updated, Jobs: []api.Job{}}
+	jobs, err := a.db().GetJobInstances(db.JobFilter{ItemID: &itemID})
+	if err != nil {
+		result.Error = fmt.Sprintf("Failed to read jobs: %v", err)
+		return result
+	}
 	for _, job := range jobs {
-		jobMap := map[string]interface{}{
-			"id":          job.ID,
-			"workspaceId": job.WorkspaceID,
-			"itemId":      job.ItemID,
-			"jobType":     job.JobType,
-			"status":      job.Status,
-			"startTime":   job.StartTime.Format(time.RFC3339),
-		}
-
-		// Add item display name and type from the joined data
-		if job.ItemDisplayName != nil {
-			jobMap["itemDisplayName"] = *job.ItemDisplayName
-		} else {
-			jobMap["itemDisplayName"] = job.ItemID // Fallback to ID if name not available
-		}
-
-		var itemType string
-		if job.ItemType != nil {
-			jobMap["itemType"] = *job.ItemType
-			itemType = *job.ItemType
-		} else {
-			jobMap["itemType"] = job.JobType // Fallback to job type
-			itemType = job.JobType
-		}
+		result.Jobs = append(result.Jobs, api.JobFromDB(job))
+	}
+	return result
+}
 
-		// Add workspace name from the joined data
-		if job.WorkspaceName != nil {
-			jobMap["workspaceName"] = *job.WorkspaceName
-		}
+// RefreshItemCache discards cached item lists so the next sync lists items from the API again
+// An empty workspaceID expires the cache for every workspace
+func (a *App) RefreshItemCache(workspaceID string) error {
+	if err := a.writable(); err != nil {
+		return err
+	}
+	if err := a.db().ExpireItemDiscovery(workspaceID); err != nil {
+		return fmt.Errorf("failed to expire item cache: %w", err)
+	}
+	logger.Info("Item cache expired", logger.WorkspaceID(workspaceID))
+	return nil
+}
 
-		if job.EndTime != nil {
-			jobMap["endTime"] = job.EndTime.Format(time.RFC3339)
-		}
-		if job.DurationMs != nil {
-			jobMap["durationMs"] = *job.DurationMs
-		}
-		if job.FailureReason != nil {
-			jobMap["failureReason"] = *job.FailureReason
-		}
-		if job.RootActivityID != nil {
-			jobMap["rootActivityId"] = *job.RootActivityID
-		}
+// defaultSyncMetricsLimit is how many sync runs GetSyncMetrics returns when no limit is given
+const defaultSyncMetricsLimit = 50
 
-		// Generate Fabric deep link URL
-		fabricURL := utils.GenerateFabricURL(job.WorkspaceID, job.ItemID, itemType, job.ID, job.LivyID)
-		if fabricURL != "" {
-			jobMap["fabricUrl"] = fabricURL
-		}
+// GetSyncMetrics returns performance metrics of the most recent sync runs, newest first
+func (a *App) GetSyncMetrics(limit int) api.SyncMetricsResult {
+	if a.db() == nil {
+		return api.SyncMetricsResult{Error: "Database not initialized"}
+	}
+	if limit <= 0 {
+		limit = defaultSyncMetricsLimit
+	}
 
-		result = append(result, jobMap)
+	metrics, err := a.db().GetSyncMetrics(limit)
+	if err != nil {
+		return api.SyncMetricsResult{Error: fmt.Sprintf("Failed to get sync metrics: %v", err)}
+	}
+	if metrics == nil {
+		metrics = []db.SyncMetrics{}
 	}
+	return api.SyncMetricsResult{Metrics: metrics}
+}
 
-	logger.Log("Loaded %d jobs from cache\n", len(result))
-	return result
+// GetJobsFromCache retrieves jobs from the local DuckDB cache
+func (a *App) GetJobsFromCache() []api.Job {
+	return a.syncer().CachedJobs()
 }
 
 // GetWorkspacesFromCache retrieves workspaces from the local DuckDB cache
-func (a *App) GetWorkspacesFromCache() []map[string]interface{} {
-	if a.db == nil {
-		return []map[string]interface{}{}
+func (a *App) GetWorkspacesFromCache() []api.Workspace {
+	if a.db() == nil {
+		return []api.Workspace{}
 	}
 
 	// Get all workspaces from database
-	workspaces, err := a.db.GetWorkspaces()
+	workspaces, err := a.db().GetWorkspaces()
 	if err != nil {
-		logger.Log("Failed to get workspaces from cache: %v\n", err)
-		return []map[string]interface{}{}
+		logger.Error("Failed to get workspaces from cache", logger.Err(err))
+		return []api.Workspace{}
 	}
 
-	// Convert to map format for frontend
-	result := make([]map[string]interface{}, 0, len(workspaces))
+	result := make([]api.Workspace, 0, len(workspaces))
 	for _, ws := range workspaces {
-		wsMap := map[string]interface{}{
-			"id":          ws.ID,
-			"displayName": ws.DisplayName,
-			"type":        ws.Type,
-		}
-
-		if ws.Description != nil {
-			wsMap["description"] = *ws.Description
-		}
-
-		result = append(result, wsMap)
+		result = append(result, api.WorkspaceFromDB(ws))
 	}
 
-	logger.Log("Loaded %d workspaces from cache\n", len(result))
+	logger.Debug("Loaded workspaces from cache", "workspaces", len(result))
 	return result
 }
 
 // GetLastSyncTime returns the last time data was synced from the API
 func (a *App) GetLastSyncTime() string {
-	if a.db == nil {
+	if a.db() == nil {
 		return ""
 	}
 
-	lastSync, err := a.db.GetLastSyncTime("job_instances")
+	lastSync, err := a.db().GetLastSyncTime("job_instances")
 	if err != nil || lastSync == nil {
 		return ""
 	}
@@ -838,931 +896,666 @@ func (a *App) GetLastSyncTime() string {
 	return lastSync.Format(time.RFC3339)
 }
 
-// StartParquetExport triggers an async export of tables to Parquet format
-func (a *App) StartParquetExport() {
-	// Skip if feature is disabled
-	if !a.config.Database.EnableReadOnlyReplica {
-		return
-	}
-
-	// Skip if database is not initialized
-	if a.db == nil {
-		return
+// analyticsWorkspaceIDs applies the personal workspace setting to an analytics workspace filter
+// Unless personal workspaces are included, they are removed from the filter, and an empty filter
+// (all workspaces) becomes every cached non-personal workspace
+func (a *App) analyticsWorkspaceIDs(workspaceIDs []string) []string {
+	if a.config().Fabric.IncludePersonal || a.db() == nil {
+		return workspaceIDs
 	}
 
-	// Check if an export is already running
-	a.parquetExportMutex.Lock()
-	if a.parquetExportActive {
-		a.parquetExportMutex.Unlock()
-		logger.Log("[PARQUET] Export already in progress, skipping\n")
-		return
+	workspaces, err := a.db().GetWorkspaces()
+	if err != nil {
+		logger.Error("Failed to read workspaces for analytics filter", logger.Err(err))
+		return workspaceIDs
 	}
-	a.parquetExportActive = true
-	a.parquetExportMutex.Unlock()
-
-	// Run export in goroutine to avoid blocking
-	go func() {
-		defer func() {
-			a.parquetExportMutex.Lock()
-			a.parquetExportActive = false
-			a.parquetExportMutex.Unlock()
-		}()
-
-		logger.Log("[PARQUET] Starting export to Parquet files...\n")
-		startTime := time.Now()
-
-		// Export all tables to Parquet
-		stats, err := a.db.ExportTablesToParquet(a.config.Database.ParquetPath)
-		if err != nil {
-			logger.Log("[PARQUET] ERROR: Export failed: %v\n", err)
-			return
-		}
 
-		// Log export statistics
-		totalRecords := 0
-		successCount := 0
-		for _, stat := range stats {
-			if stat.Success {
-				successCount++
-				totalRecords += stat.RecordCount
-			}
+	personal := make(map[string]bool)
+	var nonPersonal []string
+	for _, ws := range workspaces {
+		if ws.Type == fabric.PersonalWorkspaceType {
+			personal[ws.ID] = true
+		} else {
+			nonPersonal = append(nonPersonal, ws.ID)
 		}
+	}
+	if len(personal) == 0 {
+		return workspaceIDs
+	}
+	if len(workspaceIDs) == 0 {
+		return nonPersonal
+	}
 
-		logger.Log("[PARQUET] Export completed: %d/%d tables successful, %d total records in %dms\n",
-			successCount, len(stats), totalRecords, time.Since(startTime).Milliseconds())
-
-		// Create or verify read-only database
-		if err := db.CreateReadOnlyDatabase(a.config.Database.ReadOnlyPath, a.config.Database.ParquetPath); err != nil {
-			logger.Log("[PARQUET] ERROR: Failed to create read-only database: %v\n", err)
-			return
+	filtered := make([]string, 0, len(workspaceIDs))
+	for _, id := range workspaceIDs {
+		if !personal[id] {
+			filtered = append(filtered, id)
 		}
-
-		logger.Log("[PARQUET] Read-only replica ready at: %s\n", a.config.Database.ReadOnlyPath)
-	}()
+	}
+	if len(filtered) == 0 {
+		// Only personal workspaces were selected; an empty filter would mean all of them again
+		return nonPersonal
+	}
+	return filtered
 }
 
-// GetAnalytics returns comprehensive analytics data for the dashboard
-func (a *App) GetAnalytics(days int) map[string]interface{} {
-	if a.db == nil {
-		return map[string]interface{}{
-			"error": "Database not initialized",
-		}
+// GetAnalytics returns comprehensive analytics data for the dashboard, over the default workspaces of the settings
+// days of 0 or less covers the default analytics window
+func (a *App) GetAnalytics(days int) api.Analytics {
+	if a.db() == nil {
+		return api.Analytics{Error: "Database not initialized"}
 	}
 
 	if days <= 0 {
-		days = 7 // Default to 7 days
+		days = a.defaultAnalyticsDays()
+	}
+
+	// Personal workspaces are left out through the workspace filter
+	if workspaceIDs := a.analyticsWorkspaceIDs(a.config().UI.DefaultWorkspaceIDs); len(workspaceIDs) > 0 {
+		return a.GetAnalyticsFiltered(days, workspaceIDs, nil, "", false)
 	}
 
-	result := make(map[string]interface{})
+	result := api.Analytics{Days: days}
+	var err error
 
 	// Get daily stats
-	dailyStats, err := a.db.GetDailyStats(days)
-	if err != nil {
-		logger.Log("Failed to get daily stats: %v\n", err)
-		result["dailyStatsError"] = err.Error()
-	} else {
-		result["dailyStats"] = dailyStats
+	if result.DailyStats, err = a.db().GetDailyStats(days); err != nil {
+		logger.Error("Failed to get daily stats", logger.Err(err))
+		result.DailyStatsError = err.Error()
 	}
 
 	// Get workspace stats
-	workspaceStats, err := a.db.GetWorkspaceStats(days)
-	if err != nil {
-		logger.Log("Failed to get workspace stats: %v\n", err)
-		result["workspaceStatsError"] = err.Error()
-	} else {
-		result["workspaceStats"] = workspaceStats
+	if result.WorkspaceStats, err = a.db().GetWorkspaceStats(days); err != nil {
+		logger.Error("Failed to get workspace stats", logger.Err(err))
+		result.WorkspaceStatsError = err.Error()
 	}
 
 	// Get item type stats
-	itemTypeStats, err := a.db.GetItemTypeStats(days)
-	if err != nil {
-		logger.Log("Failed to get item type stats: %v\n", err)
-		result["itemTypeStatsError"] = err.Error()
-	} else {
-		result["itemTypeStats"] = itemTypeStats
+	if result.ItemTypeStats, err = a.db().GetItemTypeStats(days); err != nil {
+		logger.Error("Failed to get item type stats", logger.Err(err))
+		result.ItemTypeStatsError = err.Error()
 	}
 
 	// Get recent failures (last 10 within the time period)
-	recentFailures, err := a.db.GetRecentFailures(10, days)
-	if err != nil {
-		logger.Log("Failed to get recent failures: %v\n", err)
-		result["recentFailuresError"] = err.Error()
+	if recentFailures, err := a.db().GetRecentFailures(10, days); err != nil {
+		logger.Error("Failed to get recent failures", logger.Err(err))
+		result.RecentFailuresError = err.Error()
 	} else {
-		// Add Fabric URLs to failures
-		failuresWithURLs := make([]map[string]interface{}, 0, len(recentFailures))
-		for _, failure := range recentFailures {
-			failureMap := map[string]interface{}{
-				"id":              failure.ID,
-				"workspaceId":     failure.WorkspaceID,
-				"workspaceName":   failure.WorkspaceName,
-				"itemId":          failure.ItemID,
-				"itemDisplayName": failure.ItemDisplayName,
-				"itemType":        failure.ItemType,
-				"jobType":         failure.JobType,
-				"startTime":       failure.StartTime.Format(time.RFC3339),
-				"endTime":         failure.EndTime.Format(time.RFC3339),
-				"durationMs":      failure.DurationMs,
-				"failureReason":   failure.FailureReason,
-			}
-
-			fabricURL := utils.GenerateFabricURL(failure.WorkspaceID, failure.ItemID, failure.ItemType, failure.ID, failure.LivyID)
-			if fabricURL != "" {
-				failureMap["fabricUrl"] = fabricURL
-			}
-
-			failuresWithURLs = append(failuresWithURLs, failureMap)
-		}
-		result["recentFailures"] = failuresWithURLs
+		result.RecentFailures = api.RecentFailuresFromDB(recentFailures)
 	}
 
 	// Get long-running jobs (50% or more above average, last 10)
-	longRunningJobs, err := a.db.GetLongRunningJobs(days, 50.0, 10)
-	if err != nil {
-		logger.Log("Failed to get long-running jobs: %v\n", err)
-		result["longRunningJobsError"] = err.Error()
+	if longRunningJobs, err := a.db().GetLongRunningJobs(days, 50.0, 10); err != nil {
+		logger.Error("Failed to get long-running jobs", logger.Err(err))
+		result.LongRunningJobsError = err.Error()
 	} else {
-		// Add Fabric URLs to long-running jobs
-		jobsWithURLs := make([]map[string]interface{}, 0, len(longRunningJobs))
-		for _, job := range longRunningJobs {
-			jobMap := map[string]interface{}{
-				"id":              job.ID,
-				"workspaceId":     job.WorkspaceID,
-				"workspaceName":   job.WorkspaceName,
-				"itemId":          job.ItemID,
-				"itemDisplayName": job.ItemDisplayName,
-				"itemType":        job.ItemType,
-				"jobType":         job.JobType,
-				"startTime":       job.StartTime.Format(time.RFC3339),
-				"durationMs":      job.DurationMs,
-				"avgDurationMs":   job.AvgDurationMs,
-				"deviationPct":    job.DeviationPct,
-			}
+		result.LongRunningJobs = api.LongRunningJobsFromDB(longRunningJobs)
+	}
 
-			fabricURL := utils.GenerateFabricURL(job.WorkspaceID, job.ItemID, job.ItemType, job.ID, job.LivyID)
-			if fabricURL != "" {
-				jobMap["fabricUrl"] = fabricURL
-			}
+	// Get items failing repeatedly right now, regardless of the time period
+	if result.FailureStreaks, err = a.db().GetFailureStreaks(minAnalyticsFailureStreak, 10, nil, nil, "", false); err != nil {
+		logger.Error("Failed to get failure streaks", logger.Err(err))
+		result.FailureStreaksError = err.Error()
+	}
 
-			jobsWithURLs = append(jobsWithURLs, jobMap)
-		}
-		result["longRunningJobs"] = jobsWithURLs
+	// Get the most frequent error codes of failed runs and activities
+	if result.TopErrorCodes, err = a.db().GetTopErrorCodes(days, 10, nil, nil, "", false); err != nil {
+		logger.Error("Failed to get top error codes", logger.Err(err))
+		result.TopErrorCodesError = err.Error()
 	}
 
 	// Get overall stats - calculated entirely in DuckDB for consistency
-	overallStats, err := a.db.GetOverallStats(days)
-	if err != nil {
-		logger.Log("Failed to get overall stats: %v\n", err)
-		result["overallStatsError"] = err.Error()
-	} else {
-		result["overallStats"] = map[string]interface{}{
-			"totalJobs":     overallStats.TotalJobs,
-			"successful":    overallStats.Successful,
-			"failed":        overallStats.Failed,
-			"running":       overallStats.Running,
-			"successRate":   overallStats.SuccessRate,
-			"avgDurationMs": overallStats.AvgDurationMs,
-		}
+	if result.OverallStats, err = a.db().GetOverallStats(days); err != nil {
+		logger.Error("Failed to get overall stats", logger.Err(err))
+		result.OverallStatsError = err.Error()
 	}
 
-	result["days"] = days
-
 	return result
 }
 
+// minAnalyticsFailureStreak is the shortest failure streak listed on the analytics dashboard
+const minAnalyticsFailureStreak = 2
+
 // GetAnalyticsFiltered returns comprehensive analytics data with optional filters
-func (a *App) GetAnalyticsFiltered(days int, workspaceIDs []string, itemTypes []string, itemNameSearch string) map[string]interface{} {
-	if a.db == nil {
-		return map[string]interface{}{
-			"error": "Database not initialized",
-		}
+// days of 0 or less covers the default analytics window; favoritesOnly narrows it to starred items and workspaces
+func (a *App) GetAnalyticsFiltered(days int, workspaceIDs []string, itemTypes []string, itemNameSearch string, favoritesOnly bool) api.Analytics {
+	if a.db() == nil {
+		return api.Analytics{Error: "Database not initialized"}
 	}
 
 	if days <= 0 {
-		days = 7 // Default to 7 days
+		days = a.defaultAnalyticsDays()
 	}
 
-	result := make(map[string]interface{})
+	result := api.Analytics{Days: days}
+	var err error
+	workspaceIDs = a.analyticsWorkspaceIDs(workspaceIDs)
 
 	// Get daily stats
-	dailyStats, err := a.db.GetDailyStatsFiltered(days, workspaceIDs, itemTypes, itemNameSearch)
-	if err != nil {
-		logger.Log("Failed to get daily stats: %v\n", err)
-		result["dailyStatsError"] = err.Error()
-	} else {
-		result["dailyStats"] = dailyStats
+	if result.DailyStats, err = a.db().GetDailyStatsFiltered(days, workspaceIDs, itemTypes, itemNameSearch, favoritesOnly); err != nil {
+		logger.Error("Failed to get daily stats", logger.Err(err))
+		result.DailyStatsError = err.Error()
 	}
 
 	// Get workspace stats
-	workspaceStats, err := a.db.GetWorkspaceStatsFiltered(days, workspaceIDs, itemTypes, itemNameSearch)
-	if err != nil {
-		logger.Log("Failed to get workspace stats: %v\n", err)
-		result["workspaceStatsError"] = err.Error()
-	} else {
-		result["workspaceStats"] = workspaceStats
+	if result.WorkspaceStats, err = a.db().GetWorkspaceStatsFiltered(days, workspaceIDs, itemTypes, itemNameSearch, favoritesOnly); err != nil {
+		logger.Error("Failed to get workspace stats", logger.Err(err))
+		result.WorkspaceStatsError = err.Error()
 	}
 
 	// Get item type stats
-	itemTypeStats, err := a.db.GetItemTypeStatsFiltered(days, workspaceIDs, itemTypes, itemNameSearch)
-	if err != nil {
-		logger.Log("Failed to get item type stats: %v\n", err)
-		result["itemTypeStatsError"] = err.Error()
-	} else {
-		result["itemTypeStats"] = itemTypeStats
+	if result.ItemTypeStats, err = a.db().GetItemTypeStatsFiltered(days, workspaceIDs, itemTypes, itemNameSearch, favoritesOnly); err != nil {
+		logger.Error("Failed to get item type stats", logger.Err(err))
+		result.ItemTypeStatsError = err.Error()
 	}
 
 	// Get recent failures (last 10 within the time period)
-	recentFailures, err := a.db.GetRecentFailuresFiltered(10, days, workspaceIDs, itemTypes, itemNameSearch)
-	if err != nil {
-		logger.Log("Failed to get recent failures: %v\n", err)
-		result["recentFailuresError"] = err.Error()
+	if recentFailures, err := a.db().GetRecentFailuresFiltered(10, days, workspaceIDs, itemTypes, itemNameSearch, favoritesOnly); err != nil {
+		logger.Error("Failed to get recent failures", logger.Err(err))
+		result.RecentFailuresError = err.Error()
 	} else {
-		// Add Fabric URLs to failures
-		failuresWithURLs := make([]map[string]interface{}, 0, len(recentFailures))
-		for _, failure := range recentFailures {
-			failureMap := map[string]interface{}{
-				"id":              failure.ID,
-				"workspaceId":     failure.WorkspaceID,
-				"workspaceName":   failure.WorkspaceName,
-				"itemId":          failure.ItemID,
-				"itemDisplayName": failure.ItemDisplayName,
-				"itemType":        failure.ItemType,
-				"jobType":         failure.JobType,
-				"startTime":       failure.StartTime.Format(time.RFC3339),
-				"endTime":         failure.EndTime.Format(time.RFC3339),
-				"durationMs":      failure.DurationMs,
-				"failureReason":   failure.FailureReason,
-			}
-
-			fabricURL := utils.GenerateFabricURL(failure.WorkspaceID, failure.ItemID, failure.ItemType, failure.ID, failure.LivyID)
-			if fabricURL != "" {
-				failureMap["fabricUrl"] = fabricURL
-			}
-
-			failuresWithURLs = append(failuresWithURLs, failureMap)
-		}
-		result["recentFailures"] = failuresWithURLs
+		result.RecentFailures = api.RecentFailuresFromDB(recentFailures)
 	}
 
 	// Get long-running jobs (50% or more above average, last 10)
-	longRunningJobs, err := a.db.GetLongRunningJobsFiltered(days, 50.0, 10, workspaceIDs, itemTypes, itemNameSearch)
-	if err != nil {
-		logger.Log("Failed to get long-running jobs: %v\n", err)
-		result["longRunningJobsError"] = err.Error()
+	if longRunningJobs, err := a.db().GetLongRunningJobsFiltered(days, 50.0, 10, workspaceIDs, itemTypes, itemNameSearch, favoritesOnly); err != nil {
+		logger.Error("Failed to get long-running jobs", logger.Err(err))
+		result.LongRunningJobsError = err.Error()
 	} else {
-		// Add Fabric URLs to long-running jobs
-		jobsWithURLs := make([]map[string]interface{}, 0, len(longRunningJobs))
-		for _, job := range longRunningJobs {
-			jobMap := map[string]interface{}{
-				"id":              job.ID,
-				"workspaceId":     job.WorkspaceID,
-				"workspaceName":   job.WorkspaceName,
-				"itemId":          job.ItemID,
-				"itemDisplayName": job.ItemDisplayName,
-				"itemType":        job.ItemType,
-				"jobType":         job.JobType,
-				"startTime":       job.StartTime.Format(time.RFC3339),
-				"durationMs":      job.DurationMs,
-				"avgDurationMs":   job.AvgDurationMs,
-				"deviationPct":    job.DeviationPct,
-			}
+		result.LongRunningJobs = api.LongRunningJobsFromDB(longRunningJobs)
+	}
 
-			fabricURL := utils.GenerateFabricURL(job.WorkspaceID, job.ItemID, job.ItemType, job.ID, job.LivyID)
-			if fabricURL != "" {
-				jobMap["fabricUrl"] = fabricURL
-			}
+	// Get items failing repeatedly right now, regardless of the time period
+	if result.FailureStreaks, err = a.db().GetFailureStreaks(minAnalyticsFailureStreak, 10, workspaceIDs, itemTypes, itemNameSearch, favoritesOnly); err != nil {
+		logger.Error("Failed to get failure streaks", logger.Err(err))
+		result.FailureStreaksError = err.Error()
+	}
 
-			jobsWithURLs = append(jobsWithURLs, jobMap)
-		}
-		result["longRunningJobs"] = jobsWithURLs
+	// Get the most frequent error codes of failed runs and activities
+	if result.TopErrorCodes, err = a.db().GetTopErrorCodes(days, 10, workspaceIDs, itemTypes, itemNameSearch, favoritesOnly); err != nil {
+		logger.Error("Failed to get top error codes", logger.Err(err))
+		result.TopErrorCodesError = err.Error()
 	}
 
 	// Get overall stats - calculated entirely in DuckDB for consistency
-	overallStats, err := a.db.GetOverallStatsFiltered(days, workspaceIDs, itemTypes, itemNameSearch)
-	if err != nil {
-		logger.Log("Failed to get overall stats: %v\n", err)
-		result["overallStatsError"] = err.Error()
-	} else {
-		result["overallStats"] = map[string]interface{}{
-			"totalJobs":     overallStats.TotalJobs,
-			"successful":    overallStats.Successful,
-			"failed":        overallStats.Failed,
-			"running":       overallStats.Running,
-			"successRate":   overallStats.SuccessRate,
-			"avgDurationMs": overallStats.AvgDurationMs,
-		}
+	if result.OverallStats, err = a.db().GetOverallStatsFiltered(days, workspaceIDs, itemTypes, itemNameSearch, favoritesOnly); err != nil {
+		logger.Error("Failed to get overall stats", logger.Err(err))
+		result.OverallStatsError = err.Error()
 	}
 
-	result["days"] = days
-
 	return result
 }
 
-// GetAvailableItemTypes returns distinct item types that have job data
-func (a *App) GetAvailableItemTypes(days int, workspaceIDs []string) []string {
-	if a.db == nil {
-		return []string{}
+// GetRecoveryStats returns the mean and median time to recovery per item and per workspace for failures
+// started in the last days: the time from a run failing after a success to the item's next successful run
+func (a *App) GetRecoveryStats(days int, workspaceIDs []string, itemTypes []string, itemNameSearch string) api.RecoveryStatsResult {
+	if a.db() == nil {
+		return api.RecoveryStatsResult{Error: "Database not initialized"}
 	}
 
 	if days <= 0 {
-		days = 7
+		days = 30
 	}
+	workspaceIDs = a.analyticsWorkspaceIDs(workspaceIDs)
 
-	itemTypes, err := a.db.GetAvailableItemTypes(days, workspaceIDs)
+	items, err := a.db().GetItemRecoveryStats(days, workspaceIDs, itemTypes, itemNameSearch)
 	if err != nil {
-		logger.Log("Failed to get available item types: %v\n", err)
-		return []string{}
+		return api.RecoveryStatsResult{Error: fmt.Sprintf("Failed to get item recovery stats: %v", err)}
+	}
+	workspaces, err := a.db().GetWorkspaceRecoveryStats(days, workspaceIDs, itemTypes, itemNameSearch)
+	if err != nil {
+		return api.RecoveryStatsResult{Error: fmt.Sprintf("Failed to get workspace recovery stats: %v", err)}
 	}
 
-	return itemTypes
+	result := api.RecoveryStatsResult{Items: items, Workspaces: workspaces}
+	if result.Items == nil {
+		result.Items = []db.RecoveryStats{}
+	}
+	if result.Workspaces == nil {
+		result.Workspaces = []db.RecoveryStats{}
+	}
+	return result
 }
 
-// GetItemStatsByWorkspace returns item-level statistics for a specific workspace
-func (a *App) GetItemStatsByWorkspace(workspaceID string, days int) map[string]interface{} {
-	if a.db == nil {
-		return map[string]interface{}{
-			"error": "Database not initialized",
-		}
+// GetRunHeatmap returns the runs started in the last days and their failure rate by weekday and hour
+// of the local time zone, so the busiest and most failure-prone batch windows stand out
+func (a *App) GetRunHeatmap(days int, workspaceIDs []string, itemTypes []string, itemNameSearch string) api.RunHeatmapResult {
+	if a.db() == nil {
+		return api.RunHeatmapResult{Error: "Database not initialized"}
 	}
 
 	if days <= 0 {
-		days = 7
+		days = 30
 	}
 
-	itemStats, err := a.db.GetItemStatsByWorkspace(workspaceID, days)
+	cells, err := a.db().GetRunHeatmap(days, time.Local, a.analyticsWorkspaceIDs(workspaceIDs), itemTypes, itemNameSearch)
 	if err != nil {
-		return map[string]interface{}{
-			"error": err.Error(),
-		}
-	}
-
-	return map[string]interface{}{
-		"items": itemStats,
-		"days":  days,
+		return api.RunHeatmapResult{Error: fmt.Sprintf("Failed to get run heatmap: %v", err)}
 	}
+	zone, _ := time.Now().Zone()
+	return api.RunHeatmapResult{Days: days, Timezone: zone, Cells: cells}
 }
 
-// GetItemStatsByJobType returns item-level statistics for a specific job type
-func (a *App) GetItemStatsByJobType(itemType string, days int) map[string]interface{} {
-	if a.db == nil {
-		return map[string]interface{}{
-			"error": "Database not initialized",
-		}
+// GetActivityStats aggregates the activity runs of pipeline runs started in the last days per pipeline and
+// activity (average and P90 duration, share of the run time, trend), so the activity behind a slow
+// pipeline can be singled out
+func (a *App) GetActivityStats(days int, workspaceIDs []string, itemTypes []string, itemNameSearch string) api.ActivityStatsResult {
+	if a.db() == nil {
+		return api.ActivityStatsResult{Error: "Database not initialized"}
 	}
 
 	if days <= 0 {
-		days = 7
+		days = 30
 	}
 
-	itemStats, err := a.db.GetItemStatsByJobType(itemType, days)
+	activities, err := a.db().GetActivityStats(days, a.analyticsWorkspaceIDs(workspaceIDs), itemTypes, itemNameSearch)
 	if err != nil {
-		return map[string]interface{}{
-			"error": err.Error(),
-		}
+		return api.ActivityStatsResult{Error: fmt.Sprintf("Failed to get activity stats: %v", err)}
 	}
-
-	return map[string]interface{}{
-		"items": itemStats,
-		"days":  days,
+	if activities == nil {
+		activities = []db.ActivityStats{}
 	}
+	return api.ActivityStatsResult{Activities: activities}
 }
 
-// GetItemStatsByDate returns item-level statistics for a specific date with optional filters
-func (a *App) GetItemStatsByDate(date string, workspaceIDs []string, itemTypes []string, itemNameSearch string) map[string]interface{} {
-	if a.db == nil {
-		return map[string]interface{}{
-			"error": "Database not initialized",
-		}
+// GetPipelineActivityStats aggregates the activity runs of one pipeline's runs started in the last days per
+// activity (runs, failure rate, average and P90 duration), for the activity health table of the item detail view
+func (a *App) GetPipelineActivityStats(pipelineItemID string, days int) api.ActivityStatsResult {
+	if a.db() == nil {
+		return api.ActivityStatsResult{Error: "Database not initialized"}
+	}
+	if pipelineItemID == "" {
+		return api.ActivityStatsResult{Error: "Pipeline item ID is required"}
 	}
 
-	if date == "" {
-		return map[string]interface{}{
-			"error": "Date is required",
-		}
+	if days <= 0 {
+		days = 30
 	}
 
-	itemStats, err := a.db.GetItemStatsByDate(date, workspaceIDs, itemTypes, itemNameSearch)
+	activities, err := a.db().GetPipelineActivityStats(pipelineItemID, days)
 	if err != nil {
-		return map[string]interface{}{
-			"error": err.Error(),
-		}
+		return api.ActivityStatsResult{Error: fmt.Sprintf("Failed to get activity stats: %v", err)}
 	}
-
-	return map[string]interface{}{
-		"items": itemStats,
-		"date":  date,
+	if activities == nil {
+		activities = []db.ActivityStats{}
 	}
+	return api.ActivityStatsResult{Activities: activities}
 }
 
-// enrichPipelineJobsWithActivityRuns fetches activity runs for completed pipeline jobs
-// This runs in the background to avoid blocking the main sync process
-// Uses parallel processing with worker pools for scalability
-func (a *App) enrichPipelineJobsWithActivityRuns() {
-	if a.db == nil {
-		return
+// GetCopyThroughput aggregates the Copy activity runs of pipeline runs started in the last days per pipeline
+// and activity: rows and bytes read and written, throughput and its trend, and queuing versus transfer time
+func (a *App) GetCopyThroughput(days int, workspaceIDs []string, itemTypes []string, itemNameSearch string) api.CopyThroughputResult {
+	if a.db() == nil {
+		return api.CopyThroughputResult{Error: "Database not initialized"}
 	}
 
-	// Get all completed pipeline jobs without activity runs (removed LIMIT)
-	query := `
-		SELECT j.id, j.workspace_id, j.start_time, j.end_time
-		FROM job_instances j
-		LEFT JOIN items i ON j.item_id = i.id
-		WHERE i.type = 'DataPipeline'
-			AND j.end_time IS NOT NULL
-			AND j.activity_runs IS NULL
-		ORDER BY j.start_time DESC
-	`
+	if days <= 0 {
+		days = 30
+	}
 
-	rows, err := a.db.GetConnection().Query(query)
+	activities, err := a.db().GetCopyThroughput(days, a.analyticsWorkspaceIDs(workspaceIDs), itemTypes, itemNameSearch)
 	if err != nil {
-		logger.Log("Failed to query pipeline jobs for activity runs: %v\n", err)
-		return
+		return api.CopyThroughputResult{Error: fmt.Sprintf("Failed to get copy throughput: %v", err)}
+	}
+	if activities == nil {
+		activities = []db.CopyThroughputStats{}
 	}
-	defer rows.Close()
+	return api.CopyThroughputResult{Activities: activities}
+}
+
+// topErrorCodesLimit caps the error codes GetTopErrorCodes returns
+const topErrorCodesLimit = 50
 
-	type pipelineJob struct {
-		ID          string
-		WorkspaceID string
-		StartTime   time.Time
-		EndTime     time.Time
+// GetTopErrorCodes aggregates the error codes of failed runs and failed pipeline activities started in
+// the last days, with their counts, affected items and first and last occurrence, most frequent first
+func (a *App) GetTopErrorCodes(days int, workspaceIDs []string, itemTypes []string, itemNameSearch string) api.TopErrorCodesResult {
+	if a.db() == nil {
+		return api.TopErrorCodesResult{Error: "Database not initialized"}
 	}
 
-	var jobs []pipelineJob
-	for rows.Next() {
-		var job pipelineJob
-		if err := rows.Scan(&job.ID, &job.WorkspaceID, &job.StartTime, &job.EndTime); err != nil {
-			logger.Log("Failed to scan pipeline job: %v\n", err)
-			continue
-		}
-		jobs = append(jobs, job)
+	if days <= 0 {
+		days = 7
 	}
 
-	if len(jobs) == 0 {
-		return
+	codes, err := a.db().GetTopErrorCodes(days, topErrorCodesLimit, a.analyticsWorkspaceIDs(workspaceIDs), itemTypes, itemNameSearch, false)
+	if err != nil {
+		return api.TopErrorCodesResult{Error: fmt.Sprintf("Failed to get top error codes: %v", err)}
 	}
+	return api.TopErrorCodesResult{ErrorCodes: codes}
+}
 
-	logger.Log("Fetching activity runs for %d pipeline jobs in parallel...\n", len(jobs))
-	startTime := time.Now()
+// GetAvailableItemTypes returns distinct item types that have job data
+func (a *App) GetAvailableItemTypes(days int, workspaceIDs []string) []string {
+	if a.db() == nil {
+		return []string{}
+	}
 
-	// Create worker pool for parallel processing (limit to 20 concurrent requests)
-	pool := fabric.NewWorkerPool(20)
+	if days <= 0 {
+		days = 7
+	}
 
-	// Channel to collect results
-	type jobResult struct {
-		jobID         string
-		activityRuns  []db.ActivityRun
-		err           error
-		activityCount int
+	itemTypes, err := a.db().GetAvailableItemTypes(days, a.analyticsWorkspaceIDs(workspaceIDs))
+	if err != nil {
+		logger.Error("Failed to get available item types", logger.Err(err))
+		return []string{}
 	}
-	results := make(chan jobResult, len(jobs))
 
-	// Process each job in parallel
-	for _, job := range jobs {
-		job := job // Capture for goroutine
+	return itemTypes
+}
 
-		pool.Submit(a.ctx, func() error {
-			result := jobResult{jobID: job.ID}
+// GetItemStatsByWorkspace returns item-level statistics for a specific workspace
+func (a *App) GetItemStatsByWorkspace(workspaceID string, days int) api.ItemStatsResult {
+	if a.db() == nil {
+		return api.ItemStatsResult{Error: "Database not initialized"}
+	}
 
-			// Add some buffer time before and after the job run
-			startTime := job.StartTime.Add(-1 * time.Minute)
-			endTime := job.EndTime.Add(1 * time.Minute)
+	if days <= 0 {
+		days = 7
+	}
 
-			activityRuns, err := a.fabricClient.QueryActivityRuns(a.ctx, job.WorkspaceID, job.ID, startTime, endTime)
-			if err != nil {
-				result.err = err
-				results <- result
-				return nil
-			}
+	itemStats, err := a.db().GetItemStatsByWorkspace(workspaceID, days)
+	if err != nil {
+		return api.ItemStatsResult{Error: err.Error()}
+	}
 
-			result.activityCount = len(activityRuns)
-
-			// Convert fabric.ActivityRun to db.ActivityRun
-			dbActivityRuns := make([]db.ActivityRun, len(activityRuns))
-			for i, ar := range activityRuns {
-				dbActivityRuns[i] = db.ActivityRun{
-					PipelineID:              ar.PipelineID,
-					PipelineRunID:           ar.PipelineRunID,
-					ActivityName:            ar.ActivityName,
-					ActivityType:            ar.ActivityType,
-					ActivityRunID:           ar.ActivityRunID,
-					Status:                  ar.Status,
-					ActivityRunStart:        ar.ActivityRunStart,
-					ActivityRunEnd:          ar.ActivityRunEnd,
-					DurationInMs:            ar.DurationInMs,
-					Input:                   ar.Input,
-					Output:                  ar.Output,
-					Error:                   db.ActivityError(ar.Error),
-					RetryAttempt:            ar.RetryAttempt,
-					IterationHash:           ar.IterationHash,
-					UserProperties:          ar.UserProperties,
-					RecoveryStatus:          ar.RecoveryStatus,
-					IntegrationRuntimeNames: ar.IntegrationRuntimeNames,
-					ExecutionDetails:        ar.ExecutionDetails,
-				}
-			}
+	return api.ItemStatsResult{Items: itemStats, Days: days}
+}
 
-			result.activityRuns = dbActivityRuns
-			results <- result
-			return nil
-		})
+// GetItemStatsByJobType returns item-level statistics for a specific job type
+func (a *App) GetItemStatsByJobType(itemType string, days int) api.ItemStatsResult {
+	if a.db() == nil {
+		return api.ItemStatsResult{Error: "Database not initialized"}
 	}
 
-	// Wait for all jobs to complete
-	pool.Wait()
-	close(results)
+	if days <= 0 {
+		days = 7
+	}
 
-	// Process results and save to database
-	successCount := 0
-	errorCount := 0
-	totalActivities := 0
+	itemStats, err := a.db().GetItemStatsByJobType(itemType, days)
+	if err != nil {
+		return api.ItemStatsResult{Error: err.Error()}
+	}
 
-	for result := range results {
-		if result.err != nil {
-			logger.Log("Failed to fetch activity runs for job %s: %v\n", result.jobID, result.err)
-			errorCount++
-			// Do NOT mark as processed - leave activity_runs as NULL so it can be retried
-			// This allows the job to be re-enriched on the next sync
-			continue
-		}
+	return api.ItemStatsResult{Items: itemStats, Days: days}
+}
 
-		// Save activity runs (even if empty array - this is a valid result)
-		if err := a.db.UpdateJobInstanceActivityRuns(result.jobID, result.activityRuns); err != nil {
-			logger.Log("Failed to save activity runs for job %s: %v\n", result.jobID, err)
-			errorCount++
-			continue
-		}
+// GetItemStatsByDate returns item-level statistics for a specific date with optional filters
+func (a *App) GetItemStatsByDate(date string, workspaceIDs []string, itemTypes []string, itemNameSearch string) api.DailyItemStatsResult {
+	if a.db() == nil {
+		return api.DailyItemStatsResult{Error: "Database not initialized"}
+	}
 
-		successCount++
-		totalActivities += result.activityCount
+	if date == "" {
+		return api.DailyItemStatsResult{Error: "Date is required"}
 	}
 
-	elapsed := time.Since(startTime)
-	logger.Log("Activity runs sync completed in %v\n", elapsed)
-	logger.Log("Successfully fetched activity runs for %d/%d pipeline jobs (%d activities, %d errors)\n",
-		successCount, len(jobs), totalActivities, errorCount)
+	itemStats, err := a.db().GetItemStatsByDate(date, workspaceIDs, itemTypes, itemNameSearch)
+	if err != nil {
+		return api.DailyItemStatsResult{Error: err.Error()}
+	}
+
+	return api.DailyItemStatsResult{Items: itemStats, Date: date}
 }
 
 // GetJobInstanceWithActivities retrieves a job instance with its activity runs
-func (a *App) GetJobInstanceWithActivities(jobID string) map[string]interface{} {
-	if a.db == nil {
-		return map[string]interface{}{
-			"error": "Database not initialized",
-		}
+func (a *App) GetJobInstanceWithActivities(jobID string) api.JobWithActivitiesResult {
+	if a.db() == nil {
+		return api.JobWithActivitiesResult{Error: "Database not initialized"}
 	}
 
-	job, err := a.db.GetJobInstanceWithActivities(jobID)
+	job, err := a.db().GetJobInstanceWithActivities(jobID)
 	if err != nil {
-		return map[string]interface{}{
-			"error": fmt.Sprintf("Failed to get job: %v", err),
-		}
+		return api.JobWithActivitiesResult{Error: fmt.Sprintf("Failed to get job: %v", err)}
 	}
 
-	return map[string]interface{}{
-		"job": job,
-	}
+	return api.JobWithActivitiesResult{Job: job}
 }
 
-// GetChildExecutions retrieves child pipeline and notebook executions for a job
-func (a *App) GetChildExecutions(jobID string) map[string]interface{} {
-	if a.db == nil {
-		return map[string]interface{}{
-			"error": "Database not initialized",
-		}
+// GetJobDetail returns the full job record with its failure payload parsed into a tree
+// Jobs synced before failure payloads were stored only have the flattened failureReason message
+func (a *App) GetJobDetail(jobID string) api.JobDetailResult {
+	if a.db() == nil {
+		return api.JobDetailResult{Error: "Database not initialized"}
 	}
 
-	children, err := a.db.GetChildExecutions(jobID)
+	job, err := a.db().GetJobInstanceWithActivities(jobID)
 	if err != nil {
-		return map[string]interface{}{
-			"error": fmt.Sprintf("Failed to get child executions: %v", err),
-		}
+		return api.JobDetailResult{Error: fmt.Sprintf("Failed to get job: %v", err)}
 	}
 
-	// Convert to map format and add Fabric URLs
-	childrenMaps := make([]map[string]interface{}, 0, len(children))
-	for _, child := range children {
-		childMap := map[string]interface{}{
-			"activityRunId": child.ActivityRunID,
-			"activityName":  child.ActivityName,
-			"activityType":  child.ActivityType,
-			"status":        child.Status,
-			"pipelineId":    child.PipelineID,
-			"hasChildren":   child.HasChildren,
-		}
-
-		// Add optional time fields
-		if child.StartTime != nil {
-			childMap["activityRunStart"] = child.StartTime.Format(time.RFC3339)
-		}
-		if child.EndTime != nil {
-			childMap["activityRunEnd"] = child.EndTime.Format(time.RFC3339)
-		}
-		if child.DurationMs != nil {
-			childMap["durationMs"] = *child.DurationMs
-		}
-		if child.ErrorMessage != nil {
-			childMap["error"] = *child.ErrorMessage
-		}
-
-		// Add child execution details
-		if child.ChildJobInstanceID != nil {
-			childMap["childJobInstanceId"] = *child.ChildJobInstanceID
-		}
-		if child.ChildPipelineName != nil {
-			childMap["childPipelineName"] = *child.ChildPipelineName
-		}
-		if child.ChildItemDisplayName != nil {
-			childMap["childNotebookName"] = *child.ChildItemDisplayName // For notebooks
-		}
-		if child.ChildWorkspaceID != nil {
-			childMap["childWorkspaceId"] = *child.ChildWorkspaceID
-		}
-		if child.ChildItemID != nil {
-			childMap["childItemId"] = *child.ChildItemID
-		}
-		if child.ChildItemType != nil {
-			childMap["childItemType"] = *child.ChildItemType
-		}
-
-		// Generate Fabric deep link URL for child execution if we have the required info
-		if child.ChildJobInstanceID != nil && child.ChildWorkspaceID != nil {
-			var itemID string
-			var itemType string
-
-			if child.ChildItemID != nil {
-				itemID = *child.ChildItemID
-			}
-			if child.ChildItemType != nil {
-				itemType = *child.ChildItemType
-			}
-
-			fabricURL := utils.GenerateFabricURL(*child.ChildWorkspaceID, itemID, itemType, *child.ChildJobInstanceID, child.LivyID)
-			if fabricURL != "" {
-				childMap["fabricUrl"] = fabricURL
-			}
-		}
-
-		childrenMaps = append(childrenMaps, childMap)
+	result := api.JobDetailResult{Job: job}
+	if job.ItemType != nil {
+		result.FabricURL = utils.GenerateFabricURL(job.WorkspaceID, job.ItemID, *job.ItemType, job.ID, job.LivyID)
 	}
-
-	return map[string]interface{}{
-		"children": childrenMaps,
-		"count":    len(childrenMaps),
+	if job.FailureDetails != nil {
+		result.Failure = api.ParseFailureDetail(*job.FailureDetails)
 	}
+	if result.Failure == nil && job.FailureReason != nil {
+		result.Failure = &api.FailureDetail{Message: *job.FailureReason}
+	}
+	return result
 }
 
-// SyncNotebookSessions fetches and stores Livy session information for all notebooks
-// This allows generating correct notebook deep links using livyID
-func (a *App) SyncNotebookSessions() error {
-	if a.db == nil {
-		return fmt.Errorf("database not initialized")
+// RefreshJobDetail re-queries activity runs for a pipeline run even if they were already stored
+// Activity output such as child run IDs can appear after the run was first enriched
+func (a *App) RefreshJobDetail(jobID string) api.JobWithActivitiesResult {
+	if err := a.apiAvailable(); err != nil {
+		return api.JobWithActivitiesResult{Error: err.Error()}
 	}
-	if a.fabricClient == nil {
-		return fmt.Errorf("fabric client not initialized")
+	if !a.background.Begin() {
+		return api.JobWithActivitiesResult{Error: errShuttingDown.Error()}
+	}
+	defer a.background.Done()
+	if err := a.ensureValidToken(); err != nil {
+		return api.JobWithActivitiesResult{Error: "authentication_required"}
 	}
 
-	logger.Log("Starting notebook sessions sync...\n")
-
-	// Get all unique notebooks from job_instances
-	notebooks, err := a.db.GetUniqueNotebooks()
+	job, err := a.db().GetJobInstanceWithActivities(jobID)
 	if err != nil {
-		return fmt.Errorf("failed to get unique notebooks: %w", err)
-	}
-
-	logger.Log("Found %d unique notebooks to sync\n", len(notebooks))
-
-	// Use worker pool to parallelize notebook session fetching
-	numWorkers := 4 // Process 4 notebooks concurrently
-	notebookChan := make(chan struct {
-		WorkspaceID string
-		NotebookID  string
-	}, len(notebooks))
-	resultsChan := make(chan int, len(notebooks))
-	var wg sync.WaitGroup
-
-	// Start workers
-	for i := 0; i < numWorkers; i++ {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			for notebook := range notebookChan {
-				sessionsCount := a.syncNotebookSessions(notebook.WorkspaceID, notebook.NotebookID)
-				resultsChan <- sessionsCount
-			}
-		}()
+		return api.JobWithActivitiesResult{Error: fmt.Sprintf("Failed to get job: %v", err)}
 	}
-
-	// Send notebooks to workers
-	for _, notebook := range notebooks {
-		notebookChan <- struct {
-			WorkspaceID string
-			NotebookID  string
-		}{
-			WorkspaceID: notebook.WorkspaceID,
-			NotebookID:  notebook.NotebookID,
-		}
+	if job.EndTime == nil {
+		// Still running - activity runs are only queried once the run has finished
+		return api.JobWithActivitiesResult{Job: job}
 	}
-	close(notebookChan)
 
-	// Wait for all workers to complete
-	go func() {
-		wg.Wait()
-		close(resultsChan)
-	}()
-
-	// Collect results
-	totalSessions := 0
-	for count := range resultsChan {
-		totalSessions += count
+	count, err := a.syncer().RefreshActivityRuns(a.ctx, a.fabricClient, *job)
+	if err != nil {
+		logger.Error("Failed to refresh activity runs", logger.JobID(jobID), logger.Err(err))
+		return api.JobWithActivitiesResult{Error: fmt.Sprintf("Failed to refresh activity runs: %v", err), Job: job}
 	}
+	logger.Info("Refreshed activity runs", logger.JobID(jobID), "activities", count)
 
-	logger.Log("Notebook sessions sync complete: %d total sessions synced\n", totalSessions)
-	return nil
+	return a.GetJobInstanceWithActivities(jobID)
 }
 
-// syncNotebookSessions fetches and saves Livy sessions for a single notebook
-func (a *App) syncNotebookSessions(workspaceID, notebookID string) int {
-	continuationToken := ""
-	totalSessions := 0
-
-	// Paginate through all Livy sessions for this notebook
-	for {
-		response, err := a.fabricClient.GetLivySessions(a.ctx, workspaceID, notebookID, continuationToken)
-		if err != nil {
-			logger.Log("Warning: failed to get Livy sessions for notebook %s: %v\n", notebookID, err)
-			break // Skip this notebook
-		}
-
-		if response == nil || len(response.Value) == 0 {
-			break
-		}
-
-		// Convert fabric.LivySession to db.NotebookSession
-		dbSessions := make([]db.NotebookSession, 0, len(response.Value))
-		for _, livySession := range response.Value {
-			dbSession := db.NotebookSession{
-				LivyID:        livySession.LivyID,
-				JobInstanceID: livySession.JobInstanceID,
-				WorkspaceID:   workspaceID,
-				NotebookID:    notebookID,
-				State:         livySession.State, // Required non-pointer field
-			}
-
-			// Handle optional string fields
-			if livySession.SparkApplicationID != "" {
-				dbSession.SparkApplicationID = &livySession.SparkApplicationID
-			}
-			if livySession.Origin != "" {
-				dbSession.Origin = &livySession.Origin
-			}
-			if livySession.AttemptNumber != 0 {
-				dbSession.AttemptNumber = &livySession.AttemptNumber
-			}
-			if livySession.LivyName != "" {
-				dbSession.LivyName = &livySession.LivyName
-			}
-			if livySession.CancellationReason != "" {
-				dbSession.CancellationReason = &livySession.CancellationReason
-			}
-			if livySession.CapacityID != "" {
-				dbSession.CapacityID = &livySession.CapacityID
-			}
-			if livySession.OperationName != "" {
-				dbSession.OperationName = &livySession.OperationName
-			}
-			if livySession.RuntimeVersion != "" {
-				dbSession.RuntimeVersion = &livySession.RuntimeVersion
-			}
-			dbSession.IsHighConcurrency = &livySession.IsHighConcurrency
-
-			// Handle FabricTime fields
-			if !livySession.SubmittedDateTime.Time.IsZero() {
-				dbSession.SubmittedDateTime = &livySession.SubmittedDateTime.Time
-			}
-			if !livySession.StartDateTime.Time.IsZero() {
-				dbSession.StartDateTime = &livySession.StartDateTime.Time
-			}
-			if !livySession.EndDateTime.Time.IsZero() {
-				dbSession.EndDateTime = &livySession.EndDateTime.Time
-			}
-
-			// Extract submitter info
-			if livySession.Submitter.ID != "" {
-				dbSession.SubmitterID = &livySession.Submitter.ID
-			}
-			if livySession.Submitter.Type != "" {
-				dbSession.SubmitterType = &livySession.Submitter.Type
-			}
+// GetChildExecutions retrieves child pipeline and notebook executions for a job
+func (a *App) GetChildExecutions(jobID string) api.ChildExecutionsResult {
+	if a.db() == nil {
+		return api.ChildExecutionsResult{Error: "Database not initialized"}
+	}
 
-			// Extract item info from top-level fields (not nested Item struct)
-			if livySession.ItemName != "" {
-				dbSession.ItemName = &livySession.ItemName
-			}
-			if livySession.ItemType != "" {
-				dbSession.ItemType = &livySession.ItemType
-			}
-			if livySession.JobType != "" {
-				dbSession.JobType = &livySession.JobType
-			}
+	children, err := a.db().GetChildExecutions(jobID)
+	if err != nil {
+		return api.ChildExecutionsResult{Error: fmt.Sprintf("Failed to get child executions: %v", err)}
+	}
 
-			// Convert durations to milliseconds
-			if livySession.QueuedDuration.Value > 0 {
-				ms := convertToMs(livySession.QueuedDuration.Value, livySession.QueuedDuration.TimeUnit)
-				dbSession.QueuedDurationMs = &ms
-			}
-			if livySession.RunningDuration.Value > 0 {
-				ms := convertToMs(livySession.RunningDuration.Value, livySession.RunningDuration.TimeUnit)
-				dbSession.RunningDurationMs = &ms
-			}
-			if livySession.TotalDuration.Value > 0 {
-				ms := convertToMs(livySession.TotalDuration.Value, livySession.TotalDuration.TimeUnit)
-				dbSession.TotalDurationMs = &ms
-			}
+	// Notebooks started from a notebook don't appear in activity runs; link them via their Livy sessions
+	notebookChildren, err := a.db().GetChildNotebookSessions(jobID)
+	if err != nil {
+		logger.Warn("Failed to get child notebook sessions", logger.JobID(jobID), logger.Err(err))
+	}
+	children = append(children, notebookChildren...)
 
-			// Extract consumer identity ID
-			if livySession.ConsumerIdentity.ID != "" {
-				dbSession.ConsumerIdentityID = &livySession.ConsumerIdentity.ID
-			}
+	result := api.ChildExecutionsFromDB(children)
+	return api.ChildExecutionsResult{Children: result, Count: len(result)}
+}
 
-			dbSessions = append(dbSessions, dbSession)
-		}
+// SyncNotebookSessions fetches and stores Livy session information for all notebooks
+// This allows generating correct notebook deep links using livyID
+func (a *App) SyncNotebookSessions() error {
+	if err := a.apiAvailable(); errors.Is(err, errReadOnly) || a.cacheOnly() != nil {
+		return err
+	}
+	if !a.background.Begin() {
+		return errShuttingDown
+	}
+	defer a.background.Done()
+	return a.syncer().SyncNotebookSessions(a.ctx, a.fabricClient, nil)
+}
 
-		// Save sessions to database
-		if len(dbSessions) > 0 {
-			if err := a.db.SaveLivySessions(dbSessions); err != nil {
-				logger.Log("Warning: failed to save Livy sessions for notebook %s: %v\n", notebookID, err)
-				break
-			}
-			totalSessions += len(dbSessions)
-		}
+// GetLogs returns all log entries
+func (a *App) GetLogs() []logger.LogEntry {
+	return logger.GetAll()
+}
 
-		// Check if there are more pages
-		if response.ContinuationToken == "" {
-			break
-		}
-		continuationToken = response.ContinuationToken
+// SearchLogs returns a page of the log entries matching query, by level, text, time range and correlation ID
+// Pages count back from the newest match, 200 entries at a time unless query sets a limit (at most 2000)
+func (a *App) SearchLogs(query logger.Query) api.LogSearchResult {
+	if query.Limit <= 0 {
+		query.Limit = 200
+	}
+	if query.Limit > 2000 {
+		query.Limit = 2000
+	}
+	if query.Offset < 0 {
+		query.Offset = 0
 	}
 
-	if totalSessions > 0 {
-		logger.Log("Synced %d sessions for notebook %s\n", totalSessions, notebookID)
+	page, err := logger.Search(query)
+	if err != nil {
+		return api.LogSearchResult{Error: fmt.Sprintf("Failed to search logs: %v", err)}
 	}
+	return api.LogSearchResult{Page: page, Offset: query.Offset, Limit: query.Limit}
+}
 
-	return totalSessions
+// GetAPIHealth returns the Fabric API traffic of the last hours (24 by default, and at most), for the API health
+// panel: requests, 429s and retries per endpoint and hour, the average retry delay and the rate limiter's history
+// Statistics are kept in memory, so they start over when the app restarts
+func (a *App) GetAPIHealth(hours int) api.APIHealthResult {
+	maxHours := int(fabric.HealthWindow / time.Hour)
+	if hours <= 0 || hours > maxHours {
+		hours = maxHours
+	}
+	return api.APIHealthResult{HealthReport: fabric.APIHealth(hours), Hours: hours}
 }
 
-// convertToMs converts duration from Fabric API to milliseconds
-func convertToMs(value int, timeUnit string) int {
-	switch timeUnit {
-	case "Seconds":
-		return value * 1000
-	case "Minutes":
-		return value * 60000
-	case "Hours":
-		return value * 3600000
-	case "Milliseconds":
-		return value
-	default:
-		return value // Assume milliseconds if unknown
+// SetLogLevel changes the lowest level logged (debug, info, warn or error) until the app restarts, e.g. for a
+// short verbose debugging session; app.log_level in the config sets the level at startup
+func (a *App) SetLogLevel(level string) error {
+	previous := logger.GetLevel()
+	if err := logger.SetLevel(level); err != nil {
+		return err
 	}
+	logger.Info("Log level changed", "from", previous, "to", logger.GetLevel())
+	return nil
 }
 
-// GetLogs returns all log entries
-func (a *App) GetLogs() []logger.LogEntry {
-	return logger.GetAll()
+// GetLogLevel returns the lowest level logged: debug, info, warn or error
+func (a *App) GetLogLevel() string {
+	return logger.GetLevel()
 }
 
 // ClearLogs clears all log entries
 func (a *App) ClearLogs() {
 	logger.Clear()
-	logger.Log("Logs cleared\n")
+	logger.Info("Logs cleared")
 }
 
 // GetAppVersion returns the application version from config
 func (a *App) GetAppVersion() string {
-	if a.config != nil && a.config.App.Version != "" {
-		return a.config.App.Version
+	if a.config() != nil && a.config().App.Version != "" {
+		return a.config().App.Version
 	}
 	return "0.2.4" // Fallback version
 }
 
 // IsReadOnlyReplicaEnabled returns whether the read-only replica feature is enabled
 func (a *App) IsReadOnlyReplicaEnabled() bool {
-	if a.config == nil {
+	if a.config() == nil {
 		return false
 	}
-	return a.config.Database.EnableReadOnlyReplica
+	return a.config().Database.EnableReadOnlyReplica
 }
 
 // GetReadOnlyDatabasePath returns the absolute path to the read-only replica database wrapped in quotes
 func (a *App) GetReadOnlyDatabasePath() string {
-	if a.config == nil || !a.config.Database.EnableReadOnlyReplica {
+	if a.config() == nil || !a.config().Database.EnableReadOnlyReplica {
 		return ""
 	}
 
 	// Get absolute path
-	absPath, err := filepath.Abs(a.config.Database.ReadOnlyPath)
+	absPath, err := filepath.Abs(a.config().Database.ReadOnlyPath)
 	if err != nil {
-		logger.Log("Warning: failed to get absolute path for read-only database: %v\n", err)
-		return fmt.Sprintf(`"%s"`, a.config.Database.ReadOnlyPath)
+		logger.Warn("Failed to get absolute path for read-only database", logger.Err(err))
+		return fmt.Sprintf(`"%s"`, a.config().Database.ReadOnlyPath)
 	}
 
 	return fmt.Sprintf(`"%s"`, absPath)
 }
+
+// GetHighConcurrencyStats reports how many notebook sessions of the last days shared a high-concurrency Spark
+// application instead of starting their own, per consumer identity, and the queued time sharing saved
+func (a *App) GetHighConcurrencyStats(days int, workspaceIDs []string, itemTypes []string, itemNameSearch string) api.HighConcurrencyResult {
+	if a.db() == nil {
+		return api.HighConcurrencyResult{Error: "Database not initialized"}
+	}
+
+	if days <= 0 {
+		days = 30
+	}
+
+	stats, err := a.db().GetHighConcurrencyStats(days, a.analyticsWorkspaceIDs(workspaceIDs), itemTypes, itemNameSearch)
+	if err != nil {
+		return api.HighConcurrencyResult{Error: fmt.Sprintf("Failed to get high-concurrency stats: %v", err)}
+	}
+	result := api.HighConcurrencyResult{Days: days, Identities: []db.HighConcurrencyStats{}}
+	if len(stats) > 0 {
+		result.Summary, result.Identities = stats[0], append(result.Identities, stats[1:]...)
+	}
+	return result
+}
+
+// GetInvokerStats splits the runs started in the last days by invoker (scheduled, manual, pipeline or API), in
+// total and per item, so a failing schedule isn't hidden by manual reruns that succeeded
+func (a *App) GetInvokerStats(days int, workspaceIDs []string, itemTypes []string, itemNameSearch string) api.InvokerStatsResult {
+	if a.db() == nil {
+		return api.InvokerStatsResult{Error: "Database not initialized"}
+	}
+
+	if days <= 0 {
+		days = 30
+	}
+
+	stats, err := a.db().GetInvokerStats(days, a.analyticsWorkspaceIDs(workspaceIDs), itemTypes, itemNameSearch)
+	if err != nil {
+		return api.InvokerStatsResult{Error: fmt.Sprintf("Failed to get invoker stats: %v", err)}
+	}
+	result := api.InvokerStatsResult{Days: days, Invokers: []db.InvokerStats{}, Items: []db.InvokerStats{}}
+	for _, s := range stats {
+		if s.ItemID == "" {
+			result.Invokers = append(result.Invokers, s)
+		} else {
+			result.Items = append(result.Items, s)
+		}
+	}
+	return result
+}
+
+// GetDurationPercentiles returns the daily P50 and P90 duration of completed runs started in the last days, of one
+// item when itemID is set or of the items matching the filters, so duration creep shows as a trend
+func (a *App) GetDurationPercentiles(days int, itemID string, workspaceIDs []string, itemTypes []string, itemNameSearch string) api.DurationPercentilesResult {
+	if a.db() == nil {
+		return api.DurationPercentilesResult{Error: "Database not initialized"}
+	}
+
+	if days <= 0 {
+		days = 30
+	}
+
+	series, err := a.db().GetDurationPercentiles(days, itemID, a.analyticsWorkspaceIDs(workspaceIDs), itemTypes, itemNameSearch)
+	if err != nil {
+		return api.DurationPercentilesResult{Error: fmt.Sprintf("Failed to get duration percentiles: %v", err)}
+	}
+	return api.DurationPercentilesResult{DurationPercentileSeries: *series}
+}
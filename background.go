@@ -0,0 +1,74 @@
+package main
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"better-fabric-monitor/internal/reporting"
+)
+
+// errShuttingDown is returned by bindings called after shutdown has started
+var errShuttingDown = errors.New("application is shutting down")
+
+// shutdownGracePeriod is how long shutdown waits for background work before closing the database
+const shutdownGracePeriod = 10 * time.Second
+
+// backgroundWork tracks goroutines and long-running bindings that use the database
+// so shutdown can wait for them before closing it
+type backgroundWork struct {
+	mu     sync.Mutex
+	wg     sync.WaitGroup
+	closed bool
+}
+
+// Begin registers a unit of work; it returns false once shutdown has started
+// Every successful Begin must be paired with a call to Done
+func (b *backgroundWork) Begin() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return false
+	}
+	b.wg.Add(1)
+	return true
+}
+
+// Done marks a unit of work started with Begin as finished
+func (b *backgroundWork) Done() {
+	b.wg.Done()
+}
+
+// Go runs fn in a tracked goroutine; it returns false without running fn once shutdown has started
+func (b *backgroundWork) Go(fn func()) bool {
+	if !b.Begin() {
+		return false
+	}
+	go func() {
+		defer b.Done()
+		defer reporting.RecoverPanic("background")
+		fn()
+	}()
+	return true
+}
+
+// Close refuses new work and waits up to grace for running work to finish
+// Returns false if work was still running when the grace period ran out
+func (b *backgroundWork) Close(grace time.Duration) bool {
+	b.mu.Lock()
+	b.closed = true
+	b.mu.Unlock()
+
+	finished := make(chan struct{})
+	go func() {
+		b.wg.Wait()
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+		return true
+	case <-time.After(grace):
+		return false
+	}
+}
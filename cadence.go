@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"better-fabric-monitor/internal/api"
+)
+
+// defaultCadenceDays is the window GetCadenceReport looks at when none is given
+const defaultCadenceDays = 30
+
+// GetCadenceReport infers each item's run cadence from its runs of the last days, without needing schedule
+// metadata, and flags items whose runs start later and later, skip runs or are overdue
+// Flagged items come first, then the rest by name; items with too few runs are left out
+func (a *App) GetCadenceReport(days int, workspaceIDs []string, itemTypes []string, itemNameSearch string) api.CadenceReportResult {
+	if a.db() == nil {
+		return api.CadenceReportResult{Error: "Database not initialized"}
+	}
+
+	if days <= 0 {
+		days = defaultCadenceDays
+	}
+
+	items, err := a.db().GetItemCadences(days, time.Now().UTC(), a.analyticsWorkspaceIDs(workspaceIDs), itemTypes, itemNameSearch)
+	if err != nil {
+		return api.CadenceReportResult{Error: fmt.Sprintf("Failed to infer run cadences: %v", err)}
+	}
+
+	reports := []api.CadenceReport{}
+	for _, item := range items {
+		reports = append(reports, api.CadenceReport{
+			ItemID:          item.ItemID,
+			ItemDisplayName: item.ItemDisplayName,
+			ItemType:        item.ItemType,
+			WorkspaceID:     item.WorkspaceID,
+			WorkspaceName:   item.WorkspaceName,
+			Cadence:         api.CadenceFromDB(item.RunCadence),
+		})
+	}
+	sort.SliceStable(reports, func(i, j int) bool {
+		if len(reports[i].Flags) != len(reports[j].Flags) {
+			return len(reports[i].Flags) > len(reports[j].Flags)
+		}
+		return reports[i].ItemDisplayName < reports[j].ItemDisplayName
+	})
+	return api.CadenceReportResult{Items: reports}
+}
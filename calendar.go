@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"better-fabric-monitor/internal/api"
+)
+
+// calendarMonthLayout is the format of the month of GetRunCalendar
+const calendarMonthLayout = "2006-01"
+
+// GetRunCalendar returns the runs, failures and SLA breaches of each day of month (YYYY-MM, the current month
+// when empty) in the local time zone, for a calendar heatmap to jump to a bad day from
+// Runs longer than the long-running threshold count as SLA breaches, as in the digest
+func (a *App) GetRunCalendar(month string, workspaceIDs []string, itemTypes []string, itemNameSearch string) api.RunCalendarResult {
+	if a.db() == nil {
+		return api.RunCalendarResult{Error: "Database not initialized"}
+	}
+
+	first := time.Now()
+	if month != "" {
+		parsed, err := time.ParseInLocation(calendarMonthLayout, month, time.Local)
+		if err != nil {
+			return api.RunCalendarResult{Error: fmt.Sprintf("Invalid month %q: use YYYY-MM", month)}
+		}
+		first = parsed
+	}
+	first = time.Date(first.Year(), first.Month(), 1, 0, 0, 0, 0, time.Local)
+
+	threshold := a.config().Notifications.LongRunningThreshold
+	days, err := a.db().GetRunCalendar(first, first.AddDate(0, 1, 0), time.Local, threshold,
+		a.analyticsWorkspaceIDs(workspaceIDs), itemTypes, itemNameSearch)
+	if err != nil {
+		return api.RunCalendarResult{Error: fmt.Sprintf("Failed to get run calendar: %v", err)}
+	}
+	zone, _ := first.Zone()
+	return api.RunCalendarResult{
+		Month:          first.Format(calendarMonthLayout),
+		Timezone:       zone,
+		SLAThresholdMs: threshold.Milliseconds(),
+		Days:           days,
+	}
+}
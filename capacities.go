@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+
+	"better-fabric-monitor/internal/api"
+	"better-fabric-monitor/internal/config"
+	"better-fabric-monitor/internal/logger"
+)
+
+// GetCapacityLoad aggregates the runs started in the last days per capacity (runs, failures, concurrency and the
+// time notebook sessions queued) so an overloaded capacity stands out, busiest first
+// Capacity metrics are experimental and need features.capacity_metrics
+func (a *App) GetCapacityLoad(days int, workspaceIDs []string, itemTypes []string, itemNameSearch string) api.CapacityLoadResult {
+	if !a.featureEnabled(config.FeatureCapacityMetrics) {
+		return api.CapacityLoadResult{Error: featureDisabledError(config.FeatureCapacityMetrics).Error()}
+	}
+	if a.db() == nil {
+		return api.CapacityLoadResult{Error: "Database not initialized"}
+	}
+
+	if days <= 0 {
+		days = 30
+	}
+
+	stats, err := a.db().GetCapacityLoadStats(days, a.analyticsWorkspaceIDs(workspaceIDs), itemTypes, itemNameSearch)
+	if err != nil {
+		return api.CapacityLoadResult{Error: fmt.Sprintf("Failed to get capacity load: %v", err)}
+	}
+
+	// Name the workspaces on each capacity, since the capacity itself is only known by its ID
+	names := make(map[string][]string)
+	workspaces, err := a.db().GetWorkspaces()
+	if err != nil {
+		logger.Warn("Failed to read workspaces for capacity load", logger.Err(err))
+	}
+	for _, ws := range workspaces {
+		capacityID := ""
+		if ws.CapacityID != nil {
+			capacityID = *ws.CapacityID
+		}
+		names[capacityID] = append(names[capacityID], ws.DisplayName)
+	}
+
+	result := api.CapacityLoadResult{Days: days, Capacities: make([]api.CapacityLoad, 0, len(stats))}
+	for _, s := range stats {
+		load := api.CapacityLoad{CapacityLoadStats: s, WorkspaceNames: names[s.CapacityID]}
+		if load.WorkspaceNames == nil {
+			load.WorkspaceNames = []string{}
+		}
+		result.Capacities = append(result.Capacities, load)
+	}
+	return result
+}
@@ -0,0 +1,595 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"better-fabric-monitor/internal/api"
+	"better-fabric-monitor/internal/auth"
+	"better-fabric-monitor/internal/config"
+	"better-fabric-monitor/internal/db"
+	"better-fabric-monitor/internal/logger"
+	"better-fabric-monitor/internal/notify"
+	"better-fabric-monitor/internal/secrets"
+	syncer "better-fabric-monitor/internal/sync"
+)
+
+// Exit codes of the command line subcommands
+const (
+	exitOK      = 0
+	exitFailed  = 1 // The command failed
+	exitUsage   = 2 // Invalid arguments
+	exitPartial = 3 // The sync finished, but some workspaces or items could not be fetched
+	exitAuth    = 4 // Authentication failed
+	exitInUse   = 5 // Another instance holds the database
+)
+
+// runCommand runs the subcommand named by args[0]
+// ok is false when args name no subcommand, in which case the GUI starts
+func runCommand(args []string) (code int, ok bool) {
+	if len(args) == 0 {
+		return 0, false
+	}
+	switch args[0] {
+	case "sync":
+		code = runSyncCommand(args[1:])
+	case "export":
+		code = runExportCommand(args[1:])
+	case "digest":
+		code = runDigestCommand(args[1:])
+	case "secret":
+		code = runSecretCommand(args[1:])
+	case "doctor":
+		code = runDoctorCommand(args[1:])
+	default:
+		return 0, false
+	}
+	stopTracing()
+	stopErrorReporting()
+	return code, true
+}
+
+// runSyncCommand signs in, runs one sync into the configured database and returns the exit code
+func runSyncCommand(args []string) int {
+	flags := flag.NewFlagSet("sync", flag.ContinueOnError)
+	tenantID := flags.String("tenant", "", "Entra tenant ID (required with a client secret)")
+	clientID := flags.String("client-id", "", "App registration client ID (defaults to the configured client ID)")
+	clientSecret := flags.String("client-secret", "", "Client secret for service principal sign-in (or FABRIC_MONITOR_AUTH_CLIENT_SECRET)")
+	timeout := flags.Duration("timeout", 0, "Cancel the sync after this long (0 waits for it to finish)")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s sync [flags]\n\n", os.Args[0])
+		fmt.Fprintf(flags.Output(), "Syncs Fabric job history into the local database and exits.\n")
+		fmt.Fprintf(flags.Output(), "Signs in as a service principal when a client secret is given, otherwise with the\n")
+		fmt.Fprintf(flags.Output(), "account cached by the app, falling back to the device code flow.\n\n")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+	cfg, ok := loadCommandConfig()
+	if !ok {
+		return exitFailed
+	}
+	// FABRIC_MONITOR_AUTH_CLIENT_SECRET sets auth.client_secret, which may reference the OS credential manager
+	if *clientSecret == "" {
+		secret, err := secrets.Resolve(cfg.Auth.ClientSecret)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to read auth.client_secret: %v\n", err)
+			return exitAuth
+		}
+		*clientSecret = secret
+	}
+	if cfg.App.DemoMode || cfg.App.Offline {
+		fmt.Fprintln(os.Stderr, "Sync is disabled in demo and offline mode")
+		return exitUsage
+	}
+	if *tenantID == "" {
+		*tenantID = cfg.Auth.TenantID
+	}
+	if *clientID == "" {
+		*clientID = cfg.Auth.ClientID
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+
+	token, err := commandToken(ctx, *tenantID, *clientID, *clientSecret)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Authentication failed: %v\n", err)
+		return exitAuth
+	}
+
+	database, release, err := openCommandDatabase(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		if errors.Is(err, db.ErrDatabaseInUse) {
+			return exitInUse
+		}
+		return exitFailed
+	}
+	defer release()
+
+	var notifier *notify.Notifier // nil drops every event
+	if cfg.Notifications.Enabled {
+		notifier = newNotifier(cfg.Notifications)
+		recordNotifications(notifier, database)
+		applyNotificationMutes(notifier, database)
+		applyWorkspaceRouting(notifier, database)
+	}
+	var failedMu sync.Mutex
+	var failedJobs []api.Job
+
+	opts := syncOptions(cfg)
+	opts.AppVersion = cfg.App.Version
+	if cfg.Notifications.OnFailure {
+		opts.OnJobFailed = func(job api.Job) {
+			failedMu.Lock()
+			failedJobs = append(failedJobs, job)
+			failedMu.Unlock()
+		}
+	}
+	var longRunningJobs []syncer.LongRunningJob
+	if notifier != nil && cfg.Notifications.OnLongRunning {
+		opts.OnJobLongRunning = func(job syncer.LongRunningJob) {
+			longRunningJobs = append(longRunningJobs, job)
+		}
+	}
+	var stuckJobs []syncer.LongRunningJob
+	if notifier != nil && cfg.Notifications.OnStuck {
+		opts.OnJobStuck = func(job syncer.LongRunningJob) {
+			stuckJobs = append(stuckJobs, job)
+		}
+	}
+	result, err := syncer.New(database, &commandReporter{}).Run(ctx, newFabricClient(cfg, token.AccessToken), opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Sync failed: %v\n", err)
+		notifier.Notify(context.Background(), notify.SyncFailedEvent(err))
+		return exitFailed
+	}
+	// Failures are sent after the sync so slow channels don't hold up the API workers
+	for _, job := range failedJobs {
+		notifier.Notify(ctx, notify.JobFailedEvent(job, failureStreak(database, job)))
+	}
+	for _, job := range longRunningJobs {
+		notifier.Notify(ctx, notify.JobLongRunningEvent(job.Job, job.Elapsed, job.Baseline))
+	}
+	for _, job := range stuckJobs {
+		notifier.Notify(ctx, notify.JobStuckEvent(job.Job, job.Elapsed, job.Baseline))
+	}
+	notifier.Flush(ctx)
+	if result.CancelledDuringJobs || ctx.Err() != nil {
+		fmt.Fprintln(os.Stderr, "Sync cancelled; finished workspaces were saved")
+		return exitFailed
+	}
+
+	syncType := "full"
+	if result.Incremental {
+		syncType = "incremental"
+	}
+	logger.Info("Sync complete", logger.SyncRunID(result.SyncRunID), "syncType", syncType, "jobsFetched", result.JobsFetched, "jobsStored", len(result.Jobs))
+
+	if cfg.Database.EnableReadOnlyReplica {
+		if err := exportReplica(ctx, database, cfg.Database); err != nil {
+			logger.Warn("Failed to refresh read-only replica", logger.Err(err))
+		}
+	}
+
+	if len(result.Warnings) > 0 {
+		fmt.Fprintf(os.Stderr, "Sync incomplete: %d workspaces or items could not be fetched\n", len(result.Warnings))
+		for _, w := range result.Warnings {
+			target := w.Workspace
+			if w.Item != "" {
+				target += " / " + w.Item
+			}
+			fmt.Fprintf(os.Stderr, "  %s: %s\n", target, w.Error)
+		}
+		return exitPartial
+	}
+	return exitOK
+}
+
+// runExportCommand writes every table of the configured database to Parquet or CSV files and returns the exit code
+func runExportCommand(args []string) int {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	format := flags.String("format", db.ExportFormatParquet, "File format: parquet or csv")
+	outDir := flags.String("out", "", "Directory to write one file per table to (required)")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s export --out DIR [flags]\n\n", os.Args[0])
+		fmt.Fprintf(flags.Output(), "Exports workspaces, items, job instances, notebook sessions and sync history from the\n")
+		fmt.Fprintf(flags.Output(), "local database without syncing or signing in.\n\n")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if *outDir == "" {
+		fmt.Fprintln(os.Stderr, "--out is required")
+		flags.Usage()
+		return exitUsage
+	}
+	if *format != db.ExportFormatParquet && *format != db.ExportFormatCSV {
+		fmt.Fprintf(os.Stderr, "Unsupported format %q: use parquet or csv\n", *format)
+		return exitUsage
+	}
+
+	cfg, ok := loadCommandConfig()
+	if !ok {
+		return exitFailed
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	database, release, err := openCommandDatabase(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		if errors.Is(err, db.ErrDatabaseInUse) {
+			return exitInUse
+		}
+		return exitFailed
+	}
+	defer release()
+
+	stats, err := database.ExportTables(ctx, *outDir, *format)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Export failed: %v\n", err)
+		return exitFailed
+	}
+
+	failed := 0
+	for _, stat := range stats {
+		if !stat.Success {
+			failed++
+		}
+	}
+	if failed > 0 {
+		fmt.Fprintf(os.Stderr, "Export incomplete: %d of %d tables failed\n", failed, len(stats))
+		return exitFailed
+	}
+	logger.Info("Export complete", "tables", len(stats), "dir", *outDir)
+	return exitOK
+}
+
+// runDigestCommand prints the digest of the last day or week and optionally sends it through the notification channels
+func runDigestCommand(args []string) int {
+	flags := flag.NewFlagSet("digest", flag.ContinueOnError)
+	frequency := flags.String("period", digestDaily, "Period to summarize: daily or weekly")
+	format := flags.String("format", "", "Report format: markdown or html (defaults to notifications.digest.format)")
+	send := flags.Bool("send", false, "Also send the digest through the configured notification channels")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s digest [flags]\n\n", os.Args[0])
+		fmt.Fprintf(flags.Output(), "Prints the success rate, new failures, slowest items and SLA breaches of the runs\n")
+		fmt.Fprintf(flags.Output(), "started in the last day or week, from the local database.\n\n")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if *frequency != digestDaily && *frequency != digestWeekly {
+		fmt.Fprintf(os.Stderr, "Unsupported period %q: use daily or weekly\n", *frequency)
+		return exitUsage
+	}
+	if *format != "" && *format != notify.DigestFormatMarkdown && *format != notify.DigestFormatHTML {
+		fmt.Fprintf(os.Stderr, "Unsupported format %q: use markdown or html\n", *format)
+		return exitUsage
+	}
+
+	cfg, ok := loadCommandConfig()
+	if !ok {
+		return exitFailed
+	}
+	if *format != "" {
+		cfg.Notifications.Digest.Format = *format
+	}
+
+	database, release, err := openCommandDatabase(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		if errors.Is(err, db.ErrDatabaseInUse) {
+			return exitInUse
+		}
+		return exitFailed
+	}
+	defer release()
+
+	event, err := digestEvent(database, cfg.Notifications, *frequency, time.Now())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return exitFailed
+	}
+	fmt.Print(event.Message)
+	if !*send {
+		return exitOK
+	}
+
+	notifier := newNotifier(cfg.Notifications)
+	if !cfg.Notifications.Enabled || notifier.Empty() {
+		fmt.Fprintln(os.Stderr, "No notification channel is enabled")
+		return exitFailed
+	}
+	recordNotifications(notifier, database)
+	if err := notifier.Notify(context.Background(), event); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to send digest: %v\n", err)
+		return exitFailed
+	}
+	return exitOK
+}
+
+// runDoctorCommand prints the problems found in the configuration and fails when any of them is an error
+func runDoctorCommand(args []string) int {
+	flags := flag.NewFlagSet("doctor", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s doctor\n\n", os.Args[0])
+		fmt.Fprintf(flags.Output(), "Checks the configuration: writable paths, credentials against the sign-in mode, secrets\n")
+		fmt.Fprintf(flags.Output(), "in the credential manager, replica paths and reachability of notification endpoints.\n")
+	}
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+	cfg, ok := loadCommandConfig()
+	if !ok {
+		return exitFailed
+	}
+
+	findings := diagnoseConfig(context.Background(), cfg)
+	for _, finding := range findings {
+		fmt.Printf("%-7s %s: %s\n", finding.Severity, finding.Setting, finding.Message)
+		if finding.Fix != "" {
+			fmt.Printf("        %s\n", finding.Fix)
+		}
+	}
+	if hasErrorFinding(findings) {
+		return exitFailed
+	}
+	if len(findings) == 0 {
+		fmt.Println("No problems found")
+	}
+	return exitOK
+}
+
+// runSecretCommand stores or deletes a secret in the OS credential manager, or moves the secrets in the config there
+func runSecretCommand(args []string) int {
+	flags := flag.NewFlagSet("secret", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s secret set <name> | delete <name> | migrate\n\n", os.Args[0])
+		fmt.Fprintf(flags.Output(), "set stores the secret read from stdin as <name> in the OS credential manager; reference\n")
+		fmt.Fprintf(flags.Output(), "it in config.yaml as %s<name>. migrate moves every secret in the config there and\n", secrets.RefPrefix)
+		fmt.Fprintf(flags.Output(), "saves config.yaml with references to them.\n")
+	}
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+
+	rest := flags.Args()
+	switch {
+	case len(rest) == 2 && rest[0] == "set":
+		value, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			fmt.Fprintf(os.Stderr, "Failed to read the secret: %v\n", err)
+			return exitFailed
+		}
+		value = strings.TrimRight(value, "\r\n")
+		if value == "" {
+			fmt.Fprintln(os.Stderr, "No secret given on stdin")
+			return exitUsage
+		}
+		if err := secrets.Set(rest[1], value); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			return exitFailed
+		}
+		fmt.Printf("Stored %s; reference it in config.yaml as %s\n", rest[1], secrets.Ref(rest[1]))
+		return exitOK
+	case len(rest) == 2 && rest[0] == "delete":
+		if err := secrets.Delete(rest[1]); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			return exitFailed
+		}
+		fmt.Printf("Deleted %s\n", rest[1])
+		return exitOK
+	case len(rest) == 1 && rest[0] == "migrate":
+		return migrateSecrets()
+	default:
+		flags.Usage()
+		return exitUsage
+	}
+}
+
+// migrateSecrets stores every secret held in plain text in the config in the OS credential manager under its config
+// key, and saves config.yaml referencing them instead
+func migrateSecrets() int {
+	cfg, ok := loadCommandConfig()
+	if !ok {
+		return exitFailed
+	}
+
+	fields := cfg.Secrets()
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	moved := 0
+	for _, key := range keys {
+		value := fields[key]
+		if *value == "" || secrets.IsRef(*value) {
+			continue
+		}
+		if err := secrets.Set(key, *value); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			return exitFailed
+		}
+		*value = secrets.Ref(key)
+		fmt.Printf("Moved %s to the credential manager\n", key)
+		moved++
+	}
+	if moved == 0 {
+		fmt.Println("No secrets to move")
+		return exitOK
+	}
+	if err := cfg.Save(); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to save config.yaml: %v\n", err)
+		return exitFailed
+	}
+	fmt.Println("Saved config.yaml; also remove the plain values from environment variables and .env files")
+	return exitOK
+}
+
+// loadCommandConfig loads the configuration the way the app does, reporting failures on stderr
+func loadCommandConfig() (*config.Config, bool) {
+	logger.Init(2000)
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load config: %v\n", err)
+		return nil, false
+	}
+	if err := logger.SetLevel(cfg.App.LogLevel); err != nil {
+		fmt.Fprintf(os.Stderr, "Keeping the info log level: %v\n", err)
+	}
+	openLogFile(cfg.App.LogFile)
+	applyTLS(cfg.TLS)
+	startTracing(cfg.Tracing, cfg.App.Version)
+	applyFabricURLs(cfg.Fabric)
+	startErrorReporting(cfg.App.ErrorReporting, cfg.App.Version)
+	if cfg.App.MemoryLimitMB > 0 {
+		debug.SetMemoryLimit(int64(cfg.App.MemoryLimitMB) << 20)
+	}
+	return cfg, true
+}
+
+// commandToken signs in as a service principal when a client secret is given, otherwise as the cached user,
+// falling back to the device code flow
+func commandToken(ctx context.Context, tenantID, clientID, clientSecret string) (*auth.Token, error) {
+	if clientSecret != "" {
+		if clientID == "" {
+			return nil, fmt.Errorf("a client ID is required with a client secret")
+		}
+		return auth.ServicePrincipalToken(ctx, tenantID, clientID, clientSecret, auth.FabricScopes)
+	}
+
+	if clientID == "" || clientID == placeholderClientID {
+		clientID = "1950a258-227b-4e31-a9cf-717495945fc2" // Microsoft PowerShell public client
+	}
+	manager, err := auth.NewAuthManager(&auth.AuthConfig{
+		ClientID: clientID,
+		TenantID: tenantID,
+		Scopes:   auth.FabricScopes,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if token, err := manager.GetToken(ctx); err == nil {
+		return token, nil
+	}
+
+	info, err := manager.StartDeviceCodeFlow(ctx)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Println(info.Message)
+	return manager.CompleteDeviceCodeFlow(ctx)
+}
+
+// openCommandDatabase takes the single-instance lock and opens the configured database
+// The returned release closes the database and then gives up the lock
+func openCommandDatabase(cfg *config.Config) (*db.Database, func(), error) {
+	dbPath := cfg.Database.Path
+	if dbPath == "" {
+		dbPath = "data/fabric-monitor.db"
+	}
+
+	lock, err := db.AcquireInstanceLock(dbPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	database, err := openDatabaseFile(dbPath, cfg.Database.EncryptionKey)
+	if err != nil {
+		lock.Release()
+		return nil, nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	setDatabaseResources(database, cfg.Database)
+
+	return database, func() {
+		if err := database.Close(); err != nil {
+			logger.Error("Failed to close database", logger.Err(err))
+		}
+		if err := lock.Release(); err != nil {
+			logger.Warn("Failed to release instance lock", logger.Err(err))
+		}
+	}, nil
+}
+
+// commandReporter prints workspace progress of a command line sync
+type commandReporter struct {
+	total     atomic.Int32
+	completed atomic.Int32
+	syncRunID string // Set before any workspace is fetched
+}
+
+// SetSyncRunID tags the progress the reporter logs with the sync run ID
+func (r *commandReporter) SetSyncRunID(id string) {
+	r.syncRunID = id
+}
+
+// SetPhase prints the phase the sync moved to
+func (r *commandReporter) SetPhase(phase string) {
+	logger.Info("Sync phase", "phase", phase, logger.SyncRunID(r.syncRunID))
+}
+
+// SetWorkspacesTotal records how many workspaces the sync will process
+func (r *commandReporter) SetWorkspacesTotal(total int) {
+	r.total.Store(int32(total))
+}
+
+// ItemProcessed, ItemFailed and JobsSaved are not printed; the Fabric client and syncer log them
+func (r *commandReporter) ItemProcessed(workspaceName, itemName string, jobs int) {}
+
+func (r *commandReporter) ItemFailed(workspaceName, itemName string, err error) {}
+
+func (r *commandReporter) JobsSaved(workspaceName string, jobs int) {}
+
+// WorkspaceCompleted prints how many workspaces are done
+func (r *commandReporter) WorkspaceCompleted(workspaceName string, err error) {
+	done := r.completed.Add(1)
+	if err != nil {
+		logger.Warn("Workspace sync failed", "workspace", workspaceName, "done", done, "total", r.total.Load(),
+			logger.SyncRunID(r.syncRunID), logger.Err(err))
+		return
+	}
+	logger.Info("Workspace synced", "workspace", workspaceName, "done", done, "total", r.total.Load(),
+		logger.SyncRunID(r.syncRunID))
+}
@@ -0,0 +1,242 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"better-fabric-monitor/internal/config"
+	"better-fabric-monitor/internal/fabric"
+	"better-fabric-monitor/internal/logger"
+	"better-fabric-monitor/internal/utils"
+)
+
+// restartRequired is why changes to settings that are only read at startup are not applied
+const restartRequired = "requires a restart"
+
+// ConfigReload reports how a change to config.yaml was handled
+type ConfigReload struct {
+	Applied  []string          `json:"applied"`         // Keys applied to the running app, e.g. polling.interval
+	Rejected map[string]string `json:"rejected"`        // Keys left unchanged, with the reason
+	Error    string            `json:"error,omitempty"` // Set when config.yaml could not be loaded at all
+}
+
+// hotReloadable applies each setting that is safe to change at runtime from src to dst, rejecting invalid values
+// Settings not listed are read at startup, e.g. by the database, auth or notification channels
+var hotReloadable = map[string]func(dst, src *config.Config) error{
+	"polling.enabled": func(dst, src *config.Config) error {
+		dst.Polling.Enabled = src.Polling.Enabled
+		return nil
+	},
+	"polling.interval": func(dst, src *config.Config) error {
+		if src.Polling.Interval < minPollingIntervalSeconds*time.Second {
+			return fmt.Errorf("polling interval must be at least %d seconds", minPollingIntervalSeconds)
+		}
+		dst.Polling.Interval = src.Polling.Interval
+		return nil
+	},
+	"polling.adaptive": func(dst, src *config.Config) error {
+		dst.Polling.Adaptive = src.Polling.Adaptive
+		return nil
+	},
+	"polling.max_interval": func(dst, src *config.Config) error {
+		dst.Polling.MaxInterval = src.Polling.MaxInterval
+		return nil
+	},
+	"polling.status_interval": func(dst, src *config.Config) error {
+		if src.Polling.StatusInterval != 0 && src.Polling.StatusInterval < minPollingIntervalSeconds*time.Second {
+			return fmt.Errorf("status polling interval must be at least %d seconds, or 0 to disable it", minPollingIntervalSeconds)
+		}
+		dst.Polling.StatusInterval = src.Polling.StatusInterval
+		return nil
+	},
+	"notifications.enabled": func(dst, src *config.Config) error {
+		dst.Notifications.Enabled = src.Notifications.Enabled
+		return nil
+	},
+	"notifications.on_failure": func(dst, src *config.Config) error {
+		dst.Notifications.OnFailure = src.Notifications.OnFailure
+		return nil
+	},
+	"notifications.on_long_running": func(dst, src *config.Config) error {
+		dst.Notifications.OnLongRunning = src.Notifications.OnLongRunning
+		return nil
+	},
+	"notifications.on_stuck": func(dst, src *config.Config) error {
+		dst.Notifications.OnStuck = src.Notifications.OnStuck
+		return nil
+	},
+	"notifications.sound_enabled": func(dst, src *config.Config) error {
+		dst.Notifications.SoundEnabled = src.Notifications.SoundEnabled
+		return nil
+	},
+	"notifications.long_running_threshold": func(dst, src *config.Config) error {
+		if src.Notifications.LongRunningThreshold <= 0 {
+			return errors.New("long-running threshold must be positive")
+		}
+		dst.Notifications.LongRunningThreshold = src.Notifications.LongRunningThreshold
+		return nil
+	},
+	"notifications.long_running_factor": func(dst, src *config.Config) error {
+		dst.Notifications.LongRunningFactor = src.Notifications.LongRunningFactor
+		return nil
+	},
+	"notifications.stuck_factor": func(dst, src *config.Config) error {
+		dst.Notifications.StuckFactor = src.Notifications.StuckFactor
+		return nil
+	},
+	"notifications.sounds.default": func(dst, src *config.Config) error {
+		return reloadSound(&dst.Notifications.Sounds.Default, src.Notifications.Sounds.Default)
+	},
+	"notifications.sounds.failure": func(dst, src *config.Config) error {
+		return reloadSound(&dst.Notifications.Sounds.Failure, src.Notifications.Sounds.Failure)
+	},
+	"notifications.sounds.long_running": func(dst, src *config.Config) error {
+		return reloadSound(&dst.Notifications.Sounds.LongRunning, src.Notifications.Sounds.LongRunning)
+	},
+	"notifications.sounds.stuck": func(dst, src *config.Config) error {
+		return reloadSound(&dst.Notifications.Sounds.Stuck, src.Notifications.Sounds.Stuck)
+	},
+	"ui.default_view": func(dst, src *config.Config) error {
+		if !slices.Contains(supportedViews, src.UI.DefaultView) {
+			return fmt.Errorf("unsupported default view: %s", src.UI.DefaultView)
+		}
+		dst.UI.DefaultView = src.UI.DefaultView
+		return nil
+	},
+	"ui.analytics_days": func(dst, src *config.Config) error {
+		if src.UI.AnalyticsDays < minAnalyticsDays || src.UI.AnalyticsDays > maxAnalyticsDays {
+			return fmt.Errorf("default analytics window must be between %d and %d days", minAnalyticsDays, maxAnalyticsDays)
+		}
+		dst.UI.AnalyticsDays = src.UI.AnalyticsDays
+		return nil
+	},
+	"ui.default_workspace_ids": func(dst, src *config.Config) error {
+		dst.UI.DefaultWorkspaceIDs = src.UI.DefaultWorkspaceIDs
+		return nil
+	},
+	"features": func(dst, src *config.Config) error {
+		if err := validateFeatures(src.Features); err != nil {
+			return err
+		}
+		dst.Features = src.Features
+		return nil
+	},
+	"app.log_level": func(dst, src *config.Config) error {
+		if err := logger.SetLevel(src.App.LogLevel); err != nil {
+			return err
+		}
+		dst.App.LogLevel = src.App.LogLevel
+		return nil
+	},
+	"fabric.workspace_ids": func(dst, src *config.Config) error {
+		dst.Fabric.WorkspaceIDs = src.Fabric.WorkspaceIDs
+		return nil
+	},
+	"fabric.include_workspaces": func(dst, src *config.Config) error {
+		if err := fabric.ValidatePatterns(src.Fabric.IncludeWorkspaces); err != nil {
+			return err
+		}
+		dst.Fabric.IncludeWorkspaces = src.Fabric.IncludeWorkspaces
+		return nil
+	},
+	"fabric.exclude_workspaces": func(dst, src *config.Config) error {
+		if err := fabric.ValidatePatterns(src.Fabric.ExcludeWorkspaces); err != nil {
+			return err
+		}
+		dst.Fabric.ExcludeWorkspaces = src.Fabric.ExcludeWorkspaces
+		return nil
+	},
+	"fabric.include_personal": func(dst, src *config.Config) error {
+		dst.Fabric.IncludePersonal = src.Fabric.IncludePersonal
+		return nil
+	},
+	"fabric.portal_host": func(dst, src *config.Config) error {
+		if err := utils.SetFabricURLs(src.Fabric.PortalHost, dst.Fabric.LinkTemplates); err != nil {
+			return err
+		}
+		dst.Fabric.PortalHost = src.Fabric.PortalHost
+		return nil
+	},
+	"fabric.link_templates": func(dst, src *config.Config) error {
+		if err := utils.SetFabricURLs(dst.Fabric.PortalHost, src.Fabric.LinkTemplates); err != nil {
+			return err
+		}
+		dst.Fabric.LinkTemplates = src.Fabric.LinkTemplates
+		return nil
+	},
+}
+
+// reloadSound sets *dst to sound unless it is unknown; empty falls back to the default sound
+func reloadSound(dst *string, sound string) error {
+	if sound != "" && sound != soundOff && !validSound(sound) {
+		return fmt.Errorf("unsupported sound: %s", sound)
+	}
+	*dst = sound
+	return nil
+}
+
+// startConfigWatcher applies changes to config.yaml while the app runs; failures only disable hot-reload
+func (a *App) startConfigWatcher() {
+	if err := config.Watch(a.ctx, func() { a.reloadConfig() }); err != nil {
+		logger.Warn("Config hot-reload disabled", logger.Err(err))
+	}
+}
+
+// reloadConfig loads config.yaml again and applies the settings that are safe to change at runtime, emitting
+// config:applied and config:rejected events about the rest
+// The app's own saves trigger it too; they leave nothing to apply, so no events are emitted
+func (a *App) reloadConfig() ConfigReload {
+	loaded, err := config.Reload()
+	if err != nil {
+		logger.Warn("Config change rejected", logger.Err(err))
+		result := ConfigReload{Applied: []string{}, Rejected: map[string]string{}, Error: err.Error()}
+		a.emitEvent(EventConfigRejected, result)
+		return result
+	}
+
+	// The changes are applied to a copy swapped in whole, so bindings reading the config never see it half-updated
+	result := ConfigReload{Applied: []string{}, Rejected: map[string]string{}}
+	a.updateConfig(func(cfg *config.Config) error {
+		// Settings adjusted at startup, e.g. the default client ID, differ from the file without having been edited
+		var edited []string
+		if a.fileConfig != nil {
+			edited = config.Diff(a.fileConfig, loaded)
+		}
+		for _, key := range config.Diff(cfg, loaded) {
+			apply, ok := hotReloadable[key]
+			if !ok {
+				if a.fileConfig == nil || slices.Contains(edited, key) {
+					result.Rejected[key] = restartRequired
+				}
+				continue
+			}
+			if err := apply(cfg, loaded); err != nil {
+				result.Rejected[key] = err.Error()
+				continue
+			}
+			result.Applied = append(result.Applied, key)
+		}
+		a.fileConfig = loaded
+		return nil
+	})
+
+	if len(result.Applied) > 0 {
+		logger.Info("Config changes applied", "keys", strings.Join(result.Applied, ","))
+		a.emitEvent(EventConfigApplied, result)
+		a.emitEvent(EventSettingsChanged, a.GetSettings())
+	}
+	if len(result.Rejected) > 0 {
+		keys := make([]string, 0, len(result.Rejected))
+		for key := range result.Rejected {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		logger.Warn("Config changes not applied", "keys", strings.Join(keys, ","))
+		a.emitEvent(EventConfigRejected, result)
+	}
+	return result
+}
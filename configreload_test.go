@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"better-fabric-monitor/internal/config"
+)
+
+// Bindings read the config while reloads apply changes to config.yaml; run with -race
+func TestReloadConfigConcurrentReads(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	path, err := config.FilePath()
+	if err != nil {
+		t.Fatalf("FilePath: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeInterval := func(minutes int) {
+		if err := os.WriteFile(path, []byte(fmt.Sprintf("polling:\n  interval: %dm\n", minutes)), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeInterval(2)
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	a := NewApp()
+	loaded := *cfg
+	a.fileConfig = &loaded
+	a.currentConfig.Store(cfg)
+
+	done := make(chan struct{})
+	var readers sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		readers.Add(1)
+		go func() {
+			defer readers.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				if interval := a.GetSettings().Polling.IntervalSeconds; interval != 120 && interval != 180 {
+					t.Errorf("polling interval = %ds, want 120 or 180", interval)
+					return
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < 20; i++ {
+		minutes := 2 + (i+1)%2
+		writeInterval(minutes)
+		result := a.reloadConfig()
+		if result.Error != "" {
+			t.Fatalf("reloadConfig: %s", result.Error)
+		}
+		if got := a.config().Polling.Interval; got != time.Duration(minutes)*time.Minute {
+			t.Fatalf("polling interval after reload = %v, want %dm", got, minutes)
+		}
+	}
+	close(done)
+	readers.Wait()
+
+	// The loaded config the reloads started from is left as it was
+	if cfg.Polling.Interval != 2*time.Minute {
+		t.Errorf("previous config changed to %v", cfg.Polling.Interval)
+	}
+}
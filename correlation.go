@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"better-fabric-monitor/internal/api"
+)
+
+// Defaults and thresholds of the failure correlation
+const (
+	defaultCorrelationWindowMinutes = 30
+	minSharedFailureWindows         = 3   // Windows two items must both have failed in
+	minFailureJaccard               = 0.3 // Share of the windows either item failed in that both did
+)
+
+// GetFailureCorrelations finds items whose runs of the last days tend to fail in the same windows of windowMinutes,
+// as when they share an upstream source or a capacity event, and groups items linked through such pairs
+func (a *App) GetFailureCorrelations(days int, windowMinutes int, workspaceIDs []string, itemTypes []string, itemNameSearch string) api.FailureCorrelationResult {
+	if a.db() == nil {
+		return api.FailureCorrelationResult{Error: "Database not initialized"}
+	}
+
+	if days <= 0 {
+		days = 30
+	}
+	if windowMinutes <= 0 {
+		windowMinutes = defaultCorrelationWindowMinutes
+	}
+	window := time.Duration(windowMinutes) * time.Minute
+
+	groups, err := a.db().GetFailureCorrelations(days, window, minSharedFailureWindows, minFailureJaccard,
+		a.analyticsWorkspaceIDs(workspaceIDs), itemTypes, itemNameSearch)
+	if err != nil {
+		return api.FailureCorrelationResult{Error: fmt.Sprintf("Failed to correlate failures: %v", err)}
+	}
+
+	result := api.FailureCorrelationResult{Days: days, WindowMinutes: windowMinutes, Groups: make([]api.FailureCorrelationGroup, 0, len(groups))}
+	for _, g := range groups {
+		group := api.FailureCorrelationGroup{Items: g.Items, Pairs: g.Pairs, SharedWindows: make([]string, len(g.SharedWindows))}
+		for i, start := range g.SharedWindows {
+			group.SharedWindows[i] = start.UTC().Format(time.RFC3339)
+		}
+		result.Groups = append(result.Groups, group)
+	}
+	return result
+}
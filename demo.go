@@ -0,0 +1,83 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"better-fabric-monitor/internal/demo"
+	"better-fabric-monitor/internal/logger"
+)
+
+const (
+	// demoDatabaseName is the database demo mode uses, next to the configured one
+	demoDatabaseName = "fabric-monitor-demo.db"
+	// demoHistoryDays is how much run history demo data covers
+	demoHistoryDays = 30
+	// demoSeed keeps generated demo data the same between runs
+	demoSeed = 42
+)
+
+// errDemoMode is returned by bindings that call the Fabric API while demo data is shown
+var errDemoMode = errors.New("demo mode shows generated sample data; the Fabric API is not used")
+
+// demoMode reports whether the app serves generated sample data instead of a tenant's
+func (a *App) demoMode() bool {
+	return a.config() != nil && a.config().App.DemoMode
+}
+
+// IsDemoMode tells the UI it is showing generated sample data
+func (a *App) IsDemoMode() bool {
+	return a.demoMode()
+}
+
+// demoDatabasePath returns the demo database path, in the same directory as the configured database
+func demoDatabasePath(dbPath string) string {
+	return filepath.Join(filepath.Dir(dbPath), demoDatabaseName)
+}
+
+// seedDemoData fills an empty demo database with sample data
+func (a *App) seedDemoData() {
+	if a.writable() != nil {
+		return
+	}
+	if len(a.GetWorkspacesFromCache()) > 0 {
+		logger.Info("Demo mode: using existing sample data")
+		return
+	}
+	if err := a.populateDemoData(); err != nil {
+		logger.Error("Demo mode: failed to generate sample data", logger.Err(err))
+	}
+}
+
+// RegenerateDemoData replaces the demo database contents with sample data ending now
+func (a *App) RegenerateDemoData() error {
+	if !a.demoMode() {
+		return fmt.Errorf("demo mode is not enabled")
+	}
+	if err := a.writable(); err != nil {
+		return err
+	}
+	if !a.background.Begin() {
+		return errShuttingDown
+	}
+	defer a.background.Done()
+
+	if err := a.db().ClearData(); err != nil {
+		return fmt.Errorf("failed to clear demo data: %w", err)
+	}
+	return a.populateDemoData()
+}
+
+// populateDemoData generates sample data into the open database
+func (a *App) populateDemoData() error {
+	start := time.Now()
+	summary, err := demo.Populate(a.db(), time.Now(), demoHistoryDays, demoSeed)
+	if err != nil {
+		return err
+	}
+	logger.Info("Demo mode: generated sample data", "workspaces", summary.Workspaces, "items", summary.Items,
+		"jobs", summary.Jobs, "sessions", summary.Sessions, "durationMs", time.Since(start).Milliseconds())
+	return nil
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+
+	"better-fabric-monitor/internal/api"
+	"better-fabric-monitor/internal/db"
+)
+
+// defaultDependencyDays is how far back GetDependencyGraph reads pipeline runs when no window is given
+const defaultDependencyDays = 30
+
+// GetDependencyGraph returns the item dependency graph derived from the activity runs of pipeline runs
+// started in the last days: which pipelines invoke which pipelines and notebooks, what copy activities
+// read and write, and which invoked items run after one another
+// With itemID set, only the items upstream and downstream of it are returned, e.g. the parent
+// pipelines of a failing notebook and what runs after it
+func (a *App) GetDependencyGraph(days int, workspaceIDs []string, itemID string) api.DependencyGraphResult {
+	if a.db() == nil {
+		return api.DependencyGraphResult{Error: "Database not initialized"}
+	}
+
+	if days <= 0 {
+		days = defaultDependencyDays
+	}
+
+	graph, err := a.db().GetDependencyGraph(days, a.analyticsWorkspaceIDs(workspaceIDs))
+	if err != nil {
+		return api.DependencyGraphResult{Error: fmt.Sprintf("Failed to build dependency graph: %v", err)}
+	}
+	if itemID == "" {
+		return api.DependencyGraphResult{Nodes: graph.Nodes, Edges: graph.Edges}
+	}
+
+	upstream := reachableItems(graph.Edges, itemID, false)
+	downstream := reachableItems(graph.Edges, itemID, true)
+	keep := map[string]bool{itemID: true}
+	for _, id := range append(upstream, downstream...) {
+		keep[id] = true
+	}
+
+	result := api.DependencyGraphResult{
+		Nodes:      []db.DependencyNode{},
+		Edges:      []db.DependencyEdge{},
+		Upstream:   upstream,
+		Downstream: downstream,
+	}
+	for _, node := range graph.Nodes {
+		if keep[node.ItemID] {
+			result.Nodes = append(result.Nodes, node)
+		}
+	}
+	for _, edge := range graph.Edges {
+		if keep[edge.From] && keep[edge.To] {
+			result.Edges = append(result.Edges, edge)
+		}
+	}
+	return result
+}
+
+// reachableItems returns the items reachable from itemID along edges, or against them when
+// downstream is false, nearest first
+func reachableItems(edges []db.DependencyEdge, itemID string, downstream bool) []string {
+	next := make(map[string][]string)
+	for _, edge := range edges {
+		if downstream {
+			next[edge.From] = append(next[edge.From], edge.To)
+		} else {
+			next[edge.To] = append(next[edge.To], edge.From)
+		}
+	}
+
+	visited := map[string]bool{itemID: true}
+	reached := []string{}
+	queue := []string{itemID}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, id := range next[current] {
+			if !visited[id] {
+				visited[id] = true
+				reached = append(reached, id)
+				queue = append(queue, id)
+			}
+		}
+	}
+	return reached
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"better-fabric-monitor/internal/api"
+	"better-fabric-monitor/internal/config"
+	"better-fabric-monitor/internal/db"
+	"better-fabric-monitor/internal/logger"
+	"better-fabric-monitor/internal/secrets"
+)
+
+// diagnosticsSyncRuns is how many recent sync runs a diagnostics bundle includes
+const diagnosticsSyncRuns = 10
+
+// redacted replaces secrets in the config written to a diagnostics bundle
+const redacted = "<redacted>"
+
+// diagnosticsSystem describes the app and the machine it runs on
+type diagnosticsSystem struct {
+	CollectedAt   string `json:"collectedAt"`
+	AppVersion    string `json:"appVersion"`
+	GoVersion     string `json:"goVersion"`
+	OS            string `json:"os"`
+	Arch          string `json:"arch"`
+	NumCPU        int    `json:"numCpu"`
+	DemoMode      bool   `json:"demoMode"`
+	Offline       bool   `json:"offline"`
+	DatabaseInUse bool   `json:"databaseInUse"`
+	Authenticated bool   `json:"authenticated"`
+}
+
+// diagnosticsSync is the state of the current or last sync and the metrics of recent runs
+type diagnosticsSync struct {
+	Status     SyncStatus       `json:"status"`
+	LastSync   string           `json:"lastSync,omitempty"`
+	RecentRuns []db.SyncMetrics `json:"recentRuns"`
+	Error      string           `json:"error,omitempty"`
+}
+
+// CollectDiagnostics writes a zip of recent logs, the sanitized config, database stats and the last sync report
+// next to the database, for attaching to bug reports
+func (a *App) CollectDiagnostics() api.DiagnosticsResult {
+	if a.config() == nil {
+		return api.DiagnosticsResult{Error: "Configuration not loaded"}
+	}
+
+	dir := filepath.Join(filepath.Dir(a.config().Database.Path), "diagnostics")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return api.DiagnosticsResult{Error: fmt.Sprintf("Failed to create diagnostics directory: %v", err)}
+	}
+	path, err := filepath.Abs(filepath.Join(dir, fmt.Sprintf("diagnostics-%s.zip", time.Now().Format("20060102-150405"))))
+	if err != nil {
+		return api.DiagnosticsResult{Error: fmt.Sprintf("Failed to resolve diagnostics path: %v", err)}
+	}
+
+	if err := a.writeDiagnostics(path); err != nil {
+		os.Remove(path)
+		logger.Error("Failed to collect diagnostics", logger.Err(err))
+		return api.DiagnosticsResult{Error: fmt.Sprintf("Failed to collect diagnostics: %v", err)}
+	}
+
+	logger.Info("Diagnostics written", "path", path)
+	return api.DiagnosticsResult{Path: path}
+}
+
+// writeDiagnostics writes the diagnostics bundle to path
+func (a *App) writeDiagnostics(path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	archive := zip.NewWriter(file)
+	entries := []struct {
+		name    string
+		content interface{}
+	}{
+		{"system.json", a.diagnosticsSystem()},
+		{"config.json", sanitizedConfig(a.config())},
+		{"database.json", a.diagnosticsDatabase()},
+		{"sync.json", a.diagnosticsSync()},
+	}
+	for _, entry := range entries {
+		w, err := archive.Create(entry.name)
+		if err != nil {
+			return err
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(entry.content); err != nil {
+			return fmt.Errorf("failed to write %s: %w", entry.name, err)
+		}
+	}
+
+	w, err := archive.Create("logs.txt")
+	if err != nil {
+		return err
+	}
+	for _, entry := range logger.GetAll() {
+		if _, err := fmt.Fprintf(w, "[%s] %s: %s\n", entry.Timestamp, entry.Level, entry.Text()); err != nil {
+			return err
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+// diagnosticsSystem returns the app version, platform and mode flags
+func (a *App) diagnosticsSystem() diagnosticsSystem {
+	return diagnosticsSystem{
+		CollectedAt:   time.Now().Format(time.RFC3339),
+		AppVersion:    a.GetAppVersion(),
+		GoVersion:     runtime.Version(),
+		OS:            runtime.GOOS,
+		Arch:          runtime.GOARCH,
+		NumCPU:        runtime.NumCPU(),
+		DemoMode:      a.demoMode(),
+		Offline:       a.offline(),
+		DatabaseInUse: a.databaseInUse,
+		Authenticated: a.currentToken != nil,
+	}
+}
+
+// diagnosticsDatabase returns the database stats, or why they are unavailable
+func (a *App) diagnosticsDatabase() interface{} {
+	if a.db() == nil {
+		return map[string]string{"error": "Database not initialized"}
+	}
+	stats, err := a.db().GetDatabaseStats()
+	if err != nil {
+		return map[string]string{"error": err.Error()}
+	}
+	return stats
+}
+
+// diagnosticsSync returns the sync status and the metrics of the most recent runs
+func (a *App) diagnosticsSync() diagnosticsSync {
+	report := diagnosticsSync{
+		Status:     a.GetSyncStatus(),
+		LastSync:   a.GetLastSyncTime(),
+		RecentRuns: []db.SyncMetrics{},
+	}
+	if a.db() == nil {
+		return report
+	}
+	metrics, err := a.db().GetSyncMetrics(diagnosticsSyncRuns)
+	if err != nil {
+		report.Error = err.Error()
+		return report
+	}
+	if metrics != nil {
+		report.RecentRuns = metrics
+	}
+	return report
+}
+
+// sanitizedConfig returns a copy of cfg with secrets replaced, safe to share in a bug report
+func sanitizedConfig(cfg *config.Config) config.Config {
+	sanitized := *cfg
+	// References to the OS credential manager only name a secret, so they are kept to show where it comes from
+	for _, secret := range sanitized.Secrets() {
+		if *secret != "" && !secrets.IsRef(*secret) {
+			*secret = redacted
+		}
+	}
+	// Webhook URLs often embed an integration key
+	for _, url := range []*string{&sanitized.Notifications.Webhook.URL, &sanitized.Notifications.Escalation.Webhook.URL} {
+		if *url != "" {
+			*url = redacted
+		}
+	}
+	// Collector headers usually carry an API key; the map is copied so cfg keeps the real values
+	if len(cfg.Tracing.Headers) > 0 {
+		sanitized.Tracing.Headers = make(map[string]string, len(cfg.Tracing.Headers))
+		for name := range cfg.Tracing.Headers {
+			sanitized.Tracing.Headers[name] = redacted
+		}
+	}
+	return sanitized
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"better-fabric-monitor/internal/config"
+	"better-fabric-monitor/internal/secrets"
+)
+
+func TestSanitizedConfig(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Auth.ClientSecret = "client-secret-value"
+	cfg.Database.EncryptionKey = secrets.Ref("database.encryption_key")
+	cfg.Notifications.Webhook.URL = "https://events.example.com/hook?key=primary-key"
+	cfg.Notifications.Webhook.Secret = "primary-secret"
+	cfg.Notifications.Escalation.Webhook.URL = "https://pager.example.com/integration/escalation-key"
+	cfg.Notifications.Escalation.Webhook.Secret = "escalation-secret"
+	cfg.Tracing.Headers = map[string]string{"x-api-key": "collector-key"}
+
+	sanitized := sanitizedConfig(cfg)
+	out, err := json.Marshal(sanitized)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	for _, secret := range []string{"client-secret-value", "primary-key", "primary-secret", "escalation-key", "escalation-secret", "collector-key"} {
+		if strings.Contains(string(out), secret) {
+			t.Errorf("sanitized config contains %q", secret)
+		}
+	}
+
+	if sanitized.Notifications.Webhook.URL != redacted {
+		t.Errorf("webhook URL = %q, want %q", sanitized.Notifications.Webhook.URL, redacted)
+	}
+	if sanitized.Notifications.Escalation.Webhook.URL != redacted {
+		t.Errorf("escalation webhook URL = %q, want %q", sanitized.Notifications.Escalation.Webhook.URL, redacted)
+	}
+	if sanitized.Database.EncryptionKey != cfg.Database.EncryptionKey {
+		t.Errorf("credential manager reference = %q, want it kept as %q", sanitized.Database.EncryptionKey, cfg.Database.EncryptionKey)
+	}
+
+	// The live config keeps its real values
+	if cfg.Notifications.Escalation.Webhook.URL != "https://pager.example.com/integration/escalation-key" {
+		t.Errorf("escalation webhook URL of the live config changed to %q", cfg.Notifications.Escalation.Webhook.URL)
+	}
+	if cfg.Tracing.Headers["x-api-key"] != "collector-key" {
+		t.Errorf("tracing header of the live config changed to %q", cfg.Tracing.Headers["x-api-key"])
+	}
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"better-fabric-monitor/internal/config"
+	"better-fabric-monitor/internal/db"
+	"better-fabric-monitor/internal/logger"
+	"better-fabric-monitor/internal/notify"
+)
+
+// Digest frequencies accepted by notifications.digest.frequency
+const (
+	digestDaily  = "daily"
+	digestWeekly = "weekly"
+)
+
+// digestCheckInterval is how often the scheduler checks whether a digest is due
+const digestCheckInterval = time.Minute
+
+// digestListLimit caps the slowest items and SLA breaches listed in a digest
+const digestListLimit = 10
+
+// digestSchedule is when digests are due, parsed from the config
+type digestSchedule struct {
+	frequency string
+	minute    int          // Minutes after local midnight
+	weekday   time.Weekday // Only used by weekly digests
+}
+
+// parseDigestSchedule validates cfg; ok is false when digests are off
+func parseDigestSchedule(cfg config.DigestConfig) (schedule digestSchedule, ok bool, err error) {
+	frequency := strings.ToLower(strings.TrimSpace(cfg.Frequency))
+	switch frequency {
+	case "", "off":
+		return digestSchedule{}, false, nil
+	case digestDaily, digestWeekly:
+	default:
+		return digestSchedule{}, false, fmt.Errorf("unknown digest frequency %q: use daily, weekly or off", cfg.Frequency)
+	}
+
+	at, err := time.Parse("15:04", strings.TrimSpace(cfg.At))
+	if err != nil {
+		return digestSchedule{}, false, fmt.Errorf("invalid digest time %q, expected HH:MM", cfg.At)
+	}
+	schedule = digestSchedule{frequency: frequency, minute: at.Hour()*60 + at.Minute()}
+
+	if frequency == digestWeekly {
+		weekday, ok := parseWeekday(cfg.Weekday)
+		if !ok {
+			return digestSchedule{}, false, fmt.Errorf("invalid digest weekday %q", cfg.Weekday)
+		}
+		schedule.weekday = weekday
+	}
+	return schedule, true, nil
+}
+
+// parseWeekday parses an English day name such as monday or Mon
+func parseWeekday(value string) (time.Weekday, bool) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		name := strings.ToLower(day.String())
+		if value == name || len(value) >= 3 && strings.HasPrefix(name, value) {
+			return day, true
+		}
+	}
+	return 0, false
+}
+
+// lastDue returns the most recent time at or before now that a digest was due, in local time
+func (s digestSchedule) lastDue(now time.Time) time.Time {
+	now = now.Local()
+	due := time.Date(now.Year(), now.Month(), now.Day(), s.minute/60, s.minute%60, 0, 0, now.Location())
+	if due.After(now) {
+		due = due.AddDate(0, 0, -1)
+	}
+	if s.frequency == digestWeekly {
+		due = due.AddDate(0, 0, -((int(due.Weekday()) - int(s.weekday) + 7) % 7))
+	}
+	return due
+}
+
+// digestEvent builds the digest of the daily or weekly period ending at to
+// Finished runs longer than the long-running threshold are listed as SLA breaches
+func digestEvent(database *db.Database, cfg config.NotificationConfig, frequency string, to time.Time) (notify.Event, error) {
+	from, period := to.AddDate(0, 0, -1), "Daily"
+	if frequency == digestWeekly {
+		from, period = to.AddDate(0, 0, -7), "Weekly"
+	}
+
+	digest, err := database.GetDigest(from.UTC(), to.UTC(), cfg.LongRunningThreshold, digestListLimit)
+	if err != nil {
+		return notify.Event{}, fmt.Errorf("failed to build digest: %w", err)
+	}
+	return notify.DigestEvent(period, digest, cfg.Digest.Format)
+}
+
+// startDigestScheduler sends the configured daily or weekly digest through the notification channels
+// A digest missed while the app was closed is sent once on the next start
+func (a *App) startDigestScheduler() {
+	schedule, ok, err := parseDigestSchedule(a.config().Notifications.Digest)
+	if err != nil {
+		logger.Warn("Digest disabled", logger.Err(err))
+		return
+	}
+	// A read-only database belongs to another instance, which sends the digests
+	if !ok || a.notifier.Empty() || a.db() == nil || a.db().ReadOnly() {
+		return
+	}
+
+	a.background.Go(func() {
+		var lastSent time.Time
+		if last, err := a.db().GetLastNotificationTime(notify.EventDigest); err != nil {
+			logger.Warn("Failed to read when the last digest was sent", logger.Err(err))
+		} else if last != nil {
+			lastSent = *last
+		}
+
+		ticker := time.NewTicker(digestCheckInterval)
+		defer ticker.Stop()
+
+		for {
+			if due := schedule.lastDue(time.Now()); lastSent.Before(due) && a.config().Notifications.Enabled {
+				a.sendDigest(schedule.frequency, due)
+				lastSent = time.Now()
+			}
+			select {
+			case <-a.ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	})
+}
+
+// sendDigest delivers the digest of the period ending at due
+func (a *App) sendDigest(frequency string, due time.Time) {
+	event, err := digestEvent(a.db(), a.config().Notifications, frequency, due)
+	if err != nil {
+		logger.Error("Failed to send digest", "frequency", frequency, logger.Err(err))
+		return
+	}
+	logger.Info("Sending digest", "frequency", frequency, "periodEnd", due.Format(time.RFC3339))
+	a.notifier.Notify(a.ctx, event)
+}
@@ -0,0 +1,468 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"better-fabric-monitor/internal/api"
+	"better-fabric-monitor/internal/config"
+	"better-fabric-monitor/internal/fabric"
+	"better-fabric-monitor/internal/logger"
+	"better-fabric-monitor/internal/notify"
+	"better-fabric-monitor/internal/secrets"
+	"better-fabric-monitor/internal/tlsconfig"
+	"better-fabric-monitor/internal/utils"
+)
+
+// Severities of doctor findings
+const (
+	severityError   = "error"   // The feature will not work
+	severityWarning = "warning" // The feature may not work, or works differently than configured
+	severityInfo    = "info"
+)
+
+// doctorDialTimeout bounds each reachability check of an endpoint
+const doctorDialTimeout = 3 * time.Second
+
+// placeholderClientID is the client ID of the sample config, which sign-in treats as unset
+const placeholderClientID = "your-client-id-here"
+
+// Doctor checks the configuration for problems that would otherwise surface as obscure failures at startup or
+// later: paths that cannot be written, credentials that do not fit the sign-in mode, secrets missing from the
+// credential manager, replica paths and unreachable notification endpoints
+// Nothing is changed and no notification is sent
+func (a *App) Doctor() api.DoctorResult {
+	if a.config() == nil {
+		return api.DoctorResult{Error: "Configuration not loaded"}
+	}
+	ctx := a.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	findings := diagnoseConfig(ctx, a.config())
+	logger.Info("Configuration checked", "findings", len(findings))
+	return api.DoctorResult{OK: !hasErrorFinding(findings), Findings: findings}
+}
+
+// diagnoseConfig returns the findings of every configuration check of cfg, errors first
+func diagnoseConfig(ctx context.Context, cfg *config.Config) []api.DoctorFinding {
+	d := &doctor{findings: []api.DoctorFinding{}}
+	d.checkPaths(cfg)
+	d.checkReplica(cfg.Database)
+	d.checkAuth(cfg.Auth)
+	d.checkSecrets(cfg)
+	d.checkNotifications(cfg.Notifications)
+	d.checkWebhookListener(cfg)
+	d.checkFabric(cfg.Fabric)
+	d.checkTLS(cfg.TLS)
+	d.checkApp(cfg)
+	d.checkReachability(ctx, cfg)
+
+	rank := map[string]int{severityError: 0, severityWarning: 1, severityInfo: 2}
+	sort.SliceStable(d.findings, func(i, j int) bool {
+		return rank[d.findings[i].Severity] < rank[d.findings[j].Severity]
+	})
+	return d.findings
+}
+
+// hasErrorFinding reports whether any of findings is an error
+func hasErrorFinding(findings []api.DoctorFinding) bool {
+	for _, finding := range findings {
+		if finding.Severity == severityError {
+			return true
+		}
+	}
+	return false
+}
+
+// doctor collects the findings of a configuration check
+type doctor struct {
+	findings []api.DoctorFinding
+}
+
+// add records a finding about setting
+func (d *doctor) add(severity, check, setting, message, fix string) {
+	d.findings = append(d.findings, api.DoctorFinding{
+		Severity: severity,
+		Check:    check,
+		Setting:  setting,
+		Message:  message,
+		Fix:      fix,
+	})
+}
+
+// checkPaths checks that the database, log and error report locations can be written
+func (d *doctor) checkPaths(cfg *config.Config) {
+	if cfg.Database.Path == "" {
+		d.add(severityError, "paths", "database.path", "No database path is set", "Set database.path to the file the job history is stored in")
+	} else if err := writableFile(cfg.Database.Path); err != nil {
+		d.add(severityError, "paths", "database.path", fmt.Sprintf("The database cannot be created: %v", err),
+			"Point database.path to a file in a directory you can write to")
+	}
+	if cfg.Database.TempDir != "" {
+		if err := writableDir(cfg.Database.TempDir); err != nil {
+			d.add(severityWarning, "paths", "database.temp_dir", fmt.Sprintf("Large queries cannot spill to disk: %v", err),
+				"Point database.temp_dir to a directory you can write to, or leave it empty")
+		}
+	}
+
+	if cfg.App.LogFile.Enabled {
+		if dir, err := logFileDir(cfg.App.LogFile); err != nil {
+			d.add(severityWarning, "paths", "app.log_file.dir", fmt.Sprintf("No log directory: %v", err), "Set app.log_file.dir")
+		} else if err := writableDir(dir); err != nil {
+			d.add(severityWarning, "paths", "app.log_file.dir", fmt.Sprintf("Logs cannot be written: %v", err),
+				"Point app.log_file.dir to a directory you can write to, or disable app.log_file")
+		}
+	}
+
+	reporting := cfg.App.ErrorReporting
+	if reporting.Enabled && (reporting.Dir != "" || reporting.Endpoint == "") {
+		dir := reporting.Dir
+		if dir == "" {
+			dataDir, err := config.GetDataDir()
+			if err != nil {
+				d.add(severityWarning, "paths", "app.error_reporting.dir", fmt.Sprintf("No app data directory: %v", err), "Set app.error_reporting.dir")
+				return
+			}
+			dir = filepath.Join(dataDir, "reports")
+		}
+		if err := writableDir(dir); err != nil {
+			d.add(severityWarning, "paths", "app.error_reporting.dir", fmt.Sprintf("Error reports cannot be saved: %v", err),
+				"Point app.error_reporting.dir to a directory you can write to")
+		}
+	}
+}
+
+// checkReplica checks the Parquet export and read-only replica paths when the replica is enabled
+func (d *doctor) checkReplica(cfg config.DatabaseConfig) {
+	if !cfg.EnableReadOnlyReplica {
+		return
+	}
+	const disable = "or set database.enable_readonly_replica to false"
+
+	if cfg.ParquetPath == "" {
+		d.add(severityError, "replica", "database.parquet_path", "The replica is enabled, but no Parquet export directory is set",
+			"Set database.parquet_path, "+disable)
+	} else if err := writableDir(cfg.ParquetPath); err != nil {
+		d.add(severityError, "replica", "database.parquet_path", fmt.Sprintf("Parquet files cannot be exported: %v", err),
+			"Point database.parquet_path to a directory you can write to, "+disable)
+	}
+
+	if cfg.ReadOnlyPath == "" {
+		d.add(severityError, "replica", "database.readonly_path", "The replica is enabled, but no replica database path is set",
+			"Set database.readonly_path, "+disable)
+		return
+	}
+	if samePath(cfg.ReadOnlyPath, cfg.Path) {
+		d.add(severityError, "replica", "database.readonly_path", "The replica database is the database itself, so exports would overwrite it",
+			"Point database.readonly_path to a different file than database.path")
+		return
+	}
+	if err := writableFile(cfg.ReadOnlyPath); err != nil {
+		d.add(severityError, "replica", "database.readonly_path", fmt.Sprintf("The replica database cannot be created: %v", err),
+			"Point database.readonly_path to a file in a directory you can write to, "+disable)
+	}
+}
+
+// checkAuth checks that the configured credentials fit the sign-in they are used for
+func (d *doctor) checkAuth(cfg config.AuthConfig) {
+	noClientID := cfg.ClientID == "" || cfg.ClientID == placeholderClientID
+	if cfg.ClientSecret == "" {
+		if noClientID {
+			d.add(severityInfo, "auth", "auth.client_id", "No client ID is set, so sign-in uses the Microsoft PowerShell public client",
+				"Set auth.client_id to sign in with your own app registration")
+		}
+		return
+	}
+
+	// A client secret switches the sync command to service principal sign-in
+	if cfg.TenantID == "" {
+		d.add(severityError, "auth", "auth.tenant_id", "A client secret is set, but no tenant; service principal sign-in needs one",
+			"Set auth.tenant_id to your Entra tenant ID, or pass --tenant to the sync command")
+	}
+	if noClientID {
+		d.add(severityError, "auth", "auth.client_id", "A client secret is set, but not the client ID of the app registration it belongs to",
+			"Set auth.client_id to the application (client) ID of the service principal")
+	}
+}
+
+// checkSecrets checks that every secret referencing the OS credential manager can be read
+func (d *doctor) checkSecrets(cfg *config.Config) {
+	all := cfg.Secrets()
+	keys := make([]string, 0, len(all))
+	for key := range all {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value := *all[key]
+		if !secrets.IsRef(value) {
+			continue
+		}
+		if _, err := secrets.Resolve(value); err != nil {
+			name := strings.TrimPrefix(value, secrets.RefPrefix)
+			d.add(severityError, "secrets", key, fmt.Sprintf("The secret cannot be read: %v", err),
+				fmt.Sprintf("Store it with `%s secret set %s`, or put the value in %s", filepath.Base(os.Args[0]), name, key))
+		}
+	}
+}
+
+// checkNotifications checks the notification channels and digest schedule
+func (d *doctor) checkNotifications(cfg config.NotificationConfig) {
+	channels := 0
+	for _, channel := range []struct {
+		key string
+		cfg config.WebhookChannelConfig
+	}{
+		{"notifications.webhook", cfg.Webhook},
+		{"notifications.escalation.webhook", cfg.Escalation.Webhook},
+	} {
+		if !channel.cfg.Enabled {
+			continue
+		}
+		if _, err := notify.NewWebhookChannel(channel.cfg.URL, ""); err != nil {
+			d.add(severityError, "notifications", channel.key+".url", fmt.Sprintf("The channel is enabled, but %v", err),
+				fmt.Sprintf("Set %s.url to the https URL to post to, or disable the channel", channel.key))
+			continue
+		}
+		channels++
+		for _, eventType := range channel.cfg.Events {
+			if !notify.ValidEventType(eventType) {
+				d.add(severityWarning, "notifications", channel.key+".events", fmt.Sprintf("Unknown event type %q is never sent", eventType),
+					"Remove it or correct its spelling, e.g. job.failed")
+			}
+		}
+		if channel.cfg.QuietHours != "" {
+			if _, err := notify.ParseQuietHours(channel.cfg.QuietHours); err != nil {
+				d.add(severityWarning, "notifications", channel.key+".quiet_hours", fmt.Sprintf("Quiet hours are ignored: %v", err),
+					"Use a local time window such as 22:00-07:00")
+			}
+		}
+	}
+
+	_, digest, err := parseDigestSchedule(cfg.Digest)
+	switch {
+	case err != nil:
+		d.add(severityError, "notifications", "notifications.digest", fmt.Sprintf("Digests are disabled: %v", err),
+			"Correct notifications.digest, or set its frequency to off")
+	case digest && channels == 0:
+		d.add(severityWarning, "notifications", "notifications.digest", "Digests are scheduled, but no notification channel is enabled to send them",
+			"Enable notifications.webhook, or set notifications.digest.frequency to off")
+	}
+	if channels > 0 && !cfg.Enabled {
+		d.add(severityInfo, "notifications", "notifications.enabled", "Notification channels are configured, but notifications are turned off",
+			"Set notifications.enabled to true to deliver them")
+	}
+}
+
+// checkWebhookListener checks that the job event listener can start when it is enabled
+func (d *doctor) checkWebhookListener(cfg *config.Config) {
+	if !cfg.Webhook.Enabled {
+		return
+	}
+	if _, _, err := net.SplitHostPort(cfg.Webhook.Address); err != nil {
+		d.add(severityError, "webhook", "webhook.address", fmt.Sprintf("The listener address is invalid: %v", err),
+			"Set webhook.address to host:port, e.g. 127.0.0.1:8765")
+		return
+	}
+	// Matches startWebhookListener, which stays on loopback without a secret
+	if cfg.Webhook.Secret == "" && !isLoopbackAddress(cfg.Webhook.Address) {
+		d.add(severityError, "webhook", "webhook.secret", "The listener accepts connections from other machines, but no secret is set, so it does not start",
+			"Set webhook.secret, or listen on a loopback address such as 127.0.0.1")
+	}
+	if cfg.App.DemoMode || cfg.App.Offline {
+		d.add(severityWarning, "webhook", "webhook.enabled", "The listener does not start in demo or offline mode",
+			"Turn off app.demo_mode and app.offline to sync from job events")
+	}
+}
+
+// checkFabric checks the workspace scope, deep links and API limits
+func (d *doctor) checkFabric(cfg config.FabricConfig) {
+	for key, patterns := range map[string][]string{
+		"fabric.include_workspaces": cfg.IncludeWorkspaces,
+		"fabric.exclude_workspaces": cfg.ExcludeWorkspaces,
+	} {
+		if err := fabric.ValidatePatterns(patterns); err != nil {
+			d.add(severityError, "fabric", key, fmt.Sprintf("%v; it matches no workspace", err),
+				"Correct the pattern; * matches any characters and ? a single one")
+		}
+	}
+
+	if err := utils.ValidateFabricURLs(cfg.PortalHost, cfg.LinkTemplates); err != nil {
+		d.add(severityWarning, "fabric", "fabric.link_templates", fmt.Sprintf("Links to Fabric keep the defaults: %v", err),
+			"Correct fabric.portal_host or fabric.link_templates")
+	}
+
+	configured := fabricLimits(cfg.Limits)
+	if bounded := configured.Bounded(); bounded != configured {
+		d.add(severityWarning, "fabric", "fabric.limits", fmt.Sprintf("Limits out of bounds are clamped to %d workspaces and %d items in parallel, %d-%d requests per second starting at %d, and %d retries",
+			bounded.WorkspaceConcurrency, bounded.ItemConcurrency, bounded.MinRPS, bounded.MaxRPS, bounded.InitialRPS, bounded.MaxRetries),
+			"Set fabric.limits to values within the documented ranges")
+	}
+}
+
+// checkTLS checks that the CA bundles can be read and the TLS settings are supported
+func (d *doctor) checkTLS(cfg config.TLSConfig) {
+	err := tlsconfig.Validate(tlsconfig.Options{CAFiles: cfg.CAFiles, MinVersion: cfg.MinVersion})
+	if err != nil {
+		d.add(severityError, "tls", "tls", fmt.Sprintf("HTTPS calls keep the default TLS settings: %v", err),
+			"Point tls.ca_files to readable PEM files and set tls.min_version to 1.2 or 1.3")
+	}
+	if cfg.InsecureSkipVerify {
+		d.add(severityWarning, "tls", "tls.insecure_skip_verify", "Server certificates are not verified, so HTTPS traffic can be intercepted",
+			"Add your proxy's CA certificate to tls.ca_files and turn tls.insecure_skip_verify off")
+	}
+}
+
+// checkApp checks the log level and polling interval
+func (d *doctor) checkApp(cfg *config.Config) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(strings.TrimSpace(cfg.App.LogLevel))); err != nil {
+		d.add(severityWarning, "app", "app.log_level", fmt.Sprintf("Unknown log level %q; info is used instead", cfg.App.LogLevel),
+			"Set app.log_level to debug, info, warn or error")
+	}
+	if cfg.Polling.Enabled && cfg.Polling.Interval < minPollingIntervalSeconds*time.Second {
+		d.add(severityWarning, "app", "polling.interval", fmt.Sprintf("Polling every %s risks throttling by the Fabric API", cfg.Polling.Interval),
+			fmt.Sprintf("Set polling.interval to at least %ds", minPollingIntervalSeconds))
+	}
+	if cfg.Polling.Enabled && cfg.Polling.StatusInterval > 0 && cfg.Polling.StatusInterval < minPollingIntervalSeconds*time.Second {
+		d.add(severityWarning, "app", "polling.status_interval", fmt.Sprintf("Refreshing running jobs every %s risks throttling by the Fabric API", cfg.Polling.StatusInterval),
+			fmt.Sprintf("Set polling.status_interval to at least %ds, or 0 to disable it", minPollingIntervalSeconds))
+	}
+	for feature := range cfg.Features {
+		if !slices.Contains(config.Features, feature) {
+			d.add(severityWarning, "app", "features."+feature, fmt.Sprintf("Unknown feature %q is ignored", feature),
+				"Remove it; known features are "+strings.Join(config.Features, ", "))
+		}
+	}
+}
+
+// checkReachability checks in parallel that the enabled notification, error report and tracing endpoints accept
+// connections; nothing is sent to them
+func (d *doctor) checkReachability(ctx context.Context, cfg *config.Config) {
+	type endpoint struct {
+		check, setting, target string
+	}
+	var endpoints []endpoint
+	if cfg.Notifications.Webhook.Enabled {
+		endpoints = append(endpoints, endpoint{"notifications", "notifications.webhook.url", cfg.Notifications.Webhook.URL})
+	}
+	if cfg.Notifications.Escalation.Webhook.Enabled {
+		endpoints = append(endpoints, endpoint{"notifications", "notifications.escalation.webhook.url", cfg.Notifications.Escalation.Webhook.URL})
+	}
+	if cfg.App.ErrorReporting.Enabled && cfg.App.ErrorReporting.Endpoint != "" {
+		endpoints = append(endpoints, endpoint{"app", "app.error_reporting.endpoint", cfg.App.ErrorReporting.Endpoint})
+	}
+	if cfg.Tracing.Enabled && cfg.Tracing.Endpoint != "" {
+		endpoints = append(endpoints, endpoint{"app", "tracing.endpoint", cfg.Tracing.Endpoint})
+	}
+
+	errs := make([]error, len(endpoints))
+	var wg sync.WaitGroup
+	for i, e := range endpoints {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = dialEndpoint(ctx, e.target)
+		}()
+	}
+	wg.Wait()
+
+	for i, e := range endpoints {
+		if errs[i] != nil {
+			d.add(severityWarning, e.check, e.setting, fmt.Sprintf("The endpoint is not reachable: %v", errs[i]),
+				"Check the URL and that this machine can reach it through its network and proxy")
+		}
+	}
+}
+
+// dialEndpoint opens and closes a TCP connection to the host of target, a URL or a host:port
+func dialEndpoint(ctx context.Context, target string) error {
+	address := target
+	if strings.Contains(target, "://") {
+		parsed, err := url.Parse(target)
+		if err != nil {
+			return err
+		}
+		address = parsed.Host
+		if parsed.Port() == "" {
+			port := "443"
+			if parsed.Scheme == "http" {
+				port = "80"
+			}
+			address = net.JoinHostPort(parsed.Hostname(), port)
+		}
+	}
+	if address == "" {
+		return errors.New("no host")
+	}
+
+	dialer := net.Dialer{Timeout: doctorDialTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+// writableFile returns why the file at path cannot be created or written, without creating it
+func writableFile(path string) error {
+	info, err := os.Stat(path)
+	if err == nil && info.IsDir() {
+		return fmt.Errorf("%s is a directory", path)
+	}
+	return writableDir(filepath.Dir(path))
+}
+
+// writableDir returns why files cannot be created in dir; missing directories are checked through their nearest
+// existing parent, since they are created on first use
+func writableDir(dir string) error {
+	existing, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	for {
+		info, err := os.Stat(existing)
+		if err == nil {
+			if !info.IsDir() {
+				return fmt.Errorf("%s is not a directory", existing)
+			}
+			break
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			return fmt.Errorf("no part of %s exists", dir)
+		}
+		existing = parent
+	}
+
+	probe, err := os.CreateTemp(existing, ".doctor-*")
+	if err != nil {
+		return fmt.Errorf("%s is not writable: %w", existing, errors.Unwrap(err))
+	}
+	probe.Close()
+	return os.Remove(probe.Name())
+}
+
+// samePath reports whether a and b name the same file
+func samePath(a, b string) bool {
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	return errA == nil && errB == nil && absA == absB
+}
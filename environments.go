@@ -0,0 +1,226 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"better-fabric-monitor/internal/api"
+	"better-fabric-monitor/internal/db"
+	"better-fabric-monitor/internal/logger"
+)
+
+// environmentNames maps the environment names found in workspace names to the environment they stand for
+var environmentNames = map[string]string{
+	"dev": "dev", "development": "dev",
+	"test": "test", "tst": "test", "qa": "test",
+	"uat":   "uat",
+	"stage": "staging", "staging": "staging", "preprod": "staging",
+	"prod": "prod", "production": "prod", "prd": "prod",
+}
+
+// environmentOrder is the order environments are listed in, from development to production
+var environmentOrder = map[string]int{"dev": 0, "test": 1, "uat": 2, "staging": 3, "prod": 4}
+
+// environmentToken finds an environment name standing on its own in a workspace name, as in "Sales [DEV]" or "sales-prod"
+var environmentToken = regexp.MustCompile(`(?i)(^|[\s\-_.\[\](){}|/])(` +
+	`dev|development|test|tst|qa|uat|stage|staging|preprod|prod|production|prd)($|[\s\-_.\[\](){}|/])`)
+
+// Thresholds for flagging prod against the other environments
+const (
+	minEnvironmentRuns       = 5    // Finished runs each environment needs before it is compared
+	successRateRegressionPct = 10.0 // Percentage points prod's success rate may fall below another environment's
+	durationRegressionFactor = 1.5  // Multiple of another environment's average duration prod may take
+)
+
+// workspaceEnvironment splits a workspace name into the project it belongs to and its environment,
+// so "Sales [DEV]" and "Sales-Prod" are the dev and prod workspaces of project "sales"
+// ok is false when the name holds no environment
+func workspaceEnvironment(name string) (project, environment string, ok bool) {
+	match := environmentToken.FindStringSubmatchIndex(name)
+	if match == nil {
+		return "", "", false
+	}
+	environment = environmentNames[strings.ToLower(name[match[4]:match[5]])]
+	project = strings.ToLower(strings.Join(strings.FieldsFunc(name[:match[2]]+" "+name[match[7]:], func(r rune) bool {
+		return strings.ContainsRune(" -_.[](){}|/", r)
+	}), " "))
+	return project, environment, true
+}
+
+// normalizeEnvironment turns an environment name into the one it stands for, keeping names it doesn't know
+func normalizeEnvironment(environment string) string {
+	environment = strings.ToLower(strings.TrimSpace(environment))
+	if normalized, ok := environmentNames[environment]; ok {
+		return normalized
+	}
+	return environment
+}
+
+// CompareEnvironments compares the success rates and durations of the runs of the last days of items that exist
+// in several environments, flagging logical items whose prod copy fails more often or runs slower than another copy
+// Items mapped with SetEnvironmentMapping are grouped by logical name; other items are matched by name and type
+// across workspaces whose names differ only in an environment such as dev, test, uat, staging or prod
+func (a *App) CompareEnvironments(days int) api.EnvironmentComparisonResult {
+	if a.db() == nil {
+		return api.EnvironmentComparisonResult{Error: "Database not initialized"}
+	}
+
+	if days <= 0 {
+		days = 30
+	}
+
+	items, err := a.db().GetEnvironmentItemStats(days)
+	if err != nil {
+		return api.EnvironmentComparisonResult{Error: fmt.Sprintf("Failed to get item stats: %v", err)}
+	}
+
+	groups := make(map[string]*api.EnvironmentComparison)
+	var keys []string
+	for _, item := range items {
+		stats := api.EnvironmentStats{
+			ItemID:          item.ItemID,
+			ItemDisplayName: item.ItemDisplayName,
+			WorkspaceID:     item.WorkspaceID,
+			WorkspaceName:   item.WorkspaceName,
+			Runs:            item.Runs,
+			Succeeded:       item.Succeeded,
+			Failed:          item.Failed,
+			SuccessRate:     item.SuccessRate,
+			AvgDurationMs:   item.AvgDurationMs,
+			P90DurationMs:   item.P90DurationMs,
+		}
+		var key, name string
+		if item.LogicalName != nil {
+			key, name = "mapped\x00"+strings.ToLower(*item.LogicalName), *item.LogicalName
+			stats.Environment, stats.Mapped = normalizeEnvironment(*item.Environment), true
+		} else {
+			project, environment, ok := workspaceEnvironment(item.WorkspaceName)
+			if !ok {
+				continue
+			}
+			key = strings.Join([]string{project, item.ItemType, strings.ToLower(item.ItemDisplayName)}, "\x00")
+			name, stats.Environment = item.ItemDisplayName, environment
+		}
+
+		group, ok := groups[key]
+		if !ok {
+			group = &api.EnvironmentComparison{LogicalName: name, ItemType: item.ItemType}
+			groups[key] = group
+			keys = append(keys, key)
+		}
+		group.Environments = append(group.Environments, stats)
+	}
+
+	result := api.EnvironmentComparisonResult{Days: days, Comparisons: []api.EnvironmentComparison{}}
+	for _, key := range keys {
+		group := groups[key]
+		if !spansEnvironments(group.Environments) {
+			continue
+		}
+		sort.SliceStable(group.Environments, func(i, j int) bool {
+			return environmentRank(group.Environments[i].Environment) < environmentRank(group.Environments[j].Environment)
+		})
+		flagRegressions(group)
+		result.Comparisons = append(result.Comparisons, *group)
+	}
+	sort.SliceStable(result.Comparisons, func(i, j int) bool {
+		ci, cj := result.Comparisons[i], result.Comparisons[j]
+		if ri, rj := ci.SuccessRateRegression || ci.DurationRegression, cj.SuccessRateRegression || cj.DurationRegression; ri != rj {
+			return ri
+		}
+		return strings.ToLower(ci.LogicalName) < strings.ToLower(cj.LogicalName)
+	})
+	return result
+}
+
+// spansEnvironments reports whether copies run in more than one environment
+func spansEnvironments(copies []api.EnvironmentStats) bool {
+	for _, c := range copies[1:] {
+		if c.Environment != copies[0].Environment {
+			return true
+		}
+	}
+	return false
+}
+
+// environmentRank orders known environments from dev to prod, followed by the others by name
+func environmentRank(environment string) string {
+	if rank, ok := environmentOrder[environment]; ok {
+		return fmt.Sprintf("0%d", rank)
+	}
+	return "1" + environment
+}
+
+// flagRegressions compares the prod copies of a logical item with the copies in other environments
+func flagRegressions(group *api.EnvironmentComparison) {
+	for _, prod := range group.Environments {
+		if prod.Environment != "prod" || prod.Succeeded+prod.Failed < minEnvironmentRuns {
+			continue
+		}
+		for _, other := range group.Environments {
+			if other.Environment == "prod" || other.Succeeded+other.Failed < minEnvironmentRuns {
+				continue
+			}
+			if prod.SuccessRate < other.SuccessRate-successRateRegressionPct {
+				group.SuccessRateRegression = true
+			}
+			if other.AvgDurationMs > 0 && prod.AvgDurationMs > durationRegressionFactor*other.AvgDurationMs {
+				group.DurationRegression = true
+			}
+		}
+	}
+}
+
+// SetEnvironmentMapping maps an item to a logical item in an environment, for items whose workspace names don't tell
+// their environment apart; every item mapped to the same logical name is compared by CompareEnvironments
+func (a *App) SetEnvironmentMapping(itemID, logicalName, environment string) error {
+	if err := a.writable(); err != nil {
+		return err
+	}
+	logicalName, environment = strings.TrimSpace(logicalName), normalizeEnvironment(environment)
+	if itemID == "" || logicalName == "" || environment == "" {
+		return fmt.Errorf("item ID, logical name and environment are required")
+	}
+
+	mapping := &db.EnvironmentMapping{
+		ItemID:      itemID,
+		LogicalName: logicalName,
+		Environment: environment,
+		MappedAt:    time.Now().UTC(),
+	}
+	if err := a.db().SaveEnvironmentMapping(mapping); err != nil {
+		return fmt.Errorf("failed to save environment mapping: %w", err)
+	}
+	logger.Info("Item mapped to environment", logger.ItemID(itemID), "logicalName", logicalName, "environment", environment)
+	return nil
+}
+
+// RemoveEnvironmentMapping removes the mapping of an item, matching it by workspace name again
+func (a *App) RemoveEnvironmentMapping(itemID string) error {
+	if err := a.writable(); err != nil {
+		return err
+	}
+	if err := a.db().DeleteEnvironmentMapping(itemID); err != nil {
+		return fmt.Errorf("failed to remove environment mapping: %w", err)
+	}
+	logger.Info("Environment mapping removed", logger.ItemID(itemID))
+	return nil
+}
+
+// GetEnvironmentMappings returns the items mapped to logical items by hand
+func (a *App) GetEnvironmentMappings() api.EnvironmentMappingsResult {
+	if a.db() == nil {
+		return api.EnvironmentMappingsResult{Error: "Database not initialized"}
+	}
+	mappings, err := a.db().GetEnvironmentMappings()
+	if err != nil {
+		return api.EnvironmentMappingsResult{Error: fmt.Sprintf("Failed to get environment mappings: %v", err)}
+	}
+	if mappings == nil {
+		mappings = []db.EnvironmentMapping{}
+	}
+	return api.EnvironmentMappingsResult{Mappings: mappings}
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"path/filepath"
+	"time"
+
+	"better-fabric-monitor/internal/config"
+	"better-fabric-monitor/internal/logger"
+	"better-fabric-monitor/internal/reporting"
+)
+
+// errorReportingCloseTimeout bounds how long exiting waits for queued error reports to be delivered
+const errorReportingCloseTimeout = 5 * time.Second
+
+// startErrorReporting starts reporting panics and logged errors as cfg configures, when the user opted in
+func startErrorReporting(cfg config.ErrorReportingConfig, version string) {
+	if !cfg.Enabled {
+		return
+	}
+	dir := cfg.Dir
+	if dir == "" {
+		dataDir, err := config.GetDataDir()
+		if err != nil && cfg.Endpoint == "" {
+			logger.Warn("Error reporting disabled: no app data directory", logger.Err(err))
+			return
+		}
+		if err == nil {
+			dir = filepath.Join(dataDir, "reports")
+		}
+	}
+	reporting.Init(reporting.Options{
+		Endpoint:   cfg.Endpoint,
+		Dir:        dir,
+		AppVersion: version,
+		MaxPerHour: cfg.MaxPerHour,
+	})
+	logger.Info("Error reporting enabled", "dir", dir, "endpoint", cfg.Endpoint != "")
+}
+
+// stopErrorReporting delivers the error reports still queued, giving up after errorReportingCloseTimeout
+func stopErrorReporting() {
+	reporting.Close(errorReportingCloseTimeout)
+}
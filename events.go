@@ -0,0 +1,32 @@
+package main
+
+import (
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// Event names pushed to the frontend via the Wails runtime
+const (
+	EventSyncStarted     = "sync:started"
+	EventSyncProgress    = "sync:progress"
+	EventSyncCompleted   = "sync:completed"
+	EventSyncFailed      = "sync:failed"
+	EventSyncCancelled   = "sync:cancelled"
+	EventJobFailed       = "job:failed"
+	EventJobLongRunning  = "job:long_running"
+	EventJobStuck        = "job:stuck"
+	EventSettingsChanged = "settings:changed"
+	EventItemSynced      = "item:synced"
+	EventPlaySound       = "sound:play"
+	EventConfigApplied   = "config:applied"
+	EventConfigRejected  = "config:rejected"
+	EventRunningJobs     = "jobs:running"
+)
+
+// emitEvent publishes an event to the frontend
+// Safe to call before startup has stored the Wails context (the event is dropped)
+func (a *App) emitEvent(name string, data ...interface{}) {
+	if a.ctx == nil {
+		return
+	}
+	runtime.EventsEmit(a.ctx, name, data...)
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+
+	"better-fabric-monitor/internal/api"
+)
+
+// DiffFailures compares the failures of two failed runs of the same item, in either order, and returns what
+// changed from the earlier to the later one (error code, failing activities, targets and messages), so a
+// repeat of a known failure is told apart from a new one
+func (a *App) DiffFailures(jobIDA, jobIDB string) api.FailureDiffResult {
+	if a.db() == nil {
+		return api.FailureDiffResult{Error: "Database not initialized"}
+	}
+	if jobIDA == jobIDB {
+		return api.FailureDiffResult{Error: "Two different runs are required"}
+	}
+
+	before, err := a.db().GetJobInstanceWithActivities(jobIDA)
+	if err != nil {
+		return api.FailureDiffResult{Error: fmt.Sprintf("Failed to get job %s: %v", jobIDA, err)}
+	}
+	after, err := a.db().GetJobInstanceWithActivities(jobIDB)
+	if err != nil {
+		return api.FailureDiffResult{Error: fmt.Sprintf("Failed to get job %s: %v", jobIDB, err)}
+	}
+	if before.ItemID != after.ItemID {
+		return api.FailureDiffResult{Error: "The runs belong to different items"}
+	}
+	for _, status := range []string{before.Status, after.Status} {
+		if status != "Failed" {
+			return api.FailureDiffResult{Error: fmt.Sprintf("Both runs must have failed (status %s)", status)}
+		}
+	}
+	if after.StartTime.Before(before.StartTime) {
+		before, after = after, before
+	}
+
+	result := api.FailureDiffResult{
+		ItemID:          before.ItemID,
+		ItemDisplayName: before.ItemID,
+		Before:          api.FailureSnapshotFromDB(before),
+		After:           api.FailureSnapshotFromDB(after),
+	}
+	if before.ItemDisplayName != nil {
+		result.ItemDisplayName = *before.ItemDisplayName
+	}
+	result.Changes, result.SameFailure = api.DiffFailures(result.Before, result.After)
+	return result
+}
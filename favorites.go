@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"better-fabric-monitor/internal/api"
+	"better-fabric-monitor/internal/db"
+	"better-fabric-monitor/internal/logger"
+)
+
+// AddFavorite stars an item or a workspace, so the jobs grid and analytics can be narrowed to favorites
+func (a *App) AddFavorite(targetType, targetID string) error {
+	if err := a.writable(); err != nil {
+		return err
+	}
+	if targetType != db.FavoriteTargetItem && targetType != db.FavoriteTargetWorkspace {
+		return fmt.Errorf("unsupported favorite target: %s", targetType)
+	}
+	if targetID == "" {
+		return fmt.Errorf("favorite target ID is required")
+	}
+
+	favorite := &db.Favorite{TargetType: targetType, TargetID: targetID, StarredAt: time.Now().UTC()}
+	if err := a.db().SaveFavorite(favorite); err != nil {
+		return fmt.Errorf("failed to add favorite: %w", err)
+	}
+	logger.Info("Favorite added", "targetType", targetType, "targetID", targetID)
+	return nil
+}
+
+// RemoveFavorite unstars an item or a workspace
+func (a *App) RemoveFavorite(targetType, targetID string) error {
+	if err := a.writable(); err != nil {
+		return err
+	}
+	if err := a.db().DeleteFavorite(targetType, targetID); err != nil {
+		return fmt.Errorf("failed to remove favorite: %w", err)
+	}
+	logger.Info("Favorite removed", "targetType", targetType, "targetID", targetID)
+	return nil
+}
+
+// GetFavorites returns the starred workspaces and items
+func (a *App) GetFavorites() api.FavoritesResult {
+	if a.db() == nil {
+		return api.FavoritesResult{Error: "Database not initialized"}
+	}
+	favorites, err := a.db().GetFavorites()
+	if err != nil {
+		return api.FavoritesResult{Error: fmt.Sprintf("Failed to get favorites: %v", err)}
+	}
+	if favorites == nil {
+		favorites = []db.Favorite{}
+	}
+	return api.FavoritesResult{Favorites: favorites}
+}
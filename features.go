@@ -0,0 +1,38 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+
+	"better-fabric-monitor/internal/api"
+	"better-fabric-monitor/internal/config"
+)
+
+// featureEnabled reports whether the features section turns feature on
+func (a *App) featureEnabled(feature string) bool {
+	return a.config() != nil && a.config().Features[feature]
+}
+
+// featureDisabledError is returned by bindings of a feature the features section leaves off
+func featureDisabledError(feature string) error {
+	return fmt.Errorf("this feature is experimental and disabled; set features.%s to true to enable it", feature)
+}
+
+// validateFeatures returns an error naming the first feature flag that is not known
+func validateFeatures(features map[string]bool) error {
+	for feature := range features {
+		if !slices.Contains(config.Features, feature) {
+			return fmt.Errorf("unknown feature: %s", feature)
+		}
+	}
+	return nil
+}
+
+// GetFeatureFlags returns every experimental subsystem and whether it is enabled, so the UI can hide disabled ones
+func (a *App) GetFeatureFlags() []api.FeatureFlag {
+	flags := make([]api.FeatureFlag, 0, len(config.Features))
+	for _, feature := range config.Features {
+		flags = append(flags, api.FeatureFlag{Name: feature, Enabled: a.featureEnabled(feature)})
+	}
+	return flags
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"better-fabric-monitor/internal/api"
+	"better-fabric-monitor/internal/db"
+	syncer "better-fabric-monitor/internal/sync"
+)
+
+// GetDurationForecasts forecasts the duration of each item's next run from its latest completed runs,
+// following their trend, and the completion time of the item's run in progress if it has one
+// Items with too few completed runs are left out
+func (a *App) GetDurationForecasts(workspaceIDs []string, itemTypes []string, itemNameSearch string) api.DurationForecastsResult {
+	if a.db() == nil {
+		return api.DurationForecastsResult{Error: "Database not initialized"}
+	}
+
+	forecasts, err := a.db().GetDurationForecasts(syncer.ForecastRuns, nil, a.analyticsWorkspaceIDs(workspaceIDs), itemTypes, itemNameSearch)
+	if err != nil {
+		return api.DurationForecastsResult{Error: fmt.Sprintf("Failed to forecast run durations: %v", err)}
+	}
+
+	status := "InProgress"
+	running, err := a.db().GetJobInstances(db.JobFilter{Status: &status})
+	if err != nil {
+		return api.DurationForecastsResult{Error: fmt.Sprintf("Failed to get running jobs: %v", err)}
+	}
+	latestRun := make(map[string]db.JobInstance)
+	for _, job := range running {
+		if latest, ok := latestRun[job.ItemID]; job.EndTime == nil && (!ok || job.StartTime.After(latest.StartTime)) {
+			latestRun[job.ItemID] = job
+		}
+	}
+
+	result := api.DurationForecastsResult{Forecasts: []api.DurationForecast{}}
+	for _, duration := range forecasts {
+		forecast := api.DurationForecast{DurationForecast: duration}
+		if job, ok := latestRun[duration.ItemID]; ok {
+			forecast.RunningJobID = job.ID
+			forecast.ExpectedEndTime = job.StartTime.Add(time.Duration(duration.ExpectedMs) * time.Millisecond).Format(time.RFC3339)
+		}
+		result.Forecasts = append(result.Forecasts, forecast)
+	}
+	return result
+}
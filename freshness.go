@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"better-fabric-monitor/internal/api"
+	"better-fabric-monitor/internal/logger"
+)
+
+// freshnessGrace is the fraction of an item's cadence its data may be late by before it is stale,
+// so a daily load is stale 26 hours after it last succeeded
+const freshnessGrace = 1.0 / 12
+
+// GetDataFreshness reports how long ago each item last succeeded and flags items whose data is stale: older than
+// the threshold set for the item or, without one, than its inferred cadence plus a grace period
+// An item is stale whether or not its runs failed, so loads that stopped running are caught too
+func (a *App) GetDataFreshness(workspaceIDs []string, itemTypes []string, itemNameSearch string) api.DataFreshnessResult {
+	if a.db() == nil {
+		return api.DataFreshnessResult{Error: "Database not initialized"}
+	}
+
+	workspaceIDs = a.analyticsWorkspaceIDs(workspaceIDs)
+	items, err := a.db().GetItemFreshness(workspaceIDs, itemTypes, itemNameSearch)
+	if err != nil {
+		return api.DataFreshnessResult{Error: fmt.Sprintf("Failed to get item freshness: %v", err)}
+	}
+
+	now := time.Now().UTC()
+	cadences := make(map[string]time.Duration)
+	if inferred, err := a.db().GetItemCadences(defaultCadenceDays, now, workspaceIDs, itemTypes, itemNameSearch); err != nil {
+		logger.Warn("Failed to infer run cadences for freshness thresholds", logger.Err(err))
+	} else {
+		for _, item := range inferred {
+			if item.Regular {
+				cadences[item.ItemID] = time.Duration(item.CadenceMs) * time.Millisecond
+			}
+		}
+	}
+
+	result := api.DataFreshnessResult{Items: make([]api.ItemFreshness, 0, len(items))}
+	for _, item := range items {
+		freshness := api.ItemFreshness{ItemFreshness: item}
+		if item.LastSuccessAt != nil {
+			age := now.Sub(*item.LastSuccessAt).Milliseconds()
+			freshness.AgeMs = &age
+		}
+
+		if item.MaxAgeMinutes != nil {
+			freshness.ThresholdMinutes, freshness.ThresholdSource = *item.MaxAgeMinutes, api.FreshnessConfigured
+		} else if cadence, ok := cadences[item.ItemID]; ok {
+			threshold := time.Duration(float64(cadence) * (1 + freshnessGrace))
+			freshness.ThresholdMinutes, freshness.ThresholdSource = int(math.Ceil(threshold.Minutes())), api.FreshnessCadence
+		}
+		if freshness.ThresholdMinutes > 0 {
+			maxAge := time.Duration(freshness.ThresholdMinutes) * time.Minute
+			freshness.Stale = freshness.AgeMs == nil || time.Duration(*freshness.AgeMs)*time.Millisecond > maxAge
+		}
+		if freshness.Stale {
+			result.Stale++
+		}
+		result.Items = append(result.Items, freshness)
+	}
+
+	sort.SliceStable(result.Items, func(i, j int) bool {
+		x, y := result.Items[i], result.Items[j]
+		if x.Stale != y.Stale {
+			return x.Stale
+		}
+		if (x.ThresholdMinutes > 0) != (y.ThresholdMinutes > 0) {
+			return x.ThresholdMinutes > 0
+		}
+		return overdueRatio(x) > overdueRatio(y)
+	})
+	return result
+}
+
+// overdueRatio is an item's data age as a multiple of its threshold, or its age in minutes without one
+// Items that never succeeded come first
+func overdueRatio(f api.ItemFreshness) float64 {
+	if f.AgeMs == nil {
+		return math.Inf(1)
+	}
+	minutes := float64(*f.AgeMs) / float64(time.Minute.Milliseconds())
+	if f.ThresholdMinutes > 0 {
+		return minutes / float64(f.ThresholdMinutes)
+	}
+	return minutes
+}
+
+// SetFreshnessThreshold sets how many minutes after its last successful run an item's data is stale,
+// replacing the threshold inferred from its cadence
+func (a *App) SetFreshnessThreshold(itemID string, maxAgeMinutes int) error {
+	if err := a.writable(); err != nil {
+		return err
+	}
+	if itemID == "" || maxAgeMinutes <= 0 {
+		return fmt.Errorf("item ID and a positive number of minutes are required")
+	}
+	if err := a.db().SaveFreshnessThreshold(itemID, maxAgeMinutes, time.Now().UTC()); err != nil {
+		return fmt.Errorf("failed to save freshness threshold: %w", err)
+	}
+	logger.Info("Freshness threshold set", logger.ItemID(itemID), "maxAgeMinutes", maxAgeMinutes)
+	return nil
+}
+
+// RemoveFreshnessThreshold removes the threshold set for an item, checking it against its cadence again
+func (a *App) RemoveFreshnessThreshold(itemID string) error {
+	if err := a.writable(); err != nil {
+		return err
+	}
+	if err := a.db().DeleteFreshnessThreshold(itemID); err != nil {
+		return fmt.Errorf("failed to remove freshness threshold: %w", err)
+	}
+	logger.Info("Freshness threshold removed", logger.ItemID(itemID))
+	return nil
+}
@@ -0,0 +1,46 @@
+/**
+ * Alert sounds synthesized with the Web Audio API, so no audio files need to be bundled
+ */
+
+// Notes of each sound: [frequency Hz, start offset s, length s]
+const sounds = {
+    chime: [[880, 0, 0.25], [1320, 0.18, 0.4]],
+    beep: [[1000, 0, 0.2]],
+    alarm: [[700, 0, 0.18], [950, 0.22, 0.18], [700, 0.44, 0.18], [950, 0.66, 0.18]],
+};
+
+// A sync can report many failures at once; play at most one sound per window
+const minGapMs = 5000;
+
+let context = null;
+let lastPlayed = 0;
+
+/**
+ * Plays a named alert sound unless one played in the last few seconds
+ */
+export function playSound(name) {
+    const notes = sounds[name];
+    const now = Date.now();
+    if (!notes || now - lastPlayed < minGapMs) {
+        return;
+    }
+    lastPlayed = now;
+
+    try {
+        context = context || new AudioContext();
+        const start = context.currentTime;
+        for (const [frequency, offset, length] of notes) {
+            const oscillator = context.createOscillator();
+            const gain = context.createGain();
+            oscillator.type = name === "alarm" ? "square" : "sine";
+            oscillator.frequency.value = frequency;
+            gain.gain.setValueAtTime(0.2, start + offset);
+            gain.gain.exponentialRampToValueAtTime(0.001, start + offset + length);
+            oscillator.connect(gain).connect(context.destination);
+            oscillator.start(start + offset);
+            oscillator.stop(start + offset + length);
+        }
+    } catch (error) {
+        console.error("Failed to play alert sound:", error);
+    }
+}
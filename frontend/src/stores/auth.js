@@ -112,7 +112,9 @@ export const authActions = {
         }
     },
 
-    continueOffline() {
+    async continueOffline() {
+        // Offline mode is kept by the backend so no binding calls the API
+        await window.go.main.App.SetOfflineMode(true);
         authStore.update(state => ({
             ...state,
             offlineMode: true,
@@ -123,7 +125,8 @@ export const authActions = {
         }));
     },
 
-    exitOfflineMode() {
+    async exitOfflineMode() {
+        await window.go.main.App.SetOfflineMode(false);
         authStore.update(state => ({
             ...state,
             offlineMode: false,
@@ -133,8 +136,15 @@ export const authActions = {
 
     async checkAuth() {
         try {
+            const offlineMode = await window.go.main.App.IsOffline();
             const isAuthenticated = await window.go.main.App.IsAuthenticated();
-            if (isAuthenticated) {
+            if (offlineMode) {
+                authStore.update(state => ({
+                    ...state,
+                    offlineMode: true,
+                    isAuthenticated: true
+                }));
+            } else if (isAuthenticated) {
                 // Get user info if authenticated
                 const user = await window.go.main.App.GetUserInfo();
                 authStore.update(state => ({
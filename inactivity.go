@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"better-fabric-monitor/internal/api"
+)
+
+// Defaults of GetInactiveItems
+const (
+	defaultInactiveDays    = 3
+	inactivityLookbackDays = 90 // How far back the runs an item's cadence is inferred from go
+)
+
+// GetInactiveItems lists items that ran on a regular cadence in the last 90 days but have not run for at least
+// inactiveDays (3 by default) and for more than two of their cadences, as happens when a schedule is disabled or
+// a trigger breaks, most missed runs first
+func (a *App) GetInactiveItems(inactiveDays int, workspaceIDs []string, itemTypes []string, itemNameSearch string) api.InactiveItemsResult {
+	if a.db() == nil {
+		return api.InactiveItemsResult{Error: "Database not initialized"}
+	}
+
+	if inactiveDays <= 0 {
+		inactiveDays = defaultInactiveDays
+	}
+	lookbackDays := max(inactivityLookbackDays, 2*inactiveDays)
+
+	now := time.Now().UTC()
+	items, err := a.db().GetItemCadences(lookbackDays, now, a.analyticsWorkspaceIDs(workspaceIDs), itemTypes, itemNameSearch)
+	if err != nil {
+		return api.InactiveItemsResult{Error: fmt.Sprintf("Failed to infer run cadences: %v", err)}
+	}
+
+	inactiveAfter := time.Duration(inactiveDays) * 24 * time.Hour
+	result := api.InactiveItemsResult{InactiveDays: inactiveDays, LookbackDays: lookbackDays, Items: []api.InactiveItem{}}
+	for _, item := range items {
+		// The cadence comes from the intervals between runs, so the silence since the last doesn't count against it
+		silence := now.Sub(item.LastRunAt)
+		if !item.Regular || silence < inactiveAfter {
+			continue
+		}
+		interval := time.Duration(item.CadenceMs) * time.Millisecond
+		if silence <= 2*interval {
+			continue
+		}
+		result.Items = append(result.Items, api.InactiveItem{
+			ItemID:          item.ItemID,
+			ItemDisplayName: item.ItemDisplayName,
+			ItemType:        item.ItemType,
+			WorkspaceID:     item.WorkspaceID,
+			WorkspaceName:   item.WorkspaceName,
+			Runs:            item.Runs,
+			CadenceMs:       item.CadenceMs,
+			ScheduleMs:      item.ScheduleMs,
+			LastRunAt:       item.LastRunAt.Format(time.RFC3339),
+			InactiveForMs:   silence.Milliseconds(),
+			MissedRuns:      int(silence / interval),
+		})
+	}
+	sort.SliceStable(result.Items, func(i, j int) bool {
+		if result.Items[i].MissedRuns != result.Items[j].MissedRuns {
+			return result.Items[i].MissedRuns > result.Items[j].MissedRuns
+		}
+		return result.Items[i].ItemDisplayName < result.Items[j].ItemDisplayName
+	})
+	return result
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"better-fabric-monitor/internal/config"
+	"better-fabric-monitor/internal/db"
+	"better-fabric-monitor/internal/logger"
+	"better-fabric-monitor/internal/secrets"
+	syncer "better-fabric-monitor/internal/sync"
+)
+
+// errReadOnly is returned by bindings that write when the app was opened read-only
+var errReadOnly = errors.New("opened read-only because another instance is using the database; close it to sync")
+
+// DatabaseStatus tells the UI whether the database is writable, or why it isn't
+type DatabaseStatus struct {
+	InUse           bool   `json:"inUse"`           // Another instance holds the database lock
+	ReadOnly        bool   `json:"readOnly"`        // The Parquet replica is open read-only
+	CanOpenReadOnly bool   `json:"canOpenReadOnly"` // The replica is enabled, so OpenReadOnly can be offered
+	Message         string `json:"message,omitempty"`
+}
+
+// openDatabase takes the single-instance lock and opens the database
+// If another instance holds it the app starts without a database, or read-only when configured to
+func (a *App) openDatabase(dbPath string) {
+	lock, err := db.AcquireInstanceLock(dbPath)
+	if err == nil {
+		a.instanceLock = lock
+		var database *db.Database
+		database, err = openDatabaseFile(dbPath, a.config().Database.EncryptionKey)
+		if err == nil {
+			a.currentDB.Store(database)
+			setDatabaseResources(database, a.config().Database)
+			return
+		}
+		a.releaseInstanceLock()
+	}
+
+	if !errors.Is(err, db.ErrDatabaseInUse) {
+		logger.Error("Failed to initialize database", logger.Err(err))
+		return
+	}
+
+	logger.Warn("Better Fabric Monitor is already running", logger.Err(err))
+	a.databaseInUse = true
+	if a.config().Database.ReadOnlyIfInUse {
+		if err := a.openReadOnly(); err != nil {
+			logger.Error("Failed to open read-only", logger.Err(err))
+		}
+	}
+}
+
+// openDatabaseFile opens the database at path; encryptionKey may reference a secret in the OS credential manager
+func openDatabaseFile(path, encryptionKey string) (*db.Database, error) {
+	key, err := secrets.Resolve(encryptionKey)
+	if err != nil {
+		return nil, fmt.Errorf("database.encryption_key: %w", err)
+	}
+	return db.NewDatabase(path, key)
+}
+
+// setDatabaseResources applies the memory cap, threads and spill directory of cfg to database
+// Settings DuckDB rejects are logged and left at its defaults
+func setDatabaseResources(database *db.Database, cfg config.DatabaseConfig) {
+	resources := db.Resources{MemoryLimitMB: cfg.MemoryLimitMB, Threads: cfg.Threads, TempDir: cfg.TempDir}
+	if err := database.SetResources(resources); err != nil {
+		logger.Warn("Failed to set database resource limits", logger.Err(err))
+	}
+}
+
+// GetDatabaseStatus reports whether another instance holds the database and whether this one is read-only
+func (a *App) GetDatabaseStatus() DatabaseStatus {
+	status := DatabaseStatus{
+		InUse:           a.databaseInUse,
+		ReadOnly:        a.db() != nil && a.db().ReadOnly(),
+		CanOpenReadOnly: a.config() != nil && a.config().Database.EnableReadOnlyReplica,
+	}
+	switch {
+	case status.ReadOnly:
+		status.Message = "Another instance is running. Showing the read-only replica from its last export; syncing is disabled."
+	case status.InUse:
+		status.Message = "Better Fabric Monitor is already running and has the database open. Close the other instance, or open the read-only replica."
+	}
+	return status
+}
+
+// OpenReadOnly opens the Parquet replica read-only after startup found the database in use
+func (a *App) OpenReadOnly() error {
+	if !a.databaseInUse {
+		return fmt.Errorf("the database is not in use by another instance")
+	}
+	if a.db() != nil {
+		return nil
+	}
+	return a.openReadOnly()
+}
+
+// openReadOnly opens the read-only replica in place of the main database
+func (a *App) openReadOnly() error {
+	if !a.config().Database.EnableReadOnlyReplica {
+		return fmt.Errorf("the read-only replica is disabled")
+	}
+
+	database, err := db.OpenReadOnlyDatabase(a.config().Database.ReadOnlyPath)
+	if err != nil {
+		return err
+	}
+	setDatabaseResources(database, a.config().Database)
+	// Bindings and background work may already be running, so the replica only replaces a missing database
+	if !a.currentDB.CompareAndSwap(nil, database) {
+		database.Close()
+		return nil
+	}
+	a.currentSyncer.Store(syncer.New(database, a.syncStatus))
+	logger.Info("Opened read-only replica", "path", a.config().Database.ReadOnlyPath)
+	return nil
+}
+
+// writable returns an error unless the database is open for writing
+func (a *App) writable() error {
+	if a.db() == nil {
+		return fmt.Errorf("database not initialized")
+	}
+	if a.db().ReadOnly() {
+		return errReadOnly
+	}
+	return nil
+}
+
+// apiAvailable returns an error unless bindings may call the Fabric API and store the results
+func (a *App) apiAvailable() error {
+	if err := a.writable(); err != nil {
+		return err
+	}
+	return a.cacheOnly()
+}
+
+// cacheOnly returns why the app serves only local data without calling the API, or nil if it may call it
+func (a *App) cacheOnly() error {
+	switch {
+	case a.demoMode():
+		return errDemoMode
+	case a.offline():
+		return errOffline
+	}
+	return nil
+}
+
+// releaseInstanceLock lets the next instance open the database; called after the database is closed
+func (a *App) releaseInstanceLock() {
+	if err := a.instanceLock.Release(); err != nil {
+		logger.Warn("Failed to release instance lock", logger.Err(err))
+	}
+	a.instanceLock = nil
+}
@@ -0,0 +1,233 @@
+package api
+
+import (
+	"time"
+
+	"better-fabric-monitor/internal/db"
+	"better-fabric-monitor/internal/fabric"
+	"better-fabric-monitor/internal/utils"
+)
+
+// AuthRequiredWorkspace is the marker returned when the session expired
+// When cached data follows it, the marker is flagged so the frontend can filter it out
+func AuthRequiredWorkspace(cachedDataAvailable bool) Workspace {
+	return Workspace{
+		Error:               "authentication_required",
+		Message:             AuthRequiredMessage(cachedDataAvailable),
+		CachedDataAvailable: &cachedDataAvailable,
+		IsErrorMarker:       cachedDataAvailable,
+	}
+}
+
+// AuthRequiredMessage tells the user their session expired, and whether cached data can still be browsed
+func AuthRequiredMessage(cachedDataAvailable bool) string {
+	if cachedDataAvailable {
+		return "Your session has expired. Please sign in again or continue with cached data."
+	}
+	return "Your session has expired. Please sign in again."
+}
+
+// WorkspaceFromFabric converts an API workspace
+func WorkspaceFromFabric(ws fabric.Workspace) Workspace {
+	return Workspace{
+		ID:          ws.ID,
+		DisplayName: ws.DisplayName,
+		Type:        ws.Type,
+		Description: ws.Description,
+		CapacityID:  ws.CapacityID,
+	}
+}
+
+// WorkspaceFromDB converts a cached workspace
+func WorkspaceFromDB(ws db.Workspace) Workspace {
+	result := Workspace{
+		ID:          ws.ID,
+		DisplayName: ws.DisplayName,
+		Type:        ws.Type,
+	}
+	if ws.Description != nil {
+		result.Description = *ws.Description
+	}
+	if ws.CapacityID != nil {
+		result.CapacityID = *ws.CapacityID
+	}
+	return result
+}
+
+// JobFromFabric converts a freshly fetched job, linking notebook runs to their Livy session when known
+func JobFromFabric(job fabric.RecentJob, livyID *string) Job {
+	result := Job{
+		ID:              job.ID,
+		WorkspaceID:     job.WorkspaceID,
+		WorkspaceName:   job.WorkspaceName,
+		ItemID:          job.ItemID,
+		ItemDisplayName: job.ItemDisplayName,
+		ItemType:        job.ItemType,
+		JobType:         job.JobType,
+		InvokerType:     job.InvokeType,
+		Status:          job.Status,
+		StartTime:       job.StartTime.Format(time.RFC3339),
+		DurationMs:      job.DurationMs,
+		FailureReason:   job.FailureReason,
+		RootActivityID:  job.RootActivityID,
+		FabricURL:       utils.GenerateFabricURL(job.WorkspaceID, job.ItemID, job.ItemType, job.ID, livyID),
+	}
+	if job.EndTime != nil {
+		result.EndTime = job.EndTime.Format(time.RFC3339)
+	}
+	return result
+}
+
+// JobFromDB converts a cached job instance
+func JobFromDB(job db.JobInstance) Job {
+	result := Job{
+		ID:              job.ID,
+		WorkspaceID:     job.WorkspaceID,
+		ItemID:          job.ItemID,
+		ItemDisplayName: job.ItemID,  // Fallback to ID if name not available
+		ItemType:        job.JobType, // Fallback to job type
+		JobType:         job.JobType,
+		Status:          job.Status,
+		StartTime:       job.StartTime.Format(time.RFC3339),
+		DurationMs:      job.DurationMs,
+	}
+
+	if job.ItemDisplayName != nil {
+		result.ItemDisplayName = *job.ItemDisplayName
+	}
+	if job.ItemType != nil {
+		result.ItemType = *job.ItemType
+	}
+	if job.WorkspaceName != nil {
+		result.WorkspaceName = *job.WorkspaceName
+	}
+	if job.EndTime != nil {
+		result.EndTime = job.EndTime.Format(time.RFC3339)
+	}
+	if job.FailureReason != nil {
+		result.FailureReason = *job.FailureReason
+	}
+	if job.InvokerType != nil {
+		result.InvokerType = *job.InvokerType
+	}
+	if job.RootActivityID != nil {
+		result.RootActivityID = *job.RootActivityID
+	}
+	if job.RemovedUpstreamAt != nil {
+		result.RemovedUpstreamAt = job.RemovedUpstreamAt.Format(time.RFC3339)
+	}
+
+	result.FabricURL = utils.GenerateFabricURL(job.WorkspaceID, job.ItemID, result.ItemType, job.ID, job.LivyID)
+	return result
+}
+
+// RecentFailuresFromDB converts failed jobs and adds their Fabric deep links
+func RecentFailuresFromDB(failures []db.RecentFailure) []RecentFailure {
+	result := make([]RecentFailure, 0, len(failures))
+	for _, failure := range failures {
+		result = append(result, RecentFailure{
+			ID:              failure.ID,
+			WorkspaceID:     failure.WorkspaceID,
+			WorkspaceName:   failure.WorkspaceName,
+			ItemID:          failure.ItemID,
+			ItemDisplayName: failure.ItemDisplayName,
+			ItemType:        failure.ItemType,
+			JobType:         failure.JobType,
+			StartTime:       failure.StartTime.Format(time.RFC3339),
+			EndTime:         failure.EndTime.Format(time.RFC3339),
+			DurationMs:      failure.DurationMs,
+			FailureReason:   failure.FailureReason,
+			FabricURL:       utils.GenerateFabricURL(failure.WorkspaceID, failure.ItemID, failure.ItemType, failure.ID, failure.LivyID),
+		})
+	}
+	return result
+}
+
+// LongRunningJobsFromDB converts long-running jobs and adds their Fabric deep links
+func LongRunningJobsFromDB(jobs []db.LongRunningJob) []LongRunningJob {
+	result := make([]LongRunningJob, 0, len(jobs))
+	for _, job := range jobs {
+		result = append(result, LongRunningJob{
+			ID:              job.ID,
+			WorkspaceID:     job.WorkspaceID,
+			WorkspaceName:   job.WorkspaceName,
+			ItemID:          job.ItemID,
+			ItemDisplayName: job.ItemDisplayName,
+			ItemType:        job.ItemType,
+			JobType:         job.JobType,
+			StartTime:       job.StartTime.Format(time.RFC3339),
+			DurationMs:      job.DurationMs,
+			AvgDurationMs:   job.AvgDurationMs,
+			DeviationPct:    job.DeviationPct,
+			FabricURL:       utils.GenerateFabricURL(job.WorkspaceID, job.ItemID, job.ItemType, job.ID, job.LivyID),
+		})
+	}
+	return result
+}
+
+// ChildExecutionsFromDB converts child executions and adds Fabric deep links where the child run is known
+func ChildExecutionsFromDB(children []db.ChildExecution) []ChildExecution {
+	result := make([]ChildExecution, 0, len(children))
+	for _, child := range children {
+		c := ChildExecution{
+			ActivityRunID:      child.ActivityRunID,
+			ActivityName:       child.ActivityName,
+			ActivityType:       child.ActivityType,
+			Status:             child.Status,
+			PipelineID:         child.PipelineID,
+			HasChildren:        child.HasChildren,
+			DurationMs:         child.DurationMs,
+			Error:              deref(child.ErrorMessage),
+			ChildJobInstanceID: deref(child.ChildJobInstanceID),
+			ChildPipelineName:  deref(child.ChildPipelineName),
+			ChildNotebookName:  deref(child.ChildItemDisplayName), // For notebooks
+			ChildWorkspaceID:   deref(child.ChildWorkspaceID),
+			ChildItemID:        deref(child.ChildItemID),
+			ChildItemType:      deref(child.ChildItemType),
+		}
+
+		if child.StartTime != nil {
+			c.ActivityRunStart = child.StartTime.Format(time.RFC3339)
+		}
+		if child.EndTime != nil {
+			c.ActivityRunEnd = child.EndTime.Format(time.RFC3339)
+		}
+
+		// Generate Fabric deep link URL for child execution if we have the required info
+		if c.ChildJobInstanceID != "" && c.ChildWorkspaceID != "" {
+			c.FabricURL = utils.GenerateFabricURL(c.ChildWorkspaceID, c.ChildItemID, c.ChildItemType, c.ChildJobInstanceID, child.LivyID)
+		}
+
+		result = append(result, c)
+	}
+	return result
+}
+
+// CadenceFromDB flags a cadence's drift, gaps and overdue runs, or says why they could not be checked
+func CadenceFromDB(c db.RunCadence) Cadence {
+	cadence := Cadence{RunCadence: c, Flags: []string{}}
+	if !c.Regular {
+		cadence.Message = "Runs are too irregular to infer a cadence"
+		return cadence
+	}
+	if c.Gaps > 0 {
+		cadence.Flags = append(cadence.Flags, "gaps")
+	}
+	if c.ScheduleMs == 0 {
+		cadence.Message = "The cadence matches no common schedule, so drift is not checked"
+	} else if c.Drifting {
+		cadence.Flags = append(cadence.Flags, "drift")
+	}
+	if c.Overdue {
+		cadence.Flags = append(cadence.Flags, "overdue")
+	}
+	return cadence
+}
+
+// deref returns the pointed-to string, or "" for nil
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
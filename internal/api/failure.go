@@ -0,0 +1,80 @@
+package api
+
+import "encoding/json"
+
+// ParseFailureDetail parses a raw failure payload into a tree
+// Returns nil if raw is empty or not a JSON object
+func ParseFailureDetail(raw string) *FailureDetail {
+	var obj map[string]interface{}
+	if err := json.Unmarshal([]byte(raw), &obj); err != nil {
+		return nil
+	}
+	detail := failureDetailFromMap(obj)
+	return &detail
+}
+
+// failureDetailFromMap converts one level of a failure payload, recursing into nested causes
+func failureDetailFromMap(obj map[string]interface{}) FailureDetail {
+	var detail FailureDetail
+	for key, value := range obj {
+		switch key {
+		case "errorCode", "code":
+			detail.ErrorCode = stringValue(value)
+		case "message":
+			detail.Message = stringValue(value)
+		case "requestId":
+			detail.RequestID = stringValue(value)
+		case "target":
+			detail.Target = stringValue(value)
+		case "details", "moreDetails":
+			detail.Details = append(detail.Details, failureDetailsFromValue(value)...)
+		case "error", "innerError":
+			// Some services wrap the actual cause in a nested error object
+			if nested, ok := value.(map[string]interface{}); ok {
+				detail.Details = append(detail.Details, failureDetailFromMap(nested))
+			} else {
+				detail.setProperty(key, value)
+			}
+		default:
+			detail.setProperty(key, value)
+		}
+	}
+	return detail
+}
+
+// failureDetailsFromValue converts a details entry, which may be a list, a single object or plain text
+func failureDetailsFromValue(value interface{}) []FailureDetail {
+	switch v := value.(type) {
+	case []interface{}:
+		var details []FailureDetail
+		for _, entry := range v {
+			details = append(details, failureDetailsFromValue(entry)...)
+		}
+		return details
+	case map[string]interface{}:
+		return []FailureDetail{failureDetailFromMap(v)}
+	case string:
+		if v == "" {
+			return nil
+		}
+		return []FailureDetail{{Message: v}}
+	default:
+		return nil
+	}
+}
+
+func (d *FailureDetail) setProperty(key string, value interface{}) {
+	if d.Properties == nil {
+		d.Properties = make(map[string]interface{})
+	}
+	d.Properties[key] = value
+}
+
+// stringValue returns value if it is a string, or its JSON encoding otherwise
+func stringValue(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	encoded, _ := json.Marshal(value)
+	return string(encoded)
+}
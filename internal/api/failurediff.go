@@ -0,0 +1,152 @@
+package api
+
+import (
+	"regexp"
+	"sort"
+	"time"
+
+	"better-fabric-monitor/internal/db"
+)
+
+// Fields of a FailureChange
+const (
+	FailureFieldErrorCode       = "errorCode"
+	FailureFieldMessage         = "message"
+	FailureFieldTarget          = "target"
+	FailureFieldFailureType     = "failureType"
+	FailureFieldFailingActivity = "failingActivity" // An activity failed in only one of the runs
+)
+
+// volatileMessageParts match the parts of a failure message that change from run to run without changing the
+// failure: IDs, timestamps and numbers
+var volatileMessageParts = regexp.MustCompile(
+	`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}|` +
+		`\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:?\d{2})?|` +
+		`\d+`)
+
+// FailureSnapshotFromDB collects what a failed run reported: its own error and the activities whose last attempt
+// failed, in the order they started
+func FailureSnapshotFromDB(job *db.JobInstance) FailureSnapshot {
+	snapshot := FailureSnapshot{
+		JobID:      job.ID,
+		StartTime:  job.StartTime.Format(time.RFC3339),
+		Status:     job.Status,
+		Activities: []FailedActivity{},
+	}
+	if job.FailureReason != nil {
+		snapshot.Message = *job.FailureReason
+	}
+	if job.FailureDetails != nil {
+		if detail := ParseFailureDetail(*job.FailureDetails); detail != nil {
+			snapshot.ErrorCode, snapshot.Target = detail.ErrorCode, detail.Target
+			if snapshot.Message == "" {
+				snapshot.Message = detail.Message
+			}
+		}
+	}
+
+	// Keep the last attempt of each activity, or of each loop iteration of it
+	type attemptKey struct{ name, iteration string }
+	last := make(map[attemptKey]db.ActivityRun)
+	for _, activity := range job.ActivityRuns {
+		key := attemptKey{activity.ActivityName, activity.IterationHash}
+		if previous, ok := last[key]; !ok || laterAttempt(activity, previous) {
+			last[key] = activity
+		}
+	}
+	var failed []db.ActivityRun
+	for _, activity := range last {
+		if activity.Status == "Failed" {
+			failed = append(failed, activity)
+		}
+	}
+	sort.Slice(failed, func(i, j int) bool {
+		if failed[i].ActivityRunStart != failed[j].ActivityRunStart {
+			return failed[i].ActivityRunStart < failed[j].ActivityRunStart
+		}
+		return failed[i].ActivityName < failed[j].ActivityName
+	})
+
+	// A loop activity whose iterations failed is listed once, with its first failure
+	seen := make(map[string]bool)
+	for _, activity := range failed {
+		if seen[activity.ActivityName] {
+			continue
+		}
+		seen[activity.ActivityName] = true
+		snapshot.Activities = append(snapshot.Activities, FailedActivity{
+			ActivityName: activity.ActivityName,
+			ActivityType: activity.ActivityType,
+			ErrorCode:    activity.Error.ErrorCode,
+			FailureType:  activity.Error.FailureType,
+			Target:       activity.Error.Target,
+			Message:      activity.Error.Message,
+		})
+	}
+	return snapshot
+}
+
+// laterAttempt reports whether activity is a later attempt than previous
+func laterAttempt(activity, previous db.ActivityRun) bool {
+	attempt, previousAttempt := 0, 0
+	if activity.RetryAttempt != nil {
+		attempt = *activity.RetryAttempt
+	}
+	if previous.RetryAttempt != nil {
+		previousAttempt = *previous.RetryAttempt
+	}
+	if attempt != previousAttempt {
+		return attempt > previousAttempt
+	}
+	return activity.ActivityRunStart > previous.ActivityRunStart
+}
+
+// DiffFailures lists what changed from the failure of before to that of after: the run's error code, message and
+// target, activities that failed in only one run and the error fields of activities that failed in both
+// Messages are compared without IDs, timestamps and numbers, so the same failure at another time matches
+// same is true when nothing changed
+func DiffFailures(before, after FailureSnapshot) (changes []FailureChange, same bool) {
+	changes = []FailureChange{}
+	changes = appendFieldChanges(changes, "", before.ErrorCode, after.ErrorCode, before.Message, after.Message,
+		before.Target, after.Target, "", "")
+
+	afterActivities := make(map[string]FailedActivity, len(after.Activities))
+	for _, activity := range after.Activities {
+		afterActivities[activity.ActivityName] = activity
+	}
+	beforeActivities := make(map[string]bool, len(before.Activities))
+	for _, b := range before.Activities {
+		beforeActivities[b.ActivityName] = true
+		a, ok := afterActivities[b.ActivityName]
+		if !ok {
+			changes = append(changes, FailureChange{Field: FailureFieldFailingActivity, ActivityName: b.ActivityName, Before: b.ActivityName})
+			continue
+		}
+		changes = appendFieldChanges(changes, b.ActivityName, b.ErrorCode, a.ErrorCode, b.Message, a.Message,
+			b.Target, a.Target, b.FailureType, a.FailureType)
+	}
+	for _, a := range after.Activities {
+		if !beforeActivities[a.ActivityName] {
+			changes = append(changes, FailureChange{Field: FailureFieldFailingActivity, ActivityName: a.ActivityName, After: a.ActivityName})
+		}
+	}
+	return changes, len(changes) == 0
+}
+
+// appendFieldChanges appends the error fields of a run, or of its activity activityName, that differ
+func appendFieldChanges(changes []FailureChange, activityName, codeBefore, codeAfter, messageBefore, messageAfter,
+	targetBefore, targetAfter, typeBefore, typeAfter string) []FailureChange {
+	if codeBefore != codeAfter {
+		changes = append(changes, FailureChange{Field: FailureFieldErrorCode, ActivityName: activityName, Before: codeBefore, After: codeAfter})
+	}
+	if volatileMessageParts.ReplaceAllString(messageBefore, "#") != volatileMessageParts.ReplaceAllString(messageAfter, "#") {
+		changes = append(changes, FailureChange{Field: FailureFieldMessage, ActivityName: activityName, Before: messageBefore, After: messageAfter})
+	}
+	if targetBefore != targetAfter {
+		changes = append(changes, FailureChange{Field: FailureFieldTarget, ActivityName: activityName, Before: targetBefore, After: targetAfter})
+	}
+	if typeBefore != typeAfter {
+		changes = append(changes, FailureChange{Field: FailureFieldFailureType, ActivityName: activityName, Before: typeBefore, After: typeAfter})
+	}
+	return changes
+}
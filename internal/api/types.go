@@ -0,0 +1,685 @@
+package api
+
+import (
+	"better-fabric-monitor/internal/db"
+	"better-fabric-monitor/internal/fabric"
+	"better-fabric-monitor/internal/logger"
+)
+
+// Workspace is a workspace as returned to the frontend
+// Error entries (authentication/API failures) use the error fields and leave the rest empty
+type Workspace struct {
+	ID                  string `json:"id,omitempty"`
+	DisplayName         string `json:"displayName,omitempty"`
+	Type                string `json:"type,omitempty"`
+	Description         string `json:"description,omitempty"`
+	CapacityID          string `json:"capacityId,omitempty"`
+	Error               string `json:"error,omitempty"`
+	Message             string `json:"message,omitempty"`
+	CachedDataAvailable *bool  `json:"cached_data_available,omitempty"`
+	IsErrorMarker       bool   `json:"_is_error_marker,omitempty"` // Frontend filters these entries out of the data list
+}
+
+// Job is a job instance as returned to the frontend
+// Error entries (authentication/API failures) use the error fields and leave the rest empty
+type Job struct {
+	ID                  string `json:"id,omitempty"`
+	WorkspaceID         string `json:"workspaceId,omitempty"`
+	WorkspaceName       string `json:"workspaceName,omitempty"`
+	ItemID              string `json:"itemId,omitempty"`
+	ItemDisplayName     string `json:"itemDisplayName,omitempty"`
+	ItemType            string `json:"itemType,omitempty"`
+	JobType             string `json:"jobType,omitempty"`
+	InvokerType         string `json:"invokerType,omitempty"`
+	Status              string `json:"status,omitempty"`
+	StartTime           string `json:"startTime,omitempty"`
+	EndTime             string `json:"endTime,omitempty"`
+	DurationMs          *int64 `json:"durationMs,omitempty"`
+	FailureReason       string `json:"failureReason,omitempty"`
+	RootActivityID      string `json:"rootActivityId,omitempty"`
+	FabricURL           string `json:"fabricUrl,omitempty"`
+	RemovedUpstreamAt   string `json:"removedUpstreamAt,omitempty"`  // Set once the API stopped returning the run
+	ExpectedDurationMs  *int64 `json:"expectedDurationMs,omitempty"` // Forecast duration of a run in progress
+	ExpectedEndTime     string `json:"expectedEndTime,omitempty"`    // Forecast completion of a run in progress
+	ExpectedEndSource   string `json:"expectedEndSource,omitempty"`  // history, or activities when a pipeline's finished activities refined it
+	Error               string `json:"error,omitempty"`
+	Message             string `json:"message,omitempty"`
+	CachedDataAvailable *bool  `json:"cached_data_available,omitempty"`
+	IsErrorMarker       bool   `json:"_is_error_marker,omitempty"` // Frontend filters these entries out of the data list
+}
+
+// JobsPageResult is the response for GetJobsPaged
+type JobsPageResult struct {
+	Error     string   `json:"error,omitempty"`
+	Jobs      []Job    `json:"jobs"`
+	Total     int      `json:"total"` // Jobs matching the filter across all pages
+	Page      int      `json:"page"`  // 1-based
+	PageSize  int      `json:"pageSize"`
+	ItemTypes []string `json:"itemTypes"` // Item types of all cached jobs, to filter by
+	Statuses  []string `json:"statuses"`  // Statuses of all cached jobs, to filter by
+}
+
+// User describes the signed-in user
+type User struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// LoginResult is returned by the login bindings
+type LoginResult struct {
+	Success         bool        `json:"success"`
+	Error           string      `json:"error,omitempty"`
+	RequiresCode    bool        `json:"requiresCode,omitempty"`
+	UserCode        string      `json:"userCode,omitempty"`
+	VerificationURL string      `json:"verificationURL,omitempty"`
+	Message         string      `json:"message,omitempty"`
+	User            *User       `json:"user,omitempty"`
+	Token           interface{} `json:"token,omitempty"`
+}
+
+// RecentFailure is a failed job shown on the analytics dashboard
+type RecentFailure struct {
+	ID              string `json:"id"`
+	WorkspaceID     string `json:"workspaceId"`
+	WorkspaceName   string `json:"workspaceName"`
+	ItemID          string `json:"itemId"`
+	ItemDisplayName string `json:"itemDisplayName"`
+	ItemType        string `json:"itemType"`
+	JobType         string `json:"jobType"`
+	StartTime       string `json:"startTime"`
+	EndTime         string `json:"endTime"`
+	DurationMs      int64  `json:"durationMs"`
+	FailureReason   string `json:"failureReason"`
+	FabricURL       string `json:"fabricUrl,omitempty"`
+}
+
+// LongRunningJob is a completed job that ran well above its item's average duration
+type LongRunningJob struct {
+	ID              string  `json:"id"`
+	WorkspaceID     string  `json:"workspaceId"`
+	WorkspaceName   string  `json:"workspaceName"`
+	ItemID          string  `json:"itemId"`
+	ItemDisplayName string  `json:"itemDisplayName"`
+	ItemType        string  `json:"itemType"`
+	JobType         string  `json:"jobType"`
+	StartTime       string  `json:"startTime"`
+	DurationMs      int64   `json:"durationMs"`
+	AvgDurationMs   float64 `json:"avgDurationMs"`
+	DeviationPct    float64 `json:"deviationPct"`
+	FabricURL       string  `json:"fabricUrl,omitempty"`
+}
+
+// Analytics is the analytics dashboard payload
+// Each section reports its own error so one failing query doesn't blank the dashboard
+type Analytics struct {
+	Error                string              `json:"error,omitempty"`
+	Days                 int                 `json:"days,omitempty"`
+	DailyStats           []db.DailyStats     `json:"dailyStats,omitempty"`
+	DailyStatsError      string              `json:"dailyStatsError,omitempty"`
+	WorkspaceStats       []db.WorkspaceStats `json:"workspaceStats,omitempty"`
+	WorkspaceStatsError  string              `json:"workspaceStatsError,omitempty"`
+	ItemTypeStats        []db.ItemTypeStats  `json:"itemTypeStats,omitempty"`
+	ItemTypeStatsError   string              `json:"itemTypeStatsError,omitempty"`
+	RecentFailures       []RecentFailure     `json:"recentFailures,omitempty"`
+	RecentFailuresError  string              `json:"recentFailuresError,omitempty"`
+	LongRunningJobs      []LongRunningJob    `json:"longRunningJobs,omitempty"`
+	LongRunningJobsError string              `json:"longRunningJobsError,omitempty"`
+	FailureStreaks       []db.FailureStreak  `json:"failureStreaks,omitempty"`
+	FailureStreaksError  string              `json:"failureStreaksError,omitempty"`
+	TopErrorCodes        []db.ErrorCodeStats `json:"topErrorCodes,omitempty"`
+	TopErrorCodesError   string              `json:"topErrorCodesError,omitempty"`
+	OverallStats         *db.JobStats        `json:"overallStats,omitempty"`
+	OverallStatsError    string              `json:"overallStatsError,omitempty"`
+}
+
+// ItemStatsResult wraps item-level statistics for a drill-down
+type ItemStatsResult struct {
+	Error string         `json:"error,omitempty"`
+	Items []db.ItemStats `json:"items,omitempty"`
+	Days  int            `json:"days,omitempty"`
+}
+
+// DailyItemStatsResult wraps item-level statistics for a single date
+type DailyItemStatsResult struct {
+	Error string              `json:"error,omitempty"`
+	Items []db.DailyItemStats `json:"items,omitempty"`
+	Date  string              `json:"date,omitempty"`
+}
+
+// JobWithActivitiesResult wraps a job instance with its activity runs
+type JobWithActivitiesResult struct {
+	Error string          `json:"error,omitempty"`
+	Job   *db.JobInstance `json:"job,omitempty"`
+}
+
+// ChildExecution is a child pipeline or notebook run launched by a pipeline activity
+type ChildExecution struct {
+	ActivityRunID      string `json:"activityRunId"`
+	ActivityName       string `json:"activityName"`
+	ActivityType       string `json:"activityType"`
+	Status             string `json:"status"`
+	PipelineID         string `json:"pipelineId"`
+	HasChildren        bool   `json:"hasChildren"`
+	ActivityRunStart   string `json:"activityRunStart,omitempty"`
+	ActivityRunEnd     string `json:"activityRunEnd,omitempty"`
+	DurationMs         *int64 `json:"durationMs,omitempty"`
+	Error              string `json:"error,omitempty"`
+	ChildJobInstanceID string `json:"childJobInstanceId,omitempty"`
+	ChildPipelineName  string `json:"childPipelineName,omitempty"`
+	ChildNotebookName  string `json:"childNotebookName,omitempty"`
+	ChildWorkspaceID   string `json:"childWorkspaceId,omitempty"`
+	ChildItemID        string `json:"childItemId,omitempty"`
+	ChildItemType      string `json:"childItemType,omitempty"`
+	FabricURL          string `json:"fabricUrl,omitempty"`
+}
+
+// ChildExecutionsResult wraps the child executions of a pipeline run
+type ChildExecutionsResult struct {
+	Error    string           `json:"error,omitempty"`
+	Children []ChildExecution `json:"children"`
+	Count    int              `json:"count"`
+}
+
+// ItemSyncResult is returned when a single item is refreshed on demand
+type ItemSyncResult struct {
+	Error       string `json:"error,omitempty"`
+	JobsUpdated int    `json:"jobsUpdated"`
+	Jobs        []Job  `json:"jobs"` // All cached jobs of the item after the refresh
+}
+
+// FailureDetail is a node of a structured failure payload
+// Fabric nests causes under details/moreDetails; fields not modelled here are kept in Properties
+type FailureDetail struct {
+	ErrorCode  string                 `json:"errorCode,omitempty"`
+	Message    string                 `json:"message,omitempty"`
+	RequestID  string                 `json:"requestId,omitempty"`
+	Target     string                 `json:"target,omitempty"`
+	Details    []FailureDetail        `json:"details,omitempty"`
+	Properties map[string]interface{} `json:"properties,omitempty"`
+}
+
+// JobDetailResult is the full record of a job run, including its structured failure payload
+type JobDetailResult struct {
+	Error     string          `json:"error,omitempty"`
+	Job       *db.JobInstance `json:"job,omitempty"`
+	FabricURL string          `json:"fabricUrl,omitempty"`
+	Failure   *FailureDetail  `json:"failure,omitempty"`
+}
+
+// SyncMetricsResult wraps the recorded metrics of recent sync runs
+type SyncMetricsResult struct {
+	Error   string           `json:"error,omitempty"`
+	Metrics []db.SyncMetrics `json:"metrics"`
+}
+
+// PollScheduleResult wraps the adaptive polling decision of each workspace
+type PollScheduleResult struct {
+	Error     string                     `json:"error,omitempty"`
+	Schedules []db.WorkspacePollSchedule `json:"schedules"`
+}
+
+// SyncWarning describes part of a sync that failed while the rest of the sync went ahead
+type SyncWarning struct {
+	Workspace string `json:"workspace"`
+	Item      string `json:"item,omitempty"` // Set when a single item failed rather than the whole workspace
+	Error     string `json:"error"`
+	Retryable bool   `json:"retryable"` // The failure looks transient, so the next sync will likely pick the data up
+}
+
+// DiagnosticsResult is the response for CollectDiagnostics
+type DiagnosticsResult struct {
+	Error string `json:"error,omitempty"`
+	Path  string `json:"path,omitempty"` // Absolute path of the written zip file
+}
+
+// LogExportResult is the response for ExportLogs
+type LogExportResult struct {
+	Error     string `json:"error,omitempty"`
+	Path      string `json:"path,omitempty"`      // Absolute path of the written zip file
+	Files     int    `json:"files"`               // Log files included besides the logs in memory
+	Cancelled bool   `json:"cancelled,omitempty"` // The save dialog was closed without choosing a file
+}
+
+// ViewExportResult is the response for ExportView
+type ViewExportResult struct {
+	Error     string `json:"error,omitempty"`
+	Path      string `json:"path,omitempty"`      // Absolute path of the written file
+	Format    string `json:"format,omitempty"`    // csv or xlsx
+	Rows      int    `json:"rows"`                // Jobs written, the header row excluded
+	Cancelled bool   `json:"cancelled,omitempty"` // The save dialog was closed without choosing a file
+}
+
+// SettingsExportResult is the response for ExportSettings
+type SettingsExportResult struct {
+	Error     string `json:"error,omitempty"`
+	Path      string `json:"path,omitempty"`      // Absolute path of the written settings file
+	Cancelled bool   `json:"cancelled,omitempty"` // The save dialog was closed without choosing a file
+}
+
+// SettingsImportResult is the response for ImportSettings
+type SettingsImportResult struct {
+	Error     string            `json:"error,omitempty"`
+	Path      string            `json:"path,omitempty"`      // Absolute path of the imported settings file
+	Cancelled bool              `json:"cancelled,omitempty"` // The open dialog was closed without choosing a file
+	Applied   []string          `json:"applied"`             // Settings applied to the running app, e.g. polling.interval
+	Rejected  map[string]string `json:"rejected"`            // Settings saved but not applied, with the reason, e.g. requires a restart
+}
+
+// LogSearchResult is the response for SearchLogs
+type LogSearchResult struct {
+	logger.Page
+	Offset int    `json:"offset"`
+	Limit  int    `json:"limit"`
+	Error  string `json:"error,omitempty"`
+}
+
+// APIHealthResult is the response for GetAPIHealth
+type APIHealthResult struct {
+	fabric.HealthReport
+	Hours int `json:"hours"`
+}
+
+// NotificationHistoryResult is the response for GetNotificationHistory
+type NotificationHistoryResult struct {
+	Error         string                  `json:"error,omitempty"`
+	Notifications []db.NotificationRecord `json:"notifications"`
+}
+
+// NotificationMutesResult is the response for GetNotificationMutes
+type NotificationMutesResult struct {
+	Error string                `json:"error,omitempty"`
+	Mutes []db.NotificationMute `json:"mutes"`
+}
+
+// FavoritesResult is the response for GetFavorites
+type FavoritesResult struct {
+	Error     string        `json:"error,omitempty"`
+	Favorites []db.Favorite `json:"favorites"`
+}
+
+// WorkspaceSettingsResult is the response for GetWorkspaceSettings
+type WorkspaceSettingsResult struct {
+	Error    string                 `json:"error,omitempty"`
+	Settings []db.WorkspaceSettings `json:"settings"`
+	Rules    []string               `json:"rules"` // Notification rules a workspace's events can be routed to
+}
+
+// AnomaliesResult is the response for GetAnomalies
+type AnomaliesResult struct {
+	Error     string          `json:"error,omitempty"`
+	Anomalies []db.RunAnomaly `json:"anomalies"`
+}
+
+// RecoveryStatsResult is the response for GetRecoveryStats
+type RecoveryStatsResult struct {
+	Error      string             `json:"error,omitempty"`
+	Items      []db.RecoveryStats `json:"items"`
+	Workspaces []db.RecoveryStats `json:"workspaces"`
+}
+
+// Cadence is the inferred run cadence of an item with what stands out about it
+type Cadence struct {
+	db.RunCadence
+	Flags   []string `json:"flags"`             // drift, gaps and overdue when they apply
+	Message string   `json:"message,omitempty"` // Why the cadence could not be checked
+}
+
+// CadenceReport is the inferred run cadence of an item and how its scheduled runs strayed from it
+type CadenceReport struct {
+	ItemID          string `json:"itemId"`
+	ItemDisplayName string `json:"itemDisplayName"`
+	ItemType        string `json:"itemType"`
+	WorkspaceID     string `json:"workspaceId"`
+	WorkspaceName   string `json:"workspaceName"`
+	Cadence
+}
+
+// CadenceReportResult is the response for GetCadenceReport
+type CadenceReportResult struct {
+	Error string          `json:"error,omitempty"`
+	Items []CadenceReport `json:"items"`
+}
+
+// ItemRunHistoryResult is the response for GetItemRunHistory
+type ItemRunHistoryResult struct {
+	Error string `json:"error,omitempty"`
+	Days  int    `json:"days"`
+	db.ItemRunHistory
+	Cadence *Cadence    `json:"cadence,omitempty"` // Inferred from the scheduled runs; nil with too few of them
+	Gaps    []db.RunGap `json:"gaps"`              // Intervals between scheduled runs in which runs were skipped
+}
+
+// DependencyGraphResult is the response for GetDependencyGraph
+type DependencyGraphResult struct {
+	Error      string              `json:"error,omitempty"`
+	Nodes      []db.DependencyNode `json:"nodes"`
+	Edges      []db.DependencyEdge `json:"edges"`
+	Upstream   []string            `json:"upstream,omitempty"`   // Items the focused item depends on, nearest first
+	Downstream []string            `json:"downstream,omitempty"` // Items depending on the focused item, nearest first
+}
+
+// TopErrorCodesResult is the response for GetTopErrorCodes
+type TopErrorCodesResult struct {
+	Error      string              `json:"error,omitempty"`
+	ErrorCodes []db.ErrorCodeStats `json:"errorCodes"`
+}
+
+// DurationForecast is the forecast duration of an item's next run
+// For a run in progress it also carries the run's forecast completion time
+type DurationForecast struct {
+	db.DurationForecast
+	RunningJobID    string `json:"runningJobId,omitempty"`
+	ExpectedEndTime string `json:"expectedEndTime,omitempty"`
+}
+
+// DurationForecastsResult is the response for GetDurationForecasts
+type DurationForecastsResult struct {
+	Error     string             `json:"error,omitempty"`
+	Forecasts []DurationForecast `json:"forecasts"`
+}
+
+// RunHeatmapResult is the response for GetRunHeatmap
+type RunHeatmapResult struct {
+	Error    string           `json:"error,omitempty"`
+	Days     int              `json:"days"`
+	Timezone string           `json:"timezone"` // Zone the weekdays and hours are in
+	Cells    []db.HeatmapCell `json:"cells"`
+}
+
+// RunCalendarResult is the response for GetRunCalendar
+type RunCalendarResult struct {
+	Error          string           `json:"error,omitempty"`
+	Month          string           `json:"month"`          // YYYY-MM
+	Timezone       string           `json:"timezone"`       // Zone the days are in
+	SLAThresholdMs int64            `json:"slaThresholdMs"` // Runs longer than this count as SLA breaches
+	Days           []db.CalendarDay `json:"days"`           // Every day of the month, the 1st first
+}
+
+// RootCauseStep is a failing run or activity on the path from a failed run to the cause of its failure
+type RootCauseStep struct {
+	JobID           string `json:"jobId"`
+	ItemID          string `json:"itemId"`
+	ItemDisplayName string `json:"itemDisplayName"`
+	ItemType        string `json:"itemType"`
+	WorkspaceID     string `json:"workspaceId"`
+	ActivityName    string `json:"activityName,omitempty"` // Set when the step is an activity of the run
+	ActivityType    string `json:"activityType,omitempty"`
+	Status          string `json:"status"`
+	ErrorCode       string `json:"errorCode,omitempty"`
+	Message         string `json:"message,omitempty"`
+	FabricURL       string `json:"fabricUrl,omitempty"`
+}
+
+// RootCauseResult is the response for GetRootCause
+type RootCauseResult struct {
+	Error string          `json:"error,omitempty"`
+	Chain []RootCauseStep `json:"chain"` // From the failed run down to the deepest failure, its root cause
+}
+
+// ActivityStatsResult is the response for GetActivityStats
+type ActivityStatsResult struct {
+	Error      string             `json:"error,omitempty"`
+	Activities []db.ActivityStats `json:"activities"`
+}
+
+// CopyThroughputResult is the response for GetCopyThroughput
+type CopyThroughputResult struct {
+	Error      string                   `json:"error,omitempty"`
+	Activities []db.CopyThroughputStats `json:"activities"`
+}
+
+// RetrySummary totals the activity retries of all pipelines
+type RetrySummary struct {
+	Executions              int     `json:"executions"`
+	Retried                 int     `json:"retried"`
+	SucceededAfterRetry     int     `json:"succeededAfterRetry"`
+	SucceededAfterRetryRate float64 `json:"succeededAfterRetryRate"` // Percentage of executions that succeeded only after a retry
+	FailedAfterRetry        int     `json:"failedAfterRetry"`
+	Retries                 int     `json:"retries"`
+	RetryTimeMs             int64   `json:"retryTimeMs"`
+}
+
+// RetryAnalyticsResult is the response for GetRetryAnalytics
+type RetryAnalyticsResult struct {
+	Error      string                  `json:"error,omitempty"`
+	Summary    RetrySummary            `json:"summary"`
+	Pipelines  []db.PipelineRetryStats `json:"pipelines"`  // Pipelines with retries, most time lost first
+	Activities []db.ActivityRetryStats `json:"activities"` // Activities with retries, most retries first
+}
+
+// RunningJobAge is a queued or in-progress job with how long it has run against its item's usual duration
+type RunningJobAge struct {
+	Job
+	ElapsedMs         int64    `json:"elapsedMs"`
+	BaselineMs        *float64 `json:"baselineMs,omitempty"`        // Average duration of the item's completed runs over the last 30 days
+	P90DurationMs     *float64 `json:"p90DurationMs,omitempty"`     // Set with BaselineMs
+	BaselineRuns      int      `json:"baselineRuns"`                // Completed runs behind the baseline, below 3 there is none
+	PercentOfExpected *float64 `json:"percentOfExpected,omitempty"` // Elapsed time as a percentage of the baseline
+	LongRunning       bool     `json:"longRunning"`                 // Past the long-running notification threshold or factor
+	Stuck             bool     `json:"stuck"`                       // Past the stuck factor
+}
+
+// RunningJobAgesResult is the response for GetRunningJobAges
+type RunningJobAgesResult struct {
+	Error string          `json:"error,omitempty"`
+	Jobs  []RunningJobAge `json:"jobs"` // Furthest past their baseline first, then jobs without one, longest running first
+}
+
+// RunningJobsResult is the response for GetRunningJobs and the payload of the jobs:running event
+type RunningJobsResult struct {
+	Error string `json:"error,omitempty"`
+	Jobs  []Job  `json:"jobs"` // Longest running first, with their expected completion when it can be forecast
+}
+
+// HighConcurrencyResult is the response for GetHighConcurrencyStats
+type HighConcurrencyResult struct {
+	Error      string                    `json:"error,omitempty"`
+	Days       int                       `json:"days"`
+	Summary    db.HighConcurrencyStats   `json:"summary"`    // All consumer identities
+	Identities []db.HighConcurrencyStats `json:"identities"` // Most shared sessions first
+}
+
+// InvokerStatsResult is the response for GetInvokerStats
+type InvokerStatsResult struct {
+	Error    string            `json:"error,omitempty"`
+	Days     int               `json:"days"`
+	Invokers []db.InvokerStats `json:"invokers"` // Over all items, most runs first
+	Items    []db.InvokerStats `json:"items"`    // Per item and invoker
+}
+
+// EnvironmentStats is how one copy of a logical item ran in its environment
+type EnvironmentStats struct {
+	Environment     string  `json:"environment"`
+	ItemID          string  `json:"itemId"`
+	ItemDisplayName string  `json:"itemDisplayName"`
+	WorkspaceID     string  `json:"workspaceId"`
+	WorkspaceName   string  `json:"workspaceName"`
+	Mapped          bool    `json:"mapped"` // Mapped by hand rather than matched by workspace name
+	Runs            int     `json:"runs"`
+	Succeeded       int     `json:"succeeded"`
+	Failed          int     `json:"failed"`
+	SuccessRate     float64 `json:"successRate"`   // Percentage of finished runs that completed
+	AvgDurationMs   float64 `json:"avgDurationMs"` // Of completed runs
+	P90DurationMs   float64 `json:"p90DurationMs"`
+}
+
+// EnvironmentComparison compares the copies of one logical item across environments
+type EnvironmentComparison struct {
+	LogicalName           string             `json:"logicalName"`
+	ItemType              string             `json:"itemType"`
+	Environments          []EnvironmentStats `json:"environments"`          // From dev to prod
+	SuccessRateRegression bool               `json:"successRateRegression"` // Prod succeeds clearly less often than another environment
+	DurationRegression    bool               `json:"durationRegression"`    // Prod runs clearly slower than another environment
+}
+
+// EnvironmentComparisonResult is the response for CompareEnvironments
+type EnvironmentComparisonResult struct {
+	Error       string                  `json:"error,omitempty"`
+	Days        int                     `json:"days"`
+	Comparisons []EnvironmentComparison `json:"comparisons"` // Regressions first, then by name
+}
+
+// EnvironmentMappingsResult is the response for GetEnvironmentMappings
+type EnvironmentMappingsResult struct {
+	Error    string                  `json:"error,omitempty"`
+	Mappings []db.EnvironmentMapping `json:"mappings"`
+}
+
+// DurationPercentilesResult is the response for GetDurationPercentiles
+type DurationPercentilesResult struct {
+	Error string `json:"error,omitempty"`
+	db.DurationPercentileSeries
+}
+
+// FailureCorrelationGroup is items whose runs tend to fail in the same time windows, as when they share
+// an upstream source or capacity
+type FailureCorrelationGroup struct {
+	Items         []db.CorrelatedItem `json:"items"`
+	Pairs         []db.CorrelatedPair `json:"pairs"`         // Correlated items by ID, most similar first
+	SharedWindows []string            `json:"sharedWindows"` // Starts of the windows two or more of the items failed in, latest first
+}
+
+// FailureCorrelationResult is the response for GetFailureCorrelations
+type FailureCorrelationResult struct {
+	Error         string                    `json:"error,omitempty"`
+	Days          int                       `json:"days"`
+	WindowMinutes int                       `json:"windowMinutes"`
+	Groups        []FailureCorrelationGroup `json:"groups"` // Largest first
+}
+
+// CapacityLoad is the load on one capacity and the cached workspaces assigned to it
+type CapacityLoad struct {
+	db.CapacityLoadStats
+	WorkspaceNames []string `json:"workspaceNames"`
+}
+
+// CapacityLoadResult is the response for GetCapacityLoad
+type CapacityLoadResult struct {
+	Error      string         `json:"error,omitempty"`
+	Days       int            `json:"days"`
+	Capacities []CapacityLoad `json:"capacities"` // Most run time first
+}
+
+// Where the freshness threshold of an item comes from
+const (
+	FreshnessConfigured = "configured" // Set by hand
+	FreshnessCadence    = "cadence"    // Inferred from the item's run cadence
+)
+
+// ItemFreshness is how old an item's data is, going by its last successful run, and whether that is too old
+type ItemFreshness struct {
+	db.ItemFreshness
+	AgeMs            *int64 `json:"ageMs,omitempty"`            // Since the last successful run ended; nil when no run succeeded
+	ThresholdMinutes int    `json:"thresholdMinutes,omitempty"` // Age past which the data is stale, 0 when unknown
+	ThresholdSource  string `json:"thresholdSource,omitempty"`  // FreshnessConfigured or FreshnessCadence
+	Stale            bool   `json:"stale"`
+}
+
+// DataFreshnessResult is the response for GetDataFreshness
+type DataFreshnessResult struct {
+	Error string          `json:"error,omitempty"`
+	Stale int             `json:"stale"`
+	Items []ItemFreshness `json:"items"` // Stale items first, then items with a threshold, each stalest first
+}
+
+// DurationRegressionsResult is the response for GetDurationRegressions
+type DurationRegressionsResult struct {
+	Error        string                  `json:"error,omitempty"`
+	BaselineRuns int                     `json:"baselineRuns"` // Successful runs each baseline is the median of
+	Regressions  []db.DurationRegression `json:"regressions"`  // Largest regression first
+}
+
+// InactiveItem is an item that used to run on a regular cadence but has not run for a while
+type InactiveItem struct {
+	ItemID          string `json:"itemId"`
+	ItemDisplayName string `json:"itemDisplayName"`
+	ItemType        string `json:"itemType"`
+	WorkspaceID     string `json:"workspaceId"`
+	WorkspaceName   string `json:"workspaceName"`
+	Runs            int    `json:"runs"`      // Runs in the lookback window, manual runs left out
+	CadenceMs       int64  `json:"cadenceMs"` // Median interval between those runs
+	ScheduleMs      int64  `json:"scheduleMs,omitempty"`
+	LastRunAt       string `json:"lastRunAt"`
+	InactiveForMs   int64  `json:"inactiveForMs"`
+	MissedRuns      int    `json:"missedRuns"` // Runs the cadence called for since the last one
+}
+
+// InactiveItemsResult is the response for GetInactiveItems
+type InactiveItemsResult struct {
+	Error        string         `json:"error,omitempty"`
+	InactiveDays int            `json:"inactiveDays"`
+	LookbackDays int            `json:"lookbackDays"`
+	Items        []InactiveItem `json:"items"` // Most missed runs first
+}
+
+// TopResult is the response for GetTop
+type TopResult struct {
+	Error   string        `json:"error,omitempty"`
+	Days    int           `json:"days"`
+	Scope   string        `json:"scope"`  // items or workspaces
+	Metric  string        `json:"metric"` // runs, runtime or failures
+	Entries []db.TopEntry `json:"entries"`
+}
+
+// FailedActivity is an activity whose last attempt failed in a run
+type FailedActivity struct {
+	ActivityName string `json:"activityName"`
+	ActivityType string `json:"activityType"`
+	ErrorCode    string `json:"errorCode,omitempty"`
+	FailureType  string `json:"failureType,omitempty"`
+	Target       string `json:"target,omitempty"`
+	Message      string `json:"message,omitempty"`
+}
+
+// FailureSnapshot is what a failed run reported about its failure
+type FailureSnapshot struct {
+	JobID      string           `json:"jobId"`
+	StartTime  string           `json:"startTime"`
+	Status     string           `json:"status"`
+	ErrorCode  string           `json:"errorCode,omitempty"`
+	Message    string           `json:"message,omitempty"`
+	Target     string           `json:"target,omitempty"`
+	Activities []FailedActivity `json:"activities"` // In the order they started
+}
+
+// FailureChange is an error field that differs between two failures
+type FailureChange struct {
+	Field        string `json:"field"`                  // One of the FailureField names
+	ActivityName string `json:"activityName,omitempty"` // Empty for the run's own error
+	Before       string `json:"before"`
+	After        string `json:"after"`
+}
+
+// FailureDiffResult is the response for DiffFailures
+type FailureDiffResult struct {
+	Error           string          `json:"error,omitempty"`
+	ItemID          string          `json:"itemId"`
+	ItemDisplayName string          `json:"itemDisplayName"`
+	Before          FailureSnapshot `json:"before"` // The earlier run
+	After           FailureSnapshot `json:"after"`
+	SameFailure     bool            `json:"sameFailure"` // No change beyond IDs, timestamps and numbers in messages
+	Changes         []FailureChange `json:"changes"`
+}
+
+// FeatureFlag is an experimental subsystem and whether the features section enables it
+type FeatureFlag struct {
+	Name    string `json:"name"` // Key under features, e.g. capacity_metrics
+	Enabled bool   `json:"enabled"`
+}
+
+// DoctorFinding is a problem Doctor found in the configuration
+type DoctorFinding struct {
+	Severity string `json:"severity"`          // error when the feature will not work, warning when it may not, info otherwise
+	Check    string `json:"check"`             // Area checked: paths, auth, secrets, replica, notifications, webhook, fabric, tls or app
+	Setting  string `json:"setting,omitempty"` // Config key the finding is about, e.g. database.path
+	Message  string `json:"message"`
+	Fix      string `json:"fix,omitempty"` // What to change to resolve it
+}
+
+// DoctorResult is the response for Doctor
+type DoctorResult struct {
+	Error    string          `json:"error,omitempty"`
+	OK       bool            `json:"ok"` // No finding is an error
+	Findings []DoctorFinding `json:"findings"`
+}
@@ -7,10 +7,17 @@ import (
 	"time"
 
 	"better-fabric-monitor/internal/logger"
+	"better-fabric-monitor/internal/tlsconfig"
 
 	"github.com/AzureAD/microsoft-authentication-library-for-go/apps/public"
 )
 
+// httpTimeout bounds each request to Entra ID
+const httpTimeout = 30 * time.Second
+
+// FabricScopes are the scopes requested for Fabric REST API tokens
+var FabricScopes = []string{"https://analysis.windows.net/powerbi/api/.default"}
+
 // AuthManager handles Microsoft Entra ID authentication
 type AuthManager struct {
 	client            public.Client
@@ -53,7 +60,8 @@ func NewAuthManager(config *AuthConfig) (*AuthManager, error) {
 	}
 
 	// Create MSAL client with persistent cache
-	client, err := public.New(config.ClientID, public.WithCache(cache))
+	httpClient := tlsconfig.Client(httpTimeout)
+	client, err := public.New(config.ClientID, public.WithCache(cache), public.WithHTTPClient(httpClient))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create MSAL client: %w", err)
 	}
@@ -62,7 +70,7 @@ func NewAuthManager(config *AuthConfig) (*AuthManager, error) {
 		client:     client,
 		config:     config,
 		tokenCache: cache,
-		httpClient: &http.Client{Timeout: 30 * time.Second},
+		httpClient: httpClient,
 	}, nil
 }
 
@@ -80,7 +88,7 @@ func (a *AuthManager) StartDeviceCodeFlow(ctx context.Context) (*DeviceCodeInfo,
 	// Open browser to the verification URL
 	if err := openBrowser(deviceCode.Result.VerificationURL); err != nil {
 		// Don't fail if browser can't open, user can navigate manually
-		logger.Log("Warning: failed to open browser: %v\n", err)
+		logger.Warn("Failed to open browser", logger.Err(err))
 	}
 
 	// Return the device code information to display in the UI
@@ -58,7 +58,7 @@ func (tc *TokenCache) Export(ctx context.Context, m cache.Marshaler, hints cache
 
 	// Persist to disk
 	if err := os.WriteFile(tc.filePath, data, 0600); err != nil {
-		logger.Log("Warning: failed to persist cache: %v\n", err)
+		logger.Warn("Failed to persist token cache", logger.Err(err))
 	}
 
 	return nil
@@ -0,0 +1,40 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+
+	"better-fabric-monitor/internal/tlsconfig"
+
+	"github.com/AzureAD/microsoft-authentication-library-for-go/apps/confidential"
+)
+
+// ServicePrincipalToken acquires a token for an app registration using its client secret
+// Unlike the device code flow there is no user, so the tenant must be given
+func ServicePrincipalToken(ctx context.Context, tenantID, clientID, clientSecret string, scopes []string) (*Token, error) {
+	if tenantID == "" {
+		return nil, fmt.Errorf("tenant ID is required for service principal authentication")
+	}
+
+	cred, err := confidential.NewCredFromSecret(clientSecret)
+	if err != nil {
+		return nil, fmt.Errorf("invalid client secret: %w", err)
+	}
+
+	client, err := confidential.New("https://login.microsoftonline.com/"+tenantID, clientID, cred,
+		confidential.WithHTTPClient(tlsconfig.Client(httpTimeout)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create confidential client: %w", err)
+	}
+
+	result, err := client.AcquireTokenByCredential(ctx, scopes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire service principal token: %w", err)
+	}
+
+	return &Token{
+		AccessToken: result.AccessToken,
+		TokenType:   "Bearer",
+		ExpiresAt:   result.ExpiresOn,
+	}, nil
+}
@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/spf13/viper"
@@ -18,90 +20,312 @@ type Config struct {
 	UI            UIConfig           `json:"ui" mapstructure:"ui"`
 	Notifications NotificationConfig `json:"notifications" mapstructure:"notifications"`
 	Polling       PollingConfig      `json:"polling" mapstructure:"polling"`
+	Webhook       WebhookConfig      `json:"webhook" mapstructure:"webhook"`
 	App           AppConfig          `json:"app" mapstructure:"app"`
+	Tracing       TracingConfig      `json:"tracing" mapstructure:"tracing"`
+	TLS           TLSConfig          `json:"tls" mapstructure:"tls"`
+	Features      map[string]bool    `json:"features" mapstructure:"features"` // Experimental subsystems, off unless enabled
 }
 
 // AuthConfig holds authentication-related configuration
 type AuthConfig struct {
-	ClientID    string `json:"clientId" mapstructure:"client_id"`
-	TenantID    string `json:"tenantId" mapstructure:"tenant_id"`
-	RedirectURI string `json:"redirectUri" mapstructure:"redirect_uri"`
+	ClientID     string `json:"clientId" mapstructure:"client_id"`
+	TenantID     string `json:"tenantId" mapstructure:"tenant_id"`
+	RedirectURI  string `json:"redirectUri" mapstructure:"redirect_uri"`
+	ClientSecret string `json:"clientSecret" mapstructure:"client_secret"` // Service principal secret used by the sync command
 }
 
 // FabricConfig holds Fabric API-related configuration
 type FabricConfig struct {
-	WorkspaceIDs []string `json:"workspaceIds" mapstructure:"workspace_ids"`
-	BaseURL      string   `json:"baseUrl" mapstructure:"base_url"`
+	WorkspaceIDs      []string          `json:"workspaceIds" mapstructure:"workspace_ids"`
+	IncludeWorkspaces []string          `json:"includeWorkspaces" mapstructure:"include_workspaces"`  // Glob patterns on workspace names
+	ExcludeWorkspaces []string          `json:"excludeWorkspaces" mapstructure:"exclude_workspaces"`  // Glob patterns on workspace names
+	IncludePersonal   bool              `json:"includePersonal" mapstructure:"include_personal"`      // Sync and analyze personal "My workspace" workspaces
+	ExcludedItemTypes []string          `json:"excludedItemTypes" mapstructure:"excluded_item_types"` // Item types not synced (e.g. Dataflow)
+	ItemCacheTTL      time.Duration     `json:"itemCacheTtl" mapstructure:"item_cache_ttl"`           // How long listed items are reused before a workspace is re-listed (0 disables)
+	StaleJobAfter     time.Duration     `json:"staleJobAfter" mapstructure:"stale_job_after"`         // In-progress jobs older than this are marked Stale (0 disables)
+	MaxLookbackDays   int               `json:"maxLookbackDays" mapstructure:"max_lookback_days"`     // Full syncs and backfills skip jobs started longer ago (0 keeps all history)
+	BaseURL           string            `json:"baseUrl" mapstructure:"base_url"`
+	Limits            LimitsConfig      `json:"limits" mapstructure:"limits"`
+	PortalHost        string            `json:"portalHost" mapstructure:"portal_host"`       // Fabric portal host of deep links, e.g. app.fabric.microsoft.com
+	LinkTemplates     map[string]string `json:"linkTemplates" mapstructure:"link_templates"` // Deep link URL template per item type, overriding the built-in ones
+}
+
+// LimitsConfig holds the API client's concurrency, request rate and retries; values out of bounds are clamped
+type LimitsConfig struct {
+	WorkspaceConcurrency int `json:"workspaceConcurrency" mapstructure:"workspace_concurrency"` // Workspaces fetched in parallel (1-32)
+	ItemConcurrency      int `json:"itemConcurrency" mapstructure:"item_concurrency"`           // Items fetched in parallel per workspace (1-20)
+	InitialRPS           int `json:"initialRps" mapstructure:"initial_rps"`                     // Requests per second to start at (min_rps-max_rps)
+	MinRPS               int `json:"minRps" mapstructure:"min_rps"`                             // Floor when throttled (1-max_rps)
+	MaxRPS               int `json:"maxRps" mapstructure:"max_rps"`                             // Ceiling when not throttled (1-200)
+	MaxRetries           int `json:"maxRetries" mapstructure:"max_retries"`                     // Retries of a failed request (0-10)
 }
 
 // DatabaseConfig holds database-related configuration
 type DatabaseConfig struct {
-	Path                  string `json:"path" mapstructure:"path"`
-	EncryptionKey         string `json:"encryptionKey" mapstructure:"encryption_key"`
-	RetentionDays         int    `json:"retentionDays" mapstructure:"retention_days"`
-	EnableReadOnlyReplica bool   `json:"enableReadOnlyReplica" mapstructure:"enable_readonly_replica"`
-	ParquetPath           string `json:"parquetPath" mapstructure:"parquet_path"`
-	ReadOnlyPath          string `json:"readOnlyPath" mapstructure:"readonly_path"`
+	Path                  string        `json:"path" mapstructure:"path"`
+	EncryptionKey         string        `json:"encryptionKey" mapstructure:"encryption_key"`
+	RetentionDays         int           `json:"retentionDays" mapstructure:"retention_days"`
+	EnableReadOnlyReplica bool          `json:"enableReadOnlyReplica" mapstructure:"enable_readonly_replica"`
+	ParquetPath           string        `json:"parquetPath" mapstructure:"parquet_path"`
+	ReadOnlyPath          string        `json:"readOnlyPath" mapstructure:"readonly_path"`
+	MemoryLimitMB         int           `json:"memoryLimitMb" mapstructure:"memory_limit_mb"`                 // DuckDB memory cap (0 uses DuckDB's default of 80% of RAM)
+	Threads               int           `json:"threads" mapstructure:"threads"`                               // Threads DuckDB runs queries on (0 uses one per CPU core)
+	TempDir               string        `json:"tempDir" mapstructure:"temp_dir"`                              // Where DuckDB spills queries larger than the memory cap (empty is next to the database)
+	ParquetExportInterval time.Duration `json:"parquetExportInterval" mapstructure:"parquet_export_interval"` // Minimum time between Parquet exports (0 exports after every sync that wrote data)
+	ReadOnlyIfInUse       bool          `json:"readOnlyIfInUse" mapstructure:"readonly_if_in_use"`            // Open the replica read-only instead of asking when another instance holds the database
 }
 
 // UIConfig holds UI-related configuration
 type UIConfig struct {
-	Theme           string        `json:"theme" mapstructure:"theme"`
-	PrimaryColor    string        `json:"primaryColor" mapstructure:"primary_color"`
-	DefaultView     string        `json:"defaultView" mapstructure:"default_view"`
-	RefreshInterval time.Duration `json:"refreshInterval" mapstructure:"refresh_interval"`
+	Theme               string        `json:"theme" mapstructure:"theme"`
+	PrimaryColor        string        `json:"primaryColor" mapstructure:"primary_color"`
+	DefaultView         string        `json:"defaultView" mapstructure:"default_view"` // View shown after sign-in: jobs, analytics or logs
+	RefreshInterval     time.Duration `json:"refreshInterval" mapstructure:"refresh_interval"`
+	AnalyticsDays       int           `json:"analyticsDays" mapstructure:"analytics_days"`              // Days analytics cover unless another window is chosen
+	DefaultWorkspaceIDs []string      `json:"defaultWorkspaceIds" mapstructure:"default_workspace_ids"` // Workspaces analytics are filtered to unless others are chosen (empty is all)
 }
 
 // NotificationConfig holds notification-related configuration
 type NotificationConfig struct {
-	Enabled              bool          `json:"enabled" mapstructure:"enabled"`
-	OnFailure            bool          `json:"onFailure" mapstructure:"on_failure"`
-	OnLongRunning        bool          `json:"onLongRunning" mapstructure:"on_long_running"`
-	SoundEnabled         bool          `json:"soundEnabled" mapstructure:"sound_enabled"`
-	LongRunningThreshold time.Duration `json:"longRunningThreshold" mapstructure:"long_running_threshold"`
+	Enabled              bool                 `json:"enabled" mapstructure:"enabled"`
+	OnFailure            bool                 `json:"onFailure" mapstructure:"on_failure"`
+	OnLongRunning        bool                 `json:"onLongRunning" mapstructure:"on_long_running"`
+	SoundEnabled         bool                 `json:"soundEnabled" mapstructure:"sound_enabled"`
+	LongRunningThreshold time.Duration        `json:"longRunningThreshold" mapstructure:"long_running_threshold"`
+	LongRunningFactor    float64              `json:"longRunningFactor" mapstructure:"long_running_factor"` // Also alert at this multiple of the item's average duration (0 disables)
+	OnStuck              bool                 `json:"onStuck" mapstructure:"on_stuck"`
+	StuckFactor          float64              `json:"stuckFactor" mapstructure:"stuck_factor"` // Multiple of the item's average duration after which a queued or running job is stuck
+	Webhook              WebhookChannelConfig `json:"webhook" mapstructure:"webhook"`          // Outbound webhook channel
+	Escalation           EscalationConfig     `json:"escalation" mapstructure:"escalation"`    // Second channel for items that keep failing
+	Digest               DigestConfig         `json:"digest" mapstructure:"digest"`            // Scheduled summary report
+	Sounds               SoundConfig          `json:"sounds" mapstructure:"sounds"`            // Sounds played for critical alerts while SoundEnabled
+}
+
+// SoundConfig holds the sound played in the app for each critical alert: chime, beep, alarm or off
+// An empty per-alert sound falls back to Default
+type SoundConfig struct {
+	Default     string `json:"default" mapstructure:"default"`
+	Failure     string `json:"failure" mapstructure:"failure"`
+	LongRunning string `json:"longRunning" mapstructure:"long_running"`
+	Stuck       string `json:"stuck" mapstructure:"stuck"`
+}
+
+// DigestConfig holds the schedule of the digest report sent through the notification channels
+type DigestConfig struct {
+	Frequency string `json:"frequency" mapstructure:"frequency"` // daily, weekly or off
+	At        string `json:"at" mapstructure:"at"`               // Local time the digest is sent, e.g. 08:00
+	Weekday   string `json:"weekday" mapstructure:"weekday"`     // Day weekly digests are sent, e.g. monday
+	Format    string `json:"format" mapstructure:"format"`       // markdown or html
+}
+
+// EscalationConfig holds the channel that job failures escalate to once an item fails repeatedly
+type EscalationConfig struct {
+	AfterFailures int                  `json:"afterFailures" mapstructure:"after_failures"` // Consecutive failures of the same item before escalating
+	Webhook       WebhookChannelConfig `json:"webhook" mapstructure:"webhook"`
+}
+
+// WebhookChannelConfig holds the notification channel that POSTs events as JSON to a URL
+type WebhookChannelConfig struct {
+	Enabled    bool     `json:"enabled" mapstructure:"enabled"`
+	URL        string   `json:"url" mapstructure:"url"`
+	Secret     string   `json:"secret" mapstructure:"secret"`           // Sent in the X-Webhook-Secret header when set
+	Events     []string `json:"events" mapstructure:"events"`           // Event types to send, e.g. job.failed (empty sends all)
+	QuietHours string   `json:"quietHours" mapstructure:"quiet_hours"`  // Local time window with no deliveries, e.g. 22:00-07:00
+	MaxPerHour int      `json:"maxPerHour" mapstructure:"max_per_hour"` // Deliveries per rolling hour before the rest are summarized (0 is unlimited)
 }
 
 // PollingConfig holds polling-related configuration
 type PollingConfig struct {
-	Interval time.Duration `json:"interval" mapstructure:"interval"`
-	Enabled  bool          `json:"enabled" mapstructure:"enabled"`
+	Interval    time.Duration `json:"interval" mapstructure:"interval"`
+	Enabled     bool          `json:"enabled" mapstructure:"enabled"`
+	Adaptive    bool          `json:"adaptive" mapstructure:"adaptive"`        // Poll busy workspaces every Interval and back off quiet ones
+	MaxInterval time.Duration `json:"maxInterval" mapstructure:"max_interval"` // Longest gap between polls of a dormant workspace
+	// StatusInterval is how often the status of running jobs is re-read between syncs (0 disables)
+	StatusInterval time.Duration `json:"statusInterval" mapstructure:"status_interval"`
+}
+
+// WebhookConfig holds the local listener that triggers item syncs from Fabric job events
+type WebhookConfig struct {
+	Enabled bool   `json:"enabled" mapstructure:"enabled"`
+	Address string `json:"address" mapstructure:"address"` // host:port to listen on
+	Secret  string `json:"secret" mapstructure:"secret"`   // Required in the X-Webhook-Secret header when set
 }
 
 // AppConfig holds general application configuration
 type AppConfig struct {
-	Debug    bool   `json:"debug" mapstructure:"debug"`
-	LogLevel string `json:"logLevel" mapstructure:"log_level"`
-	Name     string `json:"name" mapstructure:"name"`
-	Version  string `json:"version" mapstructure:"version"`
+	Debug          bool                 `json:"debug" mapstructure:"debug"`
+	LogLevel       string               `json:"logLevel" mapstructure:"log_level"`
+	Name           string               `json:"name" mapstructure:"name"`
+	Version        string               `json:"version" mapstructure:"version"`
+	MemoryLimitMB  int                  `json:"memoryLimitMb" mapstructure:"memory_limit_mb"` // Soft cap on the Go heap (0 disables)
+	DemoMode       bool                 `json:"demoMode" mapstructure:"demo_mode"`            // Serve generated sample data from a separate database without calling the API
+	Offline        bool                 `json:"offline" mapstructure:"offline"`               // Never call the API; every view is served from the local database
+	LogFile        LogFileConfig        `json:"logFile" mapstructure:"log_file"`
+	ErrorReporting ErrorReportingConfig `json:"errorReporting" mapstructure:"error_reporting"`
+}
+
+// LogFileConfig configures the persistent log file and its rotation
+type LogFileConfig struct {
+	Enabled     bool          `json:"enabled" mapstructure:"enabled"`
+	Dir         string        `json:"dir" mapstructure:"dir"`                  // Directory of the log files (empty uses logs/ in the app data directory)
+	MaxSizeMB   int           `json:"maxSizeMb" mapstructure:"max_size_mb"`    // Rotate once the file reaches this size (0 never rotates by size)
+	RotateEvery time.Duration `json:"rotateEvery" mapstructure:"rotate_every"` // Rotate once the file is this old (0 never rotates by age)
+	MaxAgeDays  int           `json:"maxAgeDays" mapstructure:"max_age_days"`  // Remove rotated files older than this (0 keeps them)
+	MaxBackups  int           `json:"maxBackups" mapstructure:"max_backups"`   // Keep at most this many rotated files (0 keeps all)
+}
+
+// ErrorReportingConfig configures the opt-in reports of panics and logged errors, sanitized of secrets and tenant data
+type ErrorReportingConfig struct {
+	Enabled    bool   `json:"enabled" mapstructure:"enabled"`
+	Endpoint   string `json:"endpoint" mapstructure:"endpoint"`       // URL reports are POSTed to as JSON (empty only saves them locally)
+	Dir        string `json:"dir" mapstructure:"dir"`                 // Directory of local report files (empty uses reports/ in the app data directory)
+	MaxPerHour int    `json:"maxPerHour" mapstructure:"max_per_hour"` // Error reports per rolling hour (0 is unlimited)
 }
 
+// TracingConfig holds the OpenTelemetry exporter that sync spans are sent to
+type TracingConfig struct {
+	Enabled     bool              `json:"enabled" mapstructure:"enabled"`
+	Endpoint    string            `json:"endpoint" mapstructure:"endpoint"`        // host:port of an OTLP/HTTP collector, or its full URL
+	Insecure    bool              `json:"insecure" mapstructure:"insecure"`        // Export over plain HTTP when the endpoint has no scheme
+	Headers     map[string]string `json:"headers" mapstructure:"headers"`          // Sent with every export, e.g. an API key of a hosted collector
+	SampleRatio float64           `json:"sampleRatio" mapstructure:"sample_ratio"` // Fraction of sync runs traced (1 traces all)
+	ServiceName string            `json:"serviceName" mapstructure:"service_name"`
+}
+
+// TLSConfig holds the TLS settings of the Fabric, sign-in, webhook and error report HTTPS clients, e.g. to trust
+// the certificate of a TLS-inspecting corporate proxy
+type TLSConfig struct {
+	CAFiles            []string `json:"caFiles" mapstructure:"ca_files"`                        // PEM bundles trusted in addition to the system roots
+	MinVersion         string   `json:"minVersion" mapstructure:"min_version"`                  // Lowest TLS version accepted: 1.2 or 1.3 (empty is Go's default)
+	InsecureSkipVerify bool     `json:"insecureSkipVerify" mapstructure:"insecure_skip_verify"` // Accept any server certificate; only for diagnosing a proxy
+}
+
+// Experimental subsystems gated by the features section, so they can ship dark and be enabled per user
+const (
+	FeatureCapacityMetrics = "capacity_metrics" // Load per capacity, from GetCapacityLoad
+	FeatureAdminScanner    = "admin_scanner"    // Tenant-wide scans through the Fabric admin APIs
+	FeatureRESTServer      = "rest_server"      // Local REST API serving the job history
+)
+
+// Features lists every feature flag
+var Features = []string{FeatureCapacityMetrics, FeatureAdminScanner, FeatureRESTServer}
+
+// viperMu serializes loading and saving, which share viper's global state
+var viperMu sync.Mutex
+
 // Load loads configuration from environment variables and config files
 func Load() (*Config, error) {
+	viperMu.Lock()
+	defer viperMu.Unlock()
+	return load()
+}
+
+// Reload loads the configuration again from scratch, dropping values set by earlier saves, e.g. after config.yaml
+// was edited by hand
+func Reload() (*Config, error) {
+	viperMu.Lock()
+	defer viperMu.Unlock()
+	viper.Reset()
+	return load()
+}
+
+// load reads the configuration into viper's global state and returns it
+func load() (*Config, error) {
 	// Set defaults
 	// Use a redirect URI that's commonly registered with public clients
 	// The Azure CLI client accepts http://localhost:8400
 	viper.SetDefault("auth.redirect_uri", "http://localhost:8400")
+	viper.SetDefault("auth.client_secret", "")
 	viper.SetDefault("fabric.base_url", "https://api.fabric.microsoft.com/v1")
+	viper.SetDefault("fabric.include_personal", false)
+	viper.SetDefault("fabric.item_cache_ttl", "24h")
+	viper.SetDefault("fabric.stale_job_after", "72h")
+	viper.SetDefault("fabric.max_lookback_days", 0)
+	viper.SetDefault("fabric.portal_host", "app.powerbi.com")
+	viper.SetDefault("fabric.limits.workspace_concurrency", 8)
+	viper.SetDefault("fabric.limits.item_concurrency", 5)
+	viper.SetDefault("fabric.limits.initial_rps", 50)
+	viper.SetDefault("fabric.limits.min_rps", 10)
+	viper.SetDefault("fabric.limits.max_rps", 100)
+	viper.SetDefault("fabric.limits.max_retries", 5)
 	viper.SetDefault("database.path", "data/fabric-monitor.db")
 	viper.SetDefault("database.retention_days", 90)
 	viper.SetDefault("database.enable_readonly_replica", true)
 	viper.SetDefault("database.parquet_path", "data/parquet/")
 	viper.SetDefault("database.readonly_path", "data/fabric-monitor-replica.db")
+	viper.SetDefault("database.memory_limit_mb", 1024)
+	viper.SetDefault("database.threads", 0)
+	viper.SetDefault("database.temp_dir", "")
+	viper.SetDefault("database.parquet_export_interval", "10m")
+	viper.SetDefault("database.readonly_if_in_use", false)
 	viper.SetDefault("ui.theme", "dark")
 	viper.SetDefault("ui.primary_color", "#00BCF2")
-	viper.SetDefault("ui.default_view", "dashboard")
+	viper.SetDefault("ui.default_view", "jobs")
+	viper.SetDefault("ui.analytics_days", 7)
 	viper.SetDefault("ui.refresh_interval", "30s")
 	viper.SetDefault("notifications.enabled", true)
 	viper.SetDefault("notifications.on_failure", true)
 	viper.SetDefault("notifications.on_long_running", false)
 	viper.SetDefault("notifications.sound_enabled", true)
 	viper.SetDefault("notifications.long_running_threshold", "30m")
+	viper.SetDefault("notifications.long_running_factor", 0)
+	viper.SetDefault("notifications.on_stuck", false)
+	viper.SetDefault("notifications.stuck_factor", 3)
+	viper.SetDefault("notifications.webhook.enabled", false)
+	viper.SetDefault("notifications.webhook.url", "")
+	viper.SetDefault("notifications.webhook.secret", "")
+	viper.SetDefault("notifications.webhook.quiet_hours", "")
+	viper.SetDefault("notifications.webhook.max_per_hour", 20)
+	viper.SetDefault("notifications.escalation.after_failures", 3)
+	viper.SetDefault("notifications.escalation.webhook.enabled", false)
+	viper.SetDefault("notifications.escalation.webhook.url", "")
+	viper.SetDefault("notifications.escalation.webhook.secret", "")
+	viper.SetDefault("notifications.escalation.webhook.quiet_hours", "")
+	viper.SetDefault("notifications.escalation.webhook.max_per_hour", 20)
+	viper.SetDefault("notifications.sounds.default", "chime")
+	viper.SetDefault("notifications.sounds.failure", "")
+	viper.SetDefault("notifications.sounds.long_running", "")
+	viper.SetDefault("notifications.sounds.stuck", "")
+	viper.SetDefault("notifications.digest.frequency", "off")
+	viper.SetDefault("notifications.digest.at", "08:00")
+	viper.SetDefault("notifications.digest.weekday", "monday")
+	viper.SetDefault("notifications.digest.format", "markdown")
 	viper.SetDefault("polling.interval", "2m")
 	viper.SetDefault("polling.enabled", true)
+	viper.SetDefault("polling.adaptive", true)
+	viper.SetDefault("polling.max_interval", "1h")
+	viper.SetDefault("polling.status_interval", "30s")
+	viper.SetDefault("webhook.enabled", false)
+	viper.SetDefault("webhook.address", "127.0.0.1:8410")
+	viper.SetDefault("webhook.secret", "")
 	viper.SetDefault("app.debug", false)
 	viper.SetDefault("app.log_level", "info")
 	viper.SetDefault("app.name", "Better Fabric Monitor")
 	viper.SetDefault("app.version", "0.2.4")
+	viper.SetDefault("app.memory_limit_mb", 1024)
+	viper.SetDefault("app.demo_mode", false)
+	viper.SetDefault("app.offline", false)
+	viper.SetDefault("app.log_file.enabled", true)
+	viper.SetDefault("app.log_file.dir", "")
+	viper.SetDefault("app.log_file.max_size_mb", 10)
+	viper.SetDefault("app.log_file.rotate_every", "24h")
+	viper.SetDefault("app.log_file.max_age_days", 14)
+	viper.SetDefault("app.log_file.max_backups", 10)
+	viper.SetDefault("app.error_reporting.enabled", false)
+	viper.SetDefault("app.error_reporting.endpoint", "")
+	viper.SetDefault("app.error_reporting.dir", "")
+	viper.SetDefault("app.error_reporting.max_per_hour", 10)
+	viper.SetDefault("tracing.enabled", false)
+	viper.SetDefault("tracing.endpoint", "localhost:4318")
+	viper.SetDefault("tracing.insecure", true)
+	viper.SetDefault("tracing.sample_ratio", 1)
+	viper.SetDefault("tracing.service_name", "better-fabric-monitor")
+	viper.SetDefault("tls.min_version", "")
+	viper.SetDefault("tls.insecure_skip_verify", false)
+	for _, feature := range Features {
+		viper.SetDefault("features."+feature, false)
+	}
 
 	// Environment variable bindings
 	viper.SetEnvPrefix("FABRIC_MONITOR")
@@ -140,12 +364,32 @@ func Load() (*Config, error) {
 		return nil, fmt.Errorf("error unmarshaling config: %w", err)
 	}
 
-	// Parse workspace IDs from comma-separated string
+	// Parse workspace IDs and name patterns from comma-separated strings
 	if workspaceIDsStr := viper.GetString("fabric.workspace_ids"); workspaceIDsStr != "" {
-		config.Fabric.WorkspaceIDs = strings.Split(workspaceIDsStr, ",")
-		for i, id := range config.Fabric.WorkspaceIDs {
-			config.Fabric.WorkspaceIDs[i] = strings.TrimSpace(id)
-		}
+		config.Fabric.WorkspaceIDs = splitList(workspaceIDsStr)
+	}
+	if includeStr := viper.GetString("fabric.include_workspaces"); includeStr != "" {
+		config.Fabric.IncludeWorkspaces = splitList(includeStr)
+	}
+	if excludeStr := viper.GetString("fabric.exclude_workspaces"); excludeStr != "" {
+		config.Fabric.ExcludeWorkspaces = splitList(excludeStr)
+	}
+	if excludedTypesStr := viper.GetString("fabric.excluded_item_types"); excludedTypesStr != "" {
+		config.Fabric.ExcludedItemTypes = splitList(excludedTypesStr)
+	}
+
+	if caFilesStr := viper.GetString("tls.ca_files"); caFilesStr != "" {
+		config.TLS.CAFiles = splitList(caFilesStr)
+	}
+	if defaultIDsStr := viper.GetString("ui.default_workspace_ids"); defaultIDsStr != "" {
+		config.UI.DefaultWorkspaceIDs = splitList(defaultIDsStr)
+	}
+
+	if eventsStr := viper.GetString("notifications.webhook.events"); eventsStr != "" {
+		config.Notifications.Webhook.Events = splitList(eventsStr)
+	}
+	if eventsStr := viper.GetString("notifications.escalation.webhook.events"); eventsStr != "" {
+		config.Notifications.Escalation.Webhook.Events = splitList(eventsStr)
 	}
 
 	// Validate configuration
@@ -158,6 +402,9 @@ func Load() (*Config, error) {
 
 // Save saves the configuration to the config file
 func (c *Config) Save() error {
+	viperMu.Lock()
+	defer viperMu.Unlock()
+
 	configDir, err := getConfigDir()
 	if err != nil {
 		return err
@@ -169,17 +416,72 @@ func (c *Config) Save() error {
 
 	configPath := filepath.Join(configDir, "config.yaml")
 
-	viper.Set("auth", c.Auth)
-	viper.Set("fabric", c.Fabric)
-	viper.Set("database", c.Database)
-	viper.Set("ui", c.UI)
-	viper.Set("notifications", c.Notifications)
-	viper.Set("polling", c.Polling)
-	viper.Set("app", c.App)
+	for section, settings := range c.sections() {
+		viper.Set(section, settings)
+	}
 
 	return viper.WriteConfigAs(configPath)
 }
 
+// sections returns the settings of each top-level section of c, keyed as in config.yaml
+func (c *Config) sections() map[string]map[string]any {
+	return map[string]map[string]any{
+		"auth":          sectionMap(c.Auth),
+		"fabric":        sectionMap(c.Fabric),
+		"database":      sectionMap(c.Database),
+		"ui":            sectionMap(c.UI),
+		"notifications": sectionMap(c.Notifications),
+		"polling":       sectionMap(c.Polling),
+		"webhook":       sectionMap(c.Webhook),
+		"app":           sectionMap(c.App),
+		"tracing":       sectionMap(c.Tracing),
+		"tls":           sectionMap(c.TLS),
+		"features":      featureMap(c.Features),
+	}
+}
+
+// featureMap returns the feature flags of c as a config section
+func featureMap(features map[string]bool) map[string]any {
+	settings := make(map[string]any, len(features))
+	for feature, enabled := range features {
+		settings[feature] = enabled
+	}
+	return settings
+}
+
+// sectionMap returns the settings of a config section keyed as Load reads them, by their mapstructure tags, so saved
+// files load back unchanged; durations are written as strings such as 24h0m0s
+func sectionMap(section any) map[string]any {
+	v := reflect.ValueOf(section)
+	settings := make(map[string]any, v.NumField())
+	for i := 0; i < v.NumField(); i++ {
+		key := strings.Split(v.Type().Field(i).Tag.Get("mapstructure"), ",")[0]
+		switch value := v.Field(i).Interface().(type) {
+		case time.Duration:
+			settings[key] = value.String()
+		default:
+			if v.Field(i).Kind() == reflect.Struct {
+				settings[key] = sectionMap(value)
+			} else {
+				settings[key] = value
+			}
+		}
+	}
+	return settings
+}
+
+// Secrets returns the settings holding secrets, keyed as in config.yaml, so they can be moved to the OS credential
+// manager; each may hold the secret itself or a keyring: reference to it
+func (c *Config) Secrets() map[string]*string {
+	return map[string]*string{
+		"auth.client_secret":                      &c.Auth.ClientSecret,
+		"database.encryption_key":                 &c.Database.EncryptionKey,
+		"webhook.secret":                          &c.Webhook.Secret,
+		"notifications.webhook.secret":            &c.Notifications.Webhook.Secret,
+		"notifications.escalation.webhook.secret": &c.Notifications.Escalation.Webhook.Secret,
+	}
+}
+
 // Validate checks if the configuration is valid
 func (c *Config) Validate() error {
 	// client_id is optional - app will use Microsoft PowerShell public client as fallback
@@ -190,6 +492,24 @@ func (c *Config) Validate() error {
 	return nil
 }
 
+// splitList splits a comma-separated string into trimmed values
+func splitList(value string) []string {
+	parts := strings.Split(value, ",")
+	for i, part := range parts {
+		parts[i] = strings.TrimSpace(part)
+	}
+	return parts
+}
+
+// FilePath returns the path of config.yaml, which may not exist yet
+func FilePath() (string, error) {
+	configDir, err := getConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "config.yaml"), nil
+}
+
 // getConfigDir returns the application config directory
 func getConfigDir() (string, error) {
 	configDir, err := os.UserConfigDir()
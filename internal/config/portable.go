@@ -0,0 +1,70 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/spf13/viper"
+)
+
+// Export writes the settings of c to path as YAML in the layout of config.yaml, leaving out what must not leave the
+// machine: secrets, webhook URLs, which often embed an integration key, tracing headers and the app version
+func (c *Config) Export(path string) error {
+	portable := *c
+	clearLocal(&portable)
+
+	v := viper.New()
+	v.SetConfigType("yaml")
+	for section, settings := range portable.sections() {
+		v.Set(section, settings)
+	}
+	return v.WriteConfigAs(path)
+}
+
+// Import returns current with the settings in the exported file at path applied over it
+// Settings missing from the file keep their current values, and the local settings Export leaves out are never
+// taken from the file, so importing does not clear or replace this machine's secrets
+func Import(path string, current *Config) (*Config, error) {
+	// The current settings are the defaults the file overrides, so lists in the file replace current lists whole
+	v := viper.New()
+	for section, settings := range current.sections() {
+		v.SetDefault(section, settings)
+	}
+	v.SetConfigFile(path)
+	v.SetConfigType("yaml")
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("failed to read settings file: %w", err)
+	}
+
+	var imported Config
+	if err := v.Unmarshal(&imported); err != nil {
+		return nil, fmt.Errorf("failed to parse settings file: %w", err)
+	}
+	copyLocal(&imported, current)
+	if err := imported.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid settings file: %w", err)
+	}
+	return &imported, nil
+}
+
+// clearLocal empties the settings of c that Export leaves out
+func clearLocal(c *Config) {
+	for _, secret := range c.Secrets() {
+		*secret = ""
+	}
+	c.Notifications.Webhook.URL = ""
+	c.Notifications.Escalation.Webhook.URL = ""
+	c.Tracing.Headers = nil
+	c.App.Version = ""
+}
+
+// copyLocal sets the settings of dst that Export leaves out to those of src
+func copyLocal(dst, src *Config) {
+	dstSecrets := dst.Secrets()
+	for key, secret := range src.Secrets() {
+		*dstSecrets[key] = *secret
+	}
+	dst.Notifications.Webhook.URL = src.Notifications.Webhook.URL
+	dst.Notifications.Escalation.Webhook.URL = src.Notifications.Escalation.Webhook.URL
+	dst.Tracing.Headers = src.Tracing.Headers
+	dst.App.Version = src.App.Version
+}
@@ -0,0 +1,103 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"better-fabric-monitor/internal/logger"
+)
+
+// watchDebounce is how long config.yaml must stay unchanged before a change is reported, since editors often write
+// a file in several steps
+const watchDebounce = 500 * time.Millisecond
+
+// Watch calls onChange after config.yaml is written, created, replaced or removed, until ctx is done
+// The directory is watched rather than the file, so editors that save by replacing the file are picked up too
+func Watch(ctx context.Context, onChange func()) error {
+	path, err := FilePath()
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create config watcher: %w", err)
+	}
+	if err := watcher.Add(dir); err != nil {
+		watcher.Close()
+		return fmt.Errorf("failed to watch %s: %w", dir, err)
+	}
+
+	go func() {
+		defer watcher.Close()
+		debounce := time.NewTimer(watchDebounce)
+		debounce.Stop()
+		defer debounce.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if filepath.Base(event.Name) == filepath.Base(path) && !event.Has(fsnotify.Chmod) {
+					debounce.Reset(watchDebounce)
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				logger.Warn("Config watcher error", logger.Err(err))
+			case <-debounce.C:
+				onChange()
+			}
+		}
+	}()
+	return nil
+}
+
+// Diff returns the keys of the settings that differ between a and b, dotted as in config.yaml, e.g. polling.interval
+func Diff(a, b *Config) []string {
+	var keys []string
+	diffValues("", reflect.ValueOf(*a), reflect.ValueOf(*b), &keys)
+	return keys
+}
+
+// diffValues appends the keys under prefix whose values differ between a and b, recursing into nested sections
+func diffValues(prefix string, a, b reflect.Value, keys *[]string) {
+	for i := 0; i < a.NumField(); i++ {
+		field := a.Type().Field(i)
+		key := prefix + strings.Split(field.Tag.Get("mapstructure"), ",")[0]
+		if field.Type.Kind() == reflect.Struct {
+			diffValues(key+".", a.Field(i), b.Field(i), keys)
+			continue
+		}
+		if !equalValues(a.Field(i), b.Field(i)) {
+			*keys = append(*keys, key)
+		}
+	}
+}
+
+// equalValues reports whether a and b are deeply equal, treating nil and empty lists and maps as equal
+func equalValues(a, b reflect.Value) bool {
+	switch a.Kind() {
+	case reflect.Slice, reflect.Map:
+		if a.Len() == 0 && b.Len() == 0 {
+			return true
+		}
+	}
+	return reflect.DeepEqual(a.Interface(), b.Interface())
+}
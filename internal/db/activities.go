@@ -0,0 +1,85 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// GetActivityStats aggregates the activity runs of pipeline runs started in the last days by pipeline and
+// activity name, each pipeline's activities ordered by their share of its run time
+func (db *Database) GetActivityStats(days int, workspaceIDs []string, itemTypes []string, itemNameSearch string) ([]ActivityStats, error) {
+	filterClause, filterArgs := buildFilterConditions(workspaceIDs, itemTypes, itemNameSearch)
+	return db.getActivityStats(days, filterClause, filterArgs)
+}
+
+// GetPipelineActivityStats aggregates the activity runs of one pipeline's runs started in the last days by
+// activity name, ordered by their share of its run time
+func (db *Database) GetPipelineActivityStats(itemID string, days int) ([]ActivityStats, error) {
+	return db.getActivityStats(days, " AND j.item_id = ?", []interface{}{itemID})
+}
+
+// getActivityStats aggregates the activity runs of the pipeline runs started in the last days that match
+// filterClause
+// An activity failed in a run when its last attempt, or that of its last loop iteration, failed
+func (db *Database) getActivityStats(days int, filterClause string, filterArgs []interface{}) ([]ActivityStats, error) {
+	query := fmt.Sprintf(`
+		WITH activities AS (
+			SELECT j.id AS job_id, j.item_id, j.workspace_id, j.start_time, j.duration_ms AS run_ms,
+				unnest(CAST(j.activity_runs AS JSON[])) AS activity
+			FROM job_instances j
+			LEFT JOIN items i ON j.item_id = i.id
+			WHERE j.activity_runs IS NOT NULL AND j.duration_ms IS NOT NULL
+				AND j.start_time >= CURRENT_TIMESTAMP - INTERVAL (? || ' days')
+			%s
+		),
+		per_run AS (
+			SELECT job_id, item_id, workspace_id, start_time, run_ms,
+				json_extract_string(activity, '$.activityName') AS activity_name,
+				ANY_VALUE(json_extract_string(activity, '$.activityType')) AS activity_type,
+				SUM(CAST(json_extract(activity, '$.durationInMs') AS BIGINT)) AS duration_ms,
+				arg_max(json_extract_string(activity, '$.status'), (
+					COALESCE(TRY_CAST(json_extract(activity, '$.retryAttempt') AS INTEGER), 0),
+					COALESCE(json_extract_string(activity, '$.activityRunStart'), '')
+				)) AS final_status
+			FROM activities
+			GROUP BY job_id, item_id, workspace_id, start_time, run_ms, activity_name
+		)
+		SELECT r.item_id, COALESCE(i.display_name, r.item_id), r.workspace_id, COALESCE(w.display_name, r.workspace_id),
+			r.activity_name, COALESCE(r.activity_type, ''),
+			COUNT(*),
+			COUNT(*) FILTER (WHERE r.final_status = 'Failed'),
+			AVG(r.duration_ms),
+			quantile_cont(r.duration_ms, 0.9),
+			MAX(r.duration_ms),
+			SUM(r.duration_ms) * 100.0 / NULLIF(SUM(r.run_ms), 0),
+			regr_slope(r.duration_ms, epoch(r.start_time)) * 86400
+		FROM per_run r
+		LEFT JOIN items i ON r.item_id = i.id
+		LEFT JOIN workspaces w ON r.workspace_id = w.id
+		WHERE r.activity_name IS NOT NULL AND r.duration_ms IS NOT NULL
+		GROUP BY r.item_id, i.display_name, r.workspace_id, w.display_name, r.activity_name, r.activity_type
+		ORDER BY COALESCE(i.display_name, r.item_id), r.item_id, SUM(r.duration_ms) DESC, r.activity_name
+	`, filterClause)
+
+	rows, err := db.conn.Query(query, append([]interface{}{fmt.Sprintf("%d", days)}, filterArgs...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stats []ActivityStats
+	for rows.Next() {
+		var s ActivityStats
+		var p90, share, trend sql.NullFloat64
+		if err := rows.Scan(&s.ItemID, &s.ItemDisplayName, &s.WorkspaceID, &s.WorkspaceName,
+			&s.ActivityName, &s.ActivityType, &s.Runs, &s.Failed, &s.AvgDurationMs, &p90, &s.MaxDurationMs, &share, &trend); err != nil {
+			return nil, err
+		}
+		s.P90DurationMs, s.ShareOfRunTime, s.TrendMsPerDay = p90.Float64, share.Float64, trend.Float64
+		s.FailureRate = float64(s.Failed) * 100 / float64(s.Runs)
+		// Rows come ordered by total time within each pipeline, so its first activity is the slowest
+		s.Slowest = len(stats) == 0 || stats[len(stats)-1].ItemID != s.ItemID
+		stats = append(stats, s)
+	}
+	return stats, rows.Err()
+}
@@ -0,0 +1,114 @@
+package db
+
+import (
+	"fmt"
+	"time"
+)
+
+// Limits on the earlier runs a run is compared with
+const (
+	anomalyHistoryDays = 90  // How far back they may go
+	anomalyMaxSamples  = 200 // How many of the most recent are used
+	anomalyMinSamples  = 5   // Fewest a run is scored against
+)
+
+// anomalyMADScale makes the modified z-score comparable to a standard z-score for normally distributed data
+const anomalyMADScale = 0.6745
+
+// ScoreRunAnomalies scores every completed run not scored yet against the item's completed runs in the 90 days
+// before it, and returns how many runs were scored
+// Up to the 200 most recent of those runs are used, or of those started on the same local weekday when there are
+// at least 5, since many items do more work on some days. The score is the modified z-score from the median
+// absolute deviation ('mad'), or the standard z-score ('zscore') when more than half the runs took exactly as long
+// Runs whose item has too little history are stored without a score so they aren't retried
+func (db *Database) ScoreRunAnomalies(now time.Time) (int, error) {
+	// A frame holds the runs started in the window before the run, skipping runs started at the same time
+	// (its peers) and the oldest ones past the sample limit; timezone() makes dayofweek use the local time zone
+	result, err := db.conn.Exec(fmt.Sprintf(`
+		INSERT INTO job_anomalies
+		SELECT id, item_id,
+			CASE WHEN samples < %[3]d THEN NULL
+				WHEN mad > 0 THEN %[4]f * (duration_ms - median) / mad
+				WHEN stddev > 0 THEN (duration_ms - mean) / stddev
+				ELSE 0 END,
+			CASE WHEN samples < %[3]d THEN NULL WHEN mad > 0 THEN 'mad' ELSE 'zscore' END,
+			CASE WHEN samples < %[3]d THEN NULL WHEN mad > 0 THEN median ELSE mean END,
+			CASE WHEN samples < %[3]d THEN 0 ELSE samples END,
+			weekday_aware, ?
+		FROM (
+			SELECT id, item_id, duration_ms, weekday_aware,
+				CASE WHEN weekday_aware THEN weekday_samples ELSE item_samples END AS samples,
+				CASE WHEN weekday_aware THEN median(duration_ms) OVER weekday ELSE median(duration_ms) OVER item END AS median,
+				CASE WHEN weekday_aware THEN mad(duration_ms) OVER weekday ELSE mad(duration_ms) OVER item END AS mad,
+				CASE WHEN weekday_aware THEN avg(duration_ms) OVER weekday ELSE avg(duration_ms) OVER item END AS mean,
+				CASE WHEN weekday_aware THEN stddev_pop(duration_ms) OVER weekday ELSE stddev_pop(duration_ms) OVER item END AS stddev
+			FROM (
+				SELECT *, weekday_samples >= %[3]d AS weekday_aware
+				FROM (
+					SELECT *,
+						LEAST(COUNT(*) OVER (PARTITION BY item_id ORDER BY start_time %[1]s), %[2]d) AS item_samples,
+						LEAST(COUNT(*) OVER (PARTITION BY item_id, weekday ORDER BY start_time %[1]s), %[2]d) AS weekday_samples
+					FROM (
+						SELECT j.id, j.item_id, j.start_time, CAST(j.duration_ms AS DOUBLE) AS duration_ms,
+							dayofweek(timezone('UTC', j.start_time)) AS weekday,
+							ROW_NUMBER() OVER (PARTITION BY j.item_id, j.start_time ORDER BY j.id) - 1 AS peers
+						FROM job_instances j
+						WHERE j.status = 'Completed' AND j.duration_ms IS NOT NULL AND j.start_time IS NOT NULL
+							AND j.item_id IN (
+								SELECT p.item_id FROM job_instances p
+								WHERE p.status = 'Completed' AND p.duration_ms IS NOT NULL
+									AND NOT EXISTS (SELECT 1 FROM job_anomalies a WHERE a.job_id = p.id)
+							)
+					) runs
+				) sized
+			) chosen
+			WINDOW item AS (PARTITION BY item_id ORDER BY start_time, id
+					ROWS BETWEEN peers + item_samples PRECEDING AND peers + 1 PRECEDING),
+				weekday AS (PARTITION BY item_id, weekday ORDER BY start_time, id
+					ROWS BETWEEN peers + weekday_samples PRECEDING AND peers + 1 PRECEDING)
+		) scored
+		WHERE NOT EXISTS (SELECT 1 FROM job_anomalies a WHERE a.job_id = scored.id)
+		ON CONFLICT DO NOTHING
+	`, fmt.Sprintf("RANGE BETWEEN INTERVAL %d DAYS PRECEDING AND CURRENT ROW EXCLUDE GROUP", anomalyHistoryDays),
+		anomalyMaxSamples, anomalyMinSamples, anomalyMADScale), now)
+	if err != nil {
+		return 0, err
+	}
+	scored, err := result.RowsAffected()
+	return int(scored), err
+}
+
+// GetAnomalies returns runs started in the last days whose anomaly score is at least minScore
+// in either direction, most unusual first
+func (db *Database) GetAnomalies(days int, minScore float64, limit int) ([]RunAnomaly, error) {
+	query := `
+		SELECT j.id, j.workspace_id, COALESCE(w.display_name, j.workspace_id),
+			j.item_id, COALESCE(i.display_name, j.item_id), COALESCE(i.type, ''),
+			j.start_time, j.duration_ms, a.score, a.method, a.baseline_ms, a.samples, a.weekday_aware
+		FROM job_anomalies a
+		JOIN job_instances j ON a.job_id = j.id
+		LEFT JOIN items i ON j.item_id = i.id
+		LEFT JOIN workspaces w ON j.workspace_id = w.id
+		WHERE a.score IS NOT NULL AND ABS(a.score) >= ?
+			AND j.start_time >= CURRENT_TIMESTAMP - INTERVAL (? || ' days')
+		ORDER BY ABS(a.score) DESC
+		LIMIT ?
+	`
+
+	rows, err := db.conn.Query(query, minScore, fmt.Sprintf("%d", days), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var anomalies []RunAnomaly
+	for rows.Next() {
+		var a RunAnomaly
+		if err := rows.Scan(&a.JobID, &a.WorkspaceID, &a.WorkspaceName, &a.ItemID, &a.ItemDisplayName, &a.ItemType,
+			&a.StartTime, &a.DurationMs, &a.Score, &a.Method, &a.BaselineMs, &a.Samples, &a.WeekdayAware); err != nil {
+			return nil, err
+		}
+		anomalies = append(anomalies, a)
+	}
+	return anomalies, rows.Err()
+}
@@ -0,0 +1,105 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+)
+
+// seedDurations saves an item with one completed run per duration, the first at first and the rest every step after
+func seedDurations(t *testing.T, database *Database, itemID string, first time.Time, step time.Duration, durationsMs ...int64) {
+	t.Helper()
+	now := time.Now().UTC()
+	if err := database.SaveWorkspace(&Workspace{ID: "ws", DisplayName: "Workspace", Type: "Workspace", CreatedAt: now, UpdatedAt: now}); err != nil {
+		t.Fatalf("SaveWorkspace: %v", err)
+	}
+	if err := database.SaveItem(&Item{ID: itemID, WorkspaceID: "ws", DisplayName: itemID, Type: "Notebook", CreatedAt: now, UpdatedAt: now}); err != nil {
+		t.Fatalf("SaveItem: %v", err)
+	}
+
+	jobs := make([]JobInstance, len(durationsMs))
+	for n := range durationsMs {
+		start := first.Add(time.Duration(n) * step)
+		end := start.Add(time.Duration(durationsMs[n]) * time.Millisecond)
+		jobs[n] = JobInstance{ID: fmt.Sprintf("%s-%03d", itemID, n), WorkspaceID: "ws", ItemID: itemID, JobType: "RunNotebook",
+			Status: "Completed", StartTime: start, EndTime: &end, DurationMs: &durationsMs[n], CreatedAt: now, UpdatedAt: now}
+	}
+	if err := database.SaveJobInstances(jobs); err != nil {
+		t.Fatalf("SaveJobInstances: %v", err)
+	}
+}
+
+// storedAnomaly is a row of job_anomalies
+type storedAnomaly struct {
+	score, baseline sql.NullFloat64
+	method          sql.NullString
+	samples         int
+	weekdayAware    bool
+}
+
+func getStoredAnomaly(t *testing.T, database *Database, jobID string) storedAnomaly {
+	t.Helper()
+	var a storedAnomaly
+	err := database.conn.QueryRow(`SELECT score, baseline_ms, method, samples, weekday_aware FROM job_anomalies WHERE job_id = ?`, jobID).
+		Scan(&a.score, &a.baseline, &a.method, &a.samples, &a.weekdayAware)
+	if err != nil {
+		t.Fatalf("job_anomalies row of %s: %v", jobID, err)
+	}
+	return a
+}
+
+func TestScoreRunAnomalies(t *testing.T) {
+	database := newTestDatabase(t)
+	// Weekdays are taken in the session time zone, so pin it for runs placed around midnight UTC
+	if _, err := database.conn.Exec(`SET GLOBAL TimeZone = 'UTC'`); err != nil {
+		t.Fatalf("set time zone: %v", err)
+	}
+	midnight := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -200)
+
+	// Weekly runs: the last is compared with the 12 runs of the 90 days before it, all on its weekday
+	weekly := []int64{1000, 1000, 1000, 1000, 1000, 1000, 1000}
+	for n := 0; n < 2; n++ {
+		weekly = append(weekly, 58000, 59000, 60000, 60000, 61000, 62000)
+	}
+	weekly = append(weekly, 120000)
+	seedDurations(t, database, "weekly", midnight.Add(12*time.Hour), 7*24*time.Hour, weekly...)
+
+	// Runs every two minutes on one day: the last is compared with the 200 before it
+	frequent := make([]int64, 260)
+	for n := range frequent {
+		frequent[n] = 60000
+	}
+	seedDurations(t, database, "frequent", midnight.AddDate(0, 0, 150), 2*time.Minute, frequent...)
+
+	scored, err := database.ScoreRunAnomalies(time.Now().UTC())
+	if err != nil {
+		t.Fatalf("ScoreRunAnomalies: %v", err)
+	}
+	if scored != len(weekly)+len(frequent) {
+		t.Errorf("scored %d runs, want %d", scored, len(weekly)+len(frequent))
+	}
+
+	// 58, 59, 60, 60, 61, 62 seconds twice: median 60s, median absolute deviation 1s
+	last := getStoredAnomaly(t, database, fmt.Sprintf("weekly-%03d", len(weekly)-1))
+	if !last.score.Valid || math.Abs(last.score.Float64-0.6745*60) > 1e-9 || last.method.String != "mad" || last.baseline.Float64 != 60000 ||
+		last.samples != 12 || !last.weekdayAware {
+		t.Errorf("last weekly run = %+v, want a MAD score of %v against 12 weekday runs", last, 0.6745*60)
+	}
+
+	first := getStoredAnomaly(t, database, "weekly-000")
+	if first.score.Valid || first.method.Valid || first.samples != 0 {
+		t.Errorf("first weekly run = %+v, want it stored without a score", first)
+	}
+
+	// Every run took as long, so the MAD is 0 and the z-score of a run like the others is 0
+	capped := getStoredAnomaly(t, database, fmt.Sprintf("frequent-%03d", len(frequent)-1))
+	if !capped.score.Valid || capped.score.Float64 != 0 || capped.method.String != "zscore" || capped.samples != 200 {
+		t.Errorf("last frequent run = %+v, want a z-score of 0 against 200 runs", capped)
+	}
+
+	if scored, err := database.ScoreRunAnomalies(time.Now().UTC()); err != nil || scored != 0 {
+		t.Errorf("second scoring = %d, %v; want no runs left to score", scored, err)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"database/sql/driver"
+	"encoding/json"
 	"fmt"
 	"strings"
 	"time"
@@ -185,6 +186,11 @@ func appendJobInstances(driverConn driver.Conn, jobs []JobInstance) error {
 			rootActivityID = *job.RootActivityID
 		}
 
+		var failureDetails interface{} = nil
+		if job.FailureDetails != nil {
+			failureDetails = json.RawMessage(*job.FailureDetails) // Raw JSON, not a JSON string
+		}
+
 		// Handle ActivityRuns - convert empty slice to NULL for proper enrichment later
 		var activityRuns interface{} = nil
 		if len(job.ActivityRuns) > 0 {
@@ -206,6 +212,8 @@ func appendJobInstances(driverConn driver.Conn, jobs []JobInstance) error {
 			activityRuns,
 			currentTime, // created_at - use explicit timestamp
 			currentTime, // updated_at - use explicit timestamp
+			failureDetails,
+			nil, // removed_upstream_at - the run was just returned by the API
 		)
 		if err != nil {
 			return fmt.Errorf("failed to append job instance %s: %w", job.ID, err)
@@ -428,6 +436,11 @@ func appendItems(driverConn driver.Conn, items []Item) error {
 			description = *item.Description
 		}
 
+		var lastDiscovered interface{} = nil
+		if item.LastDiscovered != nil {
+			lastDiscovered = item.LastDiscovered.UTC()
+		}
+
 		err = appender.AppendRow(
 			item.ID,
 			item.WorkspaceID,
@@ -436,6 +449,7 @@ func appendItems(driverConn driver.Conn, items []Item) error {
 			description,
 			currentTime, // created_at - use explicit timestamp
 			currentTime, // updated_at - use explicit timestamp
+			lastDiscovered,
 		)
 		if err != nil {
 			return fmt.Errorf("failed to append item %s: %w", item.ID, err)
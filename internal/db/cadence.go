@@ -0,0 +1,168 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// cadenceMinRuns is how many runs an item needs before its cadence is inferred
+const cadenceMinRuns = 5
+
+// scheduleUnits are the intervals Fabric schedules are usually set to; a cadence within
+// cadenceTolerance of one is taken to be that schedule
+var scheduleUnits = []time.Duration{
+	time.Minute, 5 * time.Minute, 10 * time.Minute, 15 * time.Minute, 30 * time.Minute,
+	time.Hour, 2 * time.Hour, 3 * time.Hour, 4 * time.Hour, 6 * time.Hour, 8 * time.Hour, 12 * time.Hour,
+	24 * time.Hour, 7 * 24 * time.Hour,
+}
+
+const (
+	cadenceTolerance = 0.1 // Fraction of the schedule unit a median interval may be off by
+	irregularSpread  = 0.5 // Median deviation of the intervals, as a fraction of the cadence, above which runs have no cadence
+	gapFactor        = 2   // An interval this many cadences long means at least one run is missing
+	maxDrift         = 15 * time.Minute
+)
+
+// GetItemCadences infers each item's cadence from its runs started in the last days and checks it for gaps,
+// drift and overdue runs at now
+// Manual runs are left out since they follow no schedule, and so are items with fewer than 5 runs
+func (db *Database) GetItemCadences(days int, now time.Time, workspaceIDs []string, itemTypes []string, itemNameSearch string) ([]ItemCadence, error) {
+	filterClause, filterArgs := buildFilterConditions(workspaceIDs, itemTypes, itemNameSearch)
+	return db.itemCadences(days, now, filterClause, filterArgs)
+}
+
+// GetItemCadence infers the cadence of one item from its runs started in the last days, or returns nil
+// when it has fewer than 5 runs
+func (db *Database) GetItemCadence(itemID string, days int, now time.Time) (*RunCadence, error) {
+	cadences, err := db.itemCadences(days, now, "AND j.item_id = ?", []interface{}{itemID})
+	if err != nil || len(cadences) == 0 {
+		return nil, err
+	}
+	return &cadences[0].RunCadence, nil
+}
+
+// itemCadences infers the cadences of the items whose runs match filterClause
+// The cadence is the median interval between runs; runs are regular when the median absolute deviation of the
+// intervals is at most half of it. Drift is the slope of each run's offset from a schedule grid anchored at the
+// first run, times the time the runs span, so skipped runs don't affect it. Each offset is taken within half a
+// schedule of the previous one, so a drift past half the schedule keeps growing
+func (db *Database) itemCadences(days int, now time.Time, filterClause string, filterArgs []interface{}) ([]ItemCadence, error) {
+	units := make([]string, len(scheduleUnits))
+	for i, unit := range scheduleUnits {
+		units[i] = fmt.Sprintf("(%d)", unit.Microseconds())
+	}
+	query := fmt.Sprintf(`
+		SELECT item_id, item_name, item_type, workspace_id, workspace_name, runs,
+			CAST(trunc(median_us / 1000) AS BIGINT),
+			CASE WHEN regular THEN COALESCE(schedule_us, 0) // 1000 ELSE 0 END,
+			regular,
+			CASE WHEN regular THEN gaps ELSE 0 END,
+			CASE WHEN regular THEN longest_us // 1000 ELSE 0 END,
+			drift_ms,
+			abs(drift_ms) * 1000 >= LEAST(%[6]d, schedule_us / 4),
+			last_run_at,
+			last_run_at + to_microseconds(CAST(trunc(median_us) AS BIGINT)),
+			regular AND last_run_at + to_microseconds(CAST(trunc(%[5]d * median_us) AS BIGINT)) < ?
+		FROM (
+			SELECT item_id, ANY_VALUE(item_name) AS item_name, ANY_VALUE(item_type) AS item_type,
+				ANY_VALUE(workspace_id) AS workspace_id, ANY_VALUE(workspace_name) AS workspace_name,
+				COUNT(*) AS runs, ANY_VALUE(median_us) AS median_us, ANY_VALUE(regular) AS regular,
+				ANY_VALUE(schedule_us) AS schedule_us, MAX(start_time) AS last_run_at, MAX(interval_us) AS longest_us,
+				COUNT(*) FILTER (WHERE interval_us > %[5]d * median_us) AS gaps,
+				CASE WHEN ANY_VALUE(regular) AND ANY_VALUE(schedule_us) IS NOT NULL
+					THEN CAST(trunc(COALESCE(regr_slope(offset_us, elapsed_us) * MAX(elapsed_us), 0) / 1000) AS BIGINT)
+					ELSE 0 END AS drift_ms
+			FROM (
+				SELECT *,
+					COALESCE(SUM(CASE WHEN interval_us %% schedule_us > schedule_us / 2
+						THEN interval_us %% schedule_us - schedule_us ELSE interval_us %% schedule_us END)
+						OVER (PARTITION BY item_id ORDER BY start_time, id ROWS UNBOUNDED PRECEDING), 0) AS offset_us
+				FROM (
+					SELECT *, mad_us / median_us <= %[3]f AS regular,
+						(SELECT MIN(unit) FROM (VALUES %[1]s) units(unit)
+							WHERE abs(median_us - unit) <= %[2]f * unit) AS schedule_us
+					FROM (
+						SELECT *, COUNT(*) OVER item AS item_runs,
+							median(interval_us) OVER item AS median_us, mad(interval_us) OVER item AS mad_us
+						FROM (
+							SELECT j.id, j.item_id, COALESCE(i.display_name, j.item_id) AS item_name,
+								COALESCE(i.type, '') AS item_type, j.workspace_id,
+								COALESCE(w.display_name, j.workspace_id) AS workspace_name, j.start_time,
+								epoch_us(j.start_time) - epoch_us(LAG(j.start_time) OVER starts) AS interval_us,
+								epoch_us(j.start_time) - epoch_us(FIRST_VALUE(j.start_time) OVER starts) AS elapsed_us
+							FROM job_instances j
+							LEFT JOIN items i ON j.item_id = i.id
+							LEFT JOIN workspaces w ON j.workspace_id = w.id
+							WHERE j.start_time IS NOT NULL
+								AND j.start_time >= CURRENT_TIMESTAMP - INTERVAL (? || ' days')
+								AND COALESCE(j.invoker_type, '') <> 'Manual'
+								%[7]s
+							WINDOW starts AS (PARTITION BY j.item_id ORDER BY j.start_time, j.id)
+						) runs
+						WINDOW item AS (PARTITION BY item_id)
+					) spread
+					WHERE item_runs >= %[4]d AND median_us > 0
+				) scheduled
+			) offsets
+			GROUP BY item_id
+		) cadences
+		ORDER BY item_id
+	`, strings.Join(units, ", "), cadenceTolerance, irregularSpread, cadenceMinRuns, gapFactor,
+		maxDrift.Microseconds(), filterClause)
+
+	args := append([]interface{}{now, fmt.Sprintf("%d", days)}, filterArgs...)
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var cadences []ItemCadence
+	for rows.Next() {
+		var c ItemCadence
+		if err := rows.Scan(&c.ItemID, &c.ItemDisplayName, &c.ItemType, &c.WorkspaceID, &c.WorkspaceName, &c.Runs,
+			&c.CadenceMs, &c.ScheduleMs, &c.Regular, &c.Gaps, &c.LongestGapMs, &c.DriftMs, &c.Drifting,
+			&c.LastRunAt, &c.NextExpectedAt, &c.Overdue); err != nil {
+			return nil, err
+		}
+		cadences = append(cadences, c)
+	}
+	return cadences, rows.Err()
+}
+
+// GetItemRunGaps returns the intervals between an item's scheduled runs started in the last days, oldest first,
+// that are more than two cadences long, with how many runs were skipped in each
+func (db *Database) GetItemRunGaps(itemID string, days int, cadenceMs int64) ([]RunGap, error) {
+	gaps := []RunGap{}
+	if cadenceMs <= 0 {
+		return gaps, nil
+	}
+	rows, err := db.conn.Query(fmt.Sprintf(`
+		SELECT previous_start, start_time, CAST(round(interval_us / (? * 1000)) AS BIGINT) - 1
+		FROM (
+			SELECT start_time, LAG(start_time) OVER starts AS previous_start,
+				epoch_us(start_time) - epoch_us(LAG(start_time) OVER starts) AS interval_us
+			FROM job_instances
+			WHERE item_id = ?
+				AND start_time >= CURRENT_TIMESTAMP - INTERVAL (? || ' days')
+				AND COALESCE(invoker_type, '') <> 'Manual'
+			WINDOW starts AS (ORDER BY start_time, id)
+		) intervals
+		WHERE interval_us > %d * ? * 1000
+		ORDER BY start_time
+	`, gapFactor), cadenceMs, itemID, fmt.Sprintf("%d", days), cadenceMs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var gap RunGap
+		if err := rows.Scan(&gap.From, &gap.To, &gap.MissedRuns); err != nil {
+			return nil, err
+		}
+		gaps = append(gaps, gap)
+	}
+	return gaps, rows.Err()
+}
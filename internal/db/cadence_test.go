@@ -0,0 +1,75 @@
+package db
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// Hourly runs that start a minute later each time, with two runs skipped and a manual run in between
+func TestGetItemCadences(t *testing.T) {
+	database := newTestDatabase(t)
+	now := time.Now().UTC().Truncate(time.Hour)
+	if err := database.SaveWorkspace(&Workspace{ID: "ws", DisplayName: "Workspace", Type: "Workspace", CreatedAt: now, UpdatedAt: now}); err != nil {
+		t.Fatalf("SaveWorkspace: %v", err)
+	}
+	if err := database.SaveItem(&Item{ID: "hourly", WorkspaceID: "ws", DisplayName: "Hourly", Type: "DataPipeline", CreatedAt: now, UpdatedAt: now}); err != nil {
+		t.Fatalf("SaveItem: %v", err)
+	}
+
+	first := now.Add(-30 * time.Hour)
+	var jobs []JobInstance
+	for n := 0; n < 24; n++ {
+		if n == 10 || n == 11 {
+			continue
+		}
+		jobs = append(jobs, JobInstance{ID: fmt.Sprintf("run-%02d", n), WorkspaceID: "ws", ItemID: "hourly", JobType: "Pipeline",
+			Status: "Completed", StartTime: first.Add(time.Duration(n) * 61 * time.Minute), CreatedAt: now, UpdatedAt: now})
+	}
+	manual := InvokerManual
+	jobs = append(jobs, JobInstance{ID: "manual", WorkspaceID: "ws", ItemID: "hourly", JobType: "Pipeline", Status: "Completed",
+		StartTime: first.Add(10*time.Hour + 17*time.Minute), InvokerType: &manual, CreatedAt: now, UpdatedAt: now})
+	if err := database.SaveJobInstances(jobs); err != nil {
+		t.Fatalf("SaveJobInstances: %v", err)
+	}
+
+	cadences, err := database.GetItemCadences(30, now, nil, nil, "")
+	if err != nil {
+		t.Fatalf("GetItemCadences: %v", err)
+	}
+	if len(cadences) != 1 {
+		t.Fatalf("got %d cadences, want 1", len(cadences))
+	}
+	c := cadences[0].RunCadence
+	lastRun := first.Add(23 * 61 * time.Minute)
+	if c.Runs != 22 || c.CadenceMs != (61*time.Minute).Milliseconds() || c.ScheduleMs != time.Hour.Milliseconds() || !c.Regular {
+		t.Errorf("cadence = %+v, want 22 regular runs every 61 minutes on an hourly schedule", c)
+	}
+	if c.Gaps != 1 || c.LongestGapMs != (3*61*time.Minute).Milliseconds() {
+		t.Errorf("gaps = %d, longest %dms; want one of 183 minutes", c.Gaps, c.LongestGapMs)
+	}
+	// A minute later every run, whether or not runs were skipped in between
+	if drift := time.Duration(c.DriftMs) * time.Millisecond; drift < 23*time.Minute-time.Second || drift > 23*time.Minute || !c.Drifting {
+		t.Errorf("drift = %v (drifting %v), want 23m", drift, c.Drifting)
+	}
+	if !c.LastRunAt.Equal(lastRun) || !c.NextExpectedAt.Equal(lastRun.Add(61*time.Minute)) || !c.Overdue {
+		t.Errorf("last run %v, next expected %v, overdue %v; want %v, %v, true", c.LastRunAt, c.NextExpectedAt, c.Overdue,
+			lastRun, lastRun.Add(61*time.Minute))
+	}
+
+	gaps, err := database.GetItemRunGaps("hourly", 30, c.CadenceMs)
+	if err != nil {
+		t.Fatalf("GetItemRunGaps: %v", err)
+	}
+	if len(gaps) != 1 || !gaps[0].From.Equal(first.Add(9*61*time.Minute)) || !gaps[0].To.Equal(first.Add(12*61*time.Minute)) ||
+		gaps[0].MissedRuns != 2 {
+		t.Errorf("gaps = %+v, want two runs missed after the tenth", gaps)
+	}
+
+	if cadence, err := database.GetItemCadence("hourly", 30, now); err != nil || cadence == nil || *cadence != c {
+		t.Errorf("GetItemCadence = %+v, %v; want %+v", cadence, err, c)
+	}
+	if cadence, err := database.GetItemCadence("unknown", 30, now); err != nil || cadence != nil {
+		t.Errorf("GetItemCadence of an item without runs = %+v, %v; want nil", cadence, err)
+	}
+}
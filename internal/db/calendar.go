@@ -0,0 +1,74 @@
+package db
+
+import (
+	"fmt"
+	"time"
+)
+
+// GetRunCalendar counts the runs started each day in loc from the day of from up to the day of to, with their
+// failures and the runs longer than slaThreshold (0 counts none), returning every day of the range in order
+// Runs are grouped by UTC hour in the database and moved to loc here, like GetRunHeatmap
+func (db *Database) GetRunCalendar(from, to time.Time, loc *time.Location, slaThreshold time.Duration, workspaceIDs []string, itemTypes []string, itemNameSearch string) ([]CalendarDay, error) {
+	var days []CalendarDay
+	index := make(map[string]int)
+	for day := from.In(loc); day.Before(to); day = day.AddDate(0, 0, 1) {
+		start := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, loc)
+		date := start.Format(time.DateOnly)
+		index[date] = len(days)
+		days = append(days, CalendarDay{
+			Date:    date,
+			Weekday: int(start.Weekday()),
+			Start:   start,
+			End:     start.AddDate(0, 0, 1),
+		})
+	}
+	if len(days) == 0 {
+		return days, nil
+	}
+
+	filterClause, filterArgs := buildFilterConditions(workspaceIDs, itemTypes, itemNameSearch)
+	query := fmt.Sprintf(`
+		SELECT DATE_TRUNC('hour', j.start_time) AS hour,
+			COUNT(*),
+			COUNT(*) FILTER (WHERE j.status = 'Failed'),
+			COUNT(*) FILTER (WHERE ? > 0 AND j.duration_ms > ?)
+		FROM job_instances j
+		LEFT JOIN items i ON j.item_id = i.id
+		WHERE j.start_time >= ? AND j.start_time < ?
+		%s
+		GROUP BY DATE_TRUNC('hour', j.start_time)
+	`, filterClause)
+
+	threshold := slaThreshold.Milliseconds()
+	args := append([]interface{}{threshold, threshold, days[0].Start.UTC(), days[len(days)-1].End.UTC()}, filterArgs...)
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var hour time.Time
+		var total, failed, breaches int
+		if err := rows.Scan(&hour, &total, &failed, &breaches); err != nil {
+			return nil, err
+		}
+		i, ok := index[hour.In(loc).Format(time.DateOnly)]
+		if !ok {
+			continue
+		}
+		days[i].TotalJobs += total
+		days[i].Failed += failed
+		days[i].SLABreaches += breaches
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range days {
+		if days[i].TotalJobs > 0 {
+			days[i].FailureRate = float64(days[i].Failed) / float64(days[i].TotalJobs) * 100
+		}
+	}
+	return days, nil
+}
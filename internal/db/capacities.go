@@ -0,0 +1,97 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// GetCapacityLoadStats aggregates the runs started in the last days per capacity of their workspace: runs,
+// failures, how many ran at once and how long notebook sessions queued before Spark started them, busiest
+// capacity first
+// Runs still in progress count until now; runs of workspaces synced before capacities were stored fall under
+// an empty CapacityID
+func (db *Database) GetCapacityLoadStats(days int, workspaceIDs []string, itemTypes []string, itemNameSearch string) ([]CapacityLoadStats, error) {
+	filterClause, filterArgs := buildFilterConditions(workspaceIDs, itemTypes, itemNameSearch)
+	query := fmt.Sprintf(`
+		WITH runs AS (
+			SELECT j.id, j.workspace_id, j.item_id, j.status, j.start_time,
+				COALESCE(w.capacity_id, '') AS capacity_id,
+				GREATEST(COALESCE(j.end_time, CURRENT_TIMESTAMP), j.start_time) AS end_time
+			FROM job_instances j
+			LEFT JOIN items i ON j.item_id = i.id
+			LEFT JOIN workspaces w ON j.workspace_id = w.id
+			WHERE j.start_time >= CURRENT_TIMESTAMP - INTERVAL (? || ' days')
+			%s
+		),
+		events AS (
+			SELECT capacity_id, start_time AS event_at, 1 AS delta FROM runs
+			UNION ALL
+			SELECT capacity_id, end_time AS event_at, -1 AS delta FROM runs
+		),
+		concurrency AS (
+			-- Ends sort before starts at the same instant, so back-to-back runs don't overlap
+			SELECT capacity_id, MAX(running) AS peak, arg_max(event_at, running) AS peak_at
+			FROM (
+				SELECT capacity_id, event_at,
+					SUM(delta) OVER (PARTITION BY capacity_id ORDER BY event_at, delta ROWS UNBOUNDED PRECEDING) AS running
+				FROM events
+			)
+			GROUP BY capacity_id
+		),
+		queues AS (
+			SELECT r.capacity_id, COUNT(*) AS sessions,
+				AVG(s.queued_duration_ms) AS avg_queued,
+				quantile_cont(s.queued_duration_ms, 0.9) AS p90_queued,
+				MAX(s.queued_duration_ms) AS max_queued
+			FROM notebook_sessions s
+			JOIN runs r ON s.job_instance_id = r.id
+			WHERE s.queued_duration_ms IS NOT NULL
+			GROUP BY r.capacity_id
+		)
+		SELECT r.capacity_id,
+			COUNT(DISTINCT r.workspace_id),
+			COUNT(DISTINCT r.item_id),
+			COUNT(*),
+			COUNT(*) FILTER (WHERE r.status = 'Completed'),
+			COUNT(*) FILTER (WHERE r.status = 'Failed'),
+			COUNT(*) FILTER (WHERE r.status = 'Cancelled'),
+			COUNT(*) FILTER (WHERE r.status = 'Failed') * 100.0 / NULLIF(COUNT(*) FILTER (WHERE r.status IN ('Completed', 'Failed')), 0),
+			SUM(epoch_ms(r.end_time) - epoch_ms(r.start_time)),
+			ANY_VALUE(c.peak), ANY_VALUE(c.peak_at),
+			COALESCE(ANY_VALUE(q.sessions), 0), ANY_VALUE(q.avg_queued), ANY_VALUE(q.p90_queued), ANY_VALUE(q.max_queued)
+		FROM runs r
+		LEFT JOIN concurrency c ON c.capacity_id = r.capacity_id
+		LEFT JOIN queues q ON q.capacity_id = r.capacity_id
+		GROUP BY r.capacity_id
+		ORDER BY SUM(epoch_ms(r.end_time) - epoch_ms(r.start_time)) DESC, r.capacity_id
+	`, filterClause)
+
+	rows, err := db.conn.Query(query, append([]interface{}{fmt.Sprintf("%d", days)}, filterArgs...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	window := float64((time.Duration(days) * 24 * time.Hour).Milliseconds())
+	var stats []CapacityLoadStats
+	for rows.Next() {
+		var s CapacityLoadStats
+		var failureRate, avgQueued, p90Queued sql.NullFloat64
+		var maxQueued sql.NullInt64
+		var peakAt sql.NullTime
+		if err := rows.Scan(&s.CapacityID, &s.Workspaces, &s.Items, &s.Runs, &s.Succeeded, &s.Failed, &s.Cancelled,
+			&failureRate, &s.RunTimeMs, &s.PeakConcurrency, &peakAt,
+			&s.Sessions, &avgQueued, &p90Queued, &maxQueued); err != nil {
+			return nil, err
+		}
+		s.FailureRate = failureRate.Float64
+		s.AvgConcurrency = float64(s.RunTimeMs) / window
+		if peakAt.Valid {
+			s.PeakAt = &peakAt.Time
+		}
+		s.AvgQueuedMs, s.P90QueuedMs, s.MaxQueuedMs = avgQueued.Float64, p90Queued.Float64, maxQueued.Int64
+		stats = append(stats, s)
+	}
+	return stats, rows.Err()
+}
@@ -0,0 +1,128 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// copyMetricsInsert parses the Copy activities of the job instances matching the %s condition into copy_activity_metrics
+// Fabric reports rows written as rowsCopied; rowsWritten is read as well for sinks that use it
+// An activity run listed twice in a job's activity runs is kept once
+const copyMetricsInsert = `
+	INSERT INTO copy_activity_metrics
+	SELECT job_id,
+		json_extract_string(activity, '$.activityRunId'),
+		item_id, workspace_id,
+		json_extract_string(activity, '$.activityName'),
+		json_extract_string(activity, '$.status'),
+		COALESCE(TRY_CAST(json_extract_string(activity, '$.activityRunStart') AS TIMESTAMP), start_time),
+		TRY_CAST(json_extract(activity, '$.durationInMs') AS BIGINT),
+		TRY_CAST(json_extract(activity, '$.output.rowsRead') AS BIGINT),
+		TRY_CAST(COALESCE(json_extract(activity, '$.output.rowsCopied'), json_extract(activity, '$.output.rowsWritten')) AS BIGINT),
+		TRY_CAST(json_extract(activity, '$.output.dataRead') AS BIGINT),
+		TRY_CAST(json_extract(activity, '$.output.dataWritten') AS BIGINT),
+		TRY_CAST(json_extract(activity, '$.output.filesRead') AS BIGINT),
+		TRY_CAST(json_extract(activity, '$.output.filesWritten') AS BIGINT),
+		TRY_CAST(json_extract(activity, '$.output.throughput') AS DOUBLE),
+		TRY_CAST(json_extract(activity, '$.output.copyDuration') AS DOUBLE),
+		TRY_CAST(json_extract(activity, '$.output.executionDetails[0].detailedDurations.queuingDuration') AS DOUBLE),
+		TRY_CAST(json_extract(activity, '$.output.executionDetails[0].detailedDurations.timeToFirstByte') AS DOUBLE),
+		TRY_CAST(json_extract(activity, '$.output.executionDetails[0].detailedDurations.transferDuration') AS DOUBLE)
+	FROM (
+		SELECT j.id AS job_id, j.item_id, j.workspace_id, j.start_time,
+			unnest(list_filter(
+				CAST(j.activity_runs AS JSON[]),
+				x -> json_extract_string(x, '$.activityType') = 'Copy'
+			)) AS activity
+		FROM job_instances j
+		WHERE j.activity_runs IS NOT NULL AND %s
+	)
+	WHERE json_extract_string(activity, '$.activityRunId') IS NOT NULL
+	QUALIFY ROW_NUMBER() OVER (PARTITION BY job_id, json_extract_string(activity, '$.activityRunId')) = 1
+`
+
+// saveCopyMetrics replaces the copy metrics of a job instance with those of its stored activity runs
+// Runs without Copy activities are skipped, since the activities of a finished run don't go away
+func (db *Database) saveCopyMetrics(jobID string, activityRuns []ActivityRun) error {
+	hasCopy := false
+	for _, activity := range activityRuns {
+		hasCopy = hasCopy || activity.ActivityType == "Copy"
+	}
+	if !hasCopy {
+		return nil
+	}
+
+	if _, err := db.conn.Exec("DELETE FROM copy_activity_metrics WHERE job_id = ?", jobID); err != nil {
+		return fmt.Errorf("failed to clear copy metrics: %w", err)
+	}
+	if _, err := db.conn.Exec(fmt.Sprintf(copyMetricsInsert, "j.id = ?"), jobID); err != nil {
+		return fmt.Errorf("failed to save copy metrics: %w", err)
+	}
+	return nil
+}
+
+// backfillCopyMetrics parses the copy metrics of activity runs stored before copy_activity_metrics existed
+// It only runs while the table is empty, which costs one scan per start for tenants without Copy activities
+func (db *Database) backfillCopyMetrics() error {
+	var exists bool
+	if err := db.conn.QueryRow("SELECT EXISTS (SELECT 1 FROM copy_activity_metrics)").Scan(&exists); err != nil || exists {
+		return err
+	}
+	if _, err := db.conn.Exec(fmt.Sprintf(copyMetricsInsert, "TRUE")); err != nil {
+		return fmt.Errorf("failed to backfill copy metrics: %w", err)
+	}
+	return nil
+}
+
+// GetCopyThroughput aggregates the Copy activity runs of pipeline runs started in the last days per pipeline
+// and activity, most data written first
+func (db *Database) GetCopyThroughput(days int, workspaceIDs []string, itemTypes []string, itemNameSearch string) ([]CopyThroughputStats, error) {
+	filterClause, filterArgs := buildFilterConditions(workspaceIDs, itemTypes, itemNameSearch)
+	query := fmt.Sprintf(`
+		SELECT j.item_id, COALESCE(i.display_name, j.item_id), j.workspace_id, COALESCE(w.display_name, j.workspace_id),
+			j.activity_name,
+			COUNT(*),
+			COUNT(*) FILTER (WHERE j.status = 'Failed'),
+			COALESCE(SUM(j.rows_read), 0),
+			COALESCE(SUM(j.rows_written), 0),
+			COALESCE(SUM(j.data_read), 0),
+			COALESCE(SUM(j.data_written), 0),
+			AVG(j.throughput_kbps),
+			MIN(j.throughput_kbps),
+			AVG(j.rows_written / NULLIF(j.copy_duration_s, 0)),
+			AVG(j.duration_ms),
+			AVG(j.queuing_duration_s),
+			AVG(j.transfer_duration_s),
+			regr_slope(j.throughput_kbps, epoch(j.start_time)) * 86400
+		FROM copy_activity_metrics j
+		LEFT JOIN items i ON j.item_id = i.id
+		LEFT JOIN workspaces w ON j.workspace_id = w.id
+		WHERE j.start_time >= CURRENT_TIMESTAMP - INTERVAL (? || ' days')
+		%s
+		GROUP BY j.item_id, i.display_name, j.workspace_id, w.display_name, j.activity_name
+		ORDER BY COALESCE(SUM(j.data_written), 0) DESC, COALESCE(SUM(j.rows_written), 0) DESC
+	`, filterClause)
+
+	rows, err := db.conn.Query(query, append([]interface{}{fmt.Sprintf("%d", days)}, filterArgs...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stats []CopyThroughputStats
+	for rows.Next() {
+		var s CopyThroughputStats
+		var avgThroughput, minThroughput, rowsPerSecond, avgDuration, queuing, transfer, trend sql.NullFloat64
+		if err := rows.Scan(&s.ItemID, &s.ItemDisplayName, &s.WorkspaceID, &s.WorkspaceName, &s.ActivityName,
+			&s.Runs, &s.Failed, &s.RowsRead, &s.RowsWritten, &s.DataRead, &s.DataWritten,
+			&avgThroughput, &minThroughput, &rowsPerSecond, &avgDuration, &queuing, &transfer, &trend); err != nil {
+			return nil, err
+		}
+		s.AvgThroughputKBps, s.MinThroughputKBps = avgThroughput.Float64, minThroughput.Float64
+		s.AvgRowsPerSecond, s.AvgDurationMs = rowsPerSecond.Float64, avgDuration.Float64
+		s.AvgQueuingSeconds, s.AvgTransferSeconds = queuing.Float64, transfer.Float64
+		s.ThroughputTrendPerDay = trend.Float64
+		stats = append(stats, s)
+	}
+	return stats, rows.Err()
+}
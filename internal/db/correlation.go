@@ -0,0 +1,152 @@
+package db
+
+import (
+	"fmt"
+	"time"
+)
+
+// GetFailureCorrelations pairs the items whose runs of the last days failed together in at least minShared windows
+// of window length, covering at least minJaccard of the windows either failed in, then groups items linked through
+// such pairs; a run counts in the window it ended in
+// Groups come largest first, then by their most similar pair; their pairs come most similar first and their shared
+// windows, those two or more of the items failed in, latest first
+func (db *Database) GetFailureCorrelations(days int, window time.Duration, minShared int, minJaccard float64, workspaceIDs []string, itemTypes []string, itemNameSearch string) ([]FailureCorrelationGroup, error) {
+	filterClause, filterArgs := buildFilterConditions(workspaceIDs, itemTypes, itemNameSearch)
+	seconds := int64(window / time.Second)
+	args := append([]interface{}{seconds, fmt.Sprintf("%d", days)}, filterArgs...)
+	// linked follows the pairs from each item to every item it is linked to; a group is named after its first item
+	correlated := fmt.Sprintf(`
+		WITH RECURSIVE failures AS (
+			SELECT j.item_id, ANY_VALUE(j.workspace_id) AS workspace_id,
+				CAST(floor(epoch(COALESCE(j.end_time, j.start_time)) / ?) AS BIGINT) AS failure_window,
+				COUNT(*) AS failures
+			FROM job_instances j
+			LEFT JOIN items i ON j.item_id = i.id
+			LEFT JOIN workspaces w ON j.workspace_id = w.id
+			WHERE j.status = 'Failed'
+				AND j.start_time >= CURRENT_TIMESTAMP - INTERVAL (? || ' days')
+				%[1]s
+			GROUP BY j.item_id, failure_window
+		),
+		windows AS (
+			SELECT item_id, COUNT(*) AS windows FROM failures GROUP BY item_id HAVING COUNT(*) >= %[2]d
+		),
+		pairs AS (
+			SELECT *, ROW_NUMBER() OVER (ORDER BY jaccard DESC, shared DESC, a, b) AS pair_rank
+			FROM (
+				SELECT fa.item_id AS a, fb.item_id AS b, COUNT(*) AS shared,
+					COUNT(*) / (ANY_VALUE(wa.windows) + ANY_VALUE(wb.windows) - COUNT(*)) AS jaccard
+				FROM failures fa
+				JOIN failures fb ON fb.failure_window = fa.failure_window AND fb.item_id > fa.item_id
+				JOIN windows wa ON wa.item_id = fa.item_id
+				JOIN windows wb ON wb.item_id = fb.item_id
+				GROUP BY fa.item_id, fb.item_id
+			) candidates
+			WHERE shared >= %[2]d AND jaccard >= %[3]f
+		),
+		edges AS (
+			SELECT a AS item_id, b AS linked_id FROM pairs
+			UNION ALL
+			SELECT b, a FROM pairs
+		),
+		linked(item_id, linked_id) AS (
+			SELECT item_id, linked_id FROM edges
+			UNION
+			SELECT l.item_id, e.linked_id FROM linked l JOIN edges e ON e.item_id = l.linked_id
+		),
+		groups AS (
+			SELECT item_id, LEAST(item_id, MIN(linked_id)) AS group_id FROM linked GROUP BY item_id
+		),
+		group_order AS (
+			SELECT g.group_id, ROW_NUMBER() OVER (ORDER BY g.items DESC, p.pair_rank) AS group_rank
+			FROM (SELECT group_id, COUNT(*) AS items FROM groups GROUP BY group_id) g
+			JOIN (
+				SELECT g.group_id, MIN(p.pair_rank) AS pair_rank
+				FROM pairs p JOIN groups g ON g.item_id = p.a
+				GROUP BY g.group_id
+			) p ON p.group_id = g.group_id
+		)
+	`, filterClause, minShared, minJaccard)
+
+	rows, err := db.conn.Query(correlated+`
+		SELECT o.group_rank, f.item_id, COALESCE(ANY_VALUE(i.display_name), f.item_id), COALESCE(ANY_VALUE(i.type), ''),
+			ANY_VALUE(f.workspace_id), COALESCE(ANY_VALUE(w.display_name), ANY_VALUE(f.workspace_id)), SUM(f.failures), COUNT(*)
+		FROM failures f
+		JOIN groups g ON g.item_id = f.item_id
+		JOIN group_order o ON o.group_id = g.group_id
+		LEFT JOIN items i ON f.item_id = i.id
+		LEFT JOIN workspaces w ON f.workspace_id = w.id
+		GROUP BY o.group_rank, f.item_id
+		ORDER BY o.group_rank, f.item_id
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	groups := []FailureCorrelationGroup{}
+	for rows.Next() {
+		var rank int
+		var item CorrelatedItem
+		if err := rows.Scan(&rank, &item.ItemID, &item.ItemDisplayName, &item.ItemType, &item.WorkspaceID, &item.WorkspaceName,
+			&item.Failures, &item.FailureWindows); err != nil {
+			return nil, err
+		}
+		if rank > len(groups) {
+			groups = append(groups, FailureCorrelationGroup{Pairs: []CorrelatedPair{}, SharedWindows: []time.Time{}})
+		}
+		groups[rank-1].Items = append(groups[rank-1].Items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(groups) == 0 {
+		return groups, nil
+	}
+
+	pairRows, err := db.conn.Query(correlated+`
+		SELECT o.group_rank, p.a, p.b, p.shared, p.jaccard
+		FROM pairs p
+		JOIN groups g ON g.item_id = p.a
+		JOIN group_order o ON o.group_id = g.group_id
+		ORDER BY o.group_rank, p.pair_rank
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer pairRows.Close()
+	for pairRows.Next() {
+		var rank int
+		var pair CorrelatedPair
+		if err := pairRows.Scan(&rank, &pair.A, &pair.B, &pair.Shared, &pair.Jaccard); err != nil {
+			return nil, err
+		}
+		groups[rank-1].Pairs = append(groups[rank-1].Pairs, pair)
+	}
+	if err := pairRows.Err(); err != nil {
+		return nil, err
+	}
+
+	windowRows, err := db.conn.Query(correlated+`
+		SELECT o.group_rank, make_timestamp(f.failure_window * ? * 1000000)
+		FROM failures f
+		JOIN groups g ON g.item_id = f.item_id
+		JOIN group_order o ON o.group_id = g.group_id
+		GROUP BY o.group_rank, f.failure_window
+		HAVING COUNT(*) >= 2
+		ORDER BY o.group_rank, f.failure_window DESC
+	`, append(args, seconds)...)
+	if err != nil {
+		return nil, err
+	}
+	defer windowRows.Close()
+	for windowRows.Next() {
+		var rank int
+		var start time.Time
+		if err := windowRows.Scan(&rank, &start); err != nil {
+			return nil, err
+		}
+		groups[rank-1].SharedWindows = append(groups[rank-1].SharedWindows, start)
+	}
+	return groups, windowRows.Err()
+}
@@ -0,0 +1,66 @@
+package db
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// seedFailures saves a failed run of itemID ending a minute into each of the windows, counted back from last
+func seedFailures(t *testing.T, database *Database, last time.Time, window time.Duration, itemID string, windows ...int) {
+	t.Helper()
+	now := time.Now().UTC()
+	if err := database.SaveWorkspace(&Workspace{ID: "ws", DisplayName: "Workspace", Type: "Workspace", CreatedAt: now, UpdatedAt: now}); err != nil {
+		t.Fatalf("SaveWorkspace: %v", err)
+	}
+	if err := database.SaveItem(&Item{ID: itemID, WorkspaceID: "ws", DisplayName: itemID, Type: "Notebook", CreatedAt: now, UpdatedAt: now}); err != nil {
+		t.Fatalf("SaveItem: %v", err)
+	}
+	jobs := make([]JobInstance, len(windows))
+	for n, w := range windows {
+		end := last.Add(-time.Duration(w)*window + time.Minute)
+		jobs[n] = JobInstance{ID: fmt.Sprintf("%s-%02d", itemID, w), WorkspaceID: "ws", ItemID: itemID, JobType: "RunNotebook",
+			Status: "Failed", StartTime: end.Add(-time.Minute), EndTime: &end, CreatedAt: now, UpdatedAt: now}
+	}
+	if err := database.SaveJobInstances(jobs); err != nil {
+		t.Fatalf("SaveJobInstances: %v", err)
+	}
+}
+
+// a and c never fail together but are grouped through b; the pair e, f is closer but its group smaller
+func TestGetFailureCorrelations(t *testing.T) {
+	database := newTestDatabase(t)
+	window := 30 * time.Minute
+	last := time.Now().UTC().Truncate(window)
+	seedFailures(t, database, last, window, "a", 1, 2, 3, 4)
+	seedFailures(t, database, last, window, "b", 1, 2, 3, 4, 5, 6, 7, 8)
+	seedFailures(t, database, last, window, "c", 5, 6, 7, 8)
+	seedFailures(t, database, last, window, "d", 1, 10, 20)
+	seedFailures(t, database, last, window, "e", 30, 31, 32)
+	seedFailures(t, database, last, window, "f", 30, 31, 32)
+
+	groups, err := database.GetFailureCorrelations(30, window, 3, 0.3, nil, nil, "")
+	if err != nil {
+		t.Fatalf("GetFailureCorrelations: %v", err)
+	}
+	if len(groups) != 2 {
+		t.Fatalf("got %d groups, want 2", len(groups))
+	}
+
+	chain := groups[0]
+	if len(chain.Items) != 3 || chain.Items[0].ItemID != "a" || chain.Items[1].ItemID != "b" || chain.Items[2].ItemID != "c" ||
+		chain.Items[1].Failures != 8 || chain.Items[1].FailureWindows != 8 {
+		t.Errorf("first group items = %+v, want a, b and c", chain.Items)
+	}
+	want := []CorrelatedPair{{A: "a", B: "b", Shared: 4, Jaccard: 0.5}, {A: "b", B: "c", Shared: 4, Jaccard: 0.5}}
+	if len(chain.Pairs) != 2 || chain.Pairs[0] != want[0] || chain.Pairs[1] != want[1] {
+		t.Errorf("first group pairs = %+v, want %+v", chain.Pairs, want)
+	}
+	if len(chain.SharedWindows) != 8 || !chain.SharedWindows[0].Equal(last.Add(-window)) || !chain.SharedWindows[7].Equal(last.Add(-8*window)) {
+		t.Errorf("first group shared windows = %v, want the 8 windows before %v, latest first", chain.SharedWindows, last)
+	}
+
+	if pair := groups[1]; len(pair.Items) != 2 || len(pair.Pairs) != 1 || pair.Pairs[0].Jaccard != 1 || len(pair.SharedWindows) != 3 {
+		t.Errorf("second group = %+v, want e and f failing together 3 times", pair)
+	}
+}
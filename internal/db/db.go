@@ -2,9 +2,11 @@ package db
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"better-fabric-monitor/internal/logger"
 
@@ -13,8 +15,9 @@ import (
 
 // Database represents the DuckDB connection and operations
 type Database struct {
-	conn *sql.DB
-	path string
+	conn     *sql.DB
+	path     string
+	readOnly bool
 }
 
 // NewDatabase creates or opens a DuckDB database file
@@ -29,7 +32,7 @@ func NewDatabase(path string, encryptionKey string) (*Database, error) {
 	if err != nil {
 		absPath = path // fallback to relative path
 	}
-	fmt.Printf("Initializing DuckDB database at: %s\n", absPath)
+	logger.Info("Initializing DuckDB database", "path", absPath)
 
 	// Ensure directory exists
 	dir := filepath.Dir(path)
@@ -51,6 +54,9 @@ func NewDatabase(path string, encryptionKey string) (*Database, error) {
 	// Test connection
 	if err := conn.Ping(); err != nil {
 		conn.Close()
+		if isDuckDBLockConflict(err) {
+			return nil, fmt.Errorf("%w: %v", ErrDatabaseInUse, err)
+		}
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
@@ -68,15 +74,45 @@ func NewDatabase(path string, encryptionKey string) (*Database, error) {
 	return db, nil
 }
 
+// OpenReadOnlyDatabase opens an existing database without write access
+// The schema is not initialized, so path must be a database this app created, such as the Parquet replica
+func OpenReadOnlyDatabase(path string) (*Database, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("read-only database not found: %w", err)
+	}
+
+	conn, err := sql.Open("duckdb", fmt.Sprintf("%s?access_mode=READ_ONLY", path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	if err := conn.Ping(); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to open database read-only: %w", err)
+	}
+
+	return &Database{
+		conn:     conn,
+		path:     path,
+		readOnly: true,
+	}, nil
+}
+
+// ReadOnly reports whether the database was opened without write access
+func (db *Database) ReadOnly() bool {
+	return db.readOnly
+}
+
 // Close closes the database connection
 func (db *Database) Close() error {
 	if db.conn != nil {
 		// Force a checkpoint to merge WAL into main database file
 		// This ensures all pending writes are flushed and the .wal file is cleaned up
-		_, err := db.conn.Exec("CHECKPOINT")
-		if err != nil {
-			// Log but don't fail - still try to close the connection
-			logger.Log("Warning: failed to checkpoint database before close: %v\n", err)
+		if !db.readOnly {
+			if _, err := db.conn.Exec("CHECKPOINT"); err != nil {
+				// Log but don't fail - still try to close the connection
+				logger.Warn("Failed to checkpoint database before close", logger.Err(err))
+			}
 		}
 		return db.conn.Close()
 	}
@@ -122,7 +158,8 @@ func (db *Database) initSchema() error {
 		root_activity_id VARCHAR,
 		activity_runs JSON,
 		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		failure_details JSON
 	);
 
 	-- Create sequence for sync_metadata id
@@ -169,10 +206,232 @@ func (db *Database) initSchema() error {
 		errors INTEGER DEFAULT 0,
 		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 	);
+
+	-- Per-sync performance metrics
+	CREATE SEQUENCE IF NOT EXISTS sync_metrics_id_seq START 1;
+	CREATE TABLE IF NOT EXISTS sync_metrics (
+		id BIGINT PRIMARY KEY DEFAULT nextval('sync_metrics_id_seq'),
+		started_at TIMESTAMP NOT NULL,
+		duration_ms BIGINT NOT NULL,
+		sync_type VARCHAR NOT NULL,
+		status VARCHAR NOT NULL,
+		error_message VARCHAR,
+		app_version VARCHAR,
+		api_calls INTEGER NOT NULL,
+		retries INTEGER NOT NULL,
+		throttled INTEGER NOT NULL,
+		workspaces INTEGER NOT NULL,
+		workspaces_failed INTEGER NOT NULL,
+		jobs_fetched INTEGER NOT NULL,
+		rows_written INTEGER NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	-- Adaptive polling decisions per workspace
+	CREATE TABLE IF NOT EXISTS workspace_poll_schedule (
+		workspace_id VARCHAR PRIMARY KEY,
+		activity VARCHAR NOT NULL,
+		running_jobs INTEGER NOT NULL,
+		recent_jobs INTEGER NOT NULL,
+		last_job_at TIMESTAMP,
+		interval_seconds INTEGER NOT NULL,
+		last_polled_at TIMESTAMP NOT NULL,
+		next_poll_at TIMESTAMP NOT NULL
+	);
+
+	-- Every notification routed to a channel, including held-back and failed deliveries
+	CREATE SEQUENCE IF NOT EXISTS notification_history_id_seq START 1;
+	CREATE TABLE IF NOT EXISTS notification_history (
+		id BIGINT PRIMARY KEY DEFAULT nextval('notification_history_id_seq'),
+		sent_at TIMESTAMP NOT NULL,
+		rule VARCHAR NOT NULL,
+		channel VARCHAR NOT NULL,
+		event_type VARCHAR NOT NULL,
+		title VARCHAR NOT NULL,
+		message VARCHAR,
+		job_id VARCHAR,
+		item_id VARCHAR,
+		workspace_id VARCHAR,
+		status VARCHAR NOT NULL,
+		error_message VARCHAR
+	);
+
+	-- Items and workspaces whose notifications are muted, until expires_at if set
+	CREATE TABLE IF NOT EXISTS notification_mutes (
+		target_type VARCHAR NOT NULL,
+		target_id VARCHAR NOT NULL,
+		reason VARCHAR,
+		muted_at TIMESTAMP NOT NULL,
+		expires_at TIMESTAMP,
+		PRIMARY KEY (target_type, target_id)
+	);
+
+	-- Items and workspaces starred by the user, so the jobs grid and analytics can be narrowed to them
+	CREATE TABLE IF NOT EXISTS favorites (
+		target_type VARCHAR NOT NULL,
+		target_id VARCHAR NOT NULL,
+		starred_at TIMESTAMP NOT NULL,
+		PRIMARY KEY (target_type, target_id)
+	);
+
+	-- Alerts already raised per job, so a running job is only alerted once per alert type
+	CREATE TABLE IF NOT EXISTS job_alerts (
+		job_id VARCHAR NOT NULL,
+		alert_type VARCHAR NOT NULL,
+		alerted_at TIMESTAMP NOT NULL,
+		PRIMARY KEY (job_id, alert_type)
+	);
+
+	-- Duration anomaly score of each completed run against earlier runs of its item
+	-- score is NULL when the item had too little history to score the run
+	CREATE TABLE IF NOT EXISTS job_anomalies (
+		job_id VARCHAR PRIMARY KEY,
+		item_id VARCHAR NOT NULL,
+		score DOUBLE,
+		method VARCHAR,
+		baseline_ms DOUBLE,
+		samples INTEGER NOT NULL,
+		weekday_aware BOOLEAN NOT NULL,
+		scored_at TIMESTAMP NOT NULL
+	);
+
+	-- Duration of each completed run against the median of the item's last successful runs before it
+	-- baseline_ms and the deltas are NULL when the item had too few earlier runs to compare with
+	CREATE TABLE IF NOT EXISTS job_duration_regressions (
+		job_id VARCHAR PRIMARY KEY,
+		item_id VARCHAR NOT NULL,
+		duration_ms BIGINT NOT NULL,
+		baseline_ms DOUBLE,
+		delta_ms DOUBLE,
+		delta_pct DOUBLE,
+		samples INTEGER NOT NULL,
+		computed_at TIMESTAMP NOT NULL
+	);
+
+	-- Metrics of each Copy activity run, parsed from the activity output stored with its pipeline run
+	-- Data sizes are bytes, throughput is KB/s and the durations are seconds, as Fabric reports them
+	CREATE TABLE IF NOT EXISTS copy_activity_metrics (
+		job_id VARCHAR NOT NULL,
+		activity_run_id VARCHAR NOT NULL,
+		item_id VARCHAR NOT NULL,
+		workspace_id VARCHAR NOT NULL,
+		activity_name VARCHAR NOT NULL,
+		status VARCHAR,
+		start_time TIMESTAMP,
+		duration_ms BIGINT,
+		rows_read BIGINT,
+		rows_written BIGINT,
+		data_read BIGINT,
+		data_written BIGINT,
+		files_read BIGINT,
+		files_written BIGINT,
+		throughput_kbps DOUBLE,
+		copy_duration_s DOUBLE,
+		queuing_duration_s DOUBLE,
+		time_to_first_byte_s DOUBLE,
+		transfer_duration_s DOUBLE,
+		PRIMARY KEY (job_id, activity_run_id)
+	);
+
+	-- Items mapped by hand to the item they are the equivalent of in other environments
+	-- Items of the same logical_name are compared across environments; unmapped items are matched by workspace name
+	CREATE TABLE IF NOT EXISTS environment_mappings (
+		item_id VARCHAR PRIMARY KEY,
+		logical_name VARCHAR NOT NULL,
+		environment VARCHAR NOT NULL,
+		mapped_at TIMESTAMP NOT NULL
+	);
+
+	-- How long after its last successful run an item's data counts as stale, set by hand per item
+	-- Items without a threshold are checked against their inferred cadence
+	CREATE TABLE IF NOT EXISTS freshness_thresholds (
+		item_id VARCHAR PRIMARY KEY,
+		max_age_minutes INTEGER NOT NULL,
+		set_at TIMESTAMP NOT NULL
+	);
+
+	-- Settings overriding the global ones for a workspace; a NULL column keeps the global setting
+	-- notification_rules is a JSON array of the notification rules the workspace's events are routed to
+	CREATE TABLE IF NOT EXISTS workspace_settings (
+		workspace_id VARCHAR PRIMARY KEY,
+		poll_interval_seconds INTEGER,
+		retention_days INTEGER,
+		enrichment_enabled BOOLEAN,
+		notification_rules VARCHAR,
+		updated_at TIMESTAMP NOT NULL
+	);
+
+	-- Content fingerprints of the last Parquet export, per table or job_instances partition
+	CREATE TABLE IF NOT EXISTS parquet_exports (
+		name VARCHAR PRIMARY KEY,
+		fingerprint VARCHAR NOT NULL,
+		exported_at TIMESTAMP NOT NULL
+	);
 	`
 
-	_, err := db.conn.Exec(schema)
-	return err
+	if _, err := db.conn.Exec(schema); err != nil {
+		return err
+	}
+
+	if err := db.migrateSchema(); err != nil {
+		return err
+	}
+	return db.backfillCopyMetrics()
+}
+
+// schemaMigrations add columns introduced after a table was first created, oldest first
+// New columns go at the end of the table so existing files keep the column order the appenders rely on
+var schemaMigrations = []string{
+	`ALTER TABLE job_instances ADD COLUMN IF NOT EXISTS failure_details JSON`,
+	`ALTER TABLE items ADD COLUMN IF NOT EXISTS last_discovered TIMESTAMP`,
+	`ALTER TABLE job_instances ADD COLUMN IF NOT EXISTS removed_upstream_at TIMESTAMP`,
+	`ALTER TABLE workspaces ADD COLUMN IF NOT EXISTS capacity_id VARCHAR`,
+	`ALTER TABLE sync_metrics ADD COLUMN IF NOT EXISTS sync_run_id VARCHAR`,
+}
+
+// SchemaVersion is the number of schema migrations this build applies
+func SchemaVersion() int {
+	return len(schemaMigrations)
+}
+
+// migrateSchema applies schemaMigrations
+func (db *Database) migrateSchema() error {
+	for _, migration := range schemaMigrations {
+		if _, err := db.conn.Exec(migration); err != nil {
+			return fmt.Errorf("migration failed (%s): %w", migration, err)
+		}
+	}
+	return nil
+}
+
+// Resources bounds what DuckDB may use; zero values keep DuckDB's defaults
+type Resources struct {
+	MemoryLimitMB int    // Memory for queries and buffers (DuckDB's default is 80% of RAM)
+	Threads       int    // Threads queries run on (DuckDB's default is one per CPU core)
+	TempDir       string // Where queries larger than the memory limit spill to disk (DuckDB's default is next to the database)
+}
+
+// SetResources applies the limits of r to the database
+// A failing setting does not stop the others from being applied; all failures are returned joined
+func (db *Database) SetResources(r Resources) error {
+	var settings []string
+	if r.MemoryLimitMB > 0 {
+		settings = append(settings, fmt.Sprintf("SET memory_limit = '%dMB'", r.MemoryLimitMB))
+	}
+	if r.Threads > 0 {
+		settings = append(settings, fmt.Sprintf("SET threads = %d", r.Threads))
+	}
+	if r.TempDir != "" {
+		settings = append(settings, fmt.Sprintf("SET temp_directory = '%s'", strings.ReplaceAll(r.TempDir, "'", "''")))
+	}
+
+	var errs []error
+	for _, setting := range settings {
+		if _, err := db.conn.Exec(setting); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", setting, err))
+		}
+	}
+	return errors.Join(errs...)
 }
 
 // GetConnection returns the underlying database connection
@@ -0,0 +1,327 @@
+package db
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Activity types that run another item
+var invokingActivityTypes = map[string]bool{
+	"ExecutePipeline": true,
+	"InvokePipeline":  true,
+	"TridentNotebook": true,
+}
+
+// Paths of the item a copy source or sink points at, for the connection formats pipelines have used
+var copyArtifactPaths = [][]string{
+	{"datasetSettings", "linkedService", "properties", "typeProperties"},
+	{"datasetSettings", "connectionSettings", "properties", "typeProperties"},
+	{"connectionSettings", "properties", "typeProperties"},
+}
+
+// pipelineRun is a pipeline job with its stored activity runs
+type pipelineRun struct {
+	itemID, workspaceID string
+	start               time.Time
+	activities          []ActivityRun
+}
+
+// activityLink is an item an activity of a pipeline run depends on
+type activityLink struct {
+	kind       string
+	itemID     string
+	childRunID string // Job instance the activity started, resolved to an item when it was synced
+	activity   ActivityRun
+}
+
+// GetDependencyGraph derives the item dependency graph from the activity runs of pipeline runs started
+// in the last days, in the given workspaces (all when empty): pipelines invoking pipelines and notebooks,
+// copy activities reading and writing items, and invoked items running one after another
+func (db *Database) GetDependencyGraph(days int, workspaceIDs []string) (DependencyGraph, error) {
+	runs, err := db.getPipelineRuns(days, workspaceIDs)
+	if err != nil {
+		return DependencyGraph{}, err
+	}
+
+	links := make([][]activityLink, len(runs))
+	var childRunIDs []string
+	for i, run := range runs {
+		links[i] = activityLinks(run)
+		for _, link := range links[i] {
+			if link.childRunID != "" {
+				childRunIDs = append(childRunIDs, link.childRunID)
+			}
+		}
+	}
+	childItems, err := db.getJobItemIDs(childRunIDs)
+	if err != nil {
+		return DependencyGraph{}, err
+	}
+
+	edges := make(map[[3]string]*DependencyEdge)
+	var order [][3]string
+	addEdge := func(from, to, kind string, run pipelineRun, activity ActivityRun) {
+		if from == "" || to == "" || from == to {
+			return
+		}
+		key := [3]string{from, to, kind}
+		edge, ok := edges[key]
+		if !ok {
+			edge = &DependencyEdge{From: from, To: to, Kind: kind}
+			edges[key] = edge
+			order = append(order, key)
+		}
+		edge.Runs++
+		if !run.start.Before(edge.LastSeenAt) {
+			edge.LastSeenAt, edge.Activity, edge.LastStatus = run.start, activity.ActivityName, activity.Status
+		}
+	}
+
+	for i, run := range runs {
+		var invoked []activityLink
+		seen := make(map[[3]string]bool) // Count each edge once per run, e.g. for activities inside a ForEach
+		for _, link := range links[i] {
+			if itemID, ok := childItems[link.childRunID]; ok {
+				link.itemID = itemID
+			}
+			from, to := run.itemID, link.itemID
+			if link.kind == DependencyReads {
+				from, to = link.itemID, run.itemID
+			}
+			if key := [3]string{from, to, link.kind}; !seen[key] {
+				seen[key] = true
+				addEdge(from, to, link.kind, run, link.activity)
+			}
+			if link.kind == DependencyInvokes && link.itemID != "" {
+				invoked = append(invoked, link)
+			}
+		}
+		for _, edge := range sequenceEdges(invoked) {
+			if key := [3]string{edge[0].itemID, edge[1].itemID, DependencyPrecedes}; !seen[key] {
+				seen[key] = true
+				addEdge(edge[0].itemID, edge[1].itemID, DependencyPrecedes, run, edge[1].activity)
+			}
+		}
+	}
+
+	graph := DependencyGraph{Nodes: []DependencyNode{}, Edges: make([]DependencyEdge, 0, len(order))}
+	itemIDs := make(map[string]bool)
+	for _, key := range order {
+		graph.Edges = append(graph.Edges, *edges[key])
+		itemIDs[key[0]], itemIDs[key[1]] = true, true
+	}
+	if len(itemIDs) == 0 {
+		return graph, nil
+	}
+
+	ids := make([]string, 0, len(itemIDs))
+	for id := range itemIDs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	graph.Nodes, err = db.getDependencyNodes(ids)
+	return graph, err
+}
+
+// getPipelineRuns loads the pipeline runs started in the last days that have activity runs stored
+func (db *Database) getPipelineRuns(days int, workspaceIDs []string) ([]pipelineRun, error) {
+	query := `
+		SELECT j.item_id, j.workspace_id, j.start_time, CAST(j.activity_runs AS VARCHAR)
+		FROM job_instances j
+		WHERE j.activity_runs IS NOT NULL AND j.start_time IS NOT NULL
+			AND j.start_time >= CURRENT_TIMESTAMP - INTERVAL (? || ' days')
+	`
+	args := []interface{}{fmt.Sprintf("%d", days)}
+	if len(workspaceIDs) > 0 {
+		query += " AND list_contains(?::VARCHAR[], j.workspace_id)"
+		args = append(args, workspaceIDs)
+	}
+	query += " ORDER BY j.start_time"
+
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var runs []pipelineRun
+	for rows.Next() {
+		var run pipelineRun
+		var activityRuns string
+		if err := rows.Scan(&run.itemID, &run.workspaceID, &run.start, &activityRuns); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(activityRuns), &run.activities); err != nil || len(run.activities) == 0 {
+			continue
+		}
+		runs = append(runs, run)
+	}
+	return runs, rows.Err()
+}
+
+// getJobItemIDs maps the given job instance IDs to their items, skipping jobs not synced
+func (db *Database) getJobItemIDs(jobIDs []string) (map[string]string, error) {
+	items := make(map[string]string)
+	if len(jobIDs) == 0 {
+		return items, nil
+	}
+
+	rows, err := db.conn.Query(`
+		SELECT id, item_id FROM job_instances WHERE list_contains(?::VARCHAR[], id)
+	`, jobIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var jobID, itemID string
+		if err := rows.Scan(&jobID, &itemID); err != nil {
+			return nil, err
+		}
+		items[jobID] = itemID
+	}
+	return items, rows.Err()
+}
+
+// getDependencyNodes returns a node for each item ID, with the item's details where it has been synced
+func (db *Database) getDependencyNodes(itemIDs []string) ([]DependencyNode, error) {
+	rows, err := db.conn.Query(`
+		SELECT i.id, i.display_name, COALESCE(i.type, ''), i.workspace_id, COALESCE(w.display_name, i.workspace_id),
+			COALESCE((SELECT arg_max(j.status, j.start_time) FROM job_instances j WHERE j.item_id = i.id), '')
+		FROM items i
+		LEFT JOIN workspaces w ON i.workspace_id = w.id
+		WHERE list_contains(?::VARCHAR[], i.id)
+	`, itemIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	known := make(map[string]DependencyNode)
+	for rows.Next() {
+		var node DependencyNode
+		if err := rows.Scan(&node.ItemID, &node.ItemDisplayName, &node.ItemType,
+			&node.WorkspaceID, &node.WorkspaceName, &node.LastStatus); err != nil {
+			return nil, err
+		}
+		known[node.ItemID] = node
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	nodes := make([]DependencyNode, 0, len(itemIDs))
+	for _, id := range itemIDs {
+		node, ok := known[id]
+		if !ok {
+			// Items in workspaces that aren't monitored are only known by their ID
+			node = DependencyNode{ItemID: id, ItemDisplayName: id}
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes, nil
+}
+
+// activityLinks returns the items the activities of a pipeline run invoke, read or write
+func activityLinks(run pipelineRun) []activityLink {
+	var links []activityLink
+	for _, activity := range run.activities {
+		switch {
+		case invokingActivityTypes[activity.ActivityType]:
+			link := activityLink{kind: DependencyInvokes, activity: activity}
+			if activity.ActivityType == "TridentNotebook" {
+				link.itemID = jsonPath(activity.Input, "notebookId")
+			} else {
+				link.itemID = firstNonEmpty(jsonPath(activity.Input, "pipelineId"),
+					jsonPath(activity.Input, "pipeline", "referenceName"))
+			}
+			link.childRunID = ChildRunID(activity)
+			if link.itemID != "" || link.childRunID != "" {
+				links = append(links, link)
+			}
+		case activity.ActivityType == "Copy":
+			if itemID := copyArtifactID(activity.Input, "source"); itemID != "" {
+				links = append(links, activityLink{kind: DependencyReads, itemID: itemID, activity: activity})
+			}
+			if itemID := copyArtifactID(activity.Input, "sink"); itemID != "" {
+				links = append(links, activityLink{kind: DependencyWrites, itemID: itemID, activity: activity})
+			}
+		}
+	}
+	return links
+}
+
+// ChildRunID returns the job instance a pipeline or notebook activity started, or "" when it isn't reported
+func ChildRunID(activity ActivityRun) string {
+	return firstNonEmpty(jsonPath(activity.Output, "pipelineRunId"), jsonPath(activity.Output, "runId"))
+}
+
+// sequenceEdges pairs each invoked item with the next one started after it ended,
+// which in a pipeline means it ran downstream of it
+func sequenceEdges(invoked []activityLink) [][2]activityLink {
+	type timed struct {
+		link       activityLink
+		start, end time.Time
+	}
+	var runs []timed
+	for _, link := range invoked {
+		start, err := time.Parse(time.RFC3339Nano, link.activity.ActivityRunStart)
+		if err != nil {
+			continue
+		}
+		end, err := time.Parse(time.RFC3339Nano, link.activity.ActivityRunEnd)
+		if err != nil {
+			continue
+		}
+		runs = append(runs, timed{link, start, end})
+	}
+	sort.SliceStable(runs, func(i, j int) bool { return runs[i].start.Before(runs[j].start) })
+
+	var pairs [][2]activityLink
+	for i, run := range runs {
+		for _, next := range runs[i+1:] {
+			if !next.start.Before(run.end) {
+				pairs = append(pairs, [2]activityLink{run.link, next.link})
+				break
+			}
+		}
+	}
+	return pairs
+}
+
+// copyArtifactID returns the item the source or sink of a copy activity's input points at
+func copyArtifactID(input map[string]interface{}, side string) string {
+	for _, path := range copyArtifactPaths {
+		if id := jsonPath(input, append(append([]string{side}, path...), "artifactId")...); id != "" {
+			return id
+		}
+	}
+	return ""
+}
+
+// jsonPath returns the string at path in a decoded JSON object, or "" when there is none
+func jsonPath(value map[string]interface{}, path ...string) string {
+	var current interface{} = value
+	for _, key := range path {
+		object, ok := current.(map[string]interface{})
+		if !ok {
+			return ""
+		}
+		current = object[key]
+	}
+	s, _ := current.(string)
+	return s
+}
+
+// firstNonEmpty returns the first of values that isn't empty
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
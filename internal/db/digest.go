@@ -0,0 +1,144 @@
+package db
+
+import (
+	"database/sql"
+	"time"
+)
+
+// GetDigest summarizes the runs started in [from, to): overall stats, items that newly failed,
+// the limit slowest items and up to limit runs that took longer than slaThreshold
+func (db *Database) GetDigest(from, to time.Time, slaThreshold time.Duration, limit int) (*Digest, error) {
+	digest := &Digest{
+		From:           from,
+		To:             to,
+		SLAThresholdMs: slaThreshold.Milliseconds(),
+		NewFailures:    []DigestFailure{},
+		SlowestItems:   []DigestItem{},
+		SLABreaches:    []DigestBreach{},
+	}
+
+	var avgDuration sql.NullFloat64
+	err := db.conn.QueryRow(`
+		SELECT
+			COUNT(*),
+			COALESCE(SUM(CASE WHEN status = 'Completed' THEN 1 ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN status = 'Failed' THEN 1 ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN status IN ('InProgress', 'Running', 'NotStarted') THEN 1 ELSE 0 END), 0),
+			AVG(CASE WHEN status = 'Completed' AND duration_ms IS NOT NULL THEN duration_ms ELSE NULL END)
+		FROM job_instances
+		WHERE start_time >= ? AND start_time < ?
+	`, from, to).Scan(&digest.Stats.TotalJobs, &digest.Stats.Successful, &digest.Stats.Failed, &digest.Stats.Running, &avgDuration)
+	if err != nil {
+		return nil, err
+	}
+	if avgDuration.Valid {
+		digest.Stats.AvgDurationMs = avgDuration.Float64
+	}
+	if digest.Stats.TotalJobs > 0 {
+		digest.Stats.SuccessRate = float64(digest.Stats.Successful) / float64(digest.Stats.TotalJobs) * 100
+	}
+
+	if digest.NewFailures, err = db.getDigestNewFailures(from, to); err != nil {
+		return nil, err
+	}
+	if digest.SlowestItems, err = db.getDigestSlowestItems(from, to, limit); err != nil {
+		return nil, err
+	}
+	if slaThreshold > 0 {
+		if digest.SLABreaches, err = db.getDigestBreaches(from, to, slaThreshold, limit); err != nil {
+			return nil, err
+		}
+	}
+	return digest, nil
+}
+
+// getDigestNewFailures returns the items with failed runs in [from, to) that did not fail in the 30 days before from
+func (db *Database) getDigestNewFailures(from, to time.Time) ([]DigestFailure, error) {
+	rows, err := db.conn.Query(`
+		SELECT j.item_id, COALESCE(i.display_name, j.item_id), COALESCE(w.display_name, j.workspace_id),
+			COUNT(*), COALESCE(arg_max(j.failure_reason, j.start_time), '')
+		FROM job_instances j
+		LEFT JOIN items i ON j.item_id = i.id
+		LEFT JOIN workspaces w ON j.workspace_id = w.id
+		WHERE j.status = 'Failed' AND j.start_time >= ? AND j.start_time < ?
+			AND NOT EXISTS (
+				SELECT 1 FROM job_instances p
+				WHERE p.item_id = j.item_id AND p.status = 'Failed'
+					AND p.start_time >= ? - INTERVAL 30 DAYS AND p.start_time < ?
+			)
+		GROUP BY j.item_id, i.display_name, w.display_name, j.workspace_id
+		ORDER BY COUNT(*) DESC, 2
+	`, from, to, from, from)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	failures := []DigestFailure{}
+	for rows.Next() {
+		var f DigestFailure
+		if err := rows.Scan(&f.ItemID, &f.ItemDisplayName, &f.WorkspaceName, &f.Failures, &f.LastReason); err != nil {
+			return nil, err
+		}
+		failures = append(failures, f)
+	}
+	return failures, rows.Err()
+}
+
+// getDigestSlowestItems returns the limit items with the longest average completed run in [from, to)
+func (db *Database) getDigestSlowestItems(from, to time.Time, limit int) ([]DigestItem, error) {
+	rows, err := db.conn.Query(`
+		SELECT j.item_id, COALESCE(i.display_name, j.item_id), COALESCE(w.display_name, j.workspace_id),
+			COUNT(*), AVG(j.duration_ms), MAX(j.duration_ms)
+		FROM job_instances j
+		LEFT JOIN items i ON j.item_id = i.id
+		LEFT JOIN workspaces w ON j.workspace_id = w.id
+		WHERE j.status = 'Completed' AND j.duration_ms IS NOT NULL
+			AND j.start_time >= ? AND j.start_time < ?
+		GROUP BY j.item_id, i.display_name, w.display_name, j.workspace_id
+		ORDER BY AVG(j.duration_ms) DESC
+		LIMIT ?
+	`, from, to, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []DigestItem{}
+	for rows.Next() {
+		var item DigestItem
+		if err := rows.Scan(&item.ItemID, &item.ItemDisplayName, &item.WorkspaceName, &item.Runs, &item.AvgDurationMs, &item.MaxDurationMs); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// getDigestBreaches returns up to limit finished runs started in [from, to) that took longer than threshold, longest first
+func (db *Database) getDigestBreaches(from, to time.Time, threshold time.Duration, limit int) ([]DigestBreach, error) {
+	rows, err := db.conn.Query(`
+		SELECT j.id, COALESCE(i.display_name, j.item_id), COALESCE(w.display_name, j.workspace_id),
+			j.status, j.start_time, j.duration_ms
+		FROM job_instances j
+		LEFT JOIN items i ON j.item_id = i.id
+		LEFT JOIN workspaces w ON j.workspace_id = w.id
+		WHERE j.duration_ms > ? AND j.start_time >= ? AND j.start_time < ?
+		ORDER BY j.duration_ms DESC
+		LIMIT ?
+	`, threshold.Milliseconds(), from, to, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	breaches := []DigestBreach{}
+	for rows.Next() {
+		var b DigestBreach
+		if err := rows.Scan(&b.JobID, &b.ItemDisplayName, &b.WorkspaceName, &b.Status, &b.StartTime, &b.DurationMs); err != nil {
+			return nil, err
+		}
+		breaches = append(breaches, b)
+	}
+	return breaches, rows.Err()
+}
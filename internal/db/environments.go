@@ -0,0 +1,103 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// SaveEnvironmentMapping maps an item to a logical item in an environment, replacing any earlier mapping of it
+func (db *Database) SaveEnvironmentMapping(m *EnvironmentMapping) error {
+	query := `
+		INSERT INTO environment_mappings (item_id, logical_name, environment, mapped_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT (item_id) DO UPDATE SET
+			logical_name = EXCLUDED.logical_name,
+			environment = EXCLUDED.environment,
+			mapped_at = EXCLUDED.mapped_at
+	`
+	_, err := db.conn.Exec(query, m.ItemID, m.LogicalName, m.Environment, m.MappedAt)
+	return err
+}
+
+// DeleteEnvironmentMapping removes the mapping of an item, matching it by workspace name again
+func (db *Database) DeleteEnvironmentMapping(itemID string) error {
+	_, err := db.conn.Exec(`DELETE FROM environment_mappings WHERE item_id = ?`, itemID)
+	return err
+}
+
+// GetEnvironmentMappings returns the items mapped by hand, by logical name and environment
+func (db *Database) GetEnvironmentMappings() ([]EnvironmentMapping, error) {
+	query := `
+		SELECT m.item_id, COALESCE(i.display_name, m.item_id), COALESCE(i.workspace_id, ''),
+			COALESCE(w.display_name, i.workspace_id, ''), m.logical_name, m.environment, m.mapped_at
+		FROM environment_mappings m
+		LEFT JOIN items i ON m.item_id = i.id
+		LEFT JOIN workspaces w ON i.workspace_id = w.id
+		ORDER BY m.logical_name, m.environment, m.item_id
+	`
+
+	rows, err := db.conn.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var mappings []EnvironmentMapping
+	for rows.Next() {
+		var m EnvironmentMapping
+		if err := rows.Scan(&m.ItemID, &m.ItemDisplayName, &m.WorkspaceID, &m.WorkspaceName,
+			&m.LogicalName, &m.Environment, &m.MappedAt); err != nil {
+			return nil, err
+		}
+		mappings = append(mappings, m)
+	}
+	return mappings, rows.Err()
+}
+
+// GetEnvironmentItemStats returns the success rate and durations of the runs of each item started in the last
+// days, with the item's environment mapping when it has one
+func (db *Database) GetEnvironmentItemStats(days int) ([]EnvironmentItemStats, error) {
+	query := `
+		SELECT j.item_id, COALESCE(i.display_name, j.item_id), COALESCE(i.type, ''),
+			j.workspace_id, COALESCE(w.display_name, j.workspace_id),
+			m.logical_name, m.environment,
+			COUNT(*),
+			COUNT(*) FILTER (WHERE j.status = 'Completed'),
+			COUNT(*) FILTER (WHERE j.status = 'Failed'),
+			AVG(j.duration_ms) FILTER (WHERE j.status = 'Completed'),
+			quantile_cont(j.duration_ms, 0.9) FILTER (WHERE j.status = 'Completed')
+		FROM job_instances j
+		LEFT JOIN items i ON j.item_id = i.id
+		LEFT JOIN workspaces w ON j.workspace_id = w.id
+		LEFT JOIN environment_mappings m ON j.item_id = m.item_id
+		WHERE j.start_time >= CURRENT_TIMESTAMP - INTERVAL (? || ' days')
+		GROUP BY j.item_id, i.display_name, i.type, j.workspace_id, w.display_name, m.logical_name, m.environment
+		ORDER BY COALESCE(i.display_name, j.item_id), j.item_id
+	`
+
+	rows, err := db.conn.Query(query, fmt.Sprintf("%d", days))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stats []EnvironmentItemStats
+	for rows.Next() {
+		var s EnvironmentItemStats
+		var logicalName, environment sql.NullString
+		var avg, p90 sql.NullFloat64
+		if err := rows.Scan(&s.ItemID, &s.ItemDisplayName, &s.ItemType, &s.WorkspaceID, &s.WorkspaceName,
+			&logicalName, &environment, &s.Runs, &s.Succeeded, &s.Failed, &avg, &p90); err != nil {
+			return nil, err
+		}
+		if logicalName.Valid {
+			s.LogicalName, s.Environment = &logicalName.String, &environment.String
+		}
+		s.AvgDurationMs, s.P90DurationMs = avg.Float64, p90.Float64
+		if finished := s.Succeeded + s.Failed; finished > 0 {
+			s.SuccessRate = float64(s.Succeeded) / float64(finished) * 100
+		}
+		stats = append(stats, s)
+	}
+	return stats, rows.Err()
+}
@@ -0,0 +1,190 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"time"
+)
+
+// errorCodesItemNames caps the item names listed per error code
+const errorCodesItemNames = 5
+
+// embeddedErrorCode matches the more specific code services embed in messages, e.g. "ErrorCode=SqlOperationFailed"
+var embeddedErrorCode = regexp.MustCompile(`ErrorCode=([A-Za-z0-9_.]+)`)
+
+// errorCodeOccurrence is an error code seen in a run
+type errorCodeOccurrence struct {
+	code, message string
+	activity      bool
+}
+
+// GetTopErrorCodes aggregates the error codes of failed runs and failed pipeline activities of runs started
+// in the last days, most frequent first
+// Each failure counts under its most specific code: an embedded ErrorCode= in the message, else the
+// innermost code of the failure payload
+func (db *Database) GetTopErrorCodes(days int, limit int, workspaceIDs []string, itemTypes []string, itemNameSearch string, favoritesOnly bool) ([]ErrorCodeStats, error) {
+	filterClause, filterArgs := buildFilterConditions(workspaceIDs, itemTypes, itemNameSearch)
+	filterClause += favoritesCondition(favoritesOnly)
+	query := fmt.Sprintf(`
+		SELECT j.item_id, COALESCE(i.display_name, j.item_id), j.start_time, j.status, j.failure_reason,
+			CAST(j.failure_details AS VARCHAR), CAST(j.activity_runs AS VARCHAR)
+		FROM job_instances j
+		LEFT JOIN items i ON j.item_id = i.id
+		WHERE j.start_time >= CURRENT_TIMESTAMP - INTERVAL (? || ' days')
+			AND (j.status = 'Failed' OR CAST(j.activity_runs AS VARCHAR) LIKE '%%"status":"Failed"%%')
+			%s
+		ORDER BY j.start_time
+	`, filterClause)
+
+	rows, err := db.conn.Query(query, append([]interface{}{fmt.Sprintf("%d", days)}, filterArgs...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	byCode := make(map[string]*ErrorCodeStats)
+	itemRuns := make(map[string]map[string]int) // Occurrences per item name of each code
+	since := time.Now().Add(-24 * time.Hour)
+	for rows.Next() {
+		var itemID, itemName, status string
+		var start time.Time
+		var reason, details, activityRuns sql.NullString
+		if err := rows.Scan(&itemID, &itemName, &start, &status, &reason, &details, &activityRuns); err != nil {
+			return nil, err
+		}
+
+		seen := make(map[string]bool)
+		for _, occurrence := range runErrorCodes(status, reason.String, details.String, activityRuns.String) {
+			stats, ok := byCode[occurrence.code]
+			if !ok {
+				stats = &ErrorCodeStats{ErrorCode: occurrence.code, FirstSeen: start}
+				byCode[occurrence.code] = stats
+				itemRuns[occurrence.code] = make(map[string]int)
+			}
+			if occurrence.activity {
+				stats.ActivityErrors++
+			} else {
+				stats.JobFailures++
+			}
+			stats.LastSeen = start
+			if occurrence.message != "" {
+				stats.SampleMessage = occurrence.message
+			}
+			if !seen[occurrence.code] {
+				seen[occurrence.code] = true
+				stats.Occurrences++
+				itemRuns[occurrence.code][itemName]++
+				if since.Before(start) {
+					stats.LastDay++
+				}
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	result := make([]ErrorCodeStats, 0, len(byCode))
+	for code, stats := range byCode {
+		stats.AffectedItems = len(itemRuns[code])
+		stats.Items = mostFrequent(itemRuns[code], errorCodesItemNames)
+		result = append(result, *stats)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Occurrences != result[j].Occurrences {
+			return result[i].Occurrences > result[j].Occurrences
+		}
+		return result[i].ErrorCode < result[j].ErrorCode
+	})
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+// runErrorCodes extracts the error code of a failed run and of each failed activity in it
+func runErrorCodes(status, reason, details, activityRuns string) []errorCodeOccurrence {
+	var occurrences []errorCodeOccurrence
+	if status == "Failed" {
+		var payload map[string]interface{}
+		code, message := "", reason
+		if json.Unmarshal([]byte(details), &payload) == nil {
+			code, message = innermostErrorCode(payload)
+			if message == "" {
+				message = reason
+			}
+		}
+		if embedded := embeddedErrorCode.FindStringSubmatch(message); embedded != nil {
+			code = embedded[1]
+		} else if embedded := embeddedErrorCode.FindStringSubmatch(reason); embedded != nil {
+			code = embedded[1]
+		}
+		if code != "" {
+			occurrences = append(occurrences, errorCodeOccurrence{code: code, message: message})
+		}
+	}
+
+	var activities []ActivityRun
+	if activityRuns == "" || json.Unmarshal([]byte(activityRuns), &activities) != nil {
+		return occurrences
+	}
+	for _, activity := range activities {
+		if activity.Status != "Failed" {
+			continue
+		}
+		code := activity.Error.ErrorCode
+		if embedded := embeddedErrorCode.FindStringSubmatch(activity.Error.Message); embedded != nil {
+			code = embedded[1]
+		}
+		if code != "" {
+			occurrences = append(occurrences, errorCodeOccurrence{code: code, message: activity.Error.Message, activity: true})
+		}
+	}
+	return occurrences
+}
+
+// innermostErrorCode returns the deepest error code in a failure payload and its message
+// Fabric nests the specific cause under details, moreDetails, error or innerError
+func innermostErrorCode(payload map[string]interface{}) (code, message string) {
+	code, _ = payload["errorCode"].(string)
+	if code == "" {
+		code, _ = payload["code"].(string)
+	}
+	message, _ = payload["message"].(string)
+
+	for _, key := range []string{"details", "moreDetails", "error", "innerError"} {
+		var nested []interface{}
+		switch v := payload[key].(type) {
+		case []interface{}:
+			nested = v
+		case map[string]interface{}:
+			nested = []interface{}{v}
+		}
+		for _, entry := range nested {
+			if object, ok := entry.(map[string]interface{}); ok {
+				if innerCode, innerMessage := innermostErrorCode(object); innerCode != "" {
+					return innerCode, firstNonEmpty(innerMessage, message)
+				}
+			}
+		}
+	}
+	return code, message
+}
+
+// mostFrequent returns up to n keys of counts, highest count first
+func mostFrequent(counts map[string]int, n int) []string {
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	return keys[:min(n, len(keys))]
+}
@@ -0,0 +1,76 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"better-fabric-monitor/internal/logger"
+)
+
+// Formats accepted by ExportTables
+const (
+	ExportFormatParquet = "parquet"
+	ExportFormatCSV     = "csv"
+)
+
+// exportCopyOptions maps an export format to its COPY options
+var exportCopyOptions = map[string]string{
+	ExportFormatParquet: "FORMAT PARQUET",
+	ExportFormatCSV:     "FORMAT CSV, HEADER",
+}
+
+// ExportTables writes every mirrored table to <dir>/<table>.<format>
+// Unlike ExportTablesToParquet every table is written in full and the replica's export fingerprints are left alone
+// Tables not yet started when ctx is cancelled are skipped and ctx's error is returned
+func (db *Database) ExportTables(ctx context.Context, dir, format string) ([]ParquetExportStats, error) {
+	options, ok := exportCopyOptions[format]
+	if !ok {
+		return nil, fmt.Errorf("unsupported export format %q", format)
+	}
+
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get absolute export path: %w", err)
+	}
+	if err := os.MkdirAll(absDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create export directory: %w", err)
+	}
+
+	stats := make([]ParquetExportStats, 0, len(parquetTables))
+	for _, tableName := range parquetTables {
+		if err := ctx.Err(); err != nil {
+			return stats, err
+		}
+
+		start := time.Now()
+		stat := ParquetExportStats{TableName: tableName}
+		file := filepath.Join(absDir, fmt.Sprintf("%s.%s", tableName, format))
+
+		if err := db.conn.QueryRowContext(ctx, fmt.Sprintf("SELECT COUNT(*) FROM %s", tableName)).Scan(&stat.RecordCount); err != nil {
+			stat.ErrorMessage = fmt.Sprintf("failed to count rows: %v", err)
+		} else if err := db.copyToFile(fmt.Sprintf("SELECT * FROM %s", tableName), file, options); err != nil {
+			stat.ErrorMessage = fmt.Sprintf("failed to export: %v", err)
+		} else {
+			stat.Success = true
+		}
+		stat.DurationMs = time.Since(start).Milliseconds()
+
+		if stat.Success {
+			logger.Info("[EXPORT] Exported table", "table", tableName, "records", stat.RecordCount, "durationMs", stat.DurationMs)
+		} else {
+			logger.Error("[EXPORT] Table export failed", "table", tableName, "error", stat.ErrorMessage)
+		}
+		stats = append(stats, stat)
+	}
+
+	return stats, nil
+}
+
+// escapeSQLString doubles single quotes so a value can sit inside a SQL string literal
+func escapeSQLString(s string) string {
+	return strings.ReplaceAll(s, "'", "''")
+}
@@ -0,0 +1,60 @@
+package db
+
+// favoriteJobCondition keeps the jobs of starred items and of every item in starred workspaces
+const favoriteJobCondition = `(
+	j.item_id IN (SELECT target_id FROM favorites WHERE target_type = 'item')
+	OR j.workspace_id IN (SELECT target_id FROM favorites WHERE target_type = 'workspace')
+)`
+
+// favoritesCondition returns the filter clause narrowing jobs to favorites when favoritesOnly is set,
+// to be appended like the clause of buildFilterConditions
+func favoritesCondition(favoritesOnly bool) string {
+	if !favoritesOnly {
+		return ""
+	}
+	return " AND " + favoriteJobCondition
+}
+
+// SaveFavorite stars a target; starring it again keeps when it was first starred
+func (db *Database) SaveFavorite(f *Favorite) error {
+	query := `
+		INSERT INTO favorites (target_type, target_id, starred_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT (target_type, target_id) DO NOTHING
+	`
+	_, err := db.conn.Exec(query, f.TargetType, f.TargetID, f.StarredAt)
+	return err
+}
+
+// DeleteFavorite unstars a target
+func (db *Database) DeleteFavorite(targetType, targetID string) error {
+	_, err := db.conn.Exec(`DELETE FROM favorites WHERE target_type = ? AND target_id = ?`, targetType, targetID)
+	return err
+}
+
+// GetFavorites returns the starred workspaces and items, workspaces first, by name
+func (db *Database) GetFavorites() ([]Favorite, error) {
+	query := `
+		SELECT f.target_type, f.target_id, COALESCE(i.display_name, w.display_name, f.target_id), f.starred_at
+		FROM favorites f
+		LEFT JOIN items i ON f.target_type = 'item' AND i.id = f.target_id
+		LEFT JOIN workspaces w ON f.target_type = 'workspace' AND w.id = f.target_id
+		ORDER BY f.target_type DESC, LOWER(COALESCE(i.display_name, w.display_name, f.target_id)), f.target_id
+	`
+
+	rows, err := db.conn.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var favorites []Favorite
+	for rows.Next() {
+		var f Favorite
+		if err := rows.Scan(&f.TargetType, &f.TargetID, &f.TargetName, &f.StarredAt); err != nil {
+			return nil, err
+		}
+		favorites = append(favorites, f)
+	}
+	return favorites, rows.Err()
+}
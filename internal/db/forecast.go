@@ -0,0 +1,124 @@
+package db
+
+import "fmt"
+
+// Duration forecasts
+const (
+	forecastMinRuns = 5   // Completed runs an item needs before its next run is forecast
+	levelSmoothing  = 0.3 // Weight of the newest run in the smoothed duration
+	trendSmoothing  = 0.1 // Weight of the newest change in the smoothed trend
+)
+
+// GetDurationForecasts forecasts the duration of each item's next run from up to perItem of its latest completed
+// runs, limited to itemIDs when given, with Holt's linear exponential smoothing: an EWMA of the duration plus an
+// EWMA of its change per run
+// Items with fewer than 5 completed runs are left out
+func (db *Database) GetDurationForecasts(perItem int, itemIDs []string, workspaceIDs []string, itemTypes []string, itemNameSearch string) ([]DurationForecast, error) {
+	filterClause, filterArgs := buildFilterConditions(workspaceIDs, itemTypes, itemNameSearch)
+	if len(itemIDs) > 0 {
+		filterClause += " AND list_contains(?::VARCHAR[], j.item_id)"
+		filterArgs = append(filterArgs, itemIDs)
+	}
+	// The recursion steps from each item's oldest run to its latest; it starts flat, since a single noisy
+	// difference would otherwise bias the trend for many runs
+	query := fmt.Sprintf(`
+		WITH RECURSIVE latest AS (
+			SELECT j.item_id, j.workspace_id, CAST(j.duration_ms AS DOUBLE) AS duration_ms,
+				ROW_NUMBER() OVER (PARTITION BY j.item_id ORDER BY j.start_time DESC, j.id DESC) AS recency,
+				LEAST(COUNT(*) OVER (PARTITION BY j.item_id), ?) AS samples
+			FROM job_instances j
+			LEFT JOIN items i ON j.item_id = i.id
+			WHERE j.status = 'Completed' AND j.duration_ms IS NOT NULL AND j.start_time IS NOT NULL
+			%[1]s
+		),
+		smoothed AS (
+			SELECT item_id, workspace_id, recency, samples,
+				duration_ms AS level, CAST(0 AS DOUBLE) AS trend, CAST(NULL AS DOUBLE) AS error_ms
+			FROM latest
+			WHERE recency = samples AND samples >= %[2]d
+			UNION ALL
+			SELECT s.item_id, s.workspace_id, l.recency, s.samples,
+				%[3]f * l.duration_ms + (1 - %[3]f) * (s.level + s.trend),
+				%[4]f * (%[3]f * l.duration_ms + (1 - %[3]f) * (s.level + s.trend) - s.level) + (1 - %[4]f) * s.trend,
+				COALESCE(%[3]f * abs(l.duration_ms - s.level - s.trend) + (1 - %[3]f) * s.error_ms,
+					abs(l.duration_ms - s.level - s.trend))
+			FROM smoothed s
+			JOIN latest l ON l.item_id = s.item_id AND l.recency = s.recency - 1
+		)
+		SELECT s.item_id, COALESCE(i.display_name, s.item_id), COALESCE(i.type, ''),
+			s.workspace_id, COALESCE(w.display_name, s.workspace_id),
+			expected_ms, GREATEST(expected_ms - s.error_ms, 0), expected_ms + s.error_ms, s.level, s.trend, s.samples
+		FROM (SELECT *, GREATEST(level + trend, 0) AS expected_ms FROM smoothed WHERE recency = 1) s
+		LEFT JOIN items i ON s.item_id = i.id
+		LEFT JOIN workspaces w ON s.workspace_id = w.id
+		ORDER BY s.item_id
+	`, filterClause, forecastMinRuns, levelSmoothing, trendSmoothing)
+
+	rows, err := db.conn.Query(query, append([]interface{}{perItem}, filterArgs...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var forecasts []DurationForecast
+	for rows.Next() {
+		var f DurationForecast
+		if err := rows.Scan(&f.ItemID, &f.ItemDisplayName, &f.ItemType, &f.WorkspaceID, &f.WorkspaceName,
+			&f.ExpectedMs, &f.LowMs, &f.HighMs, &f.EWMAMs, &f.TrendMsPerRun, &f.Samples); err != nil {
+			return nil, err
+		}
+		forecasts = append(forecasts, f)
+	}
+	return forecasts, rows.Err()
+}
+
+// GetActivityRemainders returns, for each activity of the pipelines itemIDs, the median time up to perItem latest
+// completed runs of its pipeline went on after the activity finished, for predicting when a run in progress ends
+// An activity run several times in a run, as in a loop, counts from its last end; activities that succeeded in
+// fewer than 5 of the runs are left out
+func (db *Database) GetActivityRemainders(perItem int, itemIDs []string) ([]ActivityRemainder, error) {
+	query := fmt.Sprintf(`
+		WITH latest AS (
+			SELECT j.id, j.item_id, j.end_time, j.activity_runs,
+				ROW_NUMBER() OVER (PARTITION BY j.item_id ORDER BY j.start_time DESC) AS recency
+			FROM job_instances j
+			WHERE j.status = 'Completed' AND j.end_time IS NOT NULL AND j.activity_runs IS NOT NULL
+				AND list_contains(?::VARCHAR[], j.item_id)
+		),
+		activities AS (
+			SELECT id, item_id, end_time, unnest(CAST(activity_runs AS JSON[])) AS activity
+			FROM latest
+			WHERE recency <= ?
+		),
+		remainders AS (
+			SELECT item_id, json_extract_string(activity, '$.activityName') AS activity_name,
+				GREATEST(date_diff('millisecond',
+					MAX(TRY_CAST(json_extract_string(activity, '$.activityRunEnd') AS TIMESTAMP)), ANY_VALUE(end_time)), 0) AS remaining_ms
+			FROM activities
+			WHERE json_extract_string(activity, '$.status') = 'Succeeded'
+			GROUP BY id, item_id, activity_name
+			HAVING activity_name IS NOT NULL AND MAX(TRY_CAST(json_extract_string(activity, '$.activityRunEnd') AS TIMESTAMP)) IS NOT NULL
+		)
+		SELECT item_id, activity_name, median(CAST(remaining_ms AS DOUBLE))
+		FROM remainders
+		GROUP BY item_id, activity_name
+		HAVING COUNT(*) >= %d
+		ORDER BY item_id, activity_name
+	`, forecastMinRuns)
+
+	rows, err := db.conn.Query(query, itemIDs, perItem)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var remainders []ActivityRemainder
+	for rows.Next() {
+		var r ActivityRemainder
+		if err := rows.Scan(&r.ItemID, &r.ActivityName, &r.RemainingMs); err != nil {
+			return nil, err
+		}
+		remainders = append(remainders, r)
+	}
+	return remainders, rows.Err()
+}
@@ -0,0 +1,61 @@
+package db
+
+import (
+	"testing"
+	"time"
+)
+
+// Only the latest runs are smoothed, and items with too few runs get no forecast
+func TestGetDurationForecasts(t *testing.T) {
+	database := newTestDatabase(t)
+	first := time.Now().UTC().Truncate(time.Hour).Add(-24 * time.Hour)
+	seedDurations(t, database, "steady", first, time.Hour, 1000, 1000, 1000, 60000, 60000, 60000, 60000, 60000)
+	seedDurations(t, database, "new", first, time.Hour, 1000, 2000, 3000, 4000)
+
+	forecasts, err := database.GetDurationForecasts(5, nil, nil, nil, "")
+	if err != nil {
+		t.Fatalf("GetDurationForecasts: %v", err)
+	}
+	if len(forecasts) != 1 {
+		t.Fatalf("got %d forecasts, want only the item with 5 runs", len(forecasts))
+	}
+	f := forecasts[0]
+	if f.ItemID != "steady" || f.ExpectedMs != 60000 || f.LowMs != 60000 || f.HighMs != 60000 || f.TrendMsPerRun != 0 || f.Samples != 5 {
+		t.Errorf("forecast = %+v, want 60000ms from the latest 5 runs", f)
+	}
+
+	if forecasts, err := database.GetDurationForecasts(50, []string{"steady"}, nil, nil, ""); err != nil || len(forecasts) != 1 || forecasts[0].Samples != 8 {
+		t.Errorf("forecasts of steady = %+v, %v; want one from all 8 runs", forecasts, err)
+	}
+}
+
+// A pipeline's copy activity left it running 10, 20, ... 60 seconds, a median of 35; its wait ran in too few runs
+func TestGetActivityRemainders(t *testing.T) {
+	database := newTestDatabase(t)
+	first := time.Now().UTC().Truncate(time.Hour).Add(-24 * time.Hour)
+	seedDurations(t, database, "pipeline", first, time.Hour, 60000, 60000, 60000, 60000, 60000, 60000)
+
+	jobs, err := database.GetJobInstances(JobFilter{})
+	if err != nil {
+		t.Fatalf("GetJobInstances: %v", err)
+	}
+	for n := range jobs {
+		job := &jobs[n]
+		remaining := time.Duration(10*(int(job.StartTime.Sub(first)/time.Hour)+1)) * time.Second
+		job.ActivityRuns = []ActivityRun{{ActivityName: "Copy", Status: "Succeeded", ActivityRunEnd: job.EndTime.Add(-remaining).Format(time.RFC3339)}}
+		if n < 2 {
+			job.ActivityRuns = append(job.ActivityRuns, ActivityRun{ActivityName: "Wait", Status: "Succeeded", ActivityRunEnd: job.EndTime.Format(time.RFC3339)})
+		}
+	}
+	if err := database.SaveJobInstances(jobs); err != nil {
+		t.Fatalf("SaveJobInstances: %v", err)
+	}
+
+	remainders, err := database.GetActivityRemainders(50, []string{"pipeline"})
+	if err != nil {
+		t.Fatalf("GetActivityRemainders: %v", err)
+	}
+	if len(remainders) != 1 || remainders[0].ActivityName != "Copy" || remainders[0].RemainingMs != 35000 {
+		t.Errorf("remainders = %+v, want Copy with a median of 35000ms", remainders)
+	}
+}
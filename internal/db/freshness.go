@@ -0,0 +1,63 @@
+package db
+
+import (
+	"fmt"
+	"time"
+)
+
+// SaveFreshnessThreshold sets how many minutes after its last successful run an item's data counts as stale
+func (db *Database) SaveFreshnessThreshold(itemID string, maxAgeMinutes int, setAt time.Time) error {
+	query := `
+		INSERT INTO freshness_thresholds (item_id, max_age_minutes, set_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT (item_id) DO UPDATE SET
+			max_age_minutes = EXCLUDED.max_age_minutes,
+			set_at = EXCLUDED.set_at
+	`
+	_, err := db.conn.Exec(query, itemID, maxAgeMinutes, setAt)
+	return err
+}
+
+// DeleteFreshnessThreshold removes the threshold of an item, checking it against its cadence again
+func (db *Database) DeleteFreshnessThreshold(itemID string) error {
+	_, err := db.conn.Exec(`DELETE FROM freshness_thresholds WHERE item_id = ?`, itemID)
+	return err
+}
+
+// GetItemFreshness returns the latest run and latest successful run of every item that ran, whenever it ran,
+// with the freshness threshold set for it
+func (db *Database) GetItemFreshness(workspaceIDs []string, itemTypes []string, itemNameSearch string) ([]ItemFreshness, error) {
+	filterClause, filterArgs := buildFilterConditions(workspaceIDs, itemTypes, itemNameSearch)
+	query := fmt.Sprintf(`
+		SELECT j.item_id, COALESCE(i.display_name, j.item_id), COALESCE(i.type, ''),
+			j.workspace_id, COALESCE(w.display_name, j.workspace_id),
+			MAX(j.start_time), arg_max(j.status, j.start_time),
+			MAX(COALESCE(j.end_time, j.start_time)) FILTER (WHERE j.status = 'Completed'),
+			ANY_VALUE(f.max_age_minutes)
+		FROM job_instances j
+		LEFT JOIN items i ON j.item_id = i.id
+		LEFT JOIN workspaces w ON j.workspace_id = w.id
+		LEFT JOIN freshness_thresholds f ON f.item_id = j.item_id
+		WHERE j.start_time IS NOT NULL
+			%s
+		GROUP BY j.item_id, i.display_name, i.type, j.workspace_id, w.display_name
+		ORDER BY COALESCE(i.display_name, j.item_id), j.item_id
+	`, filterClause)
+
+	rows, err := db.conn.Query(query, filterArgs...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []ItemFreshness
+	for rows.Next() {
+		var f ItemFreshness
+		if err := rows.Scan(&f.ItemID, &f.ItemDisplayName, &f.ItemType, &f.WorkspaceID, &f.WorkspaceName,
+			&f.LastRunAt, &f.LastRunStatus, &f.LastSuccessAt, &f.MaxAgeMinutes); err != nil {
+			return nil, err
+		}
+		items = append(items, f)
+	}
+	return items, rows.Err()
+}
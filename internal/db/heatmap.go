@@ -0,0 +1,65 @@
+package db
+
+import (
+	"fmt"
+	"time"
+)
+
+// GetRunHeatmap counts the runs started in the last days and their failures by weekday and hour in loc,
+// returning all 168 cells, Sunday midnight first
+// Runs are grouped by UTC hour in the database and moved to loc here, so DST changes fall in the right hour
+func (db *Database) GetRunHeatmap(days int, loc *time.Location, workspaceIDs []string, itemTypes []string, itemNameSearch string) ([]HeatmapCell, error) {
+	filterClause, filterArgs := buildFilterConditions(workspaceIDs, itemTypes, itemNameSearch)
+	query := fmt.Sprintf(`
+		SELECT DATE_TRUNC('hour', j.start_time) AS hour,
+			COUNT(*),
+			COUNT(*) FILTER (WHERE j.status = 'Failed'),
+			COALESCE(SUM(j.duration_ms), 0),
+			COUNT(j.duration_ms)
+		FROM job_instances j
+		LEFT JOIN items i ON j.item_id = i.id
+		WHERE j.start_time >= CURRENT_TIMESTAMP - INTERVAL (? || ' days')
+		%s
+		GROUP BY DATE_TRUNC('hour', j.start_time)
+	`, filterClause)
+
+	rows, err := db.conn.Query(query, append([]interface{}{fmt.Sprintf("%d", days)}, filterArgs...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	cells := make([]HeatmapCell, 7*24)
+	for i := range cells {
+		cells[i].Weekday, cells[i].Hour = i/24, i%24
+	}
+	durationSums := make([]float64, len(cells))
+	durationCounts := make([]int, len(cells))
+	for rows.Next() {
+		var hour time.Time
+		var total, failed, withDuration int
+		var durationSum float64
+		if err := rows.Scan(&hour, &total, &failed, &durationSum, &withDuration); err != nil {
+			return nil, err
+		}
+		local := hour.In(loc)
+		i := int(local.Weekday())*24 + local.Hour()
+		cells[i].TotalJobs += total
+		cells[i].Failed += failed
+		durationSums[i] += durationSum
+		durationCounts[i] += withDuration
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range cells {
+		if cells[i].TotalJobs > 0 {
+			cells[i].FailureRate = float64(cells[i].Failed) / float64(cells[i].TotalJobs) * 100
+		}
+		if durationCounts[i] > 0 {
+			cells[i].AvgDurationMs = durationSums[i] / float64(durationCounts[i])
+		}
+	}
+	return cells, nil
+}
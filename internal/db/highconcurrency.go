@@ -0,0 +1,71 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// GetHighConcurrencyStats compares the notebook sessions submitted in the last days that joined a Spark application
+// already running in a high-concurrency session with those that started their own, per consumer identity
+// High-concurrency sessions are only shared by one identity, so a session is shared when it is high-concurrency and
+// an earlier session of its identity started its Spark application
+// The time saved is each shared session's queued time below the average of the sessions that started an application
+// The first row totals all identities and has an empty ConsumerIdentityID
+func (db *Database) GetHighConcurrencyStats(days int, workspaceIDs []string, itemTypes []string, itemNameSearch string) ([]HighConcurrencyStats, error) {
+	filterClause, filterArgs := buildFilterConditions(workspaceIDs, itemTypes, itemNameSearch)
+	query := fmt.Sprintf(`
+		WITH sessions AS (
+			SELECT COALESCE(j.consumer_identity_id, '') AS identity,
+				COALESCE(j.spark_application_id, j.livy_id) AS application,
+				COALESCE(j.is_high_concurrency, false) AS high_concurrency,
+				j.queued_duration_ms,
+				ROW_NUMBER() OVER (
+					PARTITION BY COALESCE(j.consumer_identity_id, ''), COALESCE(j.spark_application_id, j.livy_id)
+					ORDER BY COALESCE(j.start_datetime, j.submitted_datetime), j.livy_id
+				) > 1 AS joined
+			FROM notebook_sessions j
+			LEFT JOIN items i ON j.notebook_id = i.id
+			WHERE j.submitted_datetime >= CURRENT_TIMESTAMP - INTERVAL (? || ' days')
+			%s
+		),
+		flagged AS (
+			SELECT identity, application, high_concurrency, queued_duration_ms, high_concurrency AND joined AS shared
+			FROM sessions
+		),
+		baseline AS (
+			SELECT AVG(queued_duration_ms) AS queued_ms FROM flagged WHERE NOT shared
+		)
+		SELECT COALESCE(f.identity, ''),
+			COUNT(*),
+			COUNT(*) FILTER (WHERE f.high_concurrency),
+			COUNT(*) FILTER (WHERE f.shared),
+			COUNT(*) FILTER (WHERE NOT f.shared),
+			COUNT(DISTINCT f.application) FILTER (WHERE f.shared),
+			AVG(f.queued_duration_ms) FILTER (WHERE NOT f.shared),
+			AVG(f.queued_duration_ms) FILTER (WHERE f.shared),
+			COALESCE(SUM(GREATEST(b.queued_ms - f.queued_duration_ms, 0)) FILTER (WHERE f.shared), 0)
+		FROM flagged f, baseline b
+		GROUP BY GROUPING SETS ((), (f.identity))
+		ORDER BY GROUPING(f.identity) DESC, COUNT(*) FILTER (WHERE f.shared) DESC, COUNT(*) DESC, f.identity
+	`, filterClause)
+
+	rows, err := db.conn.Query(query, append([]interface{}{fmt.Sprintf("%d", days)}, filterArgs...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stats []HighConcurrencyStats
+	for rows.Next() {
+		var s HighConcurrencyStats
+		var dedicatedQueued, sharedQueued, saved sql.NullFloat64
+		if err := rows.Scan(&s.ConsumerIdentityID, &s.Sessions, &s.HighConcurrency, &s.Shared, &s.Dedicated,
+			&s.SharedApplications, &dedicatedQueued, &sharedQueued, &saved); err != nil {
+			return nil, err
+		}
+		s.AvgDedicatedQueuedMs, s.AvgSharedQueuedMs = dedicatedQueued.Float64, sharedQueued.Float64
+		s.EstimatedSavedMs = int64(saved.Float64)
+		stats = append(stats, s)
+	}
+	return stats, rows.Err()
+}
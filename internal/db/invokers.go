@@ -0,0 +1,87 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// Invoker categories runs are split by
+const (
+	InvokerScheduled = "Scheduled"
+	InvokerManual    = "Manual"
+	InvokerPipeline  = "Pipeline" // Started by a pipeline activity
+	InvokerAPI       = "API"      // Started through the REST API, usually by a service principal
+	InvokerUnknown   = "Unknown"  // Synced before the invoker was stored
+)
+
+// GetInvokerStats splits the runs started in the last days by invoker: runs, failures and durations per
+// invoker over all items, followed by the same per item and invoker, each ordered by run count
+// A run started by a pipeline activity counts as Pipeline whatever invoke type Fabric reports for it
+func (db *Database) GetInvokerStats(days int, workspaceIDs []string, itemTypes []string, itemNameSearch string) ([]InvokerStats, error) {
+	filterClause, filterArgs := buildFilterConditions(workspaceIDs, itemTypes, itemNameSearch)
+	query := fmt.Sprintf(`
+		WITH child_runs AS (
+			SELECT DISTINCT COALESCE(
+				json_extract_string(activity, '$.output.pipelineRunId'),
+				json_extract_string(activity, '$.output.runId')
+			) AS job_id
+			FROM (
+				SELECT unnest(CAST(activity_runs AS JSON[])) AS activity
+				FROM job_instances
+				WHERE activity_runs IS NOT NULL
+					AND start_time >= CURRENT_TIMESTAMP - INTERVAL (? || ' days') - INTERVAL 1 DAY
+			)
+		),
+		runs AS (
+			SELECT j.item_id, j.workspace_id, j.status, j.duration_ms,
+				CASE
+					WHEN c.job_id IS NOT NULL OR LOWER(j.invoker_type) LIKE '%%pipeline%%' THEN '%s'
+					WHEN j.invoker_type IS NULL OR j.invoker_type = '' THEN '%s'
+					WHEN LOWER(j.invoker_type) = 'scheduled' THEN '%s'
+					WHEN LOWER(j.invoker_type) = 'manual' THEN '%s'
+					WHEN LOWER(j.invoker_type) LIKE '%%api%%' OR LOWER(j.invoker_type) LIKE '%%service%%' THEN '%s'
+					ELSE j.invoker_type
+				END AS invoker
+			FROM job_instances j
+			LEFT JOIN items i ON j.item_id = i.id
+			LEFT JOIN child_runs c ON c.job_id = j.id
+			WHERE j.start_time >= CURRENT_TIMESTAMP - INTERVAL (? || ' days')
+			%s
+		)
+		SELECT COALESCE(r.item_id, ''), COALESCE(i.display_name, r.item_id, ''), COALESCE(i.type, ''),
+			COALESCE(r.workspace_id, ''), COALESCE(w.display_name, r.workspace_id, ''),
+			r.invoker,
+			COUNT(*),
+			COUNT(*) FILTER (WHERE r.status = 'Completed'),
+			COUNT(*) FILTER (WHERE r.status = 'Failed'),
+			COUNT(*) FILTER (WHERE r.status = 'Cancelled'),
+			COUNT(*) FILTER (WHERE r.status = 'Failed') * 100.0 / NULLIF(COUNT(*) FILTER (WHERE r.status IN ('Completed', 'Failed')), 0),
+			AVG(r.duration_ms) FILTER (WHERE r.status = 'Completed'),
+			quantile_cont(r.duration_ms, 0.9) FILTER (WHERE r.status = 'Completed')
+		FROM runs r
+		LEFT JOIN items i ON r.item_id = i.id
+		LEFT JOIN workspaces w ON r.workspace_id = w.id
+		GROUP BY GROUPING SETS ((r.invoker), (r.item_id, i.display_name, i.type, r.workspace_id, w.display_name, r.invoker))
+		ORDER BY GROUPING(r.item_id) DESC, COALESCE(i.display_name, r.item_id), r.item_id, COUNT(*) DESC, r.invoker
+	`, InvokerPipeline, InvokerUnknown, InvokerScheduled, InvokerManual, InvokerAPI, filterClause)
+
+	daysArg := fmt.Sprintf("%d", days)
+	rows, err := db.conn.Query(query, append([]interface{}{daysArg, daysArg}, filterArgs...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stats []InvokerStats
+	for rows.Next() {
+		var s InvokerStats
+		var failureRate, avg, p90 sql.NullFloat64
+		if err := rows.Scan(&s.ItemID, &s.ItemDisplayName, &s.ItemType, &s.WorkspaceID, &s.WorkspaceName, &s.Invoker,
+			&s.Runs, &s.Succeeded, &s.Failed, &s.Cancelled, &failureRate, &avg, &p90); err != nil {
+			return nil, err
+		}
+		s.FailureRate, s.AvgDurationMs, s.P90DurationMs = failureRate.Float64, avg.Float64, p90.Float64
+		stats = append(stats, s)
+	}
+	return stats, rows.Err()
+}
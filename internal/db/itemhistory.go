@@ -0,0 +1,47 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// GetItemRunHistory returns an item with its runs started in the last days, oldest first, each with its duration
+// regression against the item's last successful runs and its anomaly score where they were computed
+func (db *Database) GetItemRunHistory(itemID string, days int) (*ItemRunHistory, error) {
+	history := &ItemRunHistory{ItemID: itemID, ItemDisplayName: itemID, Runs: []ItemRun{}}
+	err := db.conn.QueryRow(`
+		SELECT COALESCE(i.display_name, i.id), COALESCE(i.type, ''), i.workspace_id, COALESCE(w.display_name, i.workspace_id)
+		FROM items i
+		LEFT JOIN workspaces w ON i.workspace_id = w.id
+		WHERE i.id = ?
+	`, itemID).Scan(&history.ItemDisplayName, &history.ItemType, &history.WorkspaceID, &history.WorkspaceName)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	rows, err := db.conn.Query(`
+		SELECT j.id, j.status, j.invoker_type, j.start_time, j.end_time, j.duration_ms, j.failure_reason,
+			r.baseline_ms, r.delta_pct, a.score
+		FROM job_instances j
+		LEFT JOIN job_duration_regressions r ON r.job_id = j.id
+		LEFT JOIN job_anomalies a ON a.job_id = j.id
+		WHERE j.item_id = ?
+			AND j.start_time >= CURRENT_TIMESTAMP - INTERVAL (? || ' days')
+		ORDER BY j.start_time, j.id
+	`, itemID, fmt.Sprintf("%d", days))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var run ItemRun
+		if err := rows.Scan(&run.JobID, &run.Status, &run.InvokerType, &run.StartTime, &run.EndTime, &run.DurationMs,
+			&run.FailureReason, &run.BaselineMs, &run.DeltaPct, &run.AnomalyScore); err != nil {
+			return nil, err
+		}
+		history.Runs = append(history.Runs, run)
+	}
+	return history, rows.Err()
+}
@@ -0,0 +1,161 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+)
+
+// jobSortColumns maps the fields jobs can be sorted by to their column
+var jobSortColumns = map[string]string{
+	JobSortStartTime:     "j.start_time",
+	JobSortEndTime:       "j.end_time",
+	JobSortDuration:      "j.duration_ms",
+	JobSortStatus:        "j.status",
+	JobSortItemName:      "LOWER(COALESCE(i.display_name, j.item_id))",
+	JobSortItemType:      "COALESCE(i.type, j.job_type)",
+	JobSortWorkspaceName: "LOWER(COALESCE(w.display_name, j.workspace_id))",
+}
+
+// ValidJobSortField reports whether jobs can be sorted by field
+func ValidJobSortField(field string) bool {
+	_, ok := jobSortColumns[field]
+	return ok
+}
+
+// GetJobPage returns the jobs matching search in the order of sort, skipping offset and returning at most limit,
+// with how many jobs match in total
+// Jobs without a value in the sorted column come last; ties are broken by start time, newest first
+func (db *Database) GetJobPage(search JobSearch, sort JobSort, limit, offset int) ([]JobInstance, int, error) {
+	if sort.Field == "" {
+		sort = JobSort{Field: JobSortStartTime, Descending: true}
+	}
+	column, ok := jobSortColumns[sort.Field]
+	if !ok {
+		return nil, 0, fmt.Errorf("unsupported sort field: %s", sort.Field)
+	}
+	direction := "ASC"
+	if sort.Descending {
+		direction = "DESC"
+	}
+
+	whereClause, args := buildJobSearchConditions(search)
+
+	var total int
+	countQuery := fmt.Sprintf(`SELECT COUNT(*) %s %s`, jobInstanceFrom, whereClause)
+	if err := db.conn.QueryRow(countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	if total == 0 {
+		return nil, 0, nil
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s
+		%s
+		%s
+		ORDER BY %s %s NULLS LAST, j.start_time DESC, j.id
+		LIMIT ? OFFSET ?
+	`, jobInstanceColumns, jobInstanceFrom, whereClause, column, direction)
+
+	rows, err := db.conn.Query(query, append(args, limit, offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	jobs, err := scanJobInstances(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+	return jobs, total, nil
+}
+
+// buildJobSearchConditions builds the WHERE clause and arguments of search over jobInstanceFrom
+func buildJobSearchConditions(search JobSearch) (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+
+	in := func(column string, values []string) {
+		placeholders := make([]string, len(values))
+		for i, value := range values {
+			placeholders[i] = "?"
+			args = append(args, value)
+		}
+		conditions = append(conditions, fmt.Sprintf("%s IN (%s)", column, strings.Join(placeholders, ",")))
+	}
+	if len(search.WorkspaceIDs) > 0 {
+		in("j.workspace_id", search.WorkspaceIDs)
+	}
+	if len(search.ItemTypes) > 0 {
+		in("COALESCE(i.type, j.job_type)", search.ItemTypes)
+	}
+	if len(search.Statuses) > 0 {
+		in("j.status", search.Statuses)
+	}
+
+	if text := strings.TrimSpace(search.Text); text != "" {
+		conditions = append(conditions, `(
+			LOWER(COALESCE(i.display_name, j.item_id)) LIKE LOWER(?)
+			OR LOWER(COALESCE(w.display_name, '')) LIKE LOWER(?)
+			OR LOWER(j.id) LIKE LOWER(?)
+			OR LOWER(COALESCE(j.failure_reason, '')) LIKE LOWER(?)
+		)`)
+		pattern := "%" + text + "%"
+		args = append(args, pattern, pattern, pattern, pattern)
+	}
+
+	if search.StartDateFrom != nil {
+		conditions = append(conditions, "j.start_time >= ?")
+		args = append(args, *search.StartDateFrom)
+	}
+	if search.StartDateTo != nil {
+		conditions = append(conditions, "j.start_time <= ?")
+		args = append(args, *search.StartDateTo)
+	}
+	if search.FavoritesOnly {
+		conditions = append(conditions, favoriteJobCondition)
+	}
+
+	if len(conditions) == 0 {
+		return "", args
+	}
+	return "WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// GetJobFacets returns the distinct item types and statuses of all cached jobs, to offer as filters of a page
+func (db *Database) GetJobFacets() (itemTypes []string, statuses []string, err error) {
+	itemTypes, err = db.distinctJobValues("COALESCE(i.type, j.job_type)")
+	if err != nil {
+		return nil, nil, err
+	}
+	statuses, err = db.distinctJobValues("j.status")
+	if err != nil {
+		return nil, nil, err
+	}
+	return itemTypes, statuses, nil
+}
+
+// distinctJobValues returns the distinct non-null values of expr over job instances joined with their items, sorted
+func (db *Database) distinctJobValues(expr string) ([]string, error) {
+	rows, err := db.conn.Query(fmt.Sprintf(`
+		SELECT DISTINCT %[1]s
+		FROM job_instances j
+		LEFT JOIN items i ON j.item_id = i.id
+		WHERE %[1]s IS NOT NULL
+		ORDER BY 1
+	`, expr))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	values := []string{}
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, rows.Err()
+}
@@ -0,0 +1,59 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrDatabaseInUse is returned when another process already has the database open
+var ErrDatabaseInUse = errors.New("database is in use by another Better Fabric Monitor instance")
+
+// InstanceLock is an exclusive lock on a database, held for the lifetime of the process that opened it
+// The operating system drops the lock when the process exits, so a crash never leaves a stale lock behind
+type InstanceLock struct {
+	file *os.File
+	path string
+}
+
+// AcquireInstanceLock takes the lock file next to the database at dbPath
+// Returns ErrDatabaseInUse if another process holds it
+func AcquireInstanceLock(dbPath string) (*InstanceLock, error) {
+	lockPath := dbPath + ".lock"
+	if err := os.MkdirAll(filepath.Dir(lockPath), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create lock directory: %w", err)
+	}
+
+	file, err := os.OpenFile(lockPath, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file: %w", err)
+	}
+
+	if err := lockFile(file); err != nil {
+		file.Close()
+		if errors.Is(err, errLockHeld) {
+			return nil, fmt.Errorf("%w (%s)", ErrDatabaseInUse, lockPath)
+		}
+		return nil, fmt.Errorf("failed to lock %s: %w", lockPath, err)
+	}
+
+	return &InstanceLock{file: file, path: lockPath}, nil
+}
+
+// Release drops the lock; the lock file itself is left in place for the next instance
+func (l *InstanceLock) Release() error {
+	if l == nil || l.file == nil {
+		return nil
+	}
+	unlockFile(l.file)
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
+
+// isDuckDBLockConflict reports whether err is DuckDB refusing to open a file another process has open
+func isDuckDBLockConflict(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "Could not set lock on file")
+}
@@ -0,0 +1,26 @@
+//go:build !windows
+
+package db
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// errLockHeld is returned by lockFile when another process holds the lock
+var errLockHeld = errors.New("lock held by another process")
+
+// lockFile takes an exclusive, non-blocking flock on file
+func lockFile(file *os.File) error {
+	err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+	if errors.Is(err, syscall.EWOULDBLOCK) {
+		return errLockHeld
+	}
+	return err
+}
+
+// unlockFile releases a lock taken by lockFile
+func unlockFile(file *os.File) {
+	syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+}
@@ -0,0 +1,30 @@
+//go:build windows
+
+package db
+
+import (
+	"errors"
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// errLockHeld is returned by lockFile when another process holds the lock
+var errLockHeld = errors.New("lock held by another process")
+
+// lockFile takes an exclusive, non-blocking lock on the first byte of file
+func lockFile(file *os.File) error {
+	var overlapped windows.Overlapped
+	err := windows.LockFileEx(windows.Handle(file.Fd()),
+		windows.LOCKFILE_EXCLUSIVE_LOCK|windows.LOCKFILE_FAIL_IMMEDIATELY, 0, 1, 0, &overlapped)
+	if errors.Is(err, windows.ERROR_LOCK_VIOLATION) {
+		return errLockHeld
+	}
+	return err
+}
+
+// unlockFile releases a lock taken by lockFile
+func unlockFile(file *os.File) {
+	var overlapped windows.Overlapped
+	windows.UnlockFileEx(windows.Handle(file.Fd()), 0, 1, 0, &overlapped)
+}
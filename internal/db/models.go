@@ -11,6 +11,7 @@ type Workspace struct {
 	DisplayName string    `json:"displayName"`
 	Type        string    `json:"type"`
 	Description *string   `json:"description,omitempty"`
+	CapacityID  *string   `json:"capacityId,omitempty"`
 	CreatedAt   time.Time `json:"createdAt"`
 	UpdatedAt   time.Time `json:"updatedAt"`
 }
@@ -24,6 +25,8 @@ type Item struct {
 	Description *string   `json:"description,omitempty"`
 	CreatedAt   time.Time `json:"createdAt"`
 	UpdatedAt   time.Time `json:"updatedAt"`
+	// LastDiscovered is when the item was last seen while listing its workspace (nil if only known from jobs)
+	LastDiscovered *time.Time `json:"lastDiscovered,omitempty"`
 }
 
 // ActivityRun represents a single activity execution within a pipeline
@@ -68,6 +71,7 @@ type JobInstance struct {
 	EndTime         *time.Time    `json:"endTime,omitempty"`
 	DurationMs      *int64        `json:"durationMs,omitempty"`
 	FailureReason   *string       `json:"failureReason,omitempty"`
+	FailureDetails  *string       `json:"failureDetails,omitempty"` // Raw failure JSON from the API (error code, details)
 	InvokerType     *string       `json:"invokerType,omitempty"`
 	RootActivityID  *string       `json:"rootActivityId,omitempty"` // Root activity id to trace requests across services
 	ActivityRuns    []ActivityRun `json:"activityRuns,omitempty"`   // Activity runs data for pipelines
@@ -78,6 +82,8 @@ type JobInstance struct {
 	ItemDisplayName *string       `json:"itemDisplayName,omitempty"` // Joined from items table
 	ItemType        *string       `json:"itemType,omitempty"`        // Joined from items table
 	WorkspaceName   *string       `json:"workspaceName,omitempty"`   // Joined from workspaces table
+	// RemovedUpstreamAt is set once the API stops returning the run (deleted or past Fabric's retention)
+	RemovedUpstreamAt *time.Time `json:"removedUpstreamAt,omitempty"`
 }
 
 // NotebookSession represents a Livy session for a notebook execution
@@ -134,6 +140,13 @@ type ChildExecution struct {
 	LivyID               *string    `json:"livyId,omitempty"`
 }
 
+// ActivityTypeNotebookRun marks a child notebook invoked from a notebook via notebookutils.run / runMultiple
+const ActivityTypeNotebookRun = "NotebookRun"
+
+// JobStatusStale marks in-progress jobs that never reached a terminal state within the stale threshold
+// Stale jobs no longer hold back the incremental sync watermark
+const JobStatusStale = "Stale"
+
 // SyncMetadata tracks sync operations
 type SyncMetadata struct {
 	ID            int64     `json:"id"`
@@ -156,6 +169,34 @@ type JobFilter struct {
 	Offset        *int       `json:"offset,omitempty"`
 }
 
+// JobSearch filters the jobs of a page; empty fields don't filter
+type JobSearch struct {
+	WorkspaceIDs  []string   `json:"workspaceIds,omitempty"`
+	ItemTypes     []string   `json:"itemTypes,omitempty"`
+	Statuses      []string   `json:"statuses,omitempty"`
+	Text          string     `json:"text,omitempty"` // Case-insensitive part of the item or workspace name, job ID or failure reason
+	StartDateFrom *time.Time `json:"startDateFrom,omitempty"`
+	StartDateTo   *time.Time `json:"startDateTo,omitempty"`
+	FavoritesOnly bool       `json:"favoritesOnly,omitempty"` // Only jobs of starred items and of items in starred workspaces
+}
+
+// Fields a page of jobs can be sorted by
+const (
+	JobSortStartTime     = "startTime"
+	JobSortEndTime       = "endTime"
+	JobSortDuration      = "duration"
+	JobSortStatus        = "status"
+	JobSortItemName      = "itemName"
+	JobSortItemType      = "itemType"
+	JobSortWorkspaceName = "workspaceName"
+)
+
+// JobSort orders a page of jobs; an empty Field sorts by start time
+type JobSort struct {
+	Field      string `json:"field"`
+	Descending bool   `json:"descending"`
+}
+
 // JobStats represents aggregated job statistics
 type JobStats struct {
 	TotalJobs     int     `json:"totalJobs"`
@@ -232,6 +273,19 @@ type LongRunningJob struct {
 	LivyID          *string   `json:"livyId,omitempty"`
 }
 
+// FailureStreak is an item whose most recent finished runs all failed
+type FailureStreak struct {
+	ItemID            string    `json:"itemId"`
+	ItemDisplayName   string    `json:"itemDisplayName"`
+	ItemType          string    `json:"itemType"`
+	WorkspaceID       string    `json:"workspaceId"`
+	WorkspaceName     string    `json:"workspaceName"`
+	Streak            int       `json:"streak"`    // Consecutive failed runs, newest first
+	StartedAt         time.Time `json:"startedAt"` // Start of the first failed run of the streak
+	LastFailureAt     time.Time `json:"lastFailureAt"`
+	LastFailureReason string    `json:"lastFailureReason"`
+}
+
 // ItemStats represents job statistics by individual item
 type ItemStats struct {
 	ItemID        string  `json:"itemId"`
@@ -263,11 +317,616 @@ type DailyItemStats struct {
 	AvgDurationMs float64 `json:"avgDurationMs"`
 }
 
+// Sync outcomes stored in SyncMetrics.Status
+const (
+	SyncStatusCompleted = "completed"
+	SyncStatusCancelled = "cancelled"
+	SyncStatusFailed    = "failed"
+)
+
+// SyncMetrics records the performance of a single sync run
+type SyncMetrics struct {
+	ID               int64     `json:"id"`
+	SyncRunID        string    `json:"syncRunId"` // Also logged with every record of the run
+	StartedAt        time.Time `json:"startedAt"`
+	DurationMs       int64     `json:"durationMs"`
+	SyncType         string    `json:"syncType"` // "full" or "incremental"
+	Status           string    `json:"status"`
+	ErrorMessage     *string   `json:"errorMessage,omitempty"`
+	AppVersion       string    `json:"appVersion"`
+	APICalls         int       `json:"apiCalls"` // HTTP requests sent, including retries
+	Retries          int       `json:"retries"`
+	Throttled        int       `json:"throttled"` // Responses with status 429
+	Workspaces       int       `json:"workspaces"`
+	WorkspacesFailed int       `json:"workspacesFailed"`
+	JobsFetched      int       `json:"jobsFetched"`
+	RowsWritten      int       `json:"rowsWritten"`
+}
+
+// WorkspaceActivity summarizes the recent jobs of a workspace for adaptive polling
+type WorkspaceActivity struct {
+	RunningJobs int        // Jobs in progress (stale jobs excluded)
+	RecentJobs  int        // Jobs started since the activity window began
+	LastJobAt   *time.Time // Start of the most recent job, if any
+}
+
+// WorkspacePollSchedule is the adaptive polling decision for a workspace
+type WorkspacePollSchedule struct {
+	WorkspaceID     string     `json:"workspaceId"`
+	WorkspaceName   string     `json:"workspaceName"`
+	Activity        string     `json:"activity"` // "busy", "quiet" or "dormant"
+	RunningJobs     int        `json:"runningJobs"`
+	RecentJobs      int        `json:"recentJobs"`
+	LastJobAt       *time.Time `json:"lastJobAt,omitempty"`
+	IntervalSeconds int        `json:"intervalSeconds"`
+	LastPolledAt    time.Time  `json:"lastPolledAt"`
+	NextPollAt      time.Time  `json:"nextPollAt"`
+}
+
 // ParquetExportStats represents statistics for a Parquet export operation
 type ParquetExportStats struct {
 	TableName    string `json:"tableName"`
 	RecordCount  int    `json:"recordCount"`
 	DurationMs   int64  `json:"durationMs"`
 	Success      bool   `json:"success"`
+	Skipped      bool   `json:"skipped"` // Unchanged since the last export, so no file was written
 	ErrorMessage string `json:"errorMessage,omitempty"`
 }
+
+// DatabaseStats describes the database file for diagnostics
+type DatabaseStats struct {
+	Path          string          `json:"path"`
+	SizeBytes     int64           `json:"sizeBytes"`    // Size of the database file, excluding the WAL
+	WALSizeBytes  int64           `json:"walSizeBytes"` // Size of the write-ahead log, 0 when none exists
+	DuckDBVersion string          `json:"duckdbVersion"`
+	SchemaVersion int             `json:"schemaVersion"`
+	ReadOnly      bool            `json:"readOnly"`
+	Tables        []TableRowCount `json:"tables"`
+}
+
+// TableRowCount is the number of rows in one table
+type TableRowCount struct {
+	Table string `json:"table"`
+	Rows  int64  `json:"rows"`
+}
+
+// NotificationRecord is one notification routed to a channel
+type NotificationRecord struct {
+	ID           int64     `json:"id"`
+	SentAt       time.Time `json:"sentAt"`
+	Rule         string    `json:"rule"` // Route that matched, e.g. notifications.escalation.webhook
+	Channel      string    `json:"channel"`
+	EventType    string    `json:"eventType"`
+	Title        string    `json:"title"`
+	Message      *string   `json:"message,omitempty"`
+	JobID        *string   `json:"jobId,omitempty"` // Run the notification was about, if any
+	ItemID       *string   `json:"itemId,omitempty"`
+	WorkspaceID  *string   `json:"workspaceId,omitempty"`
+	Status       string    `json:"status"` // sent, failed or suppressed
+	ErrorMessage *string   `json:"errorMessage,omitempty"`
+}
+
+// Targets a notification mute can apply to
+const (
+	MuteTargetItem      = "item"
+	MuteTargetWorkspace = "workspace"
+)
+
+// NotificationMute silences notifications about an item or every item of a workspace
+type NotificationMute struct {
+	TargetType string     `json:"targetType"` // item or workspace
+	TargetID   string     `json:"targetId"`
+	TargetName string     `json:"targetName"` // Display name of the item or workspace, if cached
+	Reason     *string    `json:"reason,omitempty"`
+	MutedAt    time.Time  `json:"mutedAt"`
+	ExpiresAt  *time.Time `json:"expiresAt,omitempty"` // Muted until unmuted when nil
+}
+
+// Targets a favorite can star
+const (
+	FavoriteTargetItem      = "item"
+	FavoriteTargetWorkspace = "workspace"
+)
+
+// Favorite is an item or workspace starred by the user
+type Favorite struct {
+	TargetType string    `json:"targetType"` // item or workspace
+	TargetID   string    `json:"targetId"`
+	TargetName string    `json:"targetName"` // Display name of the item or workspace, if cached
+	StarredAt  time.Time `json:"starredAt"`
+}
+
+// WorkspaceSettings overrides global settings for one workspace; a nil field keeps the global setting
+type WorkspaceSettings struct {
+	WorkspaceID         string    `json:"workspaceId"`
+	WorkspaceName       string    `json:"workspaceName"` // Display name of the workspace, if cached
+	PollIntervalSeconds *int      `json:"pollIntervalSeconds,omitempty"`
+	RetentionDays       *int      `json:"retentionDays,omitempty"`     // Runs older than this are deleted after each sync
+	EnrichmentEnabled   *bool     `json:"enrichmentEnabled,omitempty"` // Fetch activity runs and Livy sessions
+	NotificationRules   []string  `json:"notificationRules"`           // Rules events are routed to; nil routes to all rules
+	UpdatedAt           time.Time `json:"updatedAt"`
+}
+
+// Alert types recorded for running jobs so each is raised once
+const (
+	AlertLongRunning = "long_running" // The job exceeded the long-running threshold
+	AlertStuck       = "stuck"        // The job stayed queued or running far past its item's usual duration
+)
+
+// RunningJobAlert is a queued or in-progress job that crossed an alert threshold
+type RunningJobAlert struct {
+	Job        JobInstance
+	ElapsedMs  int64
+	BaselineMs *float64 // Average duration of the item's completed runs, when it has enough history
+}
+
+// RunningJobAge is a queued or in-progress job with how long it has run and its item's usual duration
+type RunningJobAge struct {
+	Job           JobInstance
+	ElapsedMs     int64
+	BaselineMs    *float64 // Average duration of the item's completed runs, when it has enough history
+	P90DurationMs *float64
+	BaselineRuns  int // Completed runs the baseline is based on
+}
+
+// Digest summarizes the job runs started in a period for a digest report
+type Digest struct {
+	From           time.Time       `json:"from"`
+	To             time.Time       `json:"to"`
+	Stats          JobStats        `json:"stats"`
+	NewFailures    []DigestFailure `json:"newFailures"`    // Items that failed in the period but not in the 30 days before
+	SlowestItems   []DigestItem    `json:"slowestItems"`   // Items with the longest average completed run
+	SLAThresholdMs int64           `json:"slaThresholdMs"` // Runs longer than this count as SLA breaches (0 disables)
+	SLABreaches    []DigestBreach  `json:"slaBreaches"`
+}
+
+// DigestFailure is an item that started failing in a digest period
+type DigestFailure struct {
+	ItemID          string `json:"itemId"`
+	ItemDisplayName string `json:"itemDisplayName"`
+	WorkspaceName   string `json:"workspaceName"`
+	Failures        int    `json:"failures"`
+	LastReason      string `json:"lastReason"`
+}
+
+// DigestItem is an item's average completed run duration in a digest period
+type DigestItem struct {
+	ItemID          string  `json:"itemId"`
+	ItemDisplayName string  `json:"itemDisplayName"`
+	WorkspaceName   string  `json:"workspaceName"`
+	Runs            int     `json:"runs"`
+	AvgDurationMs   float64 `json:"avgDurationMs"`
+	MaxDurationMs   int64   `json:"maxDurationMs"`
+}
+
+// DigestBreach is a finished run that took longer than the digest's SLA threshold
+type DigestBreach struct {
+	JobID           string    `json:"jobId"`
+	ItemDisplayName string    `json:"itemDisplayName"`
+	WorkspaceName   string    `json:"workspaceName"`
+	Status          string    `json:"status"`
+	StartTime       time.Time `json:"startTime"`
+	DurationMs      int64     `json:"durationMs"`
+}
+
+// RunAnomaly is a completed run whose duration was unusual for its item
+type RunAnomaly struct {
+	JobID           string    `json:"jobId"`
+	WorkspaceID     string    `json:"workspaceId"`
+	WorkspaceName   string    `json:"workspaceName"`
+	ItemID          string    `json:"itemId"`
+	ItemDisplayName string    `json:"itemDisplayName"`
+	ItemType        string    `json:"itemType"`
+	StartTime       time.Time `json:"startTime"`
+	DurationMs      int64     `json:"durationMs"`
+	Score           float64   `json:"score"`      // Positive when slower than usual, negative when faster
+	Method          string    `json:"method"`     // mad or zscore
+	BaselineMs      float64   `json:"baselineMs"` // Typical duration the run was compared with
+	Samples         int       `json:"samples"`
+	WeekdayAware    bool      `json:"weekdayAware"` // Compared only with runs on the same weekday
+}
+
+// RecoveryStats is how long failures of an item or a workspace's items took to be fixed
+// An incident starts when a run fails after a successful one and ends when the item next completes
+type RecoveryStats struct {
+	ItemID           string  `json:"itemId,omitempty"`
+	ItemDisplayName  string  `json:"itemDisplayName,omitempty"`
+	ItemType         string  `json:"itemType,omitempty"`
+	WorkspaceID      string  `json:"workspaceId"`
+	WorkspaceName    string  `json:"workspaceName"`
+	Incidents        int     `json:"incidents"`
+	Recovered        int     `json:"recovered"` // Incidents already ended by a successful run
+	MeanRecoveryMs   float64 `json:"meanRecoveryMs"`
+	MedianRecoveryMs float64 `json:"medianRecoveryMs"`
+	MaxRecoveryMs    int64   `json:"maxRecoveryMs"`
+}
+
+// RunCadence is the cadence inferred from an item's scheduled runs and how they strayed from it
+type RunCadence struct {
+	Runs           int       `json:"runs"`
+	CadenceMs      int64     `json:"cadenceMs"`    // Median interval between runs
+	ScheduleMs     int64     `json:"scheduleMs"`   // Schedule unit the cadence matches, 0 when it matches none
+	Regular        bool      `json:"regular"`      // The intervals are close enough to the cadence to check for gaps and drift
+	Gaps           int       `json:"gaps"`         // Intervals long enough that a run was skipped
+	LongestGapMs   int64     `json:"longestGapMs"` // Longest interval between runs
+	DriftMs        int64     `json:"driftMs"`      // Change in start time against the schedule over the window, positive when starting later and later
+	Drifting       bool      `json:"drifting"`     // The drift is at least 15 minutes or a quarter of the schedule
+	LastRunAt      time.Time `json:"lastRunAt"`
+	NextExpectedAt time.Time `json:"nextExpectedAt"` // Last run plus the cadence
+	Overdue        bool      `json:"overdue"`        // No run for two cadences
+}
+
+// ItemCadence is the run cadence of an item
+type ItemCadence struct {
+	ItemID          string `json:"itemId"`
+	ItemDisplayName string `json:"itemDisplayName"`
+	ItemType        string `json:"itemType"`
+	WorkspaceID     string `json:"workspaceId"`
+	WorkspaceName   string `json:"workspaceName"`
+	RunCadence
+}
+
+// RunGap is an interval between two runs long enough that at least one run was skipped
+type RunGap struct {
+	From       time.Time `json:"from"` // Start of the run before the gap
+	To         time.Time `json:"to"`   // Start of the run after the gap
+	MissedRuns int       `json:"missedRuns"`
+}
+
+// ItemRunHistory is an item with its runs of a period, oldest first
+type ItemRunHistory struct {
+	ItemID          string    `json:"itemId"`
+	ItemDisplayName string    `json:"itemDisplayName"`
+	ItemType        string    `json:"itemType"`
+	WorkspaceID     string    `json:"workspaceId"`
+	WorkspaceName   string    `json:"workspaceName"`
+	Runs            []ItemRun `json:"runs"`
+}
+
+// ItemRun is one run of an item's history, with how its duration compared with the item's earlier runs
+type ItemRun struct {
+	JobID         string     `json:"jobId"`
+	Status        string     `json:"status"`
+	InvokerType   *string    `json:"invokerType,omitempty"`
+	StartTime     time.Time  `json:"startTime"`
+	EndTime       *time.Time `json:"endTime,omitempty"`
+	DurationMs    *int64     `json:"durationMs,omitempty"`
+	FailureReason *string    `json:"failureReason,omitempty"`
+	BaselineMs    *float64   `json:"baselineMs,omitempty"`   // Median duration of the item's last successful runs before this one
+	DeltaPct      *float64   `json:"deltaPct,omitempty"`     // Duration against the baseline, in percent; completed runs only
+	AnomalyScore  *float64   `json:"anomalyScore,omitempty"` // Duration anomaly score against the item's earlier runs
+}
+
+// Kinds of dependency between items, derived from pipeline activity runs
+const (
+	DependencyInvokes  = "invokes"  // A pipeline runs another pipeline or a notebook
+	DependencyReads    = "reads"    // A copy activity reads from the item
+	DependencyWrites   = "writes"   // A copy activity writes to the item
+	DependencyPrecedes = "precedes" // The item runs after the other one finished within the same parent run
+)
+
+// DependencyNode is an item in the dependency graph
+type DependencyNode struct {
+	ItemID          string `json:"itemId"`
+	ItemDisplayName string `json:"itemDisplayName"`
+	ItemType        string `json:"itemType"`
+	WorkspaceID     string `json:"workspaceId"`
+	WorkspaceName   string `json:"workspaceName"`
+	LastStatus      string `json:"lastStatus,omitempty"` // Status of the item's latest run
+}
+
+// DependencyEdge is a dependency seen in the activity runs of pipeline runs, pointing downstream:
+// from a pipeline to what it invokes or writes, from an item a pipeline reads to the pipeline,
+// and from an invoked item to the one run after it
+type DependencyEdge struct {
+	From       string    `json:"from"` // Item ID
+	To         string    `json:"to"`   // Item ID
+	Kind       string    `json:"kind"`
+	Activity   string    `json:"activity"`   // Name of the activity behind the latest occurrence
+	Runs       int       `json:"runs"`       // Parent pipeline runs the dependency was seen in
+	LastSeenAt time.Time `json:"lastSeenAt"` // Start of the latest parent pipeline run
+	LastStatus string    `json:"lastStatus"` // Status of the latest activity behind it
+}
+
+// DependencyGraph is the item dependency graph derived from pipeline activity runs
+type DependencyGraph struct {
+	Nodes []DependencyNode `json:"nodes"`
+	Edges []DependencyEdge `json:"edges"`
+}
+
+// ErrorCodeStats aggregates the failures sharing an error code
+type ErrorCodeStats struct {
+	ErrorCode      string    `json:"errorCode"`
+	Occurrences    int       `json:"occurrences"`    // Runs the code appeared in, as a job failure or an activity error
+	JobFailures    int       `json:"jobFailures"`    // Failed runs whose own error has the code
+	ActivityErrors int       `json:"activityErrors"` // Failed pipeline activities with the code
+	LastDay        int       `json:"lastDay"`        // Occurrences in the last 24 hours, to spot spikes
+	AffectedItems  int       `json:"affectedItems"`
+	Items          []string  `json:"items"` // Names of the most affected items
+	FirstSeen      time.Time `json:"firstSeen"`
+	LastSeen       time.Time `json:"lastSeen"`
+	SampleMessage  string    `json:"sampleMessage"` // Message of the latest occurrence
+}
+
+// DurationForecast is the expected duration of an item's next run, from a smoothed level and trend of its past runs
+type DurationForecast struct {
+	ItemID          string  `json:"itemId"`
+	ItemDisplayName string  `json:"itemDisplayName"`
+	ItemType        string  `json:"itemType"`
+	WorkspaceID     string  `json:"workspaceId"`
+	WorkspaceName   string  `json:"workspaceName"`
+	ExpectedMs      float64 `json:"expectedMs"`
+	LowMs           float64 `json:"lowMs"`         // Expected minus the smoothed forecast error
+	HighMs          float64 `json:"highMs"`        // Expected plus the smoothed forecast error
+	EWMAMs          float64 `json:"ewmaMs"`        // Smoothed duration without the trend
+	TrendMsPerRun   float64 `json:"trendMsPerRun"` // Change in duration per run, positive when runs are getting slower
+	Samples         int     `json:"samples"`
+}
+
+// ActivityRemainder is how long the latest completed runs of a pipeline usually went on after one of its activities finished
+type ActivityRemainder struct {
+	ItemID       string
+	ActivityName string
+	RemainingMs  float64 // Median over the runs the activity succeeded in
+}
+
+// HeatmapCell counts the runs started in one hour of one weekday
+type HeatmapCell struct {
+	Weekday       int     `json:"weekday"` // 0 is Sunday
+	Hour          int     `json:"hour"`
+	TotalJobs     int     `json:"totalJobs"`
+	Failed        int     `json:"failed"`
+	FailureRate   float64 `json:"failureRate"` // Percentage of the runs that failed
+	AvgDurationMs float64 `json:"avgDurationMs"`
+}
+
+// CalendarDay counts the runs started on one day
+type CalendarDay struct {
+	Date        string    `json:"date"`    // YYYY-MM-DD in the calendar's time zone
+	Weekday     int       `json:"weekday"` // 0 is Sunday
+	Start       time.Time `json:"start"`   // Start of the day, e.g. to list its runs with GetJobsPaged
+	End         time.Time `json:"end"`     // Start of the next day
+	TotalJobs   int       `json:"totalJobs"`
+	Failed      int       `json:"failed"`
+	SLABreaches int       `json:"slaBreaches"` // Runs longer than the SLA threshold
+	FailureRate float64   `json:"failureRate"` // Percentage of the runs that failed
+}
+
+// ActivityStats aggregates the runs of one activity of a pipeline
+// Runs of the activity inside a loop are summed per pipeline run
+type ActivityStats struct {
+	ItemID          string  `json:"itemId"`
+	ItemDisplayName string  `json:"itemDisplayName"`
+	WorkspaceID     string  `json:"workspaceId"`
+	WorkspaceName   string  `json:"workspaceName"`
+	ActivityName    string  `json:"activityName"`
+	ActivityType    string  `json:"activityType"`
+	Runs            int     `json:"runs"` // Pipeline runs the activity ran in
+	Failed          int     `json:"failed"`
+	FailureRate     float64 `json:"failureRate"` // Percentage of those runs in which the activity failed
+	AvgDurationMs   float64 `json:"avgDurationMs"`
+	P90DurationMs   float64 `json:"p90DurationMs"`
+	MaxDurationMs   int64   `json:"maxDurationMs"`
+	ShareOfRunTime  float64 `json:"shareOfRunTime"` // Percentage of the pipeline runs' time spent in the activity
+	TrendMsPerDay   float64 `json:"trendMsPerDay"`  // Fitted change in duration per day, positive when getting slower
+	Slowest         bool    `json:"slowest"`        // The activity taking the largest share of its pipeline's run time
+}
+
+// CopyThroughputStats aggregates the runs of one Copy activity of a pipeline
+type CopyThroughputStats struct {
+	ItemID                string  `json:"itemId"`
+	ItemDisplayName       string  `json:"itemDisplayName"`
+	WorkspaceID           string  `json:"workspaceId"`
+	WorkspaceName         string  `json:"workspaceName"`
+	ActivityName          string  `json:"activityName"`
+	Runs                  int     `json:"runs"`
+	Failed                int     `json:"failed"`
+	RowsRead              int64   `json:"rowsRead"`
+	RowsWritten           int64   `json:"rowsWritten"`
+	DataRead              int64   `json:"dataRead"`    // Bytes
+	DataWritten           int64   `json:"dataWritten"` // Bytes
+	AvgThroughputKBps     float64 `json:"avgThroughputKBps"`
+	MinThroughputKBps     float64 `json:"minThroughputKBps"`
+	AvgRowsPerSecond      float64 `json:"avgRowsPerSecond"`
+	AvgDurationMs         float64 `json:"avgDurationMs"`
+	AvgQueuingSeconds     float64 `json:"avgQueuingSeconds"`     // Time waiting for copy resources
+	AvgTransferSeconds    float64 `json:"avgTransferSeconds"`    // Time moving data
+	ThroughputTrendPerDay float64 `json:"throughputTrendPerDay"` // Fitted change in KB/s per day, negative when slowing down
+}
+
+// ActivityRetryStats aggregates the retries of one activity of a pipeline
+// An execution is the attempts of the activity in one pipeline run, or in one loop iteration of it
+type ActivityRetryStats struct {
+	ItemID              string `json:"itemId"`
+	ItemDisplayName     string `json:"itemDisplayName"`
+	WorkspaceID         string `json:"workspaceId"`
+	WorkspaceName       string `json:"workspaceName"`
+	ActivityName        string `json:"activityName"`
+	ActivityType        string `json:"activityType"`
+	Executions          int    `json:"executions"`
+	Retried             int    `json:"retried"`             // Executions that needed more than one attempt
+	SucceededAfterRetry int    `json:"succeededAfterRetry"` // Retried executions whose last attempt succeeded
+	FailedAfterRetry    int    `json:"failedAfterRetry"`    // Retried executions whose last attempt failed too
+	Retries             int    `json:"retries"`             // Attempts after the first
+	MaxRetries          int    `json:"maxRetries"`
+	RetryTimeMs         int64  `json:"retryTimeMs"` // Time spent in attempts that were retried
+}
+
+// PipelineRetryStats aggregates the retries of the activities of one pipeline
+type PipelineRetryStats struct {
+	ItemID              string `json:"itemId"`
+	ItemDisplayName     string `json:"itemDisplayName"`
+	WorkspaceID         string `json:"workspaceId"`
+	WorkspaceName       string `json:"workspaceName"`
+	Runs                int    `json:"runs"`
+	RetriedRuns         int    `json:"retriedRuns"` // Runs with at least one retried activity
+	Executions          int    `json:"executions"`  // Activity executions, as in ActivityRetryStats
+	Retried             int    `json:"retried"`
+	SucceededAfterRetry int    `json:"succeededAfterRetry"`
+	FailedAfterRetry    int    `json:"failedAfterRetry"`
+	Retries             int    `json:"retries"`
+	RetryTimeMs         int64  `json:"retryTimeMs"` // Time lost to retries
+}
+
+// HighConcurrencyStats counts the notebook sessions that shared a high-concurrency Spark application
+// against those that started their own, and the queued time sharing saved
+type HighConcurrencyStats struct {
+	ConsumerIdentityID   string  `json:"consumerIdentityId"`
+	Sessions             int     `json:"sessions"`
+	HighConcurrency      int     `json:"highConcurrency"`    // Sessions run in high-concurrency mode
+	Shared               int     `json:"shared"`             // High-concurrency sessions that joined a running Spark application
+	Dedicated            int     `json:"dedicated"`          // Sessions that started their own Spark application
+	SharedApplications   int     `json:"sharedApplications"` // Spark applications joined by at least one shared session
+	AvgDedicatedQueuedMs float64 `json:"avgDedicatedQueuedMs"`
+	AvgSharedQueuedMs    float64 `json:"avgSharedQueuedMs"`
+	EstimatedSavedMs     int64   `json:"estimatedSavedMs"` // Queued time of the shared sessions below the dedicated average
+}
+
+// InvokerStats aggregates the runs of one invoker category, over all items when ItemID is empty
+type InvokerStats struct {
+	ItemID          string  `json:"itemId,omitempty"`
+	ItemDisplayName string  `json:"itemDisplayName,omitempty"`
+	ItemType        string  `json:"itemType,omitempty"`
+	WorkspaceID     string  `json:"workspaceId,omitempty"`
+	WorkspaceName   string  `json:"workspaceName,omitempty"`
+	Invoker         string  `json:"invoker"` // One of the Invoker categories, or the invoke type Fabric reported when it fits none
+	Runs            int     `json:"runs"`
+	Succeeded       int     `json:"succeeded"`
+	Failed          int     `json:"failed"`
+	Cancelled       int     `json:"cancelled"`
+	FailureRate     float64 `json:"failureRate"`   // Percentage of finished runs that failed, cancelled runs left out
+	AvgDurationMs   float64 `json:"avgDurationMs"` // Of completed runs
+	P90DurationMs   float64 `json:"p90DurationMs"`
+}
+
+// EnvironmentMapping maps an item to the logical item it is a copy of in one environment
+type EnvironmentMapping struct {
+	ItemID          string    `json:"itemId"`
+	ItemDisplayName string    `json:"itemDisplayName"`
+	WorkspaceID     string    `json:"workspaceId"`
+	WorkspaceName   string    `json:"workspaceName"`
+	LogicalName     string    `json:"logicalName"` // Shared by the item's copies in every environment
+	Environment     string    `json:"environment"` // Such as dev, test or prod
+	MappedAt        time.Time `json:"mappedAt"`
+}
+
+// EnvironmentItemStats aggregates the runs of one item for comparing it with its copies in other environments
+type EnvironmentItemStats struct {
+	ItemID          string
+	ItemDisplayName string
+	ItemType        string
+	WorkspaceID     string
+	WorkspaceName   string
+	LogicalName     *string // Set when the item is mapped by hand
+	Environment     *string
+	Runs            int
+	Succeeded       int
+	Failed          int
+	SuccessRate     float64 // Percentage of finished runs that completed
+	AvgDurationMs   float64 // Of completed runs
+	P90DurationMs   float64
+}
+
+// DailyDurationPercentiles is the spread of the durations of the completed runs of one day
+type DailyDurationPercentiles struct {
+	Date          string  `json:"date"`
+	Runs          int     `json:"runs"`
+	P50DurationMs float64 `json:"p50DurationMs"`
+	P90DurationMs float64 `json:"p90DurationMs"`
+	MaxDurationMs int64   `json:"maxDurationMs"`
+}
+
+// DurationPercentileSeries is the daily duration percentiles of a period and their trend
+type DurationPercentileSeries struct {
+	Days             []DailyDurationPercentiles `json:"days"`             // Days without completed runs are left out
+	P50TrendMsPerDay float64                    `json:"p50TrendMsPerDay"` // Fitted change of the daily P50 per day, positive when runs are getting slower
+	P90TrendMsPerDay float64                    `json:"p90TrendMsPerDay"`
+}
+
+// CorrelatedItem is an item of a failure correlation group
+type CorrelatedItem struct {
+	ItemID          string `json:"itemId"`
+	ItemDisplayName string `json:"itemDisplayName"`
+	ItemType        string `json:"itemType"`
+	WorkspaceID     string `json:"workspaceId"`
+	WorkspaceName   string `json:"workspaceName"`
+	Failures        int    `json:"failures"`
+	FailureWindows  int    `json:"failureWindows"` // Windows the item failed in
+}
+
+// CorrelatedPair is two items whose runs tend to fail in the same windows
+type CorrelatedPair struct {
+	A       string  `json:"a"`
+	B       string  `json:"b"`
+	Shared  int     `json:"shared"`  // Windows both failed in
+	Jaccard float64 `json:"jaccard"` // Shared windows over the windows either failed in
+}
+
+// FailureCorrelationGroup is items linked through pairs whose runs tend to fail in the same windows
+type FailureCorrelationGroup struct {
+	Items         []CorrelatedItem
+	Pairs         []CorrelatedPair
+	SharedWindows []time.Time // Starts of the windows two or more of the items failed in
+}
+
+// CapacityLoadStats is the load the runs of a period put on one capacity
+type CapacityLoadStats struct {
+	CapacityID      string     `json:"capacityId"` // Empty for workspaces synced before capacities were stored
+	Workspaces      int        `json:"workspaces"` // Workspaces with runs in the period
+	Items           int        `json:"items"`
+	Runs            int        `json:"runs"`
+	Succeeded       int        `json:"succeeded"`
+	Failed          int        `json:"failed"`
+	Cancelled       int        `json:"cancelled"`
+	FailureRate     float64    `json:"failureRate"`     // Percentage of finished runs that failed, cancelled runs left out
+	RunTimeMs       int64      `json:"runTimeMs"`       // Summed run time, in-progress runs counted until now
+	AvgConcurrency  float64    `json:"avgConcurrency"`  // Run time over the length of the period
+	PeakConcurrency int        `json:"peakConcurrency"` // Most runs in progress at once
+	PeakAt          *time.Time `json:"peakAt,omitempty"`
+	Sessions        int        `json:"sessions"` // Notebook sessions with a queued time
+	AvgQueuedMs     float64    `json:"avgQueuedMs"`
+	P90QueuedMs     float64    `json:"p90QueuedMs"`
+	MaxQueuedMs     int64      `json:"maxQueuedMs"`
+}
+
+// ItemFreshness is when an item last ran and last succeeded, with its freshness threshold when one is set
+type ItemFreshness struct {
+	ItemID          string     `json:"itemId"`
+	ItemDisplayName string     `json:"itemDisplayName"`
+	ItemType        string     `json:"itemType"`
+	WorkspaceID     string     `json:"workspaceId"`
+	WorkspaceName   string     `json:"workspaceName"`
+	LastRunAt       time.Time  `json:"lastRunAt"` // Start of the latest run
+	LastRunStatus   string     `json:"lastRunStatus"`
+	LastSuccessAt   *time.Time `json:"lastSuccessAt,omitempty"` // End of the latest completed run
+	MaxAgeMinutes   *int       `json:"maxAgeMinutes,omitempty"` // Threshold set by hand
+}
+
+// DurationRegression is a completed run that took longer than the item's recent successful runs
+type DurationRegression struct {
+	JobID           string    `json:"jobId"`
+	WorkspaceID     string    `json:"workspaceId"`
+	WorkspaceName   string    `json:"workspaceName"`
+	ItemID          string    `json:"itemId"`
+	ItemDisplayName string    `json:"itemDisplayName"`
+	ItemType        string    `json:"itemType"`
+	StartTime       time.Time `json:"startTime"`
+	DurationMs      int64     `json:"durationMs"`
+	BaselineMs      float64   `json:"baselineMs"` // Median duration of the item's last successful runs before this one
+	DeltaMs         float64   `json:"deltaMs"`
+	DeltaPct        float64   `json:"deltaPct"` // Delta as a percentage of the baseline
+	Samples         int       `json:"samples"`  // Runs the baseline was taken over
+}
+
+// TopEntry is an item or workspace ranked by its runs of a period
+type TopEntry struct {
+	ID            string  `json:"id"`
+	DisplayName   string  `json:"displayName"`
+	ItemType      string  `json:"itemType,omitempty"` // Items only
+	WorkspaceID   string  `json:"workspaceId"`
+	WorkspaceName string  `json:"workspaceName"`
+	Runs          int     `json:"runs"`
+	Failed        int     `json:"failed"`
+	RuntimeMs     int64   `json:"runtimeMs"` // Summed duration of finished runs
+	Value         float64 `json:"value"`     // The metric the entry was ranked by
+}
@@ -0,0 +1,213 @@
+package db
+
+import (
+	"database/sql"
+	"time"
+)
+
+// SaveNotification records a notification delivery attempt
+func (db *Database) SaveNotification(n *NotificationRecord) error {
+	query := `
+		INSERT INTO notification_history (
+			sent_at, rule, channel, event_type, title, message,
+			job_id, item_id, workspace_id, status, error_message
+		)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err := db.conn.Exec(query,
+		n.SentAt, n.Rule, n.Channel, n.EventType, n.Title, n.Message,
+		n.JobID, n.ItemID, n.WorkspaceID, n.Status, n.ErrorMessage)
+	return err
+}
+
+// GetNotificationHistory returns the most recent notification delivery attempts, newest first
+func (db *Database) GetNotificationHistory(limit int) ([]NotificationRecord, error) {
+	query := `
+		SELECT id, sent_at, rule, channel, event_type, title, message,
+			job_id, item_id, workspace_id, status, error_message
+		FROM notification_history
+		ORDER BY sent_at DESC, id DESC
+		LIMIT ?
+	`
+
+	rows, err := db.conn.Query(query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var history []NotificationRecord
+	for rows.Next() {
+		var n NotificationRecord
+		if err := rows.Scan(&n.ID, &n.SentAt, &n.Rule, &n.Channel, &n.EventType, &n.Title, &n.Message,
+			&n.JobID, &n.ItemID, &n.WorkspaceID, &n.Status, &n.ErrorMessage); err != nil {
+			return nil, err
+		}
+		history = append(history, n)
+	}
+	return history, rows.Err()
+}
+
+// GetLastNotificationTime returns when an event of eventType was last routed to a channel, or nil if never
+func (db *Database) GetLastNotificationTime(eventType string) (*time.Time, error) {
+	var sentAt sql.NullTime
+	if err := db.conn.QueryRow(`SELECT MAX(sent_at) FROM notification_history WHERE event_type = ?`, eventType).Scan(&sentAt); err != nil {
+		return nil, err
+	}
+	if !sentAt.Valid {
+		return nil, nil
+	}
+	return &sentAt.Time, nil
+}
+
+// SaveMute mutes a target, replacing any existing mute of it
+func (db *Database) SaveMute(m *NotificationMute) error {
+	query := `
+		INSERT INTO notification_mutes (target_type, target_id, reason, muted_at, expires_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (target_type, target_id) DO UPDATE SET
+			reason = EXCLUDED.reason,
+			muted_at = EXCLUDED.muted_at,
+			expires_at = EXCLUDED.expires_at
+	`
+	_, err := db.conn.Exec(query, m.TargetType, m.TargetID, m.Reason, m.MutedAt, m.ExpiresAt)
+	return err
+}
+
+// DeleteMute unmutes a target
+func (db *Database) DeleteMute(targetType, targetID string) error {
+	_, err := db.conn.Exec(`DELETE FROM notification_mutes WHERE target_type = ? AND target_id = ?`, targetType, targetID)
+	return err
+}
+
+// GetActiveMutes returns the mutes that have not expired at now, soonest to expire first
+func (db *Database) GetActiveMutes(now time.Time) ([]NotificationMute, error) {
+	query := `
+		SELECT m.target_type, m.target_id, COALESCE(i.display_name, w.display_name, m.target_id),
+			m.reason, m.muted_at, m.expires_at
+		FROM notification_mutes m
+		LEFT JOIN items i ON m.target_type = 'item' AND i.id = m.target_id
+		LEFT JOIN workspaces w ON m.target_type = 'workspace' AND w.id = m.target_id
+		WHERE m.expires_at IS NULL OR m.expires_at > ?
+		ORDER BY m.expires_at NULLS LAST, m.muted_at
+	`
+
+	rows, err := db.conn.Query(query, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var mutes []NotificationMute
+	for rows.Next() {
+		var m NotificationMute
+		var expiresAt sql.NullTime
+		if err := rows.Scan(&m.TargetType, &m.TargetID, &m.TargetName, &m.Reason, &m.MutedAt, &expiresAt); err != nil {
+			return nil, err
+		}
+		if expiresAt.Valid {
+			m.ExpiresAt = &expiresAt.Time
+		}
+		mutes = append(mutes, m)
+	}
+	return mutes, rows.Err()
+}
+
+// IsMuted reports whether notifications about an item are muted at now, directly or through its workspace
+func (db *Database) IsMuted(workspaceID, itemID string, now time.Time) (bool, error) {
+	query := `
+		SELECT COUNT(*) > 0
+		FROM notification_mutes
+		WHERE ((target_type = 'item' AND target_id = ?) OR (target_type = 'workspace' AND target_id = ?))
+			AND (expires_at IS NULL OR expires_at > ?)
+	`
+
+	var muted bool
+	if err := db.conn.QueryRow(query, itemID, workspaceID, now).Scan(&muted); err != nil {
+		return false, err
+	}
+	return muted, nil
+}
+
+// GetLongRunningJobAlerts returns in-progress jobs not yet alerted that have run for at least threshold,
+// or for factor times the average duration of their item's completed runs over the last 30 days
+// A zero threshold or factor disables that check
+func (db *Database) GetLongRunningJobAlerts(now time.Time, threshold time.Duration, factor float64) ([]RunningJobAlert, error) {
+	return db.getRunningJobAlerts(AlertLongRunning, []string{"InProgress"}, now, threshold, factor)
+}
+
+// GetStuckJobAlerts returns queued or in-progress jobs not yet alerted as stuck that have taken factor times
+// the average duration of their item's completed runs over the last 30 days
+// Items without 3 completed runs in that time have no baseline and are never reported
+func (db *Database) GetStuckJobAlerts(now time.Time, factor float64) ([]RunningJobAlert, error) {
+	return db.getRunningJobAlerts(AlertStuck, []string{"NotStarted", "InProgress"}, now, 0, factor)
+}
+
+// getRunningJobAlerts returns unfinished jobs in one of statuses without an alertType alert that have run
+// for at least threshold or factor times their item's average duration
+func (db *Database) getRunningJobAlerts(alertType string, statuses []string, now time.Time, threshold time.Duration, factor float64) ([]RunningJobAlert, error) {
+	if threshold <= 0 && factor <= 0 {
+		return nil, nil
+	}
+
+	query := `
+		WITH baselines AS (
+			SELECT item_id, AVG(duration_ms) AS avg_duration_ms
+			FROM job_instances
+			WHERE status = 'Completed'
+				AND duration_ms IS NOT NULL
+				AND start_time >= ? - INTERVAL 30 DAYS
+			GROUP BY item_id
+			HAVING COUNT(*) >= 3
+		),
+		running AS (
+			SELECT j.*, date_diff('millisecond', j.start_time, ?) AS elapsed_ms, b.avg_duration_ms
+			FROM job_instances j
+			LEFT JOIN baselines b ON j.item_id = b.item_id
+			WHERE list_contains(?, j.status) AND j.end_time IS NULL
+				AND NOT EXISTS (
+					SELECT 1 FROM job_alerts a WHERE a.job_id = j.id AND a.alert_type = ?
+				)
+		)
+		SELECT r.id, r.workspace_id, r.item_id, r.job_type, r.status, r.start_time,
+			i.display_name, i.type, w.display_name, r.elapsed_ms, r.avg_duration_ms
+		FROM running r
+		LEFT JOIN items i ON r.item_id = i.id
+		LEFT JOIN workspaces w ON r.workspace_id = w.id
+		WHERE (? > 0 AND r.elapsed_ms >= ?)
+			OR (? > 0 AND r.avg_duration_ms IS NOT NULL AND r.elapsed_ms >= ? * r.avg_duration_ms)
+		ORDER BY r.start_time
+	`
+
+	thresholdMs := threshold.Milliseconds()
+	rows, err := db.conn.Query(query, now, now, statuses, alertType, thresholdMs, thresholdMs, factor, factor)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var alerts []RunningJobAlert
+	for rows.Next() {
+		var a RunningJobAlert
+		var baseline sql.NullFloat64
+		if err := rows.Scan(&a.Job.ID, &a.Job.WorkspaceID, &a.Job.ItemID, &a.Job.JobType, &a.Job.Status, &a.Job.StartTime,
+			&a.Job.ItemDisplayName, &a.Job.ItemType, &a.Job.WorkspaceName, &a.ElapsedMs, &baseline); err != nil {
+			return nil, err
+		}
+		if baseline.Valid {
+			a.BaselineMs = &baseline.Float64
+		}
+		alerts = append(alerts, a)
+	}
+	return alerts, rows.Err()
+}
+
+// MarkJobAlerted records that an alert of alertType was raised for a job
+func (db *Database) MarkJobAlerted(jobID, alertType string, at time.Time) error {
+	_, err := db.conn.Exec(`
+		INSERT INTO job_alerts (job_id, alert_type, alerted_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT (job_id, alert_type) DO NOTHING
+	`, jobID, alertType, at)
+	return err
+}
@@ -1,17 +1,42 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"better-fabric-monitor/internal/logger"
 )
 
-// ExportTablesToParquet exports all tables to Parquet files
-func (db *Database) ExportTablesToParquet(parquetPath string) ([]ParquetExportStats, error) {
+// parquetTables are the tables mirrored to Parquet, in export order
+// Every table the bindings read must be listed, since the read-only replica only has views for these
+var parquetTables = []string{
+	"workspaces", "items", "job_instances", "notebook_sessions", "sync_metadata", "sync_metrics",
+	"workspace_poll_schedule", "notification_history", "notification_mutes", "favorites", "job_alerts",
+	"job_anomalies", "job_duration_regressions", "copy_activity_metrics", "environment_mappings",
+	"freshness_thresholds", "workspace_settings",
+}
+
+// upsertedTables have an updated_at column that sync upserts bump even when nothing else changed
+var upsertedTables = map[string]bool{"workspaces": true, "items": true, "job_instances": true, "notebook_sessions": true}
+
+// jobPartitionTable is written as one Parquet file per start_time month under a directory of the same name
+const jobPartitionTable = "job_instances"
+
+// jobPartitionKey maps a job_instances row to its partition; jobs without a start time share one file
+const jobPartitionKey = "COALESCE(strftime(start_time, '%Y-%m'), 'unknown')"
+
+// emptyJobPartition keeps the partition glob matching when job_instances has no rows
+const emptyJobPartition = "empty"
+
+// ExportTablesToParquet exports changed tables to Parquet files
+// Tables whose content matches the last export are skipped, and job_instances only rewrites changed months
+// Tables not yet started when ctx is cancelled are skipped and ctx's error is returned
+func (db *Database) ExportTablesToParquet(ctx context.Context, parquetPath string) ([]ParquetExportStats, error) {
 	// Get absolute path for Parquet files
 	absParquetPath, err := filepath.Abs(parquetPath)
 	if err != nil {
@@ -23,58 +48,300 @@ func (db *Database) ExportTablesToParquet(parquetPath string) ([]ParquetExportSt
 		return nil, fmt.Errorf("failed to create parquet directory: %w", err)
 	}
 
-	tables := []string{"workspaces", "items", "job_instances", "notebook_sessions", "sync_metadata"}
-	stats := make([]ParquetExportStats, 0, len(tables))
+	exported, err := db.getExportFingerprints()
+	if err != nil {
+		return nil, err
+	}
 
-	for _, tableName := range tables {
-		start := time.Now()
-		stat := ParquetExportStats{
-			TableName: tableName,
-			Success:   false,
+	stats := make([]ParquetExportStats, 0, len(parquetTables))
+	for _, tableName := range parquetTables {
+		if err := ctx.Err(); err != nil {
+			return stats, err
 		}
 
-		// Build Parquet file path
-		parquetFile := filepath.Join(absParquetPath, fmt.Sprintf("%s.parquet", tableName))
+		var stat ParquetExportStats
+		if tableName == jobPartitionTable {
+			stat = db.exportJobPartitions(ctx, absParquetPath, exported)
+		} else {
+			stat = db.exportTable(tableName, absParquetPath, exported)
+		}
+		stats = append(stats, stat)
+	}
 
-		// Delete existing Parquet file if it exists
-		if err := os.Remove(parquetFile); err != nil && !os.IsNotExist(err) {
-			stat.ErrorMessage = fmt.Sprintf("failed to delete existing parquet file: %v", err)
-			stat.DurationMs = time.Since(start).Milliseconds()
-			stats = append(stats, stat)
-			logger.Log("[PARQUET] ERROR: Failed to delete existing %s.parquet: %v\n", tableName, err)
-			continue
+	return stats, nil
+}
+
+// exportTable writes a whole table to <dir>/<table>.parquet unless it is unchanged since the last export
+func (db *Database) exportTable(tableName, dir string, exported map[string]string) ParquetExportStats {
+	start := time.Now()
+	stat := ParquetExportStats{
+		TableName: tableName,
+		Success:   false,
+	}
+
+	count, fingerprint, err := db.tableFingerprint(tableName)
+	if err != nil {
+		stat.ErrorMessage = fmt.Sprintf("failed to fingerprint table: %v", err)
+		stat.DurationMs = time.Since(start).Milliseconds()
+		logger.Error("[PARQUET] Failed to fingerprint table", "table", tableName, logger.Err(err))
+		return stat
+	}
+	stat.RecordCount = count
+
+	parquetFile := filepath.Join(dir, fmt.Sprintf("%s.parquet", tableName))
+	if exported[tableName] == fingerprint && fileExists(parquetFile) {
+		stat.Success = true
+		stat.Skipped = true
+		stat.DurationMs = time.Since(start).Milliseconds()
+		logger.Debug("[PARQUET] Skipped table unchanged since last export", "table", tableName)
+		return stat
+	}
+
+	if err := db.copyToParquet(fmt.Sprintf("SELECT * FROM %s", tableName), parquetFile); err != nil {
+		stat.ErrorMessage = fmt.Sprintf("failed to export: %v", err)
+		stat.DurationMs = time.Since(start).Milliseconds()
+		logger.Error("[PARQUET] Failed to export table", "table", tableName, logger.Err(err))
+		return stat
+	}
+
+	if err := db.saveExportFingerprint(tableName, fingerprint); err != nil {
+		logger.Warn("[PARQUET] Failed to record export", "table", tableName, logger.Err(err))
+	}
+
+	stat.Success = true
+	stat.DurationMs = time.Since(start).Milliseconds()
+	logger.Info("[PARQUET] Exported table", "table", tableName, "records", count, "durationMs", stat.DurationMs)
+	return stat
+}
+
+// exportJobPartitions writes job_instances as <dir>/job_instances/<YYYY-MM>.parquet
+// Only months whose content changed are rewritten, and files for months that no longer have jobs are removed
+func (db *Database) exportJobPartitions(ctx context.Context, dir string, exported map[string]string) ParquetExportStats {
+	start := time.Now()
+	stat := ParquetExportStats{
+		TableName: jobPartitionTable,
+		Success:   false,
+	}
+	fail := func(message string, err error) ParquetExportStats {
+		stat.ErrorMessage = fmt.Sprintf("%s: %v", message, err)
+		stat.DurationMs = time.Since(start).Milliseconds()
+		logger.Error("[PARQUET] "+message, "table", jobPartitionTable, logger.Err(err))
+		return stat
+	}
+
+	partitionDir := filepath.Join(dir, jobPartitionTable)
+	if err := os.MkdirAll(partitionDir, 0755); err != nil {
+		return fail("failed to create partition directory", err)
+	}
+
+	partitions, err := db.jobPartitionFingerprints()
+	if err != nil {
+		return fail("failed to fingerprint partitions", err)
+	}
+	if len(partitions) == 0 {
+		partitions = []partitionFingerprint{{Key: emptyJobPartition, Fingerprint: "0:0"}}
+	}
+
+	written := 0
+	current := make(map[string]bool, len(partitions))
+	for _, partition := range partitions {
+		if err := ctx.Err(); err != nil {
+			return fail("export cancelled", err)
 		}
 
-		// Get record count
-		var count int
-		err := db.conn.QueryRow(fmt.Sprintf("SELECT COUNT(*) FROM %s", tableName)).Scan(&count)
-		if err != nil {
-			stat.ErrorMessage = fmt.Sprintf("failed to count records: %v", err)
-			stat.DurationMs = time.Since(start).Milliseconds()
-			stats = append(stats, stat)
-			logger.Log("[PARQUET] ERROR: Failed to count records in %s: %v\n", tableName, err)
+		name := jobPartitionTable + "/" + partition.Key
+		current[name] = true
+		stat.RecordCount += partition.Count
+
+		parquetFile := filepath.Join(partitionDir, partition.Key+".parquet")
+		if exported[name] == partition.Fingerprint && fileExists(parquetFile) {
 			continue
 		}
-		stat.RecordCount = count
 
-		// Export to Parquet
-		query := fmt.Sprintf("COPY (SELECT * FROM %s) TO '%s' (FORMAT PARQUET)", tableName, parquetFile)
-		_, err = db.conn.Exec(query)
-		if err != nil {
-			stat.ErrorMessage = fmt.Sprintf("failed to export: %v", err)
-			stat.DurationMs = time.Since(start).Milliseconds()
-			stats = append(stats, stat)
-			logger.Log("[PARQUET] ERROR: Failed to export %s: %v\n", tableName, err)
+		query := fmt.Sprintf("SELECT * FROM %s WHERE %s = '%s'", jobPartitionTable, jobPartitionKey, partition.Key)
+		if partition.Key == emptyJobPartition {
+			query = fmt.Sprintf("SELECT * FROM %s WHERE false", jobPartitionTable)
+		}
+		if err := db.copyToParquet(query, parquetFile); err != nil {
+			return fail(fmt.Sprintf("failed to export partition %s", partition.Key), err)
+		}
+		if err := db.saveExportFingerprint(name, partition.Fingerprint); err != nil {
+			logger.Warn("[PARQUET] Failed to record export", "table", name, logger.Err(err))
+		}
+		written++
+	}
+
+	removed, err := db.removeStalePartitions(partitionDir, exported, current)
+	if err != nil {
+		return fail("failed to remove old partitions", err)
+	}
+
+	stat.Success = true
+	stat.Skipped = written == 0 && removed == 0
+	stat.DurationMs = time.Since(start).Milliseconds()
+	if stat.Skipped {
+		logger.Debug("[PARQUET] Skipped table unchanged since last export", "table", jobPartitionTable)
+	} else {
+		logger.Info("[PARQUET] Exported table", "table", jobPartitionTable, "partitions", len(partitions), "written", written,
+			"removed", removed, "records", stat.RecordCount, "durationMs", stat.DurationMs)
+	}
+	return stat
+}
+
+// removeStalePartitions deletes partition files and fingerprints that are not in current
+func (db *Database) removeStalePartitions(partitionDir string, exported map[string]string, current map[string]bool) (int, error) {
+	entries, err := os.ReadDir(partitionDir)
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for _, entry := range entries {
+		if entry.IsDir() {
 			continue
 		}
+		key, ok := strings.CutSuffix(entry.Name(), ".parquet")
+		if ok && current[jobPartitionTable+"/"+key] {
+			continue
+		}
+		if err := os.Remove(filepath.Join(partitionDir, entry.Name())); err != nil && !os.IsNotExist(err) {
+			return removed, err
+		}
+		if ok {
+			removed++
+		}
+	}
 
-		stat.Success = true
-		stat.DurationMs = time.Since(start).Milliseconds()
-		stats = append(stats, stat)
-		logger.Log("[PARQUET] Exported %s: %d records in %dms\n", tableName, count, stat.DurationMs)
+	for name := range exported {
+		if strings.HasPrefix(name, jobPartitionTable+"/") && !current[name] {
+			if _, err := db.conn.Exec(`DELETE FROM parquet_exports WHERE name = ?`, name); err != nil {
+				return removed, err
+			}
+		}
 	}
+	return removed, nil
+}
 
-	return stats, nil
+// copyToParquet writes the result of query to parquetFile
+// The file is written next to the target and renamed into place so readers never see a partial file
+func (db *Database) copyToParquet(query, parquetFile string) error {
+	return db.copyToFile(query, parquetFile, "FORMAT PARQUET")
+}
+
+// copyToFile writes the result of query to file with the given COPY options, replacing it only once complete
+func (db *Database) copyToFile(query, file, options string) error {
+	tmpFile := file + ".tmp"
+	if err := os.Remove(tmpFile); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if _, err := db.conn.Exec(fmt.Sprintf("COPY (%s) TO '%s' (%s)", query, escapeSQLString(tmpFile), options)); err != nil {
+		os.Remove(tmpFile)
+		return err
+	}
+	if err := os.Rename(tmpFile, file); err != nil {
+		os.Remove(tmpFile)
+		return err
+	}
+	return nil
+}
+
+// fingerprintColumns selects the columns whose content decides whether a table changed
+// updated_at is left out of upserted tables because upserts bump it even when nothing else changed
+func fingerprintColumns(tableName string) string {
+	if upsertedTables[tableName] {
+		return "* EXCLUDE (updated_at)"
+	}
+	return "*"
+}
+
+// tableFingerprint returns a table's row count and an order-independent hash of its content
+func (db *Database) tableFingerprint(tableName string) (int, string, error) {
+	query := fmt.Sprintf(`
+		SELECT COUNT(*), CAST(COALESCE(bit_xor(hash(r)), 0) AS VARCHAR)
+		FROM (SELECT %s FROM %s) r
+	`, fingerprintColumns(tableName), tableName)
+
+	var count int
+	var hash string
+	if err := db.conn.QueryRow(query).Scan(&count, &hash); err != nil {
+		return 0, "", err
+	}
+	return count, fmt.Sprintf("%d:%s", count, hash), nil
+}
+
+// partitionFingerprint is the content fingerprint of one job_instances partition
+type partitionFingerprint struct {
+	Key         string
+	Count       int
+	Fingerprint string
+}
+
+// jobPartitionFingerprints fingerprints every job_instances partition
+func (db *Database) jobPartitionFingerprints() ([]partitionFingerprint, error) {
+	query := fmt.Sprintf(`
+		SELECT partition_key, COUNT(*), CAST(COALESCE(bit_xor(hash(r)), 0) AS VARCHAR)
+		FROM (
+			SELECT %s AS partition_key, r
+			FROM (SELECT %s FROM %s) r
+		)
+		GROUP BY partition_key
+		ORDER BY partition_key
+	`, jobPartitionKey, fingerprintColumns(jobPartitionTable), jobPartitionTable)
+
+	rows, err := db.conn.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var partitions []partitionFingerprint
+	for rows.Next() {
+		var partition partitionFingerprint
+		var hash string
+		if err := rows.Scan(&partition.Key, &partition.Count, &hash); err != nil {
+			return nil, err
+		}
+		partition.Fingerprint = fmt.Sprintf("%d:%s", partition.Count, hash)
+		partitions = append(partitions, partition)
+	}
+	return partitions, rows.Err()
+}
+
+// getExportFingerprints returns the fingerprint recorded for each table or partition at its last export
+func (db *Database) getExportFingerprints() (map[string]string, error) {
+	rows, err := db.conn.Query(`SELECT name, fingerprint FROM parquet_exports`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read export fingerprints: %w", err)
+	}
+	defer rows.Close()
+
+	fingerprints := make(map[string]string)
+	for rows.Next() {
+		var name, fingerprint string
+		if err := rows.Scan(&name, &fingerprint); err != nil {
+			return nil, fmt.Errorf("failed to scan export fingerprint: %w", err)
+		}
+		fingerprints[name] = fingerprint
+	}
+	return fingerprints, rows.Err()
+}
+
+// saveExportFingerprint records the fingerprint a table or partition was exported with
+func (db *Database) saveExportFingerprint(name, fingerprint string) error {
+	_, err := db.conn.Exec(`
+		INSERT INTO parquet_exports (name, fingerprint, exported_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT (name) DO UPDATE SET
+			fingerprint = EXCLUDED.fingerprint,
+			exported_at = EXCLUDED.exported_at
+	`, name, fingerprint, time.Now().UTC())
+	return err
+}
+
+// fileExists reports whether path exists
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
 }
 
 // CreateReadOnlyDatabase creates a read-only replica database with views to Parquet files
@@ -90,21 +357,40 @@ func CreateReadOnlyDatabase(readOnlyPath, parquetPath string) error {
 		return fmt.Errorf("failed to get absolute parquet path: %w", err)
 	}
 
-	// Check if read-only database already exists
-	if _, err := os.Stat(absReadOnlyPath); err == nil {
-		// Database already exists, no need to recreate views
-		logger.Log("[PARQUET] Read-only database already exists at: %s\n", absReadOnlyPath)
-		return nil
+	// Replicas created before job_instances was partitioned point at a single file; rebuild them
+	legacyJobsFile := filepath.Join(absParquetPath, fmt.Sprintf("%s.parquet", jobPartitionTable))
+	if fileExists(legacyJobsFile) {
+		logger.Info("[PARQUET] Replacing single-file export with partitions", "table", jobPartitionTable)
+		if err := os.Remove(absReadOnlyPath); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove outdated readonly database: %w", err)
+		}
+		os.Remove(absReadOnlyPath + ".wal")
+		if err := os.Remove(legacyJobsFile); err != nil {
+			return fmt.Errorf("failed to remove legacy %s parquet file: %w", jobPartitionTable, err)
+		}
 	}
 
-	// Ensure directory exists
-	dir := filepath.Dir(absReadOnlyPath)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return fmt.Errorf("failed to create readonly database directory: %w", err)
+	// An existing read-only database only needs views for tables added since it was created
+	tables := parquetTables
+	if fileExists(absReadOnlyPath) {
+		tables, err = missingReplicaViews(absReadOnlyPath)
+		if err != nil {
+			return fmt.Errorf("failed to check readonly database views: %w", err)
+		}
+		if len(tables) == 0 {
+			logger.Debug("[PARQUET] Read-only database already exists", "path", absReadOnlyPath)
+			return nil
+		}
+		logger.Info("[PARQUET] Adding views to read-only database", "path", absReadOnlyPath, "tables", tables)
+	} else {
+		// Ensure directory exists
+		dir := filepath.Dir(absReadOnlyPath)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create readonly database directory: %w", err)
+		}
+		logger.Info("[PARQUET] Creating read-only database", "path", absReadOnlyPath)
 	}
 
-	logger.Log("[PARQUET] Creating read-only database at: %s\n", absReadOnlyPath)
-
 	// Open connection to create read-only database
 	conn, err := sql.Open("duckdb", absReadOnlyPath)
 	if err != nil {
@@ -113,26 +399,65 @@ func CreateReadOnlyDatabase(readOnlyPath, parquetPath string) error {
 	defer conn.Close()
 
 	// Create views for each table
-	tables := []string{"workspaces", "items", "job_instances", "notebook_sessions", "sync_metadata"}
-
 	for _, tableName := range tables {
 		parquetFile := filepath.Join(absParquetPath, fmt.Sprintf("%s.parquet", tableName))
+		source := fmt.Sprintf("read_parquet('%s')", parquetFile)
+		if tableName == jobPartitionTable {
+			parquetFile = filepath.Join(absParquetPath, jobPartitionTable, "*.parquet")
+			source = fmt.Sprintf("read_parquet('%s', union_by_name = true)", filepath.ToSlash(parquetFile))
+		}
 
 		// Verify Parquet file exists
-		if _, err := os.Stat(parquetFile); os.IsNotExist(err) {
+		if matches, _ := filepath.Glob(parquetFile); len(matches) == 0 {
 			return fmt.Errorf("parquet file not found for table %s: %s", tableName, parquetFile)
 		}
 
 		// Create view that reads from Parquet file
-		query := fmt.Sprintf("CREATE VIEW %s AS SELECT * FROM read_parquet('%s')", tableName, parquetFile)
+		query := fmt.Sprintf("CREATE VIEW %s AS SELECT * FROM %s", tableName, source)
 		_, err := conn.Exec(query)
 		if err != nil {
 			return fmt.Errorf("failed to create view for %s: %w", tableName, err)
 		}
 
-		logger.Log("[PARQUET] Created view for %s\n", tableName)
+		logger.Debug("[PARQUET] Created view", "table", tableName)
 	}
 
-	logger.Log("[PARQUET] Read-only database created successfully\n")
+	logger.Info("[PARQUET] Read-only database created")
 	return nil
 }
+
+// missingReplicaViews returns the tables in parquetTables that the read-only database at path has no view for
+// The database is opened read-only so the check does not conflict with an instance reading the replica
+func missingReplicaViews(path string) ([]string, error) {
+	conn, err := sql.Open("duckdb", fmt.Sprintf("%s?access_mode=READ_ONLY", path))
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	rows, err := conn.Query(`SELECT view_name FROM duckdb_views() WHERE NOT internal`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	views := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		views[name] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var missing []string
+	for _, tableName := range parquetTables {
+		if !views[tableName] {
+			missing = append(missing, tableName)
+		}
+	}
+	return missing, nil
+}
@@ -0,0 +1,64 @@
+package db
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// exportReplica exports database to Parquet under dir and creates or updates the read-only database there
+func exportReplica(t *testing.T, database *Database, dir string) string {
+	t.Helper()
+	parquetPath := filepath.Join(dir, "parquet")
+	stats, err := database.ExportTablesToParquet(context.Background(), parquetPath)
+	if err != nil {
+		t.Fatalf("ExportTablesToParquet: %v", err)
+	}
+	for _, stat := range stats {
+		if !stat.Success {
+			t.Fatalf("export of %s failed: %s", stat.TableName, stat.ErrorMessage)
+		}
+	}
+	readOnlyPath := filepath.Join(dir, "readonly.db")
+	if err := CreateReadOnlyDatabase(readOnlyPath, parquetPath); err != nil {
+		t.Fatalf("CreateReadOnlyDatabase: %v", err)
+	}
+	return readOnlyPath
+}
+
+// Replicas created before a table was mirrored gain its view on the next export
+func TestReadOnlyReplicaHasEveryTable(t *testing.T) {
+	database := newTestDatabase(t)
+	seedRuns(t, database, "ws-a", "item-a", "DataPipeline", "Completed", "Failed")
+	if err := database.SaveFavorite(&Favorite{TargetType: FavoriteTargetItem, TargetID: "item-a", StarredAt: time.Now().UTC()}); err != nil {
+		t.Fatalf("SaveFavorite: %v", err)
+	}
+
+	dir := t.TempDir()
+	allTables := parquetTables
+	parquetTables = allTables[:5]
+	exportReplica(t, database, dir)
+	parquetTables = allTables
+	readOnlyPath := exportReplica(t, database, dir)
+
+	replica, err := OpenReadOnlyDatabase(readOnlyPath)
+	if err != nil {
+		t.Fatalf("OpenReadOnlyDatabase: %v", err)
+	}
+	defer replica.Close()
+
+	for _, table := range parquetTables {
+		var count int
+		if err := replica.conn.QueryRow("SELECT COUNT(*) FROM " + table).Scan(&count); err != nil {
+			t.Errorf("%s: %v", table, err)
+		}
+	}
+	favorites, err := replica.GetFavorites()
+	if err != nil {
+		t.Fatalf("GetFavorites: %v", err)
+	}
+	if len(favorites) != 1 || favorites[0].TargetID != "item-a" {
+		t.Errorf("favorites = %v, want item-a", favorites)
+	}
+}
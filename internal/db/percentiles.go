@@ -0,0 +1,52 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// GetDurationPercentiles returns the P50 and P90 duration of the completed runs of each day of the last days,
+// of one item when itemID is set, with the trend fitted through each percentile's daily values
+func (db *Database) GetDurationPercentiles(days int, itemID string, workspaceIDs []string, itemTypes []string, itemNameSearch string) (*DurationPercentileSeries, error) {
+	filterClause, filterArgs := buildFilterConditions(workspaceIDs, itemTypes, itemNameSearch)
+	query := fmt.Sprintf(`
+		WITH daily AS (
+			SELECT DATE_TRUNC('day', j.start_time)::DATE AS date,
+				COUNT(*) AS runs,
+				quantile_cont(j.duration_ms, 0.5) AS p50,
+				quantile_cont(j.duration_ms, 0.9) AS p90,
+				MAX(j.duration_ms) AS max_duration_ms
+			FROM job_instances j
+			LEFT JOIN items i ON j.item_id = i.id
+			WHERE j.status = 'Completed' AND j.duration_ms IS NOT NULL
+				AND j.start_time >= CURRENT_TIMESTAMP - INTERVAL (? || ' days')
+				AND (? = '' OR j.item_id = ?)
+			%s
+			GROUP BY DATE_TRUNC('day', j.start_time)::DATE
+		)
+		SELECT CAST(date AS VARCHAR), runs, p50, p90, max_duration_ms,
+			regr_slope(p50, epoch(date)) OVER () * 86400,
+			regr_slope(p90, epoch(date)) OVER () * 86400
+		FROM daily
+		ORDER BY date
+	`, filterClause)
+
+	args := append([]interface{}{fmt.Sprintf("%d", days), itemID, itemID}, filterArgs...)
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	series := &DurationPercentileSeries{Days: []DailyDurationPercentiles{}}
+	for rows.Next() {
+		var d DailyDurationPercentiles
+		var p50Trend, p90Trend sql.NullFloat64
+		if err := rows.Scan(&d.Date, &d.Runs, &d.P50DurationMs, &d.P90DurationMs, &d.MaxDurationMs, &p50Trend, &p90Trend); err != nil {
+			return nil, err
+		}
+		series.P50TrendMsPerDay, series.P90TrendMsPerDay = p50Trend.Float64, p90Trend.Float64
+		series.Days = append(series.Days, d)
+	}
+	return series, rows.Err()
+}
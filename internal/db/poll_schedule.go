@@ -0,0 +1,187 @@
+package db
+
+import (
+	"database/sql"
+	"time"
+)
+
+// GetWorkspaceActivity returns the job activity of each workspace that has jobs
+// RecentJobs counts jobs started after since
+func (db *Database) GetWorkspaceActivity(since time.Time) (map[string]WorkspaceActivity, error) {
+	query := `
+		SELECT
+			workspace_id,
+			COUNT(*) FILTER (WHERE end_time IS NULL AND status <> ?),
+			COUNT(*) FILTER (WHERE start_time > ?),
+			MAX(start_time)
+		FROM job_instances
+		GROUP BY workspace_id
+	`
+
+	rows, err := db.conn.Query(query, JobStatusStale, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	activity := make(map[string]WorkspaceActivity)
+	for rows.Next() {
+		var workspaceID string
+		var a WorkspaceActivity
+		var lastJobAt sql.NullTime
+		if err := rows.Scan(&workspaceID, &a.RunningJobs, &a.RecentJobs, &lastJobAt); err != nil {
+			return nil, err
+		}
+		if lastJobAt.Valid {
+			a.LastJobAt = &lastJobAt.Time
+		}
+		activity[workspaceID] = a
+	}
+	return activity, rows.Err()
+}
+
+// SaveWorkspacePollSchedules records the polling decisions for workspaces that were just synced
+func (db *Database) SaveWorkspacePollSchedules(schedules []WorkspacePollSchedule) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, s := range schedules {
+		_, err := tx.Exec(`
+			INSERT INTO workspace_poll_schedule (
+				workspace_id, activity, running_jobs, recent_jobs, last_job_at,
+				interval_seconds, last_polled_at, next_poll_at
+			)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT (workspace_id) DO UPDATE SET
+				activity = EXCLUDED.activity,
+				running_jobs = EXCLUDED.running_jobs,
+				recent_jobs = EXCLUDED.recent_jobs,
+				last_job_at = EXCLUDED.last_job_at,
+				interval_seconds = EXCLUDED.interval_seconds,
+				last_polled_at = EXCLUDED.last_polled_at,
+				next_poll_at = EXCLUDED.next_poll_at
+		`, s.WorkspaceID, s.Activity, s.RunningJobs, s.RecentJobs, s.LastJobAt,
+			s.IntervalSeconds, s.LastPolledAt, s.NextPollAt)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// GetWorkspacePollSchedules returns the polling decision of every scheduled workspace, soonest first
+func (db *Database) GetWorkspacePollSchedules() ([]WorkspacePollSchedule, error) {
+	query := `
+		SELECT s.workspace_id, COALESCE(w.display_name, s.workspace_id), s.activity, s.running_jobs, s.recent_jobs,
+			s.last_job_at, s.interval_seconds, s.last_polled_at, s.next_poll_at
+		FROM workspace_poll_schedule s
+		LEFT JOIN workspaces w ON w.id = s.workspace_id
+		ORDER BY s.next_poll_at, w.display_name
+	`
+
+	rows, err := db.conn.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var schedules []WorkspacePollSchedule
+	for rows.Next() {
+		var s WorkspacePollSchedule
+		var lastJobAt sql.NullTime
+		if err := rows.Scan(&s.WorkspaceID, &s.WorkspaceName, &s.Activity, &s.RunningJobs, &s.RecentJobs,
+			&lastJobAt, &s.IntervalSeconds, &s.LastPolledAt, &s.NextPollAt); err != nil {
+			return nil, err
+		}
+		if lastJobAt.Valid {
+			s.LastJobAt = &lastJobAt.Time
+		}
+		schedules = append(schedules, s)
+	}
+	return schedules, rows.Err()
+}
+
+// GetNextPollTime returns when the next scheduled workspace poll is due, or nil if nothing is scheduled yet
+func (db *Database) GetNextPollTime() (*time.Time, error) {
+	var next sql.NullTime
+	if err := db.conn.QueryRow(`SELECT MIN(next_poll_at) FROM workspace_poll_schedule`).Scan(&next); err != nil {
+		return nil, err
+	}
+	if !next.Valid {
+		return nil, nil
+	}
+	return &next.Time, nil
+}
+
+// GetWorkspaceWatermarks returns the incremental sync start time of each workspace
+// Like GetMaxJobStartTime but per workspace: the earliest in-progress job, else the latest completed job,
+// else when the workspace was last polled. Workspaces synced at different times each resume from their own point.
+// A watermark held by a cancelled sync caps every workspace.
+func (db *Database) GetWorkspaceWatermarks() (map[string]time.Time, error) {
+	watermarks := make(map[string]time.Time)
+
+	// Workspaces without jobs resume from their last poll
+	rows, err := db.conn.Query(`SELECT workspace_id, last_polled_at FROM workspace_poll_schedule`)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var workspaceID string
+		var lastPolled time.Time
+		if err := rows.Scan(&workspaceID, &lastPolled); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		watermarks[workspaceID] = lastPolled
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = db.conn.Query(`
+		SELECT
+			workspace_id,
+			MIN(start_time) FILTER (WHERE end_time IS NULL AND status <> ?),
+			MAX(start_time) FILTER (WHERE end_time IS NOT NULL)
+		FROM job_instances
+		GROUP BY workspace_id
+	`, JobStatusStale)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var workspaceID string
+		var inProgress, completed sql.NullTime
+		if err := rows.Scan(&workspaceID, &inProgress, &completed); err != nil {
+			return nil, err
+		}
+		switch {
+		case inProgress.Valid:
+			watermarks[workspaceID] = inProgress.Time
+		case completed.Valid:
+			watermarks[workspaceID] = completed.Time
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var held sql.NullTime
+	err = db.conn.QueryRow(`SELECT MIN(last_sync_time) FROM sync_metadata WHERE sync_type = ?`, syncTypeWatermarkHold).Scan(&held)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+	if held.Valid {
+		for workspaceID, watermark := range watermarks {
+			if held.Time.Before(watermark) {
+				watermarks[workspaceID] = held.Time
+			}
+		}
+	}
+	return watermarks, nil
+}
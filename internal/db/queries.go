@@ -5,29 +5,32 @@ import (
 	"database/sql/driver"
 	"encoding/json"
 	"fmt"
+	"os"
 	"strings"
 	"time"
 )
 
 // SaveWorkspace saves or updates a workspace
+// capacity_id is only overwritten when the workspace carries a capacity
 func (db *Database) SaveWorkspace(workspace *Workspace) error {
 	query := `
-		INSERT INTO workspaces (id, display_name, type, description, updated_at)
-		VALUES (?, ?, ?, ?, get_current_timestamp())
+		INSERT INTO workspaces (id, display_name, type, description, updated_at, capacity_id)
+		VALUES (?, ?, ?, ?, get_current_timestamp(), ?)
 		ON CONFLICT(id) DO UPDATE SET
 			display_name = EXCLUDED.display_name,
 			type = EXCLUDED.type,
 			description = EXCLUDED.description,
-			updated_at = get_current_timestamp()
+			updated_at = get_current_timestamp(),
+			capacity_id = COALESCE(EXCLUDED.capacity_id, workspaces.capacity_id)
 	`
-	_, err := db.conn.Exec(query, workspace.ID, workspace.DisplayName, workspace.Type, workspace.Description)
+	_, err := db.conn.Exec(query, workspace.ID, workspace.DisplayName, workspace.Type, workspace.Description, workspace.CapacityID)
 	return err
 }
 
 // GetWorkspaces retrieves all workspaces
 func (db *Database) GetWorkspaces() ([]Workspace, error) {
 	query := `
-		SELECT id, display_name, type, description, created_at, updated_at
+		SELECT id, display_name, type, description, capacity_id, created_at, updated_at
 		FROM workspaces
 		ORDER BY display_name
 	`
@@ -40,7 +43,7 @@ func (db *Database) GetWorkspaces() ([]Workspace, error) {
 	var workspaces []Workspace
 	for rows.Next() {
 		var w Workspace
-		err := rows.Scan(&w.ID, &w.DisplayName, &w.Type, &w.Description, &w.CreatedAt, &w.UpdatedAt)
+		err := rows.Scan(&w.ID, &w.DisplayName, &w.Type, &w.Description, &w.CapacityID, &w.CreatedAt, &w.UpdatedAt)
 		if err != nil {
 			return nil, err
 		}
@@ -50,24 +53,26 @@ func (db *Database) GetWorkspaces() ([]Workspace, error) {
 }
 
 // SaveItem saves or updates an item
+// last_discovered is only overwritten when the item carries a new discovery time
 func (db *Database) SaveItem(item *Item) error {
 	query := `
-		INSERT INTO items (id, workspace_id, display_name, type, description, updated_at)
-		VALUES (?, ?, ?, ?, ?, get_current_timestamp())
+		INSERT INTO items (id, workspace_id, display_name, type, description, updated_at, last_discovered)
+		VALUES (?, ?, ?, ?, ?, get_current_timestamp(), ?)
 		ON CONFLICT(id) DO UPDATE SET
 			display_name = EXCLUDED.display_name,
 			type = EXCLUDED.type,
 			description = EXCLUDED.description,
-			updated_at = get_current_timestamp()
+			updated_at = get_current_timestamp(),
+			last_discovered = COALESCE(EXCLUDED.last_discovered, last_discovered)
 	`
-	_, err := db.conn.Exec(query, item.ID, item.WorkspaceID, item.DisplayName, item.Type, item.Description)
+	_, err := db.conn.Exec(query, item.ID, item.WorkspaceID, item.DisplayName, item.Type, item.Description, item.LastDiscovered)
 	return err
 }
 
 // GetItemsByWorkspace retrieves items for a specific workspace
 func (db *Database) GetItemsByWorkspace(workspaceID string) ([]Item, error) {
 	query := `
-		SELECT id, workspace_id, display_name, type, description, created_at, updated_at
+		SELECT id, workspace_id, display_name, type, description, created_at, updated_at, last_discovered
 		FROM items
 		WHERE workspace_id = ?
 		ORDER BY type, display_name
@@ -81,7 +86,7 @@ func (db *Database) GetItemsByWorkspace(workspaceID string) ([]Item, error) {
 	var items []Item
 	for rows.Next() {
 		var item Item
-		err := rows.Scan(&item.ID, &item.WorkspaceID, &item.DisplayName, &item.Type, &item.Description, &item.CreatedAt, &item.UpdatedAt)
+		err := rows.Scan(&item.ID, &item.WorkspaceID, &item.DisplayName, &item.Type, &item.Description, &item.CreatedAt, &item.UpdatedAt, &item.LastDiscovered)
 		if err != nil {
 			return nil, err
 		}
@@ -90,6 +95,43 @@ func (db *Database) GetItemsByWorkspace(workspaceID string) ([]Item, error) {
 	return items, rows.Err()
 }
 
+// GetItemDiscoveryTimes returns when each workspace's item list was last fetched from the API
+// Workspaces whose items were never listed are absent from the map
+func (db *Database) GetItemDiscoveryTimes() (map[string]time.Time, error) {
+	query := `
+		SELECT workspace_id, MAX(last_discovered)
+		FROM items
+		WHERE last_discovered IS NOT NULL
+		GROUP BY workspace_id
+	`
+	rows, err := db.conn.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	discovered := make(map[string]time.Time)
+	for rows.Next() {
+		var workspaceID string
+		var lastDiscovered time.Time
+		if err := rows.Scan(&workspaceID, &lastDiscovered); err != nil {
+			return nil, err
+		}
+		discovered[workspaceID] = lastDiscovered
+	}
+	return discovered, rows.Err()
+}
+
+// ExpireItemDiscovery forces the next sync to re-list items for a workspace, or for every workspace if workspaceID is empty
+func (db *Database) ExpireItemDiscovery(workspaceID string) error {
+	if workspaceID == "" {
+		_, err := db.conn.Exec(`UPDATE items SET last_discovered = NULL`)
+		return err
+	}
+	_, err := db.conn.Exec(`UPDATE items SET last_discovered = NULL WHERE workspace_id = ?`, workspaceID)
+	return err
+}
+
 // SaveJobInstances bulk inserts job instances using DuckDB appender within a single transaction
 func (db *Database) SaveJobInstances(jobs []JobInstance) error {
 	if len(jobs) == 0 {
@@ -164,26 +206,38 @@ func (db *Database) GetJobInstances(filter JobFilter) ([]JobInstance, error) {
 	}
 
 	query := fmt.Sprintf(`
-		SELECT j.id, j.workspace_id, j.item_id, j.job_type, j.status, j.start_time,
-			   j.end_time, j.duration_ms, j.failure_reason, j.invoker_type, j.root_activity_id, j.created_at, j.updated_at,
-			   i.display_name as item_display_name, i.type as item_type,
-			   w.display_name as workspace_display_name,
-			   ns.livy_id
-		FROM job_instances j
-		LEFT JOIN items i ON j.item_id = i.id
-		LEFT JOIN workspaces w ON j.workspace_id = w.id
-		LEFT JOIN notebook_sessions ns ON j.id = ns.job_instance_id
+		SELECT %s
+		%s
 		%s
 		ORDER BY j.start_time DESC
 		%s
-	`, whereClause, limitClause)
+	`, jobInstanceColumns, jobInstanceFrom, whereClause, limitClause)
 
 	rows, err := db.conn.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
+	return scanJobInstances(rows)
+}
 
+// jobInstanceColumns are the columns scanJobInstances reads, selected from jobInstanceFrom
+const jobInstanceColumns = `
+	j.id, j.workspace_id, j.item_id, j.job_type, j.status, j.start_time,
+	j.end_time, j.duration_ms, j.failure_reason, j.invoker_type, j.root_activity_id, j.created_at, j.updated_at,
+	i.display_name as item_display_name, i.type as item_type,
+	w.display_name as workspace_display_name,
+	ns.livy_id, j.removed_upstream_at`
+
+// jobInstanceFrom joins job instances with the names of their item and workspace and their Livy session
+const jobInstanceFrom = `
+	FROM job_instances j
+	LEFT JOIN items i ON j.item_id = i.id
+	LEFT JOIN workspaces w ON j.workspace_id = w.id
+	LEFT JOIN notebook_sessions ns ON j.id = ns.job_instance_id`
+
+// scanJobInstances reads job instances selected as jobInstanceColumns
+func scanJobInstances(rows *sql.Rows) ([]JobInstance, error) {
 	var jobs []JobInstance
 	for rows.Next() {
 		var job JobInstance
@@ -196,7 +250,7 @@ func (db *Database) GetJobInstances(filter JobFilter) ([]JobInstance, error) {
 		err := rows.Scan(
 			&job.ID, &job.WorkspaceID, &job.ItemID, &job.JobType, &job.Status, &job.StartTime,
 			&job.EndTime, &job.DurationMs, &job.FailureReason, &job.InvokerType, &rootActivityID, &job.CreatedAt, &job.UpdatedAt,
-			&itemDisplayName, &itemType, &workspaceDisplayName, &livyID,
+			&itemDisplayName, &itemType, &workspaceDisplayName, &livyID, &job.RemovedUpstreamAt,
 		)
 		if err != nil {
 			return nil, err
@@ -224,6 +278,45 @@ func (db *Database) GetJobInstances(filter JobFilter) ([]JobInstance, error) {
 	return jobs, rows.Err()
 }
 
+// ReconcileItemJobs compares an item's cached runs with the job instance IDs the API just returned for it
+// Runs started before cutoff that the API no longer returns are marked as removed upstream,
+// and runs it returns again are unmarked; returns how many runs were newly marked
+func (db *Database) ReconcileItemJobs(itemID string, returnedIDs []string, cutoff time.Time) (int64, error) {
+	if returnedIDs == nil {
+		returnedIDs = []string{}
+	}
+
+	result, err := db.conn.Exec(`
+		UPDATE job_instances
+		SET removed_upstream_at = ?
+		WHERE item_id = ?
+			AND removed_upstream_at IS NULL
+			AND start_time < ?
+			AND NOT list_contains(?::VARCHAR[], id)
+	`, time.Now().UTC(), itemID, cutoff, returnedIDs)
+	if err != nil {
+		return 0, err
+	}
+	marked, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	if len(returnedIDs) > 0 {
+		_, err = db.conn.Exec(`
+			UPDATE job_instances
+			SET removed_upstream_at = NULL
+			WHERE item_id = ?
+				AND removed_upstream_at IS NOT NULL
+				AND list_contains(?::VARCHAR[], id)
+		`, itemID, returnedIDs)
+		if err != nil {
+			return marked, err
+		}
+	}
+	return marked, nil
+}
+
 // UpdateJobInstanceActivityRuns updates the activity runs for a job instance
 func (db *Database) UpdateJobInstanceActivityRuns(jobID string, activityRuns []ActivityRun) error {
 	activityRunsJSON, err := json.Marshal(activityRuns)
@@ -238,8 +331,10 @@ func (db *Database) UpdateJobInstanceActivityRuns(jobID string, activityRuns []A
 		WHERE id = ?
 	`
 
-	_, err = db.conn.Exec(query, string(activityRunsJSON), jobID)
-	return err
+	if _, err = db.conn.Exec(query, string(activityRunsJSON), jobID); err != nil {
+		return err
+	}
+	return db.saveCopyMetrics(jobID, activityRuns)
 }
 
 // GetJobInstanceWithActivities retrieves a job instance with its activity runs
@@ -248,10 +343,11 @@ func (db *Database) GetJobInstanceWithActivities(jobID string) (*JobInstance, er
 		SELECT 
 			j.id, j.workspace_id, j.item_id, j.job_type, j.status, 
 			j.start_time, j.end_time, j.duration_ms, j.failure_reason, 
-			j.invoker_type, j.root_activity_id, j.activity_runs,
+			j.invoker_type, j.root_activity_id, CAST(j.activity_runs AS VARCHAR),
 			j.created_at, j.updated_at,
 			i.display_name as item_display_name, i.type as item_type,
-			w.display_name as workspace_display_name
+			w.display_name as workspace_display_name,
+			CAST(j.failure_details AS VARCHAR)
 		FROM job_instances j
 		LEFT JOIN items i ON j.item_id = i.id
 		LEFT JOIN workspaces w ON j.workspace_id = w.id
@@ -264,6 +360,7 @@ func (db *Database) GetJobInstanceWithActivities(jobID string) (*JobInstance, er
 	var itemType sql.NullString
 	var workspaceDisplayName sql.NullString
 	var rootActivityID sql.NullString
+	var failureDetails sql.NullString
 
 	err := db.conn.QueryRow(query, jobID).Scan(
 		&job.ID, &job.WorkspaceID, &job.ItemID, &job.JobType, &job.Status,
@@ -271,6 +368,7 @@ func (db *Database) GetJobInstanceWithActivities(jobID string) (*JobInstance, er
 		&job.InvokerType, &rootActivityID, &activityRunsJSON,
 		&job.CreatedAt, &job.UpdatedAt,
 		&itemDisplayName, &itemType, &workspaceDisplayName,
+		&failureDetails,
 	)
 
 	if err != nil {
@@ -290,6 +388,9 @@ func (db *Database) GetJobInstanceWithActivities(jobID string) (*JobInstance, er
 	if rootActivityID.Valid {
 		job.RootActivityID = &rootActivityID.String
 	}
+	if failureDetails.Valid && failureDetails.String != "" {
+		job.FailureDetails = &failureDetails.String
+	}
 
 	// Unmarshal activity runs if present
 	if activityRunsJSON.Valid && activityRunsJSON.String != "" {
@@ -454,6 +555,111 @@ func (db *Database) GetChildExecutions(jobID string) ([]ChildExecution, error) {
 	return children, rows.Err()
 }
 
+// GetChildNotebookSessions finds notebooks a notebook run invoked through notebookutils.run / runMultiple
+// Referenced notebooks execute inside the caller's Spark application, so their Livy sessions share its
+// spark_application_id and run within the caller's time window. High-concurrency sessions are skipped
+// because they share a Spark application between unrelated notebooks
+func (db *Database) GetChildNotebookSessions(jobID string) ([]ChildExecution, error) {
+	query := `
+		SELECT
+			child.livy_id,
+			COALESCE(child.item_name, child_item.display_name, child.notebook_id) as activity_name,
+			child.state,
+			child.start_datetime,
+			child.end_datetime,
+			child.total_duration_ms,
+			child.cancellation_reason,
+			child.job_instance_id,
+			child.workspace_id,
+			child.notebook_id,
+			child_item.display_name,
+			EXISTS (
+				SELECT 1 FROM notebook_sessions grandchild
+				WHERE grandchild.spark_application_id = child.spark_application_id
+					AND grandchild.notebook_id NOT IN (child.notebook_id, parent.notebook_id)
+					AND grandchild.start_datetime >= child.start_datetime
+			) as has_children
+		FROM notebook_sessions parent
+		INNER JOIN notebook_sessions child
+			ON child.spark_application_id = parent.spark_application_id
+			AND child.notebook_id <> parent.notebook_id
+			AND child.start_datetime >= parent.start_datetime
+			AND (parent.end_datetime IS NULL OR child.start_datetime <= parent.end_datetime)
+		LEFT JOIN items child_item ON child_item.id = child.notebook_id
+		WHERE parent.job_instance_id = ?
+			AND parent.spark_application_id IS NOT NULL
+			AND COALESCE(parent.is_high_concurrency, false) = false
+		ORDER BY child.start_datetime ASC
+	`
+
+	rows, err := db.conn.Query(query, jobID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var children []ChildExecution
+	for rows.Next() {
+		var child ChildExecution
+		var startTime sql.NullTime
+		var endTime sql.NullTime
+		var durationMs sql.NullInt64
+		var cancellationReason sql.NullString
+		var jobInstanceID string
+		var workspaceID string
+		var notebookID string
+		var displayName sql.NullString
+		var livyID string
+
+		err := rows.Scan(
+			&livyID,
+			&child.ActivityName,
+			&child.Status,
+			&startTime,
+			&endTime,
+			&durationMs,
+			&cancellationReason,
+			&jobInstanceID,
+			&workspaceID,
+			&notebookID,
+			&displayName,
+			&child.HasChildren,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		child.ActivityRunID = livyID
+		child.ActivityType = ActivityTypeNotebookRun
+		child.LivyID = &livyID
+		child.ChildJobInstanceID = &jobInstanceID
+		child.ChildWorkspaceID = &workspaceID
+		child.ChildItemID = &notebookID
+		notebookType := "Notebook"
+		child.ChildItemType = &notebookType
+
+		if startTime.Valid {
+			child.StartTime = &startTime.Time
+		}
+		if endTime.Valid {
+			child.EndTime = &endTime.Time
+		}
+		if durationMs.Valid {
+			child.DurationMs = &durationMs.Int64
+		}
+		if cancellationReason.Valid && cancellationReason.String != "" {
+			child.ErrorMessage = &cancellationReason.String
+		}
+		if displayName.Valid && displayName.String != "" {
+			child.ChildItemDisplayName = &displayName.String
+		}
+
+		children = append(children, child)
+	}
+
+	return children, rows.Err()
+}
+
 // GetOverallStats returns aggregated statistics for the specified time period
 func (db *Database) GetOverallStats(days int) (*JobStats, error) {
 	query := `
@@ -528,17 +734,32 @@ func (db *Database) GetLastSyncTime(syncType string) (*time.Time, error) {
 // This ensures we always re-check in-progress jobs for status updates
 func (db *Database) GetMaxJobStartTime() (*time.Time, error) {
 	// First check if there are any in-progress jobs
+	// Stale jobs are excluded so a run Fabric lost can't pin the watermark forever
 	queryInProgress := `
 		SELECT MIN(start_time)
 		FROM job_instances
-		WHERE end_time IS NULL
+		WHERE end_time IS NULL AND status <> ?
 	`
 
 	var minInProgressStartTime sql.NullTime
-	err := db.conn.QueryRow(queryInProgress).Scan(&minInProgressStartTime)
+	err := db.conn.QueryRow(queryInProgress, JobStatusStale).Scan(&minInProgressStartTime)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	// A cancelled sync may have left workspaces unfetched - never move past its watermark
+	var heldWatermark sql.NullTime
+	err = db.conn.QueryRow(`
+		SELECT MIN(last_sync_time)
+		FROM sync_metadata
+		WHERE sync_type = ?
+	`, syncTypeWatermarkHold).Scan(&heldWatermark)
 	if err != nil && err != sql.ErrNoRows {
 		return nil, err
 	}
+	if heldWatermark.Valid && (!minInProgressStartTime.Valid || heldWatermark.Time.Before(minInProgressStartTime.Time)) {
+		return &heldWatermark.Time, nil
+	}
 
 	// If we found in-progress jobs, return the earliest one
 	if minInProgressStartTime.Valid {
@@ -566,6 +787,110 @@ func (db *Database) GetMaxJobStartTime() (*time.Time, error) {
 	return nil, nil
 }
 
+// MarkStaleJobs flags in-progress jobs that started before cutoff as stale and returns their IDs
+func (db *Database) MarkStaleJobs(cutoff time.Time) ([]string, error) {
+	rows, err := db.conn.Query(`
+		UPDATE job_instances
+		SET status = ?, updated_at = get_current_timestamp()
+		WHERE end_time IS NULL AND status <> ? AND start_time < ?
+		RETURNING id
+	`, JobStatusStale, JobStatusStale, cutoff)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// syncTypeWatermarkHold marks sync_metadata rows that pin the incremental watermark
+const syncTypeWatermarkHold = "watermark_hold"
+
+// HoldSyncWatermark pins the incremental sync watermark at or before the given time
+// Used when a sync is cancelled part way so workspaces it skipped are re-fetched next time
+func (db *Database) HoldSyncWatermark(watermark time.Time) error {
+	query := `
+		INSERT INTO sync_metadata (last_sync_time, sync_type, records_synced, errors)
+		VALUES (?, ?, 0, 0)
+	`
+	_, err := db.conn.Exec(query, watermark, syncTypeWatermarkHold)
+	return err
+}
+
+// ReleaseSyncWatermark removes watermark holds after a sync completed across all workspaces
+func (db *Database) ReleaseSyncWatermark() error {
+	_, err := db.conn.Exec(`DELETE FROM sync_metadata WHERE sync_type = ?`, syncTypeWatermarkHold)
+	return err
+}
+
+// ClearData deletes every synced row, leaving an empty database with its schema
+// Child tables are cleared before the tables their foreign keys reference. Each delete commits on its own,
+// since DuckDB checks foreign keys against rows deleted earlier in the same transaction
+func (db *Database) ClearData() error {
+	tables := []string{
+		"notebook_sessions", "job_instances", "items", "workspace_poll_schedule",
+		"workspaces", "sync_metadata", "sync_metrics", "parquet_exports", "job_alerts", "job_anomalies",
+		"copy_activity_metrics", "job_duration_regressions",
+	}
+	for _, table := range tables {
+		if _, err := db.conn.Exec("DELETE FROM " + table); err != nil {
+			return fmt.Errorf("failed to clear %s: %w", table, err)
+		}
+	}
+	return nil
+}
+
+// SaveSyncMetrics records the metrics of a finished sync run
+func (db *Database) SaveSyncMetrics(m *SyncMetrics) error {
+	query := `
+		INSERT INTO sync_metrics (
+			started_at, duration_ms, sync_type, status, error_message, app_version,
+			api_calls, retries, throttled, workspaces, workspaces_failed, jobs_fetched, rows_written, sync_run_id
+		)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err := db.conn.Exec(query,
+		m.StartedAt, m.DurationMs, m.SyncType, m.Status, m.ErrorMessage, m.AppVersion,
+		m.APICalls, m.Retries, m.Throttled, m.Workspaces, m.WorkspacesFailed, m.JobsFetched, m.RowsWritten, m.SyncRunID)
+	return err
+}
+
+// GetSyncMetrics returns the metrics of the most recent sync runs, newest first
+func (db *Database) GetSyncMetrics(limit int) ([]SyncMetrics, error) {
+	query := `
+		SELECT id, started_at, duration_ms, sync_type, status, error_message, COALESCE(app_version, ''),
+			api_calls, retries, throttled, workspaces, workspaces_failed, jobs_fetched, rows_written, COALESCE(sync_run_id, '')
+		FROM sync_metrics
+		ORDER BY started_at DESC
+		LIMIT ?
+	`
+
+	rows, err := db.conn.Query(query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var metrics []SyncMetrics
+	for rows.Next() {
+		var m SyncMetrics
+		if err := rows.Scan(&m.ID, &m.StartedAt, &m.DurationMs, &m.SyncType, &m.Status, &m.ErrorMessage, &m.AppVersion,
+			&m.APICalls, &m.Retries, &m.Throttled, &m.Workspaces, &m.WorkspacesFailed, &m.JobsFetched, &m.RowsWritten, &m.SyncRunID); err != nil {
+			return nil, err
+		}
+		metrics = append(metrics, m)
+	}
+	return metrics, rows.Err()
+}
+
 // GetDailyStats returns job statistics grouped by day for the last N days
 func (db *Database) GetDailyStats(days int) ([]DailyStats, error) {
 	query := `
@@ -1087,8 +1412,9 @@ func (db *Database) GetAvailableItemTypes(days int, workspaceIDs []string) ([]st
 }
 
 // GetOverallStatsFiltered returns aggregated statistics with optional filters
-func (db *Database) GetOverallStatsFiltered(days int, workspaceIDs []string, itemTypes []string, itemNameSearch string) (*JobStats, error) {
+func (db *Database) GetOverallStatsFiltered(days int, workspaceIDs []string, itemTypes []string, itemNameSearch string, favoritesOnly bool) (*JobStats, error) {
 	filterClause, filterArgs := buildFilterConditions(workspaceIDs, itemTypes, itemNameSearch)
+	filterClause += favoritesCondition(favoritesOnly)
 
 	query := fmt.Sprintf(`
 		SELECT
@@ -1131,8 +1457,9 @@ func (db *Database) GetOverallStatsFiltered(days int, workspaceIDs []string, ite
 }
 
 // GetDailyStatsFiltered returns daily statistics with optional filters
-func (db *Database) GetDailyStatsFiltered(days int, workspaceIDs []string, itemTypes []string, itemNameSearch string) ([]DailyStats, error) {
+func (db *Database) GetDailyStatsFiltered(days int, workspaceIDs []string, itemTypes []string, itemNameSearch string, favoritesOnly bool) ([]DailyStats, error) {
 	filterClause, filterArgs := buildFilterConditions(workspaceIDs, itemTypes, itemNameSearch)
+	filterClause += favoritesCondition(favoritesOnly)
 
 	query := fmt.Sprintf(`
 		SELECT
@@ -1183,8 +1510,9 @@ func (db *Database) GetDailyStatsFiltered(days int, workspaceIDs []string, itemT
 }
 
 // GetWorkspaceStatsFiltered returns workspace statistics with optional filters
-func (db *Database) GetWorkspaceStatsFiltered(days int, workspaceIDs []string, itemTypes []string, itemNameSearch string) ([]WorkspaceStats, error) {
+func (db *Database) GetWorkspaceStatsFiltered(days int, workspaceIDs []string, itemTypes []string, itemNameSearch string, favoritesOnly bool) ([]WorkspaceStats, error) {
 	filterClause, filterArgs := buildFilterConditions(workspaceIDs, itemTypes, itemNameSearch)
+	filterClause += favoritesCondition(favoritesOnly)
 
 	query := fmt.Sprintf(`
 		SELECT
@@ -1237,8 +1565,9 @@ func (db *Database) GetWorkspaceStatsFiltered(days int, workspaceIDs []string, i
 }
 
 // GetItemTypeStatsFiltered returns item type statistics with optional filters
-func (db *Database) GetItemTypeStatsFiltered(days int, workspaceIDs []string, itemTypes []string, itemNameSearch string) ([]ItemTypeStats, error) {
+func (db *Database) GetItemTypeStatsFiltered(days int, workspaceIDs []string, itemTypes []string, itemNameSearch string, favoritesOnly bool) ([]ItemTypeStats, error) {
 	filterClause, filterArgs := buildFilterConditions(workspaceIDs, itemTypes, itemNameSearch)
+	filterClause += favoritesCondition(favoritesOnly)
 
 	query := fmt.Sprintf(`
 		SELECT
@@ -1289,8 +1618,9 @@ func (db *Database) GetItemTypeStatsFiltered(days int, workspaceIDs []string, it
 }
 
 // GetRecentFailuresFiltered returns recent failures with optional filters
-func (db *Database) GetRecentFailuresFiltered(limit int, days int, workspaceIDs []string, itemTypes []string, itemNameSearch string) ([]RecentFailure, error) {
+func (db *Database) GetRecentFailuresFiltered(limit int, days int, workspaceIDs []string, itemTypes []string, itemNameSearch string, favoritesOnly bool) ([]RecentFailure, error) {
 	filterClause, filterArgs := buildFilterConditions(workspaceIDs, itemTypes, itemNameSearch)
+	filterClause += favoritesCondition(favoritesOnly)
 
 	query := fmt.Sprintf(`
 		SELECT
@@ -1357,8 +1687,9 @@ func (db *Database) GetRecentFailuresFiltered(limit int, days int, workspaceIDs
 }
 
 // GetLongRunningJobsFiltered returns long-running jobs with optional filters
-func (db *Database) GetLongRunningJobsFiltered(days int, minDeviationPct float64, limit int, workspaceIDs []string, itemTypes []string, itemNameSearch string) ([]LongRunningJob, error) {
+func (db *Database) GetLongRunningJobsFiltered(days int, minDeviationPct float64, limit int, workspaceIDs []string, itemTypes []string, itemNameSearch string, favoritesOnly bool) ([]LongRunningJob, error) {
 	filterClause, filterArgs := buildFilterConditions(workspaceIDs, itemTypes, itemNameSearch)
+	filterClause += favoritesCondition(favoritesOnly)
 
 	query := fmt.Sprintf(`
 		WITH item_averages AS (
@@ -1488,16 +1819,24 @@ func (db *Database) GetLivyIDsByJobInstanceIDs(jobInstanceIDs []string) (map[str
 	return result, rows.Err()
 }
 
-// GetUniqueNotebooks returns unique notebook IDs and their workspace IDs from job_instances
-func (db *Database) GetUniqueNotebooks() ([]struct{ WorkspaceID, NotebookID string }, error) {
+// GetUniqueNotebooks returns unique notebook IDs and their workspace IDs from job_instances,
+// leaving out workspaces whose settings turn enrichment off
+// If since is set, only notebooks with a run that started or ended after it, or is still in progress, are returned
+func (db *Database) GetUniqueNotebooks(since *time.Time) ([]struct{ WorkspaceID, NotebookID string }, error) {
 	query := `
 		SELECT DISTINCT j.workspace_id, j.item_id
 		FROM job_instances j
 		INNER JOIN items i ON j.item_id = i.id
 		WHERE i.type = 'Notebook'
+			AND j.workspace_id NOT IN (SELECT workspace_id FROM workspace_settings WHERE enrichment_enabled = false)
 	`
+	var args []interface{}
+	if since != nil {
+		query += ` AND (j.start_time >= ? OR j.end_time >= ? OR j.end_time IS NULL)`
+		args = append(args, *since, *since)
+	}
 
-	rows, err := db.conn.Query(query)
+	rows, err := db.conn.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -1514,3 +1853,134 @@ func (db *Database) GetUniqueNotebooks() ([]struct{ WorkspaceID, NotebookID stri
 
 	return notebooks, rows.Err()
 }
+
+// GetDatabaseStats returns the file size, DuckDB version and row count of every table
+func (db *Database) GetDatabaseStats() (*DatabaseStats, error) {
+	stats := &DatabaseStats{
+		Path:          db.path,
+		SchemaVersion: SchemaVersion(),
+		ReadOnly:      db.readOnly,
+	}
+	if info, err := os.Stat(db.path); err == nil {
+		stats.SizeBytes = info.Size()
+	}
+	if info, err := os.Stat(db.path + ".wal"); err == nil {
+		stats.WALSizeBytes = info.Size()
+	}
+
+	if err := db.conn.QueryRow("SELECT version()").Scan(&stats.DuckDBVersion); err != nil {
+		return nil, err
+	}
+
+	rows, err := db.conn.Query(`
+		SELECT table_name FROM information_schema.tables
+		WHERE table_schema = 'main' AND table_type = 'BASE TABLE'
+		ORDER BY table_name
+	`)
+	if err != nil {
+		return nil, err
+	}
+	var tables []string
+	for rows.Next() {
+		var table string
+		if err := rows.Scan(&table); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		tables = append(tables, table)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	stats.Tables = make([]TableRowCount, 0, len(tables))
+	for _, table := range tables {
+		count := TableRowCount{Table: table}
+		if err := db.conn.QueryRow(fmt.Sprintf(`SELECT COUNT(*) FROM "%s"`, table)).Scan(&count.Rows); err != nil {
+			return nil, fmt.Errorf("failed to count rows of %s: %w", table, err)
+		}
+		stats.Tables = append(stats.Tables, count)
+	}
+	return stats, nil
+}
+
+// GetFailureStreak returns how many of an item's most recent finished runs failed in a row
+// Only completed and failed runs count; cancelled, stale and running ones neither extend nor break the streak
+func (db *Database) GetFailureStreak(itemID string) (int, error) {
+	query := `
+		WITH finished AS (
+			SELECT status, ROW_NUMBER() OVER (ORDER BY start_time DESC) AS position
+			FROM job_instances
+			WHERE item_id = ? AND status IN ('Completed', 'Failed')
+		)
+		SELECT COALESCE(MIN(position) FILTER (WHERE status = 'Completed') - 1, COUNT(*))
+		FROM finished
+	`
+
+	var streak int
+	if err := db.conn.QueryRow(query, itemID).Scan(&streak); err != nil {
+		return 0, err
+	}
+	return streak, nil
+}
+
+// GetFailureStreaks returns the items whose latest minStreak or more finished runs all failed, longest streak first
+// The filters choose which items are listed; each streak is counted like GetFailureStreak, over all the item's runs
+func (db *Database) GetFailureStreaks(minStreak, limit int, workspaceIDs []string, itemTypes []string, itemNameSearch string, favoritesOnly bool) ([]FailureStreak, error) {
+	filterClause, filterArgs := buildFilterConditions(workspaceIDs, itemTypes, itemNameSearch)
+	filterClause += favoritesCondition(favoritesOnly)
+
+	// finished is referenced twice and inlined by DuckDB each time; bound parameters inside it break the plan
+	// (an INTERNAL error that invalidates the database), so the filters narrow a materialized set of items instead
+	query := fmt.Sprintf(`
+		WITH matching AS MATERIALIZED (
+			SELECT DISTINCT j.item_id
+			FROM job_instances j
+			LEFT JOIN items i ON j.item_id = i.id
+			WHERE j.status IN ('Completed', 'Failed')
+			%s
+		),
+		finished AS (
+			SELECT item_id, workspace_id, status, start_time, failure_reason,
+				ROW_NUMBER() OVER (PARTITION BY item_id ORDER BY start_time DESC) AS position
+			FROM job_instances
+			WHERE status IN ('Completed', 'Failed')
+		),
+		streaks AS (
+			SELECT f.item_id, COALESCE(MIN(f.position) FILTER (WHERE f.status = 'Completed') - 1, COUNT(*)) AS streak
+			FROM finished f
+			JOIN matching m ON m.item_id = f.item_id
+			GROUP BY f.item_id
+		)
+		SELECT s.item_id, COALESCE(i.display_name, s.item_id), COALESCE(i.type, ''),
+			arg_max(f.workspace_id, f.start_time), COALESCE(arg_max(w.display_name, f.start_time), arg_max(f.workspace_id, f.start_time)),
+			s.streak, MIN(f.start_time), MAX(f.start_time), COALESCE(arg_max(f.failure_reason, f.start_time), '')
+		FROM streaks s
+		JOIN finished f ON f.item_id = s.item_id AND f.position <= s.streak
+		LEFT JOIN items i ON s.item_id = i.id
+		LEFT JOIN workspaces w ON f.workspace_id = w.id
+		WHERE s.streak >= ?
+		GROUP BY s.item_id, i.display_name, i.type, s.streak
+		ORDER BY s.streak DESC, MAX(f.start_time) DESC
+		LIMIT ?
+	`, filterClause)
+
+	args := append(filterArgs, max(minStreak, 1), limit)
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var streaks []FailureStreak
+	for rows.Next() {
+		var f FailureStreak
+		if err := rows.Scan(&f.ItemID, &f.ItemDisplayName, &f.ItemType, &f.WorkspaceID, &f.WorkspaceName,
+			&f.Streak, &f.StartedAt, &f.LastFailureAt, &f.LastFailureReason); err != nil {
+			return nil, err
+		}
+		streaks = append(streaks, f)
+	}
+	return streaks, rows.Err()
+}
@@ -0,0 +1,100 @@
+package db
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newTestDatabase opens an empty database in a temporary directory, closed when the test ends
+func newTestDatabase(t *testing.T) *Database {
+	t.Helper()
+	database, err := NewDatabase(filepath.Join(t.TempDir(), "test.db"), "")
+	if err != nil {
+		t.Fatalf("NewDatabase: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+	return database
+}
+
+// seedRuns saves an item in its workspace with one run per status, oldest first, an hour apart
+func seedRuns(t *testing.T, database *Database, workspaceID, itemID, itemType string, statuses ...string) {
+	t.Helper()
+	now := time.Now().UTC().Truncate(time.Second)
+	if err := database.SaveWorkspace(&Workspace{ID: workspaceID, DisplayName: "Workspace " + workspaceID, Type: "Workspace", CreatedAt: now, UpdatedAt: now}); err != nil {
+		t.Fatalf("SaveWorkspace: %v", err)
+	}
+	if err := database.SaveItem(&Item{ID: itemID, WorkspaceID: workspaceID, DisplayName: "Item " + itemID, Type: itemType, CreatedAt: now, UpdatedAt: now}); err != nil {
+		t.Fatalf("SaveItem: %v", err)
+	}
+
+	jobs := make([]JobInstance, len(statuses))
+	for n, status := range statuses {
+		start := now.Add(time.Duration(n-len(statuses)) * time.Hour)
+		end := start.Add(time.Minute)
+		duration := int64(time.Minute / time.Millisecond)
+		jobs[n] = JobInstance{
+			ID:          fmt.Sprintf("%s-run-%d", itemID, n),
+			WorkspaceID: workspaceID,
+			ItemID:      itemID,
+			JobType:     "Pipeline",
+			Status:      status,
+			StartTime:   start,
+			EndTime:     &end,
+			DurationMs:  &duration,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		}
+	}
+	if err := database.SaveJobInstances(jobs); err != nil {
+		t.Fatalf("SaveJobInstances: %v", err)
+	}
+}
+
+func TestGetFailureStreaksFilters(t *testing.T) {
+	database := newTestDatabase(t)
+	seedRuns(t, database, "ws-a", "item-a", "DataPipeline", "Completed", "Failed", "Failed", "Failed")
+	seedRuns(t, database, "ws-b", "item-b", "Notebook", "Failed", "Completed", "Failed", "Failed")
+	seedRuns(t, database, "ws-b", "item-c", "Notebook", "Failed", "Completed")
+	if err := database.SaveFavorite(&Favorite{TargetType: FavoriteTargetWorkspace, TargetID: "ws-a", StarredAt: time.Now().UTC()}); err != nil {
+		t.Fatalf("SaveFavorite: %v", err)
+	}
+
+	tests := []struct {
+		name           string
+		workspaceIDs   []string
+		itemTypes      []string
+		itemNameSearch string
+		favoritesOnly  bool
+		want           map[string]int
+	}{
+		{name: "no filter", want: map[string]int{"item-a": 3, "item-b": 2}},
+		{name: "workspace", workspaceIDs: []string{"ws-b"}, want: map[string]int{"item-b": 2}},
+		{name: "several workspaces", workspaceIDs: []string{"ws-a", "ws-b"}, want: map[string]int{"item-a": 3, "item-b": 2}},
+		{name: "item type", itemTypes: []string{"DataPipeline"}, want: map[string]int{"item-a": 3}},
+		{name: "item name", itemNameSearch: "item-b", want: map[string]int{"item-b": 2}},
+		{name: "favorites", favoritesOnly: true, want: map[string]int{"item-a": 3}},
+		{name: "workspace and item type", workspaceIDs: []string{"ws-a"}, itemTypes: []string{"Notebook"}, want: map[string]int{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			streaks, err := database.GetFailureStreaks(2, 10, tt.workspaceIDs, tt.itemTypes, tt.itemNameSearch, tt.favoritesOnly)
+			if err != nil {
+				t.Fatalf("GetFailureStreaks: %v", err)
+			}
+			got := map[string]int{}
+			for _, s := range streaks {
+				got[s.ItemID] = s.Streak
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("streaks = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// An internal error would leave the database unusable for every later query
+	if _, err := database.GetFailureStreak("item-a"); err != nil {
+		t.Fatalf("database unusable after filtered streaks: %v", err)
+	}
+}
@@ -0,0 +1,96 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// recoveryIncidentsQuery selects the failure incidents started in the last days, one row per incident,
+// with when the failing run ended and when the item's next successful run ended (NULL while still failing)
+// A %s placeholder takes the analytics filter conditions
+const recoveryIncidentsQuery = `
+	WITH finished AS (
+		SELECT j.id, j.item_id, j.workspace_id, j.status, j.start_time, COALESCE(j.end_time, j.start_time) AS end_time,
+			LAG(j.status) OVER (PARTITION BY j.item_id ORDER BY j.start_time) AS previous_status
+		FROM job_instances j
+		LEFT JOIN items i ON j.item_id = i.id
+		WHERE j.status IN ('Completed', 'Failed') AND j.start_time IS NOT NULL
+		%s
+	),
+	incidents AS (
+		SELECT f.item_id, f.workspace_id, f.end_time AS failed_at,
+			(
+				SELECT MIN(c.end_time) FROM finished c
+				WHERE c.item_id = f.item_id AND c.status = 'Completed' AND c.start_time > f.start_time
+			) AS recovered_at
+		FROM finished f
+		WHERE f.status = 'Failed' AND (f.previous_status IS NULL OR f.previous_status = 'Completed')
+			AND f.start_time >= CURRENT_TIMESTAMP - INTERVAL (? || ' days')
+	)
+`
+
+// GetItemRecoveryStats returns the mean and median time to recovery of each item with failure incidents
+// started in the last days, slowest to recover first
+func (db *Database) GetItemRecoveryStats(days int, workspaceIDs []string, itemTypes []string, itemNameSearch string) ([]RecoveryStats, error) {
+	filterClause, filterArgs := buildFilterConditions(workspaceIDs, itemTypes, itemNameSearch)
+	query := fmt.Sprintf(recoveryIncidentsQuery, filterClause) + `
+		SELECT n.item_id, COALESCE(i.display_name, n.item_id), COALESCE(i.type, ''),
+			n.workspace_id, COALESCE(w.display_name, n.workspace_id),
+			COUNT(*), COUNT(n.recovered_at),
+			AVG(date_diff('millisecond', n.failed_at, n.recovered_at)),
+			MEDIAN(date_diff('millisecond', n.failed_at, n.recovered_at)),
+			MAX(date_diff('millisecond', n.failed_at, n.recovered_at))
+		FROM incidents n
+		LEFT JOIN items i ON n.item_id = i.id
+		LEFT JOIN workspaces w ON n.workspace_id = w.id
+		GROUP BY n.item_id, i.display_name, i.type, n.workspace_id, w.display_name
+		ORDER BY AVG(date_diff('millisecond', n.failed_at, n.recovered_at)) DESC NULLS LAST, COUNT(*) DESC
+	`
+	return db.queryRecoveryStats(query, true, append(filterArgs, fmt.Sprintf("%d", days))...)
+}
+
+// GetWorkspaceRecoveryStats returns the mean and median time to recovery over each workspace's items
+// for failure incidents started in the last days, slowest to recover first
+func (db *Database) GetWorkspaceRecoveryStats(days int, workspaceIDs []string, itemTypes []string, itemNameSearch string) ([]RecoveryStats, error) {
+	filterClause, filterArgs := buildFilterConditions(workspaceIDs, itemTypes, itemNameSearch)
+	query := fmt.Sprintf(recoveryIncidentsQuery, filterClause) + `
+		SELECT n.workspace_id, COALESCE(w.display_name, n.workspace_id),
+			COUNT(*), COUNT(n.recovered_at),
+			AVG(date_diff('millisecond', n.failed_at, n.recovered_at)),
+			MEDIAN(date_diff('millisecond', n.failed_at, n.recovered_at)),
+			MAX(date_diff('millisecond', n.failed_at, n.recovered_at))
+		FROM incidents n
+		LEFT JOIN workspaces w ON n.workspace_id = w.id
+		GROUP BY n.workspace_id, w.display_name
+		ORDER BY AVG(date_diff('millisecond', n.failed_at, n.recovered_at)) DESC NULLS LAST, COUNT(*) DESC
+	`
+	return db.queryRecoveryStats(query, false, append(filterArgs, fmt.Sprintf("%d", days))...)
+}
+
+// queryRecoveryStats runs a recovery stats query whose rows start with item columns when perItem is set
+func (db *Database) queryRecoveryStats(query string, perItem bool, args ...interface{}) ([]RecoveryStats, error) {
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stats []RecoveryStats
+	for rows.Next() {
+		var s RecoveryStats
+		var mean, median sql.NullFloat64
+		var longest sql.NullInt64
+		dest := []interface{}{&s.WorkspaceID, &s.WorkspaceName, &s.Incidents, &s.Recovered, &mean, &median, &longest}
+		if perItem {
+			dest = append([]interface{}{&s.ItemID, &s.ItemDisplayName, &s.ItemType}, dest...)
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		s.MeanRecoveryMs = mean.Float64
+		s.MedianRecoveryMs = median.Float64
+		s.MaxRecoveryMs = longest.Int64
+		stats = append(stats, s)
+	}
+	return stats, rows.Err()
+}
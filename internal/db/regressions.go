@@ -0,0 +1,81 @@
+package db
+
+import (
+	"fmt"
+	"time"
+)
+
+// Runs a completed run's duration is compared with
+const (
+	RegressionBaselineRuns = 10 // Most recent successful runs of the item before it
+	regressionMinRuns      = 3  // Fewest earlier runs a baseline is taken over
+)
+
+// MeasureDurationRegressions compares every completed run not measured yet with the median duration of the
+// item's last successful runs before it, stores the difference and returns how many runs were measured
+// Runs whose item has too few earlier runs are stored without a baseline so they aren't retried
+func (db *Database) MeasureDurationRegressions(now time.Time) (int, error) {
+	result, err := db.conn.Exec(fmt.Sprintf(`
+		INSERT INTO job_duration_regressions
+		SELECT id, item_id, duration_ms,
+			CASE WHEN samples >= %[2]d THEN baseline END,
+			CASE WHEN samples >= %[2]d THEN duration_ms - baseline END,
+			CASE WHEN samples >= %[2]d THEN (duration_ms - baseline) * 100.0 / NULLIF(baseline, 0) END,
+			samples, ?
+		FROM (
+			SELECT j.id, j.item_id, j.duration_ms,
+				median(j.duration_ms) OVER recent AS baseline,
+				COUNT(*) OVER recent AS samples
+			FROM job_instances j
+			WHERE j.status = 'Completed' AND j.duration_ms IS NOT NULL AND j.start_time IS NOT NULL
+				AND j.item_id IN (
+					SELECT p.item_id FROM job_instances p
+					WHERE p.status = 'Completed' AND p.duration_ms IS NOT NULL
+						AND NOT EXISTS (SELECT 1 FROM job_duration_regressions r WHERE r.job_id = p.id)
+				)
+			WINDOW recent AS (PARTITION BY j.item_id ORDER BY j.start_time, j.id ROWS BETWEEN %[1]d PRECEDING AND 1 PRECEDING)
+		) runs
+		WHERE NOT EXISTS (SELECT 1 FROM job_duration_regressions r WHERE r.job_id = runs.id)
+		ON CONFLICT DO NOTHING
+	`, RegressionBaselineRuns, regressionMinRuns), now)
+	if err != nil {
+		return 0, err
+	}
+	measured, err := result.RowsAffected()
+	return int(measured), err
+}
+
+// GetDurationRegressions returns completed runs started in the last days that took at least minDeltaPct
+// percent longer than their baseline, largest regression first
+func (db *Database) GetDurationRegressions(days int, minDeltaPct float64, limit int) ([]DurationRegression, error) {
+	query := `
+		SELECT j.id, j.workspace_id, COALESCE(w.display_name, j.workspace_id),
+			j.item_id, COALESCE(i.display_name, j.item_id), COALESCE(i.type, ''),
+			j.start_time, r.duration_ms, r.baseline_ms, r.delta_ms, r.delta_pct, r.samples
+		FROM job_duration_regressions r
+		JOIN job_instances j ON r.job_id = j.id
+		LEFT JOIN items i ON j.item_id = i.id
+		LEFT JOIN workspaces w ON j.workspace_id = w.id
+		WHERE r.delta_pct IS NOT NULL AND r.delta_pct >= ?
+			AND j.start_time >= CURRENT_TIMESTAMP - INTERVAL (? || ' days')
+		ORDER BY r.delta_pct DESC, r.delta_ms DESC
+		LIMIT ?
+	`
+
+	rows, err := db.conn.Query(query, minDeltaPct, fmt.Sprintf("%d", days), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var regressions []DurationRegression
+	for rows.Next() {
+		var r DurationRegression
+		if err := rows.Scan(&r.JobID, &r.WorkspaceID, &r.WorkspaceName, &r.ItemID, &r.ItemDisplayName, &r.ItemType,
+			&r.StartTime, &r.DurationMs, &r.BaselineMs, &r.DeltaMs, &r.DeltaPct, &r.Samples); err != nil {
+			return nil, err
+		}
+		regressions = append(regressions, r)
+	}
+	return regressions, rows.Err()
+}
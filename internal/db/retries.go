@@ -0,0 +1,119 @@
+package db
+
+import "fmt"
+
+// activityExecutions lists the executions of activities in pipeline runs started in the last days, taking the %s
+// filter clause: the attempts of an activity in a run, or of one loop iteration of it, grouped with the outcome
+// of the last attempt, the retries made and the time spent in attempts before the last
+// Fabric lists every attempt as its own activity run, numbering the retries in retryAttempt
+const activityExecutions = `
+	WITH activities AS (
+		SELECT j.id AS job_id, j.item_id, j.workspace_id,
+			unnest(CAST(j.activity_runs AS JSON[])) AS activity
+		FROM job_instances j
+		LEFT JOIN items i ON j.item_id = i.id
+		WHERE j.activity_runs IS NOT NULL
+			AND j.start_time >= CURRENT_TIMESTAMP - INTERVAL (? || ' days')
+		%s
+	),
+	attempts AS (
+		SELECT job_id, item_id, workspace_id,
+			json_extract_string(activity, '$.activityName') AS activity_name,
+			json_extract_string(activity, '$.activityType') AS activity_type,
+			COALESCE(json_extract_string(activity, '$.iterationHash'), '') AS iteration,
+			COALESCE(TRY_CAST(json_extract(activity, '$.retryAttempt') AS INTEGER), 0) AS attempt,
+			COALESCE(json_extract_string(activity, '$.activityRunStart'), '') AS started,
+			json_extract_string(activity, '$.status') AS status,
+			COALESCE(TRY_CAST(json_extract(activity, '$.durationInMs') AS BIGINT), 0) AS duration_ms
+		FROM activities
+	),
+	executions AS (
+		SELECT job_id, item_id, workspace_id, activity_name,
+			ANY_VALUE(activity_type) AS activity_type,
+			GREATEST(COUNT(*) - 1, MAX(attempt)) AS retries,
+			arg_max(status, (attempt, started)) AS final_status,
+			SUM(duration_ms) - arg_max(duration_ms, (attempt, started)) AS retry_ms
+		FROM attempts
+		WHERE activity_name IS NOT NULL
+		GROUP BY job_id, item_id, workspace_id, activity_name, iteration
+	)
+`
+
+// GetActivityRetryStats aggregates the retries of activities of pipeline runs started in the last days per
+// pipeline and activity, leaving out activities that never retried, most retries first
+func (db *Database) GetActivityRetryStats(days int, workspaceIDs []string, itemTypes []string, itemNameSearch string) ([]ActivityRetryStats, error) {
+	filterClause, filterArgs := buildFilterConditions(workspaceIDs, itemTypes, itemNameSearch)
+	query := fmt.Sprintf(activityExecutions, filterClause) + `
+		SELECT e.item_id, COALESCE(i.display_name, e.item_id), e.workspace_id, COALESCE(w.display_name, e.workspace_id),
+			e.activity_name, COALESCE(e.activity_type, ''),
+			COUNT(*),
+			COUNT(*) FILTER (WHERE e.retries > 0),
+			COUNT(*) FILTER (WHERE e.retries > 0 AND e.final_status = 'Succeeded'),
+			COUNT(*) FILTER (WHERE e.retries > 0 AND e.final_status = 'Failed'),
+			SUM(e.retries),
+			MAX(e.retries),
+			SUM(e.retry_ms)
+		FROM executions e
+		LEFT JOIN items i ON e.item_id = i.id
+		LEFT JOIN workspaces w ON e.workspace_id = w.id
+		GROUP BY e.item_id, i.display_name, e.workspace_id, w.display_name, e.activity_name, e.activity_type
+		HAVING SUM(e.retries) > 0
+		ORDER BY SUM(e.retries) DESC, SUM(e.retry_ms) DESC, e.item_id, e.activity_name
+	`
+
+	rows, err := db.conn.Query(query, append([]interface{}{fmt.Sprintf("%d", days)}, filterArgs...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stats []ActivityRetryStats
+	for rows.Next() {
+		var s ActivityRetryStats
+		if err := rows.Scan(&s.ItemID, &s.ItemDisplayName, &s.WorkspaceID, &s.WorkspaceName, &s.ActivityName, &s.ActivityType,
+			&s.Executions, &s.Retried, &s.SucceededAfterRetry, &s.FailedAfterRetry, &s.Retries, &s.MaxRetries, &s.RetryTimeMs); err != nil {
+			return nil, err
+		}
+		stats = append(stats, s)
+	}
+	return stats, rows.Err()
+}
+
+// GetPipelineRetryStats aggregates the retries of activities of pipeline runs started in the last days per
+// pipeline, including pipelines whose activities never retried, most time lost to retries first
+func (db *Database) GetPipelineRetryStats(days int, workspaceIDs []string, itemTypes []string, itemNameSearch string) ([]PipelineRetryStats, error) {
+	filterClause, filterArgs := buildFilterConditions(workspaceIDs, itemTypes, itemNameSearch)
+	query := fmt.Sprintf(activityExecutions, filterClause) + `
+		SELECT e.item_id, COALESCE(i.display_name, e.item_id), e.workspace_id, COALESCE(w.display_name, e.workspace_id),
+			COUNT(DISTINCT e.job_id),
+			COUNT(DISTINCT e.job_id) FILTER (WHERE e.retries > 0),
+			COUNT(*),
+			COUNT(*) FILTER (WHERE e.retries > 0),
+			COUNT(*) FILTER (WHERE e.retries > 0 AND e.final_status = 'Succeeded'),
+			COUNT(*) FILTER (WHERE e.retries > 0 AND e.final_status = 'Failed'),
+			SUM(e.retries),
+			SUM(e.retry_ms)
+		FROM executions e
+		LEFT JOIN items i ON e.item_id = i.id
+		LEFT JOIN workspaces w ON e.workspace_id = w.id
+		GROUP BY e.item_id, i.display_name, e.workspace_id, w.display_name
+		ORDER BY SUM(e.retry_ms) DESC, SUM(e.retries) DESC, COALESCE(i.display_name, e.item_id), e.item_id
+	`
+
+	rows, err := db.conn.Query(query, append([]interface{}{fmt.Sprintf("%d", days)}, filterArgs...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stats []PipelineRetryStats
+	for rows.Next() {
+		var s PipelineRetryStats
+		if err := rows.Scan(&s.ItemID, &s.ItemDisplayName, &s.WorkspaceID, &s.WorkspaceName, &s.Runs, &s.RetriedRuns,
+			&s.Executions, &s.Retried, &s.SucceededAfterRetry, &s.FailedAfterRetry, &s.Retries, &s.RetryTimeMs); err != nil {
+			return nil, err
+		}
+		stats = append(stats, s)
+	}
+	return stats, rows.Err()
+}
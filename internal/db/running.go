@@ -0,0 +1,78 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// GetRunningJobAges returns the queued and in-progress jobs with how long they have run at now and the
+// baseline of their item: the average and P90 duration of its completed runs over the last 30 days,
+// the same average the long-running and stuck alerts use
+// Items without 3 completed runs in that time have no baseline
+func (db *Database) GetRunningJobAges(now time.Time, workspaceIDs []string, itemTypes []string, itemNameSearch string) ([]RunningJobAge, error) {
+	filterClause, filterArgs := buildFilterConditions(workspaceIDs, itemTypes, itemNameSearch)
+	query := fmt.Sprintf(`
+		WITH baselines AS (
+			SELECT item_id, AVG(duration_ms) AS avg_duration_ms, quantile_cont(duration_ms, 0.9) AS p90_duration_ms,
+				COUNT(*) AS runs
+			FROM job_instances
+			WHERE status = 'Completed'
+				AND duration_ms IS NOT NULL
+				AND start_time >= ? - INTERVAL 30 DAYS
+			GROUP BY item_id
+			HAVING COUNT(*) >= 3
+		)
+		SELECT j.id, j.workspace_id, j.item_id, j.job_type, j.status, j.start_time, j.root_activity_id,
+			i.display_name, i.type, w.display_name,
+			date_diff('millisecond', j.start_time, ?), b.avg_duration_ms, b.p90_duration_ms, COALESCE(b.runs, 0)
+		FROM job_instances j
+		LEFT JOIN items i ON j.item_id = i.id
+		LEFT JOIN workspaces w ON j.workspace_id = w.id
+		LEFT JOIN baselines b ON j.item_id = b.item_id
+		WHERE j.status IN ('NotStarted', 'InProgress') AND j.end_time IS NULL AND j.start_time IS NOT NULL
+		%s
+		ORDER BY j.start_time
+	`, filterClause)
+
+	rows, err := db.conn.Query(query, append([]interface{}{now, now}, filterArgs...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ages []RunningJobAge
+	for rows.Next() {
+		var a RunningJobAge
+		var avg, p90 sql.NullFloat64
+		if err := rows.Scan(&a.Job.ID, &a.Job.WorkspaceID, &a.Job.ItemID, &a.Job.JobType, &a.Job.Status, &a.Job.StartTime,
+			&a.Job.RootActivityID, &a.Job.ItemDisplayName, &a.Job.ItemType, &a.Job.WorkspaceName,
+			&a.ElapsedMs, &avg, &p90, &a.BaselineRuns); err != nil {
+			return nil, err
+		}
+		if avg.Valid {
+			a.BaselineMs, a.P90DurationMs = &avg.Float64, &p90.Float64
+		}
+		ages = append(ages, a)
+	}
+	return ages, rows.Err()
+}
+
+// GetRunningJobs returns the queued and in-progress jobs with the names of their item and workspace, oldest first
+func (db *Database) GetRunningJobs(workspaceIDs []string, itemTypes []string, itemNameSearch string) ([]JobInstance, error) {
+	filterClause, filterArgs := buildFilterConditions(workspaceIDs, itemTypes, itemNameSearch)
+	query := fmt.Sprintf(`
+		SELECT %s
+		%s
+		WHERE j.status IN ('NotStarted', 'InProgress') AND j.end_time IS NULL
+		%s
+		ORDER BY j.start_time, j.id
+	`, jobInstanceColumns, jobInstanceFrom, filterClause)
+
+	rows, err := db.conn.Query(query, filterArgs...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanJobInstances(rows)
+}
@@ -0,0 +1,79 @@
+package db
+
+import "fmt"
+
+// What GetTop ranks
+const (
+	TopItems      = "items"
+	TopWorkspaces = "workspaces"
+)
+
+// Metrics GetTop ranks by
+const (
+	TopByRuns     = "runs"
+	TopByRuntime  = "runtime"
+	TopByFailures = "failures"
+)
+
+// topMetrics maps each metric to the aggregate it ranks by
+var topMetrics = map[string]string{
+	TopByRuns:     "COUNT(*)",
+	TopByRuntime:  "COALESCE(SUM(j.duration_ms), 0)",
+	TopByFailures: "COUNT(*) FILTER (WHERE j.status = 'Failed')",
+}
+
+// GetTop returns the limit items or workspaces with the most runs, runtime or failures among the runs started
+// in the last days, highest first
+// Entries without any runtime or failures are left out when ranking by those
+func (db *Database) GetTop(scope, metric string, days, limit int, workspaceIDs []string, itemTypes []string, itemNameSearch string) ([]TopEntry, error) {
+	value, ok := topMetrics[metric]
+	if !ok {
+		return nil, fmt.Errorf("unknown metric %q: use %s, %s or %s", metric, TopByRuns, TopByRuntime, TopByFailures)
+	}
+	var id, name, itemType string
+	switch scope {
+	case TopItems:
+		id, name, itemType = "j.item_id", "COALESCE(i.display_name, j.item_id)", "COALESCE(i.type, '')"
+	case TopWorkspaces:
+		id, name, itemType = "j.workspace_id", "COALESCE(w.display_name, j.workspace_id)", "''"
+	default:
+		return nil, fmt.Errorf("unknown scope %q: use %s or %s", scope, TopItems, TopWorkspaces)
+	}
+
+	filterClause, filterArgs := buildFilterConditions(workspaceIDs, itemTypes, itemNameSearch)
+	query := fmt.Sprintf(`
+		SELECT %[1]s, ANY_VALUE(%[2]s), ANY_VALUE(%[3]s),
+			ANY_VALUE(j.workspace_id), ANY_VALUE(COALESCE(w.display_name, j.workspace_id)),
+			COUNT(*),
+			COUNT(*) FILTER (WHERE j.status = 'Failed'),
+			COALESCE(SUM(j.duration_ms), 0),
+			%[4]s
+		FROM job_instances j
+		LEFT JOIN items i ON j.item_id = i.id
+		LEFT JOIN workspaces w ON j.workspace_id = w.id
+		WHERE j.start_time >= CURRENT_TIMESTAMP - INTERVAL (? || ' days')
+			%[5]s
+		GROUP BY %[1]s
+		HAVING %[4]s > 0
+		ORDER BY %[4]s DESC, COUNT(*) DESC, ANY_VALUE(%[2]s), %[1]s
+		LIMIT ?
+	`, id, name, itemType, value, filterClause)
+
+	args := append([]interface{}{fmt.Sprintf("%d", days)}, filterArgs...)
+	rows, err := db.conn.Query(query, append(args, limit)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []TopEntry
+	for rows.Next() {
+		var e TopEntry
+		if err := rows.Scan(&e.ID, &e.DisplayName, &e.ItemType, &e.WorkspaceID, &e.WorkspaceName,
+			&e.Runs, &e.Failed, &e.RuntimeMs, &e.Value); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
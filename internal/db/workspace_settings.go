@@ -0,0 +1,118 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// SaveWorkspaceSettings sets the overrides of a workspace, replacing any it had
+func (db *Database) SaveWorkspaceSettings(s *WorkspaceSettings) error {
+	var rules *string
+	if s.NotificationRules != nil {
+		encoded, err := json.Marshal(s.NotificationRules)
+		if err != nil {
+			return err
+		}
+		value := string(encoded)
+		rules = &value
+	}
+
+	query := `
+		INSERT INTO workspace_settings (
+			workspace_id, poll_interval_seconds, retention_days, enrichment_enabled, notification_rules, updated_at
+		)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (workspace_id) DO UPDATE SET
+			poll_interval_seconds = EXCLUDED.poll_interval_seconds,
+			retention_days = EXCLUDED.retention_days,
+			enrichment_enabled = EXCLUDED.enrichment_enabled,
+			notification_rules = EXCLUDED.notification_rules,
+			updated_at = EXCLUDED.updated_at
+	`
+	_, err := db.conn.Exec(query, s.WorkspaceID, s.PollIntervalSeconds, s.RetentionDays, s.EnrichmentEnabled, rules, s.UpdatedAt)
+	return err
+}
+
+// DeleteWorkspaceSettings removes the overrides of a workspace, so it follows the global settings again
+func (db *Database) DeleteWorkspaceSettings(workspaceID string) error {
+	_, err := db.conn.Exec(`DELETE FROM workspace_settings WHERE workspace_id = ?`, workspaceID)
+	return err
+}
+
+// GetWorkspaceSettings returns the overrides of every workspace that has any, by workspace name
+func (db *Database) GetWorkspaceSettings() ([]WorkspaceSettings, error) {
+	query := `
+		SELECT s.workspace_id, COALESCE(w.display_name, s.workspace_id),
+			s.poll_interval_seconds, s.retention_days, s.enrichment_enabled, s.notification_rules, s.updated_at
+		FROM workspace_settings s
+		LEFT JOIN workspaces w ON w.id = s.workspace_id
+		ORDER BY COALESCE(w.display_name, s.workspace_id), s.workspace_id
+	`
+
+	rows, err := db.conn.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var settings []WorkspaceSettings
+	for rows.Next() {
+		var s WorkspaceSettings
+		var rules sql.NullString
+		if err := rows.Scan(&s.WorkspaceID, &s.WorkspaceName, &s.PollIntervalSeconds, &s.RetentionDays,
+			&s.EnrichmentEnabled, &rules, &s.UpdatedAt); err != nil {
+			return nil, err
+		}
+		if rules.Valid {
+			if err := json.Unmarshal([]byte(rules.String), &s.NotificationRules); err != nil {
+				return nil, fmt.Errorf("invalid notification rules of workspace %s: %w", s.WorkspaceID, err)
+			}
+		}
+		settings = append(settings, s)
+	}
+	return settings, rows.Err()
+}
+
+// GetWorkspaceSettingsByID returns the overrides of every workspace that has any, keyed by workspace ID
+func (db *Database) GetWorkspaceSettingsByID() (map[string]WorkspaceSettings, error) {
+	settings, err := db.GetWorkspaceSettings()
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]WorkspaceSettings, len(settings))
+	for _, s := range settings {
+		byID[s.WorkspaceID] = s
+	}
+	return byID, nil
+}
+
+// PruneWorkspaceHistory deletes a workspace's runs that started before cutoff, with the alerts, scores and
+// metrics derived from them and its notebook sessions submitted before cutoff, returning how many runs were deleted
+func (db *Database) PruneWorkspaceHistory(workspaceID string, cutoff time.Time) (int64, error) {
+	oldJobs := `SELECT id FROM job_instances WHERE workspace_id = ? AND start_time < ?`
+	for _, table := range []string{"job_alerts", "job_anomalies", "job_duration_regressions", "copy_activity_metrics"} {
+		if _, err := db.conn.Exec("DELETE FROM "+table+" WHERE job_id IN ("+oldJobs+")", workspaceID, cutoff); err != nil {
+			return 0, fmt.Errorf("failed to prune %s: %w", table, err)
+		}
+	}
+
+	result, err := db.conn.Exec(`DELETE FROM job_instances WHERE workspace_id = ? AND start_time < ?`, workspaceID, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune job_instances: %w", err)
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = db.conn.Exec(`
+		DELETE FROM notebook_sessions
+		WHERE workspace_id = ? AND COALESCE(submitted_datetime, start_datetime) < ?
+	`, workspaceID, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune notebook_sessions: %w", err)
+	}
+	return deleted, nil
+}
@@ -0,0 +1,401 @@
+package demo
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"time"
+
+	"better-fabric-monitor/internal/db"
+)
+
+// Summary counts the rows Populate created
+type Summary struct {
+	Workspaces int
+	Items      int
+	Jobs       int
+	Sessions   int
+}
+
+// demoItem describes a synthetic item and how it behaves
+type demoItem struct {
+	name        string
+	itemType    string
+	every       time.Duration // Scheduled run interval
+	duration    time.Duration // Typical run duration
+	failureRate float64
+}
+
+// demoWorkspace describes a synthetic workspace and its items
+type demoWorkspace struct {
+	name     string
+	capacity string
+	items    []demoItem
+	// idleFor leaves the most recent part of the history empty, so the workspace looks dormant
+	idleFor time.Duration
+}
+
+// Capacities the synthetic workspaces are assigned to
+const (
+	productionCapacity = "5f0c9d4e-7a1b-4c2d-9e8f-1a2b3c4d5e6f"
+	sharedCapacity     = "9a8b7c6d-5e4f-4a3b-8c2d-1e0f9a8b7c6d"
+)
+
+var workspaces = []demoWorkspace{
+	{name: "Sales Analytics", capacity: productionCapacity, items: []demoItem{
+		{"Load Sales Orders", "DataPipeline", time.Hour, 12 * time.Minute, 0.04},
+		{"Refresh Sales Model", "DataPipeline", 4 * time.Hour, 25 * time.Minute, 0.02},
+		{"Transform Orders", "Notebook", time.Hour, 8 * time.Minute, 0.06},
+		{"Customer Segmentation", "Notebook", 24 * time.Hour, 45 * time.Minute, 0.1},
+	}},
+	{name: "Finance Reporting", capacity: productionCapacity, items: []demoItem{
+		{"Ingest GL Entries", "DataPipeline", 2 * time.Hour, 18 * time.Minute, 0.03},
+		{"Month End Close", "DataPipeline", 24 * time.Hour, 90 * time.Minute, 0.08},
+		{"Reconcile Accounts", "Notebook", 6 * time.Hour, 20 * time.Minute, 0.3}, // Flaky on purpose
+		{"Finance Dataflow", "Dataflow", 12 * time.Hour, 6 * time.Minute, 0.05},
+	}},
+	{name: "Marketing Data", capacity: sharedCapacity, items: []demoItem{
+		{"Campaign Ingestion", "DataPipeline", 6 * time.Hour, 15 * time.Minute, 0.05},
+		{"Attribution Model", "SparkJobDefinition", 12 * time.Hour, 55 * time.Minute, 0.07},
+		{"Web Events Cleanup", "Notebook", 3 * time.Hour, 10 * time.Minute, 0.02},
+	}},
+	{name: "Operations Lakehouse", capacity: sharedCapacity, items: []demoItem{
+		{"Sensor Stream Compaction", "Notebook", 30 * time.Minute, 4 * time.Minute, 0.01},
+		{"Daily Ops Extract", "DataPipeline", 24 * time.Hour, 35 * time.Minute, 0.04},
+		{"Airflow Orchestrator", "ApacheAirflowJob", 6 * time.Hour, 30 * time.Minute, 0.05},
+	}},
+	{name: "Data Science Sandbox", capacity: sharedCapacity, idleFor: 10 * 24 * time.Hour, items: []demoItem{
+		{"Churn Experiments", "Notebook", 24 * time.Hour, 70 * time.Minute, 0.2},
+		{"Feature Store Backfill", "DataPipeline", 48 * time.Hour, 2 * time.Hour, 0.15},
+	}},
+}
+
+// running lists the items whose latest run is still in progress
+var running = map[string]bool{
+	"Transform Orders":  true,
+	"Month End Close":   true,
+	"Attribution Model": true,
+}
+
+// failures are the error codes and messages used for failed runs
+var failures = []struct {
+	code    string
+	message string
+}{
+	{"UserError", "The source table 'dbo.Orders' was not found."},
+	{"RequestExecutionFailed", "Operation on target Copy data failed: ErrorCode=SqlOperationFailed, timeout expired."},
+	{"JobInstanceFailed", "Notebook execution failed at cell 7: KeyError: 'customer_id'"},
+	{"CapacityNotActive", "The capacity for this workspace is paused or throttled."},
+	{"SparkSessionFailed", "Livy session has failed. Session state: Dead. Reason: out of memory."},
+}
+
+// jobTypes maps item types to the job type Fabric reports for their runs
+var jobTypes = map[string]string{
+	"DataPipeline":       "Pipeline",
+	"Notebook":           "RunNotebook",
+	"SparkJobDefinition": "sparkjob",
+	"Dataflow":           "Refresh",
+	"ApacheAirflowJob":   "Execute",
+}
+
+// generator produces deterministic IDs and run variations from a seeded source
+type generator struct {
+	rand *rand.Rand
+	now  time.Time
+}
+
+// Populate fills the database with synthetic workspaces, items, job runs, failures and Livy sessions
+// covering the days before now, so the app can be demoed without a tenant
+// The same seed produces the same data
+func Populate(database *db.Database, now time.Time, days int, seed int64) (Summary, error) {
+	g := &generator{rand: rand.New(rand.NewSource(seed)), now: now.UTC()}
+	from := g.now.Add(-time.Duration(days) * 24 * time.Hour)
+	var summary Summary
+
+	for _, ws := range workspaces {
+		workspace := &db.Workspace{
+			ID:          g.id(),
+			DisplayName: ws.name,
+			Type:        "Workspace",
+			CapacityID:  &ws.capacity,
+			CreatedAt:   from,
+			UpdatedAt:   g.now,
+		}
+		if err := database.SaveWorkspace(workspace); err != nil {
+			return summary, fmt.Errorf("failed to save workspace %s: %w", ws.name, err)
+		}
+		summary.Workspaces++
+
+		for _, it := range ws.items {
+			discovered := g.now
+			item := &db.Item{
+				ID:             g.id(),
+				WorkspaceID:    workspace.ID,
+				DisplayName:    it.name,
+				Type:           it.itemType,
+				CreatedAt:      from,
+				UpdatedAt:      g.now,
+				LastDiscovered: &discovered,
+			}
+			if err := database.SaveItem(item); err != nil {
+				return summary, fmt.Errorf("failed to save item %s: %w", it.name, err)
+			}
+			summary.Items++
+
+			jobs := g.runs(workspace.ID, item.ID, it, from, g.now.Add(-ws.idleFor))
+			if err := database.SaveJobInstances(jobs); err != nil {
+				return summary, fmt.Errorf("failed to save runs of %s: %w", it.name, err)
+			}
+			summary.Jobs += len(jobs)
+
+			switch it.itemType {
+			case "DataPipeline":
+				for _, job := range jobs {
+					if job.EndTime == nil {
+						continue
+					}
+					if err := database.UpdateJobInstanceActivityRuns(job.ID, g.activityRuns(item, job)); err != nil {
+						return summary, fmt.Errorf("failed to save activity runs of %s: %w", it.name, err)
+					}
+				}
+			case "Notebook":
+				sessions := g.sessions(item, ws.capacity, jobs)
+				if err := database.SaveLivySessions(sessions); err != nil {
+					return summary, fmt.Errorf("failed to save sessions of %s: %w", it.name, err)
+				}
+				summary.Sessions += len(sessions)
+			}
+		}
+	}
+
+	return summary, nil
+}
+
+// runs generates an item's scheduled runs between from and until, plus the odd manual run
+// A run still going at until is left in progress
+func (g *generator) runs(workspaceID, itemID string, it demoItem, from, until time.Time) []db.JobInstance {
+	var jobs []db.JobInstance
+	start := from.Add(time.Duration(g.rand.Int63n(int64(it.every))))
+	for ; start.Before(until); start = start.Add(it.every) {
+		// Scheduled runs start up to a minute and a half late
+		delayed := start.Add(time.Duration(g.rand.Int63n(int64(90 * time.Second))))
+		if !delayed.Before(until) {
+			break
+		}
+		jobs = append(jobs, g.run(workspaceID, itemID, it, delayed, "Scheduled", until))
+		if g.rand.Float64() < 0.03 {
+			manual := start.Add(time.Duration(g.rand.Int63n(int64(it.every))))
+			if manual.Before(until) {
+				jobs = append(jobs, g.run(workspaceID, itemID, it, manual, "Manual", until))
+			}
+		}
+	}
+	if running[it.name] && until.Equal(g.now) {
+		// Runs last at least 60% of the typical duration, so one started half of it ago is still going
+		jobs = append(jobs, g.run(workspaceID, itemID, it, until.Add(-it.duration/2), "Scheduled", until))
+	}
+	return jobs
+}
+
+// run generates a single job run
+func (g *generator) run(workspaceID, itemID string, it demoItem, start time.Time, invoker string, until time.Time) db.JobInstance {
+	job := db.JobInstance{
+		ID:          g.id(),
+		WorkspaceID: workspaceID,
+		ItemID:      itemID,
+		JobType:     jobTypes[it.itemType],
+		StartTime:   start,
+		InvokerType: &invoker,
+	}
+	rootActivityID := g.id()
+	job.RootActivityID = &rootActivityID
+
+	// Durations vary by +/-40%, with an occasional run taking three times as long
+	duration := time.Duration(float64(it.duration) * (0.6 + 0.8*g.rand.Float64()))
+	if g.rand.Float64() < 0.05 {
+		duration *= 3
+	}
+	end := start.Add(duration)
+	if end.After(until) && until.Equal(g.now) {
+		job.Status = "InProgress"
+		return job
+	}
+
+	job.EndTime = &end
+	durationMs := duration.Milliseconds()
+	job.DurationMs = &durationMs
+
+	roll := g.rand.Float64()
+	switch {
+	case roll < it.failureRate:
+		job.Status = "Failed"
+		failure := failures[g.rand.Intn(len(failures))]
+		job.FailureReason = &failure.message
+		details, _ := json.Marshal(map[string]string{
+			"errorCode": failure.code,
+			"message":   failure.message,
+			"requestId": g.id(),
+		})
+		failureDetails := string(details)
+		job.FailureDetails = &failureDetails
+	case roll < it.failureRate+0.01:
+		job.Status = "Cancelled"
+	default:
+		job.Status = "Completed"
+	}
+	return job
+}
+
+// activityRuns generates the activities of a finished pipeline run; a failed run fails at its last activity
+func (g *generator) activityRuns(item *db.Item, job db.JobInstance) []db.ActivityRun {
+	steps := []struct{ name, activityType string }{
+		{"Lookup watermark", "Lookup"},
+		{"Copy source data", "Copy"},
+		{"Transform in notebook", "TridentNotebook"},
+		{"Update watermark", "SqlServerStoredProcedure"},
+	}
+	steps = steps[:2+g.rand.Intn(len(steps)-1)]
+
+	runs := make([]db.ActivityRun, 0, len(steps))
+	stepDuration := job.EndTime.Sub(job.StartTime) / time.Duration(len(steps))
+	start := job.StartTime
+	for i, step := range steps {
+		end := start.Add(stepDuration)
+		run := db.ActivityRun{
+			PipelineID:       item.ID,
+			PipelineRunID:    job.ID,
+			ActivityName:     step.name,
+			ActivityType:     step.activityType,
+			ActivityRunID:    g.id(),
+			Status:           "Succeeded",
+			ActivityRunStart: start.Format(time.RFC3339Nano),
+			ActivityRunEnd:   end.Format(time.RFC3339Nano),
+			DurationInMs:     stepDuration.Milliseconds(),
+		}
+		if step.activityType == "Copy" {
+			run.Output = copyOutput(stepDuration)
+		}
+		if i == len(steps)-1 {
+			switch job.Status {
+			case "Failed":
+				run.Status = "Failed"
+				run.Error = db.ActivityError{ErrorCode: "2200", Message: *job.FailureReason, FailureType: "UserError"}
+			case "Cancelled":
+				run.Status = "Cancelled"
+			}
+		}
+		// Only activities that end up succeeding are retried, so a failed run's error stays its last activity's
+		if step.activityType == "Copy" && run.Status == "Succeeded" {
+			if attempt, ok := transientFailure(run, stepDuration); ok {
+				runs = append(runs, attempt)
+				retryAttempt := 1
+				run.RetryAttempt = &retryAttempt
+				run.ActivityRunStart = attempt.ActivityRunEnd
+				run.DurationInMs -= attempt.DurationInMs
+				run.Output = copyOutput(stepDuration - time.Duration(attempt.DurationInMs)*time.Millisecond)
+			}
+		}
+		runs = append(runs, run)
+		start = end
+	}
+	return runs
+}
+
+// transientFailure returns a failed first attempt of a Copy activity for about one run in ten, timing out after
+// a quarter of its duration, after which the activity is retried
+// Like copyOutput it is derived from the duration and run ID alone so it doesn't change the random sequence
+func transientFailure(run db.ActivityRun, duration time.Duration) (db.ActivityRun, bool) {
+	if duration.Milliseconds()%10 != 0 {
+		return db.ActivityRun{}, false
+	}
+	hash := fnv.New64a()
+	hash.Write([]byte(run.ActivityRunID))
+	attemptIDs := generator{rand: rand.New(rand.NewSource(int64(hash.Sum64())))}
+
+	start, _ := time.Parse(time.RFC3339Nano, run.ActivityRunStart)
+	attempt := run
+	attempt.ActivityRunID = attemptIDs.id()
+	attempt.Status = "Failed"
+	attempt.ActivityRunEnd = start.Add(duration / 4).Format(time.RFC3339Nano)
+	attempt.DurationInMs = (duration / 4).Milliseconds()
+	attempt.Output = nil
+	attempt.Error = db.ActivityError{
+		ErrorCode:   "2200",
+		Message:     "Failure happened on 'Source' side. ErrorCode=SqlFailedToConnect, Connection timed out",
+		FailureType: "SystemError",
+	}
+	return attempt, true
+}
+
+// copyOutput generates the output of a Copy activity that ran for duration, copying about 850 rows of 220 bytes a second
+// It is derived from the duration alone so it doesn't change the random sequence of the other data
+func copyOutput(duration time.Duration) map[string]interface{} {
+	seconds := duration.Seconds()
+	rows := int64(seconds * 850)
+	bytes := rows * 220
+	return map[string]interface{}{
+		"rowsRead":     rows,
+		"rowsCopied":   rows,
+		"dataRead":     bytes,
+		"dataWritten":  bytes,
+		"copyDuration": int64(seconds),
+		"throughput":   float64(bytes) / 1024 / seconds,
+		"executionDetails": []interface{}{map[string]interface{}{
+			"detailedDurations": map[string]interface{}{
+				"queuingDuration":  3,
+				"timeToFirstByte":  2,
+				"transferDuration": int64(seconds) - 5,
+			},
+		}},
+	}
+}
+
+// sessions generates the Livy session behind each notebook run on capacity
+func (g *generator) sessions(item *db.Item, capacity string, jobs []db.JobInstance) []db.NotebookSession {
+	states := map[string]string{
+		"Completed":  "Succeeded",
+		"Failed":     "Failed",
+		"Cancelled":  "Cancelled",
+		"InProgress": "InProgress",
+	}
+
+	sessions := make([]db.NotebookSession, 0, len(jobs))
+	for _, job := range jobs {
+		queued := 5000 + g.rand.Intn(40000)
+		submitted := job.StartTime
+		started := submitted.Add(time.Duration(queued) * time.Millisecond)
+		session := db.NotebookSession{
+			LivyID:            g.id(),
+			JobInstanceID:     job.ID,
+			WorkspaceID:       job.WorkspaceID,
+			NotebookID:        item.ID,
+			State:             states[job.Status],
+			ItemName:          &item.DisplayName,
+			ItemType:          &item.Type,
+			JobType:           &job.JobType,
+			SubmittedDateTime: &submitted,
+			StartDateTime:     &started,
+			QueuedDurationMs:  &queued,
+			CapacityID:        &capacity,
+		}
+		sparkApplicationID := fmt.Sprintf("application_%d_%04d", job.StartTime.Unix(), g.rand.Intn(10000))
+		session.SparkApplicationID = &sparkApplicationID
+		if job.EndTime != nil {
+			end := *job.EndTime
+			total := int(end.Sub(submitted).Milliseconds())
+			running := max(total-queued, 0)
+			session.EndDateTime = &end
+			session.TotalDurationMs = &total
+			session.RunningDurationMs = &running
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions
+}
+
+// id returns a random UUID-formatted identifier
+func (g *generator) id() string {
+	return fmt.Sprintf("%08x-%04x-4%03x-%04x-%012x",
+		g.rand.Uint32(), g.rand.Intn(0x10000), g.rand.Intn(0x1000), 0x8000|g.rand.Intn(0x4000), g.rand.Int63n(1<<48))
+}
@@ -7,11 +7,18 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"slices"
 	"sort"
 	"strings"
+	"sync/atomic"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
 	"better-fabric-monitor/internal/logger"
+	"better-fabric-monitor/internal/tlsconfig"
+	"better-fabric-monitor/internal/tracing"
 )
 
 // FabricTime is a custom time type that can parse Microsoft Fabric's timestamp format
@@ -66,18 +73,100 @@ type Client struct {
 	accessToken string
 	rateLimiter *AdaptiveRateLimiter
 	retryPolicy *RetryPolicy
+	limits      Limits
+	// Request counters, read through RequestStats
+	requests  atomic.Int64
+	attempts  atomic.Int64
+	throttled atomic.Int64
+}
+
+// RequestStats counts the API traffic of a client since it was created
+// Subtract two snapshots to get the traffic of a single operation
+type RequestStats struct {
+	Requests  int64 // Logical requests, each possibly retried
+	Attempts  int64 // HTTP requests actually sent, including retries
+	Throttled int64 // Attempts answered with 429 Too Many Requests
+}
+
+// Retries returns how many attempts were retries of an earlier attempt
+func (s RequestStats) Retries() int64 {
+	return s.Attempts - s.Requests
+}
+
+// Sub returns the traffic between an earlier snapshot and s
+func (s RequestStats) Sub(earlier RequestStats) RequestStats {
+	return RequestStats{
+		Requests:  s.Requests - earlier.Requests,
+		Attempts:  s.Attempts - earlier.Attempts,
+		Throttled: s.Throttled - earlier.Throttled,
+	}
+}
+
+// SupportedJobItemTypes lists the item types that expose job instances
+var SupportedJobItemTypes = []string{
+	"DataPipeline",
+	"Notebook",
+	"SparkJobDefinition",
+	"Dataflow",
+	"ApacheAirflowJob",
+}
+
+// ProgressReporter receives progress notifications while GetRecentJobs runs
+// Methods are called concurrently from worker goroutines and must be safe for concurrent use
+type ProgressReporter interface {
+	// ItemProcessed is called after the job instances of a single item were fetched
+	ItemProcessed(workspaceName, itemName string, jobs int)
+	// ItemFailed is called when the job instances of an item could not be fetched; the workspace carries on without them
+	ItemFailed(workspaceName, itemName string, err error)
+	// WorkspaceCompleted is called once a workspace has been fully processed (err is set if it failed)
+	WorkspaceCompleted(workspaceName string, err error)
 }
 
+// RecentJobsOptions holds the settings of a single GetRecentJobs call
+// They are passed per call rather than set on the client, which is shared by concurrent syncs
+type RecentJobsOptions struct {
+	Limit int // Most recent jobs returned (0 returns all)
+	// Since makes the sync incremental: only jobs started after it are fetched, plus all jobs in progress
+	Since *time.Time
+	// Watermarks holds per-workspace start times used instead of Since for the workspaces they cover
+	Watermarks map[string]time.Time
+	// NotBefore skips job instances started before it, in progress or not (zero keeps the full history)
+	NotBefore time.Time
+	// ExcludedItemTypes lists item types whose job instances are not fetched
+	ExcludedItemTypes []string
+	// CachedItems maps workspace IDs to item lists used instead of listing items from the API
+	CachedItems map[string][]Item
+	// Progress is notified of sync progress (nil disables reporting)
+	Progress ProgressReporter
+	// OnWorkspaceResult receives each workspace's results as soon as they are fetched (nil returns them all at the end)
+	OnWorkspaceResult WorkspaceResultHandler
+}
+
+// WorkspaceResultHandler is called by GetRecentJobs with the items and jobs of each workspace as they are fetched
+// Large workspaces are handed off in several chunks; failed workspaces are not passed on
+// It runs on worker goroutines, so it must be safe for concurrent use; ctx carries the span of the workspace's fetch
+type WorkspaceResultHandler func(ctx context.Context, result WorkspaceResult)
+
 // NewClient creates a new Fabric API client
 func NewClient(accessToken string) *Client {
-	// Configure HTTP transport with proper connection management
-	transport := &http.Transport{
-		MaxIdleConns:        100,              // Maximum idle connections across all hosts
-		MaxIdleConnsPerHost: 10,               // Maximum idle connections per host
-		IdleConnTimeout:     90 * time.Second, // How long idle connections stay open
-		DisableKeepAlives:   false,            // Keep connections alive for reuse
-		ForceAttemptHTTP2:   true,             // Prefer HTTP/2 when available
-	}
+	return NewClientWithLimits(accessToken, DefaultLimits())
+}
+
+// NewClientWithLimits creates a Fabric API client with the concurrency, rate and retry limits in limits, brought
+// within bounds
+func NewClientWithLimits(accessToken string, limits Limits) *Client {
+	limits = limits.Bounded()
+	retryPolicy := NewRetryPolicy()
+	retryPolicy.MaxRetries = limits.MaxRetries
+
+	// Configure HTTP transport with proper connection management, the proxy from the environment and the
+	// configured TLS settings
+	transport := tlsconfig.Transport()
+	transport.MaxIdleConns = 100                 // Maximum idle connections across all hosts
+	transport.MaxIdleConnsPerHost = 10           // Maximum idle connections per host
+	transport.IdleConnTimeout = 90 * time.Second // How long idle connections stay open
+	transport.DisableKeepAlives = false          // Keep connections alive for reuse
+	transport.ForceAttemptHTTP2 = true           // Prefer HTTP/2 when available
 
 	return &Client{
 		httpClient: &http.Client{
@@ -86,8 +175,18 @@ func NewClient(accessToken string) *Client {
 		},
 		baseURL:     "https://api.fabric.microsoft.com/v1",
 		accessToken: accessToken,
-		rateLimiter: NewAdaptiveRateLimiter(),
-		retryPolicy: NewRetryPolicy(),
+		rateLimiter: NewAdaptiveRateLimiter(limits.InitialRPS, limits.MinRPS, limits.MaxRPS),
+		retryPolicy: retryPolicy,
+		limits:      limits,
+	}
+}
+
+// RequestStats returns a snapshot of the client's request counters
+func (c *Client) RequestStats() RequestStats {
+	return RequestStats{
+		Requests:  c.requests.Load(),
+		Attempts:  c.attempts.Load(),
+		Throttled: c.throttled.Load(),
 	}
 }
 
@@ -95,23 +194,61 @@ func NewClient(accessToken string) *Client {
 // endpoint: API endpoint path for logging (e.g., "/workspaces/xyz/items")
 // workspaceName: Workspace display name for context (use "N/A" if not applicable)
 // itemName: Item display name for context (use "N/A" if not applicable)
+// Each request is traced as a span covering the rate limiter wait and all attempts, with throttling as events,
+// and counted in the API health statistics
 func (c *Client) doRequestWithRetry(ctx context.Context, req *http.Request, endpoint, workspaceName, itemName string) (*http.Response, error) {
+	ctx, span := tracing.Start(ctx, "fabric.request",
+		attribute.String("http.request.method", req.Method), attribute.String("fabric.endpoint", endpoint))
+
+	var attempts, throttled int64
+
 	// Wait for rate limiter token
 	c.rateLimiter.Wait()
+	c.requests.Add(1)
+	span.AddEvent("rate limiter token acquired")
+	apiHealth.RecordRPS(time.Now(), c.rateLimiter, c.rateLimiter.GetCurrentRPS())
 
 	// Execute with retry logic
-	return c.retryPolicy.ExecuteWithRetry(
+	resp, err := c.retryPolicy.ExecuteWithRetry(
+		ctx,
 		func() (*http.Response, error) {
-			return c.httpClient.Do(req)
+			c.attempts.Add(1)
+			attempts++
+			resp, err := c.httpClient.Do(req)
+			if resp != nil && resp.StatusCode == http.StatusTooManyRequests {
+				c.throttled.Add(1)
+				throttled++
+			}
+			return resp, err
 		},
-		func() {
+		func(statusCode int, backoff time.Duration) {
+			apiHealth.RecordRetry(time.Now(), endpoint, backoff)
+			if statusCode != http.StatusTooManyRequests {
+				return
+			}
 			// On throttle detected
 			c.rateLimiter.OnThrottle()
+			rps := c.rateLimiter.GetCurrentRPS()
+			apiHealth.RecordRPS(time.Now(), c.rateLimiter, rps)
+			span.AddEvent("throttled", trace.WithAttributes(attribute.Int("fabric.rps", rps)))
 		},
+		logger.FromContext(ctx),
 		endpoint,
 		workspaceName,
 		itemName,
 	)
+	failed := err != nil || resp == nil || resp.StatusCode >= http.StatusBadRequest
+	apiHealth.RecordRequest(time.Now(), endpoint, attempts, throttled, failed && ctx.Err() == nil)
+	span.SetAttributes(attribute.Int64("fabric.attempts", attempts))
+	spanErr := err
+	if resp != nil {
+		span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+		if spanErr == nil && resp.StatusCode >= http.StatusBadRequest {
+			spanErr = fmt.Errorf("request failed with status %d", resp.StatusCode)
+		}
+	}
+	tracing.End(span, spanErr)
+	return resp, err
 }
 
 // Workspace represents a Fabric workspace
@@ -187,6 +324,15 @@ func (ji *JobInstance) GetFailureReasonString() string {
 	return string(ji.FailureReason)
 }
 
+// GetFailureReasonObject returns the raw failure reason if it is a JSON object, or nil otherwise
+func (ji *JobInstance) GetFailureReasonObject() json.RawMessage {
+	trimmed := bytes.TrimSpace(ji.FailureReason)
+	if len(trimmed) == 0 || trimmed[0] != '{' {
+		return nil
+	}
+	return ji.FailureReason
+}
+
 // JobInstancesResponse represents the API response for job instances
 type JobInstancesResponse struct {
 	Value             []JobInstance `json:"value"`
@@ -195,7 +341,10 @@ type JobInstancesResponse struct {
 }
 
 // GetWorkspaces retrieves all workspaces the user has access to
-func (c *Client) GetWorkspaces(ctx context.Context) ([]Workspace, error) {
+func (c *Client) GetWorkspaces(ctx context.Context) (_ []Workspace, err error) {
+	ctx, span := tracing.Start(ctx, "fabric.list_workspaces")
+	defer func() { tracing.End(span, err) }()
+
 	url := fmt.Sprintf("%s/workspaces", c.baseURL)
 
 	var allWorkspaces []Workspace
@@ -217,7 +366,7 @@ func (c *Client) GetWorkspaces(ctx context.Context) ([]Workspace, error) {
 
 		if resp.StatusCode != http.StatusOK {
 			body, _ := io.ReadAll(resp.Body)
-			return nil, fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+			return nil, &APIError{StatusCode: resp.StatusCode, Body: string(body)}
 		}
 
 		var response WorkspacesResponse
@@ -231,11 +380,15 @@ func (c *Client) GetWorkspaces(ctx context.Context) ([]Workspace, error) {
 		url = response.ContinuationURI
 	}
 
+	span.SetAttributes(attribute.Int("fabric.workspaces", len(allWorkspaces)))
 	return allWorkspaces, nil
 }
 
 // GetWorkspaceItems retrieves all items in a workspace
-func (c *Client) GetWorkspaceItems(ctx context.Context, workspaceID, workspaceName string) ([]Item, error) {
+func (c *Client) GetWorkspaceItems(ctx context.Context, workspaceID, workspaceName string) (_ []Item, err error) {
+	ctx, span := tracing.Start(ctx, "fabric.list_items", tracing.WorkspaceID(workspaceID))
+	defer func() { tracing.End(span, err) }()
+
 	url := fmt.Sprintf("%s/workspaces/%s/items", c.baseURL, workspaceID)
 
 	var allItems []Item
@@ -257,7 +410,7 @@ func (c *Client) GetWorkspaceItems(ctx context.Context, workspaceID, workspaceNa
 
 		if resp.StatusCode != http.StatusOK {
 			body, _ := io.ReadAll(resp.Body)
-			return nil, fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+			return nil, &APIError{StatusCode: resp.StatusCode, Body: string(body)}
 		}
 
 		var response ItemsResponse
@@ -276,11 +429,15 @@ func (c *Client) GetWorkspaceItems(ctx context.Context, workspaceID, workspaceNa
 		url = response.ContinuationURI
 	}
 
+	span.SetAttributes(attribute.Int("fabric.items", len(allItems)))
 	return allItems, nil
 }
 
 // GetItemJobInstances retrieves job instances for a specific item
-func (c *Client) GetItemJobInstances(ctx context.Context, workspaceID, itemID, workspaceName, itemName string) ([]JobInstance, error) {
+func (c *Client) GetItemJobInstances(ctx context.Context, workspaceID, itemID, workspaceName, itemName string) (_ []JobInstance, err error) {
+	ctx, span := tracing.Start(ctx, "fabric.list_job_instances", tracing.WorkspaceID(workspaceID), tracing.ItemID(itemID))
+	defer func() { tracing.End(span, err) }()
+
 	url := fmt.Sprintf("%s/workspaces/%s/items/%s/jobs/instances", c.baseURL, workspaceID, itemID)
 
 	var allInstances []JobInstance
@@ -302,7 +459,7 @@ func (c *Client) GetItemJobInstances(ctx context.Context, workspaceID, itemID, w
 
 		if resp.StatusCode != http.StatusOK {
 			body, _ := io.ReadAll(resp.Body)
-			return nil, fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+			return nil, &APIError{StatusCode: resp.StatusCode, Body: string(body)}
 		}
 
 		var response JobInstancesResponse
@@ -316,9 +473,42 @@ func (c *Client) GetItemJobInstances(ctx context.Context, workspaceID, itemID, w
 		url = response.ContinuationURI
 	}
 
+	span.SetAttributes(attribute.Int("fabric.job_instances", len(allInstances)))
 	return allInstances, nil
 }
 
+// GetItemJobInstance retrieves a single job instance of an item, e.g. to refresh the status of a running job
+func (c *Client) GetItemJobInstance(ctx context.Context, workspaceID, itemID, jobInstanceID, workspaceName, itemName string) (_ *JobInstance, err error) {
+	ctx, span := tracing.Start(ctx, "fabric.get_job_instance", tracing.WorkspaceID(workspaceID), tracing.ItemID(itemID), tracing.JobID(jobInstanceID))
+	defer func() { tracing.End(span, err) }()
+
+	url := fmt.Sprintf("%s/workspaces/%s/items/%s/jobs/instances/%s", c.baseURL, workspaceID, itemID, jobInstanceID)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+c.accessToken)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.doRequestWithRetry(ctx, req, fmt.Sprintf("/workspaces/%s/items/%s/jobs/instances/%s", workspaceID, itemID, jobInstanceID), workspaceName, itemName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, &APIError{StatusCode: resp.StatusCode, Body: string(body)}
+	}
+
+	var instance JobInstance
+	if err := json.NewDecoder(resp.Body).Decode(&instance); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return &instance, nil
+}
+
 // QueryActivityRunsResponse represents the response from the QueryActivityRuns API
 type QueryActivityRunsResponse struct {
 	Value             []ActivityRun `json:"value"`
@@ -326,7 +516,10 @@ type QueryActivityRunsResponse struct {
 }
 
 // QueryActivityRuns retrieves all activity runs for a pipeline job instance with pagination support
-func (c *Client) QueryActivityRuns(ctx context.Context, workspaceID, jobInstanceID string, startTime, endTime time.Time) ([]ActivityRun, error) {
+func (c *Client) QueryActivityRuns(ctx context.Context, workspaceID, jobInstanceID string, startTime, endTime time.Time) (_ []ActivityRun, err error) {
+	ctx, span := tracing.Start(ctx, "fabric.query_activity_runs", tracing.WorkspaceID(workspaceID), tracing.JobID(jobInstanceID))
+	defer func() { tracing.End(span, err) }()
+
 	url := fmt.Sprintf("%s/workspaces/%s/datapipelines/pipelineruns/%s/queryactivityruns",
 		c.baseURL, workspaceID, jobInstanceID)
 
@@ -372,7 +565,7 @@ func (c *Client) QueryActivityRuns(ctx context.Context, workspaceID, jobInstance
 
 		if resp.StatusCode != http.StatusOK {
 			body, _ := io.ReadAll(resp.Body)
-			return nil, fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+			return nil, &APIError{StatusCode: resp.StatusCode, Body: string(body)}
 		}
 
 		// Read the response body
@@ -392,8 +585,8 @@ func (c *Client) QueryActivityRuns(ctx context.Context, workspaceID, jobInstance
 		allActivityRuns = append(allActivityRuns, response.Value...)
 
 		if len(response.Value) > 0 {
-			fmt.Printf("Fetched %d activity runs for job %s (page %d, total so far: %d)\n",
-				len(response.Value), jobInstanceID, pageCount, len(allActivityRuns))
+			logger.FromContext(ctx).Debug("Fetched activity runs page", logger.JobID(jobInstanceID), "page", pageCount,
+				"activities", len(response.Value), "total", len(allActivityRuns))
 		}
 
 		// Check if we need to fetch more pages
@@ -404,8 +597,9 @@ func (c *Client) QueryActivityRuns(ctx context.Context, workspaceID, jobInstance
 		continuationToken = response.ContinuationToken
 	}
 
+	span.SetAttributes(attribute.Int("fabric.activity_runs", len(allActivityRuns)), attribute.Int("fabric.pages", pageCount))
 	if len(allActivityRuns) > 0 {
-		fmt.Printf("Total activity runs fetched for job %s: %d (across %d pages)\n", jobInstanceID, len(allActivityRuns), pageCount)
+		logger.FromContext(ctx).Debug("Fetched activity runs", logger.JobID(jobInstanceID), "activities", len(allActivityRuns), "pages", pageCount)
 	}
 
 	return allActivityRuns, nil
@@ -502,32 +696,96 @@ type LivySessionsResponse struct {
 	ContinuationURI   string        `json:"continuationUri"`
 }
 
+// RecentJob is a job instance returned by GetRecentJobs, flattened with its workspace and item
+type RecentJob struct {
+	ID              string
+	WorkspaceID     string
+	WorkspaceName   string
+	ItemID          string
+	ItemDisplayName string
+	ItemType        string
+	JobType         string
+	InvokeType      string // Scheduled, Manual or how else the run was started
+	Status          string
+	StartTime       time.Time
+	EndTime         *time.Time // nil while the job is in progress
+	DurationMs      *int64
+	FailureReason   string
+	FailureDetails  json.RawMessage // Original failureReason payload when the API returned an object
+	RootActivityID  string
+}
+
+// NewRecentJob flattens a job instance with the workspace and item it belongs to
+func NewRecentJob(workspace Workspace, item Item, instance JobInstance) RecentJob {
+	job := RecentJob{
+		ID:              instance.ID,
+		WorkspaceID:     workspace.ID,
+		WorkspaceName:   workspace.DisplayName,
+		ItemID:          item.ID,
+		ItemDisplayName: item.DisplayName,
+		ItemType:        item.Type,
+		JobType:         instance.JobType,
+		InvokeType:      instance.InvokeType,
+		Status:          instance.Status,
+		StartTime:       instance.StartTimeUtc.Time,
+		FailureReason:   instance.GetFailureReasonString(),
+		FailureDetails:  instance.GetFailureReasonObject(),
+		RootActivityID:  instance.RootActivityID,
+	}
+
+	if !instance.EndTimeUtc.Time.IsZero() {
+		endTime := instance.EndTimeUtc.Time
+		durationMs := int64(endTime.Sub(instance.StartTimeUtc.Time) / time.Millisecond)
+		job.EndTime = &endTime
+		job.DurationMs = &durationMs
+	}
+
+	return job
+}
+
+// handOffWorkspaceResult passes the jobs and items collected so far to handler and clears them
+// Without a handler the result is left untouched so GetRecentJobs can return everything at the end
+func handOffWorkspaceResult(ctx context.Context, handler WorkspaceResultHandler, result *WorkspaceResult) {
+	if handler == nil {
+		return
+	}
+	handler(ctx, *result)
+	result.Jobs = []RecentJob{}
+	result.Items = []Item{}
+	result.ReturnedJobIDs = make(map[string][]string)
+}
+
 // GetRecentJobs retrieves recent job instances across all workspaces in Fabric with parallel processing
-// If startTimeFrom is provided, only fetches jobs with start_time > startTimeFrom
+// If opts.Since is provided, only fetches jobs with start_time > opts.Since
 // Always fetches jobs with end_time IS NULL (in progress) regardless of start time
-// cachedItems can be provided to avoid fetching items from API (optimization for incremental syncs)
-func (c *Client) GetRecentJobs(ctx context.Context, workspaces []Workspace, limit int, startTimeFrom *time.Time, cachedItems map[string][]Item) ([]map[string]interface{}, []Item, error) {
-	// Item types that support job instances
-	supportedTypes := map[string]bool{
-		"DataPipeline":       true,
-		"Notebook":           true,
-		"SparkJobDefinition": true,
-		"Dataflow":           true,
-		"ApacheAirflowJob":   true,
+// When opts.OnWorkspaceResult is set, jobs and items are handed to it in chunks of at most MaxJobsPerChunk jobs
+// (plus one item's worth) instead of being returned, so memory stays bounded on very large tenants
+func (c *Client) GetRecentJobs(ctx context.Context, workspaces []Workspace, opts RecentJobsOptions) ([]RecentJob, []Item, error) {
+	startTimeFrom := opts.Since
+	ctx, span := tracing.Start(ctx, "fabric.fetch_jobs",
+		attribute.Int("fabric.workspaces", len(workspaces)), attribute.Bool("sync.incremental", startTimeFrom != nil))
+	defer span.End()
+	log := logger.FromContext(ctx)
+
+	// Item types that support job instances, minus any the user turned off
+	supportedTypes := make(map[string]bool, len(SupportedJobItemTypes))
+	for _, itemType := range SupportedJobItemTypes {
+		if !slices.Contains(opts.ExcludedItemTypes, itemType) {
+			supportedTypes[itemType] = true
+		}
 	}
 
 	if startTimeFrom != nil {
-		fmt.Printf("Fetching jobs from %d workspaces (incremental sync from %s)...\n", len(workspaces), startTimeFrom.Format(time.RFC3339))
-		fmt.Printf("Rate limiter: %d RPS\n", c.rateLimiter.GetCurrentRPS())
+		log.Info("Fetching jobs (incremental sync)", "workspaces", len(workspaces), "since", startTimeFrom.Format(time.RFC3339),
+			"rps", c.rateLimiter.GetCurrentRPS())
 	} else {
-		fmt.Printf("Fetching jobs from %d workspaces (full sync)...\n", len(workspaces))
-		fmt.Printf("Rate limiter: %d RPS\n", c.rateLimiter.GetCurrentRPS())
+		log.Info("Fetching jobs (full sync)", "workspaces", len(workspaces), "rps", c.rateLimiter.GetCurrentRPS())
 	}
 
 	startTime := time.Now()
 
 	// Create workspace worker pool
-	workspacePool := NewWorkerPool(MaxWorkspaceConcurrency)
+	workspacePool := NewWorkerPool(c.limits.WorkspaceConcurrency)
 
 	// Channel to collect results
 	workspaceResults := make(chan WorkspaceResult, len(workspaces))
@@ -537,22 +795,48 @@ func (c *Client) GetRecentJobs(ctx context.Context, workspaces []Workspace, limi
 		workspace := workspace // Capture for goroutine
 
 		workspacePool.Submit(ctx, func() error {
+			ctx, span := tracing.Start(ctx, "fabric.fetch_workspace_jobs", tracing.WorkspaceID(workspace.ID))
 			result := WorkspaceResult{
-				WorkspaceID:   workspace.ID,
-				WorkspaceName: workspace.DisplayName,
-				Jobs:          []map[string]interface{}{},
-				Items:         []Item{},
+				WorkspaceID:    workspace.ID,
+				WorkspaceName:  workspace.DisplayName,
+				Jobs:           []RecentJob{},
+				Items:          []Item{},
+				ReturnedJobIDs: make(map[string][]string),
 			}
 
-			// Get items for this workspace
-			items, err := c.GetWorkspaceItems(ctx, workspace.ID, workspace.DisplayName)
-			if err != nil {
-				result.Error = fmt.Errorf("failed to get items: %w", err)
+			// Hand off what is left of the workspace's results and report completion once this function returns
+			defer func() {
+				if result.Error == nil {
+					handOffWorkspaceResult(ctx, opts.OnWorkspaceResult, &result)
+				}
+				tracing.End(span, result.Error)
+				if opts.Progress != nil {
+					opts.Progress.WorkspaceCompleted(workspace.DisplayName, result.Error)
+				}
 				workspaceResults <- result
-				return nil // Continue with other workspaces
+			}()
+
+			// Incremental syncs resume each workspace from its own watermark when there is one
+			since := startTimeFrom
+			if watermark, ok := opts.Watermarks[workspace.ID]; ok && startTimeFrom != nil {
+				since = &watermark
 			}
 
-			result.Items = items
+			// Reuse the cached item list when the caller has one, otherwise list items from the API
+			// Only freshly listed items are returned in result.Items
+			items, cached := opts.CachedItems[workspace.ID]
+			span.SetAttributes(attribute.Bool("fabric.items_cached", cached))
+			if cached {
+				log.Debug("Using cached items", "workspace", workspace.DisplayName, logger.WorkspaceID(workspace.ID), "items", len(items))
+			} else {
+				var err error
+				items, err = c.GetWorkspaceItems(ctx, workspace.ID, workspace.DisplayName)
+				if err != nil {
+					result.Error = fmt.Errorf("failed to get items: %w", err)
+					return nil // Continue with other workspaces
+				}
+				result.Items = items
+			}
 
 			// Filter to supported items
 			var supportedItems []Item
@@ -562,16 +846,15 @@ func (c *Client) GetRecentJobs(ctx context.Context, workspaces []Workspace, limi
 				}
 			}
 
-			fmt.Printf("[%s] Found %d items, %d with job support\n",
-				workspace.DisplayName, len(items), len(supportedItems))
+			log.Debug("Found items", "workspace", workspace.DisplayName, logger.WorkspaceID(workspace.ID),
+				"items", len(items), "withJobs", len(supportedItems))
 
 			if len(supportedItems) == 0 {
-				workspaceResults <- result
 				return nil
 			}
 
 			// Create item worker pool for this workspace
-			itemPool := NewWorkerPool(MaxItemConcurrency)
+			itemPool := NewWorkerPool(c.limits.ItemConcurrency)
 			itemResults := make(chan ItemResult, len(supportedItems))
 
 			// Process each item in parallel
@@ -583,7 +866,7 @@ func (c *Client) GetRecentJobs(ctx context.Context, workspaces []Workspace, limi
 						WorkspaceID:   workspace.ID,
 						WorkspaceName: workspace.DisplayName,
 						Item:          item,
-						Jobs:          []map[string]interface{}{},
+						Jobs:          []RecentJob{},
 					}
 
 					instances, err := c.GetItemJobInstances(ctx, workspace.ID, item.ID, workspace.DisplayName, item.DisplayName)
@@ -596,6 +879,13 @@ func (c *Client) GetRecentJobs(ctx context.Context, workspaces []Workspace, limi
 					// Filter jobs based on incremental sync criteria
 					var filteredInstances []JobInstance
 					for _, instance := range instances {
+						itemResult.InstanceIDs = append(itemResult.InstanceIDs, instance.ID)
+
+						// Jobs older than the lookback window are never stored
+						if !opts.NotBefore.IsZero() && instance.StartTimeUtc.Time.Before(opts.NotBefore) {
+							continue
+						}
+
 						// Always include jobs with no end time (in progress)
 						if instance.EndTimeUtc.Time.IsZero() {
 							filteredInstances = append(filteredInstances, instance)
@@ -603,8 +893,8 @@ func (c *Client) GetRecentJobs(ctx context.Context, workspaces []Workspace, limi
 						}
 
 						// If doing incremental sync, only include jobs newer than last sync
-						if startTimeFrom != nil {
-							if instance.StartTimeUtc.Time.After(*startTimeFrom) {
+						if since != nil {
+							if instance.StartTimeUtc.Time.After(*since) {
 								filteredInstances = append(filteredInstances, instance)
 							}
 						} else {
@@ -613,36 +903,12 @@ func (c *Client) GetRecentJobs(ctx context.Context, workspaces []Workspace, limi
 						}
 					}
 
-					// Convert to map format for frontend
 					for _, instance := range filteredInstances {
-						job := map[string]interface{}{
-							"id":              instance.ID,
-							"workspaceId":     workspace.ID,
-							"workspaceName":   workspace.DisplayName,
-							"itemId":          item.ID,
-							"itemDisplayName": item.DisplayName,
-							"itemType":        item.Type,
-							"jobType":         instance.JobType,
-							"status":          instance.Status,
-							"startTime":       instance.StartTimeUtc.Time.Format(time.RFC3339),
-						}
-
-						if !instance.EndTimeUtc.Time.IsZero() {
-							job["endTime"] = instance.EndTimeUtc.Time.Format(time.RFC3339)
-							duration := instance.EndTimeUtc.Time.Sub(instance.StartTimeUtc.Time)
-							job["durationMs"] = int64(duration / time.Millisecond)
-						}
-
-						failureReason := instance.GetFailureReasonString()
-						if failureReason != "" {
-							job["failureReason"] = failureReason
-						}
-
-						if instance.RootActivityID != "" {
-							job["rootActivityId"] = instance.RootActivityID
-						}
+						itemResult.Jobs = append(itemResult.Jobs, NewRecentJob(workspace, item, instance))
+					}
 
-						itemResult.Jobs = append(itemResult.Jobs, job)
+					if opts.Progress != nil {
+						opts.Progress.ItemProcessed(workspace.DisplayName, item.DisplayName, len(itemResult.Jobs))
 					}
 
 					itemResults <- itemResult
@@ -650,20 +916,29 @@ func (c *Client) GetRecentJobs(ctx context.Context, workspaces []Workspace, limi
 				})
 			}
 
-			// Wait for all items to complete
-			itemPool.Wait()
-			close(itemResults)
+			// Close the results channel once all items complete
+			go func() {
+				itemPool.Wait()
+				close(itemResults)
+			}()
 
-			// Collect item results
+			// Collect item results as they arrive, handing off full chunks so large workspaces stay memory-bounded
 			for itemResult := range itemResults {
 				if itemResult.Error != nil {
-					logger.Log("  [%s] Warning: %v\n", itemResult.Item.DisplayName, itemResult.Error)
+					log.Warn("Failed to fetch item jobs", "item", itemResult.Item.DisplayName,
+						logger.WorkspaceID(workspace.ID), logger.ItemID(itemResult.Item.ID), logger.Err(itemResult.Error))
+					if opts.Progress != nil {
+						opts.Progress.ItemFailed(workspace.DisplayName, itemResult.Item.DisplayName, itemResult.Error)
+					}
 					continue
 				}
 				result.Jobs = append(result.Jobs, itemResult.Jobs...)
+				result.ReturnedJobIDs[itemResult.Item.ID] = itemResult.InstanceIDs
+				if len(result.Jobs) >= MaxJobsPerChunk {
+					handOffWorkspaceResult(ctx, opts.OnWorkspaceResult, &result)
+				}
 			}
 
-			workspaceResults <- result
 			return nil
 		})
 	}
@@ -673,7 +948,7 @@ func (c *Client) GetRecentJobs(ctx context.Context, workspaces []Workspace, limi
 	close(workspaceResults)
 
 	// Collect all results
-	var allJobs []map[string]interface{}
+	var allJobs []RecentJob
 	var allItems []Item
 	var errors []string
 
@@ -687,34 +962,31 @@ func (c *Client) GetRecentJobs(ctx context.Context, workspaces []Workspace, limi
 	}
 
 	elapsed := time.Since(startTime)
-	fmt.Printf("\nCompleted in %v\n", elapsed)
-	fmt.Printf("Total jobs found: %d across %d workspaces\n", len(allJobs), len(workspaces))
-	fmt.Printf("Final rate limiter: %d RPS\n", c.rateLimiter.GetCurrentRPS())
-
-	if len(errors) > 0 {
-		fmt.Printf("Errors encountered: %d\n", len(errors))
-		for _, err := range errors {
-			fmt.Printf("  - %s\n", err)
-		}
+	span.SetAttributes(attribute.Int("fabric.jobs", len(allJobs)), attribute.Int("fabric.failed_workspaces", len(errors)))
+	log.Info("Fetched jobs", "jobs", len(allJobs), "workspaces", len(workspaces), "duration", elapsed,
+		"rps", c.rateLimiter.GetCurrentRPS(), "errors", len(errors))
+	for _, err := range errors {
+		log.Warn("Workspace sync failed", "error", err)
 	}
 
 	// Sort by start time (most recent first)
 	sort.Slice(allJobs, func(i, j int) bool {
-		timeI, _ := time.Parse(time.RFC3339, allJobs[i]["startTime"].(string))
-		timeJ, _ := time.Parse(time.RFC3339, allJobs[j]["startTime"].(string))
-		return timeI.After(timeJ)
+		return allJobs[i].StartTime.After(allJobs[j].StartTime)
 	})
 
 	// Limit results (0 means no limit)
-	if limit > 0 && len(allJobs) > limit {
-		allJobs = allJobs[:limit]
+	if opts.Limit > 0 && len(allJobs) > opts.Limit {
+		allJobs = allJobs[:opts.Limit]
 	}
 
 	return allJobs, allItems, nil
 }
 
 // GetLivySessions retrieves Livy sessions for a specific notebook with pagination support
-func (c *Client) GetLivySessions(ctx context.Context, workspaceID, notebookID string, continuationToken string) (*LivySessionsResponse, error) {
+func (c *Client) GetLivySessions(ctx context.Context, workspaceID, notebookID string, continuationToken string) (_ *LivySessionsResponse, err error) {
+	ctx, span := tracing.Start(ctx, "fabric.list_livy_sessions", tracing.WorkspaceID(workspaceID), tracing.ItemID(notebookID))
+	defer func() { tracing.End(span, err) }()
+
 	url := fmt.Sprintf("%s/workspaces/%s/notebooks/%s/livySessions", c.baseURL, workspaceID, notebookID)
 	if continuationToken != "" {
 		url += "?continuationToken=" + continuationToken
@@ -736,7 +1008,7 @@ func (c *Client) GetLivySessions(ctx context.Context, workspaceID, notebookID st
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+		return nil, &APIError{StatusCode: resp.StatusCode, Body: string(body)}
 	}
 
 	var response LivySessionsResponse
@@ -0,0 +1,136 @@
+package fabric
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"better-fabric-monitor/internal/logger"
+)
+
+// newTestClient returns a client sending its requests to a fake API serving one workspace with a notebook and a
+// pipeline, each with a run that finished two hours ago and one that finished ten minutes ago
+func newTestClient(t *testing.T) *Client {
+	t.Helper()
+	now := time.Now().UTC()
+	items := []Item{
+		{ID: "notebook", DisplayName: "Notebook", Type: "Notebook", WorkspaceID: "ws"},
+		{ID: "pipeline", DisplayName: "Pipeline", Type: "DataPipeline", WorkspaceID: "ws"},
+	}
+	run := func(id string, ago time.Duration) JobInstance {
+		start := now.Add(-ago)
+		return JobInstance{ID: id, Status: "Completed", StartTimeUtc: FabricTime{start}, EndTimeUtc: FabricTime{start.Add(time.Minute)}}
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/workspaces/ws/items"):
+			json.NewEncoder(w).Encode(ItemsResponse{Value: items})
+		case strings.HasSuffix(r.URL.Path, "/jobs/instances"):
+			itemID := strings.Split(r.URL.Path, "/")[len(strings.Split(r.URL.Path, "/"))-3]
+			json.NewEncoder(w).Encode(JobInstancesResponse{Value: []JobInstance{
+				run(itemID+"-old", 2*time.Hour),
+				run(itemID+"-new", 10*time.Minute),
+			}})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	limits := DefaultLimits()
+	limits.InitialRPS = limits.MaxRPS
+	client := NewClientWithLimits("token", limits)
+	client.baseURL = server.URL
+	return client
+}
+
+// GetRecentJobs calls running at once on a shared client each apply their own options
+func TestGetRecentJobsConcurrentOptions(t *testing.T) {
+	client := newTestClient(t)
+	workspaces := []Workspace{{ID: "ws", DisplayName: "Workspace"}}
+	since := time.Now().UTC().Add(-time.Hour)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(3)
+
+		// Incremental, pipelines excluded: only the notebook's recent run, logged through the call's own logger
+		go func() {
+			defer wg.Done()
+			var records bytes.Buffer
+			ctx := logger.NewContext(context.Background(), slog.New(slog.NewTextHandler(&records, nil)))
+			jobs, _, err := client.GetRecentJobs(ctx, workspaces, RecentJobsOptions{
+				Since:             &since,
+				ExcludedItemTypes: []string{"DataPipeline"},
+			})
+			if err != nil {
+				t.Errorf("incremental: %v", err)
+				return
+			}
+			if len(jobs) != 1 || jobs[0].ID != "notebook-new" {
+				t.Errorf("incremental jobs = %v, want [notebook-new]", jobIDs(jobs))
+			}
+			if n := strings.Count(records.String(), "Fetched jobs"); n != 1 {
+				t.Errorf("incremental call logged %d fetch summaries, want its own 1", n)
+			}
+		}()
+
+		// Full load handed to a result handler: every run of both items, none returned
+		go func() {
+			defer wg.Done()
+			var mu sync.Mutex
+			var handled []RecentJob
+			jobs, _, err := client.GetRecentJobs(context.Background(), workspaces, RecentJobsOptions{
+				OnWorkspaceResult: func(_ context.Context, result WorkspaceResult) {
+					mu.Lock()
+					handled = append(handled, result.Jobs...)
+					mu.Unlock()
+				},
+			})
+			if err != nil {
+				t.Errorf("full: %v", err)
+				return
+			}
+			if len(jobs) != 0 || len(handled) != 4 {
+				t.Errorf("full load returned %d jobs and handled %d, want 0 and 4", len(jobs), len(handled))
+			}
+		}()
+
+		// Lookback cutoff with a workspace watermark older than it: only the recent runs
+		go func() {
+			defer wg.Done()
+			old := time.Now().UTC().Add(-3 * time.Hour)
+			jobs, _, err := client.GetRecentJobs(context.Background(), workspaces, RecentJobsOptions{
+				Since:      &old,
+				Watermarks: map[string]time.Time{"ws": old},
+				NotBefore:  since,
+			})
+			if err != nil {
+				t.Errorf("lookback: %v", err)
+				return
+			}
+			if len(jobs) != 2 {
+				t.Errorf("lookback jobs = %v, want the two recent runs", jobIDs(jobs))
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// jobIDs returns the IDs of jobs
+func jobIDs(jobs []RecentJob) []string {
+	ids := make([]string, len(jobs))
+	for i, job := range jobs {
+		ids[i] = job.ID
+	}
+	return ids
+}
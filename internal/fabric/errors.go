@@ -0,0 +1,38 @@
+package fabric
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// APIError is returned when the Fabric API answers with a non-success status
+type APIError struct {
+	StatusCode int
+	Body       string
+}
+
+// Error formats the status and response body
+func (e *APIError) Error() string {
+	return fmt.Sprintf("API request failed with status %d: %s", e.StatusCode, e.Body)
+}
+
+// IsRetryable reports whether a failed request is likely to succeed if the sync is run again
+// Throttling, server errors and network failures are transient; authorization, missing items
+// and other client errors will fail the same way until something changes on the Fabric side
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.StatusCode {
+		case http.StatusTooManyRequests, http.StatusRequestTimeout:
+			return true
+		}
+		return apiErr.StatusCode >= 500
+	}
+	return true
+}
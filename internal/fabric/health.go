@@ -0,0 +1,253 @@
+package fabric
+
+import (
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// HealthWindow is how long API health statistics are kept
+	HealthWindow = 24 * time.Hour
+	// maxRPSSamples bounds the rate limiter history kept within HealthWindow
+	maxRPSSamples = 1000
+)
+
+// endpointIDs match the workspace, item and job IDs in endpoint paths, so requests to the same kind of resource
+// are counted together
+var endpointIDs = regexp.MustCompile(`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`)
+
+// Reasons the rate limiter's rate was recorded in the RPS history
+const (
+	RPSReasonStart     = "start"     // First request seen by a client
+	RPSReasonThrottled = "throttled" // Lowered after a 429 response
+	RPSReasonRecovered = "recovered" // Raised again after the throttle cooldown
+)
+
+// EndpointHealth is the API traffic to one endpoint, with IDs in its path replaced by {id}
+type EndpointHealth struct {
+	Endpoint          string  `json:"endpoint"`
+	Hour              string  `json:"hour,omitempty"` // RFC3339 start of the hour, set in HealthReport.Hourly
+	Requests          int64   `json:"requests"`       // Logical requests, each possibly retried
+	Attempts          int64   `json:"attempts"`       // HTTP requests actually sent, including retries
+	Throttled         int64   `json:"throttled"`      // Attempts answered with 429 Too Many Requests
+	Failed            int64   `json:"failed"`         // Requests that still failed after their last attempt
+	Retries           int64   `json:"retries"`
+	TotalRetryDelayMs int64   `json:"totalRetryDelayMs"` // Time spent backing off before retries
+	AvgRetryDelayMs   float64 `json:"avgRetryDelayMs"`
+	MaxRetryDelayMs   int64   `json:"maxRetryDelayMs"`
+}
+
+// RPSSample is the rate limiter's requests per second from At until the next sample
+type RPSSample struct {
+	At     string `json:"at"` // RFC3339
+	RPS    int    `json:"rps"`
+	Reason string `json:"reason"` // One of the RPSReason constants
+}
+
+// HealthReport summarizes API traffic, throttling and retries over the last hours, explaining slow syncs
+type HealthReport struct {
+	Since      string           `json:"since"`      // RFC3339 start of the first hour covered
+	CurrentRPS int              `json:"currentRps"` // Rate of the client that sent the last request (0 before any request)
+	Totals     EndpointHealth   `json:"totals"`     // All endpoints together
+	Endpoints  []EndpointHealth `json:"endpoints"`  // Per endpoint, most throttled first
+	Hourly     []EndpointHealth `json:"hourly"`     // Per endpoint and hour, oldest hour first
+	RPSHistory []RPSSample      `json:"rpsHistory"` // Rate changes, oldest first
+}
+
+// healthKey identifies the counters of one endpoint in one hour
+type healthKey struct {
+	hour     time.Time
+	endpoint string
+}
+
+// HealthMonitor aggregates the API traffic of every client into hourly counters per endpoint and keeps the history
+// of the rate limiter's rate, for HealthWindow
+type HealthMonitor struct {
+	mu      sync.Mutex
+	buckets map[healthKey]*EndpointHealth
+	samples []RPSSample
+	lastRPS int
+	sampled map[*AdaptiveRateLimiter]bool // Rate limiters whose starting rate was recorded
+}
+
+// apiHealth receives the traffic of all clients, which are replaced when the token is refreshed
+var apiHealth = NewHealthMonitor()
+
+// NewHealthMonitor creates an empty HealthMonitor
+func NewHealthMonitor() *HealthMonitor {
+	return &HealthMonitor{
+		buckets: make(map[healthKey]*EndpointHealth),
+		sampled: make(map[*AdaptiveRateLimiter]bool),
+	}
+}
+
+// APIHealth returns the API health of all clients over the last hours, at most HealthWindow
+func APIHealth(hours int) HealthReport {
+	return apiHealth.Report(time.Now(), hours)
+}
+
+// bucket returns the counters of endpoint in the hour of at, creating them and dropping expired ones as needed
+// The caller must hold mu
+func (m *HealthMonitor) bucket(at time.Time, endpoint string) *EndpointHealth {
+	key := healthKey{at.UTC().Truncate(time.Hour), endpointIDs.ReplaceAllString(endpoint, "{id}")}
+	counts, ok := m.buckets[key]
+	if !ok {
+		m.prune(at)
+		counts = &EndpointHealth{Endpoint: key.endpoint}
+		m.buckets[key] = counts
+	}
+	return counts
+}
+
+// prune drops the hours older than HealthWindow and the rate samples superseded before it; the caller must hold mu
+func (m *HealthMonitor) prune(now time.Time) {
+	cutoff := now.UTC().Add(-HealthWindow).Truncate(time.Hour)
+	for key := range m.buckets {
+		if key.hour.Before(cutoff) {
+			delete(m.buckets, key)
+		}
+	}
+	first := 0
+	for first+1 < len(m.samples) && sampleTime(m.samples[first+1]).Before(cutoff) {
+		first++
+	}
+	m.samples = m.samples[first:]
+}
+
+// sampleTime returns when sample was taken
+func sampleTime(sample RPSSample) time.Time {
+	at, _ := time.Parse(time.RFC3339, sample.At)
+	return at
+}
+
+// RecordRequest counts a logical request to endpoint, sent at least once with attempts attempts, of which
+// throttled were answered with 429; failed is true when its last attempt failed too
+func (m *HealthMonitor) RecordRequest(at time.Time, endpoint string, attempts, throttled int64, failed bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	counts := m.bucket(at, endpoint)
+	counts.Requests++
+	counts.Attempts += attempts
+	counts.Throttled += throttled
+	if failed {
+		counts.Failed++
+	}
+}
+
+// RecordRetry counts a retry of a request to endpoint after waiting delay
+func (m *HealthMonitor) RecordRetry(at time.Time, endpoint string, delay time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	counts := m.bucket(at, endpoint)
+	counts.Retries++
+	counts.TotalRetryDelayMs += delay.Milliseconds()
+	counts.MaxRetryDelayMs = max(counts.MaxRetryDelayMs, delay.Milliseconds())
+}
+
+// RecordRPS adds the rate of limiter to the history when it changed since the last sample
+func (m *HealthMonitor) RecordRPS(at time.Time, limiter *AdaptiveRateLimiter, rps int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	reason := RPSReasonRecovered
+	switch {
+	case !m.sampled[limiter]:
+		// A new client starts from its initial rate; clients replaced since then are forgotten
+		m.sampled = map[*AdaptiveRateLimiter]bool{limiter: true}
+		reason = RPSReasonStart
+	case rps == m.lastRPS:
+		return
+	case rps < m.lastRPS:
+		reason = RPSReasonThrottled
+	}
+	m.lastRPS = rps
+	m.samples = append(m.samples, RPSSample{At: at.UTC().Format(time.RFC3339), RPS: rps, Reason: reason})
+	if len(m.samples) > maxRPSSamples {
+		m.samples = m.samples[len(m.samples)-maxRPSSamples:]
+	}
+}
+
+// Report summarizes the hours before now, the current one included, limited to HealthWindow
+func (m *HealthMonitor) Report(now time.Time, hours int) HealthReport {
+	windowHours := int(HealthWindow / time.Hour)
+	if hours <= 0 || hours > windowHours {
+		hours = windowHours
+	}
+	since := now.UTC().Truncate(time.Hour).Add(-time.Duration(hours-1) * time.Hour)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.prune(now)
+
+	report := HealthReport{
+		Since:      since.Format(time.RFC3339),
+		CurrentRPS: m.lastRPS,
+		Totals:     EndpointHealth{Endpoint: "*"},
+		Endpoints:  []EndpointHealth{},
+		Hourly:     []EndpointHealth{},
+		RPSHistory: []RPSSample{},
+	}
+	byEndpoint := make(map[string]*EndpointHealth)
+	for key, counts := range m.buckets {
+		if key.hour.Before(since) {
+			continue
+		}
+		hourly := *counts
+		hourly.Hour = key.hour.Format(time.RFC3339)
+		report.Hourly = append(report.Hourly, hourly.withAverage())
+
+		total, ok := byEndpoint[key.endpoint]
+		if !ok {
+			total = &EndpointHealth{Endpoint: key.endpoint}
+			byEndpoint[key.endpoint] = total
+		}
+		total.add(*counts)
+		report.Totals.add(*counts)
+	}
+	for _, total := range byEndpoint {
+		report.Endpoints = append(report.Endpoints, total.withAverage())
+	}
+	report.Totals = report.Totals.withAverage()
+
+	sort.Slice(report.Hourly, func(i, j int) bool {
+		if report.Hourly[i].Hour != report.Hourly[j].Hour {
+			return report.Hourly[i].Hour < report.Hourly[j].Hour
+		}
+		return report.Hourly[i].Endpoint < report.Hourly[j].Endpoint
+	})
+	sort.Slice(report.Endpoints, func(i, j int) bool {
+		if report.Endpoints[i].Throttled != report.Endpoints[j].Throttled {
+			return report.Endpoints[i].Throttled > report.Endpoints[j].Throttled
+		}
+		return report.Endpoints[i].Endpoint < report.Endpoints[j].Endpoint
+	})
+
+	// Keep the sample in force at the start of the window, so the chart starts from the right rate
+	first := 0
+	for first+1 < len(m.samples) && !sampleTime(m.samples[first+1]).After(since) {
+		first++
+	}
+	report.RPSHistory = append(report.RPSHistory, m.samples[first:]...)
+	return report
+}
+
+// add adds the counters of other to h
+func (h *EndpointHealth) add(other EndpointHealth) {
+	h.Requests += other.Requests
+	h.Attempts += other.Attempts
+	h.Throttled += other.Throttled
+	h.Failed += other.Failed
+	h.Retries += other.Retries
+	h.TotalRetryDelayMs += other.TotalRetryDelayMs
+	h.MaxRetryDelayMs = max(h.MaxRetryDelayMs, other.MaxRetryDelayMs)
+}
+
+// withAverage returns h with AvgRetryDelayMs set from its retries
+func (h EndpointHealth) withAverage() EndpointHealth {
+	if h.Retries > 0 {
+		h.AvgRetryDelayMs = float64(h.TotalRetryDelayMs) / float64(h.Retries)
+	}
+	return h
+}
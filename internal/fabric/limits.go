@@ -0,0 +1,57 @@
+package fabric
+
+// Bounds of the configurable limits, keeping a misconfigured client from flooding the API or stalling
+const (
+	MaxWorkspaceConcurrencyLimit = 32
+	MaxItemConcurrencyLimit      = 20
+	MaxRPSLimit                  = 200
+	MaxRetriesLimit              = 10
+)
+
+// Limits bounds a client's concurrency, request rate and retries
+type Limits struct {
+	WorkspaceConcurrency int // Workspaces fetched in parallel
+	ItemConcurrency      int // Items fetched in parallel within each workspace
+	InitialRPS           int // Requests per second the rate limiter starts at
+	MinRPS               int // Floor the rate limiter backs off to when throttled
+	MaxRPS               int // Ceiling the rate limiter recovers to
+	MaxRetries           int // Retries of a failed request (0 never retries)
+}
+
+// DefaultLimits returns the limits clients use unless configured otherwise
+func DefaultLimits() Limits {
+	return Limits{
+		WorkspaceConcurrency: MaxWorkspaceConcurrency,
+		ItemConcurrency:      MaxItemConcurrency,
+		InitialRPS:           InitialRPS,
+		MinRPS:               MinRPS,
+		MaxRPS:               MaxRPS,
+		MaxRetries:           MaxRetries,
+	}
+}
+
+// Bounded returns l with zero concurrency and rates replaced by their defaults and every value brought within
+// bounds: concurrency from 1, rates from 1 to MaxRPSLimit with MinRPS <= InitialRPS <= MaxRPS, retries from 0
+func (l Limits) Bounded() Limits {
+	defaults := DefaultLimits()
+	l.WorkspaceConcurrency = bound(orDefault(l.WorkspaceConcurrency, defaults.WorkspaceConcurrency), 1, MaxWorkspaceConcurrencyLimit)
+	l.ItemConcurrency = bound(orDefault(l.ItemConcurrency, defaults.ItemConcurrency), 1, MaxItemConcurrencyLimit)
+	l.MaxRPS = bound(orDefault(l.MaxRPS, defaults.MaxRPS), 1, MaxRPSLimit)
+	l.MinRPS = bound(orDefault(l.MinRPS, defaults.MinRPS), 1, l.MaxRPS)
+	l.InitialRPS = bound(orDefault(l.InitialRPS, defaults.InitialRPS), l.MinRPS, l.MaxRPS)
+	l.MaxRetries = bound(l.MaxRetries, 0, MaxRetriesLimit)
+	return l
+}
+
+// orDefault returns value, or fallback when value is 0
+func orDefault(value, fallback int) int {
+	if value == 0 {
+		return fallback
+	}
+	return value
+}
+
+// bound returns value clamped to [lo, hi]
+func bound(value, lo, hi int) int {
+	return max(lo, min(value, hi))
+}
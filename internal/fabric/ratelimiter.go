@@ -29,13 +29,14 @@ type AdaptiveRateLimiter struct {
 	stopChan         chan struct{}
 }
 
-// NewAdaptiveRateLimiter creates a new adaptive rate limiter
-func NewAdaptiveRateLimiter() *AdaptiveRateLimiter {
+// NewAdaptiveRateLimiter creates a new adaptive rate limiter starting at initialRPS, backing off to minRPS when
+// throttled and recovering up to maxRPS
+func NewAdaptiveRateLimiter(initialRPS, minRPS, maxRPS int) *AdaptiveRateLimiter {
 	rl := &AdaptiveRateLimiter{
-		currentRPS:       InitialRPS,
-		minRPS:           MinRPS,
-		maxRPS:           MaxRPS,
-		tokens:           make(chan struct{}, InitialRPS),
+		currentRPS:       initialRPS,
+		minRPS:           minRPS,
+		maxRPS:           maxRPS,
+		tokens:           make(chan struct{}, initialRPS),
 		lastIncreaseTime: time.Now(),
 		stopChan:         make(chan struct{}),
 	}
@@ -1,7 +1,9 @@
 package fabric
 
 import (
+	"context"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"strconv"
 	"time"
@@ -97,16 +99,27 @@ func (rp *RetryPolicy) GetBackoffDuration(attempt int, resp *http.Response) time
 }
 
 // ExecuteWithRetry executes a function with retry logic
+// Stops retrying as soon as ctx is cancelled
+// onRetry: Called before waiting backoff to retry, with the status code that failed (0 for a network error)
+// log: Logger for retry attempts
 // endpoint: API endpoint path (e.g., "/workspaces/xyz/items")
 // workspaceName: Optional workspace display name (use "N/A" if not applicable)
 // itemName: Optional item display name (use "N/A" if not applicable)
-func (rp *RetryPolicy) ExecuteWithRetry(fn func() (*http.Response, error), onThrottle func(), endpoint, workspaceName, itemName string) (*http.Response, error) {
+func (rp *RetryPolicy) ExecuteWithRetry(ctx context.Context, fn func() (*http.Response, error), onRetry func(statusCode int, backoff time.Duration), log *slog.Logger, endpoint, workspaceName, itemName string) (*http.Response, error) {
 	var resp *http.Response
 	var err error
 
 	for attempt := 0; attempt <= rp.MaxRetries; attempt++ {
 		resp, err = fn()
 
+		// Cancelled requests are not retried
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			if resp != nil && resp.Body != nil {
+				resp.Body.Close()
+			}
+			return nil, ctxErr
+		}
+
 		// Success case
 		if err == nil && resp.StatusCode >= 200 && resp.StatusCode < 300 {
 			return resp, nil
@@ -118,18 +131,15 @@ func (rp *RetryPolicy) ExecuteWithRetry(fn func() (*http.Response, error), onThr
 				return resp, err
 			}
 
-			// Notify on throttle (429)
-			if resp.StatusCode == http.StatusTooManyRequests && onThrottle != nil {
-				onThrottle()
-			}
-
 			// Calculate backoff
 			backoff := rp.GetBackoffDuration(attempt, resp)
+			if onRetry != nil {
+				onRetry(resp.StatusCode, backoff)
+			}
 
 			// Log retry attempt with context
-			logger.Log("[RETRY %d/%d] %d → %v | %s | ws:%s | item:%s\n",
-				attempt+1, rp.MaxRetries, resp.StatusCode, backoff,
-				endpoint, workspaceName, itemName)
+			log.Warn("Retrying request", "attempt", attempt+1, "maxRetries", rp.MaxRetries, "status", resp.StatusCode,
+				"backoff", backoff, "endpoint", endpoint, "workspace", workspaceName, "item", itemName)
 
 			// Close the response body before retrying
 			if resp.Body != nil {
@@ -138,19 +148,38 @@ func (rp *RetryPolicy) ExecuteWithRetry(fn func() (*http.Response, error), onThr
 
 			// Wait before retrying
 			if attempt < rp.MaxRetries {
-				time.Sleep(backoff)
+				if err := sleepWithContext(ctx, backoff); err != nil {
+					return nil, err
+				}
 			}
 		} else if err != nil {
 			// Network error or other error
 			if attempt < rp.MaxRetries {
 				backoff := rp.GetBackoffDuration(attempt, nil)
-				logger.Log("[RETRY %d/%d] error → %v | %s | ws:%s | item:%s | err:%v\n",
-					attempt+1, rp.MaxRetries, backoff,
-					endpoint, workspaceName, itemName, err)
-				time.Sleep(backoff)
+				if onRetry != nil {
+					onRetry(0, backoff)
+				}
+				log.Warn("Retrying request", "attempt", attempt+1, "maxRetries", rp.MaxRetries, "backoff", backoff,
+					"endpoint", endpoint, "workspace", workspaceName, "item", itemName, logger.Err(err))
+				if err := sleepWithContext(ctx, backoff); err != nil {
+					return nil, err
+				}
 			}
 		}
 	}
 
 	return resp, fmt.Errorf("max retries exceeded: %w", err)
 }
+
+// sleepWithContext waits for the given duration, returning early with the context error if ctx is cancelled
+func sleepWithContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,184 @@
+package fabric
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// PersonalWorkspaceType is the workspace type of a user's "My workspace"
+const PersonalWorkspaceType = "Personal"
+
+// WorkspaceScope restricts which workspaces are synced
+// A workspace is in scope when it passes the ID allowlist, matches an include pattern
+// (if any are set), matches no exclude pattern and is not a skipped personal workspace.
+// An empty scope allows every workspace
+type WorkspaceScope struct {
+	// IDs is an allowlist of workspace IDs; empty means all workspaces
+	IDs []string
+	// Include holds glob patterns on workspace names (e.g. "PROD-*"); empty means all names
+	Include []string
+	// Exclude holds glob patterns on workspace names (e.g. "*-sandbox") that are always skipped
+	Exclude []string
+	// SkipPersonal leaves out personal ("My workspace") workspaces
+	SkipPersonal bool
+}
+
+// NewWorkspaceScope creates a scope from workspace IDs and name patterns, ignoring blank entries
+func NewWorkspaceScope(ids, include, exclude []string) WorkspaceScope {
+	return WorkspaceScope{
+		IDs:     compactList(ids),
+		Include: compactList(include),
+		Exclude: compactList(exclude),
+	}
+}
+
+// ValidatePatterns checks that every include/exclude pattern is a valid glob
+func ValidatePatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := compilePattern(pattern); err != nil {
+			return fmt.Errorf("invalid workspace pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// IsEmpty reports whether the scope allows every workspace
+func (s WorkspaceScope) IsEmpty() bool {
+	return len(s.IDs) == 0 && len(s.Include) == 0 && len(s.Exclude) == 0 && !s.SkipPersonal
+}
+
+// Allows reports whether a workspace is in scope
+func (s WorkspaceScope) Allows(ws Workspace) bool {
+	if s.SkipPersonal && ws.Type == PersonalWorkspaceType {
+		return false
+	}
+	if len(s.IDs) > 0 && !containsFold(s.IDs, ws.ID) {
+		return false
+	}
+	if len(s.Include) > 0 && !matchesAny(s.Include, ws.DisplayName) {
+		return false
+	}
+	return !matchesAny(s.Exclude, ws.DisplayName)
+}
+
+// Filter returns the workspaces that are in scope, preserving order
+func (s WorkspaceScope) Filter(workspaces []Workspace) []Workspace {
+	if s.IsEmpty() {
+		return workspaces
+	}
+
+	filtered := make([]Workspace, 0, len(workspaces))
+	for _, ws := range workspaces {
+		if s.Allows(ws) {
+			filtered = append(filtered, ws)
+		}
+	}
+	return filtered
+}
+
+// matchesAny reports whether name matches any of the glob patterns (case-insensitive)
+// Invalid patterns never match
+func matchesAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if re, err := compilePattern(pattern); err == nil && re.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// compiledPatterns caches the regular expressions of the patterns compiled so far
+var compiledPatterns sync.Map // pattern -> *regexp.Regexp
+
+// errBadPattern is returned for patterns with an unterminated character class or escape
+var errBadPattern = errors.New("syntax error in pattern")
+
+// compilePattern translates a glob pattern into an anchored, case-insensitive regular expression
+// Unlike path.Match, * matches any characters, / included, since workspace names are not paths;
+// ? matches one character, [...] or [!...] a character class and \ escapes the next character
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	if re, ok := compiledPatterns.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+
+	var expr strings.Builder
+	expr.WriteString("(?is)^")
+	runes := []rune(pattern)
+	for i := 0; i < len(runes); i++ {
+		switch runes[i] {
+		case '*':
+			expr.WriteString(".*")
+		case '?':
+			expr.WriteString(".")
+		case '\\':
+			if i++; i == len(runes) {
+				return nil, errBadPattern
+			}
+			expr.WriteString(regexp.QuoteMeta(string(runes[i])))
+		case '[':
+			end := i + 1
+			if end < len(runes) && (runes[end] == '!' || runes[end] == '^') {
+				end++
+			}
+			for end < len(runes) && runes[end] != ']' {
+				if runes[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(runes) {
+				return nil, errBadPattern
+			}
+			expr.WriteString("[")
+			for j := i + 1; j < end; j++ {
+				switch {
+				case j == i+1 && (runes[j] == '!' || runes[j] == '^'):
+					expr.WriteString("^")
+				case runes[j] == '-':
+					expr.WriteString("-")
+				case runes[j] == '\\':
+					j++
+					expr.WriteString(regexp.QuoteMeta(string(runes[j])))
+				default:
+					expr.WriteString(regexp.QuoteMeta(string(runes[j])))
+				}
+			}
+			expr.WriteString("]")
+			i = end
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(runes[i])))
+		}
+	}
+	expr.WriteString("$")
+
+	re, err := regexp.Compile(expr.String())
+	if err != nil {
+		return nil, errBadPattern
+	}
+	compiledPatterns.Store(pattern, re)
+	return re, nil
+}
+
+// containsFold reports whether values contains s, ignoring case
+func containsFold(values []string, s string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// compactList trims entries and drops blank ones
+func compactList(values []string) []string {
+	var result []string
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			result = append(result, v)
+		}
+	}
+	return result
+}
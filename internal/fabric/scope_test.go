@@ -0,0 +1,37 @@
+package fabric
+
+import "testing"
+
+func TestWorkspaceScopeAllows(t *testing.T) {
+	scope := NewWorkspaceScope(nil, []string{"PROD-*", "Team-?", "Ops-[!x]*"}, []string{"*-sandbox"})
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"PROD-Sales", true},
+		{"prod-sales", true},             // Case-insensitive
+		{"PROD-Sales/EU", true},          // * matches / too
+		{"PROD-Sales/EU-sandbox", false}, // Excluded
+		{"Team-A", true},
+		{"Team-AB", false},
+		{"Ops-Finance", true},
+		{"Ops-xFinance", false},
+		{"Sales", false},
+	}
+	for _, tt := range tests {
+		if got := scope.Allows(Workspace{DisplayName: tt.name}); got != tt.want {
+			t.Errorf("Allows(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestValidatePatterns(t *testing.T) {
+	if err := ValidatePatterns([]string{"PROD-*", `Ops\*`, "[a-c]?", "a.b+(c)"}); err != nil {
+		t.Errorf("ValidatePatterns of valid patterns: %v", err)
+	}
+	for _, pattern := range []string{"[abc", `PROD-\`, "[]"} {
+		if err := ValidatePatterns([]string{pattern}); err == nil {
+			t.Errorf("ValidatePatterns(%q) = nil, want an error", pattern)
+		}
+	}
+}
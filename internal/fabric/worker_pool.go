@@ -6,10 +6,13 @@ import (
 )
 
 const (
-	// Concurrency limits
+	// Concurrency defaults, see Limits
 	MaxWorkspaceConcurrency = 8  // Process 8 workspaces in parallel
 	MaxItemConcurrency      = 5  // 5 items per workspace initially
 	MaxTotalConcurrency     = 80 // Global max concurrent requests
+
+	// MaxJobsPerChunk caps how many jobs of a workspace are held before they are handed off for persistence
+	MaxJobsPerChunk = 2000
 )
 
 // WorkerPool manages concurrent execution of jobs
@@ -28,25 +31,24 @@ func NewWorkerPool(maxWorkers int) *WorkerPool {
 }
 
 // Submit submits a job to the worker pool
+// It blocks until a worker is free, so jobs start in the order they were submitted
 func (wp *WorkerPool) Submit(ctx context.Context, job func() error) {
-	wp.wg.Add(1)
+	// Acquire semaphore
+	select {
+	case wp.semaphore <- struct{}{}:
+	case <-ctx.Done():
+		return
+	}
 
+	wp.wg.Add(1)
 	go func() {
 		defer wp.wg.Done()
+		defer func() { <-wp.semaphore }()
 
-		// Acquire semaphore
-		select {
-		case wp.semaphore <- struct{}{}:
-			defer func() { <-wp.semaphore }()
-
-			// Execute job
-			if err := job(); err != nil {
-				// Errors are handled by the job function itself
-				// We don't propagate them here as we want to continue with other jobs
-			}
-
-		case <-ctx.Done():
-			return
+		// Execute job
+		if err := job(); err != nil {
+			// Errors are handled by the job function itself
+			// We don't propagate them here as we want to continue with other jobs
 		}
 	}()
 }
@@ -60,9 +62,12 @@ func (wp *WorkerPool) Wait() {
 type WorkspaceResult struct {
 	WorkspaceID   string
 	WorkspaceName string
-	Jobs          []map[string]interface{}
+	Jobs          []RecentJob
 	Items         []Item
-	Error         error
+	// ReturnedJobIDs maps each item whose job instances were fetched to every instance ID the API returned,
+	// including runs left out of Jobs by the incremental filter
+	ReturnedJobIDs map[string][]string
+	Error          error
 }
 
 // ItemResult holds the result of processing an item
@@ -70,6 +75,7 @@ type ItemResult struct {
 	WorkspaceID   string
 	WorkspaceName string
 	Item          Item
-	Jobs          []map[string]interface{}
+	Jobs          []RecentJob
+	InstanceIDs   []string // Every job instance ID returned by the API
 	Error         error
 }
@@ -0,0 +1,236 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// File names of the active log file and its rotated backups, e.g. fabric-monitor-20240131-150405.log
+const (
+	logFileName      = "fabric-monitor.log"
+	backupPrefix     = "fabric-monitor-"
+	backupExtension  = ".log"
+	backupTimeLayout = "20060102-150405"
+)
+
+// FileOptions configures the log file and when it is rotated and removed
+type FileOptions struct {
+	Dir         string
+	MaxSize     int64         // Rotate once the file would grow past this many bytes (0 never rotates by size)
+	RotateEvery time.Duration // Rotate once the file is this old (0 never rotates by age)
+	MaxAge      time.Duration // Remove backups rotated longer ago (0 keeps them regardless of age)
+	MaxBackups  int           // Keep at most this many backups, newest first (0 keeps all)
+}
+
+// RotatingFile is a log file that is renamed to a timestamped backup when it grows too large or too old
+// Backups beyond the retention limits are removed at each rotation
+type RotatingFile struct {
+	opts   FileOptions
+	mu     sync.Mutex
+	file   *os.File
+	size   int64
+	opened time.Time
+}
+
+// OpenRotatingFile opens, or creates, the log file in opts.Dir for appending
+func OpenRotatingFile(opts FileOptions) (*RotatingFile, error) {
+	if err := os.MkdirAll(opts.Dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create log directory: %w", err)
+	}
+	f := &RotatingFile{opts: opts}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	f.prune()
+	return f, nil
+}
+
+// Path returns the path of the active log file
+func (f *RotatingFile) Path() string {
+	return filepath.Join(f.opts.Dir, logFileName)
+}
+
+// Write appends p to the file, rotating it first when p would push it past the size limit or it is due by age
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return 0, os.ErrClosed
+	}
+	tooLarge := f.opts.MaxSize > 0 && f.size > 0 && f.size+int64(len(p)) > f.opts.MaxSize
+	tooOld := f.opts.RotateEvery > 0 && time.Since(f.opened) >= f.opts.RotateEvery
+	if tooLarge || tooOld {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// Close closes the active log file
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+// open opens the active log file, taking its size and start time from what is already there
+func (f *RotatingFile) open() error {
+	file, err := os.OpenFile(f.Path(), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	f.file, f.size, f.opened = file, 0, time.Now()
+	if info, err := file.Stat(); err == nil && info.Size() > 0 {
+		// An existing file is as old as its first record, so restarts don't postpone rotation by age
+		f.size = info.Size()
+		if started, ok := firstRecordTime(f.Path()); ok && started.Before(f.opened) {
+			f.opened = started
+		}
+	}
+	return nil
+}
+
+// rotate renames the active file to a backup named after the current time and starts a new one
+func (f *RotatingFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return fmt.Errorf("failed to close log file: %w", err)
+	}
+	f.file = nil
+
+	backup := filepath.Join(f.opts.Dir, backupPrefix+time.Now().Format(backupTimeLayout)+backupExtension)
+	for i := 1; fileExists(backup); i++ {
+		backup = filepath.Join(f.opts.Dir, fmt.Sprintf("%s%s-%d%s", backupPrefix, time.Now().Format(backupTimeLayout), i, backupExtension))
+	}
+	if err := os.Rename(f.Path(), backup); err != nil {
+		// Keep writing to the same file rather than losing records
+		if openErr := f.open(); openErr != nil {
+			return openErr
+		}
+		return fmt.Errorf("failed to rotate log file: %w", err)
+	}
+	if err := f.open(); err != nil {
+		return err
+	}
+	f.prune()
+	return nil
+}
+
+// prune removes backups older than MaxAge and all but the newest MaxBackups
+func (f *RotatingFile) prune() {
+	entries, err := os.ReadDir(f.opts.Dir)
+	if err != nil {
+		return
+	}
+	type backup struct {
+		path     string
+		rotated  time.Time
+		modified time.Time // Orders backups rotated within the same second
+	}
+	var backups []backup
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || name == logFileName || !strings.HasPrefix(name, backupPrefix) || !strings.HasSuffix(name, backupExtension) {
+			continue
+		}
+		stamp := strings.TrimSuffix(strings.TrimPrefix(name, backupPrefix), backupExtension)
+		if len(stamp) < len(backupTimeLayout) {
+			continue
+		}
+		rotated, err := time.ParseInLocation(backupTimeLayout, stamp[:len(backupTimeLayout)], time.Local)
+		if err != nil {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		backups = append(backups, backup{filepath.Join(f.opts.Dir, name), rotated, info.ModTime()})
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		if !backups[i].rotated.Equal(backups[j].rotated) {
+			return backups[i].rotated.After(backups[j].rotated)
+		}
+		return backups[i].modified.After(backups[j].modified)
+	})
+
+	for i, b := range backups {
+		expired := f.opts.MaxAge > 0 && time.Since(b.rotated) > f.opts.MaxAge
+		surplus := f.opts.MaxBackups > 0 && i >= f.opts.MaxBackups
+		if expired || surplus {
+			os.Remove(b.path)
+		}
+	}
+}
+
+// LogFiles returns the log file and rotated backups in dir modified within maxAge (0 returns all), newest first
+func LogFiles(dir string, maxAge time.Duration) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	type logFile struct {
+		path     string
+		modified time.Time
+	}
+	var files []logFile
+	for _, entry := range entries {
+		name := entry.Name()
+		isLog := name == logFileName || (strings.HasPrefix(name, backupPrefix) && strings.HasSuffix(name, backupExtension))
+		if entry.IsDir() || !isLog {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || (maxAge > 0 && time.Since(info.ModTime()) > maxAge) {
+			continue
+		}
+		files = append(files, logFile{filepath.Join(dir, name), info.ModTime()})
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].modified.After(files[j].modified)
+	})
+	paths := make([]string, len(files))
+	for i, f := range files {
+		paths[i] = f.path
+	}
+	return paths, nil
+}
+
+// firstRecordTime returns the time of the first record in the log file at path, written as time=<RFC3339>
+func firstRecordTime(path string) (time.Time, bool) {
+	file, err := os.Open(path)
+	if err != nil {
+		return time.Time{}, false
+	}
+	defer file.Close()
+
+	head := make([]byte, 64)
+	n, _ := file.Read(head)
+	line, _, _ := strings.Cut(string(head[:n]), " ")
+	value, ok := strings.CutPrefix(line, "time=")
+	if !ok {
+		return time.Time{}, false
+	}
+	started, err := time.Parse(time.RFC3339Nano, value)
+	return started, err == nil
+}
+
+// fileExists reports whether path exists
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
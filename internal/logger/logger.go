@@ -1,17 +1,59 @@
 package logger
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
+// Levels as stored in log entries and shown in the logs view
+const (
+	LevelDebug   = "DEBUG"
+	LevelInfo    = "INFO"
+	LevelWarning = "WARNING"
+	LevelError   = "ERROR"
+)
+
+// Field keys shared by log records, so entries about the same workspace, job or sync can be found together
+const (
+	KeyWorkspaceID = "workspaceID"
+	KeyItemID      = "itemID"
+	KeyJobID       = "jobID"
+	KeySyncRunID   = "syncRunID"
+	KeyError       = "error"
+)
+
 // LogEntry represents a single log entry
 type LogEntry struct {
-	Timestamp string `json:"timestamp"`
-	Level     string `json:"level"`
-	Message   string `json:"message"`
+	Timestamp string            `json:"timestamp"`
+	Level     string            `json:"level"`
+	Message   string            `json:"message"`
+	Fields    map[string]string `json:"fields,omitempty"`
+}
+
+// Text returns the message followed by the fields as key=value pairs, sorted by key
+func (e LogEntry) Text() string {
+	if len(e.Fields) == 0 {
+		return e.Message
+	}
+	keys := make([]string, 0, len(e.Fields))
+	for key := range e.Fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	b.WriteString(e.Message)
+	for _, key := range keys {
+		fmt.Fprintf(&b, " %s=%s", key, quoteIfNeeded(e.Fields[key]))
+	}
+	return b.String()
 }
 
 // LogBuffer stores recent log entries in a circular buffer
@@ -32,16 +74,10 @@ func NewLogBuffer(maxSize int) *LogBuffer {
 }
 
 // Add adds a log entry to the buffer
-func (lb *LogBuffer) Add(level, message string) {
+func (lb *LogBuffer) Add(entry LogEntry) {
 	lb.mutex.Lock()
 	defer lb.mutex.Unlock()
 
-	entry := LogEntry{
-		Timestamp: time.Now().Format(time.RFC3339Nano),
-		Level:     level,
-		Message:   message,
-	}
-
 	if len(lb.entries) < lb.maxSize {
 		lb.entries = append(lb.entries, entry)
 	} else {
@@ -79,43 +115,177 @@ func (lb *LogBuffer) Clear() {
 	lb.index = 0
 }
 
-// Global log buffer
-var globalBuffer *LogBuffer
+// Global log buffer and file, and the logger writing to them and the console
+var (
+	globalBuffer *LogBuffer
+	globalFile   *RotatingFile
+	errorHook    func(LogEntry)
+	sinksMu      sync.Mutex
+	level        slog.LevelVar // Info until SetLevel is called
+	current      atomic.Pointer[slog.Logger]
+)
+
+func init() {
+	current.Store(slog.New(redactHandler{next: textHandler(os.Stdout)}))
+}
 
-// Init initializes the global log buffer
+// Init initializes the global log buffer; records are written to it and to the console from then on
 func Init(maxSize int) {
+	sinksMu.Lock()
+	defer sinksMu.Unlock()
 	globalBuffer = NewLogBuffer(maxSize)
+	storeLogger()
 }
 
-// Log adds a log message to the buffer and prints to console
-func Log(format string, args ...interface{}) {
-	message := fmt.Sprintf(format, args...)
+// OpenFile also writes records to a rotating log file in opts.Dir, returning the file's path
+// A file opened earlier is closed first
+func OpenFile(opts FileOptions) (string, error) {
+	file, err := OpenRotatingFile(opts)
+	if err != nil {
+		return "", err
+	}
+	sinksMu.Lock()
+	defer sinksMu.Unlock()
+	previous := globalFile
+	globalFile = file
+	storeLogger()
+	if previous != nil {
+		previous.Close()
+	}
+	return file.Path(), nil
+}
 
-	// Print to console as before
-	fmt.Print(message)
+// SetErrorHook passes every error record to hook as well, after redaction, e.g. to report unexpected errors
+// hook is called on the goroutine logging the record, so it must not block; nil removes it
+func SetErrorHook(hook func(LogEntry)) {
+	sinksMu.Lock()
+	defer sinksMu.Unlock()
+	errorHook = hook
+	storeLogger()
+}
 
-	// Detect log level from content
-	level := detectLogLevel(message)
+// CloseFile stops writing records to the log file and closes it
+func CloseFile() error {
+	sinksMu.Lock()
+	defer sinksMu.Unlock()
+	if globalFile == nil {
+		return nil
+	}
+	file := globalFile
+	globalFile = nil
+	storeLogger()
+	return file.Close()
+}
 
-	// Add to buffer
+// storeLogger replaces the global logger with one writing to the console, buffer, file and error hook that are set
+// up, with secrets redacted
+// Loggers derived earlier with With keep writing to the sinks they were created with
+func storeLogger() {
+	handlers := fanoutHandler{textHandler(os.Stdout)}
 	if globalBuffer != nil {
-		globalBuffer.Add(level, strings.TrimSpace(message))
+		handlers = append(handlers, &entryHandler{add: globalBuffer.Add, level: &level})
 	}
+	if globalFile != nil {
+		handlers = append(handlers, textHandler(globalFile))
+	}
+	if errorHook != nil {
+		handlers = append(handlers, &entryHandler{add: errorHook, level: slog.LevelError})
+	}
+	current.Store(slog.New(redactHandler{next: handlers}))
 }
 
-// detectLogLevel determines the log level based on message content
-func detectLogLevel(message string) string {
-	lower := strings.ToLower(message)
-	if strings.Contains(lower, "error") || strings.Contains(lower, "failed") {
-		return "ERROR"
+// textHandler writes records as text lines to w
+func textHandler(w io.Writer) slog.Handler {
+	return slog.NewTextHandler(w, &slog.HandlerOptions{Level: &level})
+}
+
+// SetLevel sets the lowest level logged, one of debug, info, warn or error
+func SetLevel(name string) error {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(strings.TrimSpace(name))); err != nil {
+		return fmt.Errorf("unknown log level %q: use debug, info, warn or error", name)
 	}
-	if strings.Contains(lower, "warning") || strings.Contains(lower, "warn") || strings.HasPrefix(message, "[RETRY") {
-		return "WARNING"
+	level.Set(l)
+	return nil
+}
+
+// GetLevel returns the lowest level logged as SetLevel accepts it: debug, info, warn or error
+func GetLevel() string {
+	return strings.ToLower(level.Level().String())
+}
+
+// Logger returns the logger the package functions write to
+func Logger() *slog.Logger {
+	return current.Load()
+}
+
+// With returns a logger adding args, key-value pairs or slog.Attr, to every record, for work spanning many records
+func With(args ...any) *slog.Logger {
+	return Logger().With(args...)
+}
+
+// contextKey is the context key of the logger set with NewContext
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying l, so code it is passed to logs with l's fields, e.g. a sync run ID
+func NewContext(ctx context.Context, l *slog.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// FromContext returns the logger carried by ctx, or the one the package functions write to
+func FromContext(ctx context.Context) *slog.Logger {
+	if l, ok := ctx.Value(contextKey{}).(*slog.Logger); ok && l != nil {
+		return l
 	}
-	if strings.Contains(lower, "debug:") {
-		return "DEBUG"
+	return Logger()
+}
+
+// Debug logs msg with the fields in args, key-value pairs or slog.Attr, at debug level
+func Debug(msg string, args ...any) {
+	Logger().Debug(msg, args...)
+}
+
+// Info logs msg with the fields in args at info level
+func Info(msg string, args ...any) {
+	Logger().Info(msg, args...)
+}
+
+// Warn logs msg with the fields in args at warning level
+func Warn(msg string, args ...any) {
+	Logger().Warn(msg, args...)
+}
+
+// Error logs msg with the fields in args at error level
+func Error(msg string, args ...any) {
+	Logger().Error(msg, args...)
+}
+
+// WorkspaceID is the field of the workspace a record is about
+func WorkspaceID(id string) slog.Attr {
+	return slog.String(KeyWorkspaceID, id)
+}
+
+// ItemID is the field of the item a record is about
+func ItemID(id string) slog.Attr {
+	return slog.String(KeyItemID, id)
+}
+
+// JobID is the field of the job instance a record is about
+func JobID(id string) slog.Attr {
+	return slog.String(KeyJobID, id)
+}
+
+// SyncRunID is the field of the sync run a record was logged by
+func SyncRunID(id string) slog.Attr {
+	return slog.String(KeySyncRunID, id)
+}
+
+// Err is the field of the error a record reports
+func Err(err error) slog.Attr {
+	if err == nil {
+		return slog.Attr{}
 	}
-	return "INFO"
+	return slog.String(KeyError, err.Error())
 }
 
 // GetAll returns all log entries from the global buffer
@@ -132,3 +302,140 @@ func Clear() {
 		globalBuffer.Clear()
 	}
 }
+
+// fanoutHandler passes records to every handler enabled for their level
+type fanoutHandler []slog.Handler
+
+func (h fanoutHandler) Enabled(ctx context.Context, l slog.Level) bool {
+	for _, handler := range h {
+		if handler.Enabled(ctx, l) {
+			return true
+		}
+	}
+	return false
+}
+
+func (h fanoutHandler) Handle(ctx context.Context, record slog.Record) error {
+	var firstErr error
+	for _, handler := range h {
+		if !handler.Enabled(ctx, record.Level) {
+			continue
+		}
+		if err := handler.Handle(ctx, record.Clone()); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (h fanoutHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handlers := make(fanoutHandler, len(h))
+	for i, handler := range h {
+		handlers[i] = handler.WithAttrs(attrs)
+	}
+	return handlers
+}
+
+func (h fanoutHandler) WithGroup(name string) slog.Handler {
+	handlers := make(fanoutHandler, len(h))
+	for i, handler := range h {
+		handlers[i] = handler.WithGroup(name)
+	}
+	return handlers
+}
+
+// entryHandler passes records at or above level to add as log entries, e.g. to add them to a LogBuffer, flattening
+// their attributes into fields
+// Attributes of a group are keyed group.key
+type entryHandler struct {
+	add   func(LogEntry)
+	level slog.Leveler
+	attrs []slog.Attr // Added with WithAttrs, keys already prefixed
+	group string      // Prefix of keys added from now on, ending in a dot
+}
+
+func (h *entryHandler) Enabled(_ context.Context, l slog.Level) bool {
+	return l >= h.level.Level()
+}
+
+func (h *entryHandler) Handle(_ context.Context, record slog.Record) error {
+	entry := LogEntry{
+		Timestamp: record.Time.Format(time.RFC3339Nano),
+		Level:     levelName(record.Level),
+		Message:   record.Message,
+	}
+	add := func(key string, value slog.Value) {
+		if entry.Fields == nil {
+			entry.Fields = make(map[string]string)
+		}
+		entry.Fields[key] = value.String()
+	}
+	for _, attr := range h.attrs {
+		flatten("", attr, add)
+	}
+	record.Attrs(func(attr slog.Attr) bool {
+		flatten(h.group, attr, add)
+		return true
+	})
+	h.add(entry)
+	return nil
+}
+
+func (h *entryHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	next := *h
+	next.attrs = append([]slog.Attr(nil), h.attrs...)
+	for _, attr := range attrs {
+		next.attrs = append(next.attrs, slog.Attr{Key: h.group + attr.Key, Value: attr.Value})
+	}
+	return &next
+}
+
+func (h *entryHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	next := *h
+	next.group = h.group + name + "."
+	return &next
+}
+
+// flatten passes attr to add under prefix, recursing into groups; empty attributes are skipped as slog does
+func flatten(prefix string, attr slog.Attr, add func(key string, value slog.Value)) {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return
+	}
+	if attr.Value.Kind() == slog.KindGroup {
+		groupPrefix := prefix
+		if attr.Key != "" {
+			groupPrefix = prefix + attr.Key + "."
+		}
+		for _, member := range attr.Value.Group() {
+			flatten(groupPrefix, member, add)
+		}
+		return
+	}
+	add(prefix+attr.Key, attr.Value)
+}
+
+// levelName returns the name the logs view shows for l
+func levelName(l slog.Level) string {
+	switch {
+	case l >= slog.LevelError:
+		return LevelError
+	case l >= slog.LevelWarn:
+		return LevelWarning
+	case l >= slog.LevelInfo:
+		return LevelInfo
+	default:
+		return LevelDebug
+	}
+}
+
+// quoteIfNeeded quotes values containing spaces or quotes, as the console handler does
+func quoteIfNeeded(value string) string {
+	if value == "" || strings.ContainsAny(value, " \t\n\"=") {
+		return fmt.Sprintf("%q", value)
+	}
+	return value
+}
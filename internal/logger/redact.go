@@ -0,0 +1,84 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"regexp"
+)
+
+// Redacted replaces secrets and personal data in log records
+const Redacted = "<redacted>"
+
+// redactions mask what failure reasons and API error bodies may carry, applied in order
+var redactions = []struct {
+	pattern     *regexp.Regexp
+	replacement string
+}{
+	// Authorization headers and bearer tokens
+	{regexp.MustCompile(`(?i)\b(bearer|basic)\s+[A-Za-z0-9\-._~+/]+=*`), "$1 " + Redacted},
+	// JSON web tokens, e.g. access tokens quoted in an error body
+	{regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`), Redacted},
+	// Credentials in connection strings, key=value pairs and JSON, e.g. AccountKey=...; or "client_secret": "..."
+	{regexp.MustCompile(`(?i)(["']?\b(?:password|pwd|accountkey|sharedaccesskey|sharedaccesssignature|client_?secret|secret|access_?token|refresh_?token|api_?key)["']?\s*[=:]\s*)("[^"]*"|'[^']*'|[^;&\s,"'}]+)`), "${1}" + Redacted},
+	// SAS signatures and token-like query parameters in URLs
+	{regexp.MustCompile(`(?i)([?&](?:sig|code|token|key|api-key)=)[^&\s"']+`), "${1}" + Redacted},
+	// Email addresses, e.g. the user who owns a workspace or triggered a run
+	{regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`), Redacted},
+}
+
+// Redact masks tokens, credentials and email addresses in s
+func Redact(s string) string {
+	for _, r := range redactions {
+		s = r.pattern.ReplaceAllString(s, r.replacement)
+	}
+	return s
+}
+
+// redactHandler masks secrets in the message and attribute values of records before passing them on, so
+// nothing sensitive reaches the console, buffer or log file
+type redactHandler struct {
+	next slog.Handler
+}
+
+func (h redactHandler) Enabled(ctx context.Context, l slog.Level) bool {
+	return h.next.Enabled(ctx, l)
+}
+
+func (h redactHandler) Handle(ctx context.Context, record slog.Record) error {
+	redacted := slog.NewRecord(record.Time, record.Level, Redact(record.Message), record.PC)
+	record.Attrs(func(attr slog.Attr) bool {
+		redacted.AddAttrs(redactAttr(attr))
+		return true
+	})
+	return h.next.Handle(ctx, redacted)
+}
+
+func (h redactHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	masked := make([]slog.Attr, len(attrs))
+	for i, attr := range attrs {
+		masked[i] = redactAttr(attr)
+	}
+	return redactHandler{next: h.next.WithAttrs(masked)}
+}
+
+func (h redactHandler) WithGroup(name string) slog.Handler {
+	return redactHandler{next: h.next.WithGroup(name)}
+}
+
+// redactAttr masks the value of attr, formatting values that are neither numbers, booleans nor times as text
+func redactAttr(attr slog.Attr) slog.Attr {
+	value := attr.Value.Resolve()
+	switch value.Kind() {
+	case slog.KindGroup:
+		members := value.Group()
+		masked := make([]any, len(members))
+		for i, member := range members {
+			masked[i] = redactAttr(member)
+		}
+		return slog.Group(attr.Key, masked...)
+	case slog.KindString, slog.KindAny:
+		return slog.String(attr.Key, Redact(value.String()))
+	default:
+		return slog.Attr{Key: attr.Key, Value: value}
+	}
+}
@@ -0,0 +1,104 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Query selects log entries; empty fields match every entry
+type Query struct {
+	Levels        []string `json:"levels"`        // Entries at any of these levels, e.g. WARNING and ERROR
+	Text          string   `json:"text"`          // Case-insensitive substring of the message or a field
+	Since         string   `json:"since"`         // RFC3339; entries at or after it
+	Until         string   `json:"until"`         // RFC3339; entries before it
+	CorrelationID string   `json:"correlationId"` // Value of any field, e.g. a sync run, job or workspace ID
+	Offset        int      `json:"offset"`        // Matches skipped, counting back from the newest
+	Limit         int      `json:"limit"`         // Most matches returned (0 returns all)
+}
+
+// Page is one page of the entries matching a Query, oldest first
+type Page struct {
+	Entries     []LogEntry     `json:"entries"`
+	Total       int            `json:"total"`       // Matching entries in the buffer, across all pages
+	LevelCounts map[string]int `json:"levelCounts"` // Entries per level matching the query's other filters
+}
+
+// Search returns the entries in the global buffer matching q, newest page first
+func Search(q Query) (Page, error) {
+	return SearchEntries(GetAll(), q)
+}
+
+// SearchEntries returns the entries matching q, which are ordered oldest first
+// Offset and Limit page back from the newest match, so the first page holds the most recent entries
+func SearchEntries(entries []LogEntry, q Query) (Page, error) {
+	since, err := parseQueryTime("since", q.Since)
+	if err != nil {
+		return Page{}, err
+	}
+	until, err := parseQueryTime("until", q.Until)
+	if err != nil {
+		return Page{}, err
+	}
+	levels := make(map[string]bool, len(q.Levels))
+	for _, level := range q.Levels {
+		levels[strings.ToUpper(level)] = true
+	}
+	text := strings.ToLower(q.Text)
+
+	var matches []LogEntry
+	counts := map[string]int{LevelDebug: 0, LevelInfo: 0, LevelWarning: 0, LevelError: 0}
+	for _, entry := range entries {
+		if !since.IsZero() || !until.IsZero() {
+			at, err := time.Parse(time.RFC3339Nano, entry.Timestamp)
+			if err != nil || (!since.IsZero() && at.Before(since)) || (!until.IsZero() && !at.Before(until)) {
+				continue
+			}
+		}
+		if q.CorrelationID != "" && !hasFieldValue(entry, q.CorrelationID) {
+			continue
+		}
+		if text != "" && !strings.Contains(strings.ToLower(entry.Text()), text) {
+			continue
+		}
+		counts[entry.Level]++
+		if len(levels) > 0 && !levels[entry.Level] {
+			continue
+		}
+		matches = append(matches, entry)
+	}
+
+	page := Page{Total: len(matches), Entries: []LogEntry{}, LevelCounts: counts}
+	end := len(matches) - max(q.Offset, 0)
+	if end <= 0 {
+		return page, nil
+	}
+	start := 0
+	if q.Limit > 0 && end > q.Limit {
+		start = end - q.Limit
+	}
+	page.Entries = append(page.Entries, matches[start:end]...)
+	return page, nil
+}
+
+// hasFieldValue reports whether any field of entry has value
+func hasFieldValue(entry LogEntry, value string) bool {
+	for _, v := range entry.Fields {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// parseQueryTime parses the RFC3339 time of a query field, returning the zero time for an empty value
+func parseQueryTime(field, value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s time %q: use RFC3339", field, value)
+	}
+	return t, nil
+}
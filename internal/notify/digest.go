@@ -0,0 +1,112 @@
+package notify
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"text/template"
+	"time"
+
+	"better-fabric-monitor/internal/db"
+)
+
+// EventDigest is a periodic summary of job runs, for readers who prefer it to real-time alerts
+const EventDigest = "digest.report"
+
+// Formats a digest can be rendered in
+const (
+	DigestFormatMarkdown = "markdown"
+	DigestFormatHTML     = "html"
+)
+
+// digestFuncs are shared by the Markdown and HTML digest templates
+var digestFuncs = map[string]interface{}{
+	"date": func(t time.Time) string { return t.Local().Format("Mon 2 Jan 2006 15:04") },
+	"ms":   func(ms int64) string { return formatDuration(time.Duration(ms) * time.Millisecond) },
+	"avg":  func(ms float64) string { return formatDuration(time.Duration(ms) * time.Millisecond) },
+	"pct":  func(v float64) string { return fmt.Sprintf("%.1f%%", v) },
+}
+
+var markdownDigest = template.Must(template.New("digest").Funcs(digestFuncs).Parse(
+	`# {{.Title}}
+
+{{date .Digest.From}} to {{date .Digest.To}}
+
+- Runs: {{.Digest.Stats.TotalJobs}}
+- Success rate: {{pct .Digest.Stats.SuccessRate}} ({{.Digest.Stats.Successful}} succeeded, {{.Digest.Stats.Failed}} failed)
+
+## New failures
+{{range .Digest.NewFailures}}
+- **{{.ItemDisplayName}}** in {{.WorkspaceName}}: failed {{.Failures}} times{{if .LastReason}}, last with "{{.LastReason}}"{{end}}
+{{- else}}
+No item started failing.
+{{- end}}
+
+## Slowest items
+{{range .Digest.SlowestItems}}
+- **{{.ItemDisplayName}}** in {{.WorkspaceName}}: {{avg .AvgDurationMs}} on average over {{.Runs}} runs, longest {{ms .MaxDurationMs}}
+{{- else}}
+No completed runs.
+{{- end}}
+{{if .Digest.SLAThresholdMs}}
+## SLA breaches (over {{ms .Digest.SLAThresholdMs}})
+{{range .Digest.SLABreaches}}
+- **{{.ItemDisplayName}}** in {{.WorkspaceName}}: {{ms .DurationMs}}, {{.Status}}, started {{date .StartTime}}
+{{- else}}
+No run took longer.
+{{- end}}
+{{end}}`))
+
+var htmlDigest = htmltemplate.Must(htmltemplate.New("digest").Funcs(digestFuncs).Parse(
+	`<h1>{{.Title}}</h1>
+<p>{{date .Digest.From}} to {{date .Digest.To}}</p>
+<ul>
+<li>Runs: {{.Digest.Stats.TotalJobs}}</li>
+<li>Success rate: {{pct .Digest.Stats.SuccessRate}} ({{.Digest.Stats.Successful}} succeeded, {{.Digest.Stats.Failed}} failed)</li>
+</ul>
+<h2>New failures</h2>
+{{if .Digest.NewFailures}}<ul>
+{{range .Digest.NewFailures}}<li><b>{{.ItemDisplayName}}</b> in {{.WorkspaceName}}: failed {{.Failures}} times{{if .LastReason}}, last with &quot;{{.LastReason}}&quot;{{end}}</li>
+{{end}}</ul>{{else}}<p>No item started failing.</p>{{end}}
+<h2>Slowest items</h2>
+{{if .Digest.SlowestItems}}<ul>
+{{range .Digest.SlowestItems}}<li><b>{{.ItemDisplayName}}</b> in {{.WorkspaceName}}: {{avg .AvgDurationMs}} on average over {{.Runs}} runs, longest {{ms .MaxDurationMs}}</li>
+{{end}}</ul>{{else}}<p>No completed runs.</p>{{end}}
+{{if .Digest.SLAThresholdMs}}<h2>SLA breaches (over {{ms .Digest.SLAThresholdMs}})</h2>
+{{if .Digest.SLABreaches}}<ul>
+{{range .Digest.SLABreaches}}<li><b>{{.ItemDisplayName}}</b> in {{.WorkspaceName}}: {{ms .DurationMs}}, {{.Status}}, started {{date .StartTime}}</li>
+{{end}}</ul>{{else}}<p>No run took longer.</p>{{end}}
+{{end}}`))
+
+// DigestEvent renders digest as a Markdown or HTML report titled after period, e.g. "Daily"
+func DigestEvent(period string, digest *db.Digest, format string) (Event, error) {
+	title := fmt.Sprintf("%s Fabric job digest", period)
+	data := struct {
+		Title  string
+		Digest *db.Digest
+	}{title, digest}
+
+	var body bytes.Buffer
+	var err error
+	switch format {
+	case DigestFormatMarkdown, "":
+		format = DigestFormatMarkdown
+		err = markdownDigest.Execute(&body, data)
+	case DigestFormatHTML:
+		err = htmlDigest.Execute(&body, data)
+	default:
+		return Event{}, fmt.Errorf("unsupported digest format %q: use markdown or html", format)
+	}
+	if err != nil {
+		return Event{}, err
+	}
+
+	return Event{
+		Type:       EventDigest,
+		Title:      title,
+		Message:    body.String(),
+		Format:     format,
+		OccurredAt: time.Now().UTC(),
+		Digest:     digest,
+	}, nil
+}
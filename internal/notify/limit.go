@@ -0,0 +1,139 @@
+package notify
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// EventSuppressed summarizes events a channel held back during quiet hours or over its rate limit
+const EventSuppressed = "notifications.suppressed"
+
+// QuietHours is a daily window of local time in which a channel sends nothing
+// The window may cross midnight, e.g. 22:00-07:00
+type QuietHours struct {
+	start, end int // Minutes after midnight; start is inclusive, end exclusive
+}
+
+// ParseQuietHours parses a window written as HH:MM-HH:MM
+func ParseQuietHours(value string) (*QuietHours, error) {
+	from, to, ok := strings.Cut(strings.TrimSpace(value), "-")
+	if !ok {
+		return nil, fmt.Errorf("quiet hours %q must look like 22:00-07:00", value)
+	}
+	start, err := parseClock(from)
+	if err != nil {
+		return nil, err
+	}
+	end, err := parseClock(to)
+	if err != nil {
+		return nil, err
+	}
+	if start == end {
+		return nil, fmt.Errorf("quiet hours %q start and end at the same time", value)
+	}
+	return &QuietHours{start: start, end: end}, nil
+}
+
+// parseClock parses HH:MM into minutes after midnight
+func parseClock(value string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(value))
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", value)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// Contains reports whether t falls inside the window, in t's location
+func (q *QuietHours) Contains(t time.Time) bool {
+	if q == nil {
+		return false
+	}
+	minute := t.Hour()*60 + t.Minute()
+	if q.start < q.end {
+		return minute >= q.start && minute < q.end
+	}
+	return minute >= q.start || minute < q.end
+}
+
+// String formats the window as HH:MM-HH:MM
+func (q *QuietHours) String() string {
+	return fmt.Sprintf("%02d:%02d-%02d:%02d", q.start/60, q.start%60, q.end/60, q.end%60)
+}
+
+// limiter applies a route's quiet hours and hourly rate limit, counting what it holds back
+type limiter struct {
+	quietHours *QuietHours
+	maxPerHour int         // 0 disables the rate limit
+	sent       []time.Time // Deliveries within the last hour, oldest first
+	held       map[string]int
+}
+
+// allow reports whether an event may be sent at now; a refused event is counted toward the next summary
+func (l *limiter) allow(eventType string, now time.Time) bool {
+	if l.open(now) {
+		return true
+	}
+	if l.held == nil {
+		l.held = make(map[string]int)
+	}
+	l.held[eventType]++
+	return false
+}
+
+// open reports whether a delivery may be made at now
+func (l *limiter) open(now time.Time) bool {
+	if l.quietHours.Contains(now.Local()) {
+		return false
+	}
+	if l.maxPerHour <= 0 {
+		return true
+	}
+	cutoff := now.Add(-time.Hour)
+	drop := 0
+	for drop < len(l.sent) && !l.sent[drop].After(cutoff) {
+		drop++
+	}
+	l.sent = l.sent[drop:]
+	return len(l.sent) < l.maxPerHour
+}
+
+// record counts a delivery made at now toward the rate limit
+func (l *limiter) record(now time.Time) {
+	if l.maxPerHour > 0 {
+		l.sent = append(l.sent, now)
+	}
+}
+
+// takeSummary returns an event summarizing the held-back events and resets the count
+// ok is false when nothing was held back
+func (l *limiter) takeSummary(now time.Time) (event Event, ok bool) {
+	total := 0
+	for _, n := range l.held {
+		total += n
+	}
+	if total == 0 {
+		return Event{}, false
+	}
+
+	types := make([]string, 0, len(l.held))
+	for eventType := range l.held {
+		types = append(types, eventType)
+	}
+	sort.Strings(types)
+	parts := make([]string, 0, len(types))
+	for _, eventType := range types {
+		parts = append(parts, fmt.Sprintf("%d %s", l.held[eventType], eventType))
+	}
+
+	event = Event{
+		Type:       EventSuppressed,
+		Title:      fmt.Sprintf("...and %d more notifications", total),
+		Message:    fmt.Sprintf("Held back by quiet hours or the rate limit: %s", strings.Join(parts, ", ")),
+		OccurredAt: now.UTC(),
+		Suppressed: l.held,
+	}
+	l.held = nil
+	return event, true
+}
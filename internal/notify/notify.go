@@ -0,0 +1,428 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"better-fabric-monitor/internal/api"
+	"better-fabric-monitor/internal/db"
+	"better-fabric-monitor/internal/logger"
+)
+
+// Event types delivered to notification channels
+const (
+	EventJobFailed      = "job.failed"
+	EventJobLongRunning = "job.long_running"
+	EventJobStuck       = "job.stuck"
+	EventSyncFailed     = "sync.failed"
+)
+
+// EventTypes lists every event type a channel can subscribe to
+var EventTypes = []string{EventJobFailed, EventJobLongRunning, EventJobStuck, EventSyncFailed, EventDigest}
+
+// Event is something that happened during monitoring that a channel may tell someone about
+type Event struct {
+	Type       string    `json:"type"`
+	Title      string    `json:"title"`
+	Message    string    `json:"message"`
+	OccurredAt time.Time `json:"occurredAt"`
+	Job        *api.Job  `json:"job,omitempty"` // The run the event is about, if any
+	// FailureStreak is how many finished runs of the job's item failed in a row, including this one
+	FailureStreak int `json:"failureStreak,omitempty"`
+	// ElapsedMs and BaselineMs are set on job.long_running and job.stuck events; BaselineMs is the item's average duration, if known
+	ElapsedMs  int64 `json:"elapsedMs,omitempty"`
+	BaselineMs int64 `json:"baselineMs,omitempty"`
+	// Format and Digest are set on digest.report events; Message holds the report rendered as markdown or html
+	Format string     `json:"format,omitempty"`
+	Digest *db.Digest `json:"digest,omitempty"`
+	// Suppressed counts held-back events by type, set on notifications.suppressed summaries
+	Suppressed map[string]int `json:"suppressed,omitempty"`
+}
+
+// JobFailedEvent describes a failed job run that is the latest of streak consecutive failures of its item
+func JobFailedEvent(job api.Job, streak int) Event {
+	message := job.FailureReason
+	if message == "" {
+		message = "The run failed without a reason"
+	}
+	title := fmt.Sprintf("%s failed in %s", job.ItemDisplayName, job.WorkspaceName)
+	if streak > 1 {
+		title = fmt.Sprintf("%s failed %d times in a row in %s", job.ItemDisplayName, streak, job.WorkspaceName)
+	}
+	return Event{
+		Type:          EventJobFailed,
+		Title:         title,
+		Message:       message,
+		OccurredAt:    time.Now().UTC(),
+		Job:           &job,
+		FailureStreak: streak,
+	}
+}
+
+// JobLongRunningEvent describes a job that is still running after elapsed, with its item's usual duration if known
+func JobLongRunningEvent(job api.Job, elapsed, baseline time.Duration) Event {
+	message := fmt.Sprintf("Still running after %s", formatDuration(elapsed))
+	if baseline > 0 {
+		message += fmt.Sprintf(", %.1fx its average of %s", float64(elapsed)/float64(baseline), formatDuration(baseline))
+	}
+	return Event{
+		Type:       EventJobLongRunning,
+		Title:      fmt.Sprintf("%s is running long in %s", job.ItemDisplayName, job.WorkspaceName),
+		Message:    message,
+		OccurredAt: time.Now().UTC(),
+		Job:        &job,
+		ElapsedMs:  elapsed.Milliseconds(),
+		BaselineMs: baseline.Milliseconds(),
+	}
+}
+
+// JobStuckEvent describes a job still queued or running after elapsed, well past its item's usual duration baseline
+func JobStuckEvent(job api.Job, elapsed, baseline time.Duration) Event {
+	state := "running"
+	if job.Status == "NotStarted" {
+		state = "queued"
+	}
+	return Event{
+		Type:       EventJobStuck,
+		Title:      fmt.Sprintf("%s looks stuck in %s", job.ItemDisplayName, job.WorkspaceName),
+		Message:    fmt.Sprintf("Still %s after %s, %.1fx its average of %s", state, formatDuration(elapsed), float64(elapsed)/float64(baseline), formatDuration(baseline)),
+		OccurredAt: time.Now().UTC(),
+		Job:        &job,
+		ElapsedMs:  elapsed.Milliseconds(),
+		BaselineMs: baseline.Milliseconds(),
+	}
+}
+
+// formatDuration rounds d to the minute, or the second below a minute, for messages
+func formatDuration(d time.Duration) string {
+	if d < time.Minute {
+		return d.Round(time.Second).String()
+	}
+	return strings.TrimSuffix(d.Round(time.Minute).String(), "0s")
+}
+
+// SyncFailedEvent describes a sync that stopped with an error
+func SyncFailedEvent(err error) Event {
+	return Event{
+		Type:       EventSyncFailed,
+		Title:      "Sync failed",
+		Message:    err.Error(),
+		OccurredAt: time.Now().UTC(),
+	}
+}
+
+// Channel delivers events to one destination
+type Channel interface {
+	// Name identifies the channel in logs
+	Name() string
+	Send(ctx context.Context, event Event) error
+}
+
+// Outcomes of routing an event to a channel
+const (
+	DeliverySent       = "sent"
+	DeliveryFailed     = "failed"
+	DeliverySuppressed = "suppressed" // Held back by quiet hours or the rate limit, and counted in the next summary
+	DeliveryMuted      = "muted"      // The event's item or workspace is muted; not sent or summarized
+)
+
+// Delivery records what happened when an event was routed to a channel
+type Delivery struct {
+	Rule    string // Name of the route, e.g. the config key of the channel
+	Channel string
+	Event   Event
+	Status  string
+	Error   string // Set when Status is DeliveryFailed
+	At      time.Time
+}
+
+// RouteOptions controls which events a channel receives and when
+type RouteOptions struct {
+	Rule       string      // Names the route in delivery records
+	Events     []string    // Event types to send (empty sends all)
+	QuietHours *QuietHours // Local time window in which nothing is sent
+	MaxPerHour int         // Deliveries allowed per rolling hour (0 is unlimited)
+	// MinFailureStreak limits the channel to job failures of items that failed at least this many times in a row,
+	// escalating repeated failures to it (0 sends every event)
+	MinFailureStreak int
+}
+
+// route is a channel with its subscriptions and delivery limits
+type route struct {
+	rule      string
+	channel   Channel
+	events    map[string]bool // nil subscribes to every event type
+	minStreak int
+	limiter   limiter
+}
+
+// Notifier fans events out to the channels subscribed to them
+// Events a channel holds back during quiet hours or over its rate limit are summarized
+// in one notifications.suppressed event once it may send again
+// The zero value has no channels and drops every event
+type Notifier struct {
+	mu       sync.Mutex // Serializes deliveries so a summary always precedes the event that released it
+	routes   []*route
+	recorder func(Delivery)
+	muted    func(Event) bool
+	routed   func(Event, string) bool
+}
+
+// New creates a notifier without channels
+func New() *Notifier {
+	return &Notifier{}
+}
+
+// Add subscribes channel to events as configured by opts
+func (n *Notifier) Add(channel Channel, opts RouteOptions) {
+	r := &route{
+		rule:      opts.Rule,
+		channel:   channel,
+		minStreak: opts.MinFailureStreak,
+		limiter:   limiter{quietHours: opts.QuietHours, maxPerHour: opts.MaxPerHour},
+	}
+	if len(opts.Events) > 0 {
+		r.events = make(map[string]bool, len(opts.Events))
+		for _, eventType := range opts.Events {
+			r.events[eventType] = true
+		}
+	}
+	n.routes = append(n.routes, r)
+}
+
+// SetRecorder registers a function called with the outcome of every delivery, including suppressed ones
+// It runs while deliveries are serialized, so it should not block for long
+func (n *Notifier) SetRecorder(recorder func(Delivery)) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.recorder = recorder
+}
+
+// SetMuteCheck registers a function deciding whether an event is about a muted item or workspace
+func (n *Notifier) SetMuteCheck(muted func(Event) bool) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.muted = muted
+}
+
+// SetRouteCheck registers a function deciding whether an event may go to the channel of a rule,
+// e.g. because its workspace routes notifications to other channels
+func (n *Notifier) SetRouteCheck(routed func(event Event, rule string) bool) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.routed = routed
+}
+
+// Empty reports whether no channel is configured
+func (n *Notifier) Empty() bool {
+	return n == nil || len(n.routes) == 0
+}
+
+// Notify sends event to every subscribed channel that is not in quiet hours or over its rate limit
+// Events about muted items are only recorded
+// A failing channel does not stop delivery to the others; all failures are returned joined
+func (n *Notifier) Notify(ctx context.Context, event Event) error {
+	if n == nil {
+		return nil
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	now := time.Now()
+	muted := n.muted != nil && n.muted(event)
+	var errs []error
+	for _, r := range n.routes {
+		if !r.wants(event) || !n.routedTo(event, r) {
+			continue
+		}
+		if muted {
+			n.record(r, event, DeliveryMuted, nil, now)
+			continue
+		}
+		if err := n.flush(ctx, r, now); err != nil {
+			errs = append(errs, err)
+		}
+		if !r.limiter.allow(event.Type, now) {
+			n.record(r, event, DeliverySuppressed, nil, now)
+			continue
+		}
+		if err := n.send(ctx, r, event, now); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Flush sends the summary of held-back events for every channel that may send again
+// Call it periodically so a summary isn't delayed until the next event
+func (n *Notifier) Flush(ctx context.Context) error {
+	if n == nil {
+		return nil
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	now := time.Now()
+	var errs []error
+	for _, r := range n.routes {
+		if err := n.flush(ctx, r, now); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// EventTest is sent by Test to check a channel's settings
+const EventTest = "notifications.test"
+
+// Rules returns the rule names of the configured channels in the order they were added
+func (n *Notifier) Rules() []string {
+	if n == nil {
+		return nil
+	}
+	rules := make([]string, 0, len(n.routes))
+	for _, r := range n.routes {
+		rules = append(rules, r.rule)
+	}
+	return rules
+}
+
+// route returns the route named rule, or nil
+func (n *Notifier) route(rule string) *route {
+	if n == nil {
+		return nil
+	}
+	for _, r := range n.routes {
+		if r.rule == rule {
+			return r
+		}
+	}
+	return nil
+}
+
+// Test sends a test event through the channel of rule right away, ignoring its subscriptions,
+// quiet hours and rate limit; the delivery is recorded like any other
+func (n *Notifier) Test(ctx context.Context, rule string) error {
+	r := n.route(rule)
+	if r == nil {
+		return fmt.Errorf("no notification channel named %q", rule)
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	event := Event{
+		Type:       EventTest,
+		Title:      "Test notification",
+		Message:    fmt.Sprintf("Better Fabric Monitor can reach %s (%s)", r.channel.Name(), rule),
+		OccurredAt: time.Now().UTC(),
+	}
+	return n.send(ctx, r, event, time.Now())
+}
+
+// Decision explains what Notify would do with an event on one channel
+type Decision struct {
+	Rule    string `json:"rule"`
+	Channel string `json:"channel"`
+	Deliver bool   `json:"deliver"`
+	Reason  string `json:"reason"`
+}
+
+// Simulate reports whether the channel of rule would receive event at now and why, without sending anything
+func (n *Notifier) Simulate(rule string, event Event, now time.Time) (Decision, error) {
+	r := n.route(rule)
+	if r == nil {
+		return Decision{}, fmt.Errorf("no notification channel named %q", rule)
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	decision := Decision{Rule: r.rule, Channel: r.channel.Name()}
+	switch {
+	case r.events != nil && !r.events[event.Type]:
+		decision.Reason = fmt.Sprintf("The channel is not subscribed to %s", event.Type)
+	case event.FailureStreak < r.minStreak:
+		decision.Reason = fmt.Sprintf("The item failed %d times in a row; the channel needs %d", event.FailureStreak, r.minStreak)
+	case !n.routedTo(event, r):
+		decision.Reason = "The workspace's notifications are routed to other channels"
+	case n.muted != nil && n.muted(event):
+		decision.Reason = "The item or its workspace is muted"
+	case r.limiter.quietHours.Contains(now.Local()):
+		decision.Reason = fmt.Sprintf("Quiet hours (%s); it would be summarized afterwards", r.limiter.quietHours)
+	case !r.limiter.open(now):
+		decision.Reason = fmt.Sprintf("The limit of %d notifications per hour is reached; it would be summarized afterwards", r.limiter.maxPerHour)
+	default:
+		decision.Deliver = true
+		decision.Reason = "It would be sent"
+	}
+	return decision, nil
+}
+
+// routedTo reports whether the route check lets event go to r
+func (n *Notifier) routedTo(event Event, r *route) bool {
+	return n.routed == nil || n.routed(event, r.rule)
+}
+
+// wants reports whether the route subscribed to event
+func (r *route) wants(event Event) bool {
+	if r.events != nil && !r.events[event.Type] {
+		return false
+	}
+	return event.FailureStreak >= r.minStreak
+}
+
+// flush sends the route's summary if it held events back and may send now
+func (n *Notifier) flush(ctx context.Context, r *route, now time.Time) error {
+	if !r.limiter.open(now) {
+		return nil
+	}
+	summary, ok := r.limiter.takeSummary(now)
+	if !ok {
+		return nil
+	}
+	return n.send(ctx, r, summary, now)
+}
+
+// send delivers event through the route's channel and counts it toward the rate limit
+func (n *Notifier) send(ctx context.Context, r *route, event Event, now time.Time) error {
+	r.limiter.record(now)
+	err := r.channel.Send(ctx, event)
+	if err != nil {
+		logger.Error("Notification failed", "eventType", event.Type, "channel", r.channel.Name(), logger.Err(err))
+		n.record(r, event, DeliveryFailed, err, now)
+		return fmt.Errorf("%s: %w", r.channel.Name(), err)
+	}
+	n.record(r, event, DeliverySent, nil, now)
+	return nil
+}
+
+// record passes the outcome of a delivery to the recorder, if any
+func (n *Notifier) record(r *route, event Event, status string, err error, now time.Time) {
+	if n.recorder == nil {
+		return
+	}
+	delivery := Delivery{
+		Rule:    r.rule,
+		Channel: r.channel.Name(),
+		Event:   event,
+		Status:  status,
+		At:      now.UTC(),
+	}
+	if err != nil {
+		delivery.Error = err.Error()
+	}
+	n.recorder(delivery)
+}
+
+// ValidEventType reports whether eventType is one channels can subscribe to
+func ValidEventType(eventType string) bool {
+	for _, t := range EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
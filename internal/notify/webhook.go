@@ -0,0 +1,91 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"better-fabric-monitor/internal/tlsconfig"
+)
+
+const (
+	// WebhookSecretHeader carries the configured secret on outbound webhook requests
+	WebhookSecretHeader = "X-Webhook-Secret"
+	// webhookTimeout bounds a single delivery so a slow endpoint can't hold up the others
+	webhookTimeout = 10 * time.Second
+	// maxWebhookErrorBody caps how much of a failed response is kept in the error
+	maxWebhookErrorBody = 512
+)
+
+// webhookPayload is the JSON body posted to a webhook
+type webhookPayload struct {
+	Source string `json:"source"`
+	Event
+}
+
+// WebhookChannel POSTs each event as JSON to a URL, for PagerDuty, Opsgenie or internal tooling
+type WebhookChannel struct {
+	url    string
+	secret string
+	client *http.Client
+}
+
+// NewWebhookChannel creates a channel posting to rawURL
+// When secret is set it is sent in the X-Webhook-Secret header so the receiver can verify the sender
+func NewWebhookChannel(rawURL, secret string) (*WebhookChannel, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid webhook URL: %w", err)
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" || parsed.Host == "" {
+		return nil, fmt.Errorf("webhook URL must be an http or https URL")
+	}
+	return &WebhookChannel{
+		url:    rawURL,
+		secret: secret,
+		client: tlsconfig.Client(webhookTimeout),
+	}, nil
+}
+
+// Name identifies the channel by host only, since webhook paths often embed keys
+func (c *WebhookChannel) Name() string {
+	if parsed, err := url.Parse(c.url); err == nil {
+		return "webhook " + parsed.Host
+	}
+	return "webhook"
+}
+
+// Send posts event and fails on any non-2xx response
+func (c *WebhookChannel) Send(ctx context.Context, event Event) error {
+	body, err := json.Marshal(webhookPayload{Source: "better-fabric-monitor", Event: event})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.secret != "" {
+		req.Header.Set(WebhookSecretHeader, c.secret)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, maxWebhookErrorBody))
+		return fmt.Errorf("webhook returned %s: %s", resp.Status, bytes.TrimSpace(detail))
+	}
+	io.Copy(io.Discard, resp.Body)
+	return nil
+}
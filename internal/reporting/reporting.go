@@ -0,0 +1,323 @@
+package reporting
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"better-fabric-monitor/internal/logger"
+	"better-fabric-monitor/internal/tlsconfig"
+)
+
+// Kinds of error reports
+const (
+	KindPanic = "panic" // The app crashed, or a background task panicked
+	KindError = "error" // An error was logged
+)
+
+const (
+	// sendTimeout bounds how long posting a report to the endpoint may take
+	sendTimeout = 10 * time.Second
+	// queueSize is how many error reports wait for delivery before further ones are dropped
+	queueSize = 16
+	// maxErrorLength is how much of an error message a report keeps
+	maxErrorLength = 500
+)
+
+// Options configures where error reports go
+type Options struct {
+	Endpoint   string // URL reports are POSTed to as JSON; empty writes them to Dir
+	Dir        string // Directory of local report files, also used when posting fails
+	AppVersion string
+	MaxPerHour int // Error reports per rolling hour, repeats of the same error not counted (0 is unlimited)
+}
+
+// Report is an error report, sanitized so it holds no secrets or tenant data
+type Report struct {
+	ID         string   `json:"id"`
+	Kind       string   `json:"kind"`
+	Time       string   `json:"time"` // RFC3339
+	AppVersion string   `json:"appVersion"`
+	OS         string   `json:"os"`
+	Arch       string   `json:"arch"`
+	GoVersion  string   `json:"goVersion"`
+	Where      string   `json:"where,omitempty"` // What panicked, e.g. background
+	Message    string   `json:"message"`
+	Error      string   `json:"error,omitempty"`
+	Stack      string   `json:"stack,omitempty"`
+	Fields     []string `json:"fields,omitempty"` // Names of the log record's fields; their values are left out
+}
+
+// tenantData match the parts of messages that may identify a tenant: IDs, and names, which messages quote
+var tenantData = []struct {
+	pattern     *regexp.Regexp
+	replacement string
+}{
+	{regexp.MustCompile(`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`), "<id>"},
+	{regexp.MustCompile(`"[^"]*"|'[^']*'`), `"<name>"`},
+	{regexp.MustCompile(`https?://[^\s"']+`), "<url>"},
+}
+
+// Sanitize redacts secrets and email addresses in s as log records are, and masks IDs, quoted names and URLs
+func Sanitize(s string) string {
+	s = logger.Redact(s)
+	for _, t := range tenantData {
+		s = t.pattern.ReplaceAllString(s, t.replacement)
+	}
+	return s
+}
+
+// reporter delivers the reports of one configuration
+type reporter struct {
+	opts   Options
+	client *http.Client
+	queue  chan Report
+	done   chan struct{}
+
+	mu     sync.Mutex
+	sent   []time.Time          // When error reports were queued within the last hour
+	seen   map[string]time.Time // When each error was last queued, so repeats within an hour are skipped
+	closed bool                 // The queue is closed; loggers derived before Close may still call reportEntry
+}
+
+// Global reporter, nil while reporting is off
+var (
+	currentMu sync.Mutex
+	current   *reporter
+)
+
+// Init starts reporting panics and error records as opts configures, replacing an earlier configuration
+func Init(opts Options) {
+	r := &reporter{
+		opts:   opts,
+		client: tlsconfig.Client(sendTimeout),
+		queue:  make(chan Report, queueSize),
+		done:   make(chan struct{}),
+		seen:   make(map[string]time.Time),
+	}
+	go r.deliverQueued()
+
+	currentMu.Lock()
+	previous := current
+	current = r
+	currentMu.Unlock()
+	logger.SetErrorHook(r.reportEntry)
+	if previous != nil {
+		previous.close(sendTimeout)
+	}
+}
+
+// Close stops reporting, waiting up to timeout for queued reports to be delivered
+func Close(timeout time.Duration) {
+	currentMu.Lock()
+	r := current
+	current = nil
+	currentMu.Unlock()
+	if r == nil {
+		return
+	}
+	logger.SetErrorHook(nil)
+	r.close(timeout)
+}
+
+// RecoverPanic reports a panic of the calling goroutine and panics again, so the app still crashes as it would
+// without reporting; defer it directly, e.g. defer reporting.RecoverPanic("background")
+func RecoverPanic(where string) {
+	recovered := recover()
+	if recovered == nil {
+		return
+	}
+	currentMu.Lock()
+	r := current
+	currentMu.Unlock()
+	if r != nil {
+		report := r.newReport(KindPanic, Sanitize(fmt.Sprint(recovered)))
+		report.Where = where
+		report.Stack = string(debug.Stack())
+		r.deliver(report)
+	}
+	panic(recovered)
+}
+
+// reportEntry queues a report of an error record, unless it repeats a recent one or the hourly limit is reached
+// It runs on the goroutine logging the record, so reports are dropped rather than waited for when the queue is full
+func (r *reporter) reportEntry(entry logger.LogEntry) {
+	report := r.newReport(KindError, Sanitize(entry.Message))
+	if err, ok := entry.Fields[logger.KeyError]; ok {
+		report.Error = truncate(Sanitize(err), maxErrorLength)
+	}
+	for key := range entry.Fields {
+		report.Fields = append(report.Fields, key)
+	}
+	sort.Strings(report.Fields)
+
+	if !r.allow(report.Message+"\n"+report.Error, time.Now()) {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return
+	}
+	select {
+	case r.queue <- report:
+	default:
+	}
+}
+
+// allow records that the error identified by key is reported at now, returning false when it was reported within the
+// last hour or MaxPerHour reports were queued within it
+func (r *reporter) allow(key string, now time.Time) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	hourAgo := now.Add(-time.Hour)
+	for k, at := range r.seen {
+		if at.Before(hourAgo) {
+			delete(r.seen, k)
+		}
+	}
+	recent := r.sent[:0]
+	for _, at := range r.sent {
+		if at.After(hourAgo) {
+			recent = append(recent, at)
+		}
+	}
+	r.sent = recent
+
+	if _, repeated := r.seen[key]; repeated {
+		return false
+	}
+	if r.opts.MaxPerHour > 0 && len(r.sent) >= r.opts.MaxPerHour {
+		return false
+	}
+	r.seen[key] = now
+	r.sent = append(r.sent, now)
+	return true
+}
+
+// newReport returns a report of kind with message, stamped with the app version and platform
+func (r *reporter) newReport(kind, message string) Report {
+	return Report{
+		ID:         newReportID(),
+		Kind:       kind,
+		Time:       time.Now().UTC().Format(time.RFC3339),
+		AppVersion: r.opts.AppVersion,
+		OS:         runtime.GOOS,
+		Arch:       runtime.GOARCH,
+		GoVersion:  runtime.Version(),
+		Message:    message,
+	}
+}
+
+// deliverQueued delivers queued reports until the queue is closed
+func (r *reporter) deliverQueued() {
+	defer close(r.done)
+	for report := range r.queue {
+		r.deliver(report)
+	}
+}
+
+// close stops queueing reports and waits up to timeout for the queued ones to be delivered
+func (r *reporter) close(timeout time.Duration) {
+	r.mu.Lock()
+	r.closed = true
+	close(r.queue)
+	r.mu.Unlock()
+	select {
+	case <-r.done:
+	case <-time.After(timeout):
+	}
+}
+
+// deliver posts report to the endpoint, or writes it to a file in Dir when there is no endpoint or posting fails
+// Failures are logged as warnings, so they are not reported in turn
+func (r *reporter) deliver(report Report) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false) // Keep the <id> and <name> placeholders readable
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		logger.Warn("Failed to encode error report", logger.Err(err))
+		return
+	}
+	body := buf.Bytes()
+	if r.opts.Endpoint != "" {
+		err := r.post(body)
+		if err == nil {
+			logger.Info("Error report sent", "reportID", report.ID, "kind", report.Kind)
+			return
+		}
+		logger.Warn("Failed to send error report, saving it instead", "reportID", report.ID, logger.Err(err))
+	}
+	if r.opts.Dir == "" {
+		return
+	}
+	path, err := r.write(report, body)
+	if err != nil {
+		logger.Warn("Failed to save error report", "reportID", report.ID, logger.Err(err))
+		return
+	}
+	logger.Info("Error report saved", "reportID", report.ID, "kind", report.Kind, "path", path)
+}
+
+// post sends body to the endpoint
+func (r *reporter) post(body []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.opts.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "better-fabric-monitor/"+r.opts.AppVersion)
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("endpoint returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// write saves body as a report file in Dir, returning its path
+func (r *reporter) write(report Report, body []byte) (string, error) {
+	if err := os.MkdirAll(r.opts.Dir, 0755); err != nil {
+		return "", err
+	}
+	stamp := time.Now().Format("20060102-150405")
+	path := filepath.Join(r.opts.Dir, fmt.Sprintf("report-%s-%s-%s.json", stamp, report.Kind, report.ID))
+	return path, os.WriteFile(path, body, 0644)
+}
+
+// newReportID returns a random ID that a report can be referred to by, e.g. in a support request
+func newReportID() string {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
+
+// truncate shortens s to at most n bytes, dropping a rune cut in half
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return strings.ToValidUTF8(s[:n], "") + "…"
+}
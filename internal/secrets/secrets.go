@@ -0,0 +1,75 @@
+package secrets
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/zalando/go-keyring"
+)
+
+// RefPrefix marks a config value that names a secret in the OS credential manager instead of holding it,
+// e.g. keyring:webhook.secret
+const RefPrefix = "keyring:"
+
+// service groups the app's secrets in Windows Credential Manager, the macOS Keychain or the libsecret keyring
+const service = "better-fabric-monitor"
+
+// ErrNotFound is returned for a secret that is not in the credential manager
+var ErrNotFound = errors.New("secret not found in the credential manager")
+
+// IsRef reports whether value is a reference to a secret rather than the secret itself
+func IsRef(value string) bool {
+	return strings.HasPrefix(value, RefPrefix)
+}
+
+// Ref returns the config value referencing the secret called name
+func Ref(name string) string {
+	return RefPrefix + name
+}
+
+// Resolve returns the secret value references, or value itself when it is not a reference
+func Resolve(value string) (string, error) {
+	if !IsRef(value) {
+		return value, nil
+	}
+	return Get(strings.TrimPrefix(value, RefPrefix))
+}
+
+// Get returns the secret called name
+func Get(name string) (string, error) {
+	if name == "" {
+		return "", errors.New("secret name is empty")
+	}
+	value, err := keyring.Get(service, name)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return "", fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret %s: %w", name, err)
+	}
+	return value, nil
+}
+
+// Set stores value as the secret called name, replacing an earlier one
+func Set(name, value string) error {
+	if name == "" {
+		return errors.New("secret name is empty")
+	}
+	if err := keyring.Set(service, name, value); err != nil {
+		return fmt.Errorf("failed to store secret %s: %w", name, err)
+	}
+	return nil
+}
+
+// Delete removes the secret called name
+func Delete(name string) error {
+	err := keyring.Delete(service, name)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete secret %s: %w", name, err)
+	}
+	return nil
+}
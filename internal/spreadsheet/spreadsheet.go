@@ -0,0 +1,225 @@
+package spreadsheet
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Formats a table can be written in
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+// MaxXLSXRows is how many rows a worksheet holds, the header included
+const MaxXLSXRows = 1048576
+
+// maxCellLength is the longest text an XLSX cell holds; longer text is cut
+const maxCellLength = 32767
+
+// Table is a header row and the rows under it
+// Cells are strings, float64 or int64 values written as numbers, or nil for an empty cell
+type Table struct {
+	Header []string
+	Rows   [][]any
+}
+
+// Write writes table to w in format, csv or xlsx; sheet names the worksheet of an XLSX file
+func Write(w io.Writer, format, sheet string, table Table) error {
+	switch format {
+	case FormatCSV:
+		return WriteCSV(w, table)
+	case FormatXLSX:
+		return WriteXLSX(w, sheet, table)
+	default:
+		return fmt.Errorf("unsupported format %q: use csv or xlsx", format)
+	}
+}
+
+// WriteCSV writes table to w as comma-separated values with a header line
+func WriteCSV(w io.Writer, table Table) error {
+	out := csv.NewWriter(w)
+	if err := out.Write(table.Header); err != nil {
+		return err
+	}
+	record := make([]string, len(table.Header))
+	for _, row := range table.Rows {
+		for i := range record {
+			record[i] = ""
+			if i < len(row) {
+				record[i] = cellText(row[i])
+			}
+		}
+		if err := out.Write(record); err != nil {
+			return err
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
+
+// WriteXLSX writes table to w as an Excel workbook with a single worksheet named sheet
+// The header row is bold, frozen and carries a filter, so the sheet can be sorted and filtered right away
+func WriteXLSX(w io.Writer, sheet string, table Table) error {
+	if len(table.Rows)+1 > MaxXLSXRows {
+		return fmt.Errorf("%d rows do not fit in a worksheet (at most %d)", len(table.Rows), MaxXLSXRows-1)
+	}
+	if sheet == "" {
+		sheet = "Sheet1"
+	}
+
+	archive := zip.NewWriter(w)
+	parts := []struct {
+		name    string
+		content string
+	}{
+		{"[Content_Types].xml", contentTypesXML},
+		{"_rels/.rels", rootRelsXML},
+		{"xl/workbook.xml", fmt.Sprintf(workbookXML, escape(sheet))},
+		{"xl/_rels/workbook.xml.rels", workbookRelsXML},
+		{"xl/styles.xml", stylesXML},
+	}
+	for _, part := range parts {
+		f, err := archive.Create(part.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(f, part.content); err != nil {
+			return err
+		}
+	}
+
+	f, err := archive.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return err
+	}
+	if err := writeSheet(f, table); err != nil {
+		return err
+	}
+	return archive.Close()
+}
+
+// writeSheet writes the worksheet XML of table
+func writeSheet(w io.Writer, table Table) error {
+	out := bufio.NewWriter(w)
+	out.WriteString(xml.Header)
+	out.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+	out.WriteString(`<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews>`)
+	out.WriteString(`<sheetData>`)
+
+	header := make([]any, len(table.Header))
+	for i, name := range table.Header {
+		header[i] = name
+	}
+	writeRow(out, 1, header, true)
+	for i, row := range table.Rows {
+		writeRow(out, i+2, row, false)
+	}
+
+	out.WriteString(`</sheetData>`)
+	if len(table.Header) > 0 {
+		fmt.Fprintf(out, `<autoFilter ref="A1:%s%d"/>`, columnName(len(table.Header)-1), len(table.Rows)+1)
+	}
+	out.WriteString(`</worksheet>`)
+	return out.Flush()
+}
+
+// writeRow writes one worksheet row; bold rows use the header style
+func writeRow(out *bufio.Writer, number int, cells []any, bold bool) {
+	style := ""
+	if bold {
+		style = ` s="1"`
+	}
+	fmt.Fprintf(out, `<row r="%d">`, number)
+	for i, cell := range cells {
+		ref := columnName(i) + strconv.Itoa(number)
+		switch v := cell.(type) {
+		case nil:
+		case float64:
+			fmt.Fprintf(out, `<c r="%s"%s><v>%s</v></c>`, ref, style, strconv.FormatFloat(v, 'f', -1, 64))
+		case int64:
+			fmt.Fprintf(out, `<c r="%s"%s><v>%d</v></c>`, ref, style, v)
+		default:
+			text := cellText(v)
+			if text == "" {
+				continue
+			}
+			if len(text) > maxCellLength {
+				text = strings.ToValidUTF8(text[:maxCellLength], "")
+			}
+			fmt.Fprintf(out, `<c r="%s" t="inlineStr"%s><is><t xml:space="preserve">%s</t></is></c>`, ref, style, escape(text))
+		}
+	}
+	out.WriteString(`</row>`)
+}
+
+// cellText returns the text of a cell as written to CSV
+func cellText(cell any) string {
+	switch v := cell.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// columnName returns the letters of the zero-based column i: A, B, ... Z, AA, AB, ...
+func columnName(i int) string {
+	name := ""
+	for i >= 0 {
+		name = string(rune('A'+i%26)) + name
+		i = i/26 - 1
+	}
+	return name
+}
+
+// escape returns s escaped for XML text and attributes, replacing characters XML cannot hold
+func escape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+const contentTypesXML = xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+	`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+	`<Default Extension="xml" ContentType="application/xml"/>` +
+	`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+	`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+	`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+	`</Types>`
+
+const rootRelsXML = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+	`</Relationships>`
+
+const workbookXML = xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+	`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+	`<sheets><sheet name="%s" sheetId="1" r:id="rId1"/></sheets>` +
+	`</workbook>`
+
+const workbookRelsXML = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+	`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+	`</Relationships>`
+
+// stylesXML has the default style and, at index 1, the bold style of the header row
+const stylesXML = xml.Header + `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+	`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+	`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+	`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+	`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+	`<cellXfs count="2"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+	`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/></cellXfs>` +
+	`</styleSheet>`
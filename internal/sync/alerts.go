@@ -0,0 +1,67 @@
+package sync
+
+import (
+	"time"
+
+	"better-fabric-monitor/internal/api"
+	"better-fabric-monitor/internal/db"
+	"better-fabric-monitor/internal/logger"
+)
+
+// LongRunningPolicy decides when a job still in progress counts as long-running
+type LongRunningPolicy struct {
+	Threshold time.Duration // Alert once a job has run this long (0 disables)
+	Factor    float64       // Alert once a job has run this many times its item's average duration (0 disables)
+}
+
+// LongRunningJob is a job still queued or in progress past an alert threshold
+type LongRunningJob struct {
+	Job      api.Job
+	Elapsed  time.Duration
+	Baseline time.Duration // Average duration of the item's completed runs, 0 without enough history
+}
+
+// announceLongRunning reports each in-progress job past policy that was not reported before
+func (s *Syncer) announceLongRunning(policy LongRunningPolicy, onLongRunning func(LongRunningJob)) {
+	s.announceRunningJobs(db.AlertLongRunning, "running longer than expected", func(now time.Time) ([]db.RunningJobAlert, error) {
+		return s.db.GetLongRunningJobAlerts(now, policy.Threshold, policy.Factor)
+	}, onLongRunning)
+}
+
+// announceStuck reports each queued or in-progress job that has taken factor times its item's average duration
+func (s *Syncer) announceStuck(factor float64, onStuck func(LongRunningJob)) {
+	s.announceRunningJobs(db.AlertStuck, "stuck", func(now time.Time) ([]db.RunningJobAlert, error) {
+		return s.db.GetStuckJobAlerts(now, factor)
+	}, onStuck)
+}
+
+// announceRunningJobs passes each job returned by find to announce and records its alertType alert so it is raised once
+func (s *Syncer) announceRunningJobs(alertType, description string, find func(now time.Time) ([]db.RunningJobAlert, error), announce func(LongRunningJob)) {
+	if announce == nil || s.db == nil || s.db.ReadOnly() {
+		return
+	}
+
+	now := time.Now().UTC()
+	alerts, err := find(now)
+	if err != nil {
+		s.log().Warn("Failed to check for alerted jobs", "alertType", alertType, logger.Err(err))
+		return
+	}
+
+	for _, alert := range alerts {
+		job := LongRunningJob{
+			Job:     api.JobFromDB(alert.Job),
+			Elapsed: time.Duration(alert.ElapsedMs) * time.Millisecond,
+		}
+		if alert.BaselineMs != nil {
+			job.Baseline = time.Duration(*alert.BaselineMs) * time.Millisecond
+		}
+		announce(job)
+		if err := s.db.MarkJobAlerted(alert.Job.ID, alertType, now); err != nil {
+			s.log().Warn("Failed to record alert", "alertType", alertType, logger.JobID(alert.Job.ID), logger.Err(err))
+		}
+	}
+	if len(alerts) > 0 {
+		s.log().Info("Jobs are "+description, "jobs", len(alerts), "alertType", alertType)
+	}
+}
@@ -0,0 +1,141 @@
+package sync
+
+import (
+	"context"
+	"time"
+
+	"better-fabric-monitor/internal/api"
+	"better-fabric-monitor/internal/fabric"
+	"better-fabric-monitor/internal/logger"
+)
+
+// ForecastRuns is how many of an item's latest completed runs its duration forecast is based on
+const ForecastRuns = 50
+
+// Sources of the expected completion time of a job in progress
+const (
+	ExpectedFromHistory    = "history"    // Forecast from the durations of the item's completed runs
+	ExpectedFromActivities = "activities" // Predicted from when the pipeline's finished activities usually leave it to run
+)
+
+// ExpectRunningJobs sets the expected duration and completion time of the jobs in progress from their item's
+// completed runs, then, when client is set, refines those of pipelines from the activities they finished so far
+func (s *Syncer) ExpectRunningJobs(ctx context.Context, client *fabric.Client, jobs []api.Job) {
+	s.addExpectedEnds(jobs)
+	if client != nil {
+		s.addActivityExpectedEnds(ctx, client, jobs, time.Now().UTC())
+	}
+}
+
+// addExpectedEnds sets the forecast duration and completion time of the jobs still in progress
+func (s *Syncer) addExpectedEnds(jobs []api.Job) {
+	running := make(map[string][]int) // Indexes of the running jobs of each item
+	var itemIDs []string
+	for i, job := range jobs {
+		if job.Status != "InProgress" || job.EndTime != "" {
+			continue
+		}
+		if _, ok := running[job.ItemID]; !ok {
+			itemIDs = append(itemIDs, job.ItemID)
+		}
+		running[job.ItemID] = append(running[job.ItemID], i)
+	}
+	if len(itemIDs) == 0 {
+		return
+	}
+
+	forecasts, err := s.db.GetDurationForecasts(ForecastRuns, itemIDs, nil, nil, "")
+	if err != nil {
+		s.log().Warn("Failed to forecast run durations", logger.Err(err))
+		return
+	}
+	for _, forecast := range forecasts {
+		for _, i := range running[forecast.ItemID] {
+			start, err := time.Parse(time.RFC3339, jobs[i].StartTime)
+			if err != nil {
+				continue
+			}
+			expected := time.Duration(forecast.ExpectedMs) * time.Millisecond
+			expectedMs := expected.Milliseconds()
+			jobs[i].ExpectedDurationMs = &expectedMs
+			jobs[i].ExpectedEndTime = start.Add(expected).Format(time.RFC3339)
+			jobs[i].ExpectedEndSource = ExpectedFromHistory
+		}
+	}
+}
+
+// addActivityExpectedEnds predicts the completion of the pipeline runs in progress from their latest finished
+// activity: its end plus the median time the pipeline's completed runs went on after it
+// Activity runs are fetched from the API, as they are only stored once a run has finished
+func (s *Syncer) addActivityExpectedEnds(ctx context.Context, client *fabric.Client, jobs []api.Job, now time.Time) {
+	var running []int
+	var itemIDs []string
+	for i, job := range jobs {
+		if job.ItemType == "DataPipeline" && job.Status == "InProgress" && job.EndTime == "" {
+			running = append(running, i)
+			itemIDs = append(itemIDs, job.ItemID)
+		}
+	}
+	if len(running) == 0 {
+		return
+	}
+
+	remainders, err := s.db.GetActivityRemainders(ForecastRuns, itemIDs)
+	if err != nil {
+		s.log().Warn("Failed to read activity durations", logger.Err(err))
+		return
+	}
+	remaining := make(map[string]map[string]float64) // Median time left after each activity, per pipeline
+	for _, r := range remainders {
+		if remaining[r.ItemID] == nil {
+			remaining[r.ItemID] = make(map[string]float64)
+		}
+		remaining[r.ItemID][r.ActivityName] = r.RemainingMs
+	}
+
+	pool := fabric.NewWorkerPool(10)
+	for _, i := range running {
+		left, ok := remaining[jobs[i].ItemID]
+		if !ok {
+			continue
+		}
+		job := &jobs[i]
+		pool.Submit(ctx, func() error {
+			start, err := time.Parse(time.RFC3339, job.StartTime)
+			if err != nil {
+				return nil
+			}
+			activityRuns, err := FetchActivityRuns(ctx, client, job.WorkspaceID, job.ID, start, now)
+			if err != nil {
+				s.log().Warn("Failed to fetch activity runs of running job", logger.WorkspaceID(job.WorkspaceID), logger.JobID(job.ID), logger.Err(err))
+				return nil
+			}
+
+			var latestEnd, expectedEnd time.Time
+			for _, activity := range activityRuns {
+				leftMs, ok := left[activity.ActivityName]
+				if activity.Status != "Succeeded" || !ok {
+					continue
+				}
+				end, err := time.Parse(time.RFC3339Nano, activity.ActivityRunEnd)
+				if err != nil || end.Before(latestEnd) {
+					continue
+				}
+				latestEnd, expectedEnd = end, end.Add(time.Duration(leftMs)*time.Millisecond)
+			}
+			if expectedEnd.IsZero() {
+				return nil
+			}
+			// A run already past its prediction is expected to end any moment, not in the past
+			if expectedEnd.Before(now) {
+				expectedEnd = now
+			}
+			expectedMs := expectedEnd.Sub(start).Milliseconds()
+			job.ExpectedDurationMs = &expectedMs
+			job.ExpectedEndTime = expectedEnd.Format(time.RFC3339)
+			job.ExpectedEndSource = ExpectedFromActivities
+			return nil
+		})
+	}
+	pool.Wait()
+}
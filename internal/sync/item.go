@@ -0,0 +1,159 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"better-fabric-monitor/internal/db"
+	"better-fabric-monitor/internal/fabric"
+	"better-fabric-monitor/internal/logger"
+	"better-fabric-monitor/internal/tracing"
+)
+
+// SyncItem re-pulls job instances for one item, bypassing the full sync
+// New and changed runs are persisted, then Livy sessions (notebooks) or activity runs (pipelines) are refreshed
+// Returns the number of job instances that were new or changed
+func (s *Syncer) SyncItem(ctx context.Context, client *fabric.Client, workspaceID, itemID string) (_ int, err error) {
+	ctx, runID, end := s.beginRun(ctx)
+	defer end()
+	ctx, span := tracing.Start(ctx, "sync.item", tracing.SyncRunID(runID), tracing.WorkspaceID(workspaceID), tracing.ItemID(itemID))
+	defer func() { tracing.End(span, err) }()
+
+	if s.db == nil {
+		return 0, fmt.Errorf("database not initialized")
+	}
+
+	workspace, item, err := s.lookupItem(ctx, client, workspaceID, itemID)
+	if err != nil {
+		return 0, err
+	}
+
+	reconcileBefore := time.Now().UTC()
+	instances, err := client.GetItemJobInstances(ctx, workspace.ID, item.ID, workspace.DisplayName, item.DisplayName)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get job instances: %w", err)
+	}
+
+	// Only rewrite runs that are new or changed - rewriting a finished pipeline run would drop its activity runs
+	cached, err := s.db.GetJobInstances(db.JobFilter{ItemID: &item.ID})
+	if err != nil {
+		return 0, fmt.Errorf("failed to read cached jobs: %w", err)
+	}
+	cachedByID := make(map[string]db.JobInstance, len(cached))
+	for _, job := range cached {
+		cachedByID[job.ID] = job
+	}
+
+	var changed []db.JobInstance
+	for _, instance := range instances {
+		job := ToJobInstance(fabric.NewRecentJob(workspace, item, instance))
+		if existing, ok := cachedByID[job.ID]; ok && existing.Status == job.Status && existing.EndTime != nil {
+			continue
+		}
+		changed = append(changed, job)
+	}
+
+	span.SetAttributes(attribute.Int("fabric.job_instances", len(instances)), attribute.Int("sync.jobs_changed", len(changed)))
+	if len(changed) > 0 {
+		_, saveSpan := tracing.Start(ctx, "db.save_job_instances", attribute.Int("fabric.jobs", len(changed)))
+		err := s.db.SaveJobInstances(changed)
+		tracing.End(saveSpan, err)
+		if err != nil {
+			return 0, fmt.Errorf("failed to save jobs: %w", err)
+		}
+	}
+	s.log().Info("SyncItem: job instances fetched", "item", item.DisplayName, logger.WorkspaceID(workspaceID), logger.ItemID(itemID),
+		"jobs", len(instances), "changed", len(changed))
+
+	returnedIDs := make([]string, 0, len(instances))
+	for _, instance := range instances {
+		returnedIDs = append(returnedIDs, instance.ID)
+	}
+	if marked, err := s.db.ReconcileItemJobs(item.ID, returnedIDs, reconcileBefore); err != nil {
+		s.log().Warn("SyncItem: failed to reconcile jobs", logger.ItemID(itemID), logger.Err(err))
+	} else if marked > 0 {
+		s.log().Info("SyncItem: cached runs marked as removed upstream", "item", item.DisplayName, logger.ItemID(itemID), "runs", marked)
+	}
+
+	switch item.Type {
+	case "Notebook":
+		s.SyncNotebook(ctx, client, workspace.ID, item.ID, nil)
+	case "DataPipeline":
+		for _, job := range changed {
+			if job.EndTime == nil {
+				continue
+			}
+			if _, err := s.RefreshActivityRuns(ctx, client, job); err != nil {
+				s.log().Warn("SyncItem: failed to refresh activity runs", logger.ItemID(itemID), logger.Err(err))
+			}
+		}
+	}
+
+	return len(changed), nil
+}
+
+// RefreshActivityRuns re-queries and stores the activity runs of a finished pipeline run
+// Returns the number of activity runs stored
+func (s *Syncer) RefreshActivityRuns(ctx context.Context, client *fabric.Client, job db.JobInstance) (_ int, err error) {
+	ctx, runID, end := s.beginRun(ctx)
+	defer end()
+	ctx, span := tracing.Start(ctx, "sync.refresh_activity_runs", tracing.SyncRunID(runID), tracing.JobID(job.ID))
+	defer func() { tracing.End(span, err) }()
+
+	if job.EndTime == nil {
+		return 0, nil
+	}
+
+	activityRuns, err := FetchActivityRuns(ctx, client, job.WorkspaceID, job.ID, job.StartTime, *job.EndTime)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch activity runs for job %s: %w", job.ID, err)
+	}
+	if err := s.db.UpdateJobInstanceActivityRuns(job.ID, activityRuns); err != nil {
+		return 0, fmt.Errorf("failed to save activity runs for job %s: %w", job.ID, err)
+	}
+	return len(activityRuns), nil
+}
+
+// lookupItem resolves a workspace and item from the cache, falling back to the API for items not seen yet
+func (s *Syncer) lookupItem(ctx context.Context, client *fabric.Client, workspaceID, itemID string) (fabric.Workspace, fabric.Item, error) {
+	workspace := fabric.Workspace{ID: workspaceID, DisplayName: workspaceID}
+	if workspaces, err := s.db.GetWorkspaces(); err == nil {
+		for _, ws := range workspaces {
+			if ws.ID == workspaceID {
+				workspace.DisplayName = ws.DisplayName
+				workspace.Type = ws.Type
+				break
+			}
+		}
+	}
+
+	if items, err := s.db.GetItemsByWorkspace(workspaceID); err == nil {
+		for _, item := range items {
+			if item.ID == itemID {
+				return workspace, fabric.Item{ID: item.ID, WorkspaceID: item.WorkspaceID, DisplayName: item.DisplayName, Type: item.Type}, nil
+			}
+		}
+	}
+
+	items, err := client.GetWorkspaceItems(ctx, workspaceID, workspace.DisplayName)
+	if err != nil {
+		return workspace, fabric.Item{}, fmt.Errorf("failed to get items for workspace %s: %w", workspaceID, err)
+	}
+	for _, item := range items {
+		if item.ID != itemID {
+			continue
+		}
+		// Persist the workspace and item so the job instances satisfy foreign key constraints
+		if err := s.db.SaveWorkspace(&db.Workspace{ID: workspace.ID, DisplayName: workspace.DisplayName, Type: workspace.Type}); err != nil {
+			s.log().Warn("Failed to save workspace", logger.WorkspaceID(workspace.ID), logger.Err(err))
+		}
+		if err := s.db.SaveItem(&db.Item{ID: item.ID, WorkspaceID: workspaceID, DisplayName: item.DisplayName, Type: item.Type}); err != nil {
+			s.log().Warn("Failed to save item", logger.WorkspaceID(workspaceID), logger.ItemID(item.ID), logger.Err(err))
+		}
+		return workspace, item, nil
+	}
+	return workspace, fabric.Item{}, fmt.Errorf("item %s not found in workspace %s", itemID, workspaceID)
+}
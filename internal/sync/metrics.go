@@ -0,0 +1,78 @@
+package sync
+
+import (
+	"context"
+	"time"
+
+	"better-fabric-monitor/internal/db"
+	"better-fabric-monitor/internal/fabric"
+	"better-fabric-monitor/internal/logger"
+)
+
+// resetCounters clears the per-run counters before a sync starts
+func (s *Syncer) resetCounters() {
+	s.saveMu.Lock()
+	defer s.saveMu.Unlock()
+	s.jobsSaved = 0
+	s.jobsFetched = 0
+	s.workspaces = 0
+	s.workspacesFailed = 0
+	s.rowsWritten = 0
+	s.warnings = nil
+	s.synced = nil
+}
+
+// addRowsWritten counts database rows inserted or updated by the running sync
+func (s *Syncer) addRowsWritten(rows int) {
+	s.saveMu.Lock()
+	s.rowsWritten += rows
+	s.saveMu.Unlock()
+}
+
+// recordMetrics stores the performance of a finished sync run
+func (s *Syncer) recordMetrics(ctx context.Context, started time.Time, requests fabric.RequestStats, appVersion, runID string, result *Result, runErr error) {
+	if s.db == nil || s.db.ReadOnly() {
+		return
+	}
+
+	s.saveMu.Lock()
+	metrics := db.SyncMetrics{
+		SyncRunID:        runID,
+		StartedAt:        started.UTC(),
+		DurationMs:       time.Since(started).Milliseconds(),
+		SyncType:         "full",
+		Status:           db.SyncStatusCompleted,
+		AppVersion:       appVersion,
+		APICalls:         int(requests.Attempts),
+		Retries:          int(requests.Retries()),
+		Throttled:        int(requests.Throttled),
+		Workspaces:       s.workspaces,
+		WorkspacesFailed: s.workspacesFailed,
+		JobsFetched:      s.jobsFetched,
+		RowsWritten:      s.rowsWritten,
+	}
+	s.saveMu.Unlock()
+
+	if result != nil {
+		metrics.JobsFetched = result.JobsFetched
+		if result.Incremental {
+			metrics.SyncType = "incremental"
+		}
+	}
+	switch {
+	case runErr != nil:
+		message := runErr.Error()
+		metrics.Status = db.SyncStatusFailed
+		metrics.ErrorMessage = &message
+	case ctx.Err() != nil:
+		metrics.Status = db.SyncStatusCancelled
+	}
+
+	if err := s.db.SaveSyncMetrics(&metrics); err != nil {
+		s.log().Warn("Failed to save sync metrics", logger.Err(err))
+		return
+	}
+	s.log().Info("Sync metrics", "syncType", metrics.SyncType, "status", metrics.Status, "durationMs", metrics.DurationMs,
+		"apiCalls", metrics.APICalls, "retries", metrics.Retries, "throttled", metrics.Throttled,
+		"workspaces", metrics.Workspaces, "workspacesFailed", metrics.WorkspacesFailed, "rowsWritten", metrics.RowsWritten)
+}
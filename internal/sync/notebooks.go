@@ -0,0 +1,270 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	gosync "sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"better-fabric-monitor/internal/db"
+	"better-fabric-monitor/internal/fabric"
+	"better-fabric-monitor/internal/logger"
+	"better-fabric-monitor/internal/tracing"
+)
+
+// livyWindowMargin widens the incremental window so sessions submitted just before their job started are kept
+const livyWindowMargin = time.Hour
+
+// SyncNotebookSessions fetches Livy sessions for notebooks until ctx is cancelled
+// Livy IDs are needed to build notebook deep links
+// If since is set, only notebooks active since then are synced and paging stops at older sessions;
+// otherwise every notebook's full session history is fetched
+func (s *Syncer) SyncNotebookSessions(ctx context.Context, client *fabric.Client, since *time.Time) (err error) {
+	ctx, runID, end := s.beginRun(ctx)
+	defer end()
+	ctx, span := tracing.Start(ctx, "sync.notebook_sessions", tracing.SyncRunID(runID))
+	defer func() { tracing.End(span, err) }()
+
+	if s.db == nil {
+		return fmt.Errorf("database not initialized")
+	}
+	if client == nil {
+		return fmt.Errorf("fabric client not initialized")
+	}
+
+	s.log().Info("Starting notebook sessions sync")
+
+	var windowStart *time.Time
+	if since != nil {
+		start := since.Add(-livyWindowMargin)
+		windowStart = &start
+	}
+
+	// Get the notebooks to sync from job_instances
+	notebooks, err := s.db.GetUniqueNotebooks(windowStart)
+	if err != nil {
+		return fmt.Errorf("failed to get unique notebooks: %w", err)
+	}
+
+	if windowStart != nil {
+		s.log().Info("Found notebooks to sync", "notebooks", len(notebooks), "activeSince", windowStart.Format(time.RFC3339))
+	} else {
+		s.log().Info("Found notebooks to sync", "notebooks", len(notebooks))
+	}
+
+	// Use worker pool to parallelize notebook session fetching
+	numWorkers := 4 // Process 4 notebooks concurrently
+	notebookChan := make(chan struct {
+		WorkspaceID string
+		NotebookID  string
+	}, len(notebooks))
+	resultsChan := make(chan int, len(notebooks))
+	var wg gosync.WaitGroup
+
+	// Start workers
+	for i := 0; i < numWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for notebook := range notebookChan {
+				if ctx.Err() != nil {
+					resultsChan <- 0
+					continue
+				}
+				sessionsCount := s.SyncNotebook(ctx, client, notebook.WorkspaceID, notebook.NotebookID, windowStart)
+				resultsChan <- sessionsCount
+			}
+		}()
+	}
+
+	// Send notebooks to workers
+	for _, notebook := range notebooks {
+		notebookChan <- struct {
+			WorkspaceID string
+			NotebookID  string
+		}{
+			WorkspaceID: notebook.WorkspaceID,
+			NotebookID:  notebook.NotebookID,
+		}
+	}
+	close(notebookChan)
+
+	// Wait for all workers to complete
+	go func() {
+		wg.Wait()
+		close(resultsChan)
+	}()
+
+	// Collect results
+	totalSessions := 0
+	for count := range resultsChan {
+		totalSessions += count
+	}
+
+	s.addRowsWritten(totalSessions)
+	span.SetAttributes(attribute.Int("sync.notebooks", len(notebooks)), attribute.Int("sync.sessions", totalSessions))
+	s.log().Info("Notebook sessions sync complete", "sessions", totalSessions)
+	return nil
+}
+
+// SyncNotebook fetches and saves Livy sessions for a single notebook, returning how many were saved
+// Sessions are returned newest first, so if since is set paging stops after the first page reaching back before it
+func (s *Syncer) SyncNotebook(ctx context.Context, client *fabric.Client, workspaceID, notebookID string, since *time.Time) int {
+	ctx, span := tracing.Start(ctx, "sync.notebook", tracing.WorkspaceID(workspaceID), tracing.ItemID(notebookID))
+	defer span.End()
+	continuationToken := ""
+	totalSessions := 0
+
+	// Paginate through all Livy sessions for this notebook
+	for {
+		response, err := client.GetLivySessions(ctx, workspaceID, notebookID, continuationToken)
+		if err != nil {
+			s.log().Warn("Failed to get Livy sessions", logger.WorkspaceID(workspaceID), logger.ItemID(notebookID), logger.Err(err))
+			tracing.Fail(span, err)
+			break // Skip this notebook
+		}
+
+		if response == nil || len(response.Value) == 0 {
+			break
+		}
+
+		// Convert fabric.LivySession to db.NotebookSession
+		dbSessions := make([]db.NotebookSession, 0, len(response.Value))
+		reachedWindowStart := false
+		for _, livySession := range response.Value {
+			submitted := livySession.SubmittedDateTime.Time
+			if since != nil && !submitted.IsZero() && submitted.Before(*since) {
+				reachedWindowStart = true
+			}
+
+			dbSession := db.NotebookSession{
+				LivyID:        livySession.LivyID,
+				JobInstanceID: livySession.JobInstanceID,
+				WorkspaceID:   workspaceID,
+				NotebookID:    notebookID,
+				State:         livySession.State, // Required non-pointer field
+			}
+
+			// Handle optional string fields
+			if livySession.SparkApplicationID != "" {
+				dbSession.SparkApplicationID = &livySession.SparkApplicationID
+			}
+			if livySession.Origin != "" {
+				dbSession.Origin = &livySession.Origin
+			}
+			if livySession.AttemptNumber != 0 {
+				dbSession.AttemptNumber = &livySession.AttemptNumber
+			}
+			if livySession.LivyName != "" {
+				dbSession.LivyName = &livySession.LivyName
+			}
+			if livySession.CancellationReason != "" {
+				dbSession.CancellationReason = &livySession.CancellationReason
+			}
+			if livySession.CapacityID != "" {
+				dbSession.CapacityID = &livySession.CapacityID
+			}
+			if livySession.OperationName != "" {
+				dbSession.OperationName = &livySession.OperationName
+			}
+			if livySession.RuntimeVersion != "" {
+				dbSession.RuntimeVersion = &livySession.RuntimeVersion
+			}
+			dbSession.IsHighConcurrency = &livySession.IsHighConcurrency
+
+			// Handle FabricTime fields
+			if !livySession.SubmittedDateTime.Time.IsZero() {
+				dbSession.SubmittedDateTime = &livySession.SubmittedDateTime.Time
+			}
+			if !livySession.StartDateTime.Time.IsZero() {
+				dbSession.StartDateTime = &livySession.StartDateTime.Time
+			}
+			if !livySession.EndDateTime.Time.IsZero() {
+				dbSession.EndDateTime = &livySession.EndDateTime.Time
+			}
+
+			// Extract submitter info
+			if livySession.Submitter.ID != "" {
+				dbSession.SubmitterID = &livySession.Submitter.ID
+			}
+			if livySession.Submitter.Type != "" {
+				dbSession.SubmitterType = &livySession.Submitter.Type
+			}
+
+			// Extract item info from top-level fields (not nested Item struct)
+			if livySession.ItemName != "" {
+				dbSession.ItemName = &livySession.ItemName
+			}
+			if livySession.ItemType != "" {
+				dbSession.ItemType = &livySession.ItemType
+			}
+			if livySession.JobType != "" {
+				dbSession.JobType = &livySession.JobType
+			}
+
+			// Convert durations to milliseconds
+			if livySession.QueuedDuration.Value > 0 {
+				ms := convertToMs(livySession.QueuedDuration.Value, livySession.QueuedDuration.TimeUnit)
+				dbSession.QueuedDurationMs = &ms
+			}
+			if livySession.RunningDuration.Value > 0 {
+				ms := convertToMs(livySession.RunningDuration.Value, livySession.RunningDuration.TimeUnit)
+				dbSession.RunningDurationMs = &ms
+			}
+			if livySession.TotalDuration.Value > 0 {
+				ms := convertToMs(livySession.TotalDuration.Value, livySession.TotalDuration.TimeUnit)
+				dbSession.TotalDurationMs = &ms
+			}
+
+			// Extract consumer identity ID
+			if livySession.ConsumerIdentity.ID != "" {
+				dbSession.ConsumerIdentityID = &livySession.ConsumerIdentity.ID
+			}
+
+			dbSessions = append(dbSessions, dbSession)
+		}
+
+		// Save sessions to database
+		if len(dbSessions) > 0 {
+			_, saveSpan := tracing.Start(ctx, "db.save_livy_sessions", attribute.Int("sync.sessions", len(dbSessions)))
+			err := s.db.SaveLivySessions(dbSessions)
+			tracing.End(saveSpan, err)
+			if err != nil {
+				s.log().Warn("Failed to save Livy sessions", logger.WorkspaceID(workspaceID), logger.ItemID(notebookID), logger.Err(err))
+				break
+			}
+			totalSessions += len(dbSessions)
+		}
+
+		// Check if there are more pages worth fetching
+		if response.ContinuationToken == "" || reachedWindowStart {
+			break
+		}
+		continuationToken = response.ContinuationToken
+	}
+
+	span.SetAttributes(attribute.Int("sync.sessions", totalSessions))
+	if totalSessions > 0 {
+		s.log().Debug("Synced notebook sessions", logger.WorkspaceID(workspaceID), logger.ItemID(notebookID), "sessions", totalSessions)
+	}
+
+	return totalSessions
+}
+
+// convertToMs converts duration from Fabric API to milliseconds
+func convertToMs(value int, timeUnit string) int {
+	switch timeUnit {
+	case "Seconds":
+		return value * 1000
+	case "Minutes":
+		return value * 60000
+	case "Hours":
+		return value * 3600000
+	case "Milliseconds":
+		return value
+	default:
+		return value // Assume milliseconds if unknown
+	}
+}
@@ -0,0 +1,196 @@
+package sync
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"better-fabric-monitor/internal/db"
+	"better-fabric-monitor/internal/fabric"
+	"better-fabric-monitor/internal/logger"
+	"better-fabric-monitor/internal/tracing"
+)
+
+// enrichmentChunkSize bounds how many pipeline jobs have their activity runs held in memory at once
+const enrichmentChunkSize = 200
+
+// EnrichPipelineJobs fetches activity runs for completed pipeline jobs that don't have them yet,
+// skipping workspaces whose settings turn enrichment off
+// Uses parallel processing with worker pools for scalability
+func (s *Syncer) EnrichPipelineJobs(ctx context.Context, client *fabric.Client) {
+	ctx, runID, end := s.beginRun(ctx)
+	defer end()
+	ctx, span := tracing.Start(ctx, "sync.enrich_pipelines", tracing.SyncRunID(runID))
+	defer span.End()
+
+	if s.db == nil {
+		return
+	}
+
+	// Get all completed pipeline jobs without activity runs (removed LIMIT)
+	query := `
+		SELECT j.id, j.workspace_id, j.start_time, j.end_time
+		FROM job_instances j
+		LEFT JOIN items i ON j.item_id = i.id
+		WHERE i.type = 'DataPipeline'
+			AND j.end_time IS NOT NULL
+			AND j.activity_runs IS NULL
+			AND j.workspace_id NOT IN (SELECT workspace_id FROM workspace_settings WHERE enrichment_enabled = false)
+		ORDER BY j.start_time DESC
+	`
+
+	rows, err := s.db.GetConnection().Query(query)
+	if err != nil {
+		s.log().Error("Failed to query pipeline jobs for activity runs", logger.Err(err))
+		tracing.Fail(span, err)
+		return
+	}
+	defer rows.Close()
+
+	type pipelineJob struct {
+		ID          string
+		WorkspaceID string
+		StartTime   time.Time
+		EndTime     time.Time
+	}
+
+	var jobs []pipelineJob
+	for rows.Next() {
+		var job pipelineJob
+		if err := rows.Scan(&job.ID, &job.WorkspaceID, &job.StartTime, &job.EndTime); err != nil {
+			s.log().Error("Failed to scan pipeline job", logger.Err(err))
+			continue
+		}
+		jobs = append(jobs, job)
+	}
+
+	if len(jobs) == 0 {
+		return
+	}
+
+	s.log().Info("Fetching activity runs of pipeline jobs", "jobs", len(jobs))
+	startTime := time.Now()
+
+	// Activity runs carry full input/output payloads, so jobs are fetched and saved in chunks
+	// and each chunk's results are released before the next one starts
+	successCount := 0
+	errorCount := 0
+	totalActivities := 0
+
+	for chunkStart := 0; chunkStart < len(jobs) && ctx.Err() == nil; chunkStart += enrichmentChunkSize {
+		chunkEnd := chunkStart + enrichmentChunkSize
+		if chunkEnd > len(jobs) {
+			chunkEnd = len(jobs)
+		}
+		chunk := jobs[chunkStart:chunkEnd]
+
+		// Create worker pool for parallel processing (limit to 20 concurrent requests)
+		pool := fabric.NewWorkerPool(20)
+
+		// Channel to collect results
+		type jobResult struct {
+			jobID         string
+			activityRuns  []db.ActivityRun
+			err           error
+			activityCount int
+		}
+		results := make(chan jobResult, len(chunk))
+
+		// Process each job in parallel
+		for _, job := range chunk {
+			job := job // Capture for goroutine
+
+			pool.Submit(ctx, func() error {
+				result := jobResult{jobID: job.ID}
+
+				activityRuns, err := FetchActivityRuns(ctx, client, job.WorkspaceID, job.ID, job.StartTime, job.EndTime)
+				if err != nil {
+					result.err = err
+					results <- result
+					return nil
+				}
+
+				result.activityCount = len(activityRuns)
+				result.activityRuns = activityRuns
+				results <- result
+				return nil
+			})
+		}
+
+		// Wait for the chunk to complete
+		pool.Wait()
+		close(results)
+
+		// Process results and save to database
+		_, saveSpan := tracing.Start(ctx, "db.save_activity_runs", attribute.Int("sync.jobs", len(chunk)))
+		for result := range results {
+			if result.err != nil {
+				s.log().Warn("Failed to fetch activity runs", logger.JobID(result.jobID), logger.Err(result.err))
+				errorCount++
+				// Do NOT mark as processed - leave activity_runs as NULL so it can be retried
+				// This allows the job to be re-enriched on the next sync
+				continue
+			}
+
+			// Save activity runs (even if empty array - this is a valid result)
+			if err := s.db.UpdateJobInstanceActivityRuns(result.jobID, result.activityRuns); err != nil {
+				s.log().Error("Failed to save activity runs", logger.JobID(result.jobID), logger.Err(err))
+				errorCount++
+				continue
+			}
+
+			successCount++
+			totalActivities += result.activityCount
+		}
+
+		saveSpan.End()
+		s.log().Debug("Activity runs saved", "done", chunkEnd, "jobs", len(jobs))
+	}
+
+	s.addRowsWritten(successCount)
+	span.SetAttributes(attribute.Int("sync.jobs", len(jobs)), attribute.Int("sync.jobs_enriched", successCount),
+		attribute.Int("sync.activity_runs", totalActivities), attribute.Int("sync.errors", errorCount))
+	elapsed := time.Since(startTime)
+	s.log().Info("Activity runs sync complete", "jobs", len(jobs), "succeeded", successCount,
+		"activities", totalActivities, "errors", errorCount, "duration", elapsed)
+}
+
+// FetchActivityRuns queries the activity runs of a completed pipeline job
+func FetchActivityRuns(ctx context.Context, client *fabric.Client, workspaceID, jobID string, jobStart, jobEnd time.Time) ([]db.ActivityRun, error) {
+	// Add some buffer time before and after the job run
+	startTime := jobStart.Add(-1 * time.Minute)
+	endTime := jobEnd.Add(1 * time.Minute)
+
+	activityRuns, err := client.QueryActivityRuns(ctx, workspaceID, jobID, startTime, endTime)
+	if err != nil {
+		return nil, err
+	}
+
+	// Convert fabric.ActivityRun to db.ActivityRun
+	dbActivityRuns := make([]db.ActivityRun, len(activityRuns))
+	for i, ar := range activityRuns {
+		dbActivityRuns[i] = db.ActivityRun{
+			PipelineID:              ar.PipelineID,
+			PipelineRunID:           ar.PipelineRunID,
+			ActivityName:            ar.ActivityName,
+			ActivityType:            ar.ActivityType,
+			ActivityRunID:           ar.ActivityRunID,
+			Status:                  ar.Status,
+			ActivityRunStart:        ar.ActivityRunStart,
+			ActivityRunEnd:          ar.ActivityRunEnd,
+			DurationInMs:            ar.DurationInMs,
+			Input:                   ar.Input,
+			Output:                  ar.Output,
+			Error:                   db.ActivityError(ar.Error),
+			RetryAttempt:            ar.RetryAttempt,
+			IterationHash:           ar.IterationHash,
+			UserProperties:          ar.UserProperties,
+			RecoveryStatus:          ar.RecoveryStatus,
+			IntegrationRuntimeNames: ar.IntegrationRuntimeNames,
+			ExecutionDetails:        ar.ExecutionDetails,
+		}
+	}
+
+	return dbActivityRuns, nil
+}
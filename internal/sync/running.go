@@ -0,0 +1,105 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	gosync "sync"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"better-fabric-monitor/internal/api"
+	"better-fabric-monitor/internal/db"
+	"better-fabric-monitor/internal/fabric"
+	"better-fabric-monitor/internal/logger"
+	"better-fabric-monitor/internal/tracing"
+)
+
+// RefreshRunningJobs re-reads the status of the cached queued and in-progress jobs from the API and stores the
+// runs that changed, without listing items or enriching runs; finished pipelines get their activity runs from
+// the next sync
+// onJobFailed, when set, is called for each run found failed, since the next incremental sync will not fetch it again
+// Returns the number of runs whose status changed
+func (s *Syncer) RefreshRunningJobs(ctx context.Context, client *fabric.Client, onJobFailed func(api.Job)) (_ int, err error) {
+	ctx, runID, end := s.beginRun(ctx)
+	defer end()
+	ctx, span := tracing.Start(ctx, "sync.refresh_running_jobs", tracing.SyncRunID(runID))
+	defer func() { tracing.End(span, err) }()
+
+	if s.db == nil {
+		return 0, fmt.Errorf("database not initialized")
+	}
+
+	running, err := s.db.GetRunningJobs(nil, nil, "")
+	if err != nil {
+		return 0, fmt.Errorf("failed to read running jobs: %w", err)
+	}
+	span.SetAttributes(attribute.Int("sync.jobs_running", len(running)))
+	if len(running) == 0 {
+		return 0, nil
+	}
+
+	var mu gosync.Mutex
+	var changed []fabric.RecentJob
+	pool := fabric.NewWorkerPool(10)
+	for _, job := range running {
+		pool.Submit(ctx, func() error {
+			workspace := fabric.Workspace{ID: job.WorkspaceID, DisplayName: valueOr(job.WorkspaceName, job.WorkspaceID)}
+			item := fabric.Item{ID: job.ItemID, DisplayName: valueOr(job.ItemDisplayName, job.ItemID), Type: valueOr(job.ItemType, "")}
+
+			instance, err := client.GetItemJobInstance(ctx, workspace.ID, item.ID, job.ID, workspace.DisplayName, item.DisplayName)
+			if err != nil {
+				// A run Fabric no longer returns is left to the next sync to reconcile
+				var apiErr *fabric.APIError
+				if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+					s.log().Warn("Failed to refresh job status", logger.WorkspaceID(job.WorkspaceID), logger.JobID(job.ID), logger.Err(err))
+				}
+				return nil
+			}
+			if instance.Status == job.Status && instance.EndTimeUtc.Time.IsZero() {
+				return nil
+			}
+
+			mu.Lock()
+			changed = append(changed, fabric.NewRecentJob(workspace, item, *instance))
+			mu.Unlock()
+			return nil
+		})
+	}
+	pool.Wait()
+
+	span.SetAttributes(attribute.Int("sync.jobs_changed", len(changed)))
+	if len(changed) == 0 {
+		return 0, nil
+	}
+
+	dbJobs := make([]db.JobInstance, 0, len(changed))
+	for _, job := range changed {
+		dbJobs = append(dbJobs, ToJobInstance(job))
+	}
+	s.saveMu.Lock()
+	err = s.db.SaveJobInstances(dbJobs)
+	s.saveMu.Unlock()
+	if err != nil {
+		return 0, fmt.Errorf("failed to save jobs: %w", err)
+	}
+	s.log().Info("Refreshed running jobs", "running", len(running), "changed", len(changed))
+
+	if onJobFailed != nil {
+		for _, job := range changed {
+			if job.Status == "Failed" {
+				onJobFailed(api.JobFromFabric(job, nil))
+			}
+		}
+	}
+	return len(changed), nil
+}
+
+// valueOr returns the value of p, or fallback when p is nil or empty
+func valueOr(p *string, fallback string) string {
+	if p == nil || *p == "" {
+		return fallback
+	}
+	return *p
+}
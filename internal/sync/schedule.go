@@ -0,0 +1,203 @@
+package sync
+
+import (
+	"sort"
+	"time"
+
+	"better-fabric-monitor/internal/db"
+	"better-fabric-monitor/internal/fabric"
+	"better-fabric-monitor/internal/logger"
+)
+
+// Workspace activity levels used by adaptive polling
+const (
+	ActivityBusy    = "busy"    // Jobs running, or started within busyWindow
+	ActivityQuiet   = "quiet"   // Jobs started within quietWindow
+	ActivityDormant = "dormant" // No jobs for longer than quietWindow
+)
+
+const (
+	// busyWindow is how recently a job must have started for its workspace to count as busy
+	busyWindow = 6 * time.Hour
+	// quietWindow is how recently a job must have started for its workspace not to count as dormant
+	quietWindow = 7 * 24 * time.Hour
+	// quietInterval is how often quiet workspaces are polled, kept between the policy's bounds
+	quietInterval = 15 * time.Minute
+
+	defaultMinPollInterval = 2 * time.Minute
+	defaultMaxPollInterval = time.Hour
+)
+
+// PollPolicy bounds how often workspaces are polled: busy workspaces every MinInterval, dormant ones every MaxInterval
+type PollPolicy struct {
+	MinInterval time.Duration
+	MaxInterval time.Duration
+}
+
+// withDefaults fills in unset bounds and keeps MaxInterval at least MinInterval
+func (p PollPolicy) withDefaults() PollPolicy {
+	if p.MinInterval <= 0 {
+		p.MinInterval = defaultMinPollInterval
+	}
+	if p.MaxInterval <= 0 {
+		p.MaxInterval = defaultMaxPollInterval
+	}
+	if p.MaxInterval < p.MinInterval {
+		p.MaxInterval = p.MinInterval
+	}
+	return p
+}
+
+// forWorkspace returns the policy of a workspace whose settings may override how often it is polled while busy
+func (p PollPolicy) forWorkspace(settings db.WorkspaceSettings) PollPolicy {
+	if settings.PollIntervalSeconds == nil {
+		return p
+	}
+	p.MinInterval = time.Duration(*settings.PollIntervalSeconds) * time.Second
+	p.MaxInterval = max(p.MaxInterval, p.MinInterval)
+	return p
+}
+
+// classify picks a workspace's activity level and poll interval from its recent jobs
+func (p PollPolicy) classify(activity db.WorkspaceActivity, now time.Time) (string, time.Duration) {
+	switch {
+	case activity.RunningJobs > 0 || (activity.LastJobAt != nil && now.Sub(*activity.LastJobAt) < busyWindow):
+		return ActivityBusy, p.MinInterval
+	case activity.LastJobAt != nil && now.Sub(*activity.LastJobAt) < quietWindow:
+		return ActivityQuiet, min(max(quietInterval, p.MinInterval), p.MaxInterval)
+	default:
+		return ActivityDormant, p.MaxInterval
+	}
+}
+
+// dueWorkspaceIDs returns the workspaces whose next poll is due, plus any that were never polled
+func (s *Syncer) dueWorkspaceIDs(workspaceIDs []string, now time.Time) (map[string]bool, error) {
+	schedules, err := s.db.GetWorkspacePollSchedules()
+	if err != nil {
+		return nil, err
+	}
+
+	nextPoll := make(map[string]time.Time, len(schedules))
+	for _, schedule := range schedules {
+		nextPoll[schedule.WorkspaceID] = schedule.NextPollAt
+	}
+
+	due := make(map[string]bool)
+	for _, id := range workspaceIDs {
+		next, scheduled := nextPoll[id]
+		if !scheduled || !next.After(now) {
+			due[id] = true
+		}
+	}
+	return due, nil
+}
+
+// workspaceIDs returns the IDs of workspaces
+func workspaceIDs(workspaces []fabric.Workspace) []string {
+	ids := make([]string, 0, len(workspaces))
+	for _, ws := range workspaces {
+		ids = append(ids, ws.ID)
+	}
+	return ids
+}
+
+// prioritizeWorkspaces orders workspaces so those with in-progress jobs are synced first,
+// then by how recently they ran a job, with idle workspaces last
+func (s *Syncer) prioritizeWorkspaces(workspaces []fabric.Workspace) {
+	if s.db == nil || len(workspaces) < 2 {
+		return
+	}
+
+	activity, err := s.db.GetWorkspaceActivity(time.Now().UTC().Add(-busyWindow))
+	if err != nil {
+		s.log().Warn("Failed to read workspace activity, keeping workspace order", logger.Err(err))
+		return
+	}
+
+	sort.SliceStable(workspaces, func(i, j int) bool {
+		a, b := activity[workspaces[i].ID], activity[workspaces[j].ID]
+		if (a.RunningJobs > 0) != (b.RunningJobs > 0) {
+			return a.RunningJobs > 0
+		}
+		if a.LastJobAt == nil || b.LastJobAt == nil {
+			return a.LastJobAt != nil && b.LastJobAt == nil
+		}
+		return a.LastJobAt.After(*b.LastJobAt)
+	})
+
+	running := 0
+	for _, ws := range workspaces {
+		if activity[ws.ID].RunningJobs > 0 {
+			running++
+		}
+	}
+	if running > 0 {
+		s.log().Info("Syncing workspaces with in-progress jobs first", "workspaces", running)
+	}
+}
+
+// markWorkspaceSynced notes that a workspace's jobs were fetched, so its poll schedule is updated
+func (s *Syncer) markWorkspaceSynced(workspaceID string) {
+	s.saveMu.Lock()
+	defer s.saveMu.Unlock()
+	if s.synced == nil {
+		s.synced = make(map[string]bool)
+	}
+	s.synced[workspaceID] = true
+}
+
+// takeSyncedWorkspaces returns the workspaces marked as synced and clears them
+func (s *Syncer) takeSyncedWorkspaces() []string {
+	s.saveMu.Lock()
+	defer s.saveMu.Unlock()
+	ids := make([]string, 0, len(s.synced))
+	for id := range s.synced {
+		ids = append(ids, id)
+	}
+	s.synced = nil
+	return ids
+}
+
+// updatePollSchedule records when the synced workspaces were polled and when each should be polled next
+// A workspace's poll interval setting replaces the policy's MinInterval for it
+func (s *Syncer) updatePollSchedule(workspaceIDs []string, policy PollPolicy, polledAt time.Time) {
+	if s.db == nil || len(workspaceIDs) == 0 {
+		return
+	}
+
+	policy = policy.withDefaults()
+	now := time.Now().UTC()
+	activity, err := s.db.GetWorkspaceActivity(now.Add(-busyWindow))
+	if err != nil {
+		s.log().Warn("Failed to read workspace activity", logger.Err(err))
+		return
+	}
+	overrides, err := s.db.GetWorkspaceSettingsByID()
+	if err != nil {
+		s.log().Warn("Failed to read workspace settings, polling every workspace by the global policy", logger.Err(err))
+	}
+
+	schedules := make([]db.WorkspacePollSchedule, 0, len(workspaceIDs))
+	counts := make(map[string]int)
+	for _, id := range workspaceIDs {
+		a := activity[id]
+		level, interval := policy.forWorkspace(overrides[id]).classify(a, now)
+		counts[level]++
+		schedules = append(schedules, db.WorkspacePollSchedule{
+			WorkspaceID:     id,
+			Activity:        level,
+			RunningJobs:     a.RunningJobs,
+			RecentJobs:      a.RecentJobs,
+			LastJobAt:       a.LastJobAt,
+			IntervalSeconds: int(interval / time.Second),
+			LastPolledAt:    polledAt,
+			NextPollAt:      polledAt.Add(interval),
+		})
+	}
+
+	if err := s.db.SaveWorkspacePollSchedules(schedules); err != nil {
+		s.log().Warn("Failed to save poll schedule", logger.Err(err))
+		return
+	}
+	s.log().Info("Poll schedule updated", "busy", counts[ActivityBusy], "quiet", counts[ActivityQuiet], "dormant", counts[ActivityDormant])
+}
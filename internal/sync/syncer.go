@@ -0,0 +1,740 @@
+package sync
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	gosync "sync"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"better-fabric-monitor/internal/api"
+	"better-fabric-monitor/internal/db"
+	"better-fabric-monitor/internal/fabric"
+	"better-fabric-monitor/internal/logger"
+	"better-fabric-monitor/internal/tracing"
+)
+
+// Sync phases reported through Reporter.SetPhase
+const (
+	PhaseDiscovery  = "discovery"
+	PhaseJobs       = "jobs"
+	PhaseLivy       = "livy"
+	PhaseEnrichment = "enrichment"
+)
+
+// ErrListWorkspaces is returned by Run when the workspace list could not be fetched
+var ErrListWorkspaces = errors.New("failed to get workspaces")
+
+// Reporter receives progress updates while a sync runs
+type Reporter interface {
+	fabric.ProgressReporter
+	SetPhase(phase string)
+	SetWorkspacesTotal(total int)
+	// JobsSaved is called after a workspace's jobs were persisted, while other workspaces are still being fetched
+	JobsSaved(workspaceName string, jobs int)
+	// SetSyncRunID is called when a run starts with the ID tagging its log records
+	SetSyncRunID(id string)
+}
+
+// Options configures a single sync run
+type Options struct {
+	Scope             fabric.WorkspaceScope
+	ExcludedItemTypes []string
+	// ItemCacheTTL is how long a workspace's listed items are reused before it is listed again (0 always lists)
+	ItemCacheTTL time.Duration
+	// StaleJobAfter is how long a job may stay in progress before it is marked stale (0 never marks jobs)
+	StaleJobAfter time.Duration
+	// OnJobFailed is called for each failed job found by an incremental sync
+	OnJobFailed func(job api.Job)
+	// AppVersion is stored with the run's metrics so performance can be compared across releases
+	AppVersion string
+	// DueOnly limits the sync to workspaces whose adaptive poll is due (used by scheduled polling)
+	DueOnly bool
+	// Polling decides how soon each synced workspace is polled again, based on its activity
+	Polling PollPolicy
+	// MaxLookback limits full syncs and backfills to jobs started within this window (0 fetches all history)
+	MaxLookback time.Duration
+	// LongRunning decides when a job still in progress has run too long
+	LongRunning LongRunningPolicy
+	// OnJobLongRunning is called once per job that is still in progress past the LongRunning policy
+	OnJobLongRunning func(job LongRunningJob)
+	// StuckFactor is how many times its item's average duration a queued or running job may take before it counts as stuck
+	StuckFactor float64
+	// OnJobStuck is called once per job that is stuck by StuckFactor
+	OnJobStuck func(job LongRunningJob)
+}
+
+// Result is the outcome of a sync run
+type Result struct {
+	Jobs        []api.Job // All cached jobs after the sync (only the fetched jobs when there is no database)
+	JobsFetched int       // Jobs returned by the API during this run
+	Incremental bool
+	SyncRunID   string // Tags the run's log records and metrics
+	// CancelledDuringJobs is set when the run was cancelled before all workspaces were fetched
+	CancelledDuringJobs bool
+	// Warnings lists workspaces and items that failed while the rest of the sync went ahead
+	Warnings []api.SyncWarning
+}
+
+// Syncer pulls workspaces, items and job instances from Fabric into the local database
+// and enriches them with Livy sessions (notebooks) and activity runs (pipelines)
+type Syncer struct {
+	db       *db.Database
+	reporter Reporter
+
+	// saveMu serializes workspace writes coming from concurrent fetch workers and guards the counters below
+	saveMu           gosync.Mutex
+	jobsSaved        int
+	jobsFetched      int
+	workspaces       int
+	workspacesFailed int
+	rowsWritten      int
+	warnings         []api.SyncWarning
+	synced           map[string]bool // Workspaces whose jobs were fetched without error
+
+	// current is the running sync or enrichment run, whose log records it tags with its ID (nil between runs)
+	current atomic.Pointer[syncRun]
+}
+
+// syncRun is a sync or enrichment run and the logger tagging its records
+type syncRun struct {
+	id  string
+	log *slog.Logger
+}
+
+// New creates a Syncer; database may be nil, in which case nothing is persisted
+func New(database *db.Database, reporter Reporter) *Syncer {
+	return &Syncer{
+		db:       database,
+		reporter: reporter,
+	}
+}
+
+// Run performs a full or incremental sync, depending on whether jobs were synced before
+// Work finished before ctx is cancelled is persisted; later phases are skipped
+// Every run, including failed and cancelled ones, has its metrics recorded
+func (s *Syncer) Run(ctx context.Context, client *fabric.Client, opts Options) (*Result, error) {
+	started := time.Now()
+	requestsBefore := client.RequestStats()
+	s.resetCounters()
+
+	ctx, runID, end := s.beginRun(ctx)
+	defer end()
+	s.reporter.SetSyncRunID(runID)
+
+	ctx, span := tracing.Start(ctx, "sync.run", tracing.SyncRunID(runID), attribute.Bool("sync.due_only", opts.DueOnly))
+	result, err := s.run(ctx, client, opts)
+	if result != nil {
+		result.SyncRunID = runID
+		span.SetAttributes(attribute.Int("sync.jobs_fetched", result.JobsFetched), attribute.Bool("sync.incremental", result.Incremental),
+			attribute.Int("sync.warnings", len(result.Warnings)))
+	}
+	s.recordMetrics(ctx, started, client.RequestStats().Sub(requestsBefore), opts.AppVersion, runID, result, err)
+	tracing.End(span, err)
+	return result, err
+}
+
+// beginRun starts tagging the log records of the syncer with a new sync run ID, until end is called, and returns
+// ctx carrying the run's logger so the client calls made with it are tagged too
+// Enrichment started while a run is in progress, including by the run itself, shares its ID and leaves ending it
+// to the run
+func (s *Syncer) beginRun(ctx context.Context) (_ context.Context, id string, end func()) {
+	id = newSyncRunID()
+	run := &syncRun{id: id, log: logger.With(logger.SyncRunID(id))}
+	if !s.current.CompareAndSwap(nil, run) {
+		if current := s.current.Load(); current != nil {
+			return logger.NewContext(ctx, current.log), current.id, func() {}
+		}
+		s.current.Store(run)
+	}
+	return logger.NewContext(ctx, run.log), id, func() {
+		s.current.CompareAndSwap(run, nil)
+	}
+}
+
+// log returns the logger of the running sync, or the global one between runs
+func (s *Syncer) log() *slog.Logger {
+	if run := s.current.Load(); run != nil {
+		return run.log
+	}
+	return logger.Logger()
+}
+
+// newSyncRunID returns a random ID that groups the log records of one sync run
+func newSyncRunID() string {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
+
+// run performs the sync for Run
+func (s *Syncer) run(ctx context.Context, client *fabric.Client, opts Options) (*Result, error) {
+	// Get real workspaces first
+	workspaces, err := ScopedWorkspaces(ctx, client, opts.Scope)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrListWorkspaces, err)
+	}
+	// Persist workspaces to database first (needed for foreign key constraints)
+	_, span := tracing.Start(ctx, "db.save_workspaces", attribute.Int("fabric.workspaces", len(workspaces)))
+	s.SaveWorkspaces(workspaces)
+	span.End()
+
+	// Scheduled polls only visit workspaces that are due; the rest keep their own watermark for later
+	partial := false
+	if opts.DueOnly && s.db != nil {
+		due, err := s.dueWorkspaceIDs(workspaceIDs(workspaces), time.Now().UTC())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read poll schedule: %w", err)
+		}
+		dueWorkspaces := make([]fabric.Workspace, 0, len(due))
+		for _, ws := range workspaces {
+			if due[ws.ID] {
+				dueWorkspaces = append(dueWorkspaces, ws)
+			}
+		}
+		s.log().Info("Scheduled poll", "due", len(dueWorkspaces), "workspaces", len(workspaces))
+		workspaces = dueWorkspaces
+		partial = true
+	}
+	s.prioritizeWorkspaces(workspaces)
+
+	s.reporter.SetWorkspacesTotal(len(workspaces))
+	s.saveMu.Lock()
+	s.workspaces = len(workspaces)
+	s.saveMu.Unlock()
+
+	// Check for last sync time to enable incremental loading
+	// GetMaxJobStartTime returns either:
+	// - The MIN start_time of in-progress jobs (to re-check them for completion), OR
+	// - The MAX start_time of completed jobs (if no in-progress jobs exist)
+	var startTimeFrom *time.Time
+	var watermarks map[string]time.Time
+	var cachedItemsByWorkspace map[string][]fabric.Item
+	if s.db != nil {
+		// Retire jobs Fabric lost track of before they can drag the watermark back
+		s.markStaleJobs(opts.StaleJobAfter)
+
+		maxStartTime, err := s.db.GetMaxJobStartTime()
+		if err == nil && maxStartTime != nil {
+			startTimeFrom = maxStartTime
+			s.log().Info("Incremental load", "since", maxStartTime.Format(time.RFC3339))
+
+			// Workspaces are polled at different times, so each resumes from its own watermark
+			watermarks, err = s.db.GetWorkspaceWatermarks()
+			if err != nil {
+				s.log().Warn("Failed to read workspace watermarks", logger.Err(err))
+			}
+		} else {
+			s.log().Info("No previous jobs found, doing full load")
+		}
+
+		// Reuse item lists discovered within the TTL to avoid listing every workspace again
+		cachedItemsByWorkspace = s.cachedItems(workspaces, opts.ItemCacheTTL)
+	}
+
+	// Get recent jobs across all workspaces (no limit - return all)
+	// Pass startTimeFrom for incremental sync (will also fetch all in-progress jobs)
+	// Pass cachedItemsByWorkspace to avoid fetching items from API for recently listed workspaces
+	// With a database, jobs are persisted in bounded chunks as workspaces are fetched instead of being held in memory,
+	// so a failure late in the run keeps earlier work; runs that started before reconcileBefore and are
+	// no longer returned by the API get marked as removed upstream
+	s.reporter.SetPhase(PhaseJobs)
+	incremental := startTimeFrom != nil
+	reconcileBefore := time.Now().UTC()
+	fetchOpts := fabric.RecentJobsOptions{
+		Since:             startTimeFrom,
+		Watermarks:        watermarks,
+		ExcludedItemTypes: opts.ExcludedItemTypes,
+		CachedItems:       cachedItemsByWorkspace,
+		Progress:          warningReporter{Reporter: s.reporter, syncer: s},
+	}
+	if s.db != nil {
+		fetchOpts.OnWorkspaceResult = func(ctx context.Context, ws fabric.WorkspaceResult) {
+			s.markWorkspaceSynced(ws.WorkspaceID)
+			s.saveWorkspaceResult(ctx, ws, incremental)
+			s.reconcileWorkspace(ws, reconcileBefore)
+			s.announceFailures(ws.Jobs, incremental, opts.OnJobFailed)
+		}
+	}
+	polledAt := time.Now().UTC()
+	var lookbackCutoff *time.Time
+	if opts.MaxLookback > 0 {
+		cutoff := polledAt.Add(-opts.MaxLookback)
+		lookbackCutoff = &cutoff
+		fetchOpts.NotBefore = cutoff
+		if !incremental {
+			s.log().Info("Full load limited by lookback", "since", cutoff.Format(time.RFC3339))
+		}
+	}
+	jobs, _, err := client.GetRecentJobs(ctx, workspaces, fetchOpts)
+	s.recordJobsSynced()
+	s.updatePollSchedule(s.takeSyncedWorkspaces(), opts.Polling, polledAt)
+	s.announceFailures(jobs, incremental, opts.OnJobFailed)
+
+	// A sync cancelled while fetching jobs only returns workspaces that finished
+	// Hold the incremental watermark so the next sync re-fetches the skipped workspaces
+	result := &Result{
+		JobsFetched:         s.jobsFetched + len(jobs),
+		Incremental:         incremental,
+		CancelledDuringJobs: ctx.Err() != nil,
+		Warnings:            s.takeWarnings(),
+	}
+	// Workspaces and items that failed with a transient error are re-fetched the same way
+	missedJobs := result.CancelledDuringJobs || hasRetryableWarning(result.Warnings)
+	if missedJobs && s.db != nil {
+		watermark := time.Unix(0, 0).UTC()
+		if startTimeFrom != nil {
+			watermark = *startTimeFrom
+		}
+		if err := s.db.HoldSyncWatermark(watermark); err != nil {
+			s.log().Warn("Failed to hold sync watermark", logger.Err(err))
+		}
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get jobs: %w", err)
+	}
+
+	// Without a database there is nothing to enrich - return the fetched jobs as they are
+	if s.db == nil {
+		result.Jobs = make([]api.Job, 0, len(jobs))
+		for _, job := range jobs {
+			result.Jobs = append(result.Jobs, api.JobFromFabric(job, nil))
+		}
+		return result, nil
+	}
+
+	// Lost jobs are returned as in progress on every sync, so re-mark them after they were saved again
+	_, span = tracing.Start(ctx, "db.analyze_jobs")
+	s.markStaleJobs(opts.StaleJobAfter)
+	// Alert on jobs still running too long while they run, rather than after they finish
+	s.announceLongRunning(opts.LongRunning, opts.OnJobLongRunning)
+	s.announceStuck(opts.StuckFactor, opts.OnJobStuck)
+	s.scoreAnomalies()
+	s.measureRegressions()
+	s.applyRetention()
+	span.End()
+
+	// After all jobs are persisted, fetch activity runs for completed DataPipeline jobs
+	// This blocks until enrichment completes to ensure child executions are available when UI loads
+	// We do this AFTER the persistence block to ensure all jobs are committed to the database
+	if ctx.Err() == nil {
+		// Sync notebook sessions to get livyID for notebook deep links
+		// This runs synchronously to ensure all livyIDs are available before UI loads
+		// Incremental syncs only visit notebooks active within the sync window
+		if result.JobsFetched > 0 && isItemTypeSynced(opts.ExcludedItemTypes, "Notebook") {
+			s.reporter.SetPhase(PhaseLivy)
+			// A full load only needs the sessions of notebooks run within the lookback window
+			livySince := startTimeFrom
+			if livySince == nil {
+				livySince = lookbackCutoff
+			}
+			if err := s.SyncNotebookSessions(ctx, client, livySince); err != nil {
+				s.log().Warn("Failed to sync notebook sessions", logger.Err(err))
+			}
+		}
+
+		if result.JobsFetched > 0 && ctx.Err() == nil && isItemTypeSynced(opts.ExcludedItemTypes, "DataPipeline") {
+			s.reporter.SetPhase(PhaseEnrichment)
+			s.EnrichPipelineJobs(ctx, client)
+		}
+	}
+
+	// Every fetched job is in the database by now, with its Livy ID, stale status and removal mark
+	result.Jobs = s.CachedJobs()
+
+	// A sync that ran to completion releases any watermark held by earlier cancelled or partly failed syncs
+	// A partial poll can't release it, since workspaces it skipped may still depend on the hold
+	if ctx.Err() == nil && !missedJobs && !partial {
+		if err := s.db.ReleaseSyncWatermark(); err != nil {
+			s.log().Warn("Failed to release sync watermark", logger.Err(err))
+		}
+	}
+
+	return result, nil
+}
+
+// ScopedWorkspaces lists workspaces from the API, limited to the given scope
+func ScopedWorkspaces(ctx context.Context, client *fabric.Client, scope fabric.WorkspaceScope) ([]fabric.Workspace, error) {
+	workspaces, err := client.GetWorkspaces(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if scope.IsEmpty() {
+		return workspaces, nil
+	}
+
+	scoped := scope.Filter(workspaces)
+	logger.Info("Workspace scope applied", "selected", len(scoped), "workspaces", len(workspaces))
+	if len(scope.Include) == 0 && len(scope.Exclude) == 0 && len(scoped) < len(scope.IDs) {
+		logger.Warn("Configured workspace IDs were not found, are not accessible or are skipped personal workspaces",
+			"missing", len(scope.IDs)-len(scoped))
+	}
+	return scoped, nil
+}
+
+// CachedJobs returns all jobs from the local database with their Fabric deep links
+func (s *Syncer) CachedJobs() []api.Job {
+	if s.db == nil {
+		return []api.Job{}
+	}
+
+	// Get all jobs from database
+	jobs, err := s.db.GetJobInstances(db.JobFilter{})
+	if err != nil {
+		s.log().Error("Failed to get jobs from cache", logger.Err(err))
+		return []api.Job{}
+	}
+
+	result := make([]api.Job, 0, len(jobs))
+	for _, job := range jobs {
+		result = append(result, api.JobFromDB(job))
+	}
+	s.addExpectedEnds(result)
+
+	s.log().Debug("Loaded jobs from cache", "jobs", len(result))
+	return result
+}
+
+// SaveWorkspaces persists workspaces fetched from the API, logging rather than failing on errors
+func (s *Syncer) SaveWorkspaces(workspaces []fabric.Workspace) {
+	if s.db == nil || len(workspaces) == 0 {
+		s.log().Debug("Skipping workspace persistence", "database", s.db != nil, "workspaces", len(workspaces))
+		return
+	}
+
+	saved := 0
+	for _, ws := range workspaces {
+		dbWorkspace := &db.Workspace{
+			ID:          ws.ID,
+			DisplayName: ws.DisplayName,
+			Type:        ws.Type,
+		}
+		if ws.Description != "" {
+			dbWorkspace.Description = &ws.Description
+		}
+		if ws.CapacityID != "" {
+			dbWorkspace.CapacityID = &ws.CapacityID
+		}
+		if err := s.db.SaveWorkspace(dbWorkspace); err != nil {
+			s.log().Warn("Failed to save workspace", logger.WorkspaceID(ws.ID), logger.Err(err))
+			continue
+		}
+		saved++
+	}
+	s.addRowsWritten(saved)
+	s.log().Info("Persisted workspaces", "workspaces", len(workspaces))
+}
+
+// cachedItems loads the item lists of workspaces listed within ttl so the sync can skip listing them
+// Workspaces that were never listed, or whose list is older than ttl, are left out and get re-listed
+func (s *Syncer) cachedItems(workspaces []fabric.Workspace, ttl time.Duration) map[string][]fabric.Item {
+	cachedItemsByWorkspace := make(map[string][]fabric.Item)
+	if ttl <= 0 {
+		return cachedItemsByWorkspace
+	}
+
+	discovered, err := s.db.GetItemDiscoveryTimes()
+	if err != nil {
+		s.log().Warn("Failed to read item discovery times", logger.Err(err))
+		return cachedItemsByWorkspace
+	}
+
+	for _, ws := range workspaces {
+		lastDiscovered, ok := discovered[ws.ID]
+		if !ok || time.Since(lastDiscovered) > ttl {
+			continue
+		}
+
+		dbItems, err := s.db.GetItemsByWorkspace(ws.ID)
+		if err != nil || len(dbItems) == 0 {
+			continue
+		}
+
+		// Convert db.Item to fabric.Item
+		fabricItems := make([]fabric.Item, 0, len(dbItems))
+		for _, dbItem := range dbItems {
+			fabricItem := fabric.Item{
+				ID:          dbItem.ID,
+				WorkspaceID: dbItem.WorkspaceID,
+				DisplayName: dbItem.DisplayName,
+				Type:        dbItem.Type,
+			}
+			if dbItem.Description != nil {
+				fabricItem.Description = *dbItem.Description
+			}
+			fabricItems = append(fabricItems, fabricItem)
+		}
+		cachedItemsByWorkspace[ws.ID] = fabricItems
+		s.log().Debug("Loaded cached items", "workspace", ws.DisplayName, logger.WorkspaceID(ws.ID), "items", len(fabricItems),
+			"listedAgo", time.Since(lastDiscovered).Round(time.Minute))
+	}
+	return cachedItemsByWorkspace
+}
+
+// saveWorkspaceResult persists the items and jobs of one workspace as soon as it has been fetched
+// Items are written first so the jobs referencing them satisfy foreign key constraints
+func (s *Syncer) saveWorkspaceResult(ctx context.Context, result fabric.WorkspaceResult, incremental bool) {
+	if s.db == nil {
+		return
+	}
+	_, span := tracing.Start(ctx, "db.save_workspace_result", tracing.WorkspaceID(result.WorkspaceID),
+		attribute.Int("fabric.items", len(result.Items)), attribute.Int("fabric.jobs", len(result.Jobs)))
+	defer span.End()
+
+	s.saveMu.Lock()
+	defer s.saveMu.Unlock()
+
+	s.jobsFetched += len(result.Jobs)
+
+	// Persist the items listed by the API, stamping when the workspace was listed
+	discoveredAt := time.Now().UTC()
+	for _, fabricItem := range result.Items {
+		dbItem := db.Item{
+			ID:             fabricItem.ID,
+			WorkspaceID:    result.WorkspaceID,
+			DisplayName:    fabricItem.DisplayName,
+			Type:           fabricItem.Type,
+			LastDiscovered: &discoveredAt,
+		}
+		if fabricItem.Description != "" {
+			dbItem.Description = &fabricItem.Description
+		}
+		if err := s.db.SaveItem(&dbItem); err != nil {
+			s.log().Warn("Failed to save item", logger.WorkspaceID(result.WorkspaceID), logger.ItemID(dbItem.ID), logger.Err(err))
+			continue
+		}
+		s.rowsWritten++
+	}
+
+	if len(result.Jobs) == 0 {
+		return
+	}
+
+	// Also persist any item the jobs reference that was not listed (e.g. cached items on incremental syncs)
+	listed := make(map[string]bool, len(result.Items))
+	for _, item := range result.Items {
+		listed[item.ID] = true
+	}
+	for _, job := range result.Jobs {
+		if listed[job.ItemID] {
+			continue
+		}
+		listed[job.ItemID] = true
+		item := db.Item{
+			ID:          job.ItemID,
+			WorkspaceID: job.WorkspaceID,
+			DisplayName: job.ItemDisplayName,
+			Type:        job.ItemType,
+		}
+		if err := s.db.SaveItem(&item); err != nil {
+			s.log().Warn("Failed to save item", logger.WorkspaceID(item.WorkspaceID), logger.ItemID(item.ID), logger.Err(err))
+			continue
+		}
+		s.rowsWritten++
+	}
+
+	dbJobs := make([]db.JobInstance, 0, len(result.Jobs))
+	for _, job := range result.Jobs {
+		dbJobs = append(dbJobs, ToJobInstance(job))
+	}
+	if err := s.db.SaveJobInstances(dbJobs); err != nil {
+		s.log().Warn("Failed to save jobs", "workspace", result.WorkspaceName, logger.WorkspaceID(result.WorkspaceID), logger.Err(err))
+		tracing.Fail(span, err)
+		return
+	}
+
+	s.jobsSaved += len(dbJobs)
+	s.rowsWritten += len(dbJobs)
+	if incremental {
+		s.log().Info("Persisted new or updated job instances", "workspace", result.WorkspaceName,
+			logger.WorkspaceID(result.WorkspaceID), "jobs", len(dbJobs))
+	} else {
+		s.log().Info("Persisted job instances", "workspace", result.WorkspaceName,
+			logger.WorkspaceID(result.WorkspaceID), "jobs", len(dbJobs))
+	}
+	s.reporter.JobsSaved(result.WorkspaceName, len(dbJobs))
+}
+
+// markStaleJobs flags jobs that have been in progress for longer than after
+func (s *Syncer) markStaleJobs(after time.Duration) {
+	if s.db == nil || after <= 0 {
+		return
+	}
+
+	ids, err := s.db.MarkStaleJobs(time.Now().UTC().Add(-after))
+	if err != nil {
+		s.log().Warn("Failed to mark stale jobs", logger.Err(err))
+		return
+	}
+	if len(ids) > 0 {
+		s.log().Info("Marked long in-progress jobs as "+db.JobStatusStale, "jobs", len(ids), "after", after)
+	}
+}
+
+// scoreAnomalies scores the duration of newly completed runs against their item's history
+func (s *Syncer) scoreAnomalies() {
+	if s.db == nil || s.db.ReadOnly() {
+		return
+	}
+
+	scored, err := s.db.ScoreRunAnomalies(time.Now().UTC())
+	if err != nil {
+		s.log().Warn("Failed to score run anomalies", logger.Err(err))
+		return
+	}
+	if scored > 0 {
+		s.log().Info("Scored completed runs for anomalies", "runs", scored)
+	}
+}
+
+// measureRegressions compares the duration of newly completed runs with their item's recent successful runs
+func (s *Syncer) measureRegressions() {
+	if s.db == nil || s.db.ReadOnly() {
+		return
+	}
+
+	measured, err := s.db.MeasureDurationRegressions(time.Now().UTC())
+	if err != nil {
+		s.log().Warn("Failed to measure duration regressions", logger.Err(err))
+		return
+	}
+	if measured > 0 {
+		s.log().Info("Measured completed runs for duration regressions", "runs", measured)
+	}
+}
+
+// applyRetention deletes the runs of workspaces with a retention setting that are older than it
+func (s *Syncer) applyRetention() {
+	if s.db == nil || s.db.ReadOnly() {
+		return
+	}
+
+	settings, err := s.db.GetWorkspaceSettings()
+	if err != nil {
+		s.log().Warn("Failed to read workspace settings for retention", logger.Err(err))
+		return
+	}
+	now := time.Now().UTC()
+	for _, ws := range settings {
+		if ws.RetentionDays == nil {
+			continue
+		}
+		deleted, err := s.db.PruneWorkspaceHistory(ws.WorkspaceID, now.AddDate(0, 0, -*ws.RetentionDays))
+		if err != nil {
+			s.log().Warn("Failed to apply workspace retention", logger.WorkspaceID(ws.WorkspaceID), logger.Err(err))
+			continue
+		}
+		if deleted > 0 {
+			s.log().Info("Deleted runs past the workspace's retention", "workspace", ws.WorkspaceName,
+				logger.WorkspaceID(ws.WorkspaceID), "runs", deleted, "days", *ws.RetentionDays)
+		}
+	}
+}
+
+// announceFailures passes failed jobs to onJobFailed
+// Only incremental syncs announce failures - a full sync would replay the entire failure history
+func (s *Syncer) announceFailures(jobs []fabric.RecentJob, incremental bool, onJobFailed func(api.Job)) {
+	if !incremental || onJobFailed == nil {
+		return
+	}
+	for _, job := range jobs {
+		if job.Status == "Failed" {
+			onJobFailed(api.JobFromFabric(job, nil))
+		}
+	}
+}
+
+// reconcileWorkspace marks cached runs the API no longer returns for the items fetched in this workspace
+// Must run after saveWorkspaceResult, since saving a run clears its mark
+func (s *Syncer) reconcileWorkspace(result fabric.WorkspaceResult, cutoff time.Time) {
+	if s.db == nil {
+		return
+	}
+
+	s.saveMu.Lock()
+	defer s.saveMu.Unlock()
+
+	var marked int64
+	for itemID, jobIDs := range result.ReturnedJobIDs {
+		n, err := s.db.ReconcileItemJobs(itemID, jobIDs, cutoff)
+		if err != nil {
+			s.log().Warn("Failed to reconcile jobs", logger.WorkspaceID(result.WorkspaceID), logger.ItemID(itemID), logger.Err(err))
+			continue
+		}
+		marked += n
+	}
+	if marked > 0 {
+		s.log().Info("Cached runs marked as removed upstream", "workspace", result.WorkspaceName,
+			logger.WorkspaceID(result.WorkspaceID), "runs", marked)
+	}
+}
+
+// recordJobsSynced stores sync metadata once every workspace has been fetched
+func (s *Syncer) recordJobsSynced() {
+	if s.db == nil {
+		return
+	}
+
+	s.saveMu.Lock()
+	saved := s.jobsSaved
+	s.saveMu.Unlock()
+
+	s.log().Info("Persisted job instances", "jobs", saved)
+	if saved == 0 {
+		return
+	}
+	if err := s.db.UpdateSyncMetadata("job_instances", saved, 0); err != nil {
+		s.log().Warn("Failed to update sync metadata", logger.Err(err))
+	}
+}
+
+// ToJobInstance converts a job fetched from the API into its database row
+func ToJobInstance(job fabric.RecentJob) db.JobInstance {
+	dbJob := db.JobInstance{
+		ID:          job.ID,
+		WorkspaceID: job.WorkspaceID,
+		ItemID:      job.ItemID,
+		JobType:     job.JobType,
+		Status:      job.Status,
+		StartTime:   job.StartTime,
+		EndTime:     job.EndTime,
+		DurationMs:  job.DurationMs,
+	}
+	if job.FailureReason != "" {
+		failureReason := job.FailureReason
+		dbJob.FailureReason = &failureReason
+	}
+	if job.InvokeType != "" {
+		invokerType := job.InvokeType
+		dbJob.InvokerType = &invokerType
+	}
+	if job.RootActivityID != "" {
+		rootActivityID := job.RootActivityID
+		dbJob.RootActivityID = &rootActivityID
+	}
+	if len(job.FailureDetails) > 0 {
+		failureDetails := string(job.FailureDetails)
+		dbJob.FailureDetails = &failureDetails
+	}
+	return dbJob
+}
+
+// isItemTypeSynced reports whether jobs of the given item type are synced
+func isItemTypeSynced(excludedItemTypes []string, itemType string) bool {
+	for _, excluded := range excludedItemTypes {
+		if excluded == itemType {
+			return false
+		}
+	}
+	return true
+}
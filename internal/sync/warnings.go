@@ -0,0 +1,67 @@
+package sync
+
+import (
+	"context"
+	"errors"
+
+	"better-fabric-monitor/internal/api"
+	"better-fabric-monitor/internal/fabric"
+)
+
+// warningReporter passes progress on to the sync's Reporter and records failed workspaces and items as warnings
+type warningReporter struct {
+	Reporter
+	syncer *Syncer
+}
+
+// ItemFailed records a warning for the item before reporting it
+func (r warningReporter) ItemFailed(workspaceName, itemName string, err error) {
+	r.syncer.addWarning(workspaceName, itemName, err)
+	r.Reporter.ItemFailed(workspaceName, itemName, err)
+}
+
+// WorkspaceCompleted records a warning and counts the workspace if it failed before reporting it
+func (r warningReporter) WorkspaceCompleted(workspaceName string, err error) {
+	if err != nil && !errors.Is(err, context.Canceled) {
+		r.syncer.saveMu.Lock()
+		r.syncer.workspacesFailed++
+		r.syncer.saveMu.Unlock()
+		r.syncer.addWarning(workspaceName, "", err)
+	}
+	r.Reporter.WorkspaceCompleted(workspaceName, err)
+}
+
+// addWarning records a failure the sync carried on past
+// Failures caused by cancelling the sync are not warnings
+func (s *Syncer) addWarning(workspaceName, itemName string, err error) {
+	if errors.Is(err, context.Canceled) {
+		return
+	}
+	s.saveMu.Lock()
+	defer s.saveMu.Unlock()
+	s.warnings = append(s.warnings, api.SyncWarning{
+		Workspace: workspaceName,
+		Item:      itemName,
+		Error:     err.Error(),
+		Retryable: fabric.IsRetryable(err),
+	})
+}
+
+// hasRetryableWarning reports whether any warning is expected to clear up on the next sync
+func hasRetryableWarning(warnings []api.SyncWarning) bool {
+	for _, warning := range warnings {
+		if warning.Retryable {
+			return true
+		}
+	}
+	return false
+}
+
+// takeWarnings returns the warnings recorded so far and clears them
+func (s *Syncer) takeWarnings() []api.SyncWarning {
+	s.saveMu.Lock()
+	defer s.saveMu.Unlock()
+	warnings := s.warnings
+	s.warnings = nil
+	return warnings
+}
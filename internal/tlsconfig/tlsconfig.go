@@ -0,0 +1,90 @@
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Options are the TLS settings of the app's outbound HTTPS clients
+type Options struct {
+	CAFiles            []string // PEM bundles trusted in addition to the system roots, e.g. of a TLS-inspecting proxy
+	MinVersion         string   // Lowest TLS version accepted: 1.2 or 1.3 (empty is Go's default)
+	InsecureSkipVerify bool     // Accept any server certificate; only for diagnosing a proxy
+}
+
+// current is the TLS config handed out by Transport, nil until Set configures anything
+var current atomic.Pointer[tls.Config]
+
+// Set builds the TLS config of opts for the clients created afterwards; nothing changes when opts is invalid
+// Clients created before keep the settings they were created with
+func Set(opts Options) error {
+	config, err := build(opts)
+	if err != nil {
+		return err
+	}
+	current.Store(config)
+	return nil
+}
+
+// Validate checks opts the way Set does, without applying them
+func Validate(opts Options) error {
+	_, err := build(opts)
+	return err
+}
+
+// build returns the TLS config of opts, or nil when opts leave Go's defaults unchanged
+func build(opts Options) (*tls.Config, error) {
+	if len(opts.CAFiles) == 0 && opts.MinVersion == "" && !opts.InsecureSkipVerify {
+		return nil, nil
+	}
+	config := &tls.Config{InsecureSkipVerify: opts.InsecureSkipVerify}
+
+	switch strings.TrimSpace(opts.MinVersion) {
+	case "":
+	case "1.2":
+		config.MinVersion = tls.VersionTLS12
+	case "1.3":
+		config.MinVersion = tls.VersionTLS13
+	default:
+		return nil, fmt.Errorf("unsupported TLS version %q: use 1.2 or 1.3", opts.MinVersion)
+	}
+
+	if len(opts.CAFiles) > 0 {
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		for _, file := range opts.CAFiles {
+			pem, err := os.ReadFile(file)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+			}
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("no PEM certificates in CA bundle %s", file)
+			}
+		}
+		config.RootCAs = pool
+	}
+	return config, nil
+}
+
+// Transport returns a transport with the defaults of http.DefaultTransport, including the proxy from the
+// environment, and the TLS settings given to Set
+func Transport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if config := current.Load(); config != nil {
+		transport.TLSClientConfig = config.Clone()
+	}
+	return transport
+}
+
+// Client returns a client with the given timeout using a new Transport
+func Client(timeout time.Duration) *http.Client {
+	return &http.Client{Timeout: timeout, Transport: Transport()}
+}
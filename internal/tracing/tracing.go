@@ -0,0 +1,152 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+
+	"better-fabric-monitor/internal/logger"
+)
+
+// instrumentationName names the tracer the app's spans are created with
+const instrumentationName = "better-fabric-monitor"
+
+// Attribute keys shared by spans, so spans about the same workspace, job or sync can be found together
+const (
+	KeyWorkspaceID = attribute.Key("fabric.workspace.id")
+	KeyItemID      = attribute.Key("fabric.item.id")
+	KeyJobID       = attribute.Key("fabric.job.id")
+	KeySyncRunID   = attribute.Key("sync.run.id")
+)
+
+// Options configures where spans are exported
+type Options struct {
+	Endpoint       string            // host:port of an OTLP/HTTP collector, or its full URL, e.g. http://localhost:4318/v1/traces
+	Insecure       bool              // Export over plain HTTP when Endpoint has no scheme
+	Headers        map[string]string // Sent with every export, e.g. an API key of a hosted collector
+	SampleRatio    float64           // Fraction of sync runs traced (0 or above 1 traces all)
+	ServiceName    string
+	ServiceVersion string
+}
+
+// Global tracer provider, nil until Init is called; spans are no-ops without one
+var (
+	providerMu sync.Mutex
+	provider   *sdktrace.TracerProvider
+)
+
+// Init starts exporting spans to the OTLP endpoint in opts; spans are batched and sent in the background
+// A provider initialized earlier is shut down first
+func Init(ctx context.Context, opts Options) error {
+	if strings.TrimSpace(opts.Endpoint) == "" {
+		return errors.New("no OTLP endpoint configured")
+	}
+	exporterOpts := []otlptracehttp.Option{}
+	if strings.Contains(opts.Endpoint, "://") {
+		exporterOpts = append(exporterOpts, otlptracehttp.WithEndpointURL(opts.Endpoint))
+	} else {
+		exporterOpts = append(exporterOpts, otlptracehttp.WithEndpoint(opts.Endpoint))
+		if opts.Insecure {
+			exporterOpts = append(exporterOpts, otlptracehttp.WithInsecure())
+		}
+	}
+	if len(opts.Headers) > 0 {
+		exporterOpts = append(exporterOpts, otlptracehttp.WithHeaders(opts.Headers))
+	}
+	exporter, err := otlptracehttp.New(ctx, exporterOpts...)
+	if err != nil {
+		return fmt.Errorf("failed to create OTLP exporter: %w", err)
+	}
+
+	sampler := sdktrace.AlwaysSample()
+	if opts.SampleRatio > 0 && opts.SampleRatio < 1 {
+		sampler = sdktrace.TraceIDRatioBased(opts.SampleRatio)
+	}
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sampler)),
+		sdktrace.WithResource(resource.NewSchemaless(
+			attribute.String("service.name", opts.ServiceName),
+			attribute.String("service.version", opts.ServiceVersion),
+		)),
+	)
+
+	// Export failures, e.g. an unreachable collector, would otherwise go to stderr
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+		logger.Warn("Failed to export traces", logger.Err(err))
+	}))
+
+	providerMu.Lock()
+	previous := provider
+	provider = tp
+	providerMu.Unlock()
+	otel.SetTracerProvider(tp)
+	if previous != nil {
+		previous.Shutdown(ctx)
+	}
+	return nil
+}
+
+// Shutdown exports the spans still buffered and stops tracing
+func Shutdown(ctx context.Context) error {
+	providerMu.Lock()
+	tp := provider
+	provider = nil
+	providerMu.Unlock()
+	if tp == nil {
+		return nil
+	}
+	return tp.Shutdown(ctx)
+}
+
+// Start starts a span named name as a child of the span in ctx, returning a context carrying it
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End ends span, marking it failed with err when err is not nil
+func End(span trace.Span, err error) {
+	Fail(span, err)
+	span.End()
+}
+
+// Fail marks span failed with err, for spans that carry on after an error; a nil err leaves it unchanged
+// Error messages are redacted like log records, since API error bodies may carry secrets
+func Fail(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+	message := logger.Redact(err.Error())
+	span.RecordError(errors.New(message))
+	span.SetStatus(codes.Error, message)
+}
+
+// WorkspaceID is the attribute of the workspace a span works on
+func WorkspaceID(id string) attribute.KeyValue {
+	return KeyWorkspaceID.String(id)
+}
+
+// ItemID is the attribute of the item a span works on
+func ItemID(id string) attribute.KeyValue {
+	return KeyItemID.String(id)
+}
+
+// JobID is the attribute of the job instance a span works on
+func JobID(id string) attribute.KeyValue {
+	return KeyJobID.String(id)
+}
+
+// SyncRunID is the attribute of the sync run a span belongs to
+func SyncRunID(id string) attribute.KeyValue {
+	return KeySyncRunID.String(id)
+}
@@ -2,50 +2,152 @@ package utils
 
 import (
 	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync/atomic"
 
 	"better-fabric-monitor/internal/logger"
 )
 
-// GenerateFabricURL creates a deep link to Microsoft Fabric for a job run
-// Returns an empty string if the item type is not supported or required fields are missing
+// DefaultFabricHost is the Fabric portal host deep links point to unless configured otherwise
+const DefaultFabricHost = "app.powerbi.com"
+
+// DefaultFabricURLTemplates are the deep links to the run monitoring pages of the item types that have one
+// Placeholders are {host}, {workspaceId}, {itemId}, {jobId} and {livyId}
+var DefaultFabricURLTemplates = map[string]string{
+	"DataPipeline": "https://{host}/workloads/data-pipeline/monitoring/workspaces/{workspaceId}/pipelines/{itemId}/{jobId}?experience=fabric-developer",
+	"Notebook":     "https://{host}/workloads/de-ds/sparkmonitor/{itemId}/{livyId}?experience=fabric-developer",
+}
+
+// urlPlaceholder matches the placeholders of a URL template
+var urlPlaceholder = regexp.MustCompile(`\{(\w+)\}`)
+
+// knownPlaceholders are the placeholders GenerateFabricURL fills in
+var knownPlaceholders = map[string]bool{"host": true, "workspaceId": true, "itemId": true, "jobId": true, "livyId": true}
+
+// fabricURLs holds the portal host and URL template of each item type that deep links are generated with
+type fabricURLs struct {
+	host      string
+	templates map[string]string
+}
+
+// currentURLs is replaced as a whole by SetFabricURLs, so GenerateFabricURL never sees half an update
+var currentURLs atomic.Pointer[fabricURLs]
+
+func init() {
+	currentURLs.Store(&fabricURLs{host: DefaultFabricHost, templates: DefaultFabricURLTemplates})
+}
+
+// SetFabricURLs sets the portal host, e.g. app.fabric.microsoft.com or a sovereign cloud's, and URL templates that
+// override or add to DefaultFabricURLTemplates per item type; an empty template turns an item type's links off
+// An empty host keeps DefaultFabricHost; nothing changes when host or a template is invalid
+func SetFabricURLs(host string, templates map[string]string) error {
+	if err := ValidateFabricURLs(host, templates); err != nil {
+		return err
+	}
+	host = normalizeHost(host)
+	merged := make(map[string]string, len(DefaultFabricURLTemplates)+len(templates))
+	for itemType, template := range DefaultFabricURLTemplates {
+		merged[itemType] = template
+	}
+	for itemType, template := range templates {
+		merged[itemType] = template
+	}
+	currentURLs.Store(&fabricURLs{host: host, templates: merged})
+	return nil
+}
+
+// ValidateFabricURLs checks the portal host and URL templates SetFabricURLs accepts, without applying them
+func ValidateFabricURLs(host string, templates map[string]string) error {
+	if host := normalizeHost(host); strings.ContainsAny(host, "/?#@ ") {
+		return fmt.Errorf("invalid portal host %q: use a host name such as app.fabric.microsoft.com", host)
+	}
+	for itemType, template := range templates {
+		if template == "" {
+			continue
+		}
+		if err := validateURLTemplate(template); err != nil {
+			return fmt.Errorf("invalid URL template for %s: %w", itemType, err)
+		}
+	}
+	return nil
+}
+
+// normalizeHost strips the scheme and trailing slash of host, returning DefaultFabricHost when it is empty
+func normalizeHost(host string) string {
+	host = strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(host), "https://"), "/")
+	if host == "" {
+		return DefaultFabricHost
+	}
+	return host
+}
+
+// validateURLTemplate checks that template uses only known placeholders and is an http(s) URL once filled in
+func validateURLTemplate(template string) error {
+	for _, match := range urlPlaceholder.FindAllStringSubmatch(template, -1) {
+		if !knownPlaceholders[match[1]] {
+			return fmt.Errorf("unknown placeholder {%s}", match[1])
+		}
+	}
+	sample := urlPlaceholder.ReplaceAllString(template, "x")
+	parsed, err := url.Parse(sample)
+	if err != nil {
+		return err
+	}
+	if (parsed.Scheme != "https" && parsed.Scheme != "http") || parsed.Host == "" {
+		return fmt.Errorf("%q is not an http(s) URL", template)
+	}
+	return nil
+}
+
+// GenerateFabricURL creates a deep link to Microsoft Fabric for a job run from the URL template of its item type
+// Returns an empty string if the item type has no template or a field the template uses is missing
 // For notebooks, uses livyID if available, otherwise falls back to jobRunID (which may not work)
 func GenerateFabricURL(workspaceID, itemID, itemType, jobRunID string, livyID *string) string {
 	// Return empty if any required field is missing
 	if workspaceID == "" || jobRunID == "" {
 		return ""
 	}
+	urls := currentURLs.Load()
+	template := urls.templates[itemType]
+	if template == "" {
+		return ""
+	}
 
-	switch itemType {
-	case "DataPipeline":
-		// Pipeline URL requires itemID as well
-		if itemID == "" {
-			return ""
-		}
-		return fmt.Sprintf(
-			"https://app.powerbi.com/workloads/data-pipeline/monitoring/workspaces/%s/pipelines/%s/%s?experience=fabric-developer",
-			workspaceID, itemID, jobRunID,
-		)
-	case "Notebook":
-		// Notebook URL requires itemID (notebookId) and livyID
-		if itemID == "" {
+	values := map[string]string{
+		"host":        urls.host,
+		"workspaceId": workspaceID,
+		"itemId":      itemID,
+		"jobId":       jobRunID,
+	}
+	// Fall back to jobRunID (may not work, but better than no link)
+	// To get correct links, run SyncNotebookSessions() to populate livyID
+	fallback := livyID == nil || *livyID == ""
+	if fallback {
+		values["livyId"] = jobRunID
+	} else {
+		values["livyId"] = *livyID
+	}
+
+	missing := false
+	link := urlPlaceholder.ReplaceAllStringFunc(template, func(placeholder string) string {
+		name := placeholder[1 : len(placeholder)-1]
+		if values[name] == "" {
+			missing = true
 			return ""
 		}
-		// Use livyID if available for correct URL
-		if livyID != nil && *livyID != "" {
-			return fmt.Sprintf(
-				"https://app.powerbi.com/workloads/de-ds/sparkmonitor/%s/%s?experience=fabric-developer",
-				itemID, *livyID,
-			)
+		if name == "host" {
+			return values[name]
 		}
-		// Fall back to jobRunID (may not work, but better than no link)
-		// To get correct links, run SyncNotebookSessions() to populate livyID
-		logger.Log("Warning: Generating fallback notebook URL using jobRunID for notebook %s (job %s). Link may not work if capacity was paused during execution.\n", itemID, jobRunID)
-		return fmt.Sprintf(
-			"https://app.powerbi.com/workloads/de-ds/sparkmonitor/%s/%s?experience=fabric-developer",
-			itemID, jobRunID,
-		)
-	default:
-		// Unsupported item type
+		return url.PathEscape(values[name])
+	})
+	if missing {
 		return ""
 	}
+	if fallback && strings.Contains(template, "{livyId}") {
+		logger.Warn("Generating fallback notebook URL from the job run ID; the link may not work if the capacity was paused during execution",
+			logger.ItemID(itemID), logger.JobID(jobRunID))
+	}
+	return link
 }
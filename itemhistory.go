@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"better-fabric-monitor/internal/api"
+	"better-fabric-monitor/internal/db"
+	"better-fabric-monitor/internal/logger"
+)
+
+// GetItemRunHistory returns the runs of one item started in the last days (30 by default), oldest first, for a
+// sparkline of its history: each run's status, duration and deviation from the median of the item's last
+// successful runs, plus the cadence of its scheduled runs and the gaps in which runs were skipped
+// Runs not measured against their baseline yet are measured first
+func (a *App) GetItemRunHistory(itemID string, days int) api.ItemRunHistoryResult {
+	if a.db() == nil {
+		return api.ItemRunHistoryResult{Error: "Database not initialized"}
+	}
+	if itemID == "" {
+		return api.ItemRunHistoryResult{Error: "Item ID is required"}
+	}
+	if !a.background.Begin() {
+		return api.ItemRunHistoryResult{Error: errShuttingDown.Error()}
+	}
+	defer a.background.Done()
+	if days <= 0 {
+		days = defaultCadenceDays
+	}
+
+	if !a.db().ReadOnly() {
+		if _, err := a.db().MeasureDurationRegressions(time.Now().UTC()); err != nil {
+			logger.Warn("Failed to measure duration regressions", logger.Err(err))
+		}
+	}
+
+	history, err := a.db().GetItemRunHistory(itemID, days)
+	if err != nil {
+		return api.ItemRunHistoryResult{Error: fmt.Sprintf("Failed to get item run history: %v", err)}
+	}
+
+	result := api.ItemRunHistoryResult{Days: days, ItemRunHistory: *history, Gaps: []db.RunGap{}}
+	// Like GetCadenceReport, only scheduled and API runs set the cadence
+	inferred, err := a.db().GetItemCadence(itemID, days, time.Now().UTC())
+	if err != nil {
+		logger.Warn("Failed to infer run cadence", logger.ItemID(itemID), logger.Err(err))
+		return result
+	}
+	if inferred != nil {
+		cadence := api.CadenceFromDB(*inferred)
+		result.Cadence = &cadence
+		if inferred.Regular {
+			if result.Gaps, err = a.db().GetItemRunGaps(itemID, days, inferred.CadenceMs); err != nil {
+				logger.Warn("Failed to find skipped runs", logger.ItemID(itemID), logger.Err(err))
+				result.Gaps = []db.RunGap{}
+			}
+		}
+	}
+	return result
+}
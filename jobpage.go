@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+
+	"better-fabric-monitor/internal/api"
+	"better-fabric-monitor/internal/db"
+)
+
+const (
+	defaultJobsPageSize = 100
+	maxJobsPageSize     = 1000
+)
+
+// GetJobsPaged returns one page of the cached jobs matching filter in the order of sort, with the total count,
+// so the jobs grid only loads the rows it shows, and the item types and statuses it can be filtered by
+// page is 1-based; pageSize defaults to 100 and is capped at 1000
+func (a *App) GetJobsPaged(filter db.JobSearch, sort db.JobSort, page, pageSize int) api.JobsPageResult {
+	if a.db() == nil {
+		return api.JobsPageResult{Error: "Database not initialized"}
+	}
+	if sort.Field != "" && !db.ValidJobSortField(sort.Field) {
+		return api.JobsPageResult{Error: fmt.Sprintf("Unsupported sort field: %s", sort.Field)}
+	}
+	if page < 1 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = defaultJobsPageSize
+	}
+	pageSize = min(pageSize, maxJobsPageSize)
+
+	instances, total, err := a.db().GetJobPage(filter, sort, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return api.JobsPageResult{Error: fmt.Sprintf("Failed to get jobs: %v", err)}
+	}
+
+	itemTypes, statuses, err := a.db().GetJobFacets()
+	if err != nil {
+		return api.JobsPageResult{Error: fmt.Sprintf("Failed to get job filters: %v", err)}
+	}
+
+	jobs := make([]api.Job, 0, len(instances))
+	for _, job := range instances {
+		jobs = append(jobs, api.JobFromDB(job))
+	}
+	a.syncer().ExpectRunningJobs(a.ctx, nil, jobs)
+	return api.JobsPageResult{
+		Jobs:      jobs,
+		Total:     total,
+		Page:      page,
+		PageSize:  pageSize,
+		ItemTypes: itemTypes,
+		Statuses:  statuses,
+	}
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"archive/zip"
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+
+	"better-fabric-monitor/internal/api"
+	"better-fabric-monitor/internal/config"
+	"better-fabric-monitor/internal/logger"
+)
+
+// logExportMaxAge is how far back the log files included in a log export reach
+const logExportMaxAge = 7 * 24 * time.Hour
+
+// ExportLogs writes a zip of the logs in memory and the log files of the last week, crash.log included, to path
+// for attaching to support requests; an empty path asks where to save it
+// With redact set, every line is redacted again as log records are, covering files written by older versions
+func (a *App) ExportLogs(path string, redact bool) api.LogExportResult {
+	if path == "" {
+		if a.ctx == nil {
+			return api.LogExportResult{Error: "No path given"}
+		}
+		chosen, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+			Title:                "Export logs",
+			DefaultFilename:      fmt.Sprintf("fabric-monitor-logs-%s.zip", time.Now().Format("20060102-150405")),
+			Filters:              []runtime.FileFilter{{DisplayName: "Zip archives (*.zip)", Pattern: "*.zip"}},
+			CanCreateDirectories: true,
+		})
+		if err != nil {
+			return api.LogExportResult{Error: fmt.Sprintf("Failed to choose export location: %v", err)}
+		}
+		if chosen == "" {
+			return api.LogExportResult{Cancelled: true}
+		}
+		path = chosen
+	}
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return api.LogExportResult{Error: fmt.Sprintf("Failed to resolve export path: %v", err)}
+	}
+
+	files, err := a.writeLogExport(path, redact)
+	if err != nil {
+		os.Remove(path)
+		logger.Error("Failed to export logs", logger.Err(err))
+		return api.LogExportResult{Error: fmt.Sprintf("Failed to export logs: %v", err)}
+	}
+
+	logger.Info("Logs exported", "path", path, "files", files, "redacted", redact)
+	return api.LogExportResult{Path: path, Files: files}
+}
+
+// writeLogExport writes the log export zip to path, returning how many log files it includes
+func (a *App) writeLogExport(path string, redact bool) (int, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	archive := zip.NewWriter(file)
+	w, err := archive.Create("buffer.txt")
+	if err != nil {
+		return 0, err
+	}
+	for _, entry := range logger.GetAll() {
+		line := fmt.Sprintf("[%s] %s: %s\n", entry.Timestamp, entry.Level, entry.Text())
+		if redact {
+			line = logger.Redact(line)
+		}
+		if _, err := io.WriteString(w, line); err != nil {
+			return 0, err
+		}
+	}
+
+	paths := a.logExportFiles()
+	for _, logPath := range paths {
+		if err := addLogFile(archive, logPath, redact); err != nil {
+			return 0, fmt.Errorf("failed to add %s: %w", filepath.Base(logPath), err)
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return 0, err
+	}
+	return len(paths), file.Close()
+}
+
+// logExportFiles returns the log files written within logExportMaxAge and crash.log, if there are any
+// Files are looked up in the configured log directory even when the log file is turned off, to pick up older ones
+func (a *App) logExportFiles() []string {
+	var cfg config.LogFileConfig
+	if a.config() != nil {
+		cfg = a.config().App.LogFile
+	}
+	dir, err := logFileDir(cfg)
+	if err != nil {
+		return nil
+	}
+	paths, err := logger.LogFiles(dir, logExportMaxAge)
+	if err != nil && !os.IsNotExist(err) {
+		logger.Warn("Failed to list log files", "dir", dir, logger.Err(err))
+	}
+	if info, err := os.Stat(filepath.Join(dir, crashLogName)); err == nil && info.Size() > 0 {
+		paths = append(paths, filepath.Join(dir, crashLogName))
+	}
+	return paths
+}
+
+// addLogFile copies the log file at path into archive under its base name, redacting each line when redact is set
+func addLogFile(archive *zip.Writer, path string, redact bool) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	w, err := archive.Create(filepath.Base(path))
+	if err != nil {
+		return err
+	}
+	if !redact {
+		_, err = io.Copy(w, src)
+		return err
+	}
+	reader := bufio.NewReader(src)
+	for {
+		line, readErr := reader.ReadString('\n')
+		if _, err := io.WriteString(w, logger.Redact(line)); err != nil {
+			return err
+		}
+		if readErr == io.EOF {
+			return nil
+		}
+		if readErr != nil {
+			return readErr
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"time"
+
+	"better-fabric-monitor/internal/config"
+	"better-fabric-monitor/internal/logger"
+)
+
+// crashLogName is the file in the log directory that fatal errors, such as unrecovered panics, are written to
+const crashLogName = "crash.log"
+
+// openLogFile starts writing logs to the rotating log file configured by cfg, and crashes to crash.log beside it
+// Failures are logged and otherwise ignored, since the console and the Logs view still work
+func openLogFile(cfg config.LogFileConfig) {
+	if !cfg.Enabled {
+		return
+	}
+	dir, err := logFileDir(cfg)
+	if err != nil {
+		logger.Warn("Log file disabled: no app data directory", logger.Err(err))
+		return
+	}
+
+	path, err := logger.OpenFile(logger.FileOptions{
+		Dir:         dir,
+		MaxSize:     int64(cfg.MaxSizeMB) << 20,
+		RotateEvery: cfg.RotateEvery,
+		MaxAge:      time.Duration(cfg.MaxAgeDays) * 24 * time.Hour,
+		MaxBackups:  cfg.MaxBackups,
+	})
+	if err != nil {
+		logger.Warn("Log file disabled", logger.Err(err))
+		return
+	}
+	logger.Info("Writing logs to file", "path", path)
+
+	crashLog, err := os.OpenFile(filepath.Join(dir, crashLogName), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		logger.Warn("Failed to open crash log", logger.Err(err))
+		return
+	}
+	defer crashLog.Close() // SetCrashOutput keeps its own duplicate of the file
+	if err := debug.SetCrashOutput(crashLog, debug.CrashOptions{}); err != nil {
+		logger.Warn("Failed to set crash output", logger.Err(err))
+	}
+}
+
+// logFileDir returns the directory of the log files configured by cfg: app.log_file.dir, or logs/ in the app data
+// directory
+func logFileDir(cfg config.LogFileConfig) (string, error) {
+	if cfg.Dir != "" {
+		return cfg.Dir, nil
+	}
+	dataDir, err := config.GetDataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dataDir, "logs"), nil
+}
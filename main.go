@@ -2,16 +2,26 @@ package main
 
 import (
 	"embed"
+	"os"
 
 	"github.com/wailsapp/wails/v2"
 	"github.com/wailsapp/wails/v2/pkg/options"
 	"github.com/wailsapp/wails/v2/pkg/options/assetserver"
+
+	"better-fabric-monitor/internal/reporting"
 )
 
 //go:embed all:frontend/dist
 var assets embed.FS
 
 func main() {
+	defer reporting.RecoverPanic("main")
+
+	// Subcommands such as sync run headless and exit
+	if code, ok := runCommand(os.Args[1:]); ok {
+		os.Exit(code)
+	}
+
 	// Create an instance of the app structure
 	app := NewApp()
 
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"better-fabric-monitor/internal/api"
+	"better-fabric-monitor/internal/db"
+	"better-fabric-monitor/internal/logger"
+	"better-fabric-monitor/internal/notify"
+)
+
+// applyNotificationMutes makes notifier skip events about items and workspaces muted in database
+func applyNotificationMutes(notifier *notify.Notifier, database *db.Database) {
+	if notifier.Empty() || database == nil {
+		return
+	}
+	notifier.SetMuteCheck(func(event notify.Event) bool {
+		if event.Job == nil {
+			return false
+		}
+		muted, err := database.IsMuted(event.Job.WorkspaceID, event.Job.ItemID, time.Now().UTC())
+		if err != nil {
+			logger.Warn("Failed to check notification mutes", logger.Err(err))
+			return false
+		}
+		return muted
+	})
+}
+
+// MuteNotifications silences notifications about an item or a whole workspace
+// days limits the mute (0 mutes until UnmuteNotifications is called); muting again replaces the previous mute
+func (a *App) MuteNotifications(targetType, targetID string, days int, reason string) error {
+	if err := a.writable(); err != nil {
+		return err
+	}
+	if targetType != db.MuteTargetItem && targetType != db.MuteTargetWorkspace {
+		return fmt.Errorf("unsupported mute target: %s", targetType)
+	}
+	if targetID == "" {
+		return fmt.Errorf("mute target ID is required")
+	}
+	if days < 0 {
+		return fmt.Errorf("mute duration cannot be negative")
+	}
+
+	now := time.Now().UTC()
+	mute := &db.NotificationMute{
+		TargetType: targetType,
+		TargetID:   targetID,
+		Reason:     optionalString(strings.TrimSpace(reason)),
+		MutedAt:    now,
+	}
+	if days > 0 {
+		expiresAt := now.AddDate(0, 0, days)
+		mute.ExpiresAt = &expiresAt
+	}
+	if err := a.db().SaveMute(mute); err != nil {
+		return fmt.Errorf("failed to mute notifications: %w", err)
+	}
+
+	if days > 0 {
+		logger.Info("Notifications muted", "targetType", targetType, "targetID", targetID, "days", days)
+	} else {
+		logger.Info("Notifications muted until unmuted", "targetType", targetType, "targetID", targetID)
+	}
+	return nil
+}
+
+// UnmuteNotifications lifts the mute of an item or workspace
+func (a *App) UnmuteNotifications(targetType, targetID string) error {
+	if err := a.writable(); err != nil {
+		return err
+	}
+	if err := a.db().DeleteMute(targetType, targetID); err != nil {
+		return fmt.Errorf("failed to unmute notifications: %w", err)
+	}
+	logger.Info("Notifications unmuted", "targetType", targetType, "targetID", targetID)
+	return nil
+}
+
+// GetNotificationMutes returns the items and workspaces whose notifications are currently muted
+func (a *App) GetNotificationMutes() api.NotificationMutesResult {
+	if a.db() == nil {
+		return api.NotificationMutesResult{Error: "Database not initialized"}
+	}
+	mutes, err := a.db().GetActiveMutes(time.Now().UTC())
+	if err != nil {
+		return api.NotificationMutesResult{Error: fmt.Sprintf("Failed to get notification mutes: %v", err)}
+	}
+	if mutes == nil {
+		mutes = []db.NotificationMute{}
+	}
+	return api.NotificationMutesResult{Mutes: mutes}
+}
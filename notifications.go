@@ -0,0 +1,255 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"better-fabric-monitor/internal/api"
+	"better-fabric-monitor/internal/config"
+	"better-fabric-monitor/internal/db"
+	"better-fabric-monitor/internal/logger"
+	"better-fabric-monitor/internal/notify"
+	"better-fabric-monitor/internal/secrets"
+)
+
+// Rules naming the notification channels, after the config key of each
+const (
+	ruleWebhook           = "notifications.webhook"
+	ruleEscalationWebhook = "notifications.escalation.webhook"
+)
+
+// notificationRules lists every rule a workspace's notifications can be routed to
+var notificationRules = []string{ruleWebhook, ruleEscalationWebhook}
+
+// newNotifier builds the notification channels enabled in cfg
+// A misconfigured channel is logged and left out rather than failing startup
+func newNotifier(cfg config.NotificationConfig) *notify.Notifier {
+	notifier := notify.New()
+	addWebhookChannel(notifier, ruleWebhook, cfg.Webhook, 0)
+	// Escalation only ever sees job failures, starting at the configured streak
+	addWebhookChannel(notifier, ruleEscalationWebhook, cfg.Escalation.Webhook, max(cfg.Escalation.AfterFailures, 1))
+	return notifier
+}
+
+// addWebhookChannel adds the webhook channel configured under key when it is enabled
+func addWebhookChannel(notifier *notify.Notifier, key string, cfg config.WebhookChannelConfig, minFailureStreak int) {
+	if !cfg.Enabled {
+		return
+	}
+	for _, eventType := range cfg.Events {
+		if !notify.ValidEventType(eventType) {
+			logger.Warn("Unknown event type in notification events", "setting", key+".events", "eventType", eventType)
+		}
+	}
+
+	secret, err := secrets.Resolve(cfg.Secret)
+	if err != nil {
+		logger.Warn("Webhook notifications disabled", "setting", key+".secret", logger.Err(err))
+		return
+	}
+	channel, err := notify.NewWebhookChannel(cfg.URL, secret)
+	if err != nil {
+		logger.Warn("Webhook notifications disabled", "setting", key, logger.Err(err))
+		return
+	}
+	opts := routeOptions(cfg.Events, cfg.QuietHours, cfg.MaxPerHour)
+	opts.Rule = key
+	opts.MinFailureStreak = minFailureStreak
+	notifier.Add(channel, opts)
+	logger.Info("Webhook notifications enabled", "setting", key, "channel", channel.Name())
+}
+
+// recordNotifications stores the outcome of every delivery of notifier in the notification history
+func recordNotifications(notifier *notify.Notifier, database *db.Database) {
+	if notifier.Empty() || database == nil || database.ReadOnly() {
+		return
+	}
+	notifier.SetRecorder(func(d notify.Delivery) {
+		if err := database.SaveNotification(notificationRecord(d)); err != nil {
+			logger.Warn("Failed to record notification", logger.Err(err))
+		}
+	})
+}
+
+// notificationRecord converts a delivery to its notification history row
+func notificationRecord(d notify.Delivery) *db.NotificationRecord {
+	record := &db.NotificationRecord{
+		SentAt:       d.At,
+		Rule:         d.Rule,
+		Channel:      d.Channel,
+		EventType:    d.Event.Type,
+		Title:        d.Event.Title,
+		Message:      optionalString(d.Event.Message),
+		Status:       d.Status,
+		ErrorMessage: optionalString(d.Error),
+	}
+	if job := d.Event.Job; job != nil {
+		record.JobID = optionalString(job.ID)
+		record.ItemID = optionalString(job.ItemID)
+		record.WorkspaceID = optionalString(job.WorkspaceID)
+	}
+	return record
+}
+
+// optionalString returns nil for an empty string so it is stored as NULL
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// routeOptions builds a channel's delivery options; invalid quiet hours are logged and ignored
+func routeOptions(events []string, quietHours string, maxPerHour int) notify.RouteOptions {
+	opts := notify.RouteOptions{Events: events, MaxPerHour: maxPerHour}
+	if quietHours != "" {
+		window, err := notify.ParseQuietHours(quietHours)
+		if err != nil {
+			logger.Warn("Ignoring quiet hours", logger.Err(err))
+		} else {
+			opts.QuietHours = window
+		}
+	}
+	return opts
+}
+
+// notificationFlushInterval is how often summaries of held-back notifications are retried
+const notificationFlushInterval = time.Minute
+
+// startNotificationFlusher sends summaries of held-back notifications once quiet hours end or the rate limit frees up
+func (a *App) startNotificationFlusher() {
+	if a.notifier.Empty() {
+		return
+	}
+	a.background.Go(func() {
+		ticker := time.NewTicker(notificationFlushInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-a.ctx.Done():
+				return
+			case <-ticker.C:
+				a.notifier.Flush(a.ctx)
+			}
+		}
+	})
+}
+
+// sendNotification delivers event to the configured channels in the background
+// Nothing is sent while notifications are disabled
+func (a *App) sendNotification(event notify.Event) {
+	if !a.config().Notifications.Enabled || a.notifier.Empty() {
+		return
+	}
+	a.background.Go(func() {
+		a.notifier.Notify(a.ctx, event)
+	})
+}
+
+// notifyJobFailed announces a failed run unless failure notifications are turned off
+// The item's failure streak decides whether the failure also escalates
+func (a *App) notifyJobFailed(job api.Job) {
+	if !a.config().Notifications.Enabled || !a.config().Notifications.OnFailure {
+		return
+	}
+	a.playAlertSound(notify.EventJobFailed, job)
+	if a.notifier.Empty() {
+		return
+	}
+	a.sendNotification(notify.JobFailedEvent(job, failureStreak(a.db(), job)))
+}
+
+// failureStreak returns how many times in a row the job's item has failed, at least 1
+func failureStreak(database *db.Database, job api.Job) int {
+	if database == nil {
+		return 1
+	}
+	streak, err := database.GetFailureStreak(job.ItemID)
+	if err != nil {
+		logger.Warn("Failed to read failure streak", "item", job.ItemDisplayName, logger.ItemID(job.ItemID), logger.Err(err))
+		return 1
+	}
+	return max(streak, 1)
+}
+
+// defaultNotificationHistoryLimit is how many notifications GetNotificationHistory returns when no limit is given
+const defaultNotificationHistoryLimit = 100
+
+// GetNotificationHistory returns the most recent notifications routed to a channel, newest first,
+// including failed deliveries and those held back by quiet hours or the rate limit
+func (a *App) GetNotificationHistory(limit int) api.NotificationHistoryResult {
+	if a.db() == nil {
+		return api.NotificationHistoryResult{Error: "Database not initialized"}
+	}
+	if limit <= 0 {
+		limit = defaultNotificationHistoryLimit
+	}
+
+	history, err := a.db().GetNotificationHistory(limit)
+	if err != nil {
+		return api.NotificationHistoryResult{Error: fmt.Sprintf("Failed to get notification history: %v", err)}
+	}
+	if history == nil {
+		history = []db.NotificationRecord{}
+	}
+	return api.NotificationHistoryResult{Notifications: history}
+}
+
+// TestNotification sends a test notification through the channel configured under rule, e.g. notifications.webhook,
+// ignoring its event filter, quiet hours and rate limit so its settings can be checked right away
+func (a *App) TestNotification(rule string) error {
+	if a.notifier.Empty() {
+		return fmt.Errorf("no notification channel is enabled")
+	}
+	return a.notifier.Test(a.ctx, rule)
+}
+
+// RuleSimulation is what the channel of a rule would do with the notification a sample job raises
+type RuleSimulation struct {
+	Error    string          `json:"error,omitempty"`
+	Event    notify.Event    `json:"event"`
+	Decision notify.Decision `json:"decision"`
+}
+
+// SimulateRule reports whether the channel configured under rule would be notified about the job with ID
+// sampleJobID right now, and why not; nothing is sent
+// Failed jobs raise job.failed with their item's current failure streak, queued and running jobs job.long_running
+func (a *App) SimulateRule(rule, sampleJobID string) RuleSimulation {
+	if a.db() == nil {
+		return RuleSimulation{Error: "Database not initialized"}
+	}
+	if a.notifier.Empty() {
+		return RuleSimulation{Error: "No notification channel is enabled"}
+	}
+
+	instance, err := a.db().GetJobInstanceWithActivities(sampleJobID)
+	if err != nil {
+		return RuleSimulation{Error: fmt.Sprintf("Failed to get job %s: %v", sampleJobID, err)}
+	}
+	job := api.JobFromDB(*instance)
+
+	var event notify.Event
+	switch instance.Status {
+	case "Failed":
+		event = notify.JobFailedEvent(job, failureStreak(a.db(), job))
+	case "InProgress", "NotStarted":
+		event = notify.JobLongRunningEvent(job, time.Since(instance.StartTime), 0)
+	default:
+		return RuleSimulation{Error: fmt.Sprintf("%s jobs raise no notifications; pick a failed or running job", instance.Status)}
+	}
+
+	decision, err := a.notifier.Simulate(rule, event, time.Now())
+	if err != nil {
+		return RuleSimulation{Error: err.Error()}
+	}
+	switch {
+	case !a.config().Notifications.Enabled:
+		decision.Deliver, decision.Reason = false, "Notifications are disabled"
+	case event.Type == notify.EventJobFailed && !a.config().Notifications.OnFailure:
+		decision.Deliver, decision.Reason = false, "Failure notifications are turned off"
+	case event.Type == notify.EventJobLongRunning && !a.config().Notifications.OnLongRunning:
+		decision.Deliver, decision.Reason = false, "Long-running notifications are turned off"
+	}
+	return RuleSimulation{Event: event, Decision: decision}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"better-fabric-monitor/internal/config"
+	"better-fabric-monitor/internal/logger"
+)
+
+// errOffline is returned by bindings that call the Fabric API while offline mode is on
+var errOffline = errors.New("offline mode is on; turn it off and sign in to sync from the Fabric API")
+
+// offline reports whether offline mode is on
+func (a *App) offline() bool {
+	return a.config() != nil && a.config().App.Offline
+}
+
+// IsOffline reports whether the app serves only cached data without calling the Fabric API
+func (a *App) IsOffline() bool {
+	return a.offline()
+}
+
+// SetOfflineMode turns offline mode on or off and saves the choice
+// While on, no binding calls the Fabric API and every view is served from the local database
+func (a *App) SetOfflineMode(enabled bool) error {
+	if a.config() == nil {
+		return fmt.Errorf("configuration not loaded")
+	}
+	if a.config().App.Offline == enabled {
+		return nil
+	}
+
+	err := a.updateConfig(func(cfg *config.Config) error {
+		cfg.App.Offline = enabled
+		return cfg.Save()
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save offline mode: %w", err)
+	}
+	if enabled {
+		a.CancelSync()
+	}
+
+	if enabled {
+		logger.Info("Offline mode enabled: serving cached data only")
+	} else {
+		logger.Info("Offline mode disabled")
+	}
+	return nil
+}
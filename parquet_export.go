@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"better-fabric-monitor/internal/config"
+	"better-fabric-monitor/internal/db"
+	"better-fabric-monitor/internal/logger"
+)
+
+// ExportNow starts a Parquet export immediately, ignoring the export interval
+// Returns false if the replica is disabled or an export is already running
+func (a *App) ExportNow() bool {
+	return a.StartParquetExport()
+}
+
+// scheduleParquetExport refreshes the Parquet replica after data changed
+// Exports run at most once per configured interval; a change inside the interval
+// schedules a single export for when the interval has passed
+func (a *App) scheduleParquetExport(dataChanged bool) {
+	if !a.config().Database.EnableReadOnlyReplica || a.writable() != nil {
+		return
+	}
+	if !dataChanged {
+		logger.Debug("[PARQUET] No data changed, skipping export")
+		return
+	}
+
+	a.parquetExportMutex.Lock()
+	wait := a.config().Database.ParquetExportInterval - time.Since(a.parquetLastExport)
+	if a.parquetLastExport.IsZero() || wait <= 0 {
+		a.parquetExportMutex.Unlock()
+		a.StartParquetExport()
+		return
+	}
+	if a.parquetExportTimer == nil {
+		a.parquetExportTimer = time.AfterFunc(wait, func() {
+			a.parquetExportMutex.Lock()
+			a.parquetExportTimer = nil
+			a.parquetExportMutex.Unlock()
+			a.StartParquetExport()
+		})
+		logger.Info("[PARQUET] Export deferred", "wait", wait.Round(time.Second))
+	}
+	a.parquetExportMutex.Unlock()
+}
+
+// StartParquetExport triggers an async export of tables to Parquet format
+// Returns false if the replica is disabled or an export is already running
+func (a *App) StartParquetExport() bool {
+	// Skip if feature is disabled
+	if !a.config().Database.EnableReadOnlyReplica {
+		return false
+	}
+
+	// Skip if database is not initialized, or is the replica itself opened read-only
+	if a.writable() != nil {
+		return false
+	}
+
+	// Check if an export is already running
+	a.parquetExportMutex.Lock()
+	if a.parquetExportActive {
+		a.parquetExportMutex.Unlock()
+		logger.Info("[PARQUET] Export already in progress, skipping")
+		return false
+	}
+	a.parquetExportActive = true
+	a.parquetLastExport = time.Now()
+	a.parquetExportMutex.Unlock()
+
+	// Run export in goroutine to avoid blocking
+	started := a.background.Go(func() {
+		defer func() {
+			a.parquetExportMutex.Lock()
+			a.parquetExportActive = false
+			a.parquetExportMutex.Unlock()
+		}()
+
+		if err := exportReplica(a.ctx, a.db(), a.config().Database); err != nil {
+			logger.Error("[PARQUET] Export failed", logger.Err(err))
+		}
+	})
+	if !started {
+		a.parquetExportMutex.Lock()
+		a.parquetExportActive = false
+		a.parquetExportMutex.Unlock()
+	}
+	return started
+}
+
+// exportReplica exports changed tables to Parquet and refreshes the read-only database over them
+func exportReplica(ctx context.Context, database *db.Database, cfg config.DatabaseConfig) error {
+	logger.Info("[PARQUET] Starting export to Parquet files")
+	startTime := time.Now()
+
+	// Export all tables to Parquet
+	stats, err := database.ExportTablesToParquet(ctx, cfg.ParquetPath)
+	if err != nil {
+		return fmt.Errorf("export failed: %w", err)
+	}
+
+	// Log export statistics
+	totalRecords := 0
+	successCount := 0
+	skippedCount := 0
+	for _, stat := range stats {
+		if stat.Success {
+			successCount++
+			totalRecords += stat.RecordCount
+		}
+		if stat.Skipped {
+			skippedCount++
+		}
+	}
+
+	logger.Info("[PARQUET] Export completed", "tables", len(stats), "succeeded", successCount, "unchanged", skippedCount,
+		"records", totalRecords, "durationMs", time.Since(startTime).Milliseconds())
+
+	// Create or verify read-only database
+	if err := db.CreateReadOnlyDatabase(cfg.ReadOnlyPath, cfg.ParquetPath); err != nil {
+		return fmt.Errorf("failed to create read-only database: %w", err)
+	}
+
+	logger.Info("[PARQUET] Read-only replica ready", "path", cfg.ReadOnlyPath)
+	return nil
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"better-fabric-monitor/internal/api"
+	"better-fabric-monitor/internal/db"
+	"better-fabric-monitor/internal/logger"
+)
+
+// pollCheckInterval is how often the poller checks whether a scheduled sync is due
+const pollCheckInterval = 30 * time.Second
+
+// startPoller runs scheduled syncs in the background until shutdown
+func (a *App) startPoller() {
+	a.background.Go(func() {
+		ticker := time.NewTicker(pollCheckInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-a.ctx.Done():
+				return
+			case <-ticker.C:
+				now := time.Now().UTC()
+				a.pollIfDue(now)
+				a.refreshRunningJobsIfDue(now)
+			}
+		}
+	})
+}
+
+// pollIfDue starts a scheduled sync when one is due
+// Adaptive polling syncs only the workspaces whose next poll has passed; otherwise every workspace is
+// synced once per polling interval
+func (a *App) pollIfDue(now time.Time) {
+	if !a.config().Polling.Enabled || a.apiAvailable() != nil || !a.IsAuthenticated() {
+		return
+	}
+
+	if a.config().Polling.Adaptive {
+		next, err := a.db().GetNextPollTime()
+		if err != nil {
+			logger.Error("Poller: failed to read poll schedule", logger.Err(err))
+			return
+		}
+		if next != nil && next.After(now) {
+			return
+		}
+		a.startSync(true)
+		return
+	}
+
+	lastSync, err := a.db().GetLastSyncTime("job_instances")
+	if err != nil {
+		logger.Error("Poller: failed to read last sync time", logger.Err(err))
+		return
+	}
+	if lastSync != nil && now.Sub(*lastSync) < a.config().Polling.Interval {
+		return
+	}
+	a.startSync(false)
+}
+
+// refreshRunningJobsIfDue re-reads the status of the running jobs once the status interval has passed, between
+// syncs, and publishes jobs:running when they changed; a sync in progress refreshes them itself
+func (a *App) refreshRunningJobsIfDue(now time.Time) {
+	interval := a.config().Polling.StatusInterval
+	if !a.config().Polling.Enabled || interval <= 0 || now.Sub(a.statusRefreshedAt) < interval {
+		return
+	}
+	if a.apiAvailable() != nil || !a.IsAuthenticated() {
+		return
+	}
+	a.syncMutex.Lock()
+	syncing := a.syncActive
+	a.syncMutex.Unlock()
+	if syncing {
+		return
+	}
+	a.statusRefreshedAt = now
+
+	if !a.background.Begin() {
+		return
+	}
+	defer a.background.Done()
+	if err := a.ensureValidToken(); err != nil {
+		logger.Warn("Poller: authentication required to refresh running jobs", logger.Err(err))
+		return
+	}
+
+	onJobFailed := func(job api.Job) {
+		a.emitEvent(EventJobFailed, job)
+		a.notifyJobFailed(job)
+	}
+	if _, err := a.syncer().RefreshRunningJobs(a.ctx, a.fabricClient, onJobFailed); err != nil {
+		logger.Error("Poller: failed to refresh running jobs", logger.Err(err))
+	}
+	a.publishRunningJobs()
+}
+
+// GetPollSchedule returns when each workspace will next be polled and why, soonest first
+func (a *App) GetPollSchedule() api.PollScheduleResult {
+	if a.db() == nil {
+		return api.PollScheduleResult{Error: "Database not initialized"}
+	}
+
+	schedules, err := a.db().GetWorkspacePollSchedules()
+	if err != nil {
+		return api.PollScheduleResult{Error: fmt.Sprintf("Failed to get poll schedule: %v", err)}
+	}
+	if schedules == nil {
+		schedules = []db.WorkspacePollSchedule{}
+	}
+	return api.PollScheduleResult{Schedules: schedules}
+}
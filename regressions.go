@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"better-fabric-monitor/internal/api"
+	"better-fabric-monitor/internal/db"
+	"better-fabric-monitor/internal/logger"
+)
+
+// Defaults of GetDurationRegressions
+const (
+	defaultRegressionDays     = 1
+	defaultRegressionMinDelta = 50.0 // Percent slower than the baseline
+	regressionLimit           = 100
+)
+
+// GetDurationRegressions returns completed runs of the last days (the last day by default) that took at least
+// minDeltaPct percent longer than the median of their item's last successful runs, largest regression first
+// Runs not measured yet, e.g. from a headless sync or demo data, are measured first
+func (a *App) GetDurationRegressions(days int, minDeltaPct float64) api.DurationRegressionsResult {
+	if a.db() == nil {
+		return api.DurationRegressionsResult{Error: "Database not initialized"}
+	}
+	if !a.background.Begin() {
+		return api.DurationRegressionsResult{Error: errShuttingDown.Error()}
+	}
+	defer a.background.Done()
+	if days <= 0 {
+		days = defaultRegressionDays
+	}
+	if minDeltaPct <= 0 {
+		minDeltaPct = defaultRegressionMinDelta
+	}
+
+	if !a.db().ReadOnly() {
+		if _, err := a.db().MeasureDurationRegressions(time.Now().UTC()); err != nil {
+			logger.Warn("Failed to measure duration regressions", logger.Err(err))
+		}
+	}
+
+	regressions, err := a.db().GetDurationRegressions(days, minDeltaPct, regressionLimit)
+	if err != nil {
+		return api.DurationRegressionsResult{Error: fmt.Sprintf("Failed to get duration regressions: %v", err)}
+	}
+	if regressions == nil {
+		regressions = []db.DurationRegression{}
+	}
+	return api.DurationRegressionsResult{BaselineRuns: db.RegressionBaselineRuns, Regressions: regressions}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+
+	"better-fabric-monitor/internal/api"
+	"better-fabric-monitor/internal/db"
+)
+
+// GetRetryAnalytics surfaces the activity retries of pipeline runs started in the last days: how often activities
+// succeed only after a retry, which activities retry the most and the time each pipeline lost to retries
+func (a *App) GetRetryAnalytics(days int, workspaceIDs []string, itemTypes []string, itemNameSearch string) api.RetryAnalyticsResult {
+	if a.db() == nil {
+		return api.RetryAnalyticsResult{Error: "Database not initialized"}
+	}
+
+	if days <= 0 {
+		days = 30
+	}
+	workspaceIDs = a.analyticsWorkspaceIDs(workspaceIDs)
+
+	pipelines, err := a.db().GetPipelineRetryStats(days, workspaceIDs, itemTypes, itemNameSearch)
+	if err != nil {
+		return api.RetryAnalyticsResult{Error: fmt.Sprintf("Failed to get pipeline retries: %v", err)}
+	}
+	activities, err := a.db().GetActivityRetryStats(days, workspaceIDs, itemTypes, itemNameSearch)
+	if err != nil {
+		return api.RetryAnalyticsResult{Error: fmt.Sprintf("Failed to get activity retries: %v", err)}
+	}
+	if activities == nil {
+		activities = []db.ActivityRetryStats{}
+	}
+
+	// The summary counts every execution, while only pipelines that retried are listed
+	var summary api.RetrySummary
+	retried := []db.PipelineRetryStats{}
+	for _, p := range pipelines {
+		summary.Executions += p.Executions
+		summary.Retried += p.Retried
+		summary.SucceededAfterRetry += p.SucceededAfterRetry
+		summary.FailedAfterRetry += p.FailedAfterRetry
+		summary.Retries += p.Retries
+		summary.RetryTimeMs += p.RetryTimeMs
+		if p.Retries > 0 {
+			retried = append(retried, p)
+		}
+	}
+	if summary.Executions > 0 {
+		summary.SucceededAfterRetryRate = float64(summary.SucceededAfterRetry) * 100 / float64(summary.Executions)
+	}
+
+	return api.RetryAnalyticsResult{Summary: summary, Pipelines: retried, Activities: activities}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+
+	"better-fabric-monitor/internal/api"
+	"better-fabric-monitor/internal/db"
+	"better-fabric-monitor/internal/logger"
+	"better-fabric-monitor/internal/utils"
+)
+
+// maxRootCauseDepth bounds how many levels of child runs GetRootCause follows
+const maxRootCauseDepth = 10
+
+// failedSessionStates are the Livy session states of a child notebook that failed
+var failedSessionStates = map[string]bool{"Failed": true, "Dead": true, "Error": true}
+
+// GetRootCause traces a failed run down through its failed activities and the child pipelines and
+// notebooks they ran, and returns the chain to the deepest failure, so the failing leaf activity or
+// notebook is found without expanding every level
+// Child runs that haven't been synced end the chain at the activity that started them
+func (a *App) GetRootCause(jobID string) api.RootCauseResult {
+	if a.db() == nil {
+		return api.RootCauseResult{Error: "Database not initialized"}
+	}
+
+	job, err := a.db().GetJobInstanceWithActivities(jobID)
+	if err != nil {
+		return api.RootCauseResult{Error: fmt.Sprintf("Failed to get job: %v", err)}
+	}
+	if job.Status != "Failed" {
+		return api.RootCauseResult{Error: fmt.Sprintf("Job did not fail (status %s)", job.Status)}
+	}
+
+	return api.RootCauseResult{Chain: a.failureChain(job, make(map[string]bool), 0)}
+}
+
+// failureChain returns the step of job followed by the longest chain of failures below it
+func (a *App) failureChain(job *db.JobInstance, visited map[string]bool, depth int) []api.RootCauseStep {
+	visited[job.ID] = true
+	chain := []api.RootCauseStep{jobRootCauseStep(job)}
+	if depth >= maxRootCauseDepth {
+		return chain
+	}
+
+	var deepest []api.RootCauseStep
+	for _, activity := range job.ActivityRuns {
+		if activity.Status != "Failed" {
+			continue
+		}
+		step := jobRootCauseStep(job)
+		step.ActivityName, step.ActivityType, step.Status = activity.ActivityName, activity.ActivityType, activity.Status
+		step.ErrorCode, step.Message = activity.Error.ErrorCode, activity.Error.Message
+		branch := append([]api.RootCauseStep{step}, a.childFailureChain(db.ChildRunID(activity), visited, depth)...)
+		if len(branch) > len(deepest) {
+			deepest = branch
+		}
+	}
+
+	// Notebooks run from a notebook don't appear in activity runs; they are linked through their Livy sessions
+	children, err := a.db().GetChildNotebookSessions(job.ID)
+	if err != nil {
+		logger.Warn("Failed to get child notebook sessions", logger.JobID(job.ID), logger.Err(err))
+	}
+	for _, child := range children {
+		if !failedSessionStates[child.Status] || child.ChildJobInstanceID == nil {
+			continue
+		}
+		if branch := a.childFailureChain(*child.ChildJobInstanceID, visited, depth); len(branch) > len(deepest) {
+			deepest = branch
+		}
+	}
+	return append(chain, deepest...)
+}
+
+// childFailureChain returns the failure chain of a child run, or nothing when it isn't synced or was already visited
+func (a *App) childFailureChain(childJobID string, visited map[string]bool, depth int) []api.RootCauseStep {
+	if childJobID == "" || visited[childJobID] {
+		return nil
+	}
+	child, err := a.db().GetJobInstanceWithActivities(childJobID)
+	if err != nil {
+		return nil
+	}
+	return a.failureChain(child, visited, depth+1)
+}
+
+// jobRootCauseStep describes a run as a step of a failure chain
+func jobRootCauseStep(job *db.JobInstance) api.RootCauseStep {
+	step := api.RootCauseStep{
+		JobID:           job.ID,
+		ItemID:          job.ItemID,
+		ItemDisplayName: job.ItemID,
+		ItemType:        job.JobType,
+		WorkspaceID:     job.WorkspaceID,
+		Status:          job.Status,
+	}
+	if job.ItemDisplayName != nil {
+		step.ItemDisplayName = *job.ItemDisplayName
+	}
+	if job.ItemType != nil {
+		step.ItemType = *job.ItemType
+	}
+	if job.FailureReason != nil {
+		step.Message = *job.FailureReason
+	}
+	if job.FailureDetails != nil {
+		if detail := api.ParseFailureDetail(*job.FailureDetails); detail != nil {
+			step.ErrorCode = detail.ErrorCode
+		}
+	}
+	step.FabricURL = utils.GenerateFabricURL(job.WorkspaceID, job.ItemID, step.ItemType, job.ID, job.LivyID)
+	return step
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"better-fabric-monitor/internal/api"
+	"better-fabric-monitor/internal/fabric"
+	"better-fabric-monitor/internal/logger"
+)
+
+// GetRunningJobAges lists the queued and in-progress jobs by how far they have run past their item's usual
+// duration, with the elapsed time as a percentage of it, flagging jobs past the long-running and stuck settings
+// Jobs of items without enough history follow, longest running first
+// Each job carries its predicted completion time, refined for pipelines by the activities they finished so far
+func (a *App) GetRunningJobAges(workspaceIDs []string, itemTypes []string, itemNameSearch string) api.RunningJobAgesResult {
+	if a.db() == nil {
+		return api.RunningJobAgesResult{Error: "Database not initialized"}
+	}
+
+	ages, err := a.db().GetRunningJobAges(time.Now().UTC(), a.analyticsWorkspaceIDs(workspaceIDs), itemTypes, itemNameSearch)
+	if err != nil {
+		return api.RunningJobAgesResult{Error: fmt.Sprintf("Failed to get running jobs: %v", err)}
+	}
+
+	running := make([]api.Job, len(ages))
+	for i, age := range ages {
+		running[i] = api.JobFromDB(age.Job)
+	}
+	// Pipelines' finished activities refine their expected end when the API can be called
+	var client *fabric.Client
+	if a.apiAvailable() == nil && a.ensureValidToken() == nil {
+		client = a.fabricClient
+	}
+	a.syncer().ExpectRunningJobs(a.ctx, client, running)
+
+	cfg := a.config().Notifications
+	jobs := make([]api.RunningJobAge, 0, len(ages))
+	for i, age := range ages {
+		job := api.RunningJobAge{
+			Job:           running[i],
+			ElapsedMs:     age.ElapsedMs,
+			BaselineMs:    age.BaselineMs,
+			P90DurationMs: age.P90DurationMs,
+			BaselineRuns:  age.BaselineRuns,
+		}
+		elapsed := float64(age.ElapsedMs)
+		job.LongRunning = cfg.LongRunningThreshold > 0 && age.ElapsedMs >= cfg.LongRunningThreshold.Milliseconds()
+		if age.BaselineMs != nil && *age.BaselineMs > 0 {
+			baseline := *age.BaselineMs
+			percent := elapsed * 100 / baseline
+			job.PercentOfExpected = &percent
+			job.LongRunning = job.LongRunning || (cfg.LongRunningFactor > 0 && elapsed >= cfg.LongRunningFactor*baseline)
+			job.Stuck = cfg.StuckFactor > 0 && elapsed >= cfg.StuckFactor*baseline
+		}
+		jobs = append(jobs, job)
+	}
+
+	sort.SliceStable(jobs, func(i, j int) bool {
+		pi, pj := jobs[i].PercentOfExpected, jobs[j].PercentOfExpected
+		if (pi == nil) != (pj == nil) {
+			return pi != nil
+		}
+		if pi != nil && *pi != *pj {
+			return *pi > *pj
+		}
+		return jobs[i].ElapsedMs > jobs[j].ElapsedMs
+	})
+	return api.RunningJobAgesResult{Jobs: jobs}
+}
+
+// GetRunningJobs returns the queued and in-progress jobs with their item and workspace names and expected
+// completion, longest running first; jobs:running pushes the same list whenever it changes
+func (a *App) GetRunningJobs(workspaceIDs []string, itemTypes []string, itemNameSearch string) api.RunningJobsResult {
+	if a.db() == nil {
+		return api.RunningJobsResult{Error: "Database not initialized"}
+	}
+
+	// Pipelines' finished activities refine their expected end when the API can be called
+	var client *fabric.Client
+	if a.apiAvailable() == nil && a.ensureValidToken() == nil {
+		client = a.fabricClient
+	}
+	jobs, err := a.runningJobs(client, workspaceIDs, itemTypes, itemNameSearch)
+	if err != nil {
+		return api.RunningJobsResult{Error: fmt.Sprintf("Failed to get running jobs: %v", err)}
+	}
+	return api.RunningJobsResult{Jobs: jobs}
+}
+
+// runningJobs returns the running jobs matching the filters with their expected completion, refined from the
+// activities of pipelines when client is set
+func (a *App) runningJobs(client *fabric.Client, workspaceIDs []string, itemTypes []string, itemNameSearch string) ([]api.Job, error) {
+	instances, err := a.db().GetRunningJobs(a.analyticsWorkspaceIDs(workspaceIDs), itemTypes, itemNameSearch)
+	if err != nil {
+		return nil, err
+	}
+	jobs := make([]api.Job, 0, len(instances))
+	for _, job := range instances {
+		jobs = append(jobs, api.JobFromDB(job))
+	}
+	a.syncer().ExpectRunningJobs(a.ctx, client, jobs)
+	return jobs, nil
+}
+
+// publishRunningJobs emits jobs:running with every running job when the running jobs or their statuses changed
+// since they were last published
+func (a *App) publishRunningJobs() {
+	if a.db() == nil {
+		return
+	}
+	jobs, err := a.runningJobs(nil, nil, nil, "")
+	if err != nil {
+		logger.Warn("Failed to read running jobs", logger.Err(err))
+		return
+	}
+
+	keys := make([]string, len(jobs))
+	for i, job := range jobs {
+		keys[i] = job.ID + ":" + job.Status
+	}
+	signature := strings.Join(keys, ",")
+
+	a.runningMutex.Lock()
+	changed := signature != a.runningSignature
+	a.runningSignature = signature
+	a.runningMutex.Unlock()
+	if changed {
+		a.emitEvent(EventRunningJobs, api.RunningJobsResult{Jobs: jobs})
+	}
+}
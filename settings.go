@@ -0,0 +1,188 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"better-fabric-monitor/internal/config"
+	"better-fabric-monitor/internal/fabric"
+	"better-fabric-monitor/internal/logger"
+)
+
+// Supported UI themes
+var supportedThemes = []string{"dark", "light", "system"}
+
+// Views the app can land on after sign-in; the first is used when none is configured
+var supportedViews = []string{"jobs", "analytics", "logs"}
+
+// Limits enforced when saving settings
+const (
+	minPollingIntervalSeconds = 30
+	minRetentionDays          = 1
+	minAnalyticsDays          = 1
+	maxAnalyticsDays          = 365
+	defaultAnalyticsDays      = 7
+)
+
+// Settings is the user-editable subset of the configuration
+// Secrets, paths and auth settings are deliberately left out
+type Settings struct {
+	Theme          string                 `json:"theme"`
+	Polling        PollingSettings        `json:"polling"`
+	RetentionDays  int                    `json:"retentionDays"`
+	Notifications  NotificationSettings   `json:"notifications"`
+	WorkspaceScope WorkspaceScopeSettings `json:"workspaceScope"`
+	Defaults       UIDefaults             `json:"defaults"`
+}
+
+// UIDefaults is what the app shows until the user chooses otherwise
+type UIDefaults struct {
+	View          string   `json:"view"`          // Landing view after sign-in: jobs, analytics or logs
+	AnalyticsDays int      `json:"analyticsDays"` // Window of analytics in days
+	WorkspaceIDs  []string `json:"workspaceIds"`  // Workspaces selected in the filters (empty selects all)
+}
+
+// PollingSettings controls automatic background refresh
+type PollingSettings struct {
+	Enabled         bool `json:"enabled"`
+	IntervalSeconds int  `json:"intervalSeconds"`
+}
+
+// NotificationSettings controls which job events raise notifications
+type NotificationSettings struct {
+	Enabled                     bool   `json:"enabled"`
+	OnFailure                   bool   `json:"onFailure"`
+	OnLongRunning               bool   `json:"onLongRunning"`
+	SoundEnabled                bool   `json:"soundEnabled"`
+	Sound                       string `json:"sound"` // Sound of alerts without their own, e.g. chime
+	LongRunningThresholdMinutes int    `json:"longRunningThresholdMinutes"`
+}
+
+// GetSettings returns the current user-editable settings
+func (a *App) GetSettings() Settings {
+	cfg := a.config()
+	return Settings{
+		Theme: cfg.UI.Theme,
+		Polling: PollingSettings{
+			Enabled:         cfg.Polling.Enabled,
+			IntervalSeconds: int(cfg.Polling.Interval / time.Second),
+		},
+		RetentionDays: cfg.Database.RetentionDays,
+		Notifications: NotificationSettings{
+			Enabled:                     cfg.Notifications.Enabled,
+			OnFailure:                   cfg.Notifications.OnFailure,
+			OnLongRunning:               cfg.Notifications.OnLongRunning,
+			SoundEnabled:                cfg.Notifications.SoundEnabled,
+			Sound:                       cfg.Notifications.Sounds.Default,
+			LongRunningThresholdMinutes: int(cfg.Notifications.LongRunningThreshold / time.Minute),
+		},
+		WorkspaceScope: a.GetWorkspaceScope(),
+		Defaults: UIDefaults{
+			View:          landingView(cfg.UI.DefaultView),
+			AnalyticsDays: a.defaultAnalyticsDays(),
+			WorkspaceIDs:  append([]string{}, cfg.UI.DefaultWorkspaceIDs...),
+		},
+	}
+}
+
+// landingView returns view when the app supports landing on it, otherwise the first supported view
+// Older configs name the jobs view dashboard
+func landingView(view string) string {
+	if slices.Contains(supportedViews, view) {
+		return view
+	}
+	return supportedViews[0]
+}
+
+// defaultAnalyticsDays returns the configured analytics window, or defaultAnalyticsDays when it is out of range
+func (a *App) defaultAnalyticsDays() int {
+	days := a.config().UI.AnalyticsDays
+	if days < minAnalyticsDays || days > maxAnalyticsDays {
+		return defaultAnalyticsDays
+	}
+	return days
+}
+
+// SaveSettings validates and persists settings, then applies them to the running app
+// The in-memory config is updated immediately, so the next sync uses the new workspace scope,
+// and a settings:changed event lets the frontend pick up theme and polling changes without a restart
+func (a *App) SaveSettings(settings Settings) error {
+	if err := validateSettings(settings); err != nil {
+		return err
+	}
+
+	scope := fabric.NewWorkspaceScope(settings.WorkspaceScope.WorkspaceIDs,
+		settings.WorkspaceScope.IncludeWorkspaces, settings.WorkspaceScope.ExcludeWorkspaces)
+
+	// The running app only picks up the settings once they are on disk, keeping the two consistent
+	err := a.updateConfig(func(cfg *config.Config) error {
+		cfg.UI.Theme = settings.Theme
+		cfg.Polling.Enabled = settings.Polling.Enabled
+		cfg.Polling.Interval = time.Duration(settings.Polling.IntervalSeconds) * time.Second
+		cfg.Database.RetentionDays = settings.RetentionDays
+		cfg.Notifications.Enabled = settings.Notifications.Enabled
+		cfg.Notifications.OnFailure = settings.Notifications.OnFailure
+		cfg.Notifications.OnLongRunning = settings.Notifications.OnLongRunning
+		cfg.Notifications.SoundEnabled = settings.Notifications.SoundEnabled
+		cfg.Notifications.Sounds.Default = settings.Notifications.Sound
+		cfg.Notifications.LongRunningThreshold = time.Duration(settings.Notifications.LongRunningThresholdMinutes) * time.Minute
+		cfg.Fabric.WorkspaceIDs = scope.IDs
+		cfg.Fabric.IncludeWorkspaces = scope.Include
+		cfg.Fabric.ExcludeWorkspaces = scope.Exclude
+		cfg.Fabric.IncludePersonal = settings.WorkspaceScope.IncludePersonal
+		cfg.UI.DefaultView = settings.Defaults.View
+		cfg.UI.AnalyticsDays = settings.Defaults.AnalyticsDays
+		// Blank IDs are dropped the same way as in the workspace scope
+		cfg.UI.DefaultWorkspaceIDs = fabric.NewWorkspaceScope(settings.Defaults.WorkspaceIDs, nil, nil).IDs
+		return cfg.Save()
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save settings: %w", err)
+	}
+
+	cfg := a.config()
+	logger.Info("Settings updated", "theme", cfg.UI.Theme, "polling", cfg.Polling.Enabled,
+		"pollIntervalSeconds", settings.Polling.IntervalSeconds, "retentionDays", cfg.Database.RetentionDays)
+	a.emitEvent(EventSettingsChanged, a.GetSettings())
+	return nil
+}
+
+// validateSettings rejects values the app can't work with
+func validateSettings(settings Settings) error {
+	validTheme := false
+	for _, theme := range supportedThemes {
+		if settings.Theme == theme {
+			validTheme = true
+			break
+		}
+	}
+	if !validTheme {
+		return fmt.Errorf("unsupported theme: %s", settings.Theme)
+	}
+
+	if settings.Polling.IntervalSeconds < minPollingIntervalSeconds {
+		return fmt.Errorf("polling interval must be at least %d seconds", minPollingIntervalSeconds)
+	}
+	if settings.RetentionDays < minRetentionDays {
+		return fmt.Errorf("retention must be at least %d day", minRetentionDays)
+	}
+	if settings.Notifications.Sound != soundOff && !validSound(settings.Notifications.Sound) {
+		return fmt.Errorf("unsupported sound: %s", settings.Notifications.Sound)
+	}
+	if settings.Notifications.LongRunningThresholdMinutes <= 0 {
+		return fmt.Errorf("long-running threshold must be positive")
+	}
+
+	if !slices.Contains(supportedViews, settings.Defaults.View) {
+		return fmt.Errorf("unsupported default view: %s", settings.Defaults.View)
+	}
+	if settings.Defaults.AnalyticsDays < minAnalyticsDays || settings.Defaults.AnalyticsDays > maxAnalyticsDays {
+		return fmt.Errorf("default analytics window must be between %d and %d days", minAnalyticsDays, maxAnalyticsDays)
+	}
+
+	if err := fabric.ValidatePatterns(settings.WorkspaceScope.IncludeWorkspaces); err != nil {
+		return err
+	}
+	return fabric.ValidatePatterns(settings.WorkspaceScope.ExcludeWorkspaces)
+}
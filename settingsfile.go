@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+
+	"better-fabric-monitor/internal/api"
+	"better-fabric-monitor/internal/config"
+	"better-fabric-monitor/internal/logger"
+)
+
+// settingsFileFilter limits the settings file dialogs to YAML files
+var settingsFileFilter = []runtime.FileFilter{{DisplayName: "Settings files (*.yaml)", Pattern: "*.yaml;*.yml"}}
+
+// ExportSettings writes the settings to path as YAML, without secrets, webhook URLs or tracing headers, so they can
+// be imported on another machine; an empty path asks where to save them
+func (a *App) ExportSettings(path string) api.SettingsExportResult {
+	if path == "" {
+		if a.ctx == nil {
+			return api.SettingsExportResult{Error: "No path given"}
+		}
+		chosen, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+			Title:                "Export settings",
+			DefaultFilename:      "fabric-monitor-settings.yaml",
+			Filters:              settingsFileFilter,
+			CanCreateDirectories: true,
+		})
+		if err != nil {
+			return api.SettingsExportResult{Error: fmt.Sprintf("Failed to choose export location: %v", err)}
+		}
+		if chosen == "" {
+			return api.SettingsExportResult{Cancelled: true}
+		}
+		path = chosen
+	}
+	// The file type is taken from the extension
+	if ext := filepath.Ext(path); ext != ".yaml" && ext != ".yml" {
+		path += ".yaml"
+	}
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return api.SettingsExportResult{Error: fmt.Sprintf("Failed to resolve export path: %v", err)}
+	}
+
+	if err := a.config().Export(path); err != nil {
+		logger.Error("Failed to export settings", logger.Err(err))
+		return api.SettingsExportResult{Error: fmt.Sprintf("Failed to export settings: %v", err)}
+	}
+	logger.Info("Settings exported", "path", path)
+	return api.SettingsExportResult{Path: path}
+}
+
+// ImportSettings applies the settings exported to path over the current ones and saves them to config.yaml, keeping
+// this machine's secrets; an empty path asks which file to import
+// Settings that are safe to change at runtime apply right away, the rest are reported as needing a restart
+func (a *App) ImportSettings(path string) api.SettingsImportResult {
+	if path == "" {
+		if a.ctx == nil {
+			return api.SettingsImportResult{Error: "No path given"}
+		}
+		chosen, err := runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
+			Title:   "Import settings",
+			Filters: settingsFileFilter,
+		})
+		if err != nil {
+			return api.SettingsImportResult{Error: fmt.Sprintf("Failed to choose settings file: %v", err)}
+		}
+		if chosen == "" {
+			return api.SettingsImportResult{Cancelled: true}
+		}
+		path = chosen
+	}
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return api.SettingsImportResult{Error: fmt.Sprintf("Failed to resolve settings file: %v", err)}
+	}
+
+	imported, err := config.Import(path, a.config())
+	if err != nil {
+		return api.SettingsImportResult{Error: err.Error()}
+	}
+	if err := imported.Save(); err != nil {
+		return api.SettingsImportResult{Error: fmt.Sprintf("Failed to save settings: %v", err)}
+	}
+
+	reload := a.reloadConfig()
+	if reload.Error != "" {
+		return api.SettingsImportResult{Error: fmt.Sprintf("Failed to apply settings: %s", reload.Error)}
+	}
+	logger.Info("Settings imported", "path", path, "applied", len(reload.Applied), "notApplied", len(reload.Rejected))
+	return api.SettingsImportResult{Path: path, Applied: reload.Applied, Rejected: reload.Rejected}
+}
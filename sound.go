@@ -0,0 +1,70 @@
+package main
+
+import (
+	"time"
+
+	"better-fabric-monitor/internal/api"
+	"better-fabric-monitor/internal/config"
+	"better-fabric-monitor/internal/logger"
+	"better-fabric-monitor/internal/notify"
+)
+
+// Sounds the frontend can play for an alert
+var supportedSounds = []string{"chime", "beep", "alarm"}
+
+// soundOff silences an alert type
+const soundOff = "off"
+
+// alertSound returns the sound configured for eventType, or "" when it plays none
+func alertSound(cfg config.SoundConfig, eventType string) string {
+	var sound string
+	switch eventType {
+	case notify.EventJobFailed:
+		sound = cfg.Failure
+	case notify.EventJobLongRunning:
+		sound = cfg.LongRunning
+	case notify.EventJobStuck:
+		sound = cfg.Stuck
+	default:
+		return ""
+	}
+	if sound == "" {
+		sound = cfg.Default
+	}
+	if !validSound(sound) {
+		return ""
+	}
+	return sound
+}
+
+// validSound reports whether the frontend knows how to play sound
+func validSound(sound string) bool {
+	for _, s := range supportedSounds {
+		if s == sound {
+			return true
+		}
+	}
+	return false
+}
+
+// playAlertSound asks the frontend to play the sound of a critical alert about job,
+// so it is heard while the app is minimized
+// Nothing plays while sounds are disabled or the job's item or workspace is muted
+func (a *App) playAlertSound(eventType string, job api.Job) {
+	if !a.config().Notifications.SoundEnabled {
+		return
+	}
+	sound := alertSound(a.config().Notifications.Sounds, eventType)
+	if sound == "" {
+		return
+	}
+	if a.db() != nil {
+		muted, err := a.db().IsMuted(job.WorkspaceID, job.ItemID, time.Now().UTC())
+		if err != nil {
+			logger.Warn("Failed to check notification mutes", logger.Err(err))
+		} else if muted {
+			return
+		}
+	}
+	a.emitEvent(EventPlaySound, map[string]string{"sound": sound, "event": eventType})
+}
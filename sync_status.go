@@ -0,0 +1,199 @@
+package main
+
+import (
+	"sync"
+	"time"
+
+	"better-fabric-monitor/internal/api"
+	syncer "better-fabric-monitor/internal/sync"
+)
+
+// Sync phases reported through GetSyncStatus and sync:progress events
+// The working phases are set by the syncer; the rest describe the tracker's own state
+const (
+	SyncPhaseIdle       = "idle"
+	SyncPhaseDiscovery  = syncer.PhaseDiscovery
+	SyncPhaseJobs       = syncer.PhaseJobs
+	SyncPhaseLivy       = syncer.PhaseLivy
+	SyncPhaseEnrichment = syncer.PhaseEnrichment
+	SyncPhaseCompleted  = "completed"
+	SyncPhaseFailed     = "failed"
+	SyncPhaseCancelled  = "cancelled"
+)
+
+// progressEmitInterval throttles sync:progress events during the busy jobs phase
+const progressEmitInterval = 500 * time.Millisecond
+
+// SyncStatus is a snapshot of the current (or last) sync operation
+type SyncStatus struct {
+	Running             bool   `json:"running"`
+	Phase               string `json:"phase"`
+	WorkspacesCompleted int    `json:"workspacesCompleted"`
+	WorkspacesTotal     int    `json:"workspacesTotal"`
+	ItemsProcessed      int    `json:"itemsProcessed"`
+	JobsFetched         int    `json:"jobsFetched"`
+	JobsSaved           int    `json:"jobsSaved"`
+	StartedAt           string `json:"startedAt,omitempty"`
+	ElapsedMs           int64  `json:"elapsedMs"`
+	Error               string `json:"error,omitempty"`
+	SyncRunID           string `json:"syncRunId,omitempty"` // Tags the log records of the sync
+	// Warnings lists workspaces and items that failed while the rest of the sync went ahead
+	Warnings []api.SyncWarning `json:"warnings,omitempty"`
+}
+
+// syncTracker records sync progress and pushes it to the frontend
+// It implements syncer.Reporter so the syncer and API client can report phases and per-item progress
+type syncTracker struct {
+	mu        sync.Mutex
+	status    SyncStatus
+	startedAt time.Time
+	endedAt   time.Time
+	lastEmit  time.Time
+	emit      func(SyncStatus)
+}
+
+// newSyncTracker creates a tracker that publishes snapshots through emit
+func newSyncTracker(emit func(SyncStatus)) *syncTracker {
+	return &syncTracker{
+		status: SyncStatus{Phase: SyncPhaseIdle},
+		emit:   emit,
+	}
+}
+
+// Begin resets the tracker for a new sync
+func (t *syncTracker) Begin() {
+	t.mu.Lock()
+	t.startedAt = time.Now()
+	t.endedAt = time.Time{}
+	t.status = SyncStatus{
+		Running:   true,
+		Phase:     SyncPhaseDiscovery,
+		StartedAt: t.startedAt.Format(time.RFC3339),
+	}
+	t.mu.Unlock()
+	t.publish(true)
+}
+
+// SetSyncRunID records the ID tagging the sync's log records, so they can be found from its status
+func (t *syncTracker) SetSyncRunID(id string) {
+	t.mu.Lock()
+	t.status.SyncRunID = id
+	t.mu.Unlock()
+	t.publish(true)
+}
+
+// SetPhase moves the sync to a new phase
+func (t *syncTracker) SetPhase(phase string) {
+	t.mu.Lock()
+	t.status.Phase = phase
+	t.mu.Unlock()
+	t.publish(true)
+}
+
+// SetWorkspacesTotal records how many workspaces the sync will process
+func (t *syncTracker) SetWorkspacesTotal(total int) {
+	t.mu.Lock()
+	t.status.WorkspacesTotal = total
+	t.mu.Unlock()
+	t.publish(true)
+}
+
+// ItemProcessed is called by the Fabric client after an item's job instances were fetched
+func (t *syncTracker) ItemProcessed(workspaceName, itemName string, jobs int) {
+	t.mu.Lock()
+	t.status.ItemsProcessed++
+	t.status.JobsFetched += jobs
+	t.mu.Unlock()
+	t.publish(false)
+}
+
+// JobsSaved is called by the syncer once a workspace's jobs were written to the database
+func (t *syncTracker) JobsSaved(workspaceName string, jobs int) {
+	t.mu.Lock()
+	t.status.JobsSaved += jobs
+	t.mu.Unlock()
+	t.publish(false)
+}
+
+// ItemFailed is called by the Fabric client when an item's job instances could not be fetched
+// The syncer collects these as warnings, which are attached with SetWarnings when the sync ends
+func (t *syncTracker) ItemFailed(workspaceName, itemName string, err error) {}
+
+// SetWarnings attaches the partial failures of the sync to its status
+func (t *syncTracker) SetWarnings(warnings []api.SyncWarning) {
+	t.mu.Lock()
+	t.status.Warnings = warnings
+	t.mu.Unlock()
+}
+
+// WorkspaceCompleted is called by the Fabric client once all items of a workspace are done
+func (t *syncTracker) WorkspaceCompleted(workspaceName string, err error) {
+	t.mu.Lock()
+	t.status.WorkspacesCompleted++
+	t.mu.Unlock()
+	t.publish(false)
+}
+
+// Finish marks the sync as done, recording the error if it failed
+func (t *syncTracker) Finish(err error) {
+	t.mu.Lock()
+	t.status.Running = false
+	t.endedAt = time.Now()
+	if err != nil {
+		t.status.Phase = SyncPhaseFailed
+		t.status.Error = err.Error()
+	} else {
+		t.status.Phase = SyncPhaseCompleted
+	}
+	t.mu.Unlock()
+	t.publish(true)
+}
+
+// Cancel marks the sync as stopped on user request
+func (t *syncTracker) Cancel() {
+	t.mu.Lock()
+	t.status.Running = false
+	t.status.Phase = SyncPhaseCancelled
+	t.endedAt = time.Now()
+	t.mu.Unlock()
+	t.publish(true)
+}
+
+// Snapshot returns a copy of the current status with elapsed time filled in
+func (t *syncTracker) Snapshot() SyncStatus {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.snapshotLocked()
+}
+
+// snapshotLocked builds a snapshot; the caller must hold t.mu
+func (t *syncTracker) snapshotLocked() SyncStatus {
+	status := t.status
+	switch {
+	case t.startedAt.IsZero():
+		status.ElapsedMs = 0
+	case t.endedAt.IsZero():
+		status.ElapsedMs = time.Since(t.startedAt).Milliseconds()
+	default:
+		status.ElapsedMs = t.endedAt.Sub(t.startedAt).Milliseconds()
+	}
+	return status
+}
+
+// publish emits the current snapshot, throttled unless force is set
+func (t *syncTracker) publish(force bool) {
+	if t.emit == nil {
+		return
+	}
+
+	t.mu.Lock()
+	if !force && time.Since(t.lastEmit) < progressEmitInterval {
+		t.mu.Unlock()
+		return
+	}
+	t.lastEmit = time.Now()
+	status := t.snapshotLocked()
+	t.mu.Unlock()
+
+	t.emit(status)
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+
+	"better-fabric-monitor/internal/api"
+	"better-fabric-monitor/internal/db"
+)
+
+// Defaults of GetTop
+const (
+	defaultTopDays = 7
+	defaultTopN    = 10
+	maxTopN        = 100
+)
+
+// GetTop returns the n busiest items or workspaces (scope) of the last days by run count, total runtime or
+// failure count (metric), ranked in DuckDB so the overview and digests don't aggregate every run themselves
+// scope defaults to items and metric to runs
+func (a *App) GetTop(scope, metric string, n, days int, workspaceIDs []string, itemTypes []string, itemNameSearch string) api.TopResult {
+	if a.db() == nil {
+		return api.TopResult{Error: "Database not initialized"}
+	}
+
+	if scope == "" {
+		scope = db.TopItems
+	}
+	if metric == "" {
+		metric = db.TopByRuns
+	}
+	if n <= 0 {
+		n = defaultTopN
+	}
+	n = min(n, maxTopN)
+	if days <= 0 {
+		days = defaultTopDays
+	}
+
+	entries, err := a.db().GetTop(scope, metric, days, n, a.analyticsWorkspaceIDs(workspaceIDs), itemTypes, itemNameSearch)
+	if err != nil {
+		return api.TopResult{Error: fmt.Sprintf("Failed to get top %s: %v", scope, err)}
+	}
+	if entries == nil {
+		entries = []db.TopEntry{}
+	}
+	return api.TopResult{Days: days, Scope: scope, Metric: metric, Entries: entries}
+}
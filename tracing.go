@@ -0,0 +1,43 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"better-fabric-monitor/internal/config"
+	"better-fabric-monitor/internal/logger"
+	"better-fabric-monitor/internal/tracing"
+)
+
+// tracingShutdownTimeout bounds how long exiting waits for buffered spans to be exported
+const tracingShutdownTimeout = 5 * time.Second
+
+// startTracing starts exporting sync spans to the OTLP endpoint configured by cfg
+// Failures are logged and otherwise ignored, since syncs work the same without tracing
+func startTracing(cfg config.TracingConfig, version string) {
+	if !cfg.Enabled {
+		return
+	}
+	err := tracing.Init(context.Background(), tracing.Options{
+		Endpoint:       cfg.Endpoint,
+		Insecure:       cfg.Insecure,
+		Headers:        cfg.Headers,
+		SampleRatio:    cfg.SampleRatio,
+		ServiceName:    cfg.ServiceName,
+		ServiceVersion: version,
+	})
+	if err != nil {
+		logger.Warn("Tracing disabled", logger.Err(err))
+		return
+	}
+	logger.Info("Exporting traces", "endpoint", cfg.Endpoint, "sampleRatio", cfg.SampleRatio)
+}
+
+// stopTracing exports the spans still buffered, giving up after tracingShutdownTimeout
+func stopTracing() {
+	ctx, cancel := context.WithTimeout(context.Background(), tracingShutdownTimeout)
+	defer cancel()
+	if err := tracing.Shutdown(ctx); err != nil {
+		logger.Warn("Failed to flush traces", logger.Err(err))
+	}
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+
+	"better-fabric-monitor/internal/api"
+	"better-fabric-monitor/internal/db"
+	"better-fabric-monitor/internal/logger"
+	"better-fabric-monitor/internal/spreadsheet"
+)
+
+// viewExportHeader names the columns of a view export
+var viewExportHeader = []string{
+	"Job ID", "Item", "Item Type", "Workspace", "Job Type", "Invoked By", "Status",
+	"Start Time", "End Time", "Duration (s)", "Failure Reason", "Fabric URL",
+}
+
+// ExportView writes the cached jobs matching filter, in the order of sort, to a CSV or XLSX file at path,
+// with their item and workspace names, local start and end times, durations, failure reasons and Fabric links
+// format is csv or xlsx; when empty it follows the extension of path, csv by default
+// An empty path asks where to save the file
+func (a *App) ExportView(filter db.JobSearch, sort db.JobSort, format, path string) api.ViewExportResult {
+	if a.db() == nil {
+		return api.ViewExportResult{Error: "Database not initialized"}
+	}
+	if sort.Field != "" && !db.ValidJobSortField(sort.Field) {
+		return api.ViewExportResult{Error: fmt.Sprintf("Unsupported sort field: %s", sort.Field)}
+	}
+	format = strings.ToLower(strings.TrimSpace(format))
+	if format == "" {
+		format = viewExportFormat(path)
+	}
+	if format != spreadsheet.FormatCSV && format != spreadsheet.FormatXLSX {
+		return api.ViewExportResult{Error: fmt.Sprintf("Unsupported export format: %s (use csv or xlsx)", format)}
+	}
+
+	if path == "" {
+		if a.ctx == nil {
+			return api.ViewExportResult{Error: "No path given"}
+		}
+		filter := runtime.FileFilter{DisplayName: "CSV files (*.csv)", Pattern: "*.csv"}
+		if format == spreadsheet.FormatXLSX {
+			filter = runtime.FileFilter{DisplayName: "Excel workbooks (*.xlsx)", Pattern: "*.xlsx"}
+		}
+		chosen, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+			Title:                "Export jobs",
+			DefaultFilename:      fmt.Sprintf("fabric-jobs-%s.%s", time.Now().Format("20060102-150405"), format),
+			Filters:              []runtime.FileFilter{filter},
+			CanCreateDirectories: true,
+		})
+		if err != nil {
+			return api.ViewExportResult{Error: fmt.Sprintf("Failed to choose export location: %v", err)}
+		}
+		if chosen == "" {
+			return api.ViewExportResult{Cancelled: true}
+		}
+		path = chosen
+	}
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return api.ViewExportResult{Error: fmt.Sprintf("Failed to resolve export path: %v", err)}
+	}
+
+	table, err := a.viewExportTable(filter, sort)
+	if err != nil {
+		return api.ViewExportResult{Error: fmt.Sprintf("Failed to get jobs: %v", err)}
+	}
+	if format == spreadsheet.FormatXLSX && len(table.Rows) >= spreadsheet.MaxXLSXRows {
+		return api.ViewExportResult{Error: fmt.Sprintf("%d jobs do not fit in an Excel worksheet; narrow the filter or export to CSV", len(table.Rows))}
+	}
+
+	if err := writeViewExport(path, format, table); err != nil {
+		os.Remove(path)
+		logger.Error("Failed to export jobs", logger.Err(err))
+		return api.ViewExportResult{Error: fmt.Sprintf("Failed to export jobs: %v", err)}
+	}
+
+	logger.Info("Jobs exported", "path", path, "format", format, "rows", len(table.Rows))
+	return api.ViewExportResult{Path: path, Format: format, Rows: len(table.Rows)}
+}
+
+// viewExportFormat returns the export format the extension of path asks for, csv unless it is .xlsx
+func viewExportFormat(path string) string {
+	if strings.EqualFold(filepath.Ext(path), ".xlsx") {
+		return spreadsheet.FormatXLSX
+	}
+	return spreadsheet.FormatCSV
+}
+
+// viewExportTable reads every job matching filter, a page at a time, into the rows of a view export
+func (a *App) viewExportTable(filter db.JobSearch, sort db.JobSort) (spreadsheet.Table, error) {
+	table := spreadsheet.Table{Header: viewExportHeader}
+	for offset := 0; ; offset += maxJobsPageSize {
+		instances, total, err := a.db().GetJobPage(filter, sort, maxJobsPageSize, offset)
+		if err != nil {
+			return table, err
+		}
+		if table.Rows == nil {
+			table.Rows = make([][]any, 0, total)
+		}
+		for _, instance := range instances {
+			table.Rows = append(table.Rows, viewExportRow(instance))
+		}
+		if len(instances) < maxJobsPageSize || offset+len(instances) >= total {
+			return table, nil
+		}
+	}
+}
+
+// viewExportRow returns the cells of job in the order of viewExportHeader
+// Times are local, as shown in the app; the duration is a number of seconds so it can be summed and sorted
+func viewExportRow(instance db.JobInstance) []any {
+	job := api.JobFromDB(instance)
+	var endTime, duration any
+	if instance.EndTime != nil {
+		endTime = instance.EndTime.Local().Format(time.DateTime)
+	}
+	if instance.DurationMs != nil {
+		duration = float64(*instance.DurationMs) / 1000
+	}
+	return []any{
+		job.ID,
+		job.ItemDisplayName,
+		job.ItemType,
+		job.WorkspaceName,
+		job.JobType,
+		job.InvokerType,
+		job.Status,
+		instance.StartTime.Local().Format(time.DateTime),
+		endTime,
+		duration,
+		job.FailureReason,
+		job.FabricURL,
+	}
+}
+
+// writeViewExport writes table to a new file at path in format
+func writeViewExport(path, format string, table spreadsheet.Table) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if err := spreadsheet.Write(file, format, "Jobs", table); err != nil {
+		return err
+	}
+	return file.Close()
+}
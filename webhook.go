@@ -0,0 +1,321 @@
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"better-fabric-monitor/internal/db"
+	"better-fabric-monitor/internal/fabric"
+	"better-fabric-monitor/internal/logger"
+	"better-fabric-monitor/internal/secrets"
+)
+
+const (
+	// webhookPath is where Activator or eventstream webhooks post job events
+	webhookPath = "/events"
+	// webhookSecretHeader carries the shared secret configured as webhook.secret
+	webhookSecretHeader = "X-Webhook-Secret"
+	// maxWebhookBodyBytes caps the size of a posted event batch
+	maxWebhookBodyBytes = 1 << 20
+	// maxWebhookSyncs caps the targeted syncs running at once; the rest of a batch waits for a slot
+	maxWebhookSyncs = 4
+)
+
+// webhookListener accepts Fabric job events over HTTP and syncs the affected items right away
+type webhookListener struct {
+	app      *App
+	secret   string
+	server   *http.Server
+	mu       sync.Mutex
+	inFlight map[string]bool // Items with a targeted sync queued or running, keyed by workspace and item ID
+	slots    chan struct{}   // Held by each running targeted sync
+}
+
+// webhookEvent is a job event posted by a webhook
+// IDs are read from the top level or, for CloudEvents such as Fabric job events, from data
+type webhookEvent struct {
+	Type        string        `json:"type"`
+	WorkspaceID string        `json:"workspaceId"`
+	ItemID      string        `json:"itemId"`
+	Data        *webhookEvent `json:"data"`
+}
+
+// itemKey returns the workspace and item the event refers to
+func (e webhookEvent) itemKey() (workspaceID, itemID string) {
+	workspaceID, itemID = e.WorkspaceID, e.ItemID
+	if e.Data != nil {
+		if workspaceID == "" {
+			workspaceID = e.Data.WorkspaceID
+		}
+		if itemID == "" {
+			itemID = e.Data.ItemID
+		}
+	}
+	return workspaceID, itemID
+}
+
+// startWebhookListener starts the job event listener when it is enabled; it stops on shutdown
+func (a *App) startWebhookListener() {
+	cfg := a.config().Webhook
+	if !cfg.Enabled {
+		return
+	}
+	if err := a.apiAvailable(); err != nil {
+		logger.Warn("Webhook listener not started", logger.Err(err))
+		return
+	}
+
+	// Without a secret anyone who can reach the port could trigger API calls, so stay on loopback
+	if cfg.Secret == "" && !isLoopbackAddress(cfg.Address) {
+		logger.Warn("Webhook listener not started: webhook.secret is required", "address", cfg.Address)
+		return
+	}
+
+	secret, err := secrets.Resolve(cfg.Secret)
+	if err != nil {
+		logger.Warn("Webhook listener not started", "setting", "webhook.secret", logger.Err(err))
+		return
+	}
+
+	listener, err := net.Listen("tcp", cfg.Address)
+	if err != nil {
+		logger.Error("Webhook listener failed to start", logger.Err(err))
+		return
+	}
+
+	w := &webhookListener{
+		app:      a,
+		secret:   secret,
+		inFlight: make(map[string]bool),
+		slots:    make(chan struct{}, maxWebhookSyncs),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc(webhookPath, w.handleEvents)
+	w.server = &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		if err := w.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("Webhook listener stopped", logger.Err(err))
+		}
+	}()
+	go func() {
+		<-a.ctx.Done()
+		w.server.Close()
+	}()
+
+	logger.Info("Webhook listener accepting job events", "url", fmt.Sprintf("http://%s%s", listener.Addr(), webhookPath))
+}
+
+// handleEvents accepts a single event or a batch and starts a targeted sync for each item in scope
+func (w *webhookListener) handleEvents(rw http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		rw.Header().Set("Allow", http.MethodPost)
+		http.Error(rw, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	// A web page can only post a JSON content type after a CORS preflight, which the listener never answers
+	if !isJSONContentType(r.Header.Get("Content-Type")) {
+		http.Error(rw, "content type must be application/json", http.StatusUnsupportedMediaType)
+		return
+	}
+	if w.secret != "" && subtle.ConstantTimeCompare([]byte(r.Header.Get(webhookSecretHeader)), []byte(w.secret)) != 1 {
+		http.Error(rw, "invalid secret", http.StatusUnauthorized)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookBodyBytes))
+	if err != nil {
+		http.Error(rw, "failed to read body", http.StatusBadRequest)
+		return
+	}
+	events, err := parseWebhookEvents(body)
+	if err != nil {
+		http.Error(rw, fmt.Sprintf("invalid event payload: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	if !hasItemEvent(events) {
+		http.Error(rw, "no event with workspaceId and itemId", http.StatusBadRequest)
+		return
+	}
+
+	// Items out of scope are skipped, as are items already being synced; that sync will pick up the new run
+	scope := newWebhookScope(w.app)
+	accepted := 0
+	for _, event := range events {
+		workspaceID, itemID := event.itemKey()
+		if workspaceID == "" || itemID == "" {
+			continue
+		}
+		if !scope.allows(workspaceID, itemID) {
+			logger.Debug("Webhook: skipping item out of scope", "eventType", event.Type, logger.WorkspaceID(workspaceID), logger.ItemID(itemID))
+			continue
+		}
+		if w.syncItem(workspaceID, itemID, event.Type) {
+			accepted++
+		}
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(rw).Encode(map[string]int{"accepted": accepted})
+}
+
+// syncItem starts a targeted sync of an item unless one is already running for it
+func (w *webhookListener) syncItem(workspaceID, itemID, eventType string) bool {
+	key := workspaceID + "/" + itemID
+	w.mu.Lock()
+	if w.inFlight[key] {
+		w.mu.Unlock()
+		return false
+	}
+	w.inFlight[key] = true
+	w.mu.Unlock()
+
+	started := w.app.background.Go(func() {
+		defer func() {
+			w.mu.Lock()
+			delete(w.inFlight, key)
+			w.mu.Unlock()
+		}()
+
+		select {
+		case w.slots <- struct{}{}:
+			defer func() { <-w.slots }()
+		case <-w.app.ctx.Done():
+			return
+		}
+
+		logger.Info("Webhook: syncing item", "eventType", eventType, logger.WorkspaceID(workspaceID), logger.ItemID(itemID))
+		result := w.app.SyncItem(workspaceID, itemID)
+		if result.Error != "" {
+			logger.Error("Webhook: item sync failed", logger.WorkspaceID(workspaceID), logger.ItemID(itemID), "error", result.Error)
+			return
+		}
+		w.app.emitEvent(EventItemSynced, map[string]interface{}{
+			"workspaceId": workspaceID,
+			"itemId":      itemID,
+			"jobsUpdated": result.JobsUpdated,
+			"source":      "webhook",
+		})
+	})
+	if !started {
+		w.mu.Lock()
+		delete(w.inFlight, key)
+		w.mu.Unlock()
+	}
+	return started
+}
+
+// webhookScope decides which items named by events may be synced, from the scope settings and the cache
+// Only workspaces and items a full sync already stored are synced, since the scope rules need their names and types;
+// the next full sync picks up new ones that are in scope
+type webhookScope struct {
+	scope         fabric.WorkspaceScope
+	excludedTypes []string
+	database      *db.Database
+	workspaces    map[string]fabric.Workspace
+	items         map[string]map[string]string // Item types by workspace ID and item ID, loaded on first use
+}
+
+// newWebhookScope snapshots the scope settings and the cached workspaces for one request
+func newWebhookScope(a *App) *webhookScope {
+	cfg := a.config()
+	s := &webhookScope{
+		scope:         workspaceScope(cfg),
+		excludedTypes: cfg.Fabric.ExcludedItemTypes,
+		database:      a.db(),
+		workspaces:    make(map[string]fabric.Workspace),
+		items:         make(map[string]map[string]string),
+	}
+	if s.database == nil {
+		return s
+	}
+	workspaces, err := s.database.GetWorkspaces()
+	if err != nil {
+		logger.Warn("Webhook: failed to read cached workspaces", logger.Err(err))
+		return s
+	}
+	for _, ws := range workspaces {
+		s.workspaces[ws.ID] = fabric.Workspace{ID: ws.ID, DisplayName: ws.DisplayName, Type: ws.Type}
+	}
+	return s
+}
+
+// allows reports whether the item is cached, its workspace is in scope and its type is synced
+func (s *webhookScope) allows(workspaceID, itemID string) bool {
+	workspace, ok := s.workspaces[workspaceID]
+	if !ok || !s.scope.Allows(workspace) {
+		return false
+	}
+
+	types, ok := s.items[workspaceID]
+	if !ok {
+		types = make(map[string]string)
+		items, err := s.database.GetItemsByWorkspace(workspaceID)
+		if err != nil {
+			logger.Warn("Webhook: failed to read cached items", logger.WorkspaceID(workspaceID), logger.Err(err))
+		}
+		for _, item := range items {
+			types[item.ID] = item.Type
+		}
+		s.items[workspaceID] = types
+	}
+	itemType, ok := types[itemID]
+	return ok && !slices.Contains(s.excludedTypes, itemType)
+}
+
+// isJSONContentType reports whether a Content-Type header names JSON, including types such as application/cloudevents+json
+func isJSONContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && (mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"))
+}
+
+// parseWebhookEvents decodes a single event object or an array of events
+func parseWebhookEvents(body []byte) ([]webhookEvent, error) {
+	var events []webhookEvent
+	if err := json.Unmarshal(body, &events); err == nil {
+		return events, nil
+	}
+	var event webhookEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		return nil, err
+	}
+	return []webhookEvent{event}, nil
+}
+
+// hasItemEvent reports whether any event names both a workspace and an item
+func hasItemEvent(events []webhookEvent) bool {
+	for _, event := range events {
+		if workspaceID, itemID := event.itemKey(); workspaceID != "" && itemID != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// isLoopbackAddress reports whether a host:port only accepts local connections
+func isLoopbackAddress(address string) bool {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"better-fabric-monitor/internal/config"
+	"better-fabric-monitor/internal/db"
+)
+
+// newWebhookTestApp returns an app with a database caching a production and a sandbox workspace, a personal
+// workspace and items of each, and a scope limited to production workspaces without dataflows
+func newWebhookTestApp(t *testing.T) *App {
+	t.Helper()
+	database, err := db.NewDatabase(filepath.Join(t.TempDir(), "test.db"), "")
+	if err != nil {
+		t.Fatalf("NewDatabase: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	workspaces := []db.Workspace{
+		{ID: "ws-prod", DisplayName: "PROD-Sales", Type: "Workspace"},
+		{ID: "ws-sandbox", DisplayName: "PROD-Sales-sandbox", Type: "Workspace"},
+		{ID: "ws-personal", DisplayName: "PROD-Scratch", Type: "Personal"},
+	}
+	for _, ws := range workspaces {
+		if err := database.SaveWorkspace(&ws); err != nil {
+			t.Fatalf("SaveWorkspace: %v", err)
+		}
+	}
+	items := []db.Item{
+		{ID: "pipeline", WorkspaceID: "ws-prod", DisplayName: "Load", Type: "DataPipeline"},
+		{ID: "dataflow", WorkspaceID: "ws-prod", DisplayName: "Clean", Type: "Dataflow"},
+		{ID: "sandbox-pipeline", WorkspaceID: "ws-sandbox", DisplayName: "Load", Type: "DataPipeline"},
+		{ID: "personal-notebook", WorkspaceID: "ws-personal", DisplayName: "Scratch", Type: "Notebook"},
+	}
+	for _, item := range items {
+		if err := database.SaveItem(&item); err != nil {
+			t.Fatalf("SaveItem: %v", err)
+		}
+	}
+
+	cfg := &config.Config{}
+	cfg.Fabric.IncludeWorkspaces = []string{"PROD-*"}
+	cfg.Fabric.ExcludeWorkspaces = []string{"*-sandbox"}
+	cfg.Fabric.ExcludedItemTypes = []string{"Dataflow"}
+	a := NewApp()
+	a.currentConfig.Store(cfg)
+	a.currentDB.Store(database)
+	return a
+}
+
+func TestWebhookScope(t *testing.T) {
+	scope := newWebhookScope(newWebhookTestApp(t))
+	tests := []struct {
+		workspaceID, itemID string
+		want                bool
+	}{
+		{"ws-prod", "pipeline", true},
+		{"ws-prod", "dataflow", false},              // Excluded item type
+		{"ws-prod", "unknown", false},               // Item not cached yet
+		{"ws-sandbox", "sandbox-pipeline", false},   // Excluded workspace name
+		{"ws-personal", "personal-notebook", false}, // Personal workspace
+		{"ws-elsewhere", "pipeline", false},         // Workspace not cached
+		{"ws-sandbox", "pipeline", false},           // Item of another workspace
+	}
+	for _, tt := range tests {
+		if got := scope.allows(tt.workspaceID, tt.itemID); got != tt.want {
+			t.Errorf("allows(%s, %s) = %v, want %v", tt.workspaceID, tt.itemID, got, tt.want)
+		}
+	}
+}
+
+// Events posted without a JSON content type, as a web page can without a CORS preflight, are rejected
+func TestWebhookRequiresJSON(t *testing.T) {
+	w := &webhookListener{app: newWebhookTestApp(t), inFlight: make(map[string]bool), slots: make(chan struct{}, maxWebhookSyncs)}
+	body := `{"workspaceId": "ws-sandbox", "itemId": "sandbox-pipeline"}`
+	tests := []struct {
+		contentType string
+		want        int
+	}{
+		{"text/plain", http.StatusUnsupportedMediaType},
+		{"", http.StatusUnsupportedMediaType},
+		{"application/json; charset=utf-8", http.StatusAccepted},
+		{"application/cloudevents-batch+json", http.StatusAccepted},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodPost, webhookPath, strings.NewReader(body))
+		if tt.contentType != "" {
+			r.Header.Set("Content-Type", tt.contentType)
+		}
+		rw := httptest.NewRecorder()
+		w.handleEvents(rw, r)
+		if rw.Code != tt.want {
+			t.Errorf("Content-Type %q: status %d, want %d", tt.contentType, rw.Code, tt.want)
+		}
+		if rw.Code == http.StatusAccepted && !strings.Contains(rw.Body.String(), `"accepted":0`) {
+			t.Errorf("Content-Type %q: out-of-scope item accepted: %s", tt.contentType, rw.Body.String())
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"better-fabric-monitor/internal/api"
+	"better-fabric-monitor/internal/db"
+	"better-fabric-monitor/internal/logger"
+	"better-fabric-monitor/internal/notify"
+)
+
+// applyWorkspaceRouting makes notifier send events about a workspace only to the rules its settings route them to
+func applyWorkspaceRouting(notifier *notify.Notifier, database *db.Database) {
+	if notifier.Empty() || database == nil {
+		return
+	}
+	notifier.SetRouteCheck(func(event notify.Event, rule string) bool {
+		if event.Job == nil {
+			return true
+		}
+		settings, err := database.GetWorkspaceSettingsByID()
+		if err != nil {
+			logger.Warn("Failed to check workspace notification routing", logger.Err(err))
+			return true
+		}
+		ws, ok := settings[event.Job.WorkspaceID]
+		return !ok || ws.NotificationRules == nil || slices.Contains(ws.NotificationRules, rule)
+	})
+}
+
+// GetWorkspaceSettings returns the workspaces whose settings override the global ones,
+// with the notification rules their events can be routed to
+func (a *App) GetWorkspaceSettings() api.WorkspaceSettingsResult {
+	if a.db() == nil {
+		return api.WorkspaceSettingsResult{Error: "Database not initialized"}
+	}
+	settings, err := a.db().GetWorkspaceSettings()
+	if err != nil {
+		return api.WorkspaceSettingsResult{Error: fmt.Sprintf("Failed to get workspace settings: %v", err)}
+	}
+	if settings == nil {
+		settings = []db.WorkspaceSettings{}
+	}
+	return api.WorkspaceSettingsResult{Settings: settings, Rules: notificationRules}
+}
+
+// SetWorkspaceSettings replaces the overrides of a workspace; nil fields keep the global setting
+// NotificationRules routes the workspace's notifications to the listed rules only, none when empty
+// A retention setting deletes the workspace's older runs right away, and again after every sync
+func (a *App) SetWorkspaceSettings(settings db.WorkspaceSettings) error {
+	if err := a.writable(); err != nil {
+		return err
+	}
+	if settings.WorkspaceID == "" {
+		return fmt.Errorf("workspace ID is required")
+	}
+	if settings.PollIntervalSeconds != nil && *settings.PollIntervalSeconds < minPollingIntervalSeconds {
+		return fmt.Errorf("poll interval must be at least %d seconds", minPollingIntervalSeconds)
+	}
+	if settings.RetentionDays != nil && *settings.RetentionDays < 1 {
+		return fmt.Errorf("retention must be at least 1 day")
+	}
+	for _, rule := range settings.NotificationRules {
+		if !slices.Contains(notificationRules, rule) {
+			return fmt.Errorf("unknown notification rule: %s", rule)
+		}
+	}
+
+	settings.UpdatedAt = time.Now().UTC()
+	if err := a.db().SaveWorkspaceSettings(&settings); err != nil {
+		return fmt.Errorf("failed to save workspace settings: %w", err)
+	}
+	logger.Info("Workspace settings saved", logger.WorkspaceID(settings.WorkspaceID))
+
+	if settings.RetentionDays != nil {
+		cutoff := settings.UpdatedAt.AddDate(0, 0, -*settings.RetentionDays)
+		deleted, err := a.db().PruneWorkspaceHistory(settings.WorkspaceID, cutoff)
+		if err != nil {
+			return fmt.Errorf("failed to apply retention: %w", err)
+		}
+		if deleted > 0 {
+			logger.Info("Deleted runs past the workspace's retention", logger.WorkspaceID(settings.WorkspaceID),
+				"runs", deleted, "days", *settings.RetentionDays)
+		}
+	}
+	return nil
+}
+
+// RemoveWorkspaceSettings removes the overrides of a workspace, so it follows the global settings again
+func (a *App) RemoveWorkspaceSettings(workspaceID string) error {
+	if err := a.writable(); err != nil {
+		return err
+	}
+	if err := a.db().DeleteWorkspaceSettings(workspaceID); err != nil {
+		return fmt.Errorf("failed to remove workspace settings: %w", err)
+	}
+	logger.Info("Workspace settings removed", logger.WorkspaceID(workspaceID))
+	return nil
+}